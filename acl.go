@@ -0,0 +1,85 @@
+package slackbot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	aclUserDeniedMessage    = "Sorry, you aren't allowed to use this command."
+	aclChannelDeniedMessage = "Sorry, this command can't be used in this channel."
+)
+
+// ACL restricts who can use the bot's commands and where. Users are user IDs, and members of the Groups, which
+// are usergroups identified by ID, handle or name, are allowed too. Channels are channel IDs, direct messages
+// are always allowed. An ACL with no Users or Groups allows everyone, and one with no Channels allows every
+// channel. The bot's ACL applies to all of its listeners and exchanges, which can have their own ACL to
+// restrict them further.
+type ACL struct {
+	Users    []string `json:"users,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+	Channels []string `json:"channels,omitempty"`
+}
+
+// AllowsUser returns true if the user is one of the ACL's Users or a member of one of its Groups. Usergroups
+// that can't be found are logged and treated as having no members.
+func (acl *ACL) AllowsUser(bot *Bot, user string) bool {
+	if acl == nil || (len(acl.Users) == 0 && len(acl.Groups) == 0) {
+		return true
+	}
+	for _, u := range acl.Users {
+		if u == user {
+			return true
+		}
+	}
+	for _, g := range acl.Groups {
+		members, err := bot.UsersInGroup(g)
+		if err != nil {
+			bot.LogWarn(fmt.Sprintf("unable to check acl group %s - %s", g, err))
+			continue
+		}
+		for _, m := range members {
+			if m == user {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AllowsChannel returns true if the channel is one of the ACL's Channels or a direct message.
+func (acl *ACL) AllowsChannel(channel string) bool {
+	if acl == nil || len(acl.Channels) == 0 || strings.HasPrefix(channel, directMessagePrefix) {
+		return true
+	}
+	for _, c := range acl.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// CanRun returns true if the bot's ACL and the command's ACL, which can be nil, allow the user to run the
+// command in the channel.
+func (bot *Bot) CanRun(acl *ACL, user string, channel string) bool {
+	return bot.ACL.AllowsUser(bot, user) && acl.AllowsUser(bot, user) &&
+		bot.ACL.AllowsChannel(channel) && acl.AllowsChannel(channel)
+}
+
+// aclDenied returns true and tells the user why if the ACL doesn't allow the event.
+func (bot *Bot) aclDenied(acl *ACL, ev *slack.MessageEvent) bool {
+	msg := ""
+	switch {
+	case !bot.ACL.AllowsUser(bot, ev.User) || !acl.AllowsUser(bot, ev.User):
+		msg = aclUserDeniedMessage
+	case !bot.ACL.AllowsChannel(ev.Channel) || !acl.AllowsChannel(ev.Channel):
+		msg = aclChannelDeniedMessage
+	default:
+		return false
+	}
+	_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), msg)
+	return true
+}
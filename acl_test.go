@@ -0,0 +1,157 @@
+package slackbot
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_CanRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		botACL  *ACL
+		acl     *ACL
+		user    string
+		channel string
+		want    bool
+	}{
+		{
+			name:    "should allow everyone without an acl",
+			user:    "U1",
+			channel: "C1",
+			want:    true,
+		},
+		{
+			name:    "should allow the acl's users",
+			acl:     &ACL{Users: []string{"U1"}},
+			user:    "U1",
+			channel: "C1",
+			want:    true,
+		},
+		{
+			name:    "should deny other users",
+			acl:     &ACL{Users: []string{"U1"}},
+			user:    "U2",
+			channel: "C1",
+		},
+		{
+			name:    "should allow members of the acl's groups",
+			acl:     &ACL{Groups: []string{"oncall"}},
+			user:    "U2",
+			channel: "C1",
+			want:    true,
+		},
+		{
+			name:    "should deny other channels",
+			acl:     &ACL{Channels: []string{"C1"}},
+			user:    "U1",
+			channel: "C2",
+		},
+		{
+			name:    "should allow direct messages",
+			acl:     &ACL{Channels: []string{"C1"}},
+			user:    "U1",
+			channel: "D1",
+			want:    true,
+		},
+		{
+			name:    "should apply the bot's acl as well",
+			botACL:  &ACL{Channels: []string{"C2"}},
+			acl:     &ACL{Users: []string{"U1"}},
+			user:    "U1",
+			channel: "C1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{
+				ACL: tt.botACL,
+				API: &mockAPI{getUserGroups: func(...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+					return []slack.UserGroup{{ID: "S1", Handle: "oncall", Users: []string{"U2"}}}, nil
+				}},
+			}
+			if got := bot.CanRun(tt.acl, tt.user, tt.channel); got != tt.want {
+				t.Errorf("CanRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_processMessage_acl(t *testing.T) {
+	tests := []struct {
+		name        string
+		user        string
+		channel     string
+		text        string
+		wantRan     bool
+		wantReplies []string
+	}{
+		{
+			name:    "should run an allowed listener",
+			user:    "U1",
+			channel: "C1",
+			text:    "<@bot> deploy",
+			wantRan: true,
+		},
+		{
+			name:        "should tell users who aren't allowed",
+			user:        "U2",
+			channel:     "C1",
+			text:        "<@bot> deploy",
+			wantReplies: []string{aclUserDeniedMessage},
+		},
+		{
+			name:        "should tell users when the channel isn't allowed",
+			user:        "U1",
+			channel:     "C2",
+			text:        "<@bot> deploy",
+			wantReplies: []string{aclChannelDeniedMessage},
+		},
+		{
+			name:        "should check exchanges",
+			user:        "U2",
+			channel:     "C1",
+			text:        "<@bot> rollback",
+			wantReplies: []string{aclUserDeniedMessage},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var replies []string
+			ran := false
+			acl := &ACL{Users: []string{"U1"}, Channels: []string{"C1"}}
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						replies = append(replies, msgValues(opts...).Get("text"))
+						return s, "ts", nil
+					},
+				},
+				DirectListeners: []Listener{{
+					Regex:   regexp.MustCompile(`^deploy$`),
+					ACL:     acl,
+					Handler: func(bot *Bot, ev *slack.MessageEvent) { ran = true },
+				}},
+				Exchanges: []Exchange{{
+					Regex: regexp.MustCompile(`^rollback$`),
+					ACL:   acl,
+					Steps: map[int]*Step{1: {Handler: func(ex *Exchange) error {
+						ran = true
+						return nil
+					}}},
+				}},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			bot.init()
+			bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: tt.channel, User: tt.user, Text: tt.text, Timestamp: "1.1"}})
+			if ran != tt.wantRan {
+				t.Errorf("handler ran = %v, want %v", ran, tt.wantRan)
+			}
+			if !reflect.DeepEqual(replies, tt.wantReplies) {
+				t.Errorf("replies = %q, want %q", replies, tt.wantReplies)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package slackbot
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	usageStoreKeyPrefix = "usage:"
+	usageDayLayout      = "2006-01-02"
+	usageReportTop      = 10
+	defaultUsageDays    = 7
+)
+
+var usageStatsRegex = regexp.MustCompile(`^(?i)usage stats(?: (\d+))?$`)
+
+type (
+	// UsageReport is a summary of the commands used over a period, see Bot.UsageReport.
+	UsageReport struct {
+		Since    time.Time
+		Commands []UsageCount
+		Users    []UsageCount
+		Channels []UsageCount
+	}
+
+	// UsageCount is the number of times a command was used, or the number of commands a user or
+	// channel used, in a UsageReport.
+	UsageCount struct {
+		Name  string
+		Count int
+	}
+
+	// usageDay is the usage recorded in the store for a single day.
+	usageDay struct {
+		Commands map[string]int
+		Users    map[string]int
+		Channels map[string]int
+	}
+)
+
+// UsageReport returns the command usage recorded over the period up to now, sorted with the most used first.
+// Usage is only recorded when the bot has RecordUsage set and a Store.
+func (bot *Bot) UsageReport(period time.Duration) (*UsageReport, error) {
+	if bot.Store == nil {
+		return nil, errors.New("usage reports require the bot to have a Store")
+	}
+	now := bot.clock().Now()
+	report := &UsageReport{Since: now.Add(-period)}
+	totals := usageDay{Commands: map[string]int{}, Users: map[string]int{}, Channels: map[string]int{}}
+
+	bot.usageMu.Lock()
+	defer bot.usageMu.Unlock()
+	today := now.Format(usageDayLayout)
+	for d := report.Since; d.Format(usageDayLayout) <= today; d = d.AddDate(0, 0, 1) {
+		var day usageDay
+		if err := bot.Store.Get(usageStoreKeyPrefix+d.Format(usageDayLayout), &day); err != nil {
+			// days without usage are never saved
+			continue
+		}
+		addCounts(totals.Commands, day.Commands)
+		addCounts(totals.Users, day.Users)
+		addCounts(totals.Channels, day.Channels)
+	}
+	report.Commands = sortCounts(totals.Commands)
+	report.Users = sortCounts(totals.Users)
+	report.Channels = sortCounts(totals.Channels)
+	return report, nil
+}
+
+// UsageStatsListener returns a DirectListener for admins that replies with the top commands and most active
+// users when the bot is messaged "usage stats", or "usage stats <days>" to change the default period of 7 days.
+// Only the users and channels allowed by the admins ACL can use it. It is not enabled by default, add it to the
+// bot's DirectListeners to enable it.
+func UsageStatsListener(admins *ACL) Listener {
+	return Listener{
+		Usage: "usage stats [days]",
+		Regex: usageStatsRegex,
+		ACL:   admins,
+		Handler: func(bot *Bot, ev *slack.MessageEvent) {
+			days := defaultUsageDays
+			if m := usageStatsRegex.FindStringSubmatch(ev.Text); m[1] != "" {
+				days, _ = strconv.Atoi(m[1])
+			}
+			report, err := bot.UsageReport(time.Duration(days) * 24 * time.Hour)
+			if err != nil {
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), fmt.Sprintf("Error getting usage stats - %s", err))
+				return
+			}
+			_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), report.String())
+		},
+	}
+}
+
+// String formats the top commands and most active users in the report.
+func (r *UsageReport) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("Usage since %s\n", r.Since.Format(usageDayLayout)))
+	if len(r.Commands) == 0 {
+		buf.WriteString("No commands have been used")
+		return buf.String()
+	}
+	buf.WriteString("*Top Commands*\n")
+	for i, c := range r.Commands {
+		if i == usageReportTop {
+			break
+		}
+		buf.WriteString(fmt.Sprintf("%d. %s - %d\n", i+1, c.Name, c.Count))
+	}
+	buf.WriteString("*Active Users*\n")
+	for i, u := range r.Users {
+		if i == usageReportTop {
+			break
+		}
+		buf.WriteString(fmt.Sprintf("%d. <@%s> - %d\n", i+1, u.Name, u.Count))
+	}
+	return buf.String()
+}
+
+// recordUsage adds the command to today's usage in the bot's Store if RecordUsage is set.
+func (bot *Bot) recordUsage(command string, ev *slack.MessageEvent) {
+	if !bot.RecordUsage || bot.Store == nil || command == "" {
+		return
+	}
+	key := usageStoreKeyPrefix + bot.clock().Now().Format(usageDayLayout)
+
+	bot.usageMu.Lock()
+	defer bot.usageMu.Unlock()
+	var day usageDay
+	if err := bot.Store.Get(key, &day); err != nil {
+		day = usageDay{}
+	}
+	day.Commands = incrementCount(day.Commands, command)
+	day.Users = incrementCount(day.Users, ev.User)
+	day.Channels = incrementCount(day.Channels, ev.Channel)
+	if err := bot.Store.Put(key, day); err != nil {
+		bot.LogError(fmt.Sprintf("error recording usage of %s - %s", command, err))
+	}
+}
+
+// commandName returns the name usage is recorded under, the usage string if there is one or else the regex.
+func commandName(usage string, regex *regexp.Regexp) string {
+	if usage != "" {
+		return usage
+	}
+	if regex != nil {
+		return regex.String()
+	}
+	return ""
+}
+
+func incrementCount(counts map[string]int, key string) map[string]int {
+	if counts == nil {
+		counts = map[string]int{}
+	}
+	if key != "" {
+		counts[key]++
+	}
+	return counts
+}
+
+func addCounts(totals map[string]int, counts map[string]int) {
+	for k, v := range counts {
+		totals[k] += v
+	}
+}
+
+func sortCounts(counts map[string]int) []UsageCount {
+	sorted := make([]UsageCount, 0, len(counts))
+	for k, v := range counts {
+		sorted = append(sorted, UsageCount{Name: k, Count: v})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
@@ -0,0 +1,146 @@
+package slackbot
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_UsageReport(t *testing.T) {
+	tests := []struct {
+		name         string
+		recordUsage  bool
+		events       []*slack.MessageEvent
+		wantCommands []UsageCount
+		wantUsers    []UsageCount
+	}{
+		{
+			name:        "should count commands and users",
+			recordUsage: true,
+			events: []*slack.MessageEvent{
+				{Msg: slack.Msg{User: "U1", Channel: "D1", Text: "deploy"}},
+				{Msg: slack.Msg{User: "U2", Channel: "D2", Text: "deploy"}},
+				{Msg: slack.Msg{User: "U1", Channel: "D1", Text: "status"}},
+			},
+			wantCommands: []UsageCount{{Name: "deploy", Count: 2}, {Name: "^status$", Count: 1}},
+			wantUsers:    []UsageCount{{Name: "U1", Count: 2}, {Name: "U2", Count: 1}},
+		},
+		{
+			name:        "should not record usage unless it is enabled",
+			recordUsage: false,
+			events: []*slack.MessageEvent{
+				{Msg: slack.Msg{User: "U1", Channel: "D1", Text: "deploy"}},
+			},
+			wantCommands: []UsageCount{},
+			wantUsers:    []UsageCount{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{
+				RecordUsage: tt.recordUsage,
+				Store:       SimpleStore{},
+				API:         &mockAPI{},
+				DirectListeners: []Listener{
+					{Usage: "deploy", Regex: regexp.MustCompile("^deploy$"), Handler: func(*Bot, *slack.MessageEvent) {}},
+					{Regex: regexp.MustCompile("^status$"), Handler: func(*Bot, *slack.MessageEvent) {}},
+				},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			for _, ev := range tt.events {
+				bot.processMessage(ev)
+			}
+			report, err := bot.UsageReport(24 * time.Hour)
+			if err != nil {
+				t.Fatalf("UsageReport() error = %v", err)
+			}
+			if !reflect.DeepEqual(report.Commands, tt.wantCommands) {
+				t.Errorf("UsageReport() commands = %v, want %v", report.Commands, tt.wantCommands)
+			}
+			if !reflect.DeepEqual(report.Users, tt.wantUsers) {
+				t.Errorf("UsageReport() users = %v, want %v", report.Users, tt.wantUsers)
+			}
+		})
+	}
+}
+
+func TestUsageReport_String(t *testing.T) {
+	since := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		report *UsageReport
+		want   string
+	}{
+		{
+			name:   "should say when there is no usage",
+			report: &UsageReport{Since: since},
+			want:   "Usage since 2020-01-02\nNo commands have been used",
+		},
+		{
+			name: "should list top commands and users",
+			report: &UsageReport{
+				Since:    since,
+				Commands: []UsageCount{{Name: "deploy", Count: 2}},
+				Users:    []UsageCount{{Name: "U1", Count: 2}},
+			},
+			want: strings.Join([]string{
+				"Usage since 2020-01-02",
+				"*Top Commands*",
+				"1. deploy - 2",
+				"*Active Users*",
+				"1. <@U1> - 2",
+				"",
+			}, "\n"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.report.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsageStatsListener(t *testing.T) {
+	tests := []struct {
+		name string
+		user string
+		want string
+	}{
+		{
+			name: "should reply with the usage stats",
+			user: "U1",
+			want: "No commands have been used",
+		},
+		{
+			name: "should not show other users the usage stats",
+			user: "U2",
+			want: aclUserDeniedMessage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						got = msgValues(opts...).Get("text")
+						return s, "ts", nil
+					},
+				},
+				DirectListeners: []Listener{UsageStatsListener(&ACL{Users: []string{"U1"}})},
+				userDetails:     &slack.UserDetails{ID: "bot"},
+			}
+			bot.init()
+			bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: tt.user, Text: "usage stats", Timestamp: "1.1"}})
+			if !strings.HasSuffix(got, tt.want) {
+				t.Errorf("reply = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
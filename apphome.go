@@ -0,0 +1,87 @@
+package slackbot
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+const appHomeTab = "home"
+
+// AppHome builds the bot's Home tab. When a user opens the tab, Build is called and the blocks it returns are
+// published with views.publish. The tab can be refreshed from handlers with RefreshHome, for example after a
+// job completes. App Home events are only received through the Events API, see EventsHandler.
+type AppHome struct {
+	Build func(bot *Bot, user string) ([]slack.Block, error)
+
+	mu    sync.Mutex
+	users map[string]bool
+}
+
+// RefreshHome builds and publishes the Home tab for the user.
+func (bot *Bot) RefreshHome(user string) error {
+	if bot.AppHome == nil || bot.AppHome.Build == nil {
+		return errors.New("the bot does not have an AppHome")
+	}
+	bot.AppHome.addUser(user)
+	blocks, err := bot.AppHome.Build(bot, user)
+	if err != nil {
+		return errors.Wrapf(err, "unable to build home tab for %s", user)
+	}
+	view := slack.HomeTabViewRequest{
+		Type:   slack.VTHomeTab,
+		Blocks: slack.Blocks{BlockSet: blocks},
+	}
+	err = bot.withRetry(func() error {
+		_, err := bot.api().PublishViewContext(bot.context(), user, view, "")
+		return err
+	})
+	return errors.Wrapf(err, "unable to publish home tab for %s", user)
+}
+
+// RefreshHomes publishes the Home tab again for every user that has opened it since the bot started.
+func (bot *Bot) RefreshHomes() error {
+	if bot.AppHome == nil {
+		return errors.New("the bot does not have an AppHome")
+	}
+	var failed error
+	for _, user := range bot.AppHome.knownUsers() {
+		if err := bot.RefreshHome(user); err != nil {
+			failed = err
+			bot.LogError(err.Error())
+		}
+	}
+	return failed
+}
+
+// handleAppHomeOpened publishes the Home tab when a user opens it.
+func (bot *Bot) handleAppHomeOpened(ev *slackevents.AppHomeOpenedEvent) {
+	if bot.AppHome == nil || ev.Tab != appHomeTab {
+		return
+	}
+	if err := bot.RefreshHome(ev.User); err != nil {
+		bot.LogError(fmt.Sprintf("error opening home tab - %s", err))
+	}
+}
+
+func (h *AppHome) addUser(user string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.users == nil {
+		h.users = make(map[string]bool)
+	}
+	h.users[user] = true
+}
+
+func (h *AppHome) knownUsers() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	users := make([]string, 0, len(h.users))
+	for u := range h.users {
+		users = append(users, u)
+	}
+	return users
+}
@@ -0,0 +1,119 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+func TestBot_RefreshHome(t *testing.T) {
+	tests := []struct {
+		name       string
+		home       *AppHome
+		publishErr error
+		wantBlocks int
+		wantErr    bool
+	}{
+		{
+			name: "should publish the blocks",
+			home: &AppHome{
+				Build: func(bot *Bot, user string) ([]slack.Block, error) {
+					return []slack.Block{slack.NewDividerBlock(), slack.NewDividerBlock()}, nil
+				},
+			},
+			wantBlocks: 2,
+		},
+		{
+			name:    "should return an error without an app home",
+			wantErr: true,
+		},
+		{
+			name: "should return an error if the blocks can't be built",
+			home: &AppHome{
+				Build: func(bot *Bot, user string) ([]slack.Block, error) {
+					return nil, errors.New("failed")
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "should return an error if the view can't be published",
+			home: &AppHome{
+				Build: func(bot *Bot, user string) ([]slack.Block, error) {
+					return nil, nil
+				},
+			},
+			publishErr: errors.New("invalid_blocks"),
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotBlocks := 0
+			bot := &Bot{
+				AppHome: tt.home,
+				API: &mockAPI{
+					publishView: func(user string, view slack.HomeTabViewRequest, hash string) (*slack.ViewResponse, error) {
+						if user != "U1" || view.Type != slack.VTHomeTab {
+							t.Errorf("PublishView() called with %s, %s", user, view.Type)
+						}
+						gotBlocks = len(view.Blocks.BlockSet)
+						return &slack.ViewResponse{}, tt.publishErr
+					},
+				},
+			}
+			if err := bot.RefreshHome("U1"); (err != nil) != tt.wantErr {
+				t.Errorf("RefreshHome() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotBlocks != tt.wantBlocks {
+				t.Errorf("RefreshHome() published %d blocks, want %d", gotBlocks, tt.wantBlocks)
+			}
+		})
+	}
+}
+
+func TestBot_RefreshHomes(t *testing.T) {
+	var published []string
+	bot := &Bot{
+		AppHome: &AppHome{
+			Build: func(bot *Bot, user string) ([]slack.Block, error) {
+				return nil, nil
+			},
+		},
+		API: &mockAPI{
+			publishView: func(user string, view slack.HomeTabViewRequest, hash string) (*slack.ViewResponse, error) {
+				published = append(published, user)
+				return &slack.ViewResponse{}, nil
+			},
+		},
+		userDetails: &slack.UserDetails{ID: "bot"},
+	}
+	for _, e := range []string{
+		eventJSON("app_home_opened", `"user":"U1","tab":"home"`),
+		eventJSON("app_home_opened", `"user":"U2","tab":"home"`),
+		eventJSON("app_home_opened", `"user":"U3","tab":"messages"`),
+	} {
+		ev, err := slackevents.ParseEvent(json.RawMessage(e), slackevents.OptionNoVerifyToken())
+		if err != nil {
+			t.Fatalf("ParseEvent() error = %v", err)
+		}
+		bot.HandleEventsAPIEvent(ev)
+	}
+	if want := []string{"U1", "U2"}; !reflect.DeepEqual(published, want) {
+		t.Fatalf("app_home_opened published %v, want %v", published, want)
+	}
+
+	published = nil
+	if err := bot.RefreshHomes(); err != nil {
+		t.Fatalf("RefreshHomes() error = %v", err)
+	}
+	sort.Strings(published)
+	if want := []string{"U1", "U2"}; !reflect.DeepEqual(published, want) {
+		t.Errorf("RefreshHomes() published %v, want %v", published, want)
+	}
+}
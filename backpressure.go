@@ -0,0 +1,124 @@
+package slackbot
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxEventLag       = 5 * time.Second
+	backpressureWarnInterval = time.Minute
+	eventLagMessage          = "the bot is falling behind, a message in %s was processed %s after it was sent"
+	incomingFullMessage      = "the bot is falling behind, %d of %d incoming events are waiting to be processed, slack drops events when the buffer is full"
+	handlerWaitMessage       = "the bot is falling behind, a message waited %s to be handled"
+)
+
+type (
+	// BackpressureStats show whether the bot is keeping up with the events slack sends it. EventLag is how
+	// long after a message was sent the bot started processing it, and HandlerWait is how long a message
+	// waited for a free worker before it was handled. SlowEvents counts the messages whose lag or wait was
+	// over the bot's MaxEventLag.
+	BackpressureStats struct {
+		IncomingEvents   int           `json:"incoming_events"`
+		IncomingCapacity int           `json:"incoming_capacity"`
+		EventLag         time.Duration `json:"event_lag"`
+		MaxEventLag      time.Duration `json:"max_event_lag"`
+		HandlerWait      time.Duration `json:"handler_wait"`
+		MaxHandlerWait   time.Duration `json:"max_handler_wait"`
+		SlowEvents       int           `json:"slow_events"`
+	}
+
+	// backpressure records the bot's BackpressureStats and when it last warned that it is falling behind.
+	backpressure struct {
+		mu     sync.Mutex
+		stats  BackpressureStats
+		warned time.Time
+	}
+)
+
+// noteEventLag records how long after the message was sent it is being processed, and warns if it is over
+// the MaxEventLag.
+func (bot *Bot) noteEventLag(channel string, timestamp string) {
+	sent := timestampTime(timestamp)
+	if sent.IsZero() {
+		return
+	}
+	lag := bot.clock().Now().Sub(sent)
+	if lag < 0 {
+		lag = 0
+	}
+	p := &bot.pressure
+	p.mu.Lock()
+	p.stats.EventLag = lag
+	if lag > p.stats.MaxEventLag {
+		p.stats.MaxEventLag = lag
+	}
+	slow := lag > bot.maxEventLag()
+	if slow {
+		p.stats.SlowEvents++
+	}
+	p.mu.Unlock()
+	if slow {
+		bot.warnBackpressure(fmt.Sprintf(eventLagMessage, channel, lag.Round(time.Millisecond)))
+	}
+}
+
+// noteHandlerWait records how long a message waited to be handled, and warns if it is over the MaxEventLag.
+func (bot *Bot) noteHandlerWait(wait time.Duration) {
+	p := &bot.pressure
+	p.mu.Lock()
+	p.stats.HandlerWait = wait
+	if wait > p.stats.MaxHandlerWait {
+		p.stats.MaxHandlerWait = wait
+	}
+	slow := wait > bot.maxEventLag()
+	if slow {
+		p.stats.SlowEvents++
+	}
+	p.mu.Unlock()
+	if slow {
+		bot.warnBackpressure(fmt.Sprintf(handlerWaitMessage, wait.Round(time.Millisecond)))
+	}
+}
+
+// noteIncoming warns when the buffer of events received over the RTM connection is at least 80% full.
+func (bot *Bot) noteIncoming(waiting int, capacity int) {
+	if capacity > 0 && waiting*5 >= capacity*4 {
+		bot.warnBackpressure(fmt.Sprintf(incomingFullMessage, waiting, capacity))
+	}
+}
+
+// warnBackpressure logs the warning, no more than once a minute so a backlog doesn't add to itself.
+func (bot *Bot) warnBackpressure(msg string) {
+	p := &bot.pressure
+	now := bot.clock().Now()
+	p.mu.Lock()
+	if !p.warned.IsZero() && now.Sub(p.warned) < backpressureWarnInterval {
+		p.mu.Unlock()
+		return
+	}
+	p.warned = now
+	p.mu.Unlock()
+	bot.LogWarn(msg)
+}
+
+// backpressureStats returns the recorded stats along with the events waiting in the RTM connection's buffer.
+func (bot *Bot) backpressureStats() BackpressureStats {
+	bot.pressure.mu.Lock()
+	stats := bot.pressure.stats
+	bot.pressure.mu.Unlock()
+	if api := bot.api(); api != nil {
+		if events := api.GetIncomingEvents(); events != nil {
+			stats.IncomingEvents, stats.IncomingCapacity = len(events), cap(events)
+		}
+	}
+	return stats
+}
+
+func (bot *Bot) maxEventLag() time.Duration {
+	if bot.MaxEventLag > 0 {
+		return bot.MaxEventLag
+	}
+	return defaultMaxEventLag
+}
@@ -0,0 +1,133 @@
+package slackbot
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// warningAPI is a mockAPI that records the messages sent to the DebugChannel.
+func warningAPI() (*mockAPI, func() []string) {
+	var mu sync.Mutex
+	var warnings []string
+	api := &mockAPI{postMessage: func(channel string, opts ...slack.MsgOption) (string, string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		warnings = append(warnings, msgValues(opts...).Get("text"))
+		return channel, "ts", nil
+	}}
+	return api, func() []string { mu.Lock(); defer mu.Unlock(); return append([]string(nil), warnings...) }
+}
+
+func TestBot_noteEventLag(t *testing.T) {
+	now := time.Date(2021, 3, 5, 12, 0, 0, 0, time.UTC)
+	sentAt := func(ago time.Duration) string {
+		return strconv.FormatInt(now.Add(-ago).Unix(), 10) + ".000000"
+	}
+	tests := []struct {
+		name         string
+		maxEventLag  time.Duration
+		timestamps   []string
+		want         BackpressureStats
+		wantWarnings int
+	}{
+		{
+			name:       "should record the lag of messages",
+			timestamps: []string{sentAt(2 * time.Second), sentAt(time.Second)},
+			want:       BackpressureStats{EventLag: time.Second, MaxEventLag: 2 * time.Second},
+		},
+		{
+			name:         "should count and warn about slow messages once a minute",
+			timestamps:   []string{sentAt(10 * time.Second), sentAt(time.Second), sentAt(20 * time.Second)},
+			want:         BackpressureStats{EventLag: 20 * time.Second, MaxEventLag: 20 * time.Second, SlowEvents: 2},
+			wantWarnings: 1,
+		},
+		{
+			name:        "should use the bot's MaxEventLag",
+			maxEventLag: time.Minute,
+			timestamps:  []string{sentAt(10 * time.Second)},
+			want:        BackpressureStats{EventLag: 10 * time.Second, MaxEventLag: 10 * time.Second},
+		},
+		{
+			name:       "should ignore messages without a timestamp",
+			timestamps: []string{""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, warnings := warningAPI()
+			bot := &Bot{
+				API:          api,
+				DebugChannel: "debug",
+				MaxEventLag:  tt.maxEventLag,
+				Clock:        fixedClock{Clock: RealClock, now: now},
+			}
+			for _, ts := range tt.timestamps {
+				bot.noteEventLag("C1", ts)
+			}
+			if got := bot.backpressureStats(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("backpressureStats() = %+v, want %+v", got, tt.want)
+			}
+			if got := warnings(); len(got) != tt.wantWarnings {
+				t.Errorf("warnings = %q, want %d", got, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestBot_noteHandlerWait(t *testing.T) {
+	api, warnings := warningAPI()
+	bot := &Bot{API: api, DebugChannel: "debug"}
+	bot.noteHandlerWait(time.Millisecond)
+	bot.noteHandlerWait(6 * time.Second)
+	bot.noteHandlerWait(2 * time.Millisecond)
+
+	want := BackpressureStats{HandlerWait: 2 * time.Millisecond, MaxHandlerWait: 6 * time.Second, SlowEvents: 1}
+	if got := bot.backpressureStats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("backpressureStats() = %+v, want %+v", got, want)
+	}
+	if got := warnings(); len(got) != 1 || !strings.Contains(got[0], "waited 6s") {
+		t.Errorf("warnings = %q, want a warning about the wait", got)
+	}
+}
+
+func TestBot_noteIncoming(t *testing.T) {
+	tests := []struct {
+		name        string
+		waiting     int
+		wantWarning bool
+	}{
+		{
+			name:    "should not warn when the buffer has room",
+			waiting: 39,
+		},
+		{
+			name:        "should warn when the buffer is 80% full",
+			waiting:     40,
+			wantWarning: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, warnings := warningAPI()
+			api.incomingEvents = make(chan slack.RTMEvent, 50)
+			for i := 0; i < tt.waiting; i++ {
+				api.incomingEvents <- slack.RTMEvent{}
+			}
+			bot := &Bot{API: api, DebugChannel: "debug"}
+			bot.noteIncoming(len(api.incomingEvents), cap(api.incomingEvents))
+			if got := len(warnings()) == 1; got != tt.wantWarning {
+				t.Errorf("warned = %v, want %v", got, tt.wantWarning)
+			}
+			stats := bot.Stats().Backpressure
+			if stats.IncomingEvents != tt.waiting || stats.IncomingCapacity != 50 {
+				t.Errorf("Stats() incoming events = %d of %d, want %d of 50", stats.IncomingEvents, stats.IncomingCapacity, tt.waiting)
+			}
+		})
+	}
+}
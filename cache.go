@@ -0,0 +1,86 @@
+package slackbot
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// cache is a concurrency safe key value store where entries expire after the ttl, as measured by the clock.
+	// A ttl of 0 means entries never expire.
+	cache struct {
+		ttl     time.Duration
+		clock   Clock
+		mu      sync.Mutex
+		entries map[string]cacheEntry
+		hits    int
+		misses  int
+	}
+
+	cacheEntry struct {
+		value   interface{}
+		expires time.Time
+	}
+)
+
+func newCache(ttl time.Duration, clock Clock) *cache {
+	return &cache{
+		ttl:     ttl,
+		clock:   clock,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+func (c *cache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	if c.ttl > 0 && c.clock.Now().After(e.expires) {
+		delete(c.entries, key)
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	return e.value, true
+}
+
+func (c *cache) set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cacheEntry{
+		value:   value,
+		expires: c.clock.Now().Add(c.ttl),
+	}
+}
+
+// add sets the value if the key isn't already set, and returns false if it was. Checking and setting the key
+// under one lock means only one of several concurrent callers adds it.
+func (c *cache) add(key string, value interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok && (c.ttl <= 0 || !c.clock.Now().After(e.expires)) {
+		return false
+	}
+	c.entries[key] = cacheEntry{
+		value:   value,
+		expires: c.clock.Now().Add(c.ttl),
+	}
+	return true
+}
+
+func (c *cache) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// stats returns the number of entries in the cache and how many lookups hit or missed.
+func (c *cache) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Entries: len(c.entries), Hits: c.hits, Misses: c.misses}
+}
@@ -0,0 +1,98 @@
+package slackbot
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_cache(t *testing.T) {
+	tests := []struct {
+		name   string
+		ttl    time.Duration
+		wait   time.Duration
+		delete bool
+		wantOk bool
+	}{
+		{
+			name:   "should return a value that has not expired",
+			ttl:    time.Minute,
+			wantOk: true,
+		},
+		{
+			name:   "should not return an expired value",
+			ttl:    time.Millisecond,
+			wait:   5 * time.Millisecond,
+			wantOk: false,
+		},
+		{
+			name:   "should never expire with a ttl of 0",
+			ttl:    0,
+			wait:   5 * time.Millisecond,
+			wantOk: true,
+		},
+		{
+			name:   "should not return a deleted value",
+			ttl:    time.Minute,
+			delete: true,
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Date(2021, 3, 5, 12, 0, 0, 0, time.UTC)
+			c := newCache(tt.ttl, fixedClock{Clock: RealClock, now: start})
+			c.set("key", "value")
+			c.clock = fixedClock{Clock: RealClock, now: start.Add(tt.wait)}
+			if tt.delete {
+				c.delete("key")
+			}
+			if _, ok := c.get("key"); ok != tt.wantOk {
+				t.Errorf("get() ok = %v, want %v", ok, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_cache_stats(t *testing.T) {
+	c := newCache(0, RealClock)
+	c.set("a", 1)
+	c.set("b", 2)
+	c.get("a")
+	c.get("a")
+	c.get("missing")
+	want := CacheStats{Entries: 2, Hits: 2, Misses: 1}
+	if got := c.stats(); got != want {
+		t.Errorf("stats() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_cache_add(t *testing.T) {
+	c := newCache(time.Minute, RealClock)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	added := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if c.add("key", true) {
+				mu.Lock()
+				added++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if added != 1 {
+		t.Errorf("add() added the key %d times, want 1", added)
+	}
+
+	start := time.Date(2021, 3, 5, 12, 0, 0, 0, time.UTC)
+	expired := newCache(time.Millisecond, fixedClock{Clock: RealClock, now: start})
+	expired.set("key", true)
+	expired.clock = fixedClock{Clock: RealClock, now: start.Add(5 * time.Millisecond)}
+	if !expired.add("key", true) {
+		t.Error("add() = false for an expired key, want true")
+	}
+}
@@ -0,0 +1,88 @@
+package slackbot
+
+import (
+	"fmt"
+	"time"
+)
+
+type (
+	// Calendar decides which days are weekends and holidays, for ScheduledTasks that are WeekdaysOnly or
+	// SkipHolidays. It can be implemented with a holiday api or a company calendar, HolidayCalendar is a
+	// simple implementation.
+	Calendar interface {
+		IsWeekend(day time.Time) bool
+		IsHoliday(day time.Time) bool
+	}
+
+	// HolidayCalendar is a Calendar with a fixed list of Holidays. They are dates like "2021-11-25" for
+	// holidays on a single date, or "12-25" for holidays on the same date every year. The Weekend is
+	// Saturday and Sunday unless it is set, and days are in the Location, the local timezone if it isn't set.
+	//
+	// Example:
+	//
+	//	bot.Calendar = &slackbot.HolidayCalendar{
+	//		Holidays: []string{"01-01", "2021-05-31", "07-04", "2021-11-25", "12-25"},
+	//	}
+	HolidayCalendar struct {
+		Holidays []string
+		Weekend  []time.Weekday
+		Location *time.Location
+	}
+)
+
+// IsWeekend returns true if the day is one of the Weekend days.
+func (c *HolidayCalendar) IsWeekend(day time.Time) bool {
+	weekend := c.Weekend
+	if len(weekend) == 0 {
+		weekend = []time.Weekday{time.Saturday, time.Sunday}
+	}
+	d := c.in(day).Weekday()
+	for _, w := range weekend {
+		if w == d {
+			return true
+		}
+	}
+	return false
+}
+
+// IsHoliday returns true if the day is one of the Holidays.
+func (c *HolidayCalendar) IsHoliday(day time.Time) bool {
+	day = c.in(day)
+	date, annual := day.Format("2006-01-02"), day.Format("01-02")
+	for _, h := range c.Holidays {
+		if h == date || h == annual {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *HolidayCalendar) in(day time.Time) time.Time {
+	if c.Location != nil {
+		return day.In(c.Location)
+	}
+	return day.In(time.Local)
+}
+
+// calendar returns the bot's Calendar, or a HolidayCalendar without holidays if it isn't set.
+func (bot *Bot) calendar() Calendar {
+	if bot.Calendar != nil {
+		return bot.Calendar
+	}
+	return &HolidayCalendar{}
+}
+
+// skipDay returns true if a scheduled task shouldn't run on the day because it is a weekend and the task is
+// weekdaysOnly, or a holiday and the task skips holidays.
+func (bot *Bot) skipDay(name string, day time.Time, weekdaysOnly bool, skipHolidays bool) bool {
+	cal := bot.calendar()
+	switch {
+	case weekdaysOnly && cal.IsWeekend(day):
+		bot.LogDebug(fmt.Sprintf("skipping scheduled task %s, it only runs on weekdays", name))
+		return true
+	case skipHolidays && cal.IsHoliday(day):
+		bot.LogDebug(fmt.Sprintf("skipping scheduled task %s on a holiday", name))
+		return true
+	}
+	return false
+}
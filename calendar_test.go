@@ -0,0 +1,183 @@
+package slackbot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHolidayCalendar_IsWeekend(t *testing.T) {
+	// 2021-03-05 is a Friday.
+	tests := []struct {
+		name     string
+		calendar *HolidayCalendar
+		day      time.Time
+		want     bool
+	}{
+		{
+			name:     "should not be the weekend on a friday",
+			calendar: &HolidayCalendar{},
+			day:      time.Date(2021, 3, 5, 12, 0, 0, 0, time.Local),
+		},
+		{
+			name:     "should be the weekend on a saturday",
+			calendar: &HolidayCalendar{},
+			day:      time.Date(2021, 3, 6, 12, 0, 0, 0, time.Local),
+			want:     true,
+		},
+		{
+			name:     "should be the weekend on a sunday",
+			calendar: &HolidayCalendar{},
+			day:      time.Date(2021, 3, 7, 12, 0, 0, 0, time.Local),
+			want:     true,
+		},
+		{
+			name:     "should use the weekend days when they are set",
+			calendar: &HolidayCalendar{Weekend: []time.Weekday{time.Friday, time.Saturday}},
+			day:      time.Date(2021, 3, 5, 12, 0, 0, 0, time.Local),
+			want:     true,
+		},
+		{
+			name:     "should use the day in the location",
+			calendar: &HolidayCalendar{Location: time.FixedZone("UTC+10", 10*60*60)},
+			day:      time.Date(2021, 3, 5, 20, 0, 0, 0, time.UTC),
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.calendar.IsWeekend(tt.day); got != tt.want {
+				t.Errorf("IsWeekend() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHolidayCalendar_IsHoliday(t *testing.T) {
+	tests := []struct {
+		name     string
+		calendar *HolidayCalendar
+		day      time.Time
+		want     bool
+	}{
+		{
+			name:     "should not be a holiday without holidays",
+			calendar: &HolidayCalendar{},
+			day:      time.Date(2021, 12, 25, 12, 0, 0, 0, time.Local),
+		},
+		{
+			name:     "should be a holiday on the date",
+			calendar: &HolidayCalendar{Holidays: []string{"2021-11-25"}},
+			day:      time.Date(2021, 11, 25, 12, 0, 0, 0, time.Local),
+			want:     true,
+		},
+		{
+			name:     "should not be a holiday on the date in another year",
+			calendar: &HolidayCalendar{Holidays: []string{"2021-11-25"}},
+			day:      time.Date(2022, 11, 25, 12, 0, 0, 0, time.Local),
+		},
+		{
+			name:     "should be a holiday on an annual holiday every year",
+			calendar: &HolidayCalendar{Holidays: []string{"12-25"}},
+			day:      time.Date(2022, 12, 25, 12, 0, 0, 0, time.Local),
+			want:     true,
+		},
+		{
+			name:     "should use the day in the location",
+			calendar: &HolidayCalendar{Holidays: []string{"12-25"}, Location: time.FixedZone("UTC+10", 10*60*60)},
+			day:      time.Date(2021, 12, 24, 20, 0, 0, 0, time.UTC),
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.calendar.IsHoliday(tt.day); got != tt.want {
+				t.Errorf("IsHoliday() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_skipDay(t *testing.T) {
+	calendar := &HolidayCalendar{Holidays: []string{"2021-03-05"}, Location: time.UTC}
+	friday := time.Date(2021, 3, 5, 9, 0, 0, 0, time.UTC)
+	saturday := time.Date(2021, 3, 6, 9, 0, 0, 0, time.UTC)
+	monday := time.Date(2021, 3, 8, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		day          time.Time
+		weekdaysOnly bool
+		skipHolidays bool
+		want         bool
+	}{
+		{
+			name: "should not skip a weekend if the task isn't weekdays only",
+			day:  saturday,
+		},
+		{
+			name:         "should skip a weekend if the task is weekdays only",
+			day:          saturday,
+			weekdaysOnly: true,
+			want:         true,
+		},
+		{
+			name:         "should not skip a weekday if the task is weekdays only",
+			day:          monday,
+			weekdaysOnly: true,
+		},
+		{
+			name:         "should not skip a holiday if the task doesn't skip holidays",
+			day:          friday,
+			weekdaysOnly: true,
+		},
+		{
+			name:         "should skip a holiday if the task skips holidays",
+			day:          friday,
+			skipHolidays: true,
+			want:         true,
+		},
+		{
+			name:         "should not skip a day that isn't a holiday",
+			day:          monday,
+			skipHolidays: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{Calendar: calendar}
+			if got := bot.skipDay("standup", tt.day, tt.weekdaysOnly, tt.skipHolidays); got != tt.want {
+				t.Errorf("skipDay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskFuncWrapper_Run_skipsDays(t *testing.T) {
+	saturday := time.Date(2021, 3, 6, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		weekdaysOnly bool
+		wantRun      bool
+	}{
+		{
+			name:    "should run on the weekend",
+			wantRun: true,
+		},
+		{
+			name:         "should not run on the weekend if the task is weekdays only",
+			weekdaysOnly: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{
+				Clock:    fixedClock{Clock: RealClock, now: saturday},
+				Calendar: &HolidayCalendar{Location: time.UTC},
+			}
+			ran := false
+			taskFuncWrapper{bot: bot, name: "standup", weekdaysOnly: tt.weekdaysOnly, taskFunc: func(*Bot) { ran = true }}.Run()
+			if ran != tt.wantRun {
+				t.Errorf("Run() ran = %v, want %v", ran, tt.wantRun)
+			}
+		})
+	}
+}
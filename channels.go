@@ -0,0 +1,169 @@
+package slackbot
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+var slackIDRegex = regexp.MustCompile(`^[CGD][A-Z0-9]{6,}$`)
+
+// EnsureChannel returns the channel with the name, creating it if it doesn't exist or unarchiving it if it
+// has been archived, so workflows such as opening an incident channel can be run more than once.
+func (bot *Bot) EnsureChannel(name string) (*slack.Channel, error) {
+	name = strings.TrimPrefix(name, channelPrefix)
+	c, err := bot.findChannel(name)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		err = bot.withRetry(func() (err error) {
+			c, err = bot.api().CreateConversationContext(bot.context(), name, false)
+			return err
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to create channel %s", name)
+		}
+		return c, nil
+	}
+	if c.IsArchived {
+		err = bot.withRetry(func() error {
+			return bot.api().UnArchiveConversationContext(bot.context(), c.ID)
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to unarchive channel %s", name)
+		}
+		c.IsArchived = false
+	}
+	return c, nil
+}
+
+// InviteUsers invites the users to the channel. Channels and users can be identified by name or ID, and
+// usergroups can be passed to invite all of their members. Users already in the channel are ignored.
+func (bot *Bot) InviteUsers(channel string, users ...string) error {
+	channelID, err := bot.channelID(channel)
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for _, u := range users {
+		if user, err := bot.api().GetUser(u); err == nil {
+			ids = append(ids, user.ID)
+			continue
+		}
+		members, err := bot.UsersInGroup(u)
+		if err != nil {
+			return errors.Errorf("unable to find user or usergroup with identifier %s", u)
+		}
+		ids = append(ids, members...)
+	}
+	ids = unique(ids)
+	if bot.userDetails != nil {
+		ids = remove(ids, bot.userDetails.ID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	err = bot.withRetry(func() error {
+		_, err := bot.api().InviteUsersToConversationContext(bot.context(), channelID, ids...)
+		return err
+	})
+	if err != nil && err.Error() != "already_in_channel" {
+		return errors.Wrapf(err, "unable to invite users to %s", channel)
+	}
+	return nil
+}
+
+// SetTopic sets the topic of the channel, which can be identified by name or ID.
+func (bot *Bot) SetTopic(channel string, topic string) error {
+	channelID, err := bot.channelID(channel)
+	if err != nil {
+		return err
+	}
+	err = bot.withRetry(func() error {
+		_, err := bot.api().SetTopicOfConversationContext(bot.context(), channelID, topic)
+		return err
+	})
+	return errors.Wrapf(err, "unable to set the topic of %s", channel)
+}
+
+// SetPurpose sets the purpose of the channel, which can be identified by name or ID.
+func (bot *Bot) SetPurpose(channel string, purpose string) error {
+	channelID, err := bot.channelID(channel)
+	if err != nil {
+		return err
+	}
+	err = bot.withRetry(func() error {
+		_, err := bot.api().SetPurposeOfConversationContext(bot.context(), channelID, purpose)
+		return err
+	})
+	return errors.Wrapf(err, "unable to set the purpose of %s", channel)
+}
+
+// channelID returns the ID of the channel with the name, ID or mention. IDs of channels the client can't list,
+// such as private channels, are returned as they are.
+func (bot *Bot) channelID(identifier string) (string, error) {
+	if id, ok := mentionID(identifier, channelMentionPrefix); ok {
+		return id, nil
+	}
+	if c, err := bot.api().GetChannel(identifier); err == nil {
+		return c.ID, nil
+	}
+	if slackIDRegex.MatchString(identifier) {
+		return identifier, nil
+	}
+	return "", errors.Errorf("unable to find channel with identifier %s", identifier)
+}
+
+// findChannel returns the public or private channel with the name, including archived channels, or nil if
+// there isn't one.
+func (bot *Bot) findChannel(name string) (*slack.Channel, error) {
+	params := &slack.GetConversationsParameters{
+		Types: []string{"public_channel", "private_channel"},
+		Limit: 1000,
+	}
+	for {
+		var channels []slack.Channel
+		var cursor string
+		err := bot.withRetry(func() (err error) {
+			channels, cursor, err = bot.api().GetConversationsContext(bot.context(), params)
+			return err
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to list channels")
+		}
+		for i := range channels {
+			if channels[i].Name == name {
+				return &channels[i], nil
+			}
+		}
+		if cursor == "" {
+			return nil, nil
+		}
+		params.Cursor = cursor
+	}
+}
+
+func remove(values []string, value string) []string {
+	result := values[:0]
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func unique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var result []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
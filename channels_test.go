@@ -0,0 +1,210 @@
+package slackbot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func testChannel(id string, name string, archived bool) slack.Channel {
+	c := slack.Channel{}
+	c.ID = id
+	c.Name = name
+	c.IsArchived = archived
+	return c
+}
+
+func TestBot_EnsureChannel(t *testing.T) {
+	tests := []struct {
+		name           string
+		channel        string
+		wantID         string
+		wantCreated    bool
+		wantUnarchived bool
+		wantErr        bool
+	}{
+		{
+			name:    "should return an existing channel",
+			channel: "#general",
+			wantID:  "C1",
+		},
+		{
+			name:    "should find a channel on a later page",
+			channel: "inc-1233",
+			wantID:  "C3",
+		},
+		{
+			name:           "should unarchive an archived channel",
+			channel:        "inc-1232",
+			wantID:         "C2",
+			wantUnarchived: true,
+		},
+		{
+			name:        "should create a missing channel",
+			channel:     "inc-1234",
+			wantID:      "C4",
+			wantCreated: true,
+		},
+		{
+			name:    "should return an error if the channel can't be created",
+			channel: "Not Valid",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created, unarchived := false, false
+			bot := &Bot{
+				API: &mockAPI{
+					getConversations: func(params *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+						if params.Cursor == "" {
+							return []slack.Channel{testChannel("C1", "general", false), testChannel("C2", "inc-1232", true)}, "next", nil
+						}
+						return []slack.Channel{testChannel("C3", "inc-1233", false)}, "", nil
+					},
+					createConversation: func(name string, isPrivate bool) (*slack.Channel, error) {
+						if name == "Not Valid" {
+							return nil, errors.New("invalid_name_specials")
+						}
+						created = true
+						c := testChannel("C4", name, false)
+						return &c, nil
+					},
+					unArchiveConversation: func(channel string) error {
+						unarchived = true
+						return nil
+					},
+				},
+			}
+			got, err := bot.EnsureChannel(tt.channel)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("EnsureChannel() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && (got.ID != tt.wantID || got.IsArchived) {
+				t.Errorf("EnsureChannel() got = %v, want ID %v", got, tt.wantID)
+			}
+			if created != tt.wantCreated || unarchived != tt.wantUnarchived {
+				t.Errorf("EnsureChannel() created = %v, unarchived = %v, want %v, %v", created, unarchived, tt.wantCreated, tt.wantUnarchived)
+			}
+		})
+	}
+}
+
+func TestBot_InviteUsers(t *testing.T) {
+	tests := []struct {
+		name        string
+		channel     string
+		users       []string
+		inviteErr   error
+		wantChannel string
+		wantUsers   []string
+		wantErr     bool
+	}{
+		{
+			name:        "should resolve the channel and users",
+			channel:     "#general",
+			users:       []string{"@alice", "U2"},
+			wantChannel: "C1",
+			wantUsers:   []string{"U1", "U2"},
+		},
+		{
+			name:        "should invite the members of usergroups once",
+			channel:     "GPRIVATE1",
+			users:       []string{"@alice", "@oncall"},
+			wantChannel: "GPRIVATE1",
+			wantUsers:   []string{"U1", "U2"},
+		},
+		{
+			name:        "should ignore users who are already in the channel",
+			channel:     "general",
+			users:       []string{"U2"},
+			inviteErr:   errors.New("already_in_channel"),
+			wantChannel: "C1",
+			wantUsers:   []string{"U2"},
+		},
+		{
+			name:    "should return an error if a user can't be found",
+			channel: "general",
+			users:   []string{"@nobody"},
+			wantErr: true,
+		},
+		{
+			name:    "should return an error if the channel can't be found",
+			channel: "random",
+			users:   []string{"U2"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var channel string
+			var users []string
+			bot := &Bot{
+				API: &mockAPI{
+					getChannel: func(s string) (slack.Channel, error) {
+						if s == "general" || s == "#general" {
+							return testChannel("C1", "general", false), nil
+						}
+						return slack.Channel{}, errors.New("not found")
+					},
+					getUser: func(s string) (slack.User, error) {
+						switch s {
+						case "@alice":
+							return slack.User{ID: "U1"}, nil
+						case "U2":
+							return slack.User{ID: "U2"}, nil
+						}
+						return slack.User{}, errors.New("not found")
+					},
+					getUserGroups: func(opts ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+						return testUserGroups(), nil
+					},
+					inviteUsers: func(c string, u ...string) (*slack.Channel, error) {
+						channel, users = c, u
+						return nil, tt.inviteErr
+					},
+				},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			err := bot.InviteUsers(tt.channel, tt.users...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("InviteUsers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if channel != tt.wantChannel || !reflect.DeepEqual(users, tt.wantUsers) {
+				t.Errorf("InviteUsers() invited %v to %v, want %v to %v", users, channel, tt.wantUsers, tt.wantChannel)
+			}
+		})
+	}
+}
+
+func TestBot_SetTopic(t *testing.T) {
+	var got []string
+	bot := &Bot{
+		API: &mockAPI{
+			getChannel: func(s string) (slack.Channel, error) {
+				return testChannel("C1", "inc-1234", false), nil
+			},
+			setTopic: func(c string, topic string) (*slack.Channel, error) {
+				got = append(got, c, topic)
+				return nil, nil
+			},
+			setPurpose: func(c string, purpose string) (*slack.Channel, error) {
+				got = append(got, c, purpose)
+				return nil, errors.New("too_long")
+			},
+		},
+	}
+	if err := bot.SetTopic("#inc-1234", "Investigating"); err != nil {
+		t.Errorf("SetTopic() error = %v", err)
+	}
+	if err := bot.SetPurpose("#inc-1234", "Database outage"); err == nil {
+		t.Errorf("SetPurpose() error = nil, want an error")
+	}
+	if want := []string{"C1", "Investigating", "C1", "Database outage"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SetTopic() and SetPurpose() got = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,133 @@
+package slackbot
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+var circuitBreakerRegex = regexp.MustCompile(`^(?i)circuit breaker(?: (reset))?$`)
+
+// ErrCircuitBreakerTripped is returned instead of sending a message after the bot's CircuitBreaker has tripped,
+// until it is reset.
+var ErrCircuitBreakerTripped = errors.New("the circuit breaker has tripped")
+
+// CircuitBreakerState is a snapshot of a CircuitBreaker returned by State.
+type CircuitBreakerState struct {
+	// Count is the number of messages sent in the current interval.
+	Count int
+	// IntervalStart is when the current interval started, it is zero if no messages have been sent.
+	IntervalStart time.Time
+	// Remaining is the number of messages that can be sent before the breaker trips.
+	Remaining int
+	// Tripped is true if the breaker has tripped.
+	Tripped bool
+}
+
+// State returns a snapshot of the circuit breaker.
+func (cb *CircuitBreaker) State() CircuitBreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return CircuitBreakerState{
+		Count:         cb.currentCount(),
+		IntervalStart: cb.intervalStart,
+		Remaining:     cb.remaining(),
+		Tripped:       cb.tripped,
+	}
+}
+
+// Remaining returns the number of messages that can be sent in the current interval before the breaker trips.
+func (cb *CircuitBreaker) Remaining() int {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.remaining()
+}
+
+// Reset clears the messages counted in the current interval and the tripped state.
+func (cb *CircuitBreaker) Reset() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.count = 0
+	cb.intervalStart = time.Time{}
+	cb.tripped = false
+}
+
+// CircuitBreakerListener returns a DirectListener for admins that replies with the state of the bot's circuit
+// breaker when the bot is messaged "circuit breaker", and resets it for "circuit breaker reset". Only the users
+// and channels allowed by the admins ACL can use it. It is not enabled by default, add it to the bot's
+// DirectListeners to enable it.
+func CircuitBreakerListener(admins *ACL) Listener {
+	return Listener{
+		Usage: "circuit breaker [reset]",
+		Regex: circuitBreakerRegex,
+		ACL:   admins,
+		Handler: func(bot *Bot, ev *slack.MessageEvent) {
+			cb := bot.CircuitBreaker
+			var msg string
+			switch {
+			case cb == nil:
+				msg = "The circuit breaker is not enabled"
+			case circuitBreakerRegex.FindStringSubmatch(ev.Text)[1] != "":
+				cb.Reset()
+				msg = "The circuit breaker was reset"
+			default:
+				s := cb.State()
+				msg = fmt.Sprintf("The circuit breaker has counted %d messages in the current %s interval, %d remaining before it trips",
+					s.Count, cb.TimeInterval, s.Remaining)
+			}
+			_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), msg)
+		},
+	}
+}
+
+// record counts a message and returns true for the message that trips the breaker. Once it has tripped no more
+// messages are counted and ErrCircuitBreakerTripped is returned until it is reset.
+func (cb *CircuitBreaker) record() (bool, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.tripped {
+		return false, ErrCircuitBreakerTripped
+	}
+	cb.count++
+	if cb.intervalExpired() {
+		cb.intervalStart = cb.now()
+		cb.count = 1
+		return false, nil
+	}
+	if cb.count > cb.MaxMessages {
+		cb.tripped = true
+		return true, nil
+	}
+	return false, nil
+}
+
+// intervalExpired must be called while holding the lock.
+func (cb *CircuitBreaker) intervalExpired() bool {
+	return cb.intervalStart.Before(cb.now().Add(-cb.TimeInterval))
+}
+
+func (cb *CircuitBreaker) now() time.Time {
+	if cb.clock == nil {
+		return time.Now()
+	}
+	return cb.clock.Now()
+}
+
+// currentCount must be called while holding the lock.
+func (cb *CircuitBreaker) currentCount() int {
+	if cb.intervalExpired() {
+		return 0
+	}
+	return cb.count
+}
+
+// remaining must be called while holding the lock.
+func (cb *CircuitBreaker) remaining() int {
+	if r := cb.MaxMessages - cb.currentCount(); r > 0 {
+		return r
+	}
+	return 0
+}
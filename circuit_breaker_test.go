@@ -0,0 +1,164 @@
+package slackbot
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestCircuitBreaker_State(t *testing.T) {
+	tests := []struct {
+		name          string
+		sent          int
+		reset         bool
+		wantCount     int
+		wantRemaining int
+		wantTripped   bool
+	}{
+		{
+			name:          "should have all messages remaining before any are sent",
+			wantRemaining: 5,
+		},
+		{
+			name:          "should count sent messages",
+			sent:          3,
+			wantCount:     3,
+			wantRemaining: 2,
+		},
+		{
+			name:        "should trip after the max messages",
+			sent:        6,
+			wantCount:   6,
+			wantTripped: true,
+		},
+		{
+			name:          "should clear the count and tripped state on reset",
+			sent:          6,
+			reset:         true,
+			wantRemaining: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb := &CircuitBreaker{MaxMessages: 5, TimeInterval: time.Minute}
+			for i := 0; i < tt.sent; i++ {
+				cb.record()
+			}
+			if tt.reset {
+				cb.Reset()
+			}
+			s := cb.State()
+			if s.Count != tt.wantCount || s.Remaining != tt.wantRemaining || s.Tripped != tt.wantTripped {
+				t.Errorf("State() = %+v, want count %d, remaining %d, tripped %v", s, tt.wantCount, tt.wantRemaining, tt.wantTripped)
+			}
+			if got := cb.Remaining(); got != tt.wantRemaining {
+				t.Errorf("Remaining() = %v, want %v", got, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestCircuitBreaker_record_concurrent(t *testing.T) {
+	cb := &CircuitBreaker{MaxMessages: 1000, TimeInterval: time.Minute}
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cb.record()
+		}()
+	}
+	wg.Wait()
+	if got := cb.State().Count; got != 100 {
+		t.Errorf("State().Count = %v, want 100", got)
+	}
+}
+
+func TestBot_Reply_circuitBreakerTripped(t *testing.T) {
+	var sent []string
+	fatal := 0
+	bot := &Bot{
+		CircuitBreaker: &CircuitBreaker{MaxMessages: 2, TimeInterval: time.Minute},
+		OnFatal:        func(error) { fatal++ },
+		API: &mockAPI{
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				sent = append(sent, msgValues(opts...).Get("text"))
+				return s, "ts", nil
+			},
+		},
+	}
+	for i := 0; i < 5; i++ {
+		_, _, err := bot.Reply("C1", "hello")
+		if i >= 2 && err != ErrCircuitBreakerTripped {
+			t.Errorf("Reply() %d error = %v, want %v", i, err, ErrCircuitBreakerTripped)
+		}
+	}
+	if len(sent) != 3 || sent[2] == "hello" {
+		t.Errorf("sent = %q, want 2 messages and the breaker notice", sent)
+	}
+	if fatal != 1 {
+		t.Errorf("OnFatal called %d times, want 1", fatal)
+	}
+}
+
+func TestCircuitBreakerListener(t *testing.T) {
+	tests := []struct {
+		name    string
+		breaker *CircuitBreaker
+		user    string
+		text    string
+		want    string
+	}{
+		{
+			name: "should say when the breaker is not enabled",
+			text: "circuit breaker",
+			want: "The circuit breaker is not enabled",
+		},
+		{
+			name:    "should reply with the state",
+			breaker: &CircuitBreaker{MaxMessages: 5, TimeInterval: time.Minute},
+			text:    "circuit breaker",
+			want:    "The circuit breaker has counted 0 messages in the current 1m0s interval, 5 remaining before it trips",
+		},
+		{
+			name:    "should reset the breaker",
+			breaker: &CircuitBreaker{MaxMessages: 5, TimeInterval: time.Minute},
+			text:    "circuit breaker reset",
+			want:    "The circuit breaker was reset",
+		},
+		{
+			name:    "should not let other users reset the breaker",
+			breaker: &CircuitBreaker{MaxMessages: 5, TimeInterval: time.Minute},
+			user:    "U2",
+			text:    "circuit breaker reset",
+			want:    aclUserDeniedMessage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			bot := &Bot{
+				CircuitBreaker: tt.breaker,
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						got = msgValues(opts...).Get("text")
+						return s, "ts", nil
+					},
+				},
+				DirectListeners: []Listener{CircuitBreakerListener(&ACL{Users: []string{"U1"}})},
+				userDetails:     &slack.UserDetails{ID: "bot"},
+			}
+			bot.init()
+			user := tt.user
+			if user == "" {
+				user = "U1"
+			}
+			bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: user, Text: tt.text, Timestamp: "1.1"}})
+			if got != tt.want {
+				t.Errorf("reply = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
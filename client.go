@@ -2,20 +2,32 @@ package slackbot
 
 import (
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/slack-go/slack"
 )
 
 const (
-	channelPrefix = "#"
-	userPrefix    = "@"
+	channelPrefix        = "#"
+	userPrefix           = "@"
+	channelMentionPrefix = "<#"
+	userMentionPrefix    = "<@"
 )
 
+// slackClient is the MessagingClient used when the bot's API is not set. The RTM's events are forwarded to the
+// bot so the info from each connection can be kept behind a lock, the RTM itself writes it without one.
 type slackClient struct {
 	*slack.RTM
 	getChannels func(bool, ...slack.GetChannelsOption) ([]slack.Channel, error)
 	getUsers    func() ([]slack.User, error)
+
+	mu         sync.Mutex
+	info       *slack.Info
+	events     chan slack.RTMEvent
+	done       chan struct{}
+	forwarding bool
+	closed     bool
 }
 
 func (s *slackClient) GetChannel(identifier string) (slack.Channel, error) {
@@ -23,7 +35,7 @@ func (s *slackClient) GetChannel(identifier string) (slack.Channel, error) {
 	if err != nil {
 		return slack.Channel{}, err
 	}
-	i := strings.TrimPrefix(identifier, channelPrefix)
+	i := channelIdentifier(identifier)
 	for _, c := range channels {
 		if c.Name == i || c.ID == i {
 			return c, nil
@@ -37,27 +49,144 @@ func (s *slackClient) GetUser(identifier string) (slack.User, error) {
 	if err != nil {
 		return slack.User{}, err
 	}
-	i := strings.TrimPrefix(identifier, userPrefix)
-	for _, u := range users {
-		if u.Name == i || u.ID == i || u.RealName == i {
-			return u, nil
-		}
+	u, ok, err := matchUser(users, userIdentifier(identifier))
+	if err != nil || ok {
+		return u, err
 	}
 	return slack.User{}, errors.Errorf("unable to find user with identifier %s", identifier)
 }
 
 func (s *slackClient) GetIncomingEvents() chan slack.RTMEvent {
-	return s.RTM.IncomingEvents
+	return s.events
+}
+
+// GetInfo returns the info from the most recent connection, or nil before the client has connected.
+func (s *slackClient) GetInfo() *slack.Info {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.info
+}
+
+// ManageConnection connects to slack and reconnects when the connection is lost, until Disconnect is called.
+func (s *slackClient) ManageConnection() {
+	s.mu.Lock()
+	if !s.forwarding {
+		s.forwarding = true
+		go s.forward()
+	}
+	s.mu.Unlock()
+	s.RTM.ManageConnection()
+}
+
+// Disconnect closes the connection to slack and stops forwarding its events.
+func (s *slackClient) Disconnect() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+	}
+	s.mu.Unlock()
+	return s.RTM.Disconnect()
+}
+
+// forward sends the RTM's events on to the bot, saving the info of each connection.
+func (s *slackClient) forward() {
+	for {
+		select {
+		case ev := <-s.RTM.IncomingEvents:
+			if connected, ok := ev.Data.(*slack.ConnectedEvent); ok {
+				s.mu.Lock()
+				s.info = connected.Info
+				s.mu.Unlock()
+			}
+			select {
+			case s.events <- ev:
+			case <-s.done:
+				return
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// NewClient returns the slack client the bot uses when API is not set. Options are passed to the
+// underlying slack client, for example slack.OptionAPIURL can be used to connect to a different server.
+func NewClient(token string, options ...slack.Option) MessagingClient {
+	return newSlackClient(token, options...)
 }
 
-func newSlackClient(token string) *slackClient {
-	api := slack.New(token)
+func newSlackClient(token string, options ...slack.Option) *slackClient {
+	api := slack.New(token, options...)
+	rtm := api.NewRTM()
 	c := &slackClient{
-		api.NewRTM(),
-		nil,
-		nil,
+		RTM:    rtm,
+		events: make(chan slack.RTMEvent, cap(rtm.IncomingEvents)),
+		done:   make(chan struct{}),
 	}
 	c.getChannels = c.GetChannels
 	c.getUsers = c.GetUsers
 	return c
 }
+
+// channelIdentifier returns the ID from a channel mention such as <#C12345|general> or <#C12345>, or the
+// identifier without its # prefix.
+func channelIdentifier(identifier string) string {
+	if id, ok := mentionID(identifier, channelMentionPrefix); ok {
+		return id
+	}
+	return strings.TrimPrefix(strings.TrimSpace(identifier), channelPrefix)
+}
+
+// userIdentifier returns the ID from a user mention such as <@U12345> or <@U12345|bob>, or the identifier
+// without its @ prefix.
+func userIdentifier(identifier string) string {
+	if id, ok := mentionID(identifier, userMentionPrefix); ok {
+		return id
+	}
+	return strings.TrimPrefix(strings.TrimSpace(identifier), userPrefix)
+}
+
+// mentionID returns the ID from a mention with the prefix, the way slack escapes them in message text.
+func mentionID(text string, prefix string) (string, bool) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, prefix) || !strings.HasSuffix(text, ">") {
+		return "", false
+	}
+	id := strings.SplitN(strings.TrimSuffix(strings.TrimPrefix(text, prefix), ">"), "|", 2)[0]
+	return id, id != ""
+}
+
+// matchUser returns the user whose ID or name is the identifier, or otherwise the one user whose real name,
+// or display name or real name in their profile, is the identifier. Several people can share a display or
+// real name, so an error is returned if more than one user has it instead of picking one of them.
+func matchUser(users []slack.User, identifier string) (slack.User, bool, error) {
+	if identifier == "" {
+		return slack.User{}, false, nil
+	}
+	var matches []slack.User
+	for _, u := range users {
+		if u.ID == identifier || u.Name == identifier {
+			return u, true, nil
+		}
+		for _, v := range []string{
+			u.RealName,
+			u.Profile.DisplayName,
+			u.Profile.DisplayNameNormalized,
+			u.Profile.RealName,
+			u.Profile.RealNameNormalized,
+		} {
+			if v == identifier {
+				matches = append(matches, u)
+				break
+			}
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return slack.User{}, false, nil
+	case 1:
+		return matches[0], true, nil
+	}
+	return slack.User{}, false, errors.Errorf("%d users are called %s, use their ID or username", len(matches), identifier)
+}
@@ -100,6 +100,31 @@ func Test_slackClient_GetChannel(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "should return the channel of a channel mention",
+			fields: fields{
+				getChannels: func(b bool, option ...slack.GetChannelsOption) ([]slack.Channel, error) {
+					return []slack.Channel{
+						{
+							GroupConversation: slack.GroupConversation{
+								Conversation: slack.Conversation{ID: "C12345"},
+								Name:         "general",
+							},
+						},
+					}, nil
+				},
+			},
+			args: args{
+				identifier: "<#C12345|general>",
+			},
+			want: slack.Channel{
+				GroupConversation: slack.GroupConversation{
+					Conversation: slack.Conversation{ID: "C12345"},
+					Name:         "general",
+				},
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -228,6 +253,115 @@ func Test_slackClient_GetUser(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "should get a user on display name match with @ in identifier",
+			fields: fields{
+				getUsers: func() ([]slack.User, error) {
+					return []slack.User{
+						{
+							ID:       "not_match",
+							Name:     "not_match",
+							RealName: "Not Match",
+							Profile:  slack.UserProfile{DisplayName: "should_match"},
+						},
+					}, nil
+				},
+			},
+			args: args{
+				identifier: "@should_match",
+			},
+			want: slack.User{
+				RealName: "Not Match",
+				Name:     "not_match",
+				ID:       "not_match",
+				Profile:  slack.UserProfile{DisplayName: "should_match"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "should get a user from a user mention",
+			fields: fields{
+				getUsers: func() ([]slack.User, error) {
+					return []slack.User{
+						{
+							ID:   "U12345",
+							Name: "not_match",
+						},
+					}, nil
+				},
+			},
+			args: args{
+				identifier: "<@U12345|bob>",
+			},
+			want: slack.User{
+				ID:   "U12345",
+				Name: "not_match",
+			},
+			wantErr: false,
+		},
+		{
+			name: "should get a user on normalized display name match",
+			fields: fields{
+				getUsers: func() ([]slack.User, error) {
+					return []slack.User{
+						{
+							ID:      "not_match",
+							Name:    "not_match",
+							Profile: slack.UserProfile{DisplayName: "Zoë", DisplayNameNormalized: "Zoe"},
+						},
+					}, nil
+				},
+			},
+			args: args{
+				identifier: "@Zoe",
+			},
+			want: slack.User{
+				ID:      "not_match",
+				Name:    "not_match",
+				Profile: slack.UserProfile{DisplayName: "Zoë", DisplayNameNormalized: "Zoe"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "should get a user on profile real name match",
+			fields: fields{
+				getUsers: func() ([]slack.User, error) {
+					return []slack.User{
+						{
+							ID:      "not_match",
+							Name:    "not_match",
+							Profile: slack.UserProfile{RealName: "Should Match"},
+						},
+					}, nil
+				},
+			},
+			args: args{
+				identifier: "Should Match",
+			},
+			want: slack.User{
+				ID:      "not_match",
+				Name:    "not_match",
+				Profile: slack.UserProfile{RealName: "Should Match"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "should not match users without a display name to an empty identifier",
+			fields: fields{
+				getUsers: func() ([]slack.User, error) {
+					return []slack.User{
+						{
+							ID:   "not_match",
+							Name: "not_match",
+						},
+					}, nil
+				},
+			},
+			args: args{
+				identifier: "@",
+			},
+			wantErr: true,
+		},
 		{
 			name: "should return error if no user found",
 			fields: fields{
@@ -258,6 +392,36 @@ func Test_slackClient_GetUser(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "should not pick one of several users with the same real name",
+			fields: fields{
+				getUsers: func() ([]slack.User, error) {
+					return []slack.User{
+						{ID: "U1", Name: "sam.lee", RealName: "Sam Lee"},
+						{ID: "U2", Name: "sam.lee2", RealName: "Sam Lee"},
+					}, nil
+				},
+			},
+			args: args{
+				identifier: "Sam Lee",
+			},
+			wantErr: true,
+		},
+		{
+			name: "should prefer a username to another user's real name",
+			fields: fields{
+				getUsers: func() ([]slack.User, error) {
+					return []slack.User{
+						{ID: "U1", Name: "other", RealName: "sam"},
+						{ID: "U2", Name: "sam", RealName: "Sam Lee"},
+					}, nil
+				},
+			},
+			args: args{
+				identifier: "sam",
+			},
+			want: slack.User{ID: "U2", Name: "sam", RealName: "Sam Lee"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -277,3 +441,56 @@ func Test_slackClient_GetUser(t *testing.T) {
 		})
 	}
 }
+
+func Test_mentionID(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		prefix string
+		want   string
+		wantOk bool
+	}{
+		{
+			name:   "should return the ID of a channel mention with a name",
+			text:   "<#C12345|general>",
+			prefix: channelMentionPrefix,
+			want:   "C12345",
+			wantOk: true,
+		},
+		{
+			name:   "should return the ID of a user mention",
+			text:   " <@U12345> ",
+			prefix: userMentionPrefix,
+			want:   "U12345",
+			wantOk: true,
+		},
+		{
+			name:   "should not return the ID of a mention with another prefix",
+			text:   "<@U12345>",
+			prefix: channelMentionPrefix,
+		},
+		{
+			name:   "should not return an ID for a name",
+			text:   "#general",
+			prefix: channelMentionPrefix,
+		},
+		{
+			name:   "should not return an empty ID",
+			text:   "<#|general>",
+			prefix: channelMentionPrefix,
+		},
+		{
+			name:   "should not return the ID of an empty mention",
+			text:   "<@>",
+			prefix: userMentionPrefix,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := mentionID(tt.text, tt.prefix)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("mentionID() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
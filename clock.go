@@ -0,0 +1,92 @@
+package slackbot
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type (
+	// Clock is the bot's source of time. It is used for the CircuitBreaker and FloodGuard intervals, loop
+	// detection, retry backoff, listener and step timeouts, scheduled task jitter, confirmations, digests,
+	// incidents, welcome limits, cache expiry, dead letter and job times, the SendQueue, onboarding, state
+	// TTLs, do not disturb checks, usage stats, reminders, scheduled messages and ParseWhenFor, so
+	// time-dependent behavior can be tested without sleeping by setting the bot's Clock to a fake such as
+	// slackbottest.FakeClock. The cron schedules of scheduled tasks and ParseWhen, which has no bot, always use
+	// the real time.
+	Clock interface {
+		Now() time.Time
+		Sleep(d time.Duration)
+		After(d time.Duration) <-chan time.Time
+		AfterFunc(d time.Duration, f func()) Timer
+	}
+
+	// Timer is a timer created by a Clock's AfterFunc, Stop prevents it from firing and returns false if it
+	// has already fired or been stopped.
+	Timer interface {
+		Stop() bool
+	}
+
+	realClock struct{}
+
+	// deadlineContext is cancelled by a Clock when its deadline passes, and reports DeadlineExceeded like a
+	// context created with context.WithDeadline.
+	deadlineContext struct {
+		context.Context
+		deadline time.Time
+
+		mu       sync.Mutex
+		exceeded bool
+	}
+)
+
+// RealClock is the Clock used by bots that don't set one, it uses the time package.
+var RealClock Clock = realClock{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// clock returns the bot's Clock, or the RealClock if it isn't set.
+func (bot *Bot) clock() Clock {
+	if bot.Clock != nil {
+		return bot.Clock
+	}
+	return RealClock
+}
+
+// withClockTimeout returns a context that is cancelled when the clock reaches the timeout, the returned
+// function must be called to release its timer.
+func withClockTimeout(parent context.Context, clock Clock, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if clock == RealClock {
+		return context.WithTimeout(parent, timeout)
+	}
+	inner, cancel := context.WithCancel(parent)
+	ctx := &deadlineContext{Context: inner, deadline: clock.Now().Add(timeout)}
+	timer := clock.AfterFunc(timeout, func() {
+		ctx.mu.Lock()
+		ctx.exceeded = inner.Err() == nil
+		ctx.mu.Unlock()
+		cancel()
+	})
+	return ctx, func() {
+		timer.Stop()
+		cancel()
+	}
+}
+
+func (ctx *deadlineContext) Deadline() (time.Time, bool) {
+	return ctx.deadline, true
+}
+
+func (ctx *deadlineContext) Err() error {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	if ctx.exceeded {
+		return context.DeadlineExceeded
+	}
+	return ctx.Context.Err()
+}
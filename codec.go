@@ -0,0 +1,94 @@
+package slackbot
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+var (
+	// GobCodec encodes values with encoding/gob, it is the default codec for the stores in this package.
+	GobCodec Codec = gobCodec{}
+	// JSONCodec encodes values as JSON, so stored data can be inspected and shared with services that
+	// aren't written in Go.
+	JSONCodec Codec = jsonCodec{}
+)
+
+type (
+	// Codec encodes and decodes the values saved in a Store. Other formats such as MessagePack can be used
+	// by implementing it with a library for the format.
+	Codec interface {
+		Marshal(value interface{}) ([]byte, error)
+		Unmarshal(data []byte, value interface{}) error
+	}
+
+	gobCodec  struct{}
+	jsonCodec struct{}
+)
+
+func (gobCodec) Marshal(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, value interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
+
+func (jsonCodec) Marshal(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (jsonCodec) Unmarshal(data []byte, value interface{}) error {
+	return json.Unmarshal(data, value)
+}
+
+// codecOrDefault returns the codec, or the GobCodec if it is nil.
+func codecOrDefault(codec Codec) Codec {
+	if codec == nil {
+		return GobCodec
+	}
+	return codec
+}
+
+// encodeValues encodes the values of a store with the codec. JSON values are embedded as they are instead of
+// as byte strings, so a JSON file can be read without decoding each value.
+func encodeValues(codec Codec, values SimpleStore) ([]byte, error) {
+	if _, ok := codec.(jsonCodec); ok {
+		raw := make(map[string]json.RawMessage, len(values))
+		for k, v := range values {
+			raw[k] = v
+		}
+		return json.MarshalIndent(raw, "", "  ")
+	}
+	return codec.Marshal(map[string][]byte(values))
+}
+
+// decodeValues decodes values encoded with encodeValues.
+func decodeValues(codec Codec, data []byte) (SimpleStore, error) {
+	values := SimpleStore{}
+	if len(data) == 0 {
+		return values, nil
+	}
+	if _, ok := codec.(jsonCodec); ok {
+		raw := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+		for k, v := range raw {
+			values[k] = v
+		}
+		return values, nil
+	}
+	raw := make(map[string][]byte)
+	if err := codec.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	for k, v := range raw {
+		values[k] = v
+	}
+	return values, nil
+}
@@ -0,0 +1,146 @@
+package slackbot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	defaultConfirmationTimeout = time.Minute
+	confirmActionID            = "slackbot_confirm"
+	cancelActionID             = "slackbot_cancel"
+	confirmPromptMessage       = "Are you sure? Reply *yes* to continue or *no* to cancel."
+	confirmButton              = "Yes, continue"
+	cancelButton               = "Cancel"
+	confirmCancelledMessage    = "Cancelled."
+	confirmTimeoutMessage      = "Cancelled, there was no confirmation within %s."
+)
+
+// pendingConfirmation is a destructive listener waiting for the user who triggered it to confirm.
+type pendingConfirmation struct {
+	listener Listener
+	ev       *slack.MessageEvent
+	thread   string
+	timer    Timer
+}
+
+// confirm asks the user to confirm the destructive listener in the event's thread. The listener runs if they
+// reply yes, or click the button if the bot is Interactive, within the ConfirmationTimeout. Slash commands
+// aren't messages that can be replied to, so their prompt is sent on its own, without buttons, and
+// answered in its thread.
+func (bot *Bot) confirm(l *Listener, ev *slack.MessageEvent) {
+	thread := threadTimestamp(ev)
+	if thread == "" {
+		_, ts, err := bot.Reply(ev.Channel, confirmPromptMessage)
+		if ts == "" {
+			bot.LogError(fmt.Sprintf("unable to ask for confirmation in %s - %v", ev.Channel, err))
+			return
+		}
+		bot.pendConfirmation(l, ev, ts)
+		return
+	}
+	key := bot.pendConfirmation(l, ev, thread)
+
+	options := []slack.MsgOption{slack.MsgOptionText(confirmPromptMessage, false), slack.MsgOptionTS(thread)}
+	if bot.Interactive {
+		confirm := slack.NewButtonBlockElement(confirmActionID, key, slack.NewTextBlockObject(slack.PlainTextType, confirmButton, false, false))
+		confirm.Style = slack.StyleDanger
+		cancel := slack.NewButtonBlockElement(cancelActionID, key, slack.NewTextBlockObject(slack.PlainTextType, cancelButton, false, false))
+		options = append(options, slack.MsgOptionBlocks(
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, confirmPromptMessage, false, false), nil, nil),
+			slack.NewActionBlock("", confirm, cancel),
+		))
+	}
+	_, _, _ = bot.ReplyWithOptions(ev.Channel, options...)
+}
+
+// pendConfirmation waits for the user to confirm the listener in the thread until the ConfirmationTimeout,
+// replacing any confirmation they had pending there, and returns its key.
+func (bot *Bot) pendConfirmation(l *Listener, ev *slack.MessageEvent, thread string) string {
+	key := confirmationKey(ev.Channel, thread, ev.User)
+	timeout := bot.ConfirmationTimeout
+	if timeout <= 0 {
+		timeout = defaultConfirmationTimeout
+	}
+	pc := &pendingConfirmation{listener: *l, ev: ev, thread: thread}
+
+	bot.confirmMu.Lock()
+	defer bot.confirmMu.Unlock()
+	if bot.confirmations == nil {
+		bot.confirmations = make(map[string]*pendingConfirmation)
+	}
+	if previous, ok := bot.confirmations[key]; ok {
+		previous.timer.Stop()
+	}
+	bot.confirmations[key] = pc
+	pc.timer = bot.clock().AfterFunc(timeout, func() {
+		if bot.takeConfirmation(key, pc) {
+			_, _, _ = bot.ReplyInThread(ev.Channel, thread, fmt.Sprintf(confirmTimeoutMessage, timeout))
+		}
+	})
+	return key
+}
+
+// confirmationFor returns the confirmation the event's user has pending in the event's thread, if there is one.
+func (bot *Bot) confirmationFor(ev *slack.MessageEvent) (string, *pendingConfirmation) {
+	if ev.ThreadTimestamp == "" {
+		return "", nil
+	}
+	key := confirmationKey(ev.Channel, ev.ThreadTimestamp, ev.User)
+	bot.confirmMu.Lock()
+	defer bot.confirmMu.Unlock()
+	return key, bot.confirmations[key]
+}
+
+// answerConfirmationReply runs or cancels the listener if the reply is yes or no, otherwise it asks again.
+func (bot *Bot) answerConfirmationReply(key string, pc *pendingConfirmation, ev *slack.MessageEvent) {
+	switch strings.ToLower(strings.TrimSpace(ev.Text)) {
+	case "yes", "y":
+		bot.answerConfirmation(key, pc, true)
+	case "no", "n", "cancel":
+		bot.answerConfirmation(key, pc, false)
+	default:
+		_, _, _ = bot.ReplyInThread(ev.Channel, ev.ThreadTimestamp, confirmPromptMessage)
+	}
+}
+
+// handleConfirmAction answers a confirmation when one of its buttons is clicked by the user who triggered it.
+func (bot *Bot) handleConfirmAction(callback *slack.InteractionCallback, action *slack.BlockAction) {
+	bot.confirmMu.Lock()
+	pc := bot.confirmations[action.Value]
+	bot.confirmMu.Unlock()
+	if pc == nil || pc.ev.User != callback.User.ID {
+		return
+	}
+	go bot.answerConfirmation(action.Value, pc, action.ActionID == confirmActionID)
+}
+
+func (bot *Bot) answerConfirmation(key string, pc *pendingConfirmation, confirmed bool) {
+	if !bot.takeConfirmation(key, pc) {
+		return
+	}
+	pc.timer.Stop()
+	if !confirmed {
+		_, _, _ = bot.ReplyInThread(pc.ev.Channel, pc.thread, confirmCancelledMessage)
+		return
+	}
+	bot.execListener(&pc.listener, pc.ev, false)
+}
+
+// takeConfirmation removes the pending confirmation, it returns false if it was already answered or timed out.
+func (bot *Bot) takeConfirmation(key string, pc *pendingConfirmation) bool {
+	bot.confirmMu.Lock()
+	defer bot.confirmMu.Unlock()
+	if bot.confirmations[key] != pc {
+		return false
+	}
+	delete(bot.confirmations, key)
+	return true
+}
+
+func confirmationKey(channel string, thread string, user string) string {
+	return channel + " " + thread + " " + user
+}
@@ -0,0 +1,218 @@
+package slackbot
+
+import (
+	"net/url"
+	"reflect"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_confirm(t *testing.T) {
+	tests := []struct {
+		name        string
+		replies     []slack.Msg
+		wantRan     bool
+		wantReplies []string
+	}{
+		{
+			name:        "should run the listener when the user replies yes",
+			replies:     []slack.Msg{{User: "U1", Text: "yes", ThreadTimestamp: "1.1"}},
+			wantRan:     true,
+			wantReplies: []string{confirmPromptMessage},
+		},
+		{
+			name:        "should cancel the listener when the user replies no",
+			replies:     []slack.Msg{{User: "U1", Text: "no", ThreadTimestamp: "1.1"}},
+			wantReplies: []string{confirmPromptMessage, confirmCancelledMessage},
+		},
+		{
+			name: "should ask again when the reply isn't yes or no",
+			replies: []slack.Msg{
+				{User: "U1", Text: "maybe", ThreadTimestamp: "1.1"},
+				{User: "U1", Text: "Y", ThreadTimestamp: "1.1"},
+			},
+			wantRan:     true,
+			wantReplies: []string{confirmPromptMessage, confirmPromptMessage},
+		},
+		{
+			name:        "should ignore replies from other users",
+			replies:     []slack.Msg{{User: "U2", Text: "yes", ThreadTimestamp: "1.1"}},
+			wantReplies: []string{confirmPromptMessage},
+		},
+		{
+			name:        "should ignore replies in other threads",
+			replies:     []slack.Msg{{User: "U1", Text: "yes", ThreadTimestamp: "2.2"}},
+			wantReplies: []string{confirmPromptMessage},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var replies []string
+			ran := false
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						replies = append(replies, msgValues(opts...).Get("text"))
+						return s, "ts", nil
+					},
+				},
+				DirectListeners: []Listener{{
+					Regex:       regexp.MustCompile(`^deploy$`),
+					Destructive: true,
+					Handler:     func(bot *Bot, ev *slack.MessageEvent) { ran = true },
+				}},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			bot.init()
+			bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "C1", User: "U1", Text: "<@bot> deploy", Timestamp: "1.1"}})
+			for _, r := range tt.replies {
+				r.Channel, r.Timestamp = "C1", "1.2"
+				bot.processMessage(&slack.MessageEvent{Msg: r})
+			}
+			if ran != tt.wantRan {
+				t.Errorf("handler ran = %v, want %v", ran, tt.wantRan)
+			}
+			if !reflect.DeepEqual(replies, tt.wantReplies) {
+				t.Errorf("replies = %q, want %q", replies, tt.wantReplies)
+			}
+		})
+	}
+}
+
+func TestBot_confirmTimeout(t *testing.T) {
+	var mu sync.Mutex
+	var replies []string
+	ran := false
+	bot := &Bot{
+		API: &mockAPI{
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				replies = append(replies, msgValues(opts...).Get("text"))
+				return s, "ts", nil
+			},
+		},
+		ConfirmationTimeout: 10 * time.Millisecond,
+		DirectListeners: []Listener{{
+			Regex:       regexp.MustCompile(`^deploy$`),
+			Destructive: true,
+			Handler:     func(bot *Bot, ev *slack.MessageEvent) { ran = true },
+		}},
+		userDetails: &slack.UserDetails{ID: "bot"},
+	}
+	bot.init()
+	bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: "U1", Text: "deploy", Timestamp: "1.1"}})
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(replies) == 2
+	})
+	bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: "U1", Text: "yes", Timestamp: "1.2", ThreadTimestamp: "1.1"}})
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{confirmPromptMessage, "Cancelled, there was no confirmation within 10ms."}
+	if !reflect.DeepEqual(replies, want) {
+		t.Errorf("replies = %q, want %q", replies, want)
+	}
+	if ran {
+		t.Error("handler ran after the confirmation timed out")
+	}
+}
+
+func TestBot_confirm_slashCommand(t *testing.T) {
+	var replies []url.Values
+	ran := false
+	bot := &Bot{
+		API: &mockAPI{
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				replies = append(replies, msgValues(opts...))
+				return s, "2.1", nil
+			},
+		},
+		DirectListeners: []Listener{{
+			Regex:       regexp.MustCompile(`^deploy$`),
+			Destructive: true,
+			Handler:     func(bot *Bot, ev *slack.MessageEvent) { ran = true },
+		}},
+		userDetails: &slack.UserDetails{ID: "bot"},
+	}
+	bot.init()
+	bot.HandleSlashCommand(slack.SlashCommand{Command: "/deploy", ChannelID: "C1", UserID: "U1"})
+	if len(replies) != 1 || replies[0].Get("thread_ts") != "" || replies[0].Get("text") != confirmPromptMessage {
+		t.Fatalf("replies = %v, want the prompt outside of a thread", replies)
+	}
+	bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "C1", User: "U1", Text: "yes", Timestamp: "2.2", ThreadTimestamp: "2.1"}})
+	if !ran {
+		t.Error("handler didn't run after the prompt was confirmed in its thread")
+	}
+}
+
+func TestBot_handleConfirmAction(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     string
+		actionID string
+		wantRan  bool
+	}{
+		{
+			name:     "should run the listener when the user confirms",
+			user:     "U1",
+			actionID: confirmActionID,
+			wantRan:  true,
+		},
+		{
+			name:     "should not run the listener when the user cancels",
+			user:     "U1",
+			actionID: cancelActionID,
+		},
+		{
+			name:     "should ignore clicks from other users",
+			user:     "U2",
+			actionID: confirmActionID,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran := make(chan bool, 1)
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						return s, "ts", nil
+					},
+				},
+				Interactive: true,
+				DirectListeners: []Listener{{
+					Regex:       regexp.MustCompile(`^deploy$`),
+					Destructive: true,
+					Handler:     func(bot *Bot, ev *slack.MessageEvent) { ran <- true },
+				}},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			bot.once.Do(bot.init)
+			bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: "U1", Text: "deploy", Timestamp: "1.1"}})
+			callback := &slack.InteractionCallback{
+				Type: slack.InteractionTypeBlockActions,
+				User: slack.User{ID: tt.user},
+				ActionCallback: slack.ActionCallbacks{BlockActions: []*slack.BlockAction{{
+					ActionID: tt.actionID,
+					Value:    confirmationKey("D1", "1.1", "U1"),
+				}}},
+			}
+			bot.HandleInteraction(callback)
+			select {
+			case <-ran:
+				if !tt.wantRan {
+					t.Error("handler ran, want it not to")
+				}
+			case <-time.After(100 * time.Millisecond):
+				if tt.wantRan {
+					t.Error("handler didn't run")
+				}
+			}
+		})
+	}
+}
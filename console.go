@@ -0,0 +1,207 @@
+package slackbot
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	consoleChannel = "DCONSOLE"
+	consoleUser    = "UCONSOLE"
+	consoleBotID   = "UBOT"
+	consolePrompt  = "> "
+	consoleHelp    = "Messages are sent to the bot as a direct message. Commands:\n" +
+		"/main - leave the current thread\n" +
+		"/quit - stop the console\n"
+)
+
+// consoleClient is a MessagingClient that prints messages to the console instead of sending them to slack.
+// Methods it doesn't implement will panic.
+type consoleClient struct {
+	MessagingClient
+	out    io.Writer
+	mu     sync.Mutex
+	ts     int
+	thread string
+}
+
+// StartConsole runs the bot in the terminal instead of connecting to slack, no token is needed. Each line read
+// from in is sent to the bot as a direct message and the bot's replies are written to out. When the bot replies
+// in a thread, such as during an exchange, the following lines are sent in that thread until "/main" is entered.
+// Scheduled tasks will run while the console is open. StartConsole returns when in is closed or "/quit" is entered.
+func (bot *Bot) StartConsole(in io.Reader, out io.Writer) error {
+	c := &consoleClient{out: out}
+	bot.API = c
+	bot.once.Do(bot.init)
+	if err := bot.scheduleTasks(); err != nil {
+		return err
+	}
+	fmt.Fprint(out, consoleHelp)
+
+	scanner := bufio.NewScanner(in)
+	for fmt.Fprint(out, consolePrompt); scanner.Scan(); fmt.Fprint(out, consolePrompt) {
+		text := strings.TrimSpace(scanner.Text())
+		switch text {
+		case "":
+			continue
+		case "/quit":
+			return nil
+		case "/main":
+			c.setThread("")
+			continue
+		}
+		bot.HandleMessage(&slack.MessageEvent{
+			Msg: slack.Msg{
+				Type:            "message",
+				Channel:         consoleChannel,
+				User:            consoleUser,
+				Text:            text,
+				Timestamp:       c.nextTimestamp(),
+				ThreadTimestamp: c.currentThread(),
+			},
+		})
+	}
+	return scanner.Err()
+}
+
+func (c *consoleClient) GetInfo() *slack.Info {
+	return &slack.Info{User: &slack.UserDetails{ID: consoleBotID, Name: "console"}}
+}
+
+func (c *consoleClient) GetChannel(identifier string) (slack.Channel, error) {
+	ch := slack.Channel{}
+	ch.ID = identifier
+	if id, ok := mentionID(identifier, channelMentionPrefix); ok {
+		ch.ID = id
+	}
+	return ch, nil
+}
+
+func (c *consoleClient) GetUser(identifier string) (slack.User, error) {
+	if id, ok := mentionID(identifier, userMentionPrefix); ok {
+		return slack.User{ID: id}, nil
+	}
+	return slack.User{ID: identifier}, nil
+}
+
+func (c *consoleClient) GetUserInfo(ID string) (*slack.User, error) {
+	return &slack.User{ID: ID, TZ: time.Local.String()}, nil
+}
+
+func (c *consoleClient) GetUserInfoContext(_ context.Context, ID string) (*slack.User, error) {
+	return c.GetUserInfo(ID)
+}
+
+func (c *consoleClient) PostMessage(channel string, options ...slack.MsgOption) (string, string, error) {
+	_, thread, err := c.print(channel, "", options...)
+	if err != nil {
+		return "", "", err
+	}
+	if thread != "" && channel == consoleChannel {
+		c.setThread(thread)
+	}
+	return channel, c.nextTimestamp(), nil
+}
+
+func (c *consoleClient) PostMessageContext(_ context.Context, channel string, options ...slack.MsgOption) (string, string, error) {
+	return c.PostMessage(channel, options...)
+}
+
+func (c *consoleClient) PostEphemeral(channel string, user string, options ...slack.MsgOption) (string, error) {
+	_, _, err := c.print(channel, "only visible to "+user, options...)
+	return c.nextTimestamp(), err
+}
+
+func (c *consoleClient) PostEphemeralContext(_ context.Context, channel string, user string, options ...slack.MsgOption) (string, error) {
+	return c.PostEphemeral(channel, user, options...)
+}
+
+func (c *consoleClient) UpdateMessage(channel string, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+	text, _, err := c.print(channel, "updated", options...)
+	return channel, timestamp, text, err
+}
+
+func (c *consoleClient) UpdateMessageContext(_ context.Context, channel string, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+	return c.UpdateMessage(channel, timestamp, options...)
+}
+
+func (c *consoleClient) AddReaction(name string, item slack.ItemRef) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(c.out, "bot reacted with :%s:\n", name)
+	return nil
+}
+
+func (c *consoleClient) AddReactionContext(_ context.Context, name string, item slack.ItemRef) error {
+	return c.AddReaction(name, item)
+}
+
+func (c *consoleClient) UploadFile(params slack.FileUploadParameters) (*slack.File, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(c.out, "bot uploaded file %s\n", params.Filename)
+	return &slack.File{Name: params.Filename}, nil
+}
+
+func (c *consoleClient) UploadFileContext(_ context.Context, params slack.FileUploadParameters) (*slack.File, error) {
+	return c.UploadFile(params)
+}
+
+func (c *consoleClient) ScheduleMessage(channel string, postAt string, options ...slack.MsgOption) (string, string, error) {
+	_, _, err := c.print(channel, "scheduled for "+postAt, options...)
+	return channel, postAt, err
+}
+
+func (c *consoleClient) print(channel string, note string, options ...slack.MsgOption) (text string, thread string, err error) {
+	_, values, err := slack.UnsafeApplyMsgOptions("", channel, "", options...)
+	if err != nil {
+		return "", "", errors.Wrap(err, "invalid message options")
+	}
+	text, thread = values.Get("text"), values.Get("thread_ts")
+
+	prefix := "bot"
+	if channel != consoleChannel {
+		prefix += " in " + channel
+	}
+	if thread != "" {
+		prefix += " (thread " + thread + ")"
+	}
+	if note != "" {
+		prefix += " [" + note + "]"
+	}
+	if values.Get("attachments") != "" || values.Get("blocks") != "" {
+		text += " [with attachments or blocks]"
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(c.out, "%s: %s\n", prefix, text)
+	return text, thread, nil
+}
+
+func (c *consoleClient) setThread(thread string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.thread = thread
+}
+
+func (c *consoleClient) currentThread() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.thread
+}
+
+func (c *consoleClient) nextTimestamp() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ts++
+	return fmt.Sprintf("%d.%06d", time.Now().Unix(), c.ts)
+}
@@ -0,0 +1,83 @@
+package slackbot
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_StartConsole(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		want     []string
+		dontWant []string
+	}{
+		{
+			name:  "should print replies to listeners",
+			input: "hello\n",
+			want:  []string{"bot: Hi there\n"},
+		},
+		{
+			name:  "should continue an exchange in its thread",
+			input: "introduce\nSam\n",
+			want:  []string{"What's your name?\n", "Nice to meet you Sam\n"},
+		},
+		{
+			name:     "should leave the thread with /main",
+			input:    "introduce\n/main\nSam\n",
+			want:     []string{"What's your name?\n", "bot: That is not a valid command...\n"},
+			dontWant: []string{"Nice to meet you"},
+		},
+		{
+			name:     "should stop with /quit",
+			input:    "/quit\nhello\n",
+			dontWant: []string{"Hi there"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{
+				DirectListeners: []Listener{
+					{
+						Regex: regexp.MustCompile("^hello$"),
+						Handler: func(bot *Bot, ev *slack.MessageEvent) {
+							_, _, _ = bot.Reply(ev.Channel, "Hi there")
+						},
+					},
+				},
+				Exchanges: []Exchange{
+					{
+						Regex: regexp.MustCompile("^introduce$"),
+						Steps: map[int]*Step{
+							1: {Message: "What's your name?"},
+							2: {
+								MsgHandler: func(ex *Exchange, ev *slack.MessageEvent) (bool, error) {
+									ex.Reply("Nice to meet you " + ev.Text)
+									return false, nil
+								},
+							},
+						},
+					},
+				},
+			}
+			var out bytes.Buffer
+			if err := bot.StartConsole(strings.NewReader(tt.input), &out); err != nil {
+				t.Fatalf("StartConsole() error = %v", err)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(out.String(), w) {
+					t.Errorf("StartConsole() output = %q, want it to contain %q", out.String(), w)
+				}
+			}
+			for _, w := range tt.dontWant {
+				if strings.Contains(out.String(), w) {
+					t.Errorf("StartConsole() output = %q, should not contain %q", out.String(), w)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package slackbot
+
+import (
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+var slackEntities = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">")
+
+type (
+	// MessageContent is the content of a message gathered from its text, attachments, blocks and files, so
+	// listeners can read messages posted by integrations without walking the slack types by hand. Text entities
+	// such as &gt; are decoded.
+	MessageContent struct {
+		// Text is the message's text without its quoted lines, which are in Quotes without the leading >.
+		Text   string
+		Quotes []string
+
+		Attachments []AttachmentContent
+
+		// Blocks is the text of the message's section, header and context blocks.
+		Blocks []string
+
+		Files []FileContent
+
+		// Shared are the messages shared or forwarded into the message, they are not included in Attachments.
+		Shared []SharedMessage
+	}
+
+	// AttachmentContent is the content of a message attachment.
+	AttachmentContent struct {
+		Title     string
+		TitleLink string
+		Pretext   string
+		Text      string
+		Fallback  string
+		Author    string
+		Footer    string
+		Color     string
+		Fields    []AttachmentField
+		Blocks    []string
+	}
+
+	// AttachmentField is a field of an attachment, such as the "Priority" of an alert.
+	AttachmentField struct {
+		Title string
+		Value string
+	}
+
+	// FileContent describes a file attached to a message, bot.api().GetFile(file.URL, w) downloads it.
+	FileContent struct {
+		ID        string
+		Name      string
+		Title     string
+		Type      string
+		MimeType  string
+		URL       string
+		Permalink string
+		Size      int
+	}
+
+	// SharedMessage is a message that was shared or forwarded into another message.
+	SharedMessage struct {
+		Author    string
+		AuthorID  string
+		Timestamp string
+		Text      string
+	}
+)
+
+// ParseMessageContent gathers the content of the message event.
+func ParseMessageContent(ev *slack.MessageEvent) MessageContent {
+	content := MessageContent{Blocks: blocksText(ev.Blocks)}
+	var text []string
+	for _, line := range strings.Split(slackEntities.Replace(ev.Text), "\n") {
+		if strings.HasPrefix(line, ">") {
+			content.Quotes = append(content.Quotes, strings.TrimSpace(strings.TrimPrefix(line, ">")))
+			continue
+		}
+		text = append(text, line)
+	}
+	content.Text = strings.TrimSpace(strings.Join(text, "\n"))
+
+	for _, a := range ev.Attachments {
+		if a.Ts != "" && (a.AuthorSubname != "" || a.AuthorID != "") {
+			author := a.AuthorSubname
+			if author == "" {
+				author = a.AuthorName
+			}
+			content.Shared = append(content.Shared, SharedMessage{
+				Author:    author,
+				AuthorID:  a.AuthorID,
+				Timestamp: a.Ts.String(),
+				Text:      slackEntities.Replace(a.Text),
+			})
+			continue
+		}
+		content.Attachments = append(content.Attachments, parseAttachment(a))
+	}
+	for _, f := range ev.Files {
+		content.Files = append(content.Files, FileContent{
+			ID:        f.ID,
+			Name:      f.Name,
+			Title:     f.Title,
+			Type:      f.Filetype,
+			MimeType:  f.Mimetype,
+			URL:       f.URLPrivateDownload,
+			Permalink: f.Permalink,
+			Size:      f.Size,
+		})
+	}
+	return content
+}
+
+func parseAttachment(a slack.Attachment) AttachmentContent {
+	ac := AttachmentContent{
+		Title:     slackEntities.Replace(a.Title),
+		TitleLink: a.TitleLink,
+		Pretext:   slackEntities.Replace(a.Pretext),
+		Text:      slackEntities.Replace(a.Text),
+		Fallback:  slackEntities.Replace(a.Fallback),
+		Author:    a.AuthorName,
+		Footer:    slackEntities.Replace(a.Footer),
+		Color:     a.Color,
+		Blocks:    blocksText(a.Blocks),
+	}
+	for _, f := range a.Fields {
+		ac.Fields = append(ac.Fields, AttachmentField{Title: slackEntities.Replace(f.Title), Value: slackEntities.Replace(f.Value)})
+	}
+	return ac
+}
+
+// blocksText returns the text of the section, header and context blocks.
+func blocksText(blocks slack.Blocks) []string {
+	var text []string
+	add := func(t *slack.TextBlockObject) {
+		if t != nil && t.Text != "" {
+			text = append(text, slackEntities.Replace(t.Text))
+		}
+	}
+	for _, b := range blocks.BlockSet {
+		switch b := b.(type) {
+		case *slack.SectionBlock:
+			add(b.Text)
+			for _, f := range b.Fields {
+				add(f)
+			}
+		case *slack.HeaderBlock:
+			add(b.Text)
+		case *slack.ContextBlock:
+			for _, e := range b.ContextElements.Elements {
+				if t, ok := e.(*slack.TextBlockObject); ok {
+					add(t)
+				}
+			}
+		}
+	}
+	return text
+}
+
+// Field returns the value of the first field with the title in any of the attachments, ignoring case.
+func (c MessageContent) Field(title string) (string, bool) {
+	for _, a := range c.Attachments {
+		if v, ok := a.Field(title); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Field returns the value of the attachment's first field with the title, ignoring case.
+func (a AttachmentContent) Field(title string) (string, bool) {
+	for _, f := range a.Fields {
+		if strings.EqualFold(f.Title, title) {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
+
+// AllText returns all of the text in the message, its attachments and blocks on separate lines, so it can be
+// searched or matched with a regular expression.
+func (c MessageContent) AllText() string {
+	var parts []string
+	add := func(s ...string) {
+		for _, p := range s {
+			if p != "" {
+				parts = append(parts, p)
+			}
+		}
+	}
+	add(c.Text)
+	add(c.Quotes...)
+	add(c.Blocks...)
+	for _, a := range c.Attachments {
+		add(a.Pretext, a.Title, a.Text)
+		for _, f := range a.Fields {
+			add(f.Title + ": " + f.Value)
+		}
+		add(a.Blocks...)
+		add(a.Footer)
+	}
+	for _, s := range c.Shared {
+		add(s.Text)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Content returns the content of the message that triggered the listener, it is parsed the first time Content
+// is called.
+func (ctx *MessageContext) Content() MessageContent {
+	ctx.contentOnce.Do(func() {
+		ctx.content = ParseMessageContent(ctx.Event)
+	})
+	return ctx.content
+}
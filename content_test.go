@@ -0,0 +1,147 @@
+package slackbot
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+const alertMessageJSON = `{
+	"type": "message",
+	"channel": "C1",
+	"bot_id": "B1",
+	"text": "Triggered &amp; paging\n&gt; check the api",
+	"ts": "1.1",
+	"attachments": [
+		{
+			"color": "#ff0000",
+			"title": "[P1] api latency &gt; 2s",
+			"title_link": "https://example.com/monitors/1",
+			"text": "p99 latency is 2.4s",
+			"fields": [
+				{"title": "Priority", "value": "P1", "short": true},
+				{"title": "Service", "value": "api", "short": true}
+			],
+			"footer": "Datadog"
+		},
+		{
+			"author_subname": "Sam",
+			"author_id": "U2",
+			"ts": "0.5",
+			"text": "is anyone looking at this?"
+		}
+	],
+	"blocks": [
+		{"type": "header", "text": {"type": "plain_text", "text": "Monitor alert"}},
+		{"type": "section", "text": {"type": "mrkdwn", "text": "*api* is slow"}, "fields": [{"type": "mrkdwn", "text": "env: prod"}]},
+		{"type": "context", "elements": [{"type": "mrkdwn", "text": "monitor 1"}]},
+		{"type": "divider"}
+	],
+	"files": [
+		{"id": "F1", "name": "graph.png", "title": "Latency", "filetype": "png", "mimetype": "image/png", "url_private_download": "https://files.slack.com/graph.png", "permalink": "https://example.slack.com/files/F1", "size": 42}
+	]
+}`
+
+func alertMessage(t *testing.T) *slack.MessageEvent {
+	ev := &slack.MessageEvent{}
+	if err := json.Unmarshal([]byte(alertMessageJSON), ev); err != nil {
+		t.Fatal(err)
+	}
+	return ev
+}
+
+func TestParseMessageContent(t *testing.T) {
+	tests := []struct {
+		name string
+		ev   func(t *testing.T) *slack.MessageEvent
+		want MessageContent
+	}{
+		{
+			name: "should parse the text of a plain message",
+			ev: func(t *testing.T) *slack.MessageEvent {
+				return &slack.MessageEvent{Msg: slack.Msg{Text: "deploy api"}}
+			},
+			want: MessageContent{Text: "deploy api"},
+		},
+		{
+			name: "should gather the content of an integration's message",
+			ev:   alertMessage,
+			want: MessageContent{
+				Text:   "Triggered & paging",
+				Quotes: []string{"check the api"},
+				Attachments: []AttachmentContent{
+					{
+						Title:     "[P1] api latency > 2s",
+						TitleLink: "https://example.com/monitors/1",
+						Text:      "p99 latency is 2.4s",
+						Footer:    "Datadog",
+						Color:     "#ff0000",
+						Fields:    []AttachmentField{{Title: "Priority", Value: "P1"}, {Title: "Service", Value: "api"}},
+					},
+				},
+				Blocks: []string{"Monitor alert", "*api* is slow", "env: prod", "monitor 1"},
+				Files: []FileContent{
+					{
+						ID:        "F1",
+						Name:      "graph.png",
+						Title:     "Latency",
+						Type:      "png",
+						MimeType:  "image/png",
+						URL:       "https://files.slack.com/graph.png",
+						Permalink: "https://example.slack.com/files/F1",
+						Size:      42,
+					},
+				},
+				Shared: []SharedMessage{{Author: "Sam", AuthorID: "U2", Timestamp: "0.5", Text: "is anyone looking at this?"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseMessageContent(tt.ev(t)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMessageContent() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageContent_Field(t *testing.T) {
+	content := ParseMessageContent(alertMessage(t))
+	tests := []struct {
+		name   string
+		title  string
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "should find a field ignoring case",
+			title:  "priority",
+			want:   "P1",
+			wantOK: true,
+		},
+		{
+			name:  "should not find a missing field",
+			title: "Owner",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := content.Field(tt.title)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Field() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestMessageContent_AllText(t *testing.T) {
+	want := "Triggered & paging\ncheck the api\nMonitor alert\n*api* is slow\nenv: prod\nmonitor 1\n" +
+		"[P1] api latency > 2s\np99 latency is 2.4s\nPriority: P1\nService: api\nDatadog\nis anyone looking at this?"
+	ctx := &MessageContext{Context: context.Background(), Event: alertMessage(t)}
+	if got := ctx.Content().AllText(); got != want {
+		t.Errorf("AllText() = %q, want %q", got, want)
+	}
+}
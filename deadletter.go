@@ -0,0 +1,181 @@
+package slackbot
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	deadLetterStoreKey = "dead_letters"
+	maxDeadLetters     = 100
+)
+
+var deadLetterRegex = regexp.MustCompile(`^(?i)dead letters(?: (resend|delete) (\S+))?$`)
+
+// DeadLetter is an outgoing message that failed to send. Failed messages sent with the Reply methods are
+// saved as dead letters in the bot's Store so they can be inspected and resent. Only the newest 100 are kept.
+type DeadLetter struct {
+	ID      string
+	Channel string
+	Values  url.Values
+	Error   string
+	Failed  time.Time
+}
+
+// DeadLetters returns the messages that failed to send and have not been resent or deleted.
+func (bot *Bot) DeadLetters() ([]DeadLetter, error) {
+	bot.deadLetterMu.Lock()
+	defer bot.deadLetterMu.Unlock()
+	return bot.loadDeadLetters()
+}
+
+// ResendDeadLetter will try to send the dead letter again. If it is sent successfully it will be
+// removed from the dead letters, otherwise its error will be updated.
+func (bot *Bot) ResendDeadLetter(ID string) error {
+	bot.deadLetterMu.Lock()
+	defer bot.deadLetterMu.Unlock()
+	letters, err := bot.loadDeadLetters()
+	if err != nil {
+		return err
+	}
+	for i, l := range letters {
+		if l.ID != ID {
+			continue
+		}
+		options, err := decodeMsgOptions(l.Values)
+		if err != nil {
+			return err
+		}
+		if err := bot.checkCircuitBreaker(l.Channel); err != nil {
+			return err
+		}
+		err = bot.withRetry(func() error {
+			_, _, err := bot.api().PostMessageContext(bot.context(), l.Channel, options...)
+			return err
+		})
+		if err != nil {
+			letters[i].Error = err.Error()
+			letters[i].Failed = bot.clock().Now()
+			if e := bot.store().Put(deadLetterStoreKey, letters); e != nil {
+				return e
+			}
+			return err
+		}
+		return bot.store().Put(deadLetterStoreKey, append(letters[:i], letters[i+1:]...))
+	}
+	return errors.Errorf("dead letter %s not found", ID)
+}
+
+// DeleteDeadLetter removes the dead letter without sending it.
+func (bot *Bot) DeleteDeadLetter(ID string) error {
+	bot.deadLetterMu.Lock()
+	defer bot.deadLetterMu.Unlock()
+	letters, err := bot.loadDeadLetters()
+	if err != nil {
+		return err
+	}
+	for i, l := range letters {
+		if l.ID == ID {
+			return bot.store().Put(deadLetterStoreKey, append(letters[:i], letters[i+1:]...))
+		}
+	}
+	return errors.Errorf("dead letter %s not found", ID)
+}
+
+// DeadLetterListener returns a DirectListener for admins to manage dead letters. "dead letters" will list
+// them, "dead letters resend <id>" will resend one and "dead letters delete <id>" will delete one. Only the
+// users and channels allowed by the admins ACL can use it. It is not enabled by default, add it to the bot's
+// DirectListeners to enable it.
+func DeadLetterListener(admins *ACL) Listener {
+	return Listener{
+		Usage: "dead letters [resend|delete <id>]",
+		Regex: deadLetterRegex,
+		ACL:   admins,
+		Handler: func(bot *Bot, ev *slack.MessageEvent) {
+			m := deadLetterRegex.FindStringSubmatch(ev.Text)
+			reply := func(msg string) {
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), msg)
+			}
+			var err error
+			switch m[1] {
+			case "resend":
+				if err = bot.ResendDeadLetter(m[2]); err == nil {
+					reply(fmt.Sprintf("Dead letter %s was resent", m[2]))
+				}
+			case "delete":
+				if err = bot.DeleteDeadLetter(m[2]); err == nil {
+					reply(fmt.Sprintf("Dead letter %s was deleted", m[2]))
+				}
+			default:
+				var letters []DeadLetter
+				if letters, err = bot.DeadLetters(); err == nil {
+					reply(formatDeadLetters(letters))
+				}
+			}
+			if err != nil {
+				reply(fmt.Sprintf("Error managing dead letters - %s", err))
+			}
+		},
+	}
+}
+
+// deadLetter saves a message that failed to send to the dead letters in the bot's Store.
+func (bot *Bot) deadLetter(channel string, options []slack.MsgOption, sendErr error) {
+	values, err := encodeMsgOptions(options...)
+	if err != nil {
+		bot.LogError(fmt.Sprintf("unable to save dead letter for %s - %s", channel, err))
+		return
+	}
+
+	bot.deadLetterMu.Lock()
+	defer bot.deadLetterMu.Unlock()
+	letters, err := bot.loadDeadLetters()
+	if err != nil {
+		bot.LogError(fmt.Sprintf("unable to save dead letter for %s - %s", channel, err))
+		return
+	}
+	letters = append(letters, DeadLetter{
+		ID:      newID(),
+		Channel: channel,
+		Values:  values,
+		Error:   sendErr.Error(),
+		Failed:  bot.clock().Now(),
+	})
+	if len(letters) > maxDeadLetters {
+		letters = letters[len(letters)-maxDeadLetters:]
+	}
+	if err := bot.store().Put(deadLetterStoreKey, letters); err != nil {
+		bot.LogError(fmt.Sprintf("unable to save dead letter for %s - %s", channel, err))
+	}
+}
+
+// loadDeadLetters must be called while holding the deadLetterMu lock.
+func (bot *Bot) loadDeadLetters() ([]DeadLetter, error) {
+	var letters []DeadLetter
+	err := bot.store().Get(deadLetterStoreKey, &letters)
+	if errors.Is(err, ErrNotFound) {
+		// a missing key means nothing has failed yet
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load dead letters")
+	}
+	return letters, nil
+}
+
+func formatDeadLetters(letters []DeadLetter) string {
+	if len(letters) == 0 {
+		return "There are no dead letters"
+	}
+	var buf bytes.Buffer
+	for _, l := range letters {
+		buf.WriteString(fmt.Sprintf("%s - %s to %s: %q failed with %s\n", l.ID, l.Failed.Format(time.RFC3339), l.Channel, l.Values.Get("text"), l.Error))
+	}
+	return buf.String()
+}
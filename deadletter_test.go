@@ -0,0 +1,160 @@
+package slackbot
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func TestBot_deadLetter(t *testing.T) {
+	fail := true
+	var sent string
+	bot := &Bot{
+		Store: SimpleStore{},
+		API: &mockAPI{
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				if fail {
+					return "", "", errors.New("channel_not_found")
+				}
+				sent = msgValues(opts...).Get("text")
+				return s, "1.2", nil
+			},
+		},
+	}
+
+	if _, _, err := bot.Reply("C123", "important"); err == nil {
+		t.Fatalf("Reply() expected an error")
+	}
+	letters, err := bot.DeadLetters()
+	if err != nil || len(letters) != 1 {
+		t.Fatalf("DeadLetters() got = %v, err = %v, want 1 letter", letters, err)
+	}
+	if letters[0].Channel != "C123" || letters[0].Values.Get("text") != "important" || letters[0].Error != "channel_not_found" {
+		t.Errorf("DeadLetters() got = %v", letters[0])
+	}
+
+	if err := bot.ResendDeadLetter(letters[0].ID); err == nil {
+		t.Errorf("ResendDeadLetter() expected an error")
+	}
+	if letters, _ := bot.DeadLetters(); len(letters) != 1 {
+		t.Errorf("failed resend should keep the dead letter, got = %v", letters)
+	}
+
+	fail = false
+	if err := bot.ResendDeadLetter(letters[0].ID); err != nil {
+		t.Errorf("ResendDeadLetter() error = %v", err)
+	}
+	if sent != "important" {
+		t.Errorf("ResendDeadLetter() sent = %v, want important", sent)
+	}
+	if letters, _ := bot.DeadLetters(); len(letters) != 0 {
+		t.Errorf("resent dead letter should be removed, got = %v", letters)
+	}
+	if err := bot.DeleteDeadLetter("missing"); err == nil {
+		t.Errorf("DeleteDeadLetter() expected an error")
+	}
+}
+
+func TestBot_deadLetter_limit(t *testing.T) {
+	bot := &Bot{
+		Store: SimpleStore{},
+		API: &mockAPI{postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+			return "", "", errors.New("channel_not_found")
+		}},
+	}
+	for i := 0; i < maxDeadLetters+5; i++ {
+		bot.deadLetter("C1", []slack.MsgOption{slack.MsgOptionText(fmt.Sprint(i), false)}, errors.New("channel_not_found"))
+	}
+	letters, err := bot.DeadLetters()
+	if err != nil || len(letters) != maxDeadLetters {
+		t.Fatalf("DeadLetters() got %d letters, err = %v, want %d", len(letters), err, maxDeadLetters)
+	}
+	if got := letters[0].Values.Get("text"); got != "5" {
+		t.Errorf("oldest dead letter = %q, want the oldest ones dropped", got)
+	}
+}
+
+// unreadableStore is a Store whose values can't be read.
+type unreadableStore struct {
+	SimpleStore
+}
+
+func (unreadableStore) Get(string, interface{}) error {
+	return errors.New("connection refused")
+}
+
+func TestBot_deadLetter_unreadableStore(t *testing.T) {
+	store := unreadableStore{SimpleStore{}}
+	_ = store.Put(deadLetterStoreKey, []DeadLetter{{ID: "a1", Channel: "C1"}})
+	bot := &Bot{Store: store}
+	bot.deadLetter("C1", []slack.MsgOption{slack.MsgOptionText("important", false)}, errors.New("channel_not_found"))
+
+	if _, err := bot.DeadLetters(); err == nil {
+		t.Error("DeadLetters() expected an error")
+	}
+	var letters []DeadLetter
+	if _ = store.SimpleStore.Get(deadLetterStoreKey, &letters); len(letters) != 1 || letters[0].ID != "a1" {
+		t.Errorf("saved dead letters = %v, want them left alone", letters)
+	}
+}
+
+func TestBot_DeadLetters_defaultStore(t *testing.T) {
+	bot := &Bot{}
+	bot.deadLetter("C123", []slack.MsgOption{slack.MsgOptionText("text", false)}, errors.New("error"))
+	if letters, err := bot.DeadLetters(); err != nil || len(letters) != 1 {
+		t.Errorf("DeadLetters() = %v, %v, want the letter saved in the default store", letters, err)
+	}
+}
+
+func TestDeadLetterListener(t *testing.T) {
+	tests := []struct {
+		name string
+		user string
+		text string
+		want string
+	}{
+		{
+			name: "should list dead letters",
+			text: "dead letters",
+			want: "There are no dead letters",
+		},
+		{
+			name: "should reply with an error when resending a missing letter",
+			text: "dead letters resend abc",
+			want: "Error managing dead letters - dead letter abc not found",
+		},
+		{
+			name: "should not let other users manage dead letters",
+			user: "U2",
+			text: "dead letters delete abc",
+			want: aclUserDeniedMessage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reply string
+			bot := &Bot{
+				Store: SimpleStore{},
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						reply = msgValues(opts...).Get("text")
+						return "", "", nil
+					},
+				},
+				DirectListeners: []Listener{DeadLetterListener(&ACL{Users: []string{"U1"}})},
+				userDetails:     &slack.UserDetails{ID: "bot"},
+			}
+			bot.init()
+			user := tt.user
+			if user == "" {
+				user = "U1"
+			}
+			bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: user, Text: tt.text, Timestamp: "1.1"}})
+			if reply != tt.want {
+				t.Errorf("DeadLetterListener() reply = %v, want %v", reply, tt.want)
+			}
+		})
+	}
+}
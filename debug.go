@@ -0,0 +1,120 @@
+package slackbot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	debugRepeatedMessage = "last message repeated %d times"
+	maxDebugBatchLength  = 3000
+)
+
+// debugBatch collects debug messages so they can be sent to the DebugChannel together.
+type debugBatch struct {
+	mu      sync.Mutex
+	lines   []string
+	last    string
+	repeats int
+	timer   Timer
+}
+
+// batchDebug adds the message to the current batch, starting a timer to flush the batch after the
+// bot's DebugBatchInterval if one isn't already running. Repeats of the previous message are counted
+// rather than added.
+func (bot *Bot) batchDebug(msg string) {
+	bot.mu.Lock()
+	if bot.debug == nil {
+		bot.debug = &debugBatch{}
+	}
+	b := bot.debug
+	bot.mu.Unlock()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if msg == b.last && len(b.lines) > 0 {
+		b.repeats++
+	} else {
+		b.addRepeats()
+		b.lines = append(b.lines, msg)
+		b.last = msg
+	}
+	if b.timer == nil {
+		b.timer = bot.clock().AfterFunc(bot.DebugBatchInterval, bot.flushDebug)
+	}
+}
+
+// flushDebug sends the batched debug messages to the DebugChannel. Batches are not counted by the
+// circuit breaker since they are already limited to one message per DebugBatchInterval, unless a batch
+// is too long for a single message, then the messages after the first are counted. It is called when the
+// bot stops, so the last batch isn't lost.
+func (bot *Bot) flushDebug() {
+	bot.mu.Lock()
+	b := bot.debug
+	bot.mu.Unlock()
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	b.addRepeats()
+	lines := b.lines
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	b.lines, b.last, b.timer = nil, "", nil
+	b.mu.Unlock()
+
+	for i, msg := range splitDebugBatch(lines) {
+		if i > 0 {
+			if err := bot.checkCircuitBreaker(bot.DebugChannel); err != nil {
+				log.Printf("Error sending message to debug channel %s - %s\n", bot.DebugChannel, err)
+				return
+			}
+		}
+		if bot.SendQueue != nil {
+			bot.SendQueue.sendLog(bot, bot.DebugChannel, PriorityDebug, slack.MsgOptionText(msg, false), slack.MsgOptionAsUser(true))
+			continue
+		}
+		err := bot.withRetry(func() error {
+			_, _, err := bot.api().PostMessageContext(bot.context(), bot.DebugChannel, slack.MsgOptionText(msg, false), slack.MsgOptionAsUser(true))
+			return err
+		})
+		if err != nil {
+			log.Printf("Error sending message to debug channel %s - %s\n", bot.DebugChannel, err)
+		}
+	}
+}
+
+// addRepeats must be called while holding the batch lock.
+func (b *debugBatch) addRepeats() {
+	if b.repeats > 0 {
+		b.lines = append(b.lines, fmt.Sprintf(debugRepeatedMessage, b.repeats))
+		b.repeats = 0
+	}
+}
+
+// splitDebugBatch joins the lines into as few messages as possible without exceeding maxDebugBatchLength,
+// a single line longer than the max is sent on its own.
+func splitDebugBatch(lines []string) []string {
+	var msgs []string
+	var current strings.Builder
+	for _, l := range lines {
+		if current.Len() > 0 && current.Len()+len(l)+1 > maxDebugBatchLength {
+			msgs = append(msgs, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n")
+		}
+		current.WriteString(l)
+	}
+	if current.Len() > 0 {
+		msgs = append(msgs, current.String())
+	}
+	return msgs
+}
@@ -0,0 +1,144 @@
+package slackbot
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_LogDebug_batch(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		want     []string
+	}{
+		{
+			name:     "should send messages in a single batch",
+			messages: []string{"one", "two", "three"},
+			want:     []string{"one\ntwo\nthree"},
+		},
+		{
+			name:     "should collapse repeated messages",
+			messages: []string{"one", "two", "two", "two", "three", "three"},
+			want:     []string{"one\ntwo\nlast message repeated 2 times\nthree\nlast message repeated 1 times"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var sent []string
+			bot := &Bot{
+				DebugChannel:       "debug",
+				DebugBatchInterval: 10 * time.Millisecond,
+				CircuitBreaker:     &CircuitBreaker{MaxMessages: 1, TimeInterval: time.Minute},
+				terminate: func(int) {
+					t.Errorf("batched debug messages should not trip the circuit breaker")
+				},
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						mu.Lock()
+						defer mu.Unlock()
+						sent = append(sent, msgValues(opts...).Get("text"))
+						return s, "ts", nil
+					},
+				},
+			}
+			for _, m := range tt.messages {
+				bot.LogDebug(m)
+			}
+			time.Sleep(50 * time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+			if !reflect.DeepEqual(sent, tt.want) {
+				t.Errorf("LogDebug() sent = %q, want %q", sent, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_flushDebug(t *testing.T) {
+	long := func(s string) string { return strings.Repeat(s, maxDebugBatchLength) }
+	tests := []struct {
+		name      string
+		messages  []string
+		stop      bool
+		wantSent  int
+		wantFatal bool
+	}{
+		{
+			name:     "should send the pending batch when the bot stops",
+			messages: []string{"one", "two"},
+			stop:     true,
+			wantSent: 1,
+		},
+		{
+			name:      "should count the messages of a long batch after the first",
+			messages:  []string{long("a"), long("b"), long("c")},
+			wantSent:  3,
+			wantFatal: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []string
+			fatal := false
+			bot := &Bot{
+				DebugChannel:       "debug",
+				DebugBatchInterval: time.Hour,
+				CircuitBreaker:     &CircuitBreaker{MaxMessages: 1, TimeInterval: time.Minute},
+				OnFatal:            func(error) { fatal = true },
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						sent = append(sent, msgValues(opts...).Get("text"))
+						return s, "ts", nil
+					},
+				},
+			}
+			for _, m := range tt.messages {
+				bot.LogDebug(m)
+			}
+			if tt.stop {
+				bot.Stop()
+			} else {
+				bot.flushDebug()
+			}
+			if len(sent) != tt.wantSent || fatal != tt.wantFatal {
+				t.Errorf("sent %d messages with fatal %v, want %d with %v", len(sent), fatal, tt.wantSent, tt.wantFatal)
+			}
+		})
+	}
+}
+
+func Test_splitDebugBatch(t *testing.T) {
+	long := strings.Repeat("a", maxDebugBatchLength)
+	tests := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{
+			name:  "should join lines that fit in a message",
+			lines: []string{"one", "two"},
+			want:  []string{"one\ntwo"},
+		},
+		{
+			name:  "should split lines that exceed the max length",
+			lines: []string{"one", long, "two"},
+			want:  []string{"one", long, "two"},
+		},
+		{
+			name: "should return nothing for no lines",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitDebugBatch(tt.lines); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitDebugBatch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const dedupeCountFormat = "%s (x%d)"
+
+// sentReply is a reply the bot sent recently, for DedupeWindow. It is added before the reply is posted so
+// concurrent duplicates aren't posted too, they wait for posted to be closed and edit the reply once it has a
+// channel and timestamp. Both are empty if posting it failed.
+type sentReply struct {
+	channel   string
+	timestamp string
+	text      string
+	count     int
+	sent      time.Time
+	posted    chan struct{}
+}
+
+// dedupeReply sends the message unless an identical one was sent to the same channel and thread within the
+// DedupeWindow, in which case the earlier message is edited to count the repeats and its timestamp is returned.
+func (bot *Bot) dedupeReply(ctx context.Context, priority Priority, channel string, options []slack.MsgOption) (string, string, error) {
+	values, err := encodeMsgOptions(options...)
+	if err != nil {
+		return bot.postReply(ctx, priority, channel, options)
+	}
+	key := channel + " " + values.Encode()
+
+	bot.dedupeMu.Lock()
+	now := bot.clock().Now()
+	for k, r := range bot.sentReplies {
+		if now.Sub(r.sent) >= bot.DedupeWindow {
+			delete(bot.sentReplies, k)
+		}
+	}
+	sent, ok := bot.sentReplies[key]
+	if ok {
+		sent.count++
+		values.Set("text", fmt.Sprintf(dedupeCountFormat, sent.text, sent.count))
+	} else {
+		if bot.sentReplies == nil {
+			bot.sentReplies = make(map[string]*sentReply)
+		}
+		sent = &sentReply{text: values.Get("text"), count: 1, sent: now, posted: make(chan struct{})}
+		bot.sentReplies[key] = sent
+	}
+	bot.dedupeMu.Unlock()
+
+	if ok {
+		select {
+		case <-sent.posted:
+		case <-ctx.Done():
+			return "", "", errors.Wrap(ctx.Err(), "unable to count the repeated message")
+		}
+		if sent.timestamp == "" {
+			return bot.postReply(ctx, priority, channel, options)
+		}
+		return bot.updateDuplicate(ctx, sent, values)
+	}
+	c, t, err := bot.postReply(ctx, priority, channel, options)
+	bot.dedupeMu.Lock()
+	if err == nil && t != "" {
+		sent.channel, sent.timestamp = c, t
+	} else if bot.sentReplies[key] == sent {
+		delete(bot.sentReplies, key)
+	}
+	close(sent.posted)
+	bot.dedupeMu.Unlock()
+	return c, t, err
+}
+
+// updateDuplicate edits the earlier reply to show how many times it has been sent.
+func (bot *Bot) updateDuplicate(ctx context.Context, sent *sentReply, values url.Values) (string, string, error) {
+	values.Del("thread_ts")
+	values.Del("reply_broadcast")
+	options, err := decodeMsgOptions(values)
+	if err == nil {
+		err = bot.withRetry(func() (err error) {
+			_, _, _, err = bot.api().UpdateMessageContext(ctx, sent.channel, sent.timestamp, options...)
+			return err
+		})
+	}
+	if err != nil {
+		return "", "", errors.Wrapf(err, "unable to count the repeated message %s in %s", sent.timestamp, sent.channel)
+	}
+	return sent.channel, sent.timestamp, nil
+}
@@ -0,0 +1,145 @@
+package slackbot
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_dedupeReply(t *testing.T) {
+	type reply struct {
+		channel string
+		thread  string
+		text    string
+	}
+	tests := []struct {
+		name        string
+		window      time.Duration
+		replies     []reply
+		wantPosts   []string
+		wantUpdates []string
+	}{
+		{
+			name:      "should send every reply without a window",
+			replies:   []reply{{channel: "C1", text: "deploy failed"}, {channel: "C1", text: "deploy failed"}},
+			wantPosts: []string{"deploy failed", "deploy failed"},
+		},
+		{
+			name:   "should count identical replies in the window",
+			window: time.Minute,
+			replies: []reply{
+				{channel: "C1", text: "deploy failed"},
+				{channel: "C1", text: "deploy failed"},
+				{channel: "C1", text: "deploy failed"},
+			},
+			wantPosts:   []string{"deploy failed"},
+			wantUpdates: []string{"deploy failed (x2)", "deploy failed (x3)"},
+		},
+		{
+			name:      "should send different replies",
+			window:    time.Minute,
+			replies:   []reply{{channel: "C1", text: "deploy failed"}, {channel: "C1", text: "deploy finished"}},
+			wantPosts: []string{"deploy failed", "deploy finished"},
+		},
+		{
+			name:      "should send identical replies to other channels",
+			window:    time.Minute,
+			replies:   []reply{{channel: "C1", text: "deploy failed"}, {channel: "C2", text: "deploy failed"}},
+			wantPosts: []string{"deploy failed", "deploy failed"},
+		},
+		{
+			name:      "should send identical replies to other threads",
+			window:    time.Minute,
+			replies:   []reply{{channel: "C1", thread: "1.1", text: "deploy failed"}, {channel: "C1", thread: "2.2", text: "deploy failed"}},
+			wantPosts: []string{"deploy failed", "deploy failed"},
+		},
+		{
+			name:      "should send identical replies after the window",
+			window:    time.Nanosecond,
+			replies:   []reply{{channel: "C1", text: "deploy failed"}, {channel: "C1", text: "deploy failed"}},
+			wantPosts: []string{"deploy failed", "deploy failed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var posts, updates []string
+			bot := &Bot{
+				DedupeWindow: tt.window,
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						posts = append(posts, msgValues(opts...).Get("text"))
+						return s, "ts", nil
+					},
+					updateMessage: func(c string, ts string, opts ...slack.MsgOption) (string, string, string, error) {
+						if c != "C1" || ts != "ts" {
+							t.Errorf("UpdateMessage() updated %s %s, want C1 ts", c, ts)
+						}
+						updates = append(updates, msgValues(opts...).Get("text"))
+						return c, ts, "", nil
+					},
+				},
+			}
+			for _, r := range tt.replies {
+				if _, ts, err := bot.ReplyInThread(r.channel, r.thread, r.text); err != nil || ts != "ts" {
+					t.Errorf("ReplyInThread() = %q, %v", ts, err)
+				}
+			}
+			if !reflect.DeepEqual(posts, tt.wantPosts) {
+				t.Errorf("posted %q, want %q", posts, tt.wantPosts)
+			}
+			if !reflect.DeepEqual(updates, tt.wantUpdates) {
+				t.Errorf("updated %q, want %q", updates, tt.wantUpdates)
+			}
+		})
+	}
+}
+
+func TestBot_dedupeReply_concurrent(t *testing.T) {
+	var mu sync.Mutex
+	posts, updates := 0, 0
+	release := make(chan struct{})
+	bot := &Bot{
+		DedupeWindow: time.Minute,
+		API: &mockAPI{
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				<-release
+				mu.Lock()
+				defer mu.Unlock()
+				posts++
+				return s, "ts", nil
+			},
+			updateMessage: func(c string, ts string, opts ...slack.MsgOption) (string, string, string, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				updates++
+				return c, ts, "", nil
+			},
+		},
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ts, err := bot.Reply("C1", "deploy failed"); err != nil || ts != "ts" {
+				t.Errorf("Reply() = %q, %v", ts, err)
+			}
+		}()
+	}
+	waitFor(t, func() bool {
+		bot.dedupeMu.Lock()
+		defer bot.dedupeMu.Unlock()
+		for _, r := range bot.sentReplies {
+			return r.count == 5
+		}
+		return false
+	})
+	close(release)
+	wg.Wait()
+	if posts != 1 || updates != 4 {
+		t.Errorf("posted %d and updated %d times, want 1 and 4", posts, updates)
+	}
+}
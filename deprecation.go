@@ -0,0 +1,50 @@
+package slackbot
+
+import (
+	"fmt"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	deprecatedNotice           = "_This command is deprecated and will be removed._"
+	deprecatedReplacedNotice   = "_This command is deprecated and will be removed, use `%s` instead._"
+	deprecatedDisabledMessage  = "This command has been removed."
+	deprecatedReplacedMessage  = "This command has been removed, use `%s` instead."
+	deprecatedHelpNote         = " (deprecated)"
+	deprecatedReplacedHelpNote = " (deprecated, use %s instead)"
+)
+
+// deprecatedDisabled replies that the listener has been removed if it is Deprecated and the bot's
+// DisableDeprecatedAfter has passed, it returns true if the listener shouldn't run.
+func (bot *Bot) deprecatedDisabled(l *Listener, ev *slack.MessageEvent) bool {
+	if !l.Deprecated || bot.DisableDeprecatedAfter.IsZero() || bot.clock().Now().Before(bot.DisableDeprecatedAfter) {
+		return false
+	}
+	msg := deprecatedDisabledMessage
+	if l.ReplacedBy != "" {
+		msg = fmt.Sprintf(deprecatedReplacedMessage, l.ReplacedBy)
+	}
+	_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), msg)
+	return true
+}
+
+// sendDeprecationNotice tells the user the listener they used is deprecated, after it has run.
+func (bot *Bot) sendDeprecationNotice(l *Listener, ev *slack.MessageEvent) {
+	msg := deprecatedNotice
+	if l.ReplacedBy != "" {
+		msg = fmt.Sprintf(deprecatedReplacedNotice, l.ReplacedBy)
+	}
+	_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), msg)
+}
+
+// deprecationHelpNote returns the note added to a deprecated listener's help text.
+func deprecationHelpNote(l *Listener) string {
+	if !l.Deprecated {
+		return ""
+	}
+	if l.ReplacedBy != "" {
+		return fmt.Sprintf(deprecatedReplacedHelpNote, l.ReplacedBy)
+	}
+	return deprecatedHelpNote
+}
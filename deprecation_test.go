@@ -0,0 +1,100 @@
+package slackbot
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_deprecatedListener(t *testing.T) {
+	tests := []struct {
+		name        string
+		replacedBy  string
+		disableAt   time.Time
+		wantRan     bool
+		wantReplies []string
+	}{
+		{
+			name:        "should run and add a notice",
+			wantRan:     true,
+			wantReplies: []string{"deployed", deprecatedNotice},
+		},
+		{
+			name:        "should suggest the replacement",
+			replacedBy:  "ship <service>",
+			wantRan:     true,
+			wantReplies: []string{"deployed", "_This command is deprecated and will be removed, use `ship <service>` instead._"},
+		},
+		{
+			name:        "should run before the disable date",
+			disableAt:   time.Now().Add(time.Hour),
+			wantRan:     true,
+			wantReplies: []string{"deployed", deprecatedNotice},
+		},
+		{
+			name:        "should not run after the disable date",
+			replacedBy:  "ship <service>",
+			disableAt:   time.Now().Add(-time.Hour),
+			wantReplies: []string{"This command has been removed, use `ship <service>` instead."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var replies []string
+			ran := false
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						replies = append(replies, msgValues(opts...).Get("text"))
+						return s, "ts", nil
+					},
+				},
+				DisableDeprecatedAfter: tt.disableAt,
+				DirectListeners: []Listener{{
+					Regex:      regexp.MustCompile(`^deploy$`),
+					Deprecated: true,
+					ReplacedBy: tt.replacedBy,
+					Handler: func(bot *Bot, ev *slack.MessageEvent) {
+						ran = true
+						_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), "deployed")
+					},
+				}},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			bot.init()
+			bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: "U1", Text: "deploy", Timestamp: "1.1"}})
+			if ran != tt.wantRan {
+				t.Errorf("handler ran = %v, want %v", ran, tt.wantRan)
+			}
+			if !reflect.DeepEqual(replies, tt.wantReplies) {
+				t.Errorf("replies = %q, want %q", replies, tt.wantReplies)
+			}
+		})
+	}
+}
+
+func TestListener_helpTextDeprecated(t *testing.T) {
+	tests := []struct {
+		name string
+		l    Listener
+		want string
+	}{
+		{name: "should not flag current listeners", l: Listener{Usage: "deploy"}, want: "deploy"},
+		{name: "should flag deprecated listeners", l: Listener{Usage: "deploy", Deprecated: true}, want: "deploy (deprecated)"},
+		{
+			name: "should flag deprecated listeners with a replacement",
+			l:    Listener{Usage: "deploy", Deprecated: true, ReplacedBy: "ship", Aliases: []*regexp.Regexp{regexp.MustCompile(`^push$`)}},
+			want: "deploy (aliases: push) (deprecated, use ship instead)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.l.helpText(); got != tt.want {
+				t.Errorf("helpText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package slackbot
+
+import (
+	"regexp"
+)
+
+type (
+	// Description is the metadata of a bot's commands and scheduled tasks returned by Describe. It can be
+	// encoded as JSON so tools can generate documentation or audit what a deployed bot responds to.
+	Description struct {
+		Environment       string               `json:"environment,omitempty"`
+		ReadOnly          bool                 `json:"read_only,omitempty"`
+		ACL               *ACL                 `json:"acl,omitempty"`
+		DirectListeners   []CommandDescription `json:"direct_listeners"`
+		IndirectListeners []CommandDescription `json:"indirect_listeners"`
+		Exchanges         []CommandDescription `json:"exchanges"`
+		Forms             []CommandDescription `json:"forms"`
+		ScheduledTasks    []TaskDescription    `json:"scheduled_tasks"`
+	}
+
+	// CommandDescription describes a listener, exchange or form. Name is the Usage, or the Regex if there
+	// isn't one, the same name used for its analytics.
+	CommandDescription struct {
+		Name          string   `json:"name"`
+		Usage         string   `json:"usage,omitempty"`
+		Category      string   `json:"category,omitempty"`
+		Regex         string   `json:"regex,omitempty"`
+		Aliases       []string `json:"aliases,omitempty"`
+		Examples      []string `json:"examples,omitempty"`
+		ACL           *ACL     `json:"acl,omitempty"`
+		Destructive   bool     `json:"destructive,omitempty"`
+		FeatureFlag   string   `json:"feature_flag,omitempty"`
+		Deprecated    bool     `json:"deprecated,omitempty"`
+		ReplacedBy    string   `json:"replaced_by,omitempty"`
+		AllowExternal bool     `json:"allow_external,omitempty"`
+	}
+
+	// TaskDescription describes a scheduled task.
+	TaskDescription struct {
+		Name          string `json:"name,omitempty"`
+		Schedule      string `json:"schedule"`
+		TargetChannel string `json:"target_channel,omitempty"`
+		WeekdaysOnly  bool   `json:"weekdays_only,omitempty"`
+		SkipHolidays  bool   `json:"skip_holidays,omitempty"`
+	}
+)
+
+// Describe returns the metadata of the bot's listeners, exchanges, forms and scheduled tasks. The Description
+// can be encoded as JSON with encoding/json.
+func (bot *Bot) Describe() Description {
+	d := Description{
+		Environment:       bot.Environment,
+		ReadOnly:          bot.ReadOnly,
+		ACL:               bot.ACL,
+		DirectListeners:   describeListeners(bot.DirectListeners),
+		IndirectListeners: describeListeners(bot.IndirectListeners),
+		Exchanges:         make([]CommandDescription, 0, len(bot.Exchanges)),
+		Forms:             make([]CommandDescription, 0, len(bot.Forms)),
+		ScheduledTasks:    make([]TaskDescription, 0, len(bot.ScheduledTasks)),
+	}
+	for _, e := range bot.Exchanges {
+		d.Exchanges = append(d.Exchanges, CommandDescription{
+			Name:          commandName(e.Usage, e.Regex),
+			Usage:         e.Usage,
+			Category:      e.Category,
+			Regex:         regexSource(e.Regex),
+			Examples:      e.Examples,
+			ACL:           e.ACL,
+			Destructive:   e.Destructive,
+			FeatureFlag:   e.FeatureFlag,
+			AllowExternal: e.AllowExternal,
+		})
+	}
+	for _, f := range bot.Forms {
+		d.Forms = append(d.Forms, CommandDescription{
+			Name:          commandName(f.Usage, f.Regex),
+			Usage:         f.Usage,
+			Category:      f.Category,
+			Regex:         regexSource(f.Regex),
+			Examples:      f.Examples,
+			AllowExternal: f.AllowExternal,
+		})
+	}
+	for _, t := range bot.ScheduledTasks {
+		d.ScheduledTasks = append(d.ScheduledTasks, TaskDescription{
+			Name:          t.Name,
+			Schedule:      t.Schedule,
+			TargetChannel: t.TargetChannel,
+			WeekdaysOnly:  t.WeekdaysOnly,
+			SkipHolidays:  t.SkipHolidays,
+		})
+	}
+	return d
+}
+
+func describeListeners(listeners []Listener) []CommandDescription {
+	descriptions := make([]CommandDescription, 0, len(listeners))
+	for _, l := range listeners {
+		var aliases []string
+		for _, a := range l.Aliases {
+			aliases = append(aliases, a.String())
+		}
+		descriptions = append(descriptions, CommandDescription{
+			Name:          commandName(l.Usage, l.Regex),
+			Usage:         l.Usage,
+			Category:      l.Category,
+			Regex:         regexSource(l.Regex),
+			Aliases:       aliases,
+			Examples:      l.Examples,
+			ACL:           l.ACL,
+			Destructive:   l.Destructive,
+			FeatureFlag:   l.FeatureFlag,
+			Deprecated:    l.Deprecated,
+			ReplacedBy:    l.ReplacedBy,
+			AllowExternal: l.AllowExternal,
+		})
+	}
+	return descriptions
+}
+
+func regexSource(r *regexp.Regexp) string {
+	if r == nil {
+		return ""
+	}
+	return r.String()
+}
@@ -0,0 +1,59 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+)
+
+func TestBot_Describe(t *testing.T) {
+	tests := []struct {
+		name string
+		bot  *Bot
+		want string
+	}{
+		{
+			name: "should describe a bot without commands",
+			bot:  &Bot{},
+			want: `{"direct_listeners":[],"indirect_listeners":[],"exchanges":[],"forms":[],"scheduled_tasks":[]}`,
+		},
+		{
+			name: "should describe the commands and scheduled tasks",
+			bot: &Bot{
+				Environment: "staging",
+				ACL:         &ACL{Groups: []string{"engineering"}},
+				DirectListeners: []Listener{{
+					Usage:       "deploy SERVICE",
+					Category:    "deploys",
+					Regex:       regexp.MustCompile(`^deploy (\S+)$`),
+					Aliases:     []*regexp.Regexp{regexp.MustCompile(`^ship (\S+)$`)},
+					ACL:         &ACL{Users: []string{"U1"}},
+					Destructive: true,
+					FeatureFlag: "deploys",
+				}},
+				IndirectListeners: []Listener{{Regex: regexp.MustCompile(`(?i)thanks`)}},
+				Exchanges:         []Exchange{{Usage: "rollback", Category: "deploys", Regex: regexp.MustCompile(`^rollback$`)}},
+				Forms:             []Form{{Usage: "request access", Regex: regexp.MustCompile(`^request access$`)}},
+				ScheduledTasks:    []ScheduledTask{{Name: "standup", Schedule: "0 9 * * 1-5", TargetChannel: "C1"}},
+			},
+			want: `{"environment":"staging","acl":{"groups":["engineering"]},` +
+				`"direct_listeners":[{"name":"deploy SERVICE","usage":"deploy SERVICE","category":"deploys",` +
+				`"regex":"^deploy (\\S+)$","aliases":["^ship (\\S+)$"],"acl":{"users":["U1"]},"destructive":true,"feature_flag":"deploys"}],` +
+				`"indirect_listeners":[{"name":"(?i)thanks","regex":"(?i)thanks"}],` +
+				`"exchanges":[{"name":"rollback","usage":"rollback","category":"deploys","regex":"^rollback$"}],` +
+				`"forms":[{"name":"request access","usage":"request access","regex":"^request access$"}],` +
+				`"scheduled_tasks":[{"name":"standup","schedule":"0 9 * * 1-5","target_channel":"C1"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.bot.Describe())
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Describe() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
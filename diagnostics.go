@@ -0,0 +1,235 @@
+package slackbot
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	debugPprofPath           = "/debug/pprof/"
+	debugStatsPath           = "/debug/bot"
+	defaultProfileSeconds    = 30
+	maxProfileSeconds        = 300
+	debugAuthorizationScheme = "Bearer "
+)
+
+type (
+	// BotStats is a snapshot of the bot's runtime state, to diagnose a long-running bot that has slowed down.
+	BotStats struct {
+		Goroutines      int `json:"goroutines"`
+		ActiveExchanges int `json:"active_exchanges"`
+		RunningJobs     int `json:"running_jobs"`
+		BusyWorkers     int `json:"busy_workers"`
+
+		// Queues are the number of items waiting in each of the bot's queues, such as the messages in the
+		// SendQueue and the channels with a pending digest.
+		Queues map[string]int `json:"queues"`
+
+		// Caches are the stats of the bot's caches of slack data, by name.
+		Caches map[string]CacheStats `json:"caches"`
+
+		// Backpressure shows whether the bot is keeping up with the events it receives.
+		Backpressure BackpressureStats `json:"backpressure"`
+	}
+
+	// CacheStats are the number of entries in one of the bot's caches, including expired entries that haven't
+	// been removed yet, and how many lookups were found in the cache.
+	CacheStats struct {
+		Entries int `json:"entries"`
+		Hits    int `json:"hits"`
+		Misses  int `json:"misses"`
+	}
+)
+
+// Stats returns a snapshot of the bot's goroutines, exchanges, jobs, queues, caches and backpressure.
+func (bot *Bot) Stats() BotStats {
+	stats := BotStats{
+		Goroutines:   runtime.NumGoroutine(),
+		Queues:       make(map[string]int),
+		Caches:       make(map[string]CacheStats),
+		Backpressure: bot.backpressureStats(),
+	}
+
+	bot.mu.Lock()
+	stats.ActiveExchanges = len(bot.activeExchanges)
+	for _, rj := range bot.jobs {
+		if rj.job.Status == JobRunning {
+			stats.RunningJobs++
+		}
+	}
+	stats.BusyWorkers = len(bot.workers)
+	caches := map[string]*cache{
+		"locations":       bot.locations,
+		"user_groups":     bot.groups,
+		"presence":        bot.presence,
+		"emoji":           bot.emoji,
+		"dm_users":        bot.dmUsers,
+		"deliveries":      bot.deliveries,
+		"shared_channels": bot.sharedChannels,
+		"channel_ids":     bot.channelIDs,
+		"external_users":  bot.externalUsers,
+	}
+	for team, c := range bot.teamUsers {
+		caches["team_users:"+team] = c
+	}
+	bot.mu.Unlock()
+	for name, c := range caches {
+		if c != nil {
+			stats.Caches[name] = c.stats()
+		}
+	}
+
+	if q := bot.SendQueue; q != nil {
+		q.resolveKey(bot)
+		q.mu.Lock()
+		stats.Queues["send_queue"] = len(q.load(bot))
+		q.mu.Unlock()
+	}
+	if od := bot.OutageDetector; od != nil {
+		od.mu.Lock()
+		stats.Queues["outage_buffer"] = len(od.queue)
+		od.mu.Unlock()
+	}
+	bot.digestMu.Lock()
+	stats.Queues["digests"] = len(bot.digests)
+	bot.digestMu.Unlock()
+	bot.confirmMu.Lock()
+	stats.Queues["confirmations"] = len(bot.confirmations)
+	bot.confirmMu.Unlock()
+	bot.escalationMu.Lock()
+	stats.Queues["escalations"] = len(bot.escalateTimers)
+	bot.escalationMu.Unlock()
+	return stats
+}
+
+// DebugHandler returns an http.Handler that serves the bot's Stats as JSON at /debug/bot, and the go runtime's
+// profiles at /debug/pprof/ for `go tool pprof`, such as /debug/pprof/heap, /debug/pprof/goroutine?debug=2 and
+// a CPU profile at /debug/pprof/profile?seconds=30. Requests must have an "Authorization: Bearer <token>"
+// header with the bot's DebugToken, every request is refused if it isn't set. Unlike the net/http/pprof
+// package it doesn't register the profiles on http.DefaultServeMux.
+//
+// Example:
+//
+//	go http.ListenAndServe(":6060", bot.DebugHandler())
+func (bot *Bot) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !bot.debugAllowed(r) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch {
+		case r.URL.Path == debugStatsPath:
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(bot.Stats())
+		case r.URL.Path == debugPprofPath:
+			servePprofIndex(w)
+		case r.URL.Path == debugPprofPath+"profile":
+			serveCPUProfile(w, r, bot.clock())
+		case r.URL.Path == debugPprofPath+"trace":
+			serveTrace(w, r, bot.clock())
+		case strings.HasPrefix(r.URL.Path, debugPprofPath):
+			serveProfile(w, r, strings.TrimPrefix(r.URL.Path, debugPprofPath))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// debugAllowed returns true if the request has the bot's DebugToken. Requests are never allowed without one,
+// since the remote address of a request can't be trusted behind a proxy.
+func (bot *Bot) debugAllowed(r *http.Request) bool {
+	if bot.DebugToken == "" {
+		return false
+	}
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, debugAuthorizationScheme) {
+		return false
+	}
+	token := strings.TrimPrefix(auth, debugAuthorizationScheme)
+	return subtle.ConstantTimeCompare([]byte(token), []byte(bot.DebugToken)) == 1
+}
+
+// servePprofIndex lists the runtime's profiles.
+func servePprofIndex(w http.ResponseWriter) {
+	profiles := pprof.Profiles()
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name() < profiles[j].Name() })
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	for _, p := range profiles {
+		fmt.Fprintf(w, "%d\t%s%s\n", p.Count(), debugPprofPath, p.Name())
+	}
+	fmt.Fprintf(w, "\t%sprofile?seconds=%d\n", debugPprofPath, defaultProfileSeconds)
+	fmt.Fprintf(w, "\t%strace?seconds=1\n", debugPprofPath)
+}
+
+// serveProfile writes the named profile, in the text format if the debug parameter is set.
+func serveProfile(w http.ResponseWriter, r *http.Request, name string) {
+	p := pprof.Lookup(name)
+	if p == nil {
+		http.NotFound(w, r)
+		return
+	}
+	debug, _ := strconv.Atoi(r.URL.Query().Get("debug"))
+	if name == "heap" && r.URL.Query().Get("gc") != "" {
+		runtime.GC()
+	}
+	if debug > 0 {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	}
+	_ = p.WriteTo(w, debug)
+}
+
+// serveCPUProfile profiles the CPU for the number of seconds in the request.
+func serveCPUProfile(w http.ResponseWriter, r *http.Request, clock Clock) {
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="profile"`)
+	if err := pprof.StartCPUProfile(w); err != nil {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Del("Content-Disposition")
+		http.Error(w, fmt.Sprintf("unable to start the cpu profile - %s", err), http.StatusInternalServerError)
+		return
+	}
+	waitProfileSeconds(r, clock, defaultProfileSeconds)
+	pprof.StopCPUProfile()
+}
+
+// serveTrace traces the runtime for the number of seconds in the request.
+func serveTrace(w http.ResponseWriter, r *http.Request, clock Clock) {
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="trace"`)
+	if err := trace.Start(w); err != nil {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Del("Content-Disposition")
+		http.Error(w, fmt.Sprintf("unable to start the trace - %s", err), http.StatusInternalServerError)
+		return
+	}
+	waitProfileSeconds(r, clock, 1)
+	trace.Stop()
+}
+
+// waitProfileSeconds waits on the clock for the seconds parameter of the request, or until the request is
+// cancelled.
+func waitProfileSeconds(r *http.Request, clock Clock, defaultSeconds int) {
+	seconds, err := strconv.Atoi(r.URL.Query().Get("seconds"))
+	if err != nil || seconds <= 0 {
+		seconds = defaultSeconds
+	}
+	if seconds > maxProfileSeconds {
+		seconds = maxProfileSeconds
+	}
+	select {
+	case <-clock.After(time.Duration(seconds) * time.Second):
+	case <-r.Context().Done():
+	}
+}
@@ -0,0 +1,182 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_Stats(t *testing.T) {
+	bot := &Bot{API: &mockAPI{}, SendQueue: &SendQueue{}, Store: NewMemoryStore(nil), userDetails: &slack.UserDetails{ID: "bot"}}
+	_ = bot.Store.Put(sendQueueStoreKey+":bot", []queuedSend{{ID: "1"}, {ID: "2"}})
+	bot.activeExchanges = map[string]*Exchange{"1.1": {}}
+	bot.jobs = map[string]*runningJob{
+		"a": {job: Job{Status: JobRunning}},
+		"b": {job: Job{Status: JobSucceeded}},
+	}
+	bot.emoji = newCache(0, RealClock)
+	bot.emoji.set("party", "url")
+	bot.emoji.get("party")
+
+	stats := bot.Stats()
+	if stats.Goroutines == 0 {
+		t.Errorf("Stats() Goroutines = 0, want the number of goroutines")
+	}
+	if stats.ActiveExchanges != 1 {
+		t.Errorf("Stats() ActiveExchanges = %d, want 1", stats.ActiveExchanges)
+	}
+	if stats.RunningJobs != 1 {
+		t.Errorf("Stats() RunningJobs = %d, want 1", stats.RunningJobs)
+	}
+	if got := stats.Queues["send_queue"]; got != 2 {
+		t.Errorf("Stats() send_queue = %d, want 2", got)
+	}
+	if got, want := stats.Caches["emoji"], (CacheStats{Entries: 1, Hits: 1}); got != want {
+		t.Errorf("Stats() emoji cache = %+v, want %+v", got, want)
+	}
+	if _, ok := stats.Caches["locations"]; ok {
+		t.Errorf("Stats() included a cache that hasn't been created")
+	}
+}
+
+func TestBot_DebugHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		path       string
+		remoteAddr string
+		auth       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "should refuse loopback requests without a token",
+			path:       "/debug/bot",
+			remoteAddr: "127.0.0.1:5000",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "should refuse other requests without a token",
+			path:       "/debug/bot",
+			remoteAddr: "10.0.0.5:5000",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "should refuse profiles without a token",
+			path:       "/debug/pprof/goroutine?debug=1",
+			remoteAddr: "127.0.0.1:5000",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "should serve requests with the token",
+			token:      "secret",
+			path:       "/debug/bot",
+			remoteAddr: "10.0.0.5:5000",
+			auth:       "Bearer secret",
+			wantStatus: http.StatusOK,
+			wantBody:   `"queues"`,
+		},
+		{
+			name:       "should refuse requests with the wrong token",
+			token:      "secret",
+			path:       "/debug/bot",
+			remoteAddr: "127.0.0.1:5000",
+			auth:       "Bearer guess",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "should refuse requests without the token when one is set",
+			token:      "secret",
+			path:       "/debug/bot",
+			remoteAddr: "127.0.0.1:5000",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "should list the profiles",
+			token:      "secret",
+			auth:       "Bearer secret",
+			path:       "/debug/pprof/",
+			remoteAddr: "[::1]:5000",
+			wantStatus: http.StatusOK,
+			wantBody:   "/debug/pprof/goroutine",
+		},
+		{
+			name:       "should serve a profile",
+			token:      "secret",
+			auth:       "Bearer secret",
+			path:       "/debug/pprof/goroutine?debug=1",
+			remoteAddr: "127.0.0.1:5000",
+			wantStatus: http.StatusOK,
+			wantBody:   "goroutine profile",
+		},
+		{
+			name:       "should not find unknown profiles",
+			token:      "secret",
+			auth:       "Bearer secret",
+			path:       "/debug/pprof/unknown",
+			remoteAddr: "127.0.0.1:5000",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{API: &mockAPI{}, DebugToken: tt.token}
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			bot.DebugHandler().ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want it to contain %q", rec.Body.String(), tt.wantBody)
+			}
+			if tt.path == "/debug/bot" && rec.Code == http.StatusOK {
+				var stats BotStats
+				if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+					t.Errorf("unable to decode the stats - %s", err)
+				}
+			}
+		})
+	}
+}
+
+func Test_waitProfileSeconds(t *testing.T) {
+	now := time.Date(2021, 3, 5, 12, 0, 0, 0, time.UTC)
+	req := httptest.NewRequest(http.MethodGet, "/debug/pprof/profile?seconds=5", nil)
+	clock := &afterClock{Clock: RealClock, after: make(chan time.Time, 1)}
+	clock.after <- now
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		waitProfileSeconds(req, clock, defaultProfileSeconds)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("waitProfileSeconds() did not wait on the clock")
+	}
+	if clock.waited != 5*time.Second {
+		t.Errorf("waitProfileSeconds() waited %s, want 5s", clock.waited)
+	}
+}
+
+// afterClock is a Clock whose After returns the after channel and records the duration it was called with.
+type afterClock struct {
+	Clock
+	after  chan time.Time
+	waited time.Duration
+}
+
+func (c *afterClock) After(d time.Duration) <-chan time.Time {
+	c.waited = d
+	return c.after
+}
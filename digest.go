@@ -0,0 +1,109 @@
+package slackbot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDigestInterval = 5 * time.Minute
+	defaultDigestMaxLines = 50
+	digestLineFormat      = "• %s"
+)
+
+// digestBatch collects the lines for one channel's digest, grouped by key in the order the keys were first seen.
+type digestBatch struct {
+	keys  []string
+	lines map[string][]string
+	count int
+	timer Timer
+}
+
+// Digest adds a line to the channel's digest instead of sending it straight away. The lines are posted as a
+// single message every DigestInterval, five minutes by default, or as soon as the digest has DigestMaxLines
+// lines, 50 by default. Lines with the same key are listed together under it, such as the name of the
+// service an alert is for, and an empty key lists lines without a heading. This is useful for noisy
+// notifications where a line each is more than anyone will read.
+func (bot *Bot) Digest(channel string, key string, line string) {
+	bot.digestMu.Lock()
+	if bot.digests == nil {
+		bot.digests = make(map[string]*digestBatch)
+	}
+	d, ok := bot.digests[channel]
+	if !ok {
+		d = &digestBatch{lines: make(map[string][]string)}
+		d.timer = bot.clock().AfterFunc(bot.digestInterval(), func() { bot.flushDigest(channel, d) })
+		bot.digests[channel] = d
+	}
+	if _, ok := d.lines[key]; !ok {
+		d.keys = append(d.keys, key)
+	}
+	d.lines[key] = append(d.lines[key], line)
+	d.count++
+	full := d.count >= bot.digestMaxLines()
+	bot.digestMu.Unlock()
+
+	if full {
+		bot.flushDigest(channel, d)
+	}
+}
+
+// flushDigest posts the digest if it is still the channel's current one, the next line starts a new digest.
+func (bot *Bot) flushDigest(channel string, d *digestBatch) {
+	bot.digestMu.Lock()
+	if bot.digests[channel] != d {
+		bot.digestMu.Unlock()
+		return
+	}
+	delete(bot.digests, channel)
+	d.timer.Stop()
+	bot.digestMu.Unlock()
+
+	for _, msg := range splitDebugBatch(d.render()) {
+		if _, _, err := bot.Reply(channel, msg); err != nil {
+			bot.LogError(fmt.Sprintf("error sending digest to %s - %s", channel, err))
+		}
+	}
+}
+
+// flushDigests posts all of the pending digests, so they aren't lost when the bot stops.
+func (bot *Bot) flushDigests() {
+	bot.digestMu.Lock()
+	digests := make(map[string]*digestBatch, len(bot.digests))
+	for channel, d := range bot.digests {
+		digests[channel] = d
+	}
+	bot.digestMu.Unlock()
+	for channel, d := range digests {
+		bot.flushDigest(channel, d)
+	}
+}
+
+// render returns the digest's lines, with the lines for each key after it in bold.
+func (d *digestBatch) render() []string {
+	lines := make([]string, 0, d.count+len(d.keys))
+	for _, key := range d.keys {
+		if key != "" {
+			lines = append(lines, fmt.Sprintf("*%s*", key))
+		}
+		for _, l := range d.lines[key] {
+			lines = append(lines, fmt.Sprintf(digestLineFormat, strings.TrimSpace(l)))
+		}
+	}
+	return lines
+}
+
+func (bot *Bot) digestInterval() time.Duration {
+	if bot.DigestInterval > 0 {
+		return bot.DigestInterval
+	}
+	return defaultDigestInterval
+}
+
+func (bot *Bot) digestMaxLines() int {
+	if bot.DigestMaxLines > 0 {
+		return bot.DigestMaxLines
+	}
+	return defaultDigestMaxLines
+}
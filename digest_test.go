@@ -0,0 +1,93 @@
+package slackbot
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_Digest(t *testing.T) {
+	type line struct {
+		channel string
+		key     string
+		text    string
+	}
+	tests := []struct {
+		name     string
+		maxLines int
+		lines    []line
+		stop     bool
+		want     map[string][]string
+	}{
+		{
+			name: "should post the lines grouped by key after the interval",
+			lines: []line{
+				{channel: "C1", key: "api", text: "5xx rate high"},
+				{channel: "C1", key: "db", text: "replica lag"},
+				{channel: "C1", key: "api", text: "latency high"},
+				{channel: "C1", text: "certificate expires soon"},
+			},
+			want: map[string][]string{"C1": {"*api*\n• 5xx rate high\n• latency high\n*db*\n• replica lag\n• certificate expires soon"}},
+		},
+		{
+			name: "should post a digest for each channel",
+			lines: []line{
+				{channel: "C1", key: "api", text: "5xx rate high"},
+				{channel: "C2", key: "db", text: "replica lag"},
+			},
+			want: map[string][]string{"C1": {"*api*\n• 5xx rate high"}, "C2": {"*db*\n• replica lag"}},
+		},
+		{
+			name:     "should post the digest when it has the max lines",
+			maxLines: 2,
+			lines: []line{
+				{channel: "C1", text: "one"},
+				{channel: "C1", text: "two"},
+				{channel: "C1", text: "three"},
+			},
+			want: map[string][]string{"C1": {"• one\n• two", "• three"}},
+		},
+		{
+			name:  "should post pending digests when the bot stops",
+			lines: []line{{channel: "C1", text: "one"}},
+			stop:  true,
+			want:  map[string][]string{"C1": {"• one"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			got := map[string][]string{}
+			interval := 20 * time.Millisecond
+			if tt.stop {
+				interval = time.Hour
+			}
+			bot := &Bot{
+				DigestInterval: interval,
+				DigestMaxLines: tt.maxLines,
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						mu.Lock()
+						defer mu.Unlock()
+						got[s] = append(got[s], msgValues(opts...).Get("text"))
+						return s, "ts", nil
+					},
+				},
+			}
+			for _, l := range tt.lines {
+				bot.Digest(l.channel, l.key, l.text)
+			}
+			if tt.stop {
+				bot.Stop()
+			}
+			waitFor(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return reflect.DeepEqual(got, tt.want)
+			})
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package slackbot
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DocsMarkdown generates docs as Markdown, for wikis and READMEs.
+	DocsMarkdown DocsFormat = "markdown"
+	// DocsHTML generates docs as an HTML fragment, to be embedded in a page.
+	DocsHTML DocsFormat = "html"
+
+	docsTitle             = "Commands"
+	docsOtherCategory     = "Other"
+	docsTasksTitle        = "Scheduled Tasks"
+	docsDestructiveNote   = "asks for confirmation"
+	docsRestrictedNote    = "restricted"
+	docsDeprecatedNote    = "deprecated"
+	docsReplacedNote      = "deprecated, use %s instead"
+	docsExamplesLabel     = "Examples:"
+	docsMissingUsageLabel = "matches"
+)
+
+// DocsFormat is the format of the docs produced by GenerateDocs.
+type DocsFormat string
+
+// docsCategory is the commands in a category, in the order they are defined on the bot.
+type docsCategory struct {
+	name     string
+	commands []CommandDescription
+}
+
+// GenerateDocs returns a reference of the bot's commands in the format, so teams can publish the docs for a
+// bot to their wiki as part of deploying it. The direct listeners, exchanges and forms are listed by Category
+// with their Examples, commands without a category are listed last under "Other", and the bot's scheduled
+// tasks are listed at the end. It is built from the bot's Describe output.
+func GenerateDocs(bot *Bot, format DocsFormat) (string, error) {
+	d := bot.Describe()
+	categories := docsCategories(d)
+	switch format {
+	case DocsMarkdown:
+		return markdownDocs(categories, d.ScheduledTasks), nil
+	case DocsHTML:
+		return htmlDocs(categories, d.ScheduledTasks), nil
+	default:
+		return "", errors.Errorf("unknown docs format %q", format)
+	}
+}
+
+// docsCategories groups the commands users can send by category, sorted by name with "Other" last.
+func docsCategories(d Description) []docsCategory {
+	byName := map[string]*docsCategory{}
+	var names []string
+	for _, group := range [][]CommandDescription{d.DirectListeners, d.Exchanges, d.Forms} {
+		for _, c := range group {
+			name := c.Category
+			if name == "" {
+				name = docsOtherCategory
+			}
+			if _, ok := byName[name]; !ok {
+				byName[name] = &docsCategory{name: name}
+				names = append(names, name)
+			}
+			byName[name].commands = append(byName[name].commands, c)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == docsOtherCategory || names[j] == docsOtherCategory {
+			return names[j] == docsOtherCategory
+		}
+		return names[i] < names[j]
+	})
+	categories := make([]docsCategory, len(names))
+	for i, name := range names {
+		categories[i] = *byName[name]
+	}
+	return categories
+}
+
+func markdownDocs(categories []docsCategory, tasks []TaskDescription) string {
+	var b strings.Builder
+	b.WriteString("# " + docsTitle + "\n")
+	for _, category := range categories {
+		b.WriteString("\n## " + category.name + "\n\n")
+		for _, c := range category.commands {
+			b.WriteString(fmt.Sprintf("- %s%s\n", commandTitle(c, "`%s`"), commandNotes(c, " _(%s)_")))
+			if len(c.Examples) > 0 {
+				examples := make([]string, len(c.Examples))
+				for i, e := range c.Examples {
+					examples[i] = "`" + e + "`"
+				}
+				b.WriteString(fmt.Sprintf("  - %s %s\n", docsExamplesLabel, strings.Join(examples, ", ")))
+			}
+		}
+	}
+	if len(tasks) > 0 {
+		b.WriteString("\n## " + docsTasksTitle + "\n\n")
+		for _, t := range tasks {
+			b.WriteString(fmt.Sprintf("- %s `%s`\n", taskTitle(t), t.Schedule))
+		}
+	}
+	return b.String()
+}
+
+func htmlDocs(categories []docsCategory, tasks []TaskDescription) string {
+	var b strings.Builder
+	b.WriteString("<h1>" + docsTitle + "</h1>\n")
+	for _, category := range categories {
+		b.WriteString("<h2>" + html.EscapeString(category.name) + "</h2>\n<ul>\n")
+		for _, c := range category.commands {
+			b.WriteString("<li>" + commandTitle(c, "<code>%s</code>") + commandNotes(c, " <em>(%s)</em>"))
+			if len(c.Examples) > 0 {
+				examples := make([]string, len(c.Examples))
+				for i, e := range c.Examples {
+					examples[i] = "<code>" + html.EscapeString(e) + "</code>"
+				}
+				b.WriteString(fmt.Sprintf("<br>%s %s", docsExamplesLabel, strings.Join(examples, ", ")))
+			}
+			b.WriteString("</li>\n")
+		}
+		b.WriteString("</ul>\n")
+	}
+	if len(tasks) > 0 {
+		b.WriteString("<h2>" + docsTasksTitle + "</h2>\n<ul>\n")
+		for _, t := range tasks {
+			b.WriteString(fmt.Sprintf("<li>%s <code>%s</code></li>\n", html.EscapeString(taskTitle(t)), html.EscapeString(t.Schedule)))
+		}
+		b.WriteString("</ul>\n")
+	}
+	return b.String()
+}
+
+// commandTitle returns the command's Usage, or its Regex formatted as code if it doesn't have one. Text is
+// escaped for HTML when the code format is an HTML tag.
+func commandTitle(c CommandDescription, code string) string {
+	escape := func(s string) string { return s }
+	if strings.HasPrefix(code, "<") {
+		escape = html.EscapeString
+	}
+	if c.Usage != "" {
+		return escape(c.Usage)
+	}
+	return docsMissingUsageLabel + " " + fmt.Sprintf(code, escape(c.Regex))
+}
+
+// commandNotes returns notes for deprecated, destructive and restricted commands in the format, or an empty
+// string. The notes are escaped for HTML when the format is HTML.
+func commandNotes(c CommandDescription, format string) string {
+	var notes []string
+	if c.Deprecated && c.ReplacedBy != "" {
+		notes = append(notes, fmt.Sprintf(docsReplacedNote, c.ReplacedBy))
+	} else if c.Deprecated {
+		notes = append(notes, docsDeprecatedNote)
+	}
+	if c.Destructive {
+		notes = append(notes, docsDestructiveNote)
+	}
+	if c.ACL != nil && (len(c.ACL.Users) > 0 || len(c.ACL.Groups) > 0 || len(c.ACL.Channels) > 0) {
+		notes = append(notes, docsRestrictedNote)
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	joined := strings.Join(notes, ", ")
+	if strings.HasPrefix(strings.TrimSpace(format), "<") {
+		joined = html.EscapeString(joined)
+	}
+	return fmt.Sprintf(format, joined)
+}
+
+func taskTitle(t TaskDescription) string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return "unnamed task"
+}
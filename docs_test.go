@@ -0,0 +1,75 @@
+package slackbot
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGenerateDocs(t *testing.T) {
+	bot := &Bot{
+		DirectListeners: []Listener{
+			{Usage: "status", Regex: regexp.MustCompile(`^status$`), Deprecated: true, ReplacedBy: "health <service>"},
+			{
+				Usage:       "deploy <service>",
+				Category:    "deploys",
+				Regex:       regexp.MustCompile(`^deploy (\S+)$`),
+				Examples:    []string{"deploy api", "deploy web"},
+				Destructive: true,
+				ACL:         &ACL{Users: []string{"U1"}},
+			},
+		},
+		Exchanges:      []Exchange{{Category: "access", Regex: regexp.MustCompile(`^grant$`)}},
+		Forms:          []Form{{Usage: "rollback", Category: "deploys", Regex: regexp.MustCompile(`^rollback$`)}},
+		ScheduledTasks: []ScheduledTask{{Name: "standup", Schedule: "0 9 * * 1-5"}},
+	}
+	tests := []struct {
+		name    string
+		format  DocsFormat
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "should generate markdown grouped by category",
+			format: DocsMarkdown,
+			want: "# Commands\n" +
+				"\n## access\n\n" +
+				"- matches `^grant$`\n" +
+				"\n## deploys\n\n" +
+				"- deploy <service> _(asks for confirmation, restricted)_\n" +
+				"  - Examples: `deploy api`, `deploy web`\n" +
+				"- rollback\n" +
+				"\n## Other\n\n" +
+				"- status _(deprecated, use health <service> instead)_\n" +
+				"\n## Scheduled Tasks\n\n" +
+				"- standup `0 9 * * 1-5`\n",
+		},
+		{
+			name:   "should generate escaped html grouped by category",
+			format: DocsHTML,
+			want: "<h1>Commands</h1>\n" +
+				"<h2>access</h2>\n<ul>\n<li>matches <code>^grant$</code></li>\n</ul>\n" +
+				"<h2>deploys</h2>\n<ul>\n" +
+				"<li>deploy &lt;service&gt; <em>(asks for confirmation, restricted)</em>" +
+				"<br>Examples: <code>deploy api</code>, <code>deploy web</code></li>\n" +
+				"<li>rollback</li>\n</ul>\n" +
+				"<h2>Other</h2>\n<ul>\n<li>status <em>(deprecated, use health &lt;service&gt; instead)</em></li>\n</ul>\n" +
+				"<h2>Scheduled Tasks</h2>\n<ul>\n<li>standup <code>0 9 * * 1-5</code></li>\n</ul>\n",
+		},
+		{
+			name:    "should return an error for an unknown format",
+			format:  "pdf",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateDocs(bot, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenerateDocs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateDocs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package slackbot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	dryRunMessage            = "[dry run] would %s to %s: %s"
+	dryRunFlag               = "--dry-run"
+	dryRunBanner             = ":construction: *DRY RUN* - nothing was changed."
+	dryRunUnsupportedMessage = "This command doesn't support --dry-run."
+)
+
+// dryRunClient wraps the bot's client when DryRun is set. Methods that send or change messages are logged
+// instead of being sent, except for messages to the bot's debug and error channels. All other methods are
+// passed through to the wrapped client so listeners can still read from slack.
+type dryRunClient struct {
+	MessagingClient
+	bot *Bot
+	mu  sync.Mutex
+	ts  int
+}
+
+func (c *dryRunClient) PostMessage(channel string, options ...slack.MsgOption) (string, string, error) {
+	return c.PostMessageContext(context.Background(), channel, options...)
+}
+
+func (c *dryRunClient) PostMessageContext(ctx context.Context, channel string, options ...slack.MsgOption) (string, string, error) {
+	if c.isLogChannel(channel) {
+		return c.MessagingClient.PostMessageContext(ctx, channel, options...)
+	}
+	c.record("post", channel, options...)
+	return channel, c.nextTimestamp(), nil
+}
+
+func (c *dryRunClient) PostEphemeral(channel string, user string, options ...slack.MsgOption) (string, error) {
+	c.record("post ephemeral message for "+user, channel, options...)
+	return c.nextTimestamp(), nil
+}
+
+func (c *dryRunClient) PostEphemeralContext(_ context.Context, channel string, user string, options ...slack.MsgOption) (string, error) {
+	return c.PostEphemeral(channel, user, options...)
+}
+
+func (c *dryRunClient) UpdateMessage(channel string, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+	return c.UpdateMessageContext(context.Background(), channel, timestamp, options...)
+}
+
+func (c *dryRunClient) UpdateMessageContext(ctx context.Context, channel string, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+	if c.isLogChannel(channel) {
+		return c.MessagingClient.UpdateMessageContext(ctx, channel, timestamp, options...)
+	}
+	text := c.record("update message "+timestamp, channel, options...)
+	return channel, timestamp, text, nil
+}
+
+func (c *dryRunClient) DeleteMessage(channel string, timestamp string) (string, string, error) {
+	c.log("delete message", channel, timestamp)
+	return channel, timestamp, nil
+}
+
+func (c *dryRunClient) DeleteMessageContext(_ context.Context, channel string, timestamp string) (string, string, error) {
+	return c.DeleteMessage(channel, timestamp)
+}
+
+func (c *dryRunClient) ScheduleMessage(channel string, postAt string, options ...slack.MsgOption) (string, string, error) {
+	c.record("schedule message at "+postAt, channel, options...)
+	return channel, postAt, nil
+}
+
+func (c *dryRunClient) DeleteScheduledMessage(params *slack.DeleteScheduledMessageParameters) (bool, error) {
+	c.log("delete scheduled message", params.Channel, params.ScheduledMessageID)
+	return true, nil
+}
+
+func (c *dryRunClient) DeleteScheduledMessageContext(_ context.Context, params *slack.DeleteScheduledMessageParameters) (bool, error) {
+	return c.DeleteScheduledMessage(params)
+}
+
+func (c *dryRunClient) UploadFile(params slack.FileUploadParameters) (*slack.File, error) {
+	c.log("upload file", fmt.Sprint(params.Channels), params.Filename)
+	return &slack.File{Name: params.Filename}, nil
+}
+
+func (c *dryRunClient) UploadFileContext(_ context.Context, params slack.FileUploadParameters) (*slack.File, error) {
+	return c.UploadFile(params)
+}
+
+func (c *dryRunClient) AddReaction(name string, item slack.ItemRef) error {
+	c.log("add reaction", item.Channel, ":"+name+":")
+	return nil
+}
+
+func (c *dryRunClient) AddReactionContext(_ context.Context, name string, item slack.ItemRef) error {
+	return c.AddReaction(name, item)
+}
+
+func (c *dryRunClient) AddUserReminder(user string, text string, when string) (*slack.Reminder, error) {
+	c.log("add reminder at "+when, user, text)
+	return &slack.Reminder{User: user, Text: text}, nil
+}
+
+func (c *dryRunClient) AddChannelReminder(channel string, text string, when string) (*slack.Reminder, error) {
+	c.log("add reminder at "+when, channel, text)
+	return &slack.Reminder{Text: text}, nil
+}
+
+func (c *dryRunClient) PublishView(user string, view slack.HomeTabViewRequest, hash string) (*slack.ViewResponse, error) {
+	c.log("publish home tab", user, fmt.Sprintf("%d blocks", len(view.Blocks.BlockSet)))
+	return &slack.ViewResponse{}, nil
+}
+
+func (c *dryRunClient) PublishViewContext(_ context.Context, user string, view slack.HomeTabViewRequest, hash string) (*slack.ViewResponse, error) {
+	return c.PublishView(user, view, hash)
+}
+
+func (c *dryRunClient) OpenView(triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error) {
+	title := ""
+	if view.Title != nil {
+		title = view.Title.Text
+	}
+	c.log("open modal", triggerID, title)
+	return &slack.ViewResponse{}, nil
+}
+
+func (c *dryRunClient) OpenViewContext(_ context.Context, triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error) {
+	return c.OpenView(triggerID, view)
+}
+
+func (c *dryRunClient) UnfurlMessage(channel string, timestamp string, unfurls map[string]slack.Attachment, options ...slack.MsgOption) (string, string, string, error) {
+	for link := range unfurls {
+		c.log("unfurl "+link, channel, timestamp)
+	}
+	return channel, timestamp, "", nil
+}
+
+func (c *dryRunClient) CreateConversation(name string, isPrivate bool) (*slack.Channel, error) {
+	c.log("create channel", name, "")
+	ch := &slack.Channel{}
+	ch.ID = name
+	ch.Name = name
+	return ch, nil
+}
+
+func (c *dryRunClient) CreateConversationContext(_ context.Context, name string, isPrivate bool) (*slack.Channel, error) {
+	return c.CreateConversation(name, isPrivate)
+}
+
+func (c *dryRunClient) UnArchiveConversation(channel string) error {
+	c.log("unarchive channel", channel, "")
+	return nil
+}
+
+func (c *dryRunClient) UnArchiveConversationContext(_ context.Context, channel string) error {
+	return c.UnArchiveConversation(channel)
+}
+
+func (c *dryRunClient) InviteUsersToConversation(channel string, users ...string) (*slack.Channel, error) {
+	c.log("invite users", channel, fmt.Sprint(users))
+	ch := &slack.Channel{}
+	ch.ID = channel
+	return ch, nil
+}
+
+func (c *dryRunClient) InviteUsersToConversationContext(_ context.Context, channel string, users ...string) (*slack.Channel, error) {
+	return c.InviteUsersToConversation(channel, users...)
+}
+
+func (c *dryRunClient) SetTopicOfConversation(channel string, topic string) (*slack.Channel, error) {
+	c.log("set topic", channel, topic)
+	ch := &slack.Channel{}
+	ch.ID = channel
+	return ch, nil
+}
+
+func (c *dryRunClient) SetTopicOfConversationContext(_ context.Context, channel string, topic string) (*slack.Channel, error) {
+	return c.SetTopicOfConversation(channel, topic)
+}
+
+func (c *dryRunClient) SetPurposeOfConversation(channel string, purpose string) (*slack.Channel, error) {
+	c.log("set purpose", channel, purpose)
+	ch := &slack.Channel{}
+	ch.ID = channel
+	return ch, nil
+}
+
+func (c *dryRunClient) SetPurposeOfConversationContext(_ context.Context, channel string, purpose string) (*slack.Channel, error) {
+	return c.SetPurposeOfConversation(channel, purpose)
+}
+
+func (c *dryRunClient) ArchiveConversation(channel string) error {
+	c.log("archive channel", channel, "")
+	return nil
+}
+
+func (c *dryRunClient) ArchiveConversationContext(_ context.Context, channel string) error {
+	return c.ArchiveConversation(channel)
+}
+
+func (c *dryRunClient) AddPin(channel string, item slack.ItemRef) error {
+	c.log("pin message", channel, item.Timestamp)
+	return nil
+}
+
+func (c *dryRunClient) AddPinContext(_ context.Context, channel string, item slack.ItemRef) error {
+	return c.AddPin(channel, item)
+}
+
+func (c *dryRunClient) RemovePin(channel string, item slack.ItemRef) error {
+	c.log("unpin message", channel, item.Timestamp)
+	return nil
+}
+
+func (c *dryRunClient) RemovePinContext(_ context.Context, channel string, item slack.ItemRef) error {
+	return c.RemovePin(channel, item)
+}
+
+func (c *dryRunClient) isLogChannel(channel string) bool {
+	return channel != "" && (channel == c.bot.DebugChannel || channel == c.bot.ErrorChannel)
+}
+
+// record logs the text of the message the options would have sent and returns it.
+func (c *dryRunClient) record(action string, channel string, options ...slack.MsgOption) string {
+	values, err := encodeMsgOptions(options...)
+	if err != nil {
+		c.log(action, channel, fmt.Sprintf("invalid message options - %s", err))
+		return ""
+	}
+	text := values.Get("text")
+	if values.Get("attachments") != "" || values.Get("blocks") != "" {
+		text += " [with attachments or blocks]"
+	}
+	c.log(action, channel, text)
+	return values.Get("text")
+}
+
+// log writes the action to std out and, if MirrorDryRun is set, to the debug channel.
+func (c *dryRunClient) log(action string, channel string, detail string) {
+	msg := c.bot.redact(fmt.Sprintf(dryRunMessage, action, channel, detail))
+	log.Println(msg)
+	if c.bot.MirrorDryRun && c.bot.DebugChannel != "" {
+		_, _, err := c.MessagingClient.PostMessage(c.bot.DebugChannel, slack.MsgOptionText(msg, false), slack.MsgOptionAsUser(true))
+		if err != nil {
+			log.Printf("Error sending message to debug channel %s - %s\n", c.bot.DebugChannel, err)
+		}
+	}
+}
+
+func (c *dryRunClient) nextTimestamp() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ts++
+	return fmt.Sprintf("%d.%06d", c.bot.clock().Now().Unix(), c.ts)
+}
+
+// parseDryRunFlag removes a trailing --dry-run from the message text, returning true if it was there.
+func parseDryRunFlag(text string) (string, bool) {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasSuffix(trimmed, dryRunFlag) {
+		return text, false
+	}
+	rest := strings.TrimSuffix(trimmed, dryRunFlag)
+	if rest != "" && !unicode.IsSpace(rune(rest[len(rest)-1])) {
+		return text, false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// IsDryRun returns true if the command was sent with a trailing --dry-run, such as "deploy api --dry-run".
+// The flag is removed from the event's text before it is matched, so handlers should check IsDryRun and
+// preview what they would do instead of doing it. Listeners with a Handler instead of a ContextHandler
+// can't tell, so the bot replies that they don't support dry runs rather than running them.
+func (ctx *MessageContext) IsDryRun() bool {
+	return ctx.dryRun
+}
+
+// Reply sends a message in the thread of the event that triggered the listener, starting with a DRY RUN
+// banner if the command was sent with --dry-run.
+func (ctx *MessageContext) Reply(text string) (respChannel string, timestamp string, err error) {
+	return ctx.ReplyWithOptions(slack.MsgOptionText(text, false))
+}
+
+// ReplyWithOptions sends a message with the options in the thread of the event that triggered the listener,
+// starting with a DRY RUN banner if the command was sent with --dry-run.
+func (ctx *MessageContext) ReplyWithOptions(options ...slack.MsgOption) (respChannel string, timestamp string, err error) {
+	options = append(options, slack.MsgOptionTS(threadTimestamp(ctx.Event)))
+	if ctx.dryRun {
+		if options, err = withDryRunBanner(options); err != nil {
+			return "", "", err
+		}
+	}
+	return ctx.Bot.reply(ctx, PriorityReply, ctx.Event.Channel, options)
+}
+
+// withDryRunBanner adds the DRY RUN banner to the start of the message's text and blocks.
+func withDryRunBanner(options []slack.MsgOption) ([]slack.MsgOption, error) {
+	values, err := encodeMsgOptions(options...)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to add the dry run banner")
+	}
+	values.Set("text", strings.TrimSpace(dryRunBanner+" "+values.Get("text")))
+	if v := values.Get("blocks"); v != "" {
+		var blocks slack.Blocks
+		if err := json.Unmarshal([]byte(v), &blocks); err != nil {
+			return nil, errors.Wrap(err, "unable to add the dry run banner")
+		}
+		banner := slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, dryRunBanner, false, false))
+		b, err := json.Marshal(append([]slack.Block{banner}, blocks.BlockSet...))
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to add the dry run banner")
+		}
+		values.Set("blocks", string(b))
+	}
+	return decodeMsgOptions(values)
+}
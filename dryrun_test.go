@@ -0,0 +1,245 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_DryRun(t *testing.T) {
+	tests := []struct {
+		name         string
+		mirror       bool
+		channel      string
+		wantChannels []string
+		wantTexts    []string
+	}{
+		{
+			name:    "should not post messages",
+			channel: "C123",
+		},
+		{
+			name:         "should mirror messages to the debug channel",
+			mirror:       true,
+			channel:      "C123",
+			wantChannels: []string{"debug"},
+			wantTexts:    []string{"[dry run] would post to C123: hello"},
+		},
+		{
+			name:         "should still post to the debug channel",
+			channel:      "debug",
+			wantChannels: []string{"debug"},
+			wantTexts:    []string{"hello"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotChannels, gotTexts []string
+			bot := &Bot{
+				DryRun:       true,
+				MirrorDryRun: tt.mirror,
+				DebugChannel: "debug",
+				API: &mockAPI{
+					getChannel: func(s string) (slack.Channel, error) {
+						c := slack.Channel{}
+						c.ID = s
+						return c, nil
+					},
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						gotChannels = append(gotChannels, s)
+						gotTexts = append(gotTexts, msgValues(opts...).Get("text"))
+						return s, "ts", nil
+					},
+				},
+			}
+			bot.once.Do(bot.init)
+
+			c, ts, err := bot.Reply(tt.channel, "hello")
+			if err != nil || c != tt.channel || ts == "" {
+				t.Errorf("Reply() = %v, %v, %v", c, ts, err)
+			}
+			if !reflect.DeepEqual(gotChannels, tt.wantChannels) {
+				t.Errorf("Reply() posted to %v, want %v", gotChannels, tt.wantChannels)
+			}
+			if !reflect.DeepEqual(gotTexts, tt.wantTexts) {
+				t.Errorf("Reply() posted %q, want %q", gotTexts, tt.wantTexts)
+			}
+		})
+	}
+}
+
+func Test_parseDryRunFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		wantText   string
+		wantDryRun bool
+	}{
+		{name: "should remove a trailing flag", text: "deploy api --dry-run", wantText: "deploy api", wantDryRun: true},
+		{name: "should ignore trailing whitespace", text: "deploy api --dry-run  ", wantText: "deploy api", wantDryRun: true},
+		{name: "should leave text without the flag", text: "deploy api", wantText: "deploy api"},
+		{name: "should only match the whole flag", text: "deploy api---dry-run", wantText: "deploy api---dry-run"},
+		{name: "should only match a trailing flag", text: "deploy --dry-run api", wantText: "deploy --dry-run api"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, dryRun := parseDryRunFlag(tt.text)
+			if text != tt.wantText || dryRun != tt.wantDryRun {
+				t.Errorf("parseDryRunFlag() = %q, %v, want %q, %v", text, dryRun, tt.wantText, tt.wantDryRun)
+			}
+		})
+	}
+}
+
+func TestMessageContext_IsDryRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		handler     bool
+		exchange    bool
+		wantDryRun  bool
+		wantReplies []string
+	}{
+		{
+			name:        "should reply without a banner",
+			text:        "deploy api",
+			wantReplies: []string{"deploying api"},
+		},
+		{
+			name:        "should set the flag and add the banner to replies",
+			text:        "deploy api --dry-run",
+			wantDryRun:  true,
+			wantReplies: []string{dryRunBanner + " deploying api"},
+		},
+		{
+			name:        "should not run handlers that can't tell it is a dry run",
+			text:        "deploy api --dry-run",
+			handler:     true,
+			wantReplies: []string{dryRunUnsupportedMessage},
+		},
+		{
+			name:        "should not start exchanges",
+			text:        "rollback --dry-run",
+			exchange:    true,
+			wantReplies: []string{dryRunUnsupportedMessage},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var replies []string
+			dryRun, ran := false, false
+			l := Listener{Regex: regexp.MustCompile(`^deploy (\S+)$`)}
+			if tt.handler {
+				l.Handler = func(bot *Bot, ev *slack.MessageEvent) { ran = true }
+			} else {
+				l.ContextHandler = func(ctx *MessageContext) {
+					dryRun = ctx.IsDryRun()
+					_, _, _ = ctx.Reply("deploying " + l.Regex.FindStringSubmatch(ctx.Event.Text)[1])
+				}
+			}
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						replies = append(replies, msgValues(opts...).Get("text"))
+						return s, "ts", nil
+					},
+				},
+				DirectListeners: []Listener{l},
+				Exchanges: []Exchange{{
+					Regex: regexp.MustCompile(`^rollback$`),
+					Steps: map[int]*Step{1: {Handler: func(ex *Exchange) error {
+						ran = true
+						return nil
+					}}},
+				}},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			bot.init()
+			bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: "U1", Text: tt.text, Timestamp: "1.1"}})
+			if dryRun != tt.wantDryRun {
+				t.Errorf("IsDryRun() = %v, want %v", dryRun, tt.wantDryRun)
+			}
+			if ran {
+				t.Error("handler ran, want it not to")
+			}
+			if !reflect.DeepEqual(replies, tt.wantReplies) {
+				t.Errorf("replies = %q, want %q", replies, tt.wantReplies)
+			}
+		})
+	}
+}
+
+func Test_withDryRunBanner(t *testing.T) {
+	section := slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "deploying", false, false), nil, nil)
+	options, err := withDryRunBanner([]slack.MsgOption{slack.MsgOptionBlocks(section)})
+	if err != nil {
+		t.Fatalf("withDryRunBanner() error = %v", err)
+	}
+	var blocks slack.Blocks
+	if err := json.Unmarshal([]byte(msgValues(options...).Get("blocks")), &blocks); err != nil {
+		t.Fatalf("unable to decode blocks - %v", err)
+	}
+	if len(blocks.BlockSet) != 2 || blocks.BlockSet[0].BlockType() != slack.MBTContext {
+		t.Errorf("withDryRunBanner() blocks = %v, want the banner first", blocks.BlockSet)
+	}
+	if text := msgValues(options...).Get("text"); text != dryRunBanner {
+		t.Errorf("withDryRunBanner() text = %q, want %q", text, dryRunBanner)
+	}
+}
+
+func TestBot_processMessage_indirectDryRunFlag(t *testing.T) {
+	var text string
+	dryRun := false
+	bot := &Bot{
+		API: &mockAPI{},
+		IndirectListeners: []Listener{{
+			Regex: regexp.MustCompile(`deploy`),
+			ContextHandler: func(ctx *MessageContext) {
+				text, dryRun = ctx.Event.Text, ctx.IsDryRun()
+			},
+		}},
+		userDetails: &slack.UserDetails{ID: "bot"},
+	}
+	bot.init()
+	bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "C1", User: "U1", Text: "deploy api --dry-run", Timestamp: "1.1"}})
+	if text != "deploy api --dry-run" || dryRun {
+		t.Errorf("indirect listener got %q with IsDryRun() = %v, want the text unchanged and not a dry run", text, dryRun)
+	}
+}
+
+func TestBot_processMessage_exchangeDryRunFlag(t *testing.T) {
+	answers := make(chan string, 1)
+	bot := &Bot{
+		API: &mockAPI{
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				return s, "ts", nil
+			},
+		},
+		Exchanges: []Exchange{{
+			Regex: regexp.MustCompile(`^rollback$`),
+			Steps: map[int]*Step{
+				1: {Message: "Why?"},
+				2: {MsgHandler: func(ex *Exchange, ev *slack.MessageEvent) (bool, error) {
+					answers <- ev.Text
+					return false, nil
+				}},
+			},
+		}},
+		userDetails: &slack.UserDetails{ID: "bot"},
+	}
+	bot.init()
+	bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: "U1", Text: "rollback", Timestamp: "1.1"}})
+	bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: "U1", Text: "testing --dry-run", Timestamp: "1.2", ThreadTimestamp: "1.1"}})
+	select {
+	case answer := <-answers:
+		if answer != "testing --dry-run" {
+			t.Errorf("exchange answer = %q, want the text unchanged", answer)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the exchange wasn't answered")
+	}
+}
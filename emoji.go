@@ -0,0 +1,114 @@
+package slackbot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	emojiCacheTTL      = time.Hour
+	emojiCacheKey      = "emoji"
+	emojiAliasPrefix   = "alias:"
+	emojiChangedType   = "emoji_changed"
+	emojiAddedSubtype  = "add"
+	emojiRemoveSubtype = "remove"
+)
+
+// ListCustomEmoji returns the team's custom emoji, a map of names to image urls, or to "alias:<name>" for
+// aliases of other emoji. The emoji are cached for an hour, and the cache is updated when emoji are added or
+// removed.
+func (bot *Bot) ListCustomEmoji() (map[string]string, error) {
+	c := bot.emojiCache()
+	if emoji, ok := c.get(emojiCacheKey); ok {
+		return emoji.(map[string]string), nil
+	}
+	var emoji map[string]string
+	err := bot.withRetry(func() (err error) {
+		emoji, err = bot.api().GetEmojiContext(bot.context())
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list custom emoji")
+	}
+	c.set(emojiCacheKey, emoji)
+	return emoji, nil
+}
+
+// RandomEmoji returns a random custom emoji whose name contains the category, such as "party" or "parrot",
+// formatted to be sent in a message like ":partyparrot:". An empty category chooses from all of the custom
+// emoji. It returns an error if none of the emoji match.
+func (bot *Bot) RandomEmoji(category string) (string, error) {
+	emoji, err := bot.ListCustomEmoji()
+	if err != nil {
+		return "", err
+	}
+	category = strings.ToLower(category)
+	var names []string
+	for name := range emoji {
+		if strings.Contains(name, category) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "", errors.Errorf("there are no custom emoji matching %q", category)
+	}
+	return fmt.Sprintf(":%s:", names[randomIndex(len(names))]), nil
+}
+
+// handleEmojiChanged updates the cached emoji and calls OnEmojiAdded for new emoji.
+func (bot *Bot) handleEmojiChanged(ev *slack.EmojiChangedEvent) {
+	c := bot.emojiCache()
+	switch ev.SubType {
+	case emojiAddedSubtype:
+		if emoji, ok := c.get(emojiCacheKey); ok {
+			updated := copyEmoji(emoji.(map[string]string))
+			updated[ev.Name] = ev.Value
+			c.set(emojiCacheKey, updated)
+		}
+		if bot.OnEmojiAdded != nil && !strings.HasPrefix(ev.Value, emojiAliasPrefix) {
+			go func() {
+				defer bot.recoverPanic(ErrorInfo{Source: ErrorSourceEvent}, nil)
+				bot.OnEmojiAdded(bot, ev.Name, ev.Value)
+			}()
+		}
+	case emojiRemoveSubtype:
+		if emoji, ok := c.get(emojiCacheKey); ok {
+			updated := copyEmoji(emoji.(map[string]string))
+			for _, name := range ev.Names {
+				delete(updated, name)
+			}
+			c.set(emojiCacheKey, updated)
+		}
+	default:
+		c.delete(emojiCacheKey)
+	}
+}
+
+func (bot *Bot) emojiCache() *cache {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.emoji == nil {
+		bot.emoji = newCache(emojiCacheTTL, bot.clock())
+	}
+	return bot.emoji
+}
+
+// copyEmoji copies the cached emoji so callers of ListCustomEmoji aren't affected by updates.
+func copyEmoji(emoji map[string]string) map[string]string {
+	c := make(map[string]string, len(emoji)+1)
+	for k, v := range emoji {
+		c[k] = v
+	}
+	return c
+}
+
+// randomIndex returns a random index from 0 up to n.
+func randomIndex(n int) int {
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+	return jitterRand.Intn(n)
+}
@@ -0,0 +1,159 @@
+package slackbot
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_ListCustomEmoji(t *testing.T) {
+	tests := []struct {
+		name      string
+		emoji     map[string]string
+		err       error
+		calls     int
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "should cache the emoji",
+			emoji:     map[string]string{"partyparrot": "https://emoji/partyparrot.gif"},
+			calls:     3,
+			wantCalls: 1,
+		},
+		{
+			name:      "should not cache errors",
+			err:       errors.New("boom"),
+			calls:     2,
+			wantCalls: 2,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			bot := &Bot{
+				RetryPolicy: &RetryPolicy{Attempts: 1},
+				API: &mockAPI{getEmoji: func() (map[string]string, error) {
+					calls++
+					return tt.emoji, tt.err
+				}},
+			}
+			for i := 0; i < tt.calls; i++ {
+				got, err := bot.ListCustomEmoji()
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("ListCustomEmoji() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if !tt.wantErr && !reflect.DeepEqual(got, tt.emoji) {
+					t.Errorf("ListCustomEmoji() = %v, want %v", got, tt.emoji)
+				}
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("ListCustomEmoji() called GetEmoji %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestBot_RandomEmoji(t *testing.T) {
+	emoji := map[string]string{
+		"partyparrot": "https://emoji/partyparrot.gif",
+		"party-blob":  "https://emoji/party-blob.gif",
+		"shipit":      "https://emoji/shipit.png",
+	}
+	tests := []struct {
+		name     string
+		category string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "should choose an emoji in the category",
+			category: "Party",
+			want:     []string{":partyparrot:", ":party-blob:"},
+		},
+		{
+			name: "should choose from all of the emoji without a category",
+			want: []string{":partyparrot:", ":party-blob:", ":shipit:"},
+		},
+		{
+			name:     "should return an error when no emoji match",
+			category: "cat",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{API: &mockAPI{getEmoji: func() (map[string]string, error) {
+				return emoji, nil
+			}}}
+			for i := 0; i < 10; i++ {
+				got, err := bot.RandomEmoji(tt.category)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("RandomEmoji() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if !tt.wantErr && !containsString(tt.want, got) {
+					t.Errorf("RandomEmoji() = %v, want one of %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBot_handleEmojiChanged(t *testing.T) {
+	tests := []struct {
+		name      string
+		event     *slack.EmojiChangedEvent
+		wantEmoji map[string]string
+		wantAdded string
+	}{
+		{
+			name:      "should add the emoji and call OnEmojiAdded",
+			event:     &slack.EmojiChangedEvent{SubType: "add", Name: "shipit", Value: "https://emoji/shipit.png"},
+			wantEmoji: map[string]string{"partyparrot": "https://emoji/partyparrot.gif", "shipit": "https://emoji/shipit.png"},
+			wantAdded: "shipit https://emoji/shipit.png",
+		},
+		{
+			name:      "should not call OnEmojiAdded for aliases",
+			event:     &slack.EmojiChangedEvent{SubType: "add", Name: "parrot", Value: "alias:partyparrot"},
+			wantEmoji: map[string]string{"partyparrot": "https://emoji/partyparrot.gif", "parrot": "alias:partyparrot"},
+		},
+		{
+			name:      "should remove the emoji",
+			event:     &slack.EmojiChangedEvent{SubType: "remove", Names: []string{"partyparrot"}},
+			wantEmoji: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added := make(chan string, 1)
+			bot := &Bot{
+				API: &mockAPI{getEmoji: func() (map[string]string, error) {
+					return map[string]string{"partyparrot": "https://emoji/partyparrot.gif"}, nil
+				}},
+				OnEmojiAdded: func(bot *Bot, name string, url string) {
+					added <- name + " " + url
+				},
+			}
+			if _, err := bot.ListCustomEmoji(); err != nil {
+				t.Fatalf("ListCustomEmoji() error = %v", err)
+			}
+			bot.handleEmojiChanged(tt.event)
+			got, _ := bot.ListCustomEmoji()
+			if !reflect.DeepEqual(got, tt.wantEmoji) {
+				t.Errorf("ListCustomEmoji() = %v, want %v", got, tt.wantEmoji)
+			}
+			var gotAdded string
+			select {
+			case gotAdded = <-added:
+			case <-time.After(50 * time.Millisecond):
+			}
+			if gotAdded != tt.wantAdded {
+				t.Errorf("OnEmojiAdded() called with %q, want %q", gotAdded, tt.wantAdded)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package slackbot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	escalationStoreKey         = "escalations"
+	escalationAckActionID      = "slackbot_escalation_ack"
+	defaultEscalationTimeout   = 15 * time.Minute
+	escalationAckButton        = "Acknowledge"
+	escalationAckHint          = "React or reply in the thread to acknowledge."
+	escalationMessage          = "*Escalated*, this wasn't acknowledged within %s:\n%s"
+	escalationAcknowledged     = "%s\n:white_check_mark: Acknowledged by <@%s>"
+	escalationUnacknowledged   = "escalation %s was not acknowledged by anyone - %s"
+	escalationNotifyErrMessage = "unable to escalate %s to %s - %s"
+)
+
+type (
+	// EscalationPolicy decides who is notified when a message sent with NotifyWithEscalation isn't
+	// acknowledged. Each of the Escalations, a channel or user, is notified in order when the Timeout passes
+	// without anyone acknowledging the message, such as the on call engineer, then the team's channel, then a
+	// fallback channel.
+	//
+	// Example:
+	//
+	//	bot.NotifyWithEscalation(oncall, "The api is returning 500s", slackbot.EscalationPolicy{
+	//		Timeout:     10 * time.Minute,
+	//		Escalations: []string{"platform-team", "engineering-managers"},
+	//	})
+	EscalationPolicy struct {
+		// Timeout is how long each notification waits to be acknowledged, the default is fifteen minutes.
+		Timeout     time.Duration
+		Escalations []string
+	}
+
+	// Escalation is a message sent with NotifyWithEscalation that hasn't been acknowledged. It is saved in the
+	// bot's Store so it keeps escalating when the bot restarts.
+	Escalation struct {
+		ID             string
+		Text           string
+		Policy         EscalationPolicy
+		Notified       []EscalationNotice
+		NextEscalation time.Time
+	}
+
+	// EscalationNotice is one of the messages an Escalation was sent as.
+	EscalationNotice struct {
+		Target    string
+		Channel   string
+		Timestamp string
+		Sent      time.Time
+	}
+)
+
+// NotifyWithEscalation sends the message to the target, a channel or user, and escalates it according to the
+// policy until someone acknowledges it by reacting to one of its messages, replying in one of their threads,
+// or clicking its Acknowledge button if the bot is Interactive. Messages are sent with PriorityAlert so they
+// aren't held during QuietHours. When the last of the policy's Escalations isn't acknowledged the escalation
+// is logged as an error.
+func (bot *Bot) NotifyWithEscalation(target string, msg string, policy EscalationPolicy) (*Escalation, error) {
+	if policy.Timeout <= 0 {
+		policy.Timeout = defaultEscalationTimeout
+	}
+	esc := &Escalation{ID: newID(), Text: msg, Policy: policy}
+	notice, err := bot.sendEscalationNotice(esc, target, msg)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to send the notification")
+	}
+	esc.Notified = append(esc.Notified, notice)
+	esc.NextEscalation = bot.clock().Now().Add(policy.Timeout)
+
+	bot.escalationMu.Lock()
+	defer bot.escalationMu.Unlock()
+	escalations := bot.loadEscalations()
+	escalations[esc.ID] = esc
+	if err := bot.store().Put(escalationStoreKey, escalations); err != nil {
+		return nil, errors.Wrap(err, "unable to save the escalation")
+	}
+	bot.trackEscalation(esc)
+	return esc, nil
+}
+
+// Escalations returns the escalations that haven't been acknowledged.
+func (bot *Bot) Escalations() []Escalation {
+	bot.escalationMu.Lock()
+	defer bot.escalationMu.Unlock()
+	var pending []Escalation
+	for _, esc := range bot.loadEscalations() {
+		pending = append(pending, *esc)
+	}
+	return pending
+}
+
+// AcknowledgeEscalation stops the escalation with the ID from escalating, and marks its messages as
+// acknowledged by the user.
+func (bot *Bot) AcknowledgeEscalation(ID string, user string) error {
+	esc, err := bot.removeEscalation(ID)
+	if err != nil {
+		return err
+	}
+	if esc == nil {
+		return errors.Errorf("there is no escalation %s waiting to be acknowledged", ID)
+	}
+	text := fmt.Sprintf(escalationAcknowledged, esc.Text, user)
+	for _, n := range esc.Notified {
+		if n.Timestamp == "" {
+			continue
+		}
+		err := bot.withRetry(func() error {
+			_, _, _, err := bot.api().UpdateMessageContext(bot.context(), n.Channel, n.Timestamp,
+				slack.MsgOptionText(text, false),
+				slack.MsgOptionBlocks(slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil)),
+			)
+			return err
+		})
+		if err != nil {
+			bot.LogError(fmt.Sprintf("unable to mark escalation %s as acknowledged in %s - %s", ID, n.Channel, err))
+		}
+	}
+	return nil
+}
+
+// escalate notifies the next of the escalation's targets, or gives up if they have all been notified. The
+// notice is sent without holding escalationMu, since sending can wait on the SendQueue, and isn't saved if the
+// escalation was acknowledged in the meantime.
+func (bot *Bot) escalate(ID string) {
+	bot.escalationMu.Lock()
+	escalations := bot.loadEscalations()
+	esc, ok := escalations[ID]
+	if !ok {
+		bot.escalationMu.Unlock()
+		return
+	}
+	level := len(esc.Notified) - 1
+	if level >= len(esc.Policy.Escalations) {
+		delete(escalations, ID)
+		err := bot.store().Put(escalationStoreKey, escalations)
+		bot.untrackEscalation(esc)
+		bot.escalationMu.Unlock()
+		if err != nil {
+			bot.LogError(fmt.Sprintf("unable to remove escalation %s - %s", ID, err))
+		}
+		bot.LogError(fmt.Sprintf(escalationUnacknowledged, ID, esc.Text))
+		return
+	}
+	bot.escalationMu.Unlock()
+
+	target := esc.Policy.Escalations[level]
+	notice, err := bot.sendEscalationNotice(esc, target, fmt.Sprintf(escalationMessage, esc.Policy.Timeout, esc.Text))
+	if err != nil {
+		bot.LogError(fmt.Sprintf(escalationNotifyErrMessage, ID, target, err))
+		notice = EscalationNotice{Target: target, Sent: bot.clock().Now()}
+	}
+
+	bot.escalationMu.Lock()
+	escalations = bot.loadEscalations()
+	current, ok := escalations[ID]
+	if !ok || len(current.Notified) != len(esc.Notified) {
+		bot.escalationMu.Unlock()
+		return
+	}
+	current.Notified = append(current.Notified, notice)
+	current.NextEscalation = bot.clock().Now().Add(current.Policy.Timeout)
+	err = bot.store().Put(escalationStoreKey, escalations)
+	bot.trackEscalation(current)
+	bot.escalationMu.Unlock()
+	if err != nil {
+		bot.LogError(fmt.Sprintf("unable to save escalation %s - %s", ID, err))
+	}
+}
+
+// sendEscalationNotice sends the escalation's text to the target, with an Acknowledge button if the bot is
+// Interactive.
+func (bot *Bot) sendEscalationNotice(esc *Escalation, target string, text string) (EscalationNotice, error) {
+	options := []slack.MsgOption{slack.MsgOptionText(text+"\n"+escalationAckHint, false)}
+	if bot.Interactive {
+		ack := slack.NewButtonBlockElement(escalationAckActionID, esc.ID, slack.NewTextBlockObject(slack.PlainTextType, escalationAckButton, false, false))
+		ack.Style = slack.StylePrimary
+		options = append(options, slack.MsgOptionBlocks(
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil),
+			slack.NewActionBlock("", ack),
+		))
+	}
+	channel, timestamp, err := bot.ReplyWithPriority(PriorityAlert, target, options...)
+	if err != nil {
+		return EscalationNotice{}, err
+	}
+	return EscalationNotice{Target: target, Channel: channel, Timestamp: timestamp, Sent: bot.clock().Now()}, nil
+}
+
+// removeEscalation removes the escalation from the Store and stops its timer, it returns nil if there is no
+// escalation with the ID.
+func (bot *Bot) removeEscalation(ID string) (*Escalation, error) {
+	bot.escalationMu.Lock()
+	defer bot.escalationMu.Unlock()
+	escalations := bot.loadEscalations()
+	esc, ok := escalations[ID]
+	if !ok {
+		return nil, nil
+	}
+	delete(escalations, ID)
+	if err := bot.store().Put(escalationStoreKey, escalations); err != nil {
+		return nil, errors.Wrap(err, "unable to remove the escalation")
+	}
+	bot.untrackEscalation(esc)
+	return esc, nil
+}
+
+// resumeEscalations tracks the escalations saved in the Store when the bot starts, those that were due while
+// the bot was stopped escalate straight away.
+func (bot *Bot) resumeEscalations() {
+	bot.escalationMu.Lock()
+	defer bot.escalationMu.Unlock()
+	for _, esc := range bot.loadEscalations() {
+		bot.trackEscalation(esc)
+	}
+}
+
+// trackEscalation indexes the escalation's messages so reactions and replies to them acknowledge it, and
+// schedules its next escalation. escalationMu must be held.
+func (bot *Bot) trackEscalation(esc *Escalation) {
+	if bot.escalateTimers == nil {
+		bot.escalateTimers = make(map[string]Timer)
+		bot.escalatedMsgs = make(map[string]string)
+	}
+	for _, n := range esc.Notified {
+		if n.Timestamp != "" {
+			bot.escalatedMsgs[escalationMessageKey(n.Channel, n.Timestamp)] = esc.ID
+		}
+	}
+	if timer, ok := bot.escalateTimers[esc.ID]; ok {
+		timer.Stop()
+	}
+	ID := esc.ID
+	bot.escalateTimers[ID] = bot.clock().AfterFunc(esc.NextEscalation.Sub(bot.clock().Now()), func() {
+		bot.escalate(ID)
+	})
+}
+
+// untrackEscalation stops the escalation's timer and removes its messages from the index. escalationMu must
+// be held.
+func (bot *Bot) untrackEscalation(esc *Escalation) {
+	if timer, ok := bot.escalateTimers[esc.ID]; ok {
+		timer.Stop()
+		delete(bot.escalateTimers, esc.ID)
+	}
+	for _, n := range esc.Notified {
+		delete(bot.escalatedMsgs, escalationMessageKey(n.Channel, n.Timestamp))
+	}
+}
+
+// loadEscalations returns the escalations in the Store, escalationMu must be held.
+func (bot *Bot) loadEscalations() map[string]*Escalation {
+	escalations := make(map[string]*Escalation)
+	_ = bot.store().Get(escalationStoreKey, &escalations)
+	return escalations
+}
+
+// escalationFor returns the ID of the escalation the message was sent for, if there is one.
+func (bot *Bot) escalationFor(channel string, timestamp string) string {
+	bot.escalationMu.Lock()
+	defer bot.escalationMu.Unlock()
+	return bot.escalatedMsgs[escalationMessageKey(channel, timestamp)]
+}
+
+// acknowledgeMessage acknowledges the escalation the message was sent for when the user reacts to it or
+// replies in its thread.
+func (bot *Bot) acknowledgeMessage(user string, channel string, timestamp string) {
+	if user == "" || (bot.userDetails != nil && user == bot.userDetails.ID) {
+		return
+	}
+	ID := bot.escalationFor(channel, timestamp)
+	if ID == "" {
+		return
+	}
+	if err := bot.AcknowledgeEscalation(ID, user); err != nil {
+		bot.LogDebug(err.Error())
+	}
+}
+
+// handleEscalationAck acknowledges an escalation when its Acknowledge button is clicked.
+func (bot *Bot) handleEscalationAck(callback *slack.InteractionCallback, action *slack.BlockAction) {
+	go func() {
+		if err := bot.AcknowledgeEscalation(action.Value, callback.User.ID); err != nil {
+			bot.LogDebug(err.Error())
+		}
+	}()
+}
+
+func escalationMessageKey(channel string, timestamp string) string {
+	return channel + " " + timestamp
+}
@@ -0,0 +1,248 @@
+package slackbot
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// escalationAPI is a mockAPI that records the channels messages are sent to and the messages that are updated.
+func escalationAPI() (*mockAPI, func() []string, func() []string) {
+	var mu sync.Mutex
+	var sent, updated []string
+	api := &mockAPI{
+		postMessage: func(channel string, opts ...slack.MsgOption) (string, string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			sent = append(sent, channel)
+			return channel, fmt.Sprintf("%d.1", len(sent)), nil
+		},
+		updateMessage: func(channel string, ts string, opts ...slack.MsgOption) (string, string, string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			updated = append(updated, channel+" "+ts)
+			return channel, ts, "", nil
+		},
+	}
+	return api,
+		func() []string { mu.Lock(); defer mu.Unlock(); return append([]string(nil), sent...) },
+		func() []string { mu.Lock(); defer mu.Unlock(); return append([]string(nil), updated...) }
+}
+
+func TestBot_NotifyWithEscalation(t *testing.T) {
+	tests := []struct {
+		name        string
+		escalations int
+		wantSent    []string
+		wantPending bool
+	}{
+		{
+			name:        "should notify the target",
+			wantSent:    []string{"U1"},
+			wantPending: true,
+		},
+		{
+			name:        "should notify the next target when the timeout passes",
+			escalations: 1,
+			wantSent:    []string{"U1", "team"},
+			wantPending: true,
+		},
+		{
+			name:        "should notify each target in order",
+			escalations: 2,
+			wantSent:    []string{"U1", "team", "fallback"},
+			wantPending: true,
+		},
+		{
+			name:        "should give up once the last target isn't acknowledged",
+			escalations: 3,
+			wantSent:    []string{"U1", "team", "fallback"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, sent, _ := escalationAPI()
+			bot := &Bot{API: api}
+			esc, err := bot.NotifyWithEscalation("U1", "the api is down", EscalationPolicy{
+				Timeout:     time.Hour,
+				Escalations: []string{"team", "fallback"},
+			})
+			if err != nil {
+				t.Fatalf("NotifyWithEscalation() error = %v", err)
+			}
+			for i := 0; i < tt.escalations; i++ {
+				bot.escalate(esc.ID)
+			}
+			if got := sent(); !reflect.DeepEqual(got, tt.wantSent) {
+				t.Errorf("sent to = %v, want %v", got, tt.wantSent)
+			}
+			if pending := len(bot.Escalations()) == 1; pending != tt.wantPending {
+				t.Errorf("pending = %v, want %v", pending, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestBot_AcknowledgeEscalation(t *testing.T) {
+	tests := []struct {
+		name        string
+		acknowledge func(bot *Bot, esc *Escalation)
+		wantAcked   bool
+	}{
+		{
+			name: "should acknowledge with the ID",
+			acknowledge: func(bot *Bot, esc *Escalation) {
+				_ = bot.AcknowledgeEscalation(esc.ID, "U2")
+			},
+			wantAcked: true,
+		},
+		{
+			name: "should acknowledge when someone reacts to one of its messages",
+			acknowledge: func(bot *Bot, esc *Escalation) {
+				bot.acknowledgeMessage("U2", "team", esc.Notified[0].Timestamp)
+			},
+			wantAcked: true,
+		},
+		{
+			name: "should acknowledge when someone replies in the thread of one of its messages",
+			acknowledge: func(bot *Bot, esc *Escalation) {
+				bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "team", User: "U2", Text: "on it", Timestamp: "9.9", ThreadTimestamp: esc.Notified[0].Timestamp}})
+			},
+			wantAcked: true,
+		},
+		{
+			name: "should acknowledge when the button is clicked",
+			acknowledge: func(bot *Bot, esc *Escalation) {
+				bot.HandleInteraction(&slack.InteractionCallback{
+					Type: slack.InteractionTypeBlockActions,
+					User: slack.User{ID: "U2"},
+					ActionCallback: slack.ActionCallbacks{BlockActions: []*slack.BlockAction{{
+						ActionID: escalationAckActionID,
+						Value:    esc.ID,
+					}}},
+				})
+			},
+			wantAcked: true,
+		},
+		{
+			name: "should not acknowledge when the bot reacts",
+			acknowledge: func(bot *Bot, esc *Escalation) {
+				bot.acknowledgeMessage("bot", "team", esc.Notified[0].Timestamp)
+			},
+		},
+		{
+			name: "should not acknowledge for reactions to other messages",
+			acknowledge: func(bot *Bot, esc *Escalation) {
+				bot.acknowledgeMessage("U2", "team", "8.8")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, sent, updated := escalationAPI()
+			bot := &Bot{API: api, Interactive: true, userDetails: &slack.UserDetails{ID: "bot"}}
+			bot.init()
+			esc, err := bot.NotifyWithEscalation("team", "the api is down", EscalationPolicy{
+				Timeout:     time.Hour,
+				Escalations: []string{"fallback"},
+			})
+			if err != nil {
+				t.Fatalf("NotifyWithEscalation() error = %v", err)
+			}
+			tt.acknowledge(bot, esc)
+			if tt.wantAcked {
+				waitFor(t, func() bool { return len(updated()) == 1 })
+			}
+			bot.escalate(esc.ID)
+
+			wantSent := []string{"team", "fallback"}
+			if tt.wantAcked {
+				wantSent = []string{"team"}
+			}
+			if got := sent(); !reflect.DeepEqual(got, wantSent) {
+				t.Errorf("sent to = %v, want %v", got, wantSent)
+			}
+			if pending := len(bot.Escalations()) == 1; pending == tt.wantAcked {
+				t.Errorf("pending = %v, want %v", pending, !tt.wantAcked)
+			}
+		})
+	}
+}
+
+func TestBot_AcknowledgeEscalation_unknown(t *testing.T) {
+	bot := &Bot{API: &mockAPI{}}
+	if err := bot.AcknowledgeEscalation("missing", "U1"); err == nil {
+		t.Errorf("AcknowledgeEscalation() error = nil, want an error")
+	}
+}
+
+func TestBot_resumeEscalations(t *testing.T) {
+	api, sent, _ := escalationAPI()
+	store := NewMemoryStore(nil)
+	esc := &Escalation{
+		ID:             "E1",
+		Text:           "the api is down",
+		Policy:         EscalationPolicy{Timeout: time.Hour, Escalations: []string{"fallback"}},
+		Notified:       []EscalationNotice{{Target: "team", Channel: "team", Timestamp: "1.1"}},
+		NextEscalation: time.Now().Add(-time.Minute),
+	}
+	if err := store.Put(escalationStoreKey, map[string]*Escalation{esc.ID: esc}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	bot := &Bot{API: api, Store: store}
+	bot.resumeEscalations()
+	waitFor(t, func() bool { return len(sent()) == 1 })
+	if got := sent(); got[0] != "fallback" {
+		t.Errorf("sent to = %v, want the escalation that was due to be sent", got)
+	}
+	if ID := bot.escalationFor("team", "1.1"); ID != "E1" {
+		t.Errorf("escalationFor() = %q, want E1", ID)
+	}
+}
+
+func TestBot_escalate_acknowledgedWhileSending(t *testing.T) {
+	store := NewMemoryStore(nil)
+	esc := &Escalation{
+		ID:             "E1",
+		Text:           "the api is down",
+		Policy:         EscalationPolicy{Timeout: time.Hour, Escalations: []string{"fallback"}},
+		Notified:       []EscalationNotice{{Target: "team", Channel: "team", Timestamp: "1.1"}},
+		NextEscalation: time.Now().Add(time.Hour),
+	}
+	if err := store.Put(escalationStoreKey, map[string]*Escalation{esc.ID: esc}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	bot := &Bot{Store: store}
+	bot.API = &mockAPI{
+		postMessage: func(channel string, opts ...slack.MsgOption) (string, string, error) {
+			// a reply in the escalation's thread is processed while the notice is being sent
+			bot.acknowledgeMessage("U1", "team", "1.1")
+			return channel, "2.1", nil
+		},
+		updateMessage: func(channel string, ts string, opts ...slack.MsgOption) (string, string, string, error) {
+			return channel, ts, "", nil
+		},
+	}
+	bot.resumeEscalations()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		bot.escalate("E1")
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("escalate() did not return while the escalation was acknowledged")
+	}
+	if pending := bot.Escalations(); len(pending) != 0 {
+		t.Errorf("Escalations() = %v, want the acknowledged escalation removed", pending)
+	}
+	if ID := bot.escalationFor("fallback", "2.1"); ID != "" {
+		t.Errorf("escalationFor() = %q, want the notice of the acknowledged escalation untracked", ID)
+	}
+}
@@ -0,0 +1,259 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+const (
+	recordedAppMentionType = "app_mention"
+	mentionDeliveryTTL     = 10 * time.Minute
+)
+
+// EventsHandler returns an http.Handler that receives events from slack's Events API, so the bot can be run
+// without an RTM connection. Slack should be configured to send events to the url the handler is served on.
+// Requests are verified with the bot's SigningSecret, and rejected if it isn't set. Events are
+// acknowledged straight away and processed in the background, see HandleEventsAPIEvent. The bot's scheduled
+// tasks are started with the handler, and stopped by Stop.
+func (bot *Bot) EventsHandler() http.Handler {
+	bot.startEvents()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := bot.readSlackRequest(r)
+		if err != nil {
+			bot.LogError(fmt.Sprintf("invalid events api request - %s", err))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		ev, err := slackevents.ParseEvent(json.RawMessage(body), slackevents.OptionNoVerifyToken())
+		if err != nil {
+			bot.LogError(fmt.Sprintf("unable to parse events api request - %s", err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if ev.Type == slackevents.URLVerification {
+			var challenge slackevents.ChallengeResponse
+			if err := json.Unmarshal(body, &challenge); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte(challenge.Challenge))
+			return
+		}
+		bot.noteEnvelope(body)
+		w.WriteHeader(http.StatusOK)
+		go bot.HandleEventsAPIEvent(ev)
+	})
+}
+
+// HandleEventsAPIEvent processes an event from the Events API synchronously. It is used by EventsHandler and
+// can be called with the events received from a Socket Mode connection. Message events are processed the same
+// way as messages from the RTM connection. app_mention events are sent through the direct listeners and
+// exchanges even if the bot is not mentioned at the start of the message, and if the bot also receives the
+// message event for the same message it is only processed once. Events that are delivered again within the
+// bot's EventReplayWindow are ignored. The bot's scheduled tasks are started with the first event if they
+// haven't been already.
+func (bot *Bot) HandleEventsAPIEvent(ev slackevents.EventsAPIEvent) {
+	bot.startEvents()
+	bot.ensureUserDetails()
+	cb, ok := ev.Data.(*slackevents.EventsAPICallbackEvent)
+	if !ok || cb.InnerEvent == nil {
+		return
+	}
+	if !bot.firstEvent(cb.EventID) {
+		bot.LogDebug(fmt.Sprintf("ignoring event %s, it was already received", cb.EventID))
+		return
+	}
+	switch ev.InnerEvent.Type {
+	case slackevents.Message:
+		msg := &slack.MessageEvent{}
+		if err := json.Unmarshal(*cb.InnerEvent, msg); err != nil {
+			bot.LogError(fmt.Sprintf("unable to read message event - %s", err))
+			return
+		}
+		if msg.Team == "" {
+			msg.Team = cb.TeamID
+		}
+		bot.recordEvent(recordedMessageType, msg)
+		bot.noteEventLag(msg.Channel, msg.Timestamp)
+		if (bot.mentionsBot(msg.Text) || bot.isEnterpriseTeam(msg.Team)) && !bot.firstMessageDelivery(msg) {
+			return
+		}
+		bot.processMessage(msg)
+
+	case slackevents.AppMention:
+		msg := &slack.MessageEvent{}
+		if err := json.Unmarshal(*cb.InnerEvent, msg); err != nil {
+			bot.LogError(fmt.Sprintf("unable to read app mention event - %s", err))
+			return
+		}
+		if msg.Team == "" {
+			msg.Team = cb.TeamID
+		}
+		bot.recordEvent(recordedAppMentionType, msg)
+		bot.handleMention(msg)
+
+	case slackevents.AppHomeOpened:
+		if opened, ok := ev.InnerEvent.Data.(*slackevents.AppHomeOpenedEvent); ok {
+			bot.handleAppHomeOpened(opened)
+		}
+
+	case slackevents.MemberJoinedChannel:
+		if joined, ok := ev.InnerEvent.Data.(*slackevents.MemberJoinedChannelEvent); ok {
+			bot.handleMemberJoinedChannel(joined.User, joined.Channel)
+		}
+
+	case slackevents.LinkShared:
+		if shared, ok := ev.InnerEvent.Data.(*slackevents.LinkSharedEvent); ok {
+			bot.handleLinkShared(shared)
+		}
+
+	case slackevents.ReactionAdded:
+		if reaction, ok := ev.InnerEvent.Data.(*slackevents.ReactionAddedEvent); ok {
+			bot.acknowledgeMessage(reaction.User, reaction.Item.Channel, reaction.Item.Timestamp)
+		}
+
+	case emojiChangedType:
+		changed := &slack.EmojiChangedEvent{}
+		if err := json.Unmarshal(*cb.InnerEvent, changed); err != nil {
+			bot.LogError(fmt.Sprintf("unable to read emoji changed event - %s", err))
+			return
+		}
+		bot.handleEmojiChanged(changed)
+
+	case channelArchiveType, channelUnarchiveType, channelDeletedType, groupArchiveType, groupUnarchiveType:
+		bot.handleChannelAvailability(ev.InnerEvent.Type, *cb.InnerEvent)
+	}
+}
+
+// handleMention moves the bot's mention to the start of the message, so it is handled by the direct listeners
+// the same way as a mention received over RTM, and processes it unless it was already received as a message
+// that started with the mention. A message that mentions the bot later in its text isn't sent to the direct
+// listeners as a message event, so the app_mention is still processed, but the indirect listeners are only
+// run by whichever of the two events is received first.
+func (bot *Bot) handleMention(ev *slack.MessageEvent) {
+	mention := fmt.Sprintf("<@%s>", bot.userDetails.ID)
+	indirect := true
+	if !strings.HasPrefix(ev.Text, mention+" ") {
+		text := strings.Replace(strings.Replace(ev.Text, " "+mention+" ", " ", 1), mention, "", 1)
+		ev.Text = mention + " " + strings.TrimSpace(text)
+		indirect = bot.deliveryCache().add(indirectDeliveryKey(ev), true)
+	}
+	if !bot.deliveryCache().add(deliveryKey(ev), true) {
+		return
+	}
+	bot.process(ev, indirect)
+}
+
+// handleMemberJoinedChannel welcomes a user who joined a channel and starts the channel's onboardings.
+func (bot *Bot) handleMemberJoinedChannel(user string, channel string) {
+	if bot.userDetails != nil && user == bot.userDetails.ID {
+		return
+	}
+	bot.welcomeMember(user, channel)
+	bot.startChannelOnboardings(user, channel)
+}
+
+// mentionsBot returns true if the text contains a mention of the bot.
+func (bot *Bot) mentionsBot(text string) bool {
+	return bot.userDetails.ID != "" && strings.Contains(text, fmt.Sprintf("<@%s>", bot.userDetails.ID))
+}
+
+// firstMessageDelivery returns true the first time a message event is received for a message that mentions the
+// bot, or for a message in a channel shared between the workspaces of an Enterprise Grid organization, which
+// is delivered once for each workspace the bot is installed in. A message that starts with the mention is
+// processed by the direct listeners, so it is the same delivery as its app_mention. Other messages only run
+// the indirect listeners, and only share those with the app_mention.
+func (bot *Bot) firstMessageDelivery(ev *slack.MessageEvent) bool {
+	if strings.HasPrefix(ev.Text, fmt.Sprintf("<@%s> ", bot.userDetails.ID)) {
+		return bot.deliveryCache().add(deliveryKey(ev), true)
+	}
+	return bot.deliveryCache().add(indirectDeliveryKey(ev), true)
+}
+
+// deliveryKey identifies a message processed by the direct listeners, from either a message or an app_mention.
+func deliveryKey(ev *slack.MessageEvent) string {
+	return ev.Channel + " " + ev.Timestamp
+}
+
+// indirectDeliveryKey identifies a message processed by the indirect listeners.
+func indirectDeliveryKey(ev *slack.MessageEvent) string {
+	return "indirect " + deliveryKey(ev)
+}
+
+func (bot *Bot) deliveryCache() *cache {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.deliveries == nil {
+		bot.deliveries = newCache(mentionDeliveryTTL, bot.clock())
+	}
+	return bot.deliveries
+}
+
+// startEvents starts a bot that receives events from the Events API instead of an RTM connection. Its scheduled
+// tasks, recurring messages, rotations, onboarding, escalations and watchdog heartbeat are scheduled once, unless
+// the bot was started with Start.
+func (bot *Bot) startEvents() {
+	bot.once.Do(bot.init)
+	bot.eventsOnce.Do(func() {
+		bot.mu.Lock()
+		scheduled := bot.scheduler != nil
+		bot.mu.Unlock()
+		if scheduled {
+			return
+		}
+		if err := bot.scheduleTasks(); err != nil {
+			bot.LogError(fmt.Sprintf("unable to schedule the bot's tasks - %s", err))
+		}
+	})
+}
+
+// ensureUserDetails sets the bot's user details from the RTM connection if there is one, or from auth.test.
+func (bot *Bot) ensureUserDetails() {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.userDetails != nil {
+		return
+	}
+	bot.userDetails = &slack.UserDetails{}
+	if info := bot.api().GetInfo(); info != nil && info.User != nil {
+		bot.userDetails = info.User
+	} else if resp, err := bot.api().AuthTestContext(bot.context()); err == nil {
+		bot.userDetails = &slack.UserDetails{ID: resp.UserID, Name: resp.User}
+		bot.workspace = Workspace{EnterpriseID: resp.EnterpriseID, TeamID: resp.TeamID}
+	}
+}
+
+// readSlackRequest reads the body of a request from slack and verifies its signature with the bot's
+// SigningSecret. Requests are rejected when there is no SigningSecret, unless InsecureSkipVerify is set.
+func (bot *Bot) readSlackRequest(r *http.Request) ([]byte, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read request body")
+	}
+	if bot.SigningSecret == "" {
+		if bot.InsecureSkipVerify {
+			return body, nil
+		}
+		return nil, errors.New("the bot has no SigningSecret to verify the request with")
+	}
+	sv, err := slack.NewSecretsVerifier(r.Header, bot.SigningSecret)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to verify request")
+	}
+	if _, err := sv.Write(body); err != nil {
+		return nil, errors.Wrap(err, "unable to verify request")
+	}
+	if err := sv.Ensure(); err != nil {
+		return nil, errors.Wrap(err, "invalid request signature")
+	}
+	return body, nil
+}
@@ -0,0 +1,282 @@
+package slackbot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+func TestBot_EventsHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		secret     string
+		insecure   bool
+		body       string
+		sign       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "should respond to url verification",
+			insecure:   true,
+			body:       `{"type":"url_verification","challenge":"abc123"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   "abc123",
+		},
+		{
+			name:       "should accept a signed request",
+			secret:     "secret",
+			sign:       "secret",
+			body:       `{"type":"url_verification","challenge":"abc123"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   "abc123",
+		},
+		{
+			name:       "should reject a request with an invalid signature",
+			secret:     "secret",
+			sign:       "wrong",
+			body:       `{"type":"url_verification","challenge":"abc123"}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "should reject a request when the bot has no signing secret",
+			body:       `{"type":"url_verification","challenge":"abc123"}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "should reject an invalid event",
+			insecure:   true,
+			body:       `not json`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "should acknowledge callback events",
+			insecure:   true,
+			body:       eventJSON("message", `"channel":"C1","user":"U1","text":"hi","ts":"1.1"`),
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{
+				SigningSecret:      tt.secret,
+				InsecureSkipVerify: tt.insecure,
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						return s, "ts", nil
+					},
+				},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			req := httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader(tt.body))
+			if tt.sign != "" {
+				signRequest(req, tt.sign, tt.body)
+			}
+			rec := httptest.NewRecorder()
+			bot.EventsHandler().ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("EventsHandler() status = %v, want %v", rec.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("EventsHandler() body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestBot_HandleEventsAPIEvent(t *testing.T) {
+	tests := []struct {
+		name         string
+		workspace    Workspace
+		events       []string
+		wantDirect   []string
+		wantIndirect int
+	}{
+		{
+			name:         "should process message events",
+			events:       []string{eventJSON("message", `"channel":"C1","user":"U1","text":"<@bot> deploy api","ts":"1.1"`)},
+			wantDirect:   []string{"deploy api"},
+			wantIndirect: 1,
+		},
+		{
+			name:         "should send app mentions through the same listeners as messages",
+			events:       []string{eventJSON("app_mention", `"channel":"C1","user":"U1","text":"<@bot> deploy api","ts":"1.1"`)},
+			wantDirect:   []string{"deploy api"},
+			wantIndirect: 1,
+		},
+		{
+			name:         "should handle app mentions that don't start with the mention",
+			events:       []string{eventJSON("app_mention", `"channel":"C1","user":"U1","text":"deploy <@bot> api","ts":"1.1"`)},
+			wantDirect:   []string{"deploy api"},
+			wantIndirect: 1,
+		},
+		{
+			name: "should only process a mention once when it is also received as a message",
+			events: []string{
+				eventJSON("message", `"channel":"C1","user":"U1","text":"<@bot> deploy api","ts":"1.1"`),
+				eventJSON("app_mention", `"channel":"C1","user":"U1","text":"<@bot> deploy api","ts":"1.1"`),
+			},
+			wantDirect:   []string{"deploy api"},
+			wantIndirect: 1,
+		},
+		{
+			name: "should only process a mention once when the app mention is received first",
+			events: []string{
+				eventJSON("app_mention", `"channel":"C1","user":"U1","text":"<@bot> deploy api","ts":"1.1"`),
+				eventJSON("message", `"channel":"C1","user":"U1","text":"<@bot> deploy api","ts":"1.1"`),
+			},
+			wantDirect:   []string{"deploy api"},
+			wantIndirect: 1,
+		},
+		{
+			name: "should process an app mention after a message that mentions the bot later in its text",
+			events: []string{
+				eventJSON("message", `"channel":"C1","user":"U1","text":"deploy <@bot> api","ts":"1.1"`),
+				eventJSON("app_mention", `"channel":"C1","user":"U1","text":"deploy <@bot> api","ts":"1.1"`),
+			},
+			wantDirect:   []string{"deploy api"},
+			wantIndirect: 1,
+		},
+		{
+			name: "should only run the indirect listeners once when the app mention is received first",
+			events: []string{
+				eventJSON("app_mention", `"channel":"C1","user":"U1","text":"deploy <@bot> api","ts":"1.1"`),
+				eventJSON("message", `"channel":"C1","user":"U1","text":"deploy <@bot> api","ts":"1.1"`),
+			},
+			wantDirect:   []string{"deploy api"},
+			wantIndirect: 1,
+		},
+		{
+			name:      "should only process a message in a shared channel once in an Enterprise Grid organization",
+			workspace: Workspace{EnterpriseID: "E1", TeamID: "T1"},
+			events: []string{
+				eventJSON("message", `"channel":"C1","user":"U1","text":"deploy api","ts":"1.1"`),
+				eventJSON("message", `"channel":"C1","user":"U1","text":"deploy api","ts":"1.1","team":"T2"`),
+			},
+			wantIndirect: 1,
+		},
+		{
+			name: "should process repeated messages outside of Enterprise Grid",
+			events: []string{
+				eventJSON("message", `"channel":"C1","user":"U1","text":"deploy api","ts":"1.1"`),
+				eventJSON("message", `"channel":"C1","user":"U1","text":"deploy api","ts":"1.1"`),
+			},
+			wantIndirect: 2,
+		},
+		{
+			name: "should ignore an event that is delivered again",
+			events: []string{
+				withEventID("Ev1", eventJSON("message", `"channel":"C1","user":"U1","text":"deploy api","ts":"1.1"`)),
+				withEventID("Ev1", eventJSON("message", `"channel":"C1","user":"U1","text":"deploy api","ts":"1.1"`)),
+			},
+			wantIndirect: 1,
+		},
+		{
+			name:   "should ignore other events",
+			events: []string{eventJSON("reaction_added", `"user":"U1","reaction":"thumbsup"`)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var direct []string
+			indirect := 0
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						return s, "ts", nil
+					},
+				},
+				DirectListeners: []Listener{
+					{
+						Regex: regexp.MustCompile(`^deploy`),
+						Handler: func(bot *Bot, ev *slack.MessageEvent) {
+							direct = append(direct, ev.Text)
+						},
+					},
+				},
+				IndirectListeners: []Listener{
+					{
+						Regex: regexp.MustCompile(`deploy`),
+						Handler: func(bot *Bot, ev *slack.MessageEvent) {
+							indirect++
+						},
+					},
+				},
+				userDetails: &slack.UserDetails{ID: "bot"},
+				workspace:   tt.workspace,
+			}
+			for _, e := range tt.events {
+				ev, err := slackevents.ParseEvent(json.RawMessage(e), slackevents.OptionNoVerifyToken())
+				if err != nil {
+					t.Fatalf("ParseEvent() error = %v", err)
+				}
+				bot.HandleEventsAPIEvent(ev)
+			}
+			if strings.Join(direct, ",") != strings.Join(tt.wantDirect, ",") {
+				t.Errorf("direct listener handled %q, want %q", direct, tt.wantDirect)
+			}
+			if indirect != tt.wantIndirect {
+				t.Errorf("indirect listener handled %d messages, want %d", indirect, tt.wantIndirect)
+			}
+		})
+	}
+}
+
+func eventJSON(eventType string, fields string) string {
+	return fmt.Sprintf(`{"type":"event_callback","team_id":"T1","api_app_id":"A1","event":{"type":"%s",%s}}`, eventType, fields)
+}
+
+func withEventID(id string, event string) string {
+	return strings.Replace(event, `"type":"event_callback"`, fmt.Sprintf(`"type":"event_callback","event_id":"%s"`, id), 1)
+}
+
+func signRequest(req *http.Request, secret string, body string) {
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte("v0:" + ts + ":" + body))
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+}
+
+func TestBot_EventsHandler_schedulesTasks(t *testing.T) {
+	api, sent, _ := escalationAPI()
+	store := NewMemoryStore(nil)
+	esc := &Escalation{
+		ID:             "E1",
+		Text:           "the api is down",
+		Policy:         EscalationPolicy{Timeout: time.Hour, Escalations: []string{"fallback"}},
+		Notified:       []EscalationNotice{{Target: "team", Channel: "team", Timestamp: "1.1"}},
+		NextEscalation: time.Now().Add(-time.Minute),
+	}
+	if err := store.Put(escalationStoreKey, map[string]*Escalation{esc.ID: esc}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	bot := &Bot{API: api, Store: store, SigningSecret: "secret"}
+	defer bot.Stop()
+	_ = bot.EventsHandler()
+	_ = bot.EventsHandler()
+
+	waitFor(t, func() bool { return len(sent()) == 1 })
+	bot.mu.Lock()
+	scheduled := bot.scheduler != nil
+	bot.mu.Unlock()
+	if !scheduled {
+		t.Errorf("EventsHandler() did not schedule the bot's tasks")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := sent(); len(got) != 1 || got[0] != "fallback" {
+		t.Errorf("sent to = %v, want the resumed escalation sent once", got)
+	}
+}
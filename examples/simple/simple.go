@@ -1,6 +1,8 @@
 package main
 
 import (
+	"flag"
+	"os"
 	"regexp"
 
 	"github.com/daftn/slackbot"
@@ -29,6 +31,16 @@ func main() {
 		},
 	}
 
+	// Run with -console to chat with the bot in your terminal without a token.
+	console := flag.Bool("console", false, "run the bot in the terminal instead of connecting to slack")
+	flag.Parse()
+	if *console {
+		if err := bot.StartConsole(os.Stdin, os.Stdout); err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	err := bot.Start()
 	if err != nil {
 		panic("error starting bot")
@@ -1,14 +1,21 @@
 package slackbot
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"regexp"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/slack-go/slack"
 )
 
-const firstStepIndex = 1
+const (
+	firstStepIndex    = 1
+	expectFileMessage = "I'm waiting for a file, please share it in this thread."
+)
 
 type (
 	// Exchange is used to have a back and forth conversation between a slack user and a slack bot.
@@ -22,6 +29,10 @@ type (
 		// Usage describes how to use the exchange. It will be returned with GetHelp().
 		Usage string
 
+		// Category groups related commands and Examples show how to use it, see Listener.Category.
+		Category string
+		Examples []string
+
 		// Map of steps in sequential order numbered from 1 -> n, with the step number as the key.
 		// They must start with 1 and increase by one for each step.
 		Steps map[int]*Step
@@ -29,6 +40,10 @@ type (
 		// A data store to allow data to be passed between steps.
 		Store Store
 
+		// StoreCodec encodes the values in the exchange's Store, the default is the GobCodec. The JSONCodec
+		// makes the values readable when debugging.
+		StoreCodec Codec
+
 		// A pointer to the bot that owns the exchange.
 		Bot *Bot
 
@@ -39,8 +54,29 @@ type (
 		Channel string
 
 		// User that initiated the exchange.
-		User        string
+		User string
+
+		// StepTimeout is the Timeout used for steps that don't set one.
+		StepTimeout time.Duration
+
+		// Destructive exchanges aren't started when the bot is ReadOnly, see Listener.Destructive.
+		Destructive bool
+
+		// FeatureFlag is checked with the bot's FlagProvider before the exchange is started, see
+		// Listener.FeatureFlag.
+		FeatureFlag string
+
+		// ACL restricts who can start the exchange and where, as well as the bot's ACL.
+		ACL *ACL
+
+		// AllowExternal lets users of other organizations use the exchange, see Listener.AllowExternal.
+		AllowExternal bool
+
 		currentStep int
+		running     *sync.Mutex
+		ctx         context.Context
+		base        context.Context
+		cancel      context.CancelFunc
 	}
 
 	// Step Exchanges contain a list of Steps. Steps have three potential interaction methods: Message,
@@ -49,7 +85,9 @@ type (
 	// be checked, if it is set the Handler will be called. If the message and handler are not set,
 	// the MsgHandler will be called. As the exchange moves to the next step if MsgHandler is the
 	// interaction method, the MsgHandler will not be called until an incoming message event happens
-	// on the exchange's thread.
+	// on the exchange's thread. Steps that set ExpectFile will wait for a file to be shared in the
+	// exchange's thread instead, see ExpectFile, and steps that set Select will ask the user to choose
+	// an option, see SelectMenu.
 	Step struct {
 
 		// Name of the step, used for readability and in log messages.
@@ -68,6 +106,23 @@ type (
 		// not increment, the exchange will wait for another incoming message event and the
 		// MsgHandler will be retried.
 		MsgHandler func(exchange *Exchange, event *slack.MessageEvent) (retry bool, err error)
+
+		// ExpectFile will cause the step to wait for a file to be shared in the exchange thread. When
+		// a message with a file is posted in the thread the first file will be downloaded and passed
+		// to the FileHandler along with its content. Messages without a file will be ignored.
+		ExpectFile bool
+
+		// FileHandler function will be called when ExpectFile is set on the step. It behaves the
+		// same as the MsgHandler, if retry is returned as true the exchange will wait for another file.
+		FileHandler func(exchange *Exchange, file *SharedFile, content []byte) (retry bool, err error)
+
+		// Select asks the user to choose one of the menu's options, the step moves on once they have.
+		Select *SelectMenu
+
+		// Timeout is the maximum amount of time the step's handler should take. If it is exceeded a message
+		// will be sent to the exchange's thread and the context returned by exchange.Context() will be cancelled.
+		// It defaults to the exchange's StepTimeout.
+		Timeout time.Duration
 	}
 )
 
@@ -80,6 +135,19 @@ func (ex *Exchange) incrementCurrentStep() bool {
 	return false
 }
 
+// resume continues the exchange with the event. Events are handled one at a time, so a message that arrives in
+// the thread while a step is running waits for it, and is ignored if the exchange ended.
+func (ex *Exchange) resume(ev *slack.MessageEvent) {
+	if ex.running != nil {
+		ex.running.Lock()
+		defer ex.running.Unlock()
+	}
+	if ex.terminated() {
+		return
+	}
+	ex.continueExecution(ev)
+}
+
 func (ex *Exchange) continueExecution(ev *slack.MessageEvent) {
 	step, err := ex.GetCurrentStep()
 	initialStep := ex.currentStep
@@ -91,12 +159,49 @@ func (ex *Exchange) continueExecution(ev *slack.MessageEvent) {
 	if step.Message != "" {
 		ex.Reply(step.Message)
 	} else if step.Handler != nil {
-		if err := step.Handler(ex); err != nil {
+		var err error
+		ex.runStep(step, func() {
+			err = step.Handler(ex)
+		})
+		if err != nil {
 			ex.handleError(step, err)
 			return
 		}
+	} else if step.ExpectFile && step.FileHandler != nil {
+		if ev == nil {
+			return
+		}
+		if len(ev.Files) == 0 {
+			ex.Reply(expectFileMessage)
+			return
+		}
+		file := &SharedFile{File: ev.Files[0], bot: ex.Bot}
+		var content bytes.Buffer
+		if err := file.Download(&content); err != nil {
+			ex.handleError(step, err)
+			return
+		}
+		var retry bool
+		ex.runStep(step, func() {
+			retry, err = step.FileHandler(ex, file, content.Bytes())
+		})
+		if retry {
+			ex.continueExecution(nil)
+			return
+		}
+		if err != nil {
+			ex.handleError(step, err)
+			return
+		}
+	} else if step.Select != nil && step.Select.Options != nil {
+		if !ex.runSelect(step, ev) {
+			return
+		}
 	} else if step.MsgHandler != nil && ev != nil {
-		retry, err := step.MsgHandler(ex, ev)
+		var retry bool
+		ex.runStep(step, func() {
+			retry, err = step.MsgHandler(ex, ev)
+		})
 		if retry {
 			ex.continueExecution(nil)
 			return
@@ -109,21 +214,105 @@ func (ex *Exchange) continueExecution(ev *slack.MessageEvent) {
 		return
 	}
 
+	if ex.terminated() {
+		return
+	}
 	if initialStep == ex.currentStep && !ex.incrementCurrentStep() {
-		delete(ex.Bot.activeExchanges, ex.Thread)
+		ex.end()
 		return
 	}
 	ex.continueExecution(nil)
 }
 
+// runStep calls fn, which should call one of the step's handlers, enforcing the step's Timeout.
+func (ex *Exchange) runStep(step *Step, fn func()) {
+	timeout := step.Timeout
+	if timeout == 0 {
+		timeout = ex.StepTimeout
+	}
+	parent := ex.base
+	if parent == nil {
+		parent = ex.Bot.context()
+	}
+	runWithTimeout(parent, ex.Bot.clock(), timeout, func(ctx context.Context) {
+		ex.ctx = ctx
+		defer func() { ex.ctx = nil }()
+		defer ex.Bot.recoverPanic(ex.errorInfo(), func(err error) {
+			ex.handleError(step, err)
+		})
+		fn()
+	}, func() {
+		// The step's context has timed out, and the step may still be running, so the bot's context is used.
+		ex.reply(ex.Bot.context(), slack.MsgOptionText(fmt.Sprintf(handlerTimeoutMessage, timeout), false))
+	})
+}
+
+// begin creates the context that is cancelled when the exchange is terminated.
+func (ex *Exchange) begin() {
+	ex.base, ex.cancel = context.WithCancel(ex.Bot.context())
+}
+
+// end removes the exchange from the bot's active exchanges and cancels its context.
+func (ex *Exchange) end() {
+	if ex.cancel != nil {
+		ex.cancel()
+	}
+	ex.Bot.mu.Lock()
+	delete(ex.Bot.activeExchanges, ex.Thread)
+	ex.Bot.mu.Unlock()
+}
+
+// activeExchange returns the exchange running in the thread.
+func (bot *Bot) activeExchange(thread string) (*Exchange, bool) {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	ex, ok := bot.activeExchanges[thread]
+	return ex, ok
+}
+
+// setActiveExchange makes the exchange the one running in its thread.
+func (bot *Bot) setActiveExchange(ex *Exchange) {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.activeExchanges == nil {
+		bot.activeExchanges = make(map[string]*Exchange)
+	}
+	bot.activeExchanges[ex.Thread] = ex
+}
+
+// terminated returns true if the exchange was terminated while a step was executing.
+func (ex *Exchange) terminated() bool {
+	return ex.base != nil && ex.base.Err() != nil
+}
+
+func (ex *Exchange) errorInfo() ErrorInfo {
+	return ErrorInfo{
+		Source:  ErrorSourceExchange,
+		Channel: ex.Channel,
+		User:    ex.User,
+		Thread:  ex.Thread,
+	}
+}
+
+// Context returns the context for the step that is currently executing. It will be cancelled
+// when the step's Timeout is exceeded or the exchange is terminated, handlers doing long running
+// work should stop when it is done.
+func (ex *Exchange) Context() context.Context {
+	if ex.ctx == nil {
+		return context.Background()
+	}
+	return ex.ctx
+}
+
 func (ex *Exchange) handleError(step *Step, err error) {
 	stepName := ""
 	if step != nil {
 		stepName = step.Name
 	}
 	msg := fmt.Sprintf("An error has occurred in exchange %s-%s, step %d %s: %s", ex.Channel, ex.Thread, ex.currentStep, stepName, err)
-	ex.Bot.LogDebug(msg)
-	delete(ex.Bot.activeExchanges, ex.Thread)
+	ex.Bot.LogError(msg)
+	ex.Bot.reportError(err, ex.errorInfo())
+	ex.end()
 }
 
 // GetCurrentStep will get the current step. If there is no step in the exchange with the
@@ -146,12 +335,10 @@ func (ex *Exchange) SkipToStep(i int) error {
 }
 
 // Terminate will remove the exchange from the bot's active exchanges list so the next steps will not be executed.
+// The context returned by Context() is cancelled, so a step that is currently executing can stop its work.
 func (ex *Exchange) Terminate() {
-
-	// TODO - figure out if there is a way to kill the currently executing step
-
 	ex.Bot.LogDebug(fmt.Sprintf("killing exchange %s", ex.Thread))
-	delete(ex.Bot.activeExchanges, ex.Thread)
+	ex.end()
 }
 
 // Reply will send a message to the exchange's channel and thread.
@@ -160,10 +347,20 @@ func (ex *Exchange) Reply(msg string) {
 }
 
 // ReplyWithOptions will send a message to the exchange's channel and thread with the options specified.
-// See Bot.ReplyWithOptions method for more information on sending messages with message options.
+// See Bot.ReplyWithOptions method for more information on sending messages with message options. Replies
+// sent while a step is running are cancelled with the step's context.
 func (ex *Exchange) ReplyWithOptions(options ...slack.MsgOption) {
+	ctx := ex.ctx
+	if ctx == nil {
+		ctx = ex.Bot.context()
+	}
+	ex.reply(ctx, options...)
+}
+
+// reply sends a message to the exchange's channel and thread, the slack api calls are cancelled with ctx.
+func (ex *Exchange) reply(ctx context.Context, options ...slack.MsgOption) {
 	options = append(options, slack.MsgOptionTS(ex.Thread))
-	if _, _, err := ex.Bot.ReplyWithOptions(ex.Channel, options...); err != nil {
+	if _, _, err := ex.Bot.ReplyWithOptionsContext(ctx, ex.Channel, options...); err != nil {
 		if s, _ := ex.GetCurrentStep(); s != nil {
 			ex.handleError(s, err)
 		}
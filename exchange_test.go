@@ -1,11 +1,14 @@
 package slackbot
 
 import (
+	"context"
 	"errors"
+	"io"
 	"reflect"
 	"regexp"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/slack-go/slack"
 )
@@ -648,3 +651,228 @@ func TestExchange_incrementCurrentStep(t *testing.T) {
 		})
 	}
 }
+
+func TestExchange_continueExecution_expectFile(t *testing.T) {
+	var gotContent string
+	replies := 0
+	tests := []struct {
+		name        string
+		ev          *slack.MessageEvent
+		wantContent string
+		wantReplies int
+		wantStep    int
+	}{
+		{
+			name: "should download the file and call the file handler",
+			ev: &slack.MessageEvent{
+				Msg: slack.Msg{
+					Files: []slack.File{{Name: "config.yml", URLPrivateDownload: "file contents"}},
+				},
+			},
+			wantContent: "file contents",
+			wantStep:    2,
+		},
+		{
+			name: "should wait for a file if the message has none",
+			ev: &slack.MessageEvent{
+				Msg: slack.Msg{
+					Text: "here you go",
+				},
+			},
+			wantReplies: 1,
+			wantStep:    1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotContent = ""
+			replies = 0
+			ex := &Exchange{
+				Steps: map[int]*Step{
+					1: {
+						Name:       "receive file",
+						ExpectFile: true,
+						FileHandler: func(ex *Exchange, file *SharedFile, content []byte) (bool, error) {
+							gotContent = string(content)
+							return false, nil
+						},
+					},
+					2: {
+						Name:       "wait",
+						MsgHandler: func(ex *Exchange, ev *slack.MessageEvent) (bool, error) { return false, nil },
+					},
+				},
+				Bot: &Bot{
+					API: &mockAPI{
+						getFile: func(url string, w io.Writer) error {
+							_, err := w.Write([]byte(url))
+							return err
+						},
+						postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+							replies++
+							return "", "", nil
+						},
+					},
+					activeExchanges: map[string]*Exchange{},
+				},
+				Thread:      "test_thread",
+				currentStep: 1,
+			}
+			ex.continueExecution(tt.ev)
+			if gotContent != tt.wantContent {
+				t.Errorf("file content got = %v, want %v", gotContent, tt.wantContent)
+			}
+			if replies != tt.wantReplies {
+				t.Errorf("replies got = %v, want %v", replies, tt.wantReplies)
+			}
+			if ex.currentStep != tt.wantStep {
+				t.Errorf("current step got = %v, want %v", ex.currentStep, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestExchange_Terminate_cancelsStep(t *testing.T) {
+	tests := []struct {
+		name        string
+		stepTimeout time.Duration
+		wantReplies int
+	}{
+		{
+			name: "should cancel the executing step and not run the next steps",
+		},
+		{
+			name:        "should cancel a step with a timeout without sending the timeout message",
+			stepTimeout: time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			replies := 0
+			started := make(chan struct{})
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						mu.Lock()
+						defer mu.Unlock()
+						replies++
+						return s, "ts", nil
+					},
+				},
+				activeExchanges: map[string]*Exchange{},
+			}
+			var stepErr error
+			ex := &Exchange{
+				Steps: map[int]*Step{
+					1: {
+						Name: "long running",
+						Handler: func(ex *Exchange) error {
+							close(started)
+							<-ex.Context().Done()
+							stepErr = ex.Context().Err()
+							return nil
+						},
+					},
+					2: {Name: "next", Message: "should not be sent"},
+				},
+				Bot:         bot,
+				Thread:      "test_thread",
+				StepTimeout: tt.stepTimeout,
+				currentStep: firstStepIndex,
+			}
+			ex.begin()
+			bot.activeExchanges[ex.Thread] = ex
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				ex.continueExecution(nil)
+			}()
+			<-started
+			ex.Terminate()
+			<-done
+
+			if stepErr != context.Canceled {
+				t.Errorf("step context error = %v, want %v", stepErr, context.Canceled)
+			}
+			if replies != tt.wantReplies {
+				t.Errorf("replies got = %v, want %v", replies, tt.wantReplies)
+			}
+			if ex.currentStep != firstStepIndex {
+				t.Errorf("current step got = %v, want %v", ex.currentStep, firstStepIndex)
+			}
+			if len(bot.activeExchanges) != 0 {
+				t.Errorf("active exchange count got = %v, want 0", len(bot.activeExchanges))
+			}
+		})
+	}
+}
+
+func TestExchange_resume(t *testing.T) {
+	var mu sync.Mutex
+	var handled []string
+	started := make(chan struct{})
+	release := make(chan struct{})
+	bot := &Bot{
+		API: &mockAPI{
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				return s, "ts", nil
+			},
+		},
+	}
+	template := &Exchange{
+		Steps: map[int]*Step{
+			1: {
+				Name: "first",
+				MsgHandler: func(ex *Exchange, ev *slack.MessageEvent) (bool, error) {
+					close(started)
+					<-release
+					mu.Lock()
+					defer mu.Unlock()
+					handled = append(handled, "first "+ev.Text)
+					return false, nil
+				},
+			},
+			2: {
+				Name: "second",
+				MsgHandler: func(ex *Exchange, ev *slack.MessageEvent) (bool, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					handled = append(handled, "second "+ev.Text)
+					return false, nil
+				},
+			},
+		},
+	}
+	ex, err := bot.newExchange(&slack.MessageEvent{Msg: slack.Msg{Channel: "C1", Timestamp: "1.1"}}, template)
+	if err != nil {
+		t.Fatalf("newExchange() error = %v", err)
+	}
+	bot.setActiveExchange(ex)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ex.resume(&slack.MessageEvent{Msg: slack.Msg{Text: "a"}})
+	}()
+	<-started
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ex.resume(&slack.MessageEvent{Msg: slack.Msg{Text: "b"}})
+	}()
+	close(release)
+	wg.Wait()
+	ex.resume(&slack.MessageEvent{Msg: slack.Msg{Text: "c"}})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"first a", "second b"}; !reflect.DeepEqual(handled, want) {
+		t.Errorf("handled = %q, want %q", handled, want)
+	}
+	if _, ok := bot.activeExchange(ex.Thread); ok {
+		t.Errorf("activeExchange() found the exchange after it ended")
+	}
+}
@@ -0,0 +1,119 @@
+package slackbot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	externalUsersTTL      = time.Hour
+	externalWarningTTL    = time.Minute
+	externalDeniedMessage = "Sorry, this command isn't available to users outside of the organization."
+)
+
+// ExternalUserPolicy decides how the bot handles messages from external users, who are members of another
+// organization in a Slack Connect channel.
+type ExternalUserPolicy int
+
+const (
+	// ExternalAllow handles messages from external users like any other message, it is the default.
+	ExternalAllow ExternalUserPolicy = iota
+
+	// ExternalIgnore ignores all messages from external users.
+	ExternalIgnore
+
+	// ExternalRestrict only lets external users use listeners, exchanges and forms with AllowExternal set.
+	// They are told other commands aren't available to them.
+	ExternalRestrict
+
+	// ExternalWarn handles messages from external users, and logs a warning when they use a command so it
+	// can be audited.
+	ExternalWarn
+)
+
+// IsExternalUser returns true if the user is a member of another organization, from a Slack Connect channel.
+// Users of other workspaces in the bot's Enterprise Grid organization are not external. The result is cached
+// for an hour. Users that can't be looked up are treated as external unless the bot's ExternalUsers policy is
+// ExternalAllow, so a failed lookup doesn't let them past the policy.
+func (bot *Bot) IsExternalUser(user string) bool {
+	if user == "" {
+		return false
+	}
+	c := bot.externalUserCache()
+	if external, ok := c.get(user); ok {
+		return external.(bool)
+	}
+	var info *slack.User
+	err := bot.withRetry(func() (err error) {
+		info, err = bot.api().GetUserInfoContext(bot.context(), user)
+		return err
+	})
+	if err != nil {
+		bot.LogWarn(fmt.Sprintf("unable to check if %s is an external user - %s", user, err))
+		return bot.ExternalUsers != ExternalAllow
+	}
+	external := bot.isExternal(info)
+	c.set(user, external)
+	return external
+}
+
+// isExternal returns true if the user is not a member of the bot's workspace or organization.
+func (bot *Bot) isExternal(u *slack.User) bool {
+	if u.IsStranger {
+		return true
+	}
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	own := bot.workspace
+	if own.TeamID == "" || u.TeamID == "" || u.TeamID == own.TeamID {
+		return false
+	}
+	if own.EnterpriseID == "" {
+		return true
+	}
+	return u.Enterprise.EnterpriseID != own.EnterpriseID && bot.enterprises[u.TeamID] != own.EnterpriseID
+}
+
+// externalDenied returns true if the user sent the message from another organization and the bot's
+// ExternalUsers policy doesn't let them use the command. If notify is set they are told the command isn't
+// available. With ExternalWarn the warning is logged once for the message, for the first command it matched.
+func (bot *Bot) externalDenied(allowExternal bool, command string, ev *slack.MessageEvent, notify bool) bool {
+	if bot.ExternalUsers == ExternalAllow || !bot.IsExternalUser(ev.User) {
+		return false
+	}
+	switch bot.ExternalUsers {
+	case ExternalWarn:
+		if bot.externalWarningCache().add(deliveryKey(ev), true) {
+			bot.LogWarn(fmt.Sprintf("external user %s used %s in %s", ev.User, command, ev.Channel))
+		}
+		return false
+	case ExternalRestrict:
+		if allowExternal {
+			return false
+		}
+		if notify {
+			_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), externalDeniedMessage)
+		}
+	}
+	return true
+}
+
+func (bot *Bot) externalUserCache() *cache {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.externalUsers == nil {
+		bot.externalUsers = newCache(externalUsersTTL, bot.clock())
+	}
+	return bot.externalUsers
+}
+
+func (bot *Bot) externalWarningCache() *cache {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.externalWarned == nil {
+		bot.externalWarned = newCache(externalWarningTTL, bot.clock())
+	}
+	return bot.externalWarned
+}
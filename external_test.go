@@ -0,0 +1,182 @@
+package slackbot
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func TestBot_IsExternalUser(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    ExternalUserPolicy
+		workspace Workspace
+		user      *slack.User
+		err       error
+		want      bool
+	}{
+		{
+			name:      "should not treat members of the bot's workspace as external",
+			workspace: Workspace{TeamID: "T1"},
+			user:      &slack.User{ID: "U1", TeamID: "T1"},
+		},
+		{
+			name:      "should treat members of other workspaces as external",
+			workspace: Workspace{TeamID: "T1"},
+			user:      &slack.User{ID: "U1", TeamID: "T9"},
+			want:      true,
+		},
+		{
+			name:      "should treat strangers as external",
+			workspace: Workspace{TeamID: "T1"},
+			user:      &slack.User{ID: "U1", IsStranger: true},
+			want:      true,
+		},
+		{
+			name:      "should not treat members of the bot's organization as external",
+			workspace: Workspace{EnterpriseID: "E1", TeamID: "T1"},
+			user:      &slack.User{ID: "W1", TeamID: "T2", Enterprise: slack.EnterpriseUser{EnterpriseID: "E1"}},
+		},
+		{
+			name:      "should treat members of other organizations as external",
+			workspace: Workspace{EnterpriseID: "E1", TeamID: "T1"},
+			user:      &slack.User{ID: "W1", TeamID: "T9", Enterprise: slack.EnterpriseUser{EnterpriseID: "E9"}},
+			want:      true,
+		},
+		{
+			name:      "should treat users that can't be found as internal when external users are allowed",
+			workspace: Workspace{TeamID: "T1"},
+			err:       errors.New("user_not_found"),
+		},
+		{
+			name:      "should treat users that can't be found as external when external users are restricted",
+			policy:    ExternalRestrict,
+			workspace: Workspace{TeamID: "T1"},
+			err:       errors.New("user_not_found"),
+			want:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{
+				ExternalUsers: tt.policy,
+				workspace:     tt.workspace,
+				API: &mockAPI{getUserInfo: func(string) (*slack.User, error) {
+					return tt.user, tt.err
+				}},
+			}
+			if got := bot.IsExternalUser("U1"); got != tt.want {
+				t.Errorf("IsExternalUser() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_processMessage_externalUsers(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        ExternalUserPolicy
+		allowExternal bool
+		wantHandled   bool
+		wantDenied    bool
+	}{
+		{
+			name:        "should let external users run commands by default",
+			policy:      ExternalAllow,
+			wantHandled: true,
+		},
+		{
+			name:   "should ignore external users",
+			policy: ExternalIgnore,
+		},
+		{
+			name:       "should tell external users the command isn't available",
+			policy:     ExternalRestrict,
+			wantDenied: true,
+		},
+		{
+			name:          "should let external users run commands that allow them",
+			policy:        ExternalRestrict,
+			allowExternal: true,
+			wantHandled:   true,
+		},
+		{
+			name:        "should run the command and warn",
+			policy:      ExternalWarn,
+			wantHandled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled, denied := false, false
+			bot := &Bot{
+				ExternalUsers: tt.policy,
+				workspace:     Workspace{TeamID: "T1"},
+				API: &mockAPI{
+					getUserInfo: func(string) (*slack.User, error) {
+						return &slack.User{ID: "U1", TeamID: "T9"}, nil
+					},
+					postMessage: func(channel string, opts ...slack.MsgOption) (string, string, error) {
+						denied = denied || msgValues(opts...).Get("text") == externalDeniedMessage
+						return channel, "1.2", nil
+					},
+				},
+				DirectListeners: []Listener{
+					{
+						Regex:         regexp.MustCompile(`^deploy`),
+						AllowExternal: tt.allowExternal,
+						Handler: func(bot *Bot, ev *slack.MessageEvent) {
+							handled = true
+						},
+					},
+				},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			bot.init()
+			bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: "U1", Text: "deploy api", Timestamp: "1.1"}})
+			if handled != tt.wantHandled {
+				t.Errorf("handled = %v, want %v", handled, tt.wantHandled)
+			}
+			if denied != tt.wantDenied {
+				t.Errorf("denied = %v, want %v", denied, tt.wantDenied)
+			}
+		})
+	}
+}
+
+func TestBot_externalDenied_warnOnce(t *testing.T) {
+	var warnings []string
+	handled := 0
+	handler := func(bot *Bot, ev *slack.MessageEvent) { handled++ }
+	bot := &Bot{
+		ExternalUsers: ExternalWarn,
+		workspace:     Workspace{TeamID: "T1"},
+		API: &mockAPI{
+			getUserInfo: func(string) (*slack.User, error) {
+				return &slack.User{ID: "U1", TeamID: "T9"}, nil
+			},
+			postMessage: func(channel string, opts ...slack.MsgOption) (string, string, error) {
+				if channel == "debug" {
+					warnings = append(warnings, msgValues(opts...).Get("text"))
+				}
+				return channel, "1.2", nil
+			},
+		},
+		IndirectListeners: []Listener{
+			{Regex: regexp.MustCompile(`deploy`), Handler: handler},
+			{Regex: regexp.MustCompile(`api`), Handler: handler},
+		},
+		userDetails: &slack.UserDetails{ID: "bot"},
+	}
+	bot.init()
+	bot.DebugChannel = "debug"
+	bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "C1", User: "U1", Text: "deploy api", Timestamp: "1.1"}})
+	if handled != 2 {
+		t.Errorf("handled = %d, want 2", handled)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %q, want 1", warnings)
+	}
+}
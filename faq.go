@@ -0,0 +1,288 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	faqStoreKey            = "faq"
+	defaultFAQThreshold    = 0.5
+	faqMinPrefixLength     = 4
+	faqNoAnswerMessage     = "Sorry, I don't know the answer to that."
+	faqAddedMessage        = "Added FAQ %s."
+	faqRemovedMessage      = "Removed FAQ %s."
+	faqNoneMessage         = "There are no FAQs."
+	faqErrorMessage        = "Sorry, I wasn't able to do that - %s"
+	faqListItemMessage     = "• `%s` %s"
+	faqListFileItemMessage = "• %s"
+)
+
+var (
+	faqQuestionRegex = regexp.MustCompile(`^(?i)(?:faq )?(.+\?)$`)
+	faqAddRegex      = regexp.MustCompile(`^(?i)faq add (.+?)\s*\|\s*(.+)$`)
+	faqRemoveRegex   = regexp.MustCompile(`^(?i)faq remove (\w+)$`)
+	faqListRegex     = regexp.MustCompile(`^(?i)faq list$`)
+
+	faqStopWords = map[string]bool{
+		"a": true, "an": true, "and": true, "are": true, "can": true, "do": true, "does": true, "for": true,
+		"how": true, "i": true, "in": true, "is": true, "it": true, "my": true, "of": true, "on": true,
+		"the": true, "this": true, "to": true, "we": true, "what": true, "when": true, "where": true, "who": true,
+		"why": true, "you": true,
+	}
+)
+
+type (
+	// FAQ answers questions asked to the bot with the closest matching entry. Questions are matched by the
+	// words they share with an entry's Question and Keywords, allowing for plurals and small typos, and the
+	// best answer is only sent if its score, from 0 to 1, is at least the Threshold. Entries can be set in
+	// code or loaded with LoadFAQFile, and more can be added in chat, which are saved in the bot's Store.
+	FAQ struct {
+		Entries   []FAQEntry
+		Threshold float64
+	}
+
+	// FAQEntry is a question and its answer. Keywords are other words that should match the question. The ID
+	// is set for entries added with AddFAQ.
+	FAQEntry struct {
+		ID       string   `json:"id,omitempty"`
+		Question string   `json:"question"`
+		Answer   string   `json:"answer"`
+		Keywords []string `json:"keywords,omitempty"`
+	}
+)
+
+// LoadFAQFile reads FAQ entries from a JSON file containing a list of objects with "question", "answer" and
+// optionally "keywords".
+func LoadFAQFile(path string) ([]FAQEntry, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read faq file %s", path)
+	}
+	var entries []FAQEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse faq file %s", path)
+	}
+	return entries, nil
+}
+
+// FAQListeners returns DirectListeners that answer questions with the FAQ. Messages ending with a question
+// mark, or starting with "faq", are answered, so the listeners should be added after the bot's other
+// DirectListeners. The admin commands "faq add <question> | <answer>", "faq remove <id>" and "faq list"
+// manage the entries saved in the Store, only the users and channels allowed by the admins ACL can use them.
+func FAQListeners(faq FAQ, admins *ACL) []Listener {
+	return []Listener{
+		{
+			Usage: "faq add <question> | <answer>",
+			Regex: faqAddRegex,
+			ACL:   admins,
+			Handler: func(bot *Bot, ev *slack.MessageEvent) {
+				m := faqAddRegex.FindStringSubmatch(ev.Text)
+				entry, err := bot.AddFAQ(FAQEntry{Question: m[1], Answer: m[2]})
+				reply := fmt.Sprintf(faqAddedMessage, entry.ID)
+				if err != nil {
+					reply = fmt.Sprintf(faqErrorMessage, err)
+				}
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), reply)
+			},
+		},
+		{
+			Usage: "faq remove <id>",
+			Regex: faqRemoveRegex,
+			ACL:   admins,
+			Handler: func(bot *Bot, ev *slack.MessageEvent) {
+				ID := faqRemoveRegex.FindStringSubmatch(ev.Text)[1]
+				reply := fmt.Sprintf(faqRemovedMessage, ID)
+				if err := bot.RemoveFAQ(ID); err != nil {
+					reply = fmt.Sprintf(faqErrorMessage, err)
+				}
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), reply)
+			},
+		},
+		{
+			Usage: "faq list",
+			Regex: faqListRegex,
+			ACL:   admins,
+			Handler: func(bot *Bot, ev *slack.MessageEvent) {
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), formatFAQList(append(append([]FAQEntry{}, faq.Entries...), bot.FAQEntries()...)))
+			},
+		},
+		{
+			Usage: "faq <question>?",
+			Regex: faqQuestionRegex,
+			Handler: func(bot *Bot, ev *slack.MessageEvent) {
+				question := faqQuestionRegex.FindStringSubmatch(ev.Text)[1]
+				reply := faqNoAnswerMessage
+				if entry, ok := faq.Answer(question, bot.FAQEntries()...); ok {
+					reply = entry.Answer
+				}
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), reply)
+			},
+		},
+	}
+}
+
+// Answer returns the entry that best matches the question from the FAQ's Entries and the extra entries, and
+// false if none of them score at least the Threshold.
+func (faq *FAQ) Answer(question string, extra ...FAQEntry) (FAQEntry, bool) {
+	threshold := faq.Threshold
+	if threshold == 0 {
+		threshold = defaultFAQThreshold
+	}
+	words := faqWords(question)
+	var best FAQEntry
+	bestScore := 0.0
+	for _, entry := range append(append([]FAQEntry{}, faq.Entries...), extra...) {
+		if score := entry.score(words); score > bestScore {
+			best, bestScore = entry, score
+		}
+	}
+	return best, bestScore >= threshold
+}
+
+// AddFAQ saves the entry in the Store with a new ID and returns it.
+func (bot *Bot) AddFAQ(entry FAQEntry) (FAQEntry, error) {
+	if strings.TrimSpace(entry.Question) == "" || strings.TrimSpace(entry.Answer) == "" {
+		return FAQEntry{}, errors.New("an faq needs a question and an answer")
+	}
+	bot.faqMu.Lock()
+	defer bot.faqMu.Unlock()
+	entry.ID = newID()
+	if err := bot.store().Put(faqStoreKey, append(bot.loadFAQEntries(), entry)); err != nil {
+		return FAQEntry{}, errors.Wrap(err, "unable to save the faq")
+	}
+	return entry, nil
+}
+
+// RemoveFAQ removes the entry with the ID from the Store.
+func (bot *Bot) RemoveFAQ(ID string) error {
+	bot.faqMu.Lock()
+	defer bot.faqMu.Unlock()
+	entries := bot.loadFAQEntries()
+	for i, e := range entries {
+		if e.ID != ID {
+			continue
+		}
+		if err := bot.store().Put(faqStoreKey, append(entries[:i], entries[i+1:]...)); err != nil {
+			return errors.Wrap(err, "unable to remove the faq")
+		}
+		return nil
+	}
+	return errors.Errorf("there is no faq %s", ID)
+}
+
+// FAQEntries returns the entries saved in the Store with AddFAQ.
+func (bot *Bot) FAQEntries() []FAQEntry {
+	bot.faqMu.Lock()
+	defer bot.faqMu.Unlock()
+	return bot.loadFAQEntries()
+}
+
+// loadFAQEntries returns the entries in the Store, faqMu must be held.
+func (bot *Bot) loadFAQEntries() []FAQEntry {
+	var entries []FAQEntry
+	_ = bot.store().Get(faqStoreKey, &entries)
+	return entries
+}
+
+// score returns the Dice coefficient of the question's words and the entry's words, from 0 for no words in
+// common to 1 when they all match.
+func (entry *FAQEntry) score(words []string) float64 {
+	entryWords := faqWords(entry.Question + " " + strings.Join(entry.Keywords, " "))
+	if len(words) == 0 || len(entryWords) == 0 {
+		return 0
+	}
+	matched := 0
+	for _, w := range words {
+		for _, e := range entryWords {
+			if faqWordsMatch(w, e) {
+				matched++
+				break
+			}
+		}
+	}
+	return 2 * float64(matched) / float64(len(words)+len(entryWords))
+}
+
+// faqWords returns the distinct lowercase words in the text, without punctuation, single letters or stop words.
+func faqWords(text string) []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		if len(w) < 2 || faqStopWords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		words = append(words, w)
+	}
+	return words
+}
+
+// faqWordsMatch returns true if the words are the same, one is a prefix of the other, such as "deploy" and
+// "deploys", or they are long enough and differ by a single character.
+func faqWordsMatch(a string, b string) bool {
+	if a == b {
+		return true
+	}
+	if len(a) >= faqMinPrefixLength && len(b) >= faqMinPrefixLength && (strings.HasPrefix(a, b) || strings.HasPrefix(b, a)) {
+		return true
+	}
+	return len(a) > faqMinPrefixLength && len(b) > faqMinPrefixLength && editDistance(a, b) <= 1
+}
+
+// editDistance returns the Levenshtein distance between the words.
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+func min3(a int, b int, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// formatFAQList lists the questions, with the IDs of the entries that can be removed.
+func formatFAQList(entries []FAQEntry) string {
+	if len(entries) == 0 {
+		return faqNoneMessage
+	}
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		if e.ID == "" {
+			lines[i] = fmt.Sprintf(faqListFileItemMessage, e.Question)
+			continue
+		}
+		lines[i] = fmt.Sprintf(faqListItemMessage, e.ID, e.Question)
+	}
+	return strings.Join(lines, "\n")
+}
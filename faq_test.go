@@ -0,0 +1,199 @@
+package slackbot
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func testFAQ() FAQ {
+	return FAQ{
+		Entries: []FAQEntry{
+			{Question: "How do I deploy my app?", Answer: "Send me: deploy <app> to <env>"},
+			{Question: "Where can I find the logs?", Answer: "In the logging dashboard.", Keywords: []string{"kibana"}},
+			{Question: "Who is on call?", Answer: "Check the on call calendar."},
+		},
+	}
+}
+
+func TestFAQ_Answer(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold float64
+		question  string
+		extra     []FAQEntry
+		want      string
+		wantOk    bool
+	}{
+		{
+			name:     "should answer a matching question",
+			question: "how do i deploy an app",
+			want:     "Send me: deploy <app> to <env>",
+			wantOk:   true,
+		},
+		{
+			name:     "should allow for plurals and typos",
+			question: "deploying apps",
+			want:     "Send me: deploy <app> to <env>",
+			wantOk:   true,
+		},
+		{
+			name:     "should match keywords",
+			question: "where is kibana?",
+			want:     "In the logging dashboard.",
+			wantOk:   true,
+		},
+		{
+			name:     "should match extra entries",
+			question: "how do I request access?",
+			extra:    []FAQEntry{{ID: "a1", Question: "How do I request access?", Answer: "Ask in #it."}},
+			want:     "Ask in #it.",
+			wantOk:   true,
+		},
+		{
+			name:     "should not answer when nothing is close enough",
+			question: "what is the wifi password?",
+		},
+		{
+			name:      "should use the threshold",
+			threshold: 0.9,
+			question:  "where are the logs stored?",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			faq := testFAQ()
+			faq.Threshold = tt.threshold
+			got, ok := faq.Answer(tt.question, tt.extra...)
+			if ok != tt.wantOk {
+				t.Fatalf("Answer() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if ok && got.Answer != tt.want {
+				t.Errorf("Answer() = %q, want %q", got.Answer, tt.want)
+			}
+		})
+	}
+}
+
+func TestFAQListeners(t *testing.T) {
+	tests := []struct {
+		name        string
+		stored      []FAQEntry
+		user        string
+		text        string
+		wantReplies []string
+		wantStored  []FAQEntry
+	}{
+		{
+			name:        "should answer questions",
+			text:        "who's on call this week?",
+			wantReplies: []string{"Check the on call calendar."},
+		},
+		{
+			name:        "should answer questions saved in the store",
+			stored:      []FAQEntry{{ID: "a1", Question: "What is the wifi password?", Answer: "hunter2"}},
+			text:        "faq wifi password?",
+			wantReplies: []string{"hunter2"},
+			wantStored:  []FAQEntry{{ID: "a1", Question: "What is the wifi password?", Answer: "hunter2"}},
+		},
+		{
+			name:        "should reply when there is no answer",
+			text:        "what is the meaning of life?",
+			wantReplies: []string{faqNoAnswerMessage},
+		},
+		{
+			name:        "should remove an faq",
+			stored:      []FAQEntry{{ID: "a1", Question: "What is the wifi password?", Answer: "hunter2"}},
+			text:        "faq remove a1",
+			wantReplies: []string{"Removed FAQ a1."},
+			wantStored:  []FAQEntry{},
+		},
+		{
+			name:        "should not let other users remove an faq",
+			stored:      []FAQEntry{{ID: "a1", Question: "What is the wifi password?", Answer: "hunter2"}},
+			user:        "U2",
+			text:        "faq remove a1",
+			wantReplies: []string{aclUserDeniedMessage},
+			wantStored:  []FAQEntry{{ID: "a1", Question: "What is the wifi password?", Answer: "hunter2"}},
+		},
+		{
+			name:        "should list the faqs",
+			stored:      []FAQEntry{{ID: "a1", Question: "What is the wifi password?", Answer: "hunter2"}},
+			text:        "faq list",
+			wantReplies: []string{"• How do I deploy my app?\n• Where can I find the logs?\n• Who is on call?\n• `a1` What is the wifi password?"},
+			wantStored:  []FAQEntry{{ID: "a1", Question: "What is the wifi password?", Answer: "hunter2"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var replies []string
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						replies = append(replies, msgValues(opts...).Get("text"))
+						return s, "ts", nil
+					},
+				},
+				DirectListeners: FAQListeners(testFAQ(), &ACL{Users: []string{"U1"}}),
+				userDetails:     &slack.UserDetails{ID: "bot"},
+			}
+			bot.init()
+			if tt.stored != nil {
+				_ = bot.store().Put(faqStoreKey, tt.stored)
+			}
+			user := tt.user
+			if user == "" {
+				user = "U1"
+			}
+			bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: user, Text: tt.text, Timestamp: "1.1"}})
+			if !reflect.DeepEqual(replies, tt.wantReplies) {
+				t.Errorf("replies = %q, want %q", replies, tt.wantReplies)
+			}
+			if got := bot.FAQEntries(); len(got) > 0 || len(tt.wantStored) > 0 {
+				if !reflect.DeepEqual(got, tt.wantStored) {
+					t.Errorf("FAQEntries() = %v, want %v", got, tt.wantStored)
+				}
+			}
+		})
+	}
+}
+
+func TestBot_AddFAQ(t *testing.T) {
+	bot := &Bot{}
+	entry, err := bot.AddFAQ(FAQEntry{Question: "How do I get access?", Answer: "Ask in #it."})
+	if err != nil {
+		t.Fatalf("AddFAQ() error = %v", err)
+	}
+	if entry.ID == "" {
+		t.Errorf("AddFAQ() did not set an ID")
+	}
+	if got := bot.FAQEntries(); !reflect.DeepEqual(got, []FAQEntry{entry}) {
+		t.Errorf("FAQEntries() = %v, want %v", got, []FAQEntry{entry})
+	}
+	if _, err := bot.AddFAQ(FAQEntry{Question: "No answer?"}); err == nil {
+		t.Errorf("AddFAQ() should return an error for an entry without an answer")
+	}
+}
+
+func TestLoadFAQFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "faq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "faq.json")
+	_ = ioutil.WriteFile(path, []byte(`[{"question":"Who is on call?","answer":"Check the calendar.","keywords":["pager"]}]`), 0600)
+	got, err := LoadFAQFile(path)
+	if err != nil {
+		t.Fatalf("LoadFAQFile() error = %v", err)
+	}
+	want := []FAQEntry{{Question: "Who is on call?", Answer: "Check the calendar.", Keywords: []string{"pager"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadFAQFile() = %v, want %v", got, want)
+	}
+	if _, err := LoadFAQFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Errorf("LoadFAQFile() should return an error for a missing file")
+	}
+}
@@ -0,0 +1,82 @@
+package slackbot
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+type (
+	// FileListener listens for files shared in channels that the bot is a member of. When a file is
+	// shared the Handler will be called once for each file. If FileTypes is set, the Handler will only be
+	// called for files with a matching slack filetype such as "csv", "pdf" or "text".
+	FileListener struct {
+		// A string to be presented to users describing how to use the listener.
+		Usage     string
+		FileTypes []string
+		Handler   func(bot *Bot, ev *slack.MessageEvent, file *SharedFile)
+	}
+
+	// SharedFile is a file that was shared in slack. It is passed to FileListener handlers
+	// and can be downloaded with Download.
+	SharedFile struct {
+		slack.File
+		bot *Bot
+	}
+)
+
+// Download will write the contents of the file to w.
+func (f *SharedFile) Download(w io.Writer) error {
+	return f.bot.api().GetFile(f.URLPrivateDownload, w)
+}
+
+// UploadFileTo will upload the contents of r as a file with the name specified to the channel. If thread is
+// not empty the file will be shared in the thread. Uploads are not retried by the RetryPolicy since r can
+// only be read once.
+func (bot *Bot) UploadFileTo(channel string, thread string, name string, r io.Reader) (*slack.File, error) {
+	if err := bot.checkCircuitBreaker(channel); err != nil {
+		return nil, err
+	}
+	f, err := bot.api().UploadFileContext(bot.context(), slack.FileUploadParameters{
+		Reader:          r,
+		Filename:        name,
+		Channels:        []string{channel},
+		ThreadTimestamp: thread,
+	})
+	if err != nil {
+		bot.LogError(fmt.Sprintf("failure uploading file %s to %s with - %s", name, channel, err))
+	}
+	return f, err
+}
+
+func (bot *Bot) processFiles(ev *slack.MessageEvent) {
+	if ev.User == bot.userDetails.ID {
+		return
+	}
+	for _, f := range ev.Files {
+		for _, l := range bot.FileListeners {
+			if l.Handler != nil && l.matchesFileType(f.Filetype) {
+				bot.runFileListener(&l, ev, &SharedFile{File: f, bot: bot})
+			}
+		}
+	}
+}
+
+func (l *FileListener) matchesFileType(fileType string) bool {
+	if len(l.FileTypes) == 0 {
+		return true
+	}
+	for _, t := range l.FileTypes {
+		if strings.EqualFold(t, fileType) {
+			return true
+		}
+	}
+	return false
+}
+
+func (bot *Bot) runFileListener(l *FileListener, ev *slack.MessageEvent, file *SharedFile) {
+	defer bot.recoverPanic(messageErrorInfo(ErrorSourceFileListener, ev), nil)
+	l.Handler(bot, ev, file)
+}
@@ -0,0 +1,152 @@
+package slackbot
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func TestBot_UploadFileTo(t *testing.T) {
+	var got slack.FileUploadParameters
+	type fields struct {
+		API MessagingClient
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    slack.FileUploadParameters
+		wantErr bool
+	}{
+		{
+			name: "should upload the file to the channel and thread",
+			fields: fields{
+				API: &mockAPI{
+					uploadFile: func(params slack.FileUploadParameters) (*slack.File, error) {
+						got = params
+						return &slack.File{}, nil
+					},
+				},
+			},
+			want: slack.FileUploadParameters{
+				Filename:        "report.csv",
+				Channels:        []string{"C123"},
+				ThreadTimestamp: "123.456",
+			},
+		},
+		{
+			name: "should return an error if the upload fails",
+			fields: fields{
+				API: &mockAPI{
+					uploadFile: func(params slack.FileUploadParameters) (*slack.File, error) {
+						got = params
+						return nil, errors.New("error")
+					},
+				},
+			},
+			want: slack.FileUploadParameters{
+				Filename:        "report.csv",
+				Channels:        []string{"C123"},
+				ThreadTimestamp: "123.456",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{
+				API: tt.fields.API,
+			}
+			_, err := bot.UploadFileTo("C123", "123.456", "report.csv", strings.NewReader("a,b"))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UploadFileTo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got.Reader = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UploadFileTo() params = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_processFiles(t *testing.T) {
+	var handled []string
+	handler := func(bot *Bot, ev *slack.MessageEvent, file *SharedFile) {
+		handled = append(handled, file.Name)
+	}
+	tests := []struct {
+		name      string
+		listeners []FileListener
+		ev        *slack.MessageEvent
+		want      []string
+	}{
+		{
+			name:      "should call the handler for every file",
+			listeners: []FileListener{{Handler: handler}},
+			ev: &slack.MessageEvent{
+				Msg: slack.Msg{
+					User:  "U123",
+					Files: []slack.File{{Name: "a.csv", Filetype: "csv"}, {Name: "b.pdf", Filetype: "pdf"}},
+				},
+			},
+			want: []string{"a.csv", "b.pdf"},
+		},
+		{
+			name:      "should filter on file type",
+			listeners: []FileListener{{FileTypes: []string{"CSV"}, Handler: handler}},
+			ev: &slack.MessageEvent{
+				Msg: slack.Msg{
+					User:  "U123",
+					Files: []slack.File{{Name: "a.csv", Filetype: "csv"}, {Name: "b.pdf", Filetype: "pdf"}},
+				},
+			},
+			want: []string{"a.csv"},
+		},
+		{
+			name:      "should ignore files shared by the bot",
+			listeners: []FileListener{{Handler: handler}},
+			ev: &slack.MessageEvent{
+				Msg: slack.Msg{
+					User:  "myID",
+					Files: []slack.File{{Name: "a.csv", Filetype: "csv"}},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled = nil
+			bot := &Bot{
+				FileListeners: tt.listeners,
+				userDetails:   &slack.UserDetails{ID: "myID"},
+			}
+			bot.processFiles(tt.ev)
+			if !reflect.DeepEqual(handled, tt.want) {
+				t.Errorf("processFiles() handled = %v, want %v", handled, tt.want)
+			}
+		})
+	}
+}
+
+func TestSharedFile_Download(t *testing.T) {
+	bot := &Bot{
+		API: &mockAPI{
+			getFile: func(url string, w io.Writer) error {
+				_, err := w.Write([]byte(url))
+				return err
+			},
+		},
+	}
+	f := &SharedFile{File: slack.File{URLPrivateDownload: "https://files.slack.com/a.csv"}, bot: bot}
+	var buf bytes.Buffer
+	if err := f.Download(&buf); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if buf.String() != "https://files.slack.com/a.csv" {
+		t.Errorf("Download() got = %v", buf.String())
+	}
+}
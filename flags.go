@@ -0,0 +1,35 @@
+package slackbot
+
+import "github.com/slack-go/slack"
+
+type (
+	// FlagProvider decides if a feature flag is enabled, so listeners and exchanges with a FeatureFlag can be
+	// rolled out gradually or turned off without a deploy. It is usually backed by a feature flag service such
+	// as LaunchDarkly or Unleash.
+	FlagProvider interface {
+		IsEnabled(flag string, ctx FlagContext) bool
+	}
+
+	// FlagProviderFunc is a function that implements FlagProvider.
+	FlagProviderFunc func(flag string, ctx FlagContext) bool
+
+	// FlagContext describes the message a flag is checked for, so providers can target users and channels.
+	FlagContext struct {
+		User    string
+		Channel string
+	}
+)
+
+// IsEnabled calls the function.
+func (f FlagProviderFunc) IsEnabled(flag string, ctx FlagContext) bool {
+	return f(flag, ctx)
+}
+
+// flagEnabled returns true if the flag is empty, the bot doesn't have a FlagProvider, or the provider has
+// enabled the flag for the event's user and channel.
+func (bot *Bot) flagEnabled(flag string, ev *slack.MessageEvent) bool {
+	if flag == "" || bot.FlagProvider == nil {
+		return true
+	}
+	return bot.FlagProvider.IsEnabled(flag, FlagContext{User: ev.User, Channel: ev.Channel})
+}
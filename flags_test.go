@@ -0,0 +1,101 @@
+package slackbot
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_flagEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider FlagProvider
+		flag     string
+		want     bool
+	}{
+		{
+			name: "should be enabled without a provider",
+			flag: "deploys",
+			want: true,
+		},
+		{
+			name:     "should be enabled without a flag",
+			provider: FlagProviderFunc(func(string, FlagContext) bool { return false }),
+			want:     true,
+		},
+		{
+			name:     "should ask the provider with the user and channel",
+			provider: FlagProviderFunc(func(flag string, ctx FlagContext) bool { return flag == "deploys" && ctx.User == "U1" }),
+			flag:     "deploys",
+			want:     true,
+		},
+		{
+			name:     "should be disabled by the provider",
+			provider: FlagProviderFunc(func(string, FlagContext) bool { return false }),
+			flag:     "deploys",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{FlagProvider: tt.provider}
+			if got := bot.flagEnabled(tt.flag, &slack.MessageEvent{Msg: slack.Msg{User: "U1", Channel: "C1"}}); got != tt.want {
+				t.Errorf("flagEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_processMessage_featureFlags(t *testing.T) {
+	tests := []struct {
+		name        string
+		enabled     bool
+		wantRan     []string
+		wantReplies []string
+	}{
+		{
+			name:    "should run the flagged listener when the flag is enabled",
+			enabled: true,
+			wantRan: []string{"new deploy"},
+		},
+		{
+			name:    "should fall through to other listeners when the flag is disabled",
+			wantRan: []string{"old deploy"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran, replies []string
+			listener := func(name string, flag string) Listener {
+				return Listener{
+					Regex:       regexp.MustCompile(`^deploy$`),
+					FeatureFlag: flag,
+					Handler:     func(bot *Bot, ev *slack.MessageEvent) { ran = append(ran, name) },
+				}
+			}
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						replies = append(replies, msgValues(opts...).Get("text"))
+						return s, "ts", nil
+					},
+				},
+				FlagProvider: FlagProviderFunc(func(string, FlagContext) bool { return tt.enabled }),
+				DirectListeners: []Listener{
+					listener("new deploy", "new-deploys"),
+					listener("old deploy", ""),
+				},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			bot.init()
+			bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: "U1", Text: "deploy", Timestamp: "1.1"}})
+			if !reflect.DeepEqual(ran, tt.wantRan) {
+				t.Errorf("ran = %q, want %q", ran, tt.wantRan)
+			}
+			if !reflect.DeepEqual(replies, tt.wantReplies) {
+				t.Errorf("replies = %q, want %q", replies, tt.wantReplies)
+			}
+		})
+	}
+}
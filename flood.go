@@ -0,0 +1,139 @@
+package slackbot
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const floodMutedMessage = "Muting %s for %s, it triggered more than %d listeners in %s"
+
+type (
+	// FloodGuard protects the bot from floods of incoming messages, such as a feedback loop with another
+	// bot. If a single channel or user triggers more than MaxEvents listeners or exchanges in the
+	// TimeInterval, messages from that channel or user are ignored for the MuteDuration and a warning
+	// is sent to the debug channel. Unlike the CircuitBreaker the bot keeps running. It is safe to use
+	// from concurrent handlers.
+	FloodGuard struct {
+		MaxEvents    int
+		TimeInterval time.Duration
+		// MuteDuration defaults to the TimeInterval if it is not set.
+		MuteDuration time.Duration
+
+		mu        sync.Mutex
+		sources   map[string]*floodSource
+		lastPrune time.Time
+		clock     Clock
+	}
+
+	floodSource struct {
+		intervalStart time.Time
+		count         int
+		mutedUntil    time.Time
+	}
+)
+
+// Muted returns true if messages from the channel or user ID are currently being ignored.
+func (fg *FloodGuard) Muted(id string) bool {
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+	for _, key := range []string{"channel " + id, "user " + id} {
+		if s, ok := fg.sources[key]; ok && fg.now().Before(s.mutedUntil) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unmute clears the muted state and the count of matched events for the channel or user ID.
+func (fg *FloodGuard) Unmute(id string) {
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+	delete(fg.sources, "channel "+id)
+	delete(fg.sources, "user "+id)
+}
+
+// allowEvent counts a matched event against its channel and user, and returns false if either
+// of them is muted.
+func (bot *Bot) allowEvent(ev *slack.MessageEvent) bool {
+	fg := bot.FloodGuard
+	if fg == nil {
+		return true
+	}
+	allowed := true
+	for _, key := range []string{"channel " + ev.Channel, "user " + ev.User} {
+		if key == "user " || key == "channel " {
+			continue
+		}
+		muted, justMuted := fg.record(key)
+		if justMuted {
+			bot.LogWarn(fmt.Sprintf(floodMutedMessage, key, fg.muteDuration(), fg.MaxEvents, fg.TimeInterval))
+		}
+		if muted {
+			allowed = false
+		}
+	}
+	return allowed
+}
+
+// record counts an event for the key. It returns whether the key is muted, and whether this event
+// caused it to be muted.
+func (fg *FloodGuard) record(key string) (muted bool, justMuted bool) {
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+	now := fg.now()
+	if fg.sources == nil {
+		fg.sources = make(map[string]*floodSource)
+	}
+	fg.prune(now)
+
+	s, ok := fg.sources[key]
+	if !ok {
+		s = &floodSource{}
+		fg.sources[key] = s
+	}
+	if now.Before(s.mutedUntil) {
+		return true, false
+	}
+	if s.intervalStart.Before(now.Add(-fg.TimeInterval)) {
+		s.intervalStart = now
+		s.count = 0
+	}
+	s.count++
+	if s.count > fg.MaxEvents {
+		s.mutedUntil = now.Add(fg.muteDuration())
+		s.count = 0
+		return true, true
+	}
+	return false, false
+}
+
+// prune removes sources that are not muted and have no events in the current interval, it must be
+// called while holding the lock.
+func (fg *FloodGuard) prune(now time.Time) {
+	if now.Sub(fg.lastPrune) < fg.TimeInterval {
+		return
+	}
+	fg.lastPrune = now
+	for key, s := range fg.sources {
+		if !now.Before(s.mutedUntil) && s.intervalStart.Before(now.Add(-fg.TimeInterval)) {
+			delete(fg.sources, key)
+		}
+	}
+}
+
+func (fg *FloodGuard) muteDuration() time.Duration {
+	if fg.MuteDuration > 0 {
+		return fg.MuteDuration
+	}
+	return fg.TimeInterval
+}
+
+func (fg *FloodGuard) now() time.Time {
+	if fg.clock == nil {
+		return time.Now()
+	}
+	return fg.clock.Now()
+}
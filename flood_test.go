@@ -0,0 +1,129 @@
+package slackbot
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_allowEvent(t *testing.T) {
+	tests := []struct {
+		name        string
+		guard       *FloodGuard
+		events      []*slack.MessageEvent
+		wantHandled int
+		wantWarns   int
+		wantMuted   []string
+	}{
+		{
+			name:        "should allow every event without a flood guard",
+			events:      repeatEvent(slack.Msg{Channel: "C1", User: "U1", Text: "ping"}, 5),
+			wantHandled: 5,
+		},
+		{
+			name:        "should allow events under the limit",
+			guard:       &FloodGuard{MaxEvents: 5, TimeInterval: time.Minute},
+			events:      repeatEvent(slack.Msg{Channel: "C1", User: "U1", Text: "ping"}, 5),
+			wantHandled: 5,
+		},
+		{
+			name:        "should mute the channel and user over the limit and warn once for each",
+			guard:       &FloodGuard{MaxEvents: 2, TimeInterval: time.Minute},
+			events:      repeatEvent(slack.Msg{Channel: "C1", User: "U1", Text: "ping"}, 5),
+			wantHandled: 2,
+			wantWarns:   2,
+			wantMuted:   []string{"C1", "U1"},
+		},
+		{
+			name:  "should mute a user across channels",
+			guard: &FloodGuard{MaxEvents: 2, TimeInterval: time.Minute},
+			events: []*slack.MessageEvent{
+				{Msg: slack.Msg{Channel: "C1", User: "U1", Text: "ping"}},
+				{Msg: slack.Msg{Channel: "C2", User: "U1", Text: "ping"}},
+				{Msg: slack.Msg{Channel: "C3", User: "U1", Text: "ping"}},
+				{Msg: slack.Msg{Channel: "C1", User: "U2", Text: "ping"}},
+			},
+			wantHandled: 3,
+			wantWarns:   1,
+			wantMuted:   []string{"U1"},
+		},
+		{
+			name:        "should not count unmatched messages",
+			guard:       &FloodGuard{MaxEvents: 1, TimeInterval: time.Minute},
+			events:      repeatEvent(slack.Msg{Channel: "C1", User: "U1", Text: "hello"}, 3),
+			wantHandled: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled, warns := 0, 0
+			bot := &Bot{
+				FloodGuard:   tt.guard,
+				DebugChannel: "debug",
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						if strings.Contains(msgValues(opts...).Get("text"), "Muting") {
+							warns++
+						}
+						return s, "ts", nil
+					},
+				},
+				IndirectListeners: []Listener{
+					{
+						Regex: regexp.MustCompile("ping"),
+						Handler: func(bot *Bot, ev *slack.MessageEvent) {
+							handled++
+						},
+					},
+				},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			for _, ev := range tt.events {
+				bot.processMessage(ev)
+			}
+			if handled != tt.wantHandled {
+				t.Errorf("processMessage() handled %d events, want %d", handled, tt.wantHandled)
+			}
+			if warns != tt.wantWarns {
+				t.Errorf("processMessage() sent %d warnings, want %d", warns, tt.wantWarns)
+			}
+			for _, id := range tt.wantMuted {
+				if !tt.guard.Muted(id) {
+					t.Errorf("Muted(%s) = false, want true", id)
+				}
+				tt.guard.Unmute(id)
+				if tt.guard.Muted(id) {
+					t.Errorf("Muted(%s) after Unmute = true, want false", id)
+				}
+			}
+		})
+	}
+}
+
+func TestFloodGuard_record(t *testing.T) {
+	fg := &FloodGuard{MaxEvents: 1, TimeInterval: time.Minute, MuteDuration: 10 * time.Millisecond}
+	if muted, _ := fg.record("user U1"); muted {
+		t.Fatalf("record() muted on the first event")
+	}
+	if muted, justMuted := fg.record("user U1"); !muted || !justMuted {
+		t.Fatalf("record() = %v, %v, want true, true", muted, justMuted)
+	}
+	if muted, justMuted := fg.record("user U1"); !muted || justMuted {
+		t.Fatalf("record() = %v, %v, want true, false", muted, justMuted)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if muted, _ := fg.record("user U1"); muted {
+		t.Errorf("record() still muted after the MuteDuration")
+	}
+}
+
+func repeatEvent(msg slack.Msg, n int) []*slack.MessageEvent {
+	events := make([]*slack.MessageEvent, n)
+	for i := range events {
+		events[i] = &slack.MessageEvent{Msg: msg}
+	}
+	return events
+}
@@ -0,0 +1,288 @@
+package slackbot
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// FieldText is a single line of text.
+	FieldText FieldType = "text"
+	// FieldNumber is a number, it is validated with strconv.ParseFloat.
+	FieldNumber FieldType = "number"
+	// FieldChoice is one of the field's Options.
+	FieldChoice FieldType = "choice"
+
+	formCallbackPrefix  = "slackbot_form:"
+	formOpenActionID    = "slackbot_form_open"
+	formValuesKey       = "slackbot form values"
+	formSkipReply       = "skip"
+	formOpenMessage     = "Click the button to fill in *%s*."
+	formOpenButton      = "Open form"
+	formSubmitButton    = "Submit"
+	formRequiredMessage = "this field is required"
+	formErrorMessage    = "There was a problem submitting the form - %s"
+)
+
+type (
+	// FieldType is the type of a FormField.
+	FieldType string
+
+	// Form collects data from a user. When a message matches the Regex, the form is shown as a modal if the
+	// bot is Interactive, or else the fields are asked for one at a time in a thread like an Exchange. Either
+	// way the Submit function is called with the values once they are all valid, so the same form works in
+	// both deployment modes.
+	Form struct {
+		// Name identifies the form in modal callbacks, it defaults to the Title and must be unique.
+		Name  string
+		Title string
+		Usage string
+		Regex *regexp.Regexp
+
+		// Category groups related commands and Examples show how to use it, see Listener.Category.
+		Category string
+		Examples []string
+
+		Fields []FormField
+
+		// AllowExternal lets users of other organizations use the form, see Listener.AllowExternal.
+		AllowExternal bool
+
+		// Submit is called with the values entered in the form. If an error is returned it is sent to the
+		// user in the form's thread.
+		Submit func(sub *FormSubmission) error
+	}
+
+	// FormField is a field on a Form. Values are required unless the field is Optional. FieldChoice fields
+	// must have Options. Validate can be set to add additional validation, the error it returns is shown
+	// to the user.
+	FormField struct {
+		Name     string
+		Label    string
+		Type     FieldType
+		Options  []string
+		Optional bool
+		Validate func(value string) error
+	}
+
+	// FormSubmission is passed to a Form's Submit function. Values holds the value of each field by Name,
+	// optional fields that were left empty have an empty value. Channel and Thread are where the form was
+	// started.
+	FormSubmission struct {
+		Bot     *Bot
+		Form    *Form
+		User    string
+		Channel string
+		Thread  string
+		Values  map[string]string
+	}
+)
+
+// Reply sends a message to the thread the form was started in.
+func (sub *FormSubmission) Reply(text string) {
+	_, _, _ = sub.Bot.ReplyInThread(sub.Channel, sub.Thread, text)
+}
+
+// validate returns an error if the value is not valid for the field.
+func (f *FormField) validate(value string) error {
+	if value == "" {
+		if f.Optional {
+			return nil
+		}
+		return errors.New(formRequiredMessage)
+	}
+	switch f.Type {
+	case FieldNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return errors.Errorf("%s is not a number", value)
+		}
+	case FieldChoice:
+		if f.option(value) == "" {
+			return errors.Errorf("%s is not one of %s", value, strings.Join(f.Options, ", "))
+		}
+	}
+	if f.Validate != nil {
+		return f.Validate(value)
+	}
+	return nil
+}
+
+// option returns the option matching the value ignoring case, or an empty string.
+func (f *FormField) option(value string) string {
+	for _, o := range f.Options {
+		if strings.EqualFold(o, value) {
+			return o
+		}
+	}
+	return ""
+}
+
+// prompt is the message asking for the field when the form is run as an exchange.
+func (f *FormField) prompt() string {
+	var prompt strings.Builder
+	prompt.WriteString(fmt.Sprintf("*%s*", f.Label))
+	if f.Type == FieldChoice {
+		prompt.WriteString(fmt.Sprintf(" (%s)", strings.Join(f.Options, ", ")))
+	}
+	if f.Optional {
+		prompt.WriteString(fmt.Sprintf("\nThis is optional, reply %s to leave it empty.", formSkipReply))
+	}
+	return prompt.String()
+}
+
+func (form *Form) name() string {
+	if form.Name != "" {
+		return form.Name
+	}
+	return form.Title
+}
+
+// start shows the form to the user who sent the message.
+func (form *Form) start(bot *Bot, ev *slack.MessageEvent) {
+	if bot.Interactive {
+		button := slack.NewButtonBlockElement(formOpenActionID, form.name(), slack.NewTextBlockObject(slack.PlainTextType, formOpenButton, false, false))
+		section := slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf(formOpenMessage, form.Title), false, false), nil, slack.NewAccessory(button))
+		_, _, _ = bot.ReplyWithOptions(ev.Channel, slack.MsgOptionBlocks(section), slack.MsgOptionTS(threadTimestamp(ev)),
+			slack.MsgOptionText(fmt.Sprintf(formOpenMessage, form.Title), false))
+		return
+	}
+	bot.startExchange(ev, form.exchange())
+}
+
+// exchange returns an exchange that asks for each field in turn, then submits the form.
+func (form *Form) exchange() *Exchange {
+	steps := make(map[int]*Step)
+	for _, f := range form.Fields {
+		field := f
+		steps[len(steps)+1] = &Step{Name: field.Name, Message: field.prompt()}
+		steps[len(steps)+1] = &Step{
+			Name: field.Name,
+			MsgHandler: func(ex *Exchange, ev *slack.MessageEvent) (bool, error) {
+				value := strings.TrimSpace(ev.Text)
+				if field.Optional && strings.EqualFold(value, formSkipReply) {
+					value = ""
+				}
+				if err := field.validate(value); err != nil {
+					ex.Reply(fmt.Sprintf("%s, please try again.", err))
+					return true, nil
+				}
+				if field.Type == FieldChoice && value != "" {
+					value = field.option(value)
+				}
+				values := map[string]string{}
+				_ = ex.Store.Get(formValuesKey, &values)
+				values[field.Name] = value
+				return false, ex.Store.Put(formValuesKey, values)
+			},
+		}
+	}
+	steps[len(steps)+1] = &Step{
+		Name: "submit",
+		Handler: func(ex *Exchange) error {
+			values := map[string]string{}
+			_ = ex.Store.Get(formValuesKey, &values)
+			form.submit(&FormSubmission{Bot: ex.Bot, Form: form, User: ex.User, Channel: ex.Channel, Thread: ex.Thread, Values: values})
+			return nil
+		},
+	}
+	return &Exchange{Regex: form.Regex, Usage: form.Usage, Steps: steps}
+}
+
+// modal returns the modal view for the form, the metadata holds the channel and thread it was started in.
+func (form *Form) modal(channel string, thread string) slack.ModalViewRequest {
+	blocks := make([]slack.Block, 0, len(form.Fields))
+	for _, f := range form.Fields {
+		var element slack.BlockElement
+		if f.Type == FieldChoice {
+			options := make([]*slack.OptionBlockObject, len(f.Options))
+			for i, o := range f.Options {
+				options[i] = slack.NewOptionBlockObject(o, slack.NewTextBlockObject(slack.PlainTextType, o, false, false), nil)
+			}
+			element = slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, nil, f.Name, options...)
+		} else {
+			element = slack.NewPlainTextInputBlockElement(nil, f.Name)
+		}
+		input := slack.NewInputBlock(f.Name, slack.NewTextBlockObject(slack.PlainTextType, f.Label, false, false), element)
+		input.Optional = f.Optional
+		blocks = append(blocks, input)
+	}
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		Title:           slack.NewTextBlockObject(slack.PlainTextType, form.Title, false, false),
+		Submit:          slack.NewTextBlockObject(slack.PlainTextType, formSubmitButton, false, false),
+		Blocks:          slack.Blocks{BlockSet: blocks},
+		CallbackID:      formCallbackPrefix + form.name(),
+		PrivateMetadata: channel + " " + thread,
+	}
+}
+
+// submitModal validates the values of a submitted modal. It returns the errors to show in the modal, or
+// submits the form in the background if they are valid.
+func (form *Form) submitModal(bot *Bot, callback *slack.InteractionCallback) *slack.ViewSubmissionResponse {
+	values := make(map[string]string)
+	fieldErrors := make(map[string]string)
+	for _, f := range form.Fields {
+		var value string
+		if callback.View.State != nil {
+			action := callback.View.State.Values[f.Name][f.Name]
+			value = strings.TrimSpace(action.Value)
+			if action.SelectedOption.Value != "" {
+				value = action.SelectedOption.Value
+			}
+		}
+		if err := f.validate(value); err != nil {
+			fieldErrors[f.Name] = err.Error()
+		}
+		values[f.Name] = value
+	}
+	if len(fieldErrors) > 0 {
+		return slack.NewErrorsViewSubmissionResponse(fieldErrors)
+	}
+	var channel, thread string
+	if parts := strings.SplitN(callback.View.PrivateMetadata, " ", 2); len(parts) == 2 {
+		channel, thread = parts[0], parts[1]
+	}
+	go form.submit(&FormSubmission{Bot: bot, Form: form, User: callback.User.ID, Channel: channel, Thread: thread, Values: values})
+	return nil
+}
+
+func (form *Form) submit(sub *FormSubmission) {
+	if form.Submit == nil {
+		return
+	}
+	defer sub.Bot.recoverPanic(ErrorInfo{Source: ErrorSourceForm, Channel: sub.Channel, User: sub.User, Thread: sub.Thread}, nil)
+	if err := form.Submit(sub); err != nil {
+		sub.Reply(fmt.Sprintf(formErrorMessage, err))
+	}
+}
+
+// form returns the bot's form with the name.
+func (bot *Bot) form(name string) *Form {
+	for i := range bot.Forms {
+		if bot.Forms[i].name() == name {
+			return &bot.Forms[i]
+		}
+	}
+	return nil
+}
+
+// openForm opens the form's modal when its button is clicked.
+func (bot *Bot) openForm(callback *slack.InteractionCallback, action *slack.BlockAction) {
+	form := bot.form(action.Value)
+	if form == nil {
+		bot.LogWarn(fmt.Sprintf("no form named %s", action.Value))
+		return
+	}
+	thread := callback.Message.ThreadTimestamp
+	if thread == "" {
+		thread = callback.Message.Timestamp
+	}
+	if _, err := bot.OpenModal(callback.TriggerID, form.modal(callback.Channel.ID, thread)); err != nil {
+		bot.LogError(fmt.Sprintf("error opening form %s - %s", form.name(), err))
+	}
+}
@@ -0,0 +1,178 @@
+package slackbot
+
+import (
+	"reflect"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func testForm(submitted chan map[string]string) Form {
+	return Form{
+		Title: "New Ticket",
+		Usage: "new ticket",
+		Regex: regexp.MustCompile(`^new ticket$`),
+		Fields: []FormField{
+			{Name: "title", Label: "Title", Type: FieldText},
+			{Name: "priority", Label: "Priority", Type: FieldChoice, Options: []string{"Low", "High"}},
+			{Name: "points", Label: "Points", Type: FieldNumber, Optional: true},
+		},
+		Submit: func(sub *FormSubmission) error {
+			submitted <- sub.Values
+			if sub.Values["title"] == "fail" {
+				return errors.New("tracker is down")
+			}
+			return nil
+		},
+	}
+}
+
+func TestForm_exchange(t *testing.T) {
+	tests := []struct {
+		name        string
+		replies     []string
+		wantValues  map[string]string
+		wantReplies []string
+	}{
+		{
+			name:       "should ask for each field and submit the values",
+			replies:    []string{"Broken build", "high", "3"},
+			wantValues: map[string]string{"title": "Broken build", "priority": "High", "points": "3"},
+			wantReplies: []string{
+				"*Title*",
+				"*Priority* (Low, High)",
+				"*Points*\nThis is optional, reply skip to leave it empty.",
+			},
+		},
+		{
+			name:       "should ask again for invalid values and allow optional fields to be skipped",
+			replies:    []string{"Broken build", "urgent", "low", "lots", "skip"},
+			wantValues: map[string]string{"title": "Broken build", "priority": "Low", "points": ""},
+			wantReplies: []string{
+				"*Title*",
+				"*Priority* (Low, High)",
+				"urgent is not one of Low, High, please try again.",
+				"*Points*\nThis is optional, reply skip to leave it empty.",
+				"lots is not a number, please try again.",
+			},
+		},
+		{
+			name:       "should reply with the submit error",
+			replies:    []string{"fail", "low", "skip"},
+			wantValues: map[string]string{"title": "fail", "priority": "Low", "points": ""},
+			wantReplies: []string{
+				"*Title*",
+				"*Priority* (Low, High)",
+				"*Points*\nThis is optional, reply skip to leave it empty.",
+				"There was a problem submitting the form - tracker is down",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var replies []string
+			submitted := make(chan map[string]string, 1)
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						replies = append(replies, msgValues(opts...).Get("text"))
+						return s, "ts", nil
+					},
+				},
+				Forms:           []Form{testForm(submitted)},
+				activeExchanges: map[string]*Exchange{},
+				userDetails:     &slack.UserDetails{ID: "bot"},
+			}
+			bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: "U1", Text: "new ticket", Timestamp: "1.1"}})
+			for _, r := range tt.replies {
+				bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: "U1", Text: r, ThreadTimestamp: "1.1"}})
+			}
+			select {
+			case values := <-submitted:
+				if !reflect.DeepEqual(values, tt.wantValues) {
+					t.Errorf("Submit() values = %v, want %v", values, tt.wantValues)
+				}
+			default:
+				t.Errorf("Submit() was not called")
+			}
+			if !reflect.DeepEqual(replies, tt.wantReplies) {
+				t.Errorf("replies = %q, want %q", replies, tt.wantReplies)
+			}
+		})
+	}
+}
+
+func TestForm_modal(t *testing.T) {
+	var mu sync.Mutex
+	var opened *slack.ModalViewRequest
+	var buttons int
+	submitted := make(chan map[string]string, 1)
+	bot := &Bot{
+		Interactive: true,
+		API: &mockAPI{
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				if msgValues(opts...).Get("blocks") != "" {
+					buttons++
+				}
+				return s, "ts", nil
+			},
+			openView: func(triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				opened = &view
+				return &slack.ViewResponse{}, nil
+			},
+		},
+		Forms:       []Form{testForm(submitted)},
+		userDetails: &slack.UserDetails{ID: "bot"},
+	}
+	bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: "U1", Text: "new ticket", Timestamp: "1.1"}})
+	if buttons != 1 {
+		t.Fatalf("processMessage() sent %d form buttons, want 1", buttons)
+	}
+
+	bot.HandleInteraction(&slack.InteractionCallback{
+		Type:      slack.InteractionTypeBlockActions,
+		TriggerID: "T1",
+		Channel:   slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "D1"}}},
+		Message:   slack.Message{Msg: slack.Msg{Timestamp: "1.2", ThreadTimestamp: "1.1"}},
+		ActionCallback: slack.ActionCallbacks{BlockActions: []*slack.BlockAction{
+			{ActionID: formOpenActionID, Value: "New Ticket"},
+		}},
+	})
+	if opened == nil {
+		t.Fatalf("HandleInteraction() did not open the modal")
+	}
+	if opened.CallbackID != "slackbot_form:New Ticket" || opened.PrivateMetadata != "D1 1.1" || len(opened.Blocks.BlockSet) != 3 {
+		t.Errorf("HandleInteraction() opened %+v", opened)
+	}
+
+	submit := func(title string, priority string, points string) *slack.ViewSubmissionResponse {
+		return bot.HandleInteraction(&slack.InteractionCallback{
+			Type: slack.InteractionTypeViewSubmission,
+			User: slack.User{ID: "U1"},
+			View: slack.View{
+				CallbackID:      opened.CallbackID,
+				PrivateMetadata: opened.PrivateMetadata,
+				State: &slack.ViewState{Values: map[string]map[string]slack.BlockAction{
+					"title":    {"title": {Value: title}},
+					"priority": {"priority": {SelectedOption: slack.OptionBlockObject{Value: priority}}},
+					"points":   {"points": {Value: points}},
+				}},
+			},
+		})
+	}
+	resp := submit("", "High", "many")
+	if resp == nil || !reflect.DeepEqual(resp.Errors, map[string]string{"title": "this field is required", "points": "many is not a number"}) {
+		t.Errorf("HandleInteraction() response = %+v, want validation errors", resp)
+	}
+	if resp := submit("Broken build", "High", ""); resp != nil {
+		t.Errorf("HandleInteraction() response = %+v, want nil", resp)
+	}
+	if values := <-submitted; !reflect.DeepEqual(values, map[string]string{"title": "Broken build", "priority": "High", "points": ""}) {
+		t.Errorf("Submit() values = %v", values)
+	}
+}
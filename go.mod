@@ -3,8 +3,9 @@ module github.com/daftn/slackbot
 go 1.13
 
 require (
+	github.com/gorilla/websocket v1.4.2
 	github.com/pkg/errors v0.9.1
-	github.com/robfig/cron v1.2.0
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/slack-go/slack v0.8.0
 	github.com/ulule/deepcopier v0.0.0-20200117111125-792cfb847af8
 )
@@ -0,0 +1,184 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	teamUsersTTL      = 10 * time.Minute
+	sharedChannelsTTL = 10 * time.Minute
+)
+
+type (
+	// Workspace identifies the workspace an event came from, and the Enterprise Grid organization it belongs to
+	// if there is one.
+	Workspace struct {
+		EnterpriseID string
+		TeamID       string
+	}
+
+	// ChannelSharing describes who a channel is shared with. OrgShared channels are shared between the
+	// workspaces of an Enterprise Grid organization, ExternallyShared channels are Slack Connect channels shared
+	// with other organizations.
+	ChannelSharing struct {
+		Shared           bool
+		OrgShared        bool
+		ExternallyShared bool
+	}
+
+	// eventEnvelope holds the fields of an Events API request that slackevents doesn't parse.
+	eventEnvelope struct {
+		TeamID       string `json:"team_id"`
+		EnterpriseID string `json:"enterprise_id"`
+	}
+)
+
+// IsEnterprise returns true if the workspace belongs to an Enterprise Grid organization.
+func (w Workspace) IsEnterprise() bool {
+	return w.EnterpriseID != ""
+}
+
+// Workspace returns the workspace the message was sent from. Messages without a team are from the bot's own
+// workspace.
+func (bot *Bot) Workspace(ev *slack.MessageEvent) Workspace {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	team := ev.Team
+	if team == "" || team == bot.workspace.TeamID {
+		return bot.workspace
+	}
+	return Workspace{EnterpriseID: bot.enterprises[team], TeamID: team}
+}
+
+// Workspace returns the workspace the message that triggered the listener was sent from.
+func (ctx *MessageContext) Workspace() Workspace {
+	return ctx.Bot.Workspace(ctx.Event)
+}
+
+// noteWorkspace records the organization a team belongs to, from the events the bot receives.
+func (bot *Bot) noteWorkspace(w Workspace) {
+	if w.TeamID == "" || w.EnterpriseID == "" {
+		return
+	}
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.enterprises == nil {
+		bot.enterprises = make(map[string]string)
+	}
+	bot.enterprises[w.TeamID] = w.EnterpriseID
+}
+
+// noteEnvelope records the workspace of an Events API request.
+func (bot *Bot) noteEnvelope(body []byte) {
+	var env eventEnvelope
+	if err := json.Unmarshal(body, &env); err == nil {
+		bot.noteWorkspace(Workspace{EnterpriseID: env.EnterpriseID, TeamID: env.TeamID})
+	}
+}
+
+// isEnterpriseTeam returns true if the team belongs to an Enterprise Grid organization. Teams the bot hasn't
+// seen an organization for are assumed to be in the bot's own organization, if it has one.
+func (bot *Bot) isEnterpriseTeam(team string) bool {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	return bot.workspace.IsEnterprise() || bot.enterprises[team] != ""
+}
+
+// UserInTeam finds a user of the workspace by ID, name, real name or display name, and returns an error if
+// several users share the real or display name. In an Enterprise Grid organization names are only unique
+// within a workspace, so users of other workspaces are not matched. The users of each workspace are cached
+// for ten minutes.
+func (bot *Bot) UserInTeam(team string, identifier string) (slack.User, error) {
+	i := userIdentifier(identifier)
+	c := bot.teamUserCache(team)
+	if u, ok := c.get(i); ok {
+		return u.(slack.User), nil
+	}
+	var users []slack.User
+	err := bot.withRetry(func() (err error) {
+		users, err = bot.api().GetUsersContext(bot.context())
+		return err
+	})
+	if err != nil {
+		return slack.User{}, errors.Wrapf(err, "unable to find user %s", identifier)
+	}
+	var members []slack.User
+	for _, u := range users {
+		if inTeam(u, team) {
+			members = append(members, u)
+		}
+	}
+	u, ok, err := matchUser(members, i)
+	if err != nil {
+		return slack.User{}, errors.Wrapf(err, "unable to find user %s in team %s", identifier, team)
+	}
+	if !ok {
+		return slack.User{}, errors.Errorf("unable to find user %s in team %s", identifier, team)
+	}
+	c.set(i, u)
+	return u, nil
+}
+
+// inTeam returns true if the user is a member of the workspace.
+func inTeam(u slack.User, team string) bool {
+	if u.TeamID == team {
+		return true
+	}
+	for _, t := range u.Enterprise.Teams {
+		if t == team {
+			return true
+		}
+	}
+	return false
+}
+
+func (bot *Bot) teamUserCache(team string) *cache {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.teamUsers == nil {
+		bot.teamUsers = make(map[string]*cache)
+	}
+	c, ok := bot.teamUsers[team]
+	if !ok {
+		c = newCache(teamUsersTTL, bot.clock())
+		bot.teamUsers[team] = c
+	}
+	return c
+}
+
+// ChannelSharing returns who the channel is shared with, so listeners can behave differently in channels
+// shared with other workspaces or organizations. It is cached for ten minutes.
+func (bot *Bot) ChannelSharing(channel string) (ChannelSharing, error) {
+	c := bot.sharedChannelCache()
+	if s, ok := c.get(channel); ok {
+		return s.(ChannelSharing), nil
+	}
+	var info *slack.Channel
+	err := bot.withRetry(func() (err error) {
+		info, err = bot.api().GetConversationInfoContext(bot.context(), channel, false)
+		return err
+	})
+	if err != nil {
+		return ChannelSharing{}, errors.Wrapf(err, "unable to get channel %s", channel)
+	}
+	s := ChannelSharing{
+		Shared:           info.IsShared || info.IsOrgShared || info.IsExtShared,
+		OrgShared:        info.IsOrgShared,
+		ExternallyShared: info.IsExtShared,
+	}
+	c.set(channel, s)
+	return s, nil
+}
+
+func (bot *Bot) sharedChannelCache() *cache {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.sharedChannels == nil {
+		bot.sharedChannels = newCache(sharedChannelsTTL, bot.clock())
+	}
+	return bot.sharedChannels
+}
@@ -0,0 +1,175 @@
+package slackbot
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func TestBot_Workspace(t *testing.T) {
+	tests := []struct {
+		name     string
+		envelope string
+		team     string
+		want     Workspace
+	}{
+		{
+			name: "should use the bot's workspace for messages without a team",
+			want: Workspace{EnterpriseID: "E1", TeamID: "T1"},
+		},
+		{
+			name: "should use the bot's workspace for messages from its team",
+			team: "T1",
+			want: Workspace{EnterpriseID: "E1", TeamID: "T1"},
+		},
+		{
+			name:     "should use the organization from the team's events",
+			envelope: `{"type":"event_callback","team_id":"T2","enterprise_id":"E1"}`,
+			team:     "T2",
+			want:     Workspace{EnterpriseID: "E1", TeamID: "T2"},
+		},
+		{
+			name: "should return the team of messages from an unknown organization",
+			team: "T3",
+			want: Workspace{TeamID: "T3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{workspace: Workspace{EnterpriseID: "E1", TeamID: "T1"}}
+			if tt.envelope != "" {
+				bot.noteEnvelope([]byte(tt.envelope))
+			}
+			if got := bot.Workspace(&slack.MessageEvent{Msg: slack.Msg{Team: tt.team}}); got != tt.want {
+				t.Errorf("Workspace() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_UserInTeam(t *testing.T) {
+	users := []slack.User{
+		{ID: "W1", Name: "sam", TeamID: "T1"},
+		{ID: "W2", Name: "sam", TeamID: "T2"},
+		{ID: "W3", Name: "alex", TeamID: "T1", Enterprise: slack.EnterpriseUser{Teams: []string{"T1", "T2"}}},
+		{ID: "W4", Name: "jo.kim", RealName: "Jo Kim", TeamID: "T1"},
+		{ID: "W5", Name: "jo.kim2", RealName: "Jo Kim", TeamID: "T1"},
+		{ID: "W6", Name: "jo.kim", RealName: "Jo Kim", TeamID: "T2"},
+	}
+	tests := []struct {
+		name       string
+		team       string
+		identifier string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "should find the user in the team",
+			team:       "T2",
+			identifier: "@sam",
+			want:       "W2",
+		},
+		{
+			name:       "should find users who belong to several teams",
+			team:       "T2",
+			identifier: "alex",
+			want:       "W3",
+		},
+		{
+			name:       "should not find a real name shared by users of the team",
+			team:       "T1",
+			identifier: "Jo Kim",
+			wantErr:    true,
+		},
+		{
+			name:       "should find a real name only one user of the team has",
+			team:       "T2",
+			identifier: "Jo Kim",
+			want:       "W6",
+		},
+		{
+			name:       "should not find users of other teams",
+			team:       "T3",
+			identifier: "sam",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			bot := &Bot{API: &mockAPI{getUsers: func() ([]slack.User, error) {
+				calls++
+				return users, nil
+			}}}
+			got, err := bot.UserInTeam(tt.team, tt.identifier)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UserInTeam() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.ID != tt.want {
+				t.Errorf("UserInTeam() = %v, want %v", got.ID, tt.want)
+			}
+			if !tt.wantErr {
+				_, _ = bot.UserInTeam(tt.team, tt.identifier)
+				if calls != 1 {
+					t.Errorf("UserInTeam() listed the users %d times, want them cached", calls)
+				}
+			}
+		})
+	}
+}
+
+func TestBot_ChannelSharing(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel func() *slack.Channel
+		err     error
+		want    ChannelSharing
+		wantErr bool
+	}{
+		{
+			name:    "should return an unshared channel",
+			channel: func() *slack.Channel { return &slack.Channel{} },
+		},
+		{
+			name: "should return a channel shared in the organization",
+			channel: func() *slack.Channel {
+				c := &slack.Channel{}
+				c.IsShared, c.IsOrgShared = true, true
+				return c
+			},
+			want: ChannelSharing{Shared: true, OrgShared: true},
+		},
+		{
+			name: "should return a Slack Connect channel",
+			channel: func() *slack.Channel {
+				c := &slack.Channel{}
+				c.IsExtShared = true
+				return c
+			},
+			want: ChannelSharing{Shared: true, ExternallyShared: true},
+		},
+		{
+			name:    "should error if the channel can't be found",
+			err:     errors.New("channel_not_found"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{API: &mockAPI{getConversationInfo: func(channel string, includeLocale bool) (*slack.Channel, error) {
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return tt.channel(), nil
+			}}}
+			got, err := bot.ChannelSharing("C1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ChannelSharing() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ChannelSharing() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
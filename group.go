@@ -0,0 +1,123 @@
+package slackbot
+
+import (
+	"strconv"
+	"sync"
+)
+
+const groupStorePrefix = "bot:"
+
+type (
+	// BotGroup runs several bots, for example with different tokens or personas, in one process. Bots without a
+	// Store use a view of the group's Store with keys namespaced to the bot's position in Bots, so keep the order
+	// of Bots the same when the Store is persistent. State the bots share is kept in the group's Store itself,
+	// see Bot.GroupStore. If Workers is set the bots share a pool of that many workers for processing messages,
+	// and Stats reports on the pool and every bot. A CircuitBreaker tripping stops only its own bot instead of
+	// exiting the process.
+	BotGroup struct {
+		Bots    []*Bot
+		Store   Store
+		Workers int
+
+		workers chan struct{}
+	}
+
+	// GroupStats is a snapshot of a BotGroup returned by Stats.
+	GroupStats struct {
+		// Workers is the size of the shared worker pool, and BusyWorkers how many are processing a message.
+		Workers     int `json:"workers"`
+		BusyWorkers int `json:"busy_workers"`
+
+		// Bots are the stats of each bot, in the order of the group's Bots.
+		Bots []BotStats `json:"bots"`
+	}
+)
+
+// Start starts all of the bots and blocks until they have all stopped. If a bot fails to start or stops with
+// an error the rest of the bots are stopped and the first error is returned.
+func (g *BotGroup) Start() error {
+	g.share()
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	for _, b := range g.Bots {
+		wg.Add(1)
+		go func(bot *Bot) {
+			defer wg.Done()
+			if err := bot.Start(); err != nil {
+				once.Do(func() {
+					firstErr = err
+					g.Stop()
+				})
+			}
+		}(b)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// share sets the group's Store and worker pool on the bots, and makes a tripped CircuitBreaker stop its bot.
+// Each bot gets its own view of the Store, so the keys the bots use for their queues and state don't collide.
+func (g *BotGroup) share() {
+	if g.Workers > 0 && g.workers == nil {
+		g.workers = make(chan struct{}, g.Workers)
+	}
+	for i, b := range g.Bots {
+		bot := b
+		if bot.Store == nil && g.Store != nil {
+			bot.Store = prefixStore{store: g.Store, prefix: groupStorePrefix + strconv.Itoa(i) + ":"}
+		}
+		bot.workers = g.workers
+		bot.group = g
+		if bot.OnFatal == nil {
+			bot.OnFatal = func(error) { bot.Stop() }
+		}
+	}
+}
+
+// Stop stops all of the bots in the group.
+func (g *BotGroup) Stop() {
+	for _, bot := range g.Bots {
+		bot.Stop()
+	}
+}
+
+// Stats returns the stats of the group's worker pool and of each of its bots.
+func (g *BotGroup) Stats() GroupStats {
+	stats := GroupStats{Workers: g.Workers, BusyWorkers: len(g.workers)}
+	for _, bot := range g.Bots {
+		stats.Bots = append(stats.Bots, bot.Stats())
+	}
+	return stats
+}
+
+// GroupStore returns the Store of the bot's BotGroup without the bot's namespace, for state shared by every bot
+// in the group. The bot's own Store is returned if it isn't in a group or the group has no Store.
+func (bot *Bot) GroupStore() Store {
+	if g := bot.group; g != nil && g.Store != nil {
+		return g.Store
+	}
+	return bot.store()
+}
+
+// prefixStore is a view of a Store with every key prefixed, for a bot sharing its group's Store.
+type prefixStore struct {
+	store  Store
+	prefix string
+}
+
+// Put adds the value to the store under the prefixed key.
+func (s prefixStore) Put(key string, value interface{}) error {
+	return s.store.Put(s.prefix+key, value)
+}
+
+// Get retrieves a value by its prefixed key from the store.
+func (s prefixStore) Get(key string, value interface{}) error {
+	return s.store.Get(s.prefix+key, value)
+}
+
+// Delete removes a value by its prefixed key from the store.
+func (s prefixStore) Delete(key string) error {
+	return s.store.Delete(s.prefix + key)
+}
@@ -0,0 +1,203 @@
+package slackbot
+
+import (
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBotGroup_Start(t *testing.T) {
+	tests := []struct {
+		name           string
+		failing        bool
+		wantErr        bool
+		wantDisconnect int
+	}{
+		{
+			name:           "should run the bots until the group is stopped",
+			wantDisconnect: 2,
+		},
+		{
+			name:           "should stop the other bots if one fails to start",
+			failing:        true,
+			wantErr:        true,
+			wantDisconnect: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retry := slackConnectionRetry
+			slackConnectionRetry = 1
+			defer func() { slackConnectionRetry = retry }()
+
+			var mu sync.Mutex
+			disconnected := 0
+			newBot := func(connects bool) *Bot {
+				return &Bot{
+					API: &mockAPI{
+						getInfo: func() *slack.Info {
+							if !connects {
+								return nil
+							}
+							return &slack.Info{User: &slack.UserDetails{ID: "bot"}}
+						},
+						manageConnection: func() {},
+						postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+							return s, "ts", nil
+						},
+						disconnect: func() error {
+							mu.Lock()
+							defer mu.Unlock()
+							disconnected++
+							return nil
+						},
+					},
+				}
+			}
+			store := SimpleStore{}
+			g := &BotGroup{
+				Bots:    []*Bot{newBot(true), newBot(!tt.failing)},
+				Store:   store,
+				Workers: 2,
+			}
+			done := make(chan error)
+			go func() {
+				done <- g.Start()
+			}()
+			if !tt.failing {
+				for _, bot := range g.Bots {
+					b := bot
+					waitFor(t, func() bool {
+						b.mu.Lock()
+						defer b.mu.Unlock()
+						return b.rtmConnected
+					})
+				}
+				g.Stop()
+			}
+			err := <-done
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Start() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if disconnected != tt.wantDisconnect {
+				t.Errorf("Start() disconnected %d bots, want %d", disconnected, tt.wantDisconnect)
+			}
+			for _, bot := range g.Bots {
+				if s, ok := bot.Store.(prefixStore); !ok || s.store == nil {
+					t.Errorf("Start() did not share the group's Store")
+				}
+				if bot.workers == nil || bot.workers != g.Bots[0].workers {
+					t.Errorf("Start() did not share the worker pool")
+				}
+			}
+		})
+	}
+}
+
+func TestBotGroup_share(t *testing.T) {
+	stopped := false
+	bot := &Bot{
+		CircuitBreaker: &CircuitBreaker{MaxMessages: 1, TimeInterval: time.Minute},
+		API: &mockAPI{
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				return s, "ts", nil
+			},
+		},
+	}
+	g := &BotGroup{Bots: []*Bot{bot}}
+	g.share()
+	for i := 0; i < 3; i++ {
+		bot.checkCircuitBreaker("C1")
+	}
+	select {
+	case <-bot.stopChan():
+		stopped = true
+	default:
+	}
+	if !stopped {
+		t.Errorf("checkCircuitBreaker() did not stop the bot")
+	}
+}
+
+func TestBotGroup_share_store(t *testing.T) {
+	store := NewMemoryStore(nil)
+	own := SimpleStore{}
+	g := &BotGroup{
+		Bots:  []*Bot{{}, {}, {Store: own}},
+		Store: store,
+	}
+	g.share()
+	for i, bot := range g.Bots {
+		if err := bot.Store.Put("queue", i); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+	for i, bot := range g.Bots {
+		var got int
+		if err := bot.Store.Get("queue", &got); err != nil || got != i {
+			t.Errorf("bot %d Get() = %d, %v, want %d", i, got, err, i)
+		}
+	}
+	if _, ok := own["queue"]; !ok {
+		t.Errorf("share() replaced the bot's own Store")
+	}
+	var got int
+	if err := store.Get("queue", &got); err == nil {
+		t.Errorf("share() wrote to the group's Store without a prefix")
+	}
+}
+
+func TestBot_dispatch_busyWorkers(t *testing.T) {
+	workers := make(chan struct{}, 1)
+	workers <- struct{}{}
+	handled := make(chan struct{}, 1)
+	bot := &Bot{
+		API:         &mockAPI{},
+		workers:     workers,
+		userDetails: &slack.UserDetails{ID: "bot"},
+		IndirectListeners: []Listener{{
+			Regex:   regexp.MustCompile(`.*`),
+			Handler: func(*Bot, *slack.MessageEvent) { handled <- struct{}{} },
+		}},
+	}
+	done := make(chan struct{})
+	go func() {
+		bot.dispatch(&slack.MessageEvent{Msg: slack.Msg{Channel: "C1", User: "U1", Text: "hi"}})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("dispatch() blocked while every worker was busy")
+	}
+	bot.Stop()
+	time.Sleep(10 * time.Millisecond)
+	<-workers
+	time.Sleep(10 * time.Millisecond)
+	select {
+	case <-handled:
+		t.Error("dispatch() processed the message after the bot stopped")
+	default:
+	}
+}
+
+func TestBotGroup_Stats(t *testing.T) {
+	store := NewMemoryStore(nil)
+	g := &BotGroup{Bots: []*Bot{{}, {}}, Store: store, Workers: 3}
+	g.share()
+	if err := g.Bots[0].GroupStore().Put("shared", "value"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	var got string
+	if err := g.Bots[1].GroupStore().Get("shared", &got); err != nil || got != "value" {
+		t.Errorf("GroupStore() Get() = %q, %v, want the value put by the other bot", got, err)
+	}
+	g.Bots[0].workers <- struct{}{}
+	stats := g.Stats()
+	if stats.Workers != 3 || stats.BusyWorkers != 1 || len(stats.Bots) != 2 {
+		t.Errorf("Stats() = %+v, want 3 workers with 1 busy and 2 bots", stats)
+	}
+}
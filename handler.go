@@ -0,0 +1,142 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const handlerTimeoutMessage = "This is taking longer than expected, it timed out after %s."
+
+// MessageContext is passed to a Listener's ContextHandler. It is a context.Context that will be
+// cancelled when the listener's Timeout is exceeded, along with the bot and the message event
+// that triggered the listener. Thread returns the other messages in the thread the event was sent in,
+// and IsDryRun returns true if the command was sent with --dry-run. ResponseURL is set when the listener
+// was triggered by a slash command, see Respond.
+type MessageContext struct {
+	context.Context
+	Bot         *Bot
+	Event       *slack.MessageEvent
+	ResponseURL string
+
+	dryRun bool
+
+	threadOnce sync.Once
+	thread     []slack.Message
+	threadErr  error
+
+	contentOnce sync.Once
+	content     MessageContent
+}
+
+// matches returns true if the text matches the listener's Regex or one of its Aliases.
+func (l *Listener) matches(bot *Bot, text string) bool {
+	if l.Regex != nil && bot.matchString(l.Regex, text) {
+		return true
+	}
+	for _, a := range l.Aliases {
+		if bot.matchString(a, text) {
+			return true
+		}
+	}
+	return false
+}
+
+// helpText returns the listener's Usage followed by its Aliases for SendHelp, and a note if it is Deprecated.
+// Anchors and flags are trimmed from the aliases so simple patterns read as the words they match.
+func (l *Listener) helpText() string {
+	if len(l.Aliases) == 0 {
+		return l.Usage + deprecationHelpNote(l)
+	}
+	aliases := make([]string, len(l.Aliases))
+	for i, a := range l.Aliases {
+		alias := strings.TrimPrefix(a.String(), "^")
+		alias = strings.TrimPrefix(strings.TrimPrefix(alias, "(?i)"), "^")
+		aliases[i] = strings.TrimSuffix(alias, "$")
+	}
+	return fmt.Sprintf("%s (aliases: %s)%s", l.Usage, strings.Join(aliases, ", "), deprecationHelpNote(l))
+}
+
+// runListener calls the listener's handler for the message event, enforcing the listener's Timeout. If dryRun
+// is set the command was sent with --dry-run, see MessageContext.IsDryRun.
+func (bot *Bot) runListener(l *Listener, ev *slack.MessageEvent, dryRun bool) {
+	if l.Handler == nil && l.ContextHandler == nil {
+		return
+	}
+	if dryRun && l.ContextHandler == nil {
+		_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), dryRunUnsupportedMessage)
+		return
+	}
+	if bot.readOnlyBlocked(l.Destructive, ev) || bot.deprecatedDisabled(l, ev) {
+		return
+	}
+	if l.Destructive && !dryRun {
+		bot.confirm(l, ev)
+		return
+	}
+	bot.execListener(l, ev, dryRun)
+}
+
+// execListener calls the listener's handler, once it has been confirmed if it is Destructive.
+func (bot *Bot) execListener(l *Listener, ev *slack.MessageEvent, dryRun bool) {
+	if l.MaxConcurrent > 0 {
+		release, ok := bot.acquireListener(l, ev)
+		if !ok {
+			return
+		}
+		defer release()
+	}
+	runWithTimeout(bot.context(), bot.clock(), l.Timeout, func(ctx context.Context) {
+		defer bot.recoverPanic(messageErrorInfo(ErrorSourceListener, ev), nil)
+		if l.ContextHandler != nil {
+			l.ContextHandler(&MessageContext{Context: ctx, Bot: bot, Event: ev, ResponseURL: bot.responseURL(ev), dryRun: dryRun})
+			return
+		}
+		l.Handler(bot, ev)
+	}, func() {
+		_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), fmt.Sprintf(handlerTimeoutMessage, l.Timeout))
+	})
+	if l.Deprecated {
+		bot.sendDeprecationNotice(l, ev)
+	}
+}
+
+// runWithTimeout calls fn with a context that is cancelled when the clock reaches the timeout, a timeout of 0
+// means there is no timeout. If the timeout is exceeded before fn returns onTimeout will be called.
+// runWithTimeout always waits for fn to return.
+func runWithTimeout(parent context.Context, clock Clock, timeout time.Duration, fn func(ctx context.Context), onTimeout func()) {
+	if timeout <= 0 {
+		fn(parent)
+		return
+	}
+	ctx, cancel := withClockTimeout(parent, clock, timeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(ctx)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded && onTimeout != nil {
+			onTimeout()
+		}
+		<-done
+	}
+}
+
+// threadTimestamp returns the timestamp of the thread the event belongs to, or the event's
+// timestamp if it is not in a thread so that replies will start a new thread.
+func threadTimestamp(ev *slack.MessageEvent) string {
+	if ev.ThreadTimestamp != "" {
+		return ev.ThreadTimestamp
+	}
+	return ev.Timestamp
+}
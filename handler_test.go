@@ -0,0 +1,250 @@
+package slackbot
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_runListener(t *testing.T) {
+	handlerCalled := false
+	timeoutSent := false
+	type args struct {
+		l *Listener
+	}
+	tests := []struct {
+		name          string
+		args          args
+		handlerCalled bool
+		timeoutSent   bool
+	}{
+		{
+			name: "should call the handler",
+			args: args{
+				l: &Listener{
+					Handler: func(bot *Bot, ev *slack.MessageEvent) {
+						handlerCalled = true
+					},
+				},
+			},
+			handlerCalled: true,
+		},
+		{
+			name: "should call the context handler",
+			args: args{
+				l: &Listener{
+					ContextHandler: func(ctx *MessageContext) {
+						handlerCalled = ctx.Event.Text == "text"
+					},
+					Handler: func(bot *Bot, ev *slack.MessageEvent) {
+						t.Errorf("handler should not be called")
+					},
+				},
+			},
+			handlerCalled: true,
+		},
+		{
+			name: "should send a message and cancel the context when the timeout is exceeded",
+			args: args{
+				l: &Listener{
+					Timeout: time.Millisecond,
+					ContextHandler: func(ctx *MessageContext) {
+						<-ctx.Done()
+						handlerCalled = true
+					},
+				},
+			},
+			handlerCalled: true,
+			timeoutSent:   true,
+		},
+		{
+			name: "should not send a timeout message if the handler finishes in time",
+			args: args{
+				l: &Listener{
+					Timeout: time.Minute,
+					Handler: func(bot *Bot, ev *slack.MessageEvent) {
+						handlerCalled = true
+					},
+				},
+			},
+			handlerCalled: true,
+		},
+		{
+			name: "should do nothing without a handler",
+			args: args{
+				l: &Listener{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlerCalled = false
+			timeoutSent = false
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						timeoutSent = true
+						return "", "", nil
+					},
+				},
+			}
+			bot.runListener(tt.args.l, &slack.MessageEvent{Msg: slack.Msg{Text: "text", Channel: "C123", Timestamp: "1.2"}}, false)
+			if handlerCalled != tt.handlerCalled {
+				t.Errorf("handler called wrong, got = %v, want %v", handlerCalled, tt.handlerCalled)
+			}
+			if timeoutSent != tt.timeoutSent {
+				t.Errorf("timeout message sent wrong, got = %v, want %v", timeoutSent, tt.timeoutSent)
+			}
+		})
+	}
+}
+
+func TestBot_runListener_context(t *testing.T) {
+	type key struct{}
+	cancelled, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "parent"))
+	cancel()
+	tests := []struct {
+		name          string
+		parent        context.Context
+		botReply      bool
+		wantCancelled bool
+	}{
+		{
+			name:   "should derive the handler and reply contexts from the bot's context",
+			parent: context.WithValue(context.Background(), key{}, "parent"),
+		},
+		{
+			name:          "should cancel the handler and reply contexts with the bot's context",
+			parent:        cancelled,
+			wantCancelled: true,
+		},
+		{
+			name:     "should send the bot's replies with the handler's context",
+			parent:   context.WithValue(context.Background(), key{}, "parent"),
+			botReply: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var replyCtx context.Context
+			var replyErr error
+			bot := &Bot{
+				Context: tt.parent,
+				API: &mockAPI{
+					postMessageContext: func(ctx context.Context, s string, opts ...slack.MsgOption) (string, string, error) {
+						replyCtx, replyErr = ctx, ctx.Err()
+						return s, "1.3", nil
+					},
+				},
+			}
+			l := &Listener{
+				Timeout: time.Minute,
+				ContextHandler: func(ctx *MessageContext) {
+					if tt.botReply {
+						_, _, _ = ctx.Bot.ReplyContext(ctx, ctx.Event.Channel, "done")
+						return
+					}
+					_, _, _ = ctx.Reply("done")
+				},
+			}
+			bot.runListener(l, &slack.MessageEvent{Msg: slack.Msg{Text: "text", Channel: "C123", Timestamp: "1.2"}}, false)
+			if replyCtx == nil {
+				t.Fatalf("reply was not sent with a context")
+			}
+			if got := replyCtx.Value(key{}); got != "parent" {
+				t.Errorf("reply context value = %v, want parent", got)
+			}
+			if _, ok := replyCtx.Deadline(); !ok {
+				t.Errorf("reply context should have the listener's timeout as its deadline")
+			}
+			if got := replyErr != nil; got != tt.wantCancelled {
+				t.Errorf("reply context cancelled = %v, want %v", got, tt.wantCancelled)
+			}
+		})
+	}
+}
+
+func TestListener_matches(t *testing.T) {
+	l := &Listener{
+		Regex:   regexp.MustCompile(`^deploy (\S+)$`),
+		Aliases: []*regexp.Regexp{regexp.MustCompile(`^ship (\S+)$`), regexp.MustCompile(`^(?i)release$`)},
+	}
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{name: "should match the regex", text: "deploy api", want: true},
+		{name: "should match an alias", text: "ship api", want: true},
+		{name: "should match another alias", text: "Release", want: true},
+		{name: "should not match other text", text: "rollback api", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := l.matches(&Bot{}, tt.text); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListener_helpText(t *testing.T) {
+	tests := []struct {
+		name string
+		l    *Listener
+		want string
+	}{
+		{
+			name: "should return the usage without aliases",
+			l:    &Listener{Usage: "deploy <service>", Regex: regexp.MustCompile(`^deploy`)},
+			want: "deploy <service>",
+		},
+		{
+			name: "should list the aliases",
+			l: &Listener{
+				Usage:   "deploy <service>",
+				Regex:   regexp.MustCompile(`^deploy`),
+				Aliases: []*regexp.Regexp{regexp.MustCompile(`^ship`), regexp.MustCompile(`^(?i)release$`)},
+			},
+			want: "deploy <service> (aliases: ship, release)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.l.helpText(); got != tt.want {
+				t.Errorf("helpText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExchange_runStep(t *testing.T) {
+	replied := false
+	ex := &Exchange{
+		Bot: &Bot{
+			API: &mockAPI{
+				postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+					replied = true
+					return "", "", nil
+				},
+			},
+		},
+	}
+	var stepErr error
+	ex.runStep(&Step{Timeout: time.Millisecond}, func() {
+		<-ex.Context().Done()
+		stepErr = ex.Context().Err()
+	})
+	if stepErr != context.DeadlineExceeded {
+		t.Errorf("step context error = %v, want %v", stepErr, context.DeadlineExceeded)
+	}
+	if !replied {
+		t.Errorf("timeout message was not sent")
+	}
+	if ex.Context().Err() != nil {
+		t.Errorf("exchange context should be reset after the step")
+	}
+}
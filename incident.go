@@ -0,0 +1,254 @@
+package slackbot
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	defaultIncidentChannel       = "inc-%d"
+	defaultIncidentStatusMessage = "Reminder: please post a status update for this incident."
+	incidentNumberKey            = "incident:number"
+	incidentActiveKey            = "incident:active"
+	incidentSummaryMessage       = "*Incident %d: %s*\nStarted by <@%s> at %s"
+	incidentStartedMessage       = "Started incident %d in <#%s>"
+	incidentResolvedMessage      = "Incident %d was resolved by <@%s> after %s. This channel will now be archived."
+	incidentNotFoundMessage      = "This isn't an active incident channel."
+	incidentErrorMessage         = "There was a problem starting the incident - %s"
+)
+
+var (
+	incidentStartRegex   = regexp.MustCompile(`^(?i)incident (.+)$`)
+	incidentResolveRegex = regexp.MustCompile(`^(?i)resolve$`)
+)
+
+type (
+	// Incident runs the incident channel workflow. Add its Listeners to the bot's DirectListeners, then
+	// "incident <title>" creates a channel named from the ChannelTemplate, invites the Roster and the user who
+	// started it, sets the topic to the title, pins a summary and reminds the channel to post a status update
+	// every StatusInterval. "resolve" in the incident channel stops the reminders and archives the channel.
+	// Incident numbers are kept in the bot's Store, so they continue after a restart. Active
+	// incidents are saved in the bot's Store, so they can be resolved after a restart, and Resume restarts
+	// their reminders. Reminders stop when the bot is stopped.
+	Incident struct {
+		// ChannelTemplate is the name of the incident channel, %d is replaced with the incident number. The
+		// default is "inc-%d".
+		ChannelTemplate string
+
+		// Roster holds the users and usergroups to invite to each incident channel, by name or ID.
+		Roster []string
+
+		// StatusInterval is how often StatusMessage is sent to the channel, reminders are disabled if it is 0.
+		StatusInterval time.Duration
+		StatusMessage  string
+
+		mu     sync.Mutex
+		active map[string]*activeIncident
+	}
+
+	// activeIncident is the status reminder of an incident that hasn't been resolved. done is closed when its
+	// reminders have stopped.
+	activeIncident struct {
+		stop chan struct{}
+		done chan struct{}
+	}
+
+	// incidentRecord is an incident that hasn't been resolved, saved in the bot's Store.
+	incidentRecord struct {
+		Number  int
+		Title   string
+		Started time.Time
+	}
+)
+
+// Listeners returns the direct listeners that start and resolve incidents.
+func (inc *Incident) Listeners() []Listener {
+	return []Listener{
+		{
+			Usage: "incident <title>",
+			Regex: incidentStartRegex,
+			Handler: func(bot *Bot, ev *slack.MessageEvent) {
+				title := incidentStartRegex.FindStringSubmatch(ev.Text)[1]
+				number, channel, err := inc.Start(bot, title, ev.User)
+				msg := fmt.Sprintf(incidentStartedMessage, number, channel)
+				if err != nil {
+					msg = fmt.Sprintf(incidentErrorMessage, err)
+				}
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), msg)
+			},
+		},
+		{
+			Usage: "resolve (in an incident channel)",
+			Regex: incidentResolveRegex,
+			Handler: func(bot *Bot, ev *slack.MessageEvent) {
+				if err := inc.Resolve(bot, ev.Channel, ev.User); err != nil {
+					_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), err.Error())
+				}
+			},
+		},
+	}
+}
+
+// Start opens a new incident with the title for the user and returns its number and channel ID.
+func (inc *Incident) Start(bot *Bot, title string, user string) (int, string, error) {
+	number, err := inc.nextNumber(bot)
+	if err != nil {
+		return 0, "", err
+	}
+	template := inc.ChannelTemplate
+	if template == "" {
+		template = defaultIncidentChannel
+	}
+	channel, err := bot.EnsureChannel(fmt.Sprintf(template, number))
+	if err != nil {
+		return 0, "", err
+	}
+	if err := bot.InviteUsers(channel.ID, append([]string{user}, inc.Roster...)...); err != nil {
+		return 0, "", err
+	}
+	if err := bot.SetTopic(channel.ID, title); err != nil {
+		bot.LogWarn(err.Error())
+	}
+
+	started := bot.clock().Now()
+	summary := fmt.Sprintf(incidentSummaryMessage, number, title, user, bot.FormatTimeFor(user, started))
+	_, ts, err := bot.PinReply(channel.ID, summary)
+	if ts == "" {
+		return 0, "", err
+	}
+	if err != nil {
+		bot.LogWarn(err.Error())
+	}
+
+	inc.mu.Lock()
+	records := inc.load(bot)
+	records[channel.ID] = incidentRecord{Number: number, Title: title, Started: started}
+	err = bot.store().Put(incidentActiveKey, records)
+	inc.mu.Unlock()
+	if err != nil {
+		bot.LogWarn(fmt.Sprintf("unable to save incident %d - %s", number, err))
+	}
+	inc.startReminders(bot, channel.ID)
+	return number, channel.ID, nil
+}
+
+// Resume restarts the status reminders of the incidents that were active when the bot stopped, it should be
+// called once the bot has started.
+func (inc *Incident) Resume(bot *Bot) {
+	inc.mu.Lock()
+	records := inc.load(bot)
+	inc.mu.Unlock()
+	for channel := range records {
+		inc.startReminders(bot, channel)
+	}
+}
+
+// Resolve stops the status reminders for the incident in the channel and archives the channel.
+func (inc *Incident) Resolve(bot *Bot, channel string, user string) error {
+	inc.mu.Lock()
+	records := inc.load(bot)
+	record, ok := records[channel]
+	var err error
+	if ok {
+		delete(records, channel)
+		err = bot.store().Put(incidentActiveKey, records)
+	}
+	a, running := inc.active[channel]
+	if running {
+		close(a.stop)
+		delete(inc.active, channel)
+	}
+	inc.mu.Unlock()
+	if running {
+		<-a.done
+	}
+	if !ok {
+		return errors.New(incidentNotFoundMessage)
+	}
+	if err != nil {
+		bot.LogWarn(fmt.Sprintf("unable to remove incident %d - %s", record.Number, err))
+	}
+	duration := bot.clock().Now().Sub(record.Started).Round(time.Minute)
+	_, _, _ = bot.Reply(channel, fmt.Sprintf(incidentResolvedMessage, record.Number, user, duration))
+	err = bot.withRetry(func() error {
+		return bot.api().ArchiveConversationContext(bot.context(), channel)
+	})
+	return errors.Wrapf(err, "unable to archive the channel for incident %d", record.Number)
+}
+
+// startReminders starts reminding the incident channel to post a status update, unless reminders are
+// disabled or already running.
+func (inc *Incident) startReminders(bot *Bot, channel string) {
+	if inc.StatusInterval <= 0 {
+		return
+	}
+	inc.mu.Lock()
+	defer inc.mu.Unlock()
+	if _, running := inc.active[channel]; running {
+		return
+	}
+	if inc.active == nil {
+		inc.active = make(map[string]*activeIncident)
+	}
+	a := &activeIncident{stop: make(chan struct{}), done: make(chan struct{})}
+	inc.active[channel] = a
+	go inc.remind(bot, channel, a)
+}
+
+// load returns the active incidents saved in the bot's Store by channel, mu must be held.
+func (inc *Incident) load(bot *Bot) map[string]incidentRecord {
+	records := make(map[string]incidentRecord)
+	_ = bot.store().Get(incidentActiveKey, &records)
+	return records
+}
+
+// remind sends the status message to the channel every StatusInterval until the incident is resolved or the
+// bot is stopped. A reminder due at the same time as the incident is resolved isn't sent.
+func (inc *Incident) remind(bot *Bot, channel string, a *activeIncident) {
+	defer close(a.done)
+	msg := inc.StatusMessage
+	if msg == "" {
+		msg = defaultIncidentStatusMessage
+	}
+	stop := bot.stopChan()
+	for {
+		select {
+		case <-bot.clock().After(inc.StatusInterval):
+			select {
+			case <-a.stop:
+				return
+			case <-stop:
+				continue
+			default:
+			}
+			_, _, _ = bot.Reply(channel, msg)
+		case <-a.stop:
+			return
+		case <-stop:
+			inc.mu.Lock()
+			if inc.active[channel] == a {
+				delete(inc.active, channel)
+			}
+			inc.mu.Unlock()
+			return
+		}
+	}
+}
+
+// nextNumber returns the number of the next incident, saving it in the bot's Store.
+func (inc *Incident) nextNumber(bot *Bot) (int, error) {
+	inc.mu.Lock()
+	defer inc.mu.Unlock()
+	var number int
+	_ = bot.store().Get(incidentNumberKey, &number)
+	number++
+	if err := bot.store().Put(incidentNumberKey, number); err != nil {
+		return 0, errors.Wrap(err, "unable to save the incident number")
+	}
+	return number, nil
+}
@@ -0,0 +1,249 @@
+package slackbot
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func TestIncident_Listeners(t *testing.T) {
+	tests := []struct {
+		name         string
+		store        Store
+		messages     []*slack.MessageEvent
+		wantReplies  []string
+		wantInvited  []string
+		wantPinned   bool
+		wantArchived []string
+	}{
+		{
+			name: "should start an incident and resolve it",
+			messages: []*slack.MessageEvent{
+				{Msg: slack.Msg{Channel: "D1", User: "U1", Text: "incident database is down", Timestamp: "1.1"}},
+				{Msg: slack.Msg{Channel: "C9", User: "U2", Text: "resolve", Timestamp: "2.1"}},
+			},
+			wantReplies: []string{
+				"D1: Started incident 1 in <#C9>",
+				"C9: Incident 1 was resolved by <@U2> after 0s. This channel will now be archived.",
+			},
+			wantInvited:  []string{"U1", "U2", "U3"},
+			wantPinned:   true,
+			wantArchived: []string{"C9"},
+		},
+		{
+			name:  "should continue the incident numbers from the store",
+			store: SimpleStore{},
+			messages: []*slack.MessageEvent{
+				{Msg: slack.Msg{Channel: "D1", User: "U1", Text: "incident database is down", Timestamp: "1.1"}},
+			},
+			wantReplies: []string{"D1: Started incident 42 in <#C9>"},
+			wantInvited: []string{"U1", "U2", "U3"},
+			wantPinned:  true,
+		},
+		{
+			name: "should only resolve incident channels",
+			messages: []*slack.MessageEvent{
+				{Msg: slack.Msg{Channel: "C1", User: "U2", Text: "resolve", Timestamp: "2.1"}},
+			},
+			wantReplies: []string{"C1: This isn't an active incident channel."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var replies, invited, archived []string
+			var topic string
+			pinned := false
+			if tt.store != nil {
+				_ = tt.store.Put(incidentNumberKey, 41)
+			}
+			inc := &Incident{Roster: []string{"@oncall"}}
+			bot := &Bot{
+				Store: tt.store,
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						text := msgValues(opts...).Get("text")
+						if s != "C9" || msgValues(opts...).Get("thread_ts") != "" || text[0] != '*' {
+							replies = append(replies, s+": "+text)
+						}
+						return s, "3.1", nil
+					},
+					getConversations: func(params *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+						return nil, "", nil
+					},
+					createConversation: func(name string, isPrivate bool) (*slack.Channel, error) {
+						if name != "inc-1" && name != "inc-42" {
+							t.Errorf("EnsureChannel() created %s", name)
+						}
+						c := testChannel("C9", name, false)
+						return &c, nil
+					},
+					getChannel: func(s string) (slack.Channel, error) {
+						return testChannel(s, "", false), nil
+					},
+					getUser: func(s string) (slack.User, error) {
+						if s == "@oncall" {
+							return slack.User{}, errors.New("not found")
+						}
+						return slack.User{ID: s}, nil
+					},
+					getUserInfo: func(s string) (*slack.User, error) {
+						return &slack.User{ID: s}, nil
+					},
+					getUserGroups: func(opts ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+						return []slack.UserGroup{{ID: "S1", Handle: "oncall", Users: []string{"U2", "U3", "U1"}}}, nil
+					},
+					inviteUsers: func(c string, u ...string) (*slack.Channel, error) {
+						invited = u
+						return nil, nil
+					},
+					setTopic: func(c string, s string) (*slack.Channel, error) {
+						topic = s
+						return nil, nil
+					},
+					addPin: func(c string, item slack.ItemRef) error {
+						pinned = c == "C9" && item.Timestamp == "3.1"
+						return nil
+					},
+					archiveConversation: func(c string) error {
+						archived = append(archived, c)
+						return nil
+					},
+				},
+				DirectListeners: inc.Listeners(),
+			}
+			for _, ev := range tt.messages {
+				for _, l := range bot.DirectListeners {
+					if l.Regex.MatchString(ev.Text) {
+						l.Handler(bot, ev)
+					}
+				}
+			}
+			if !reflect.DeepEqual(replies, tt.wantReplies) {
+				t.Errorf("replies = %q, want %q", replies, tt.wantReplies)
+			}
+			if !reflect.DeepEqual(invited, tt.wantInvited) {
+				t.Errorf("invited = %v, want %v", invited, tt.wantInvited)
+			}
+			if tt.wantInvited != nil && topic != "database is down" {
+				t.Errorf("topic = %q, want %q", topic, "database is down")
+			}
+			if pinned != tt.wantPinned {
+				t.Errorf("pinned = %v, want %v", pinned, tt.wantPinned)
+			}
+			if !reflect.DeepEqual(archived, tt.wantArchived) {
+				t.Errorf("archived = %v, want %v", archived, tt.wantArchived)
+			}
+		})
+	}
+}
+
+func TestIncident_remind(t *testing.T) {
+	tests := []struct {
+		name    string
+		resolve bool
+	}{
+		{
+			name:    "should remind the channel until the incident is resolved",
+			resolve: true,
+		},
+		{
+			name: "should stop reminding the channel when the bot stops",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			reminders := 0
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						mu.Lock()
+						defer mu.Unlock()
+						if msgValues(opts...).Get("text") == "post an update" {
+							reminders++
+						}
+						return s, "ts", nil
+					},
+					archiveConversation: func(c string) error {
+						return nil
+					},
+				},
+			}
+			defer bot.Stop()
+			records := map[string]incidentRecord{"C9": {Number: 1, Title: "database is down", Started: time.Now()}}
+			if err := bot.store().Put(incidentActiveKey, records); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+			inc := &Incident{StatusInterval: time.Millisecond, StatusMessage: "post an update"}
+			inc.Resume(bot)
+			inc.Resume(bot)
+			waitFor(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return reminders >= 2
+			})
+			if tt.resolve {
+				if err := inc.Resolve(bot, "C9", "U1"); err != nil {
+					t.Errorf("Resolve() error = %v", err)
+				}
+				if got := inc.load(bot); len(got) != 0 {
+					t.Errorf("Resolve() left the incident in the store, got %v", got)
+				}
+			} else {
+				bot.Stop()
+			}
+			waitFor(t, func() bool {
+				inc.mu.Lock()
+				defer inc.mu.Unlock()
+				return len(inc.active) == 0
+			})
+			mu.Lock()
+			sent := reminders
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+			if reminders != sent {
+				t.Errorf("reminders = %d after the reminders stopped, want %d", reminders, sent)
+			}
+		})
+	}
+}
+
+func TestIncident_Resolve_afterRestart(t *testing.T) {
+	store := NewMemoryStore(nil)
+	var replies, archived []string
+	newBot := func() *Bot {
+		return &Bot{
+			Store: store,
+			API: &mockAPI{
+				postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+					replies = append(replies, msgValues(opts...).Get("text"))
+					return s, "ts", nil
+				},
+				archiveConversation: func(c string) error {
+					archived = append(archived, c)
+					return nil
+				},
+			},
+		}
+	}
+	records := map[string]incidentRecord{"C9": {Number: 7, Title: "database is down", Started: time.Now()}}
+	if err := store.Put(incidentActiveKey, records); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	inc := &Incident{}
+	if err := inc.Resolve(newBot(), "C9", "U1"); err != nil {
+		t.Errorf("Resolve() error = %v", err)
+	}
+	if want := []string{"Incident 7 was resolved by <@U1> after 0s. This channel will now be archived."}; !reflect.DeepEqual(replies, want) {
+		t.Errorf("replies = %q, want %q", replies, want)
+	}
+	if want := []string{"C9"}; !reflect.DeepEqual(archived, want) {
+		t.Errorf("archived = %v, want %v", archived, want)
+	}
+}
@@ -0,0 +1,222 @@
+package slackbot
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// JobRunning is the status of a job that has not finished.
+	JobRunning JobStatus = "running"
+	// JobSucceeded is the status of a job that finished without an error.
+	JobSucceeded JobStatus = "succeeded"
+	// JobFailed is the status of a job that returned an error.
+	JobFailed JobStatus = "failed"
+
+	jobStoreKeyPrefix = "job:"
+)
+
+var jobStatusRegex = regexp.MustCompile(`^(?i)job status (\S+)`)
+
+type (
+	// JobStatus is the state of a background job.
+	JobStatus string
+
+	// JobFunc is the work done by a background job. The context will be cancelled if the job is
+	// cancelled with Bot.CancelJob. Progress can be reported to users with the Reporter.
+	JobFunc func(ctx context.Context, progress Reporter) error
+
+	// Reporter is used by background jobs to report their progress. Each report will update the
+	// job's tracking message in slack.
+	Reporter interface {
+		Report(progress string)
+	}
+
+	// Job holds the details of a background job submitted with Bot.SubmitJob.
+	Job struct {
+		ID        string
+		Name      string
+		Channel   string
+		Timestamp string
+		Status    JobStatus
+		Progress  string
+		Error     string
+		Started   time.Time
+		Finished  time.Time
+	}
+
+	runningJob struct {
+		job    Job
+		bot    *Bot
+		cancel context.CancelFunc
+	}
+)
+
+// SubmitJob will run the job in the background and return its ID. A tracking message is posted to the
+// channel and updated as the job reports progress and when it completes. When the job is complete the
+// result will be saved in the bot's Store, if one is set, so the status can still be found after a restart.
+func (bot *Bot) SubmitJob(channel string, name string, fn JobFunc) (string, error) {
+	ctx, cancel := context.WithCancel(bot.context())
+	rj := &runningJob{
+		job: Job{
+			ID:      newID(),
+			Name:    name,
+			Status:  JobRunning,
+			Started: bot.clock().Now(),
+		},
+		bot:    bot,
+		cancel: cancel,
+	}
+	c, ts, err := bot.Reply(channel, rj.job.String())
+	if err != nil {
+		cancel()
+		return "", err
+	}
+	rj.job.Channel, rj.job.Timestamp = c, ts
+
+	bot.mu.Lock()
+	if bot.jobs == nil {
+		bot.jobs = make(map[string]*runningJob)
+	}
+	bot.jobs[rj.job.ID] = rj
+	bot.mu.Unlock()
+
+	go rj.run(ctx, fn)
+	return rj.job.ID, nil
+}
+
+// GetJob returns the details of the job with the ID passed in. Jobs that have finished and
+// were saved are looked up in the bot's Store.
+func (bot *Bot) GetJob(ID string) (Job, error) {
+	bot.mu.Lock()
+	rj, ok := bot.jobs[ID]
+	var job Job
+	if ok {
+		job = rj.job
+	}
+	bot.mu.Unlock()
+	if ok {
+		return job, nil
+	}
+	if bot.Store != nil {
+		if err := bot.Store.Get(jobStoreKeyPrefix+ID, &job); err == nil {
+			return job, nil
+		}
+	}
+	return Job{}, errors.Errorf("job %s not found", ID)
+}
+
+// CancelJob will cancel the context of a running job.
+func (bot *Bot) CancelJob(ID string) error {
+	bot.mu.Lock()
+	rj, ok := bot.jobs[ID]
+	running := ok && rj.job.Status == JobRunning
+	bot.mu.Unlock()
+	if !running {
+		return errors.Errorf("job %s is not running", ID)
+	}
+	rj.cancel()
+	return nil
+}
+
+// JobStatusListener returns a DirectListener that replies with the status of a job when a user
+// messages the bot "job status <id>". Add it to the bot's DirectListeners to enable it.
+func JobStatusListener() Listener {
+	return Listener{
+		Usage: "job status <job id>",
+		Regex: jobStatusRegex,
+		Handler: func(bot *Bot, ev *slack.MessageEvent) {
+			ID := jobStatusRegex.FindStringSubmatch(ev.Text)[1]
+			job, err := bot.GetJob(ID)
+			if err != nil {
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), err.Error())
+				return
+			}
+			_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), job.String())
+		},
+	}
+}
+
+// String returns a one line summary of the job's status.
+func (j Job) String() string {
+	msg := fmt.Sprintf("[job %s] %s: %s", j.ID, j.Name, j.Status)
+	switch j.Status {
+	case JobFailed:
+		msg += fmt.Sprintf(" - %s", j.Error)
+	case JobSucceeded:
+		msg += fmt.Sprintf(" in %s", j.Finished.Sub(j.Started).Round(time.Second))
+	default:
+		if j.Progress != "" {
+			msg += fmt.Sprintf(" - %s", j.Progress)
+		}
+	}
+	return msg
+}
+
+// Report updates the job's progress and its tracking message.
+func (rj *runningJob) Report(progress string) {
+	rj.bot.mu.Lock()
+	rj.job.Progress = progress
+	rj.bot.mu.Unlock()
+	rj.update()
+}
+
+func (rj *runningJob) run(ctx context.Context, fn JobFunc) {
+	defer rj.cancel()
+	info := ErrorInfo{Source: ErrorSourceJob, Channel: rj.job.Channel, Thread: rj.job.Timestamp}
+	err := func() (err error) {
+		defer rj.bot.recoverPanic(info, func(e error) { err = e })
+		return fn(ctx, rj)
+	}()
+	rj.bot.reportError(err, info)
+
+	rj.bot.mu.Lock()
+	rj.job.Finished = rj.bot.clock().Now()
+	rj.job.Status = JobSucceeded
+	if err != nil {
+		rj.job.Status = JobFailed
+		rj.job.Error = err.Error()
+	}
+	job := rj.job
+	rj.bot.mu.Unlock()
+	rj.update()
+
+	// Finished jobs are kept in memory unless they can be saved to the store.
+	if rj.bot.Store == nil {
+		return
+	}
+	if err := rj.bot.Store.Put(jobStoreKeyPrefix+job.ID, job); err != nil {
+		rj.bot.LogError(fmt.Sprintf("error saving job %s - %s", job.ID, err))
+		return
+	}
+	rj.bot.mu.Lock()
+	delete(rj.bot.jobs, job.ID)
+	rj.bot.mu.Unlock()
+}
+
+func (rj *runningJob) update() {
+	rj.bot.mu.Lock()
+	job := rj.job
+	rj.bot.mu.Unlock()
+	err := rj.bot.withRetry(func() error {
+		_, _, _, err := rj.bot.api().UpdateMessageContext(rj.bot.context(), job.Channel, job.Timestamp, slack.MsgOptionText(job.String(), false))
+		return err
+	})
+	if err != nil {
+		rj.bot.LogError(fmt.Sprintf("error updating job %s tracking message - %s", job.ID, err))
+	}
+}
+
+// newID returns a short random hex ID.
+func newID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,191 @@
+package slackbot
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func TestBot_SubmitJob(t *testing.T) {
+	tests := []struct {
+		name       string
+		fn         JobFunc
+		store      Store
+		wantStatus JobStatus
+		wantError  string
+	}{
+		{
+			name: "should run the job and save the result",
+			fn: func(ctx context.Context, progress Reporter) error {
+				progress.Report("halfway")
+				return nil
+			},
+			store:      SimpleStore{},
+			wantStatus: JobSucceeded,
+		},
+		{
+			name: "should record a failed job without a store",
+			fn: func(ctx context.Context, progress Reporter) error {
+				return errors.New("boom")
+			},
+			wantStatus: JobFailed,
+			wantError:  "boom",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var updates []string
+			done := make(chan struct{})
+			bot := &Bot{
+				Store: tt.store,
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						return "C123", "1.2", nil
+					},
+					updateMessage: func(ch string, ts string, opts ...slack.MsgOption) (string, string, string, error) {
+						mu.Lock()
+						defer mu.Unlock()
+						updates = append(updates, ts)
+						return ch, ts, "", nil
+					},
+				},
+			}
+			ID, err := bot.SubmitJob("C123", "deploy", func(ctx context.Context, progress Reporter) error {
+				defer close(done)
+				return tt.fn(ctx, progress)
+			})
+			if err != nil {
+				t.Fatalf("SubmitJob() error = %v", err)
+			}
+			<-done
+
+			var job Job
+			for i := 0; i < 100; i++ {
+				if job, err = bot.GetJob(ID); err == nil && job.Status != JobRunning {
+					break
+				}
+				time.Sleep(time.Millisecond)
+			}
+			if job.Status != tt.wantStatus || job.Error != tt.wantError {
+				t.Errorf("GetJob() got = %v, want status %v error %v", job, tt.wantStatus, tt.wantError)
+			}
+			if job.Channel != "C123" || job.Timestamp != "1.2" {
+				t.Errorf("GetJob() tracking message got = %s %s", job.Channel, job.Timestamp)
+			}
+			mu.Lock()
+			if len(updates) == 0 {
+				t.Errorf("tracking message was not updated")
+			}
+			mu.Unlock()
+		})
+	}
+}
+
+func TestBot_CancelJob(t *testing.T) {
+	bot := &Bot{
+		API: &mockAPI{
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				return "C123", "1.2", nil
+			},
+			updateMessage: func(ch string, ts string, opts ...slack.MsgOption) (string, string, string, error) {
+				return ch, ts, "", nil
+			},
+		},
+	}
+	done := make(chan error)
+	ID, _ := bot.SubmitJob("C123", "wait", func(ctx context.Context, progress Reporter) error {
+		<-ctx.Done()
+		done <- ctx.Err()
+		return ctx.Err()
+	})
+	if err := bot.CancelJob(ID); err != nil {
+		t.Fatalf("CancelJob() error = %v", err)
+	}
+	if err := <-done; err != context.Canceled {
+		t.Errorf("job context error = %v, want %v", err, context.Canceled)
+	}
+	if err := bot.CancelJob("missing"); err == nil {
+		t.Errorf("CancelJob() expected an error for a missing job")
+	}
+}
+
+func TestJob_String(t *testing.T) {
+	started := time.Now()
+	tests := []struct {
+		name string
+		job  Job
+		want string
+	}{
+		{
+			name: "should include progress for a running job",
+			job:  Job{ID: "abc", Name: "deploy", Status: JobRunning, Progress: "step 2"},
+			want: "[job abc] deploy: running - step 2",
+		},
+		{
+			name: "should include the error for a failed job",
+			job:  Job{ID: "abc", Name: "deploy", Status: JobFailed, Error: "boom"},
+			want: "[job abc] deploy: failed - boom",
+		},
+		{
+			name: "should include the duration for a finished job",
+			job:  Job{ID: "abc", Name: "deploy", Status: JobSucceeded, Started: started, Finished: started.Add(3 * time.Second)},
+			want: "[job abc] deploy: succeeded in 3s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.job.String(); got != tt.want {
+				t.Errorf("String() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobStatusListener(t *testing.T) {
+	var reply string
+	bot := &Bot{
+		API: &mockAPI{
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				reply = msgValues(opts...).Get("text")
+				return "", "", nil
+			},
+		},
+		jobs: map[string]*runningJob{
+			"abc": {job: Job{ID: "abc", Name: "deploy", Status: JobRunning}},
+		},
+	}
+	l := JobStatusListener()
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "should reply with the job status",
+			text: "job status abc",
+			want: "[job abc] deploy: running",
+		},
+		{
+			name: "should reply with an error if the job is not found",
+			text: "job status xyz",
+			want: "job xyz not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev := &slack.MessageEvent{Msg: slack.Msg{Text: tt.text, Channel: "C123"}}
+			if !l.Regex.MatchString(ev.Text) {
+				t.Fatalf("JobStatusListener() regex did not match %s", ev.Text)
+			}
+			l.Handler(bot, ev)
+			if reply != tt.want {
+				t.Errorf("JobStatusListener() reply = %v, want %v", reply, tt.want)
+			}
+		})
+	}
+}
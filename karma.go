@@ -0,0 +1,187 @@
+package slackbot
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	karmaStoreKey       = "karma"
+	karmaDisabledKey    = "karma disabled"
+	karmaLeaderboardTop = 10
+	karmaSelfMessage    = "You can't change your own karma."
+	karmaPointsMessage  = "<@%s> has %d points."
+	karmaNoneMessage    = "Nobody has any karma yet."
+	karmaEnabledMessage = "Karma is enabled in this channel."
+	karmaDisableMessage = "Karma is disabled in this channel."
+)
+
+var (
+	karmaChangeRegex      = regexp.MustCompile(`<@([A-Z0-9]+)>\s?(\+\+|--)`)
+	karmaReportRegex      = regexp.MustCompile(`(?i)^karma <@([A-Z0-9]+)>$`)
+	karmaLeaderboardRegex = regexp.MustCompile(`(?i)^karma (?:leaderboard|top)$`)
+	karmaToggleRegex      = regexp.MustCompile(`(?i)^karma (on|off)$`)
+)
+
+// KarmaScore is a user's karma, see Bot.KarmaLeaderboard.
+type KarmaScore struct {
+	User   string
+	Points int
+}
+
+// KarmaListeners returns the listeners for the karma plugin. The indirect listener gives a point to users
+// mentioned with "@user ++" and takes one away with "@user --" in any channel karma is enabled in. The direct
+// listeners report a user's points with "karma @user", the top users with "karma leaderboard", and turn karma
+// on or off in a channel with "karma on" and "karma off". Points are saved in the bot's Store.
+//
+//	indirect, direct := slackbot.KarmaListeners()
+//	bot.IndirectListeners = append(bot.IndirectListeners, indirect)
+//	bot.DirectListeners = append(bot.DirectListeners, direct...)
+func KarmaListeners() (indirect Listener, direct []Listener) {
+	indirect = Listener{
+		Usage:   "@user ++ or @user --",
+		Regex:   karmaChangeRegex,
+		Handler: handleKarmaChange,
+	}
+	direct = []Listener{
+		{
+			Usage: "karma @user",
+			Regex: karmaReportRegex,
+			Handler: func(bot *Bot, ev *slack.MessageEvent) {
+				user := karmaReportRegex.FindStringSubmatch(ev.Text)[1]
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), fmt.Sprintf(karmaPointsMessage, user, bot.Karma(user)))
+			},
+		},
+		{
+			Usage: "karma leaderboard",
+			Regex: karmaLeaderboardRegex,
+			Handler: func(bot *Bot, ev *slack.MessageEvent) {
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), formatKarmaLeaderboard(bot.KarmaLeaderboard(karmaLeaderboardTop)))
+			},
+		},
+		{
+			Usage: "karma on|off",
+			Regex: karmaToggleRegex,
+			Handler: func(bot *Bot, ev *slack.MessageEvent) {
+				enabled := strings.EqualFold(karmaToggleRegex.FindStringSubmatch(ev.Text)[1], "on")
+				msg := karmaDisableMessage
+				if enabled {
+					msg = karmaEnabledMessage
+				}
+				if err := bot.SetKarmaEnabled(ev.Channel, enabled); err != nil {
+					msg = err.Error()
+				}
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), msg)
+			},
+		},
+	}
+	return indirect, direct
+}
+
+// handleKarmaChange gives or takes a point from each user mentioned with ++ or --.
+func handleKarmaChange(bot *Bot, ev *slack.MessageEvent) {
+	if ev.User == "" || (bot.userDetails != nil && ev.User == bot.userDetails.ID) || !bot.KarmaEnabled(ev.Channel) {
+		return
+	}
+	var buf bytes.Buffer
+	for _, m := range karmaChangeRegex.FindAllStringSubmatch(ev.Text, -1) {
+		if m[1] == ev.User {
+			buf.WriteString(karmaSelfMessage + "\n")
+			continue
+		}
+		delta := 1
+		if m[2] == "--" {
+			delta = -1
+		}
+		points, err := bot.AddKarma(m[1], delta)
+		if err != nil {
+			bot.LogError(err.Error())
+			continue
+		}
+		buf.WriteString(fmt.Sprintf(karmaPointsMessage+"\n", m[1], points))
+	}
+	if buf.Len() > 0 {
+		_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), buf.String())
+	}
+}
+
+// AddKarma adds the delta to the user's karma and returns their new points.
+func (bot *Bot) AddKarma(user string, delta int) (int, error) {
+	bot.karmaMu.Lock()
+	defer bot.karmaMu.Unlock()
+	karma := bot.loadKarma()
+	karma[user] += delta
+	if err := bot.store().Put(karmaStoreKey, karma); err != nil {
+		return 0, errors.Wrapf(err, "unable to save the karma of %s", user)
+	}
+	return karma[user], nil
+}
+
+// Karma returns the user's karma.
+func (bot *Bot) Karma(user string) int {
+	bot.karmaMu.Lock()
+	defer bot.karmaMu.Unlock()
+	return bot.loadKarma()[user]
+}
+
+// KarmaLeaderboard returns up to n users with the most karma, highest first.
+func (bot *Bot) KarmaLeaderboard(n int) []KarmaScore {
+	bot.karmaMu.Lock()
+	karma := bot.loadKarma()
+	bot.karmaMu.Unlock()
+	scores := make([]KarmaScore, 0, len(karma))
+	for user, points := range karma {
+		scores = append(scores, KarmaScore{User: user, Points: points})
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Points != scores[j].Points {
+			return scores[i].Points > scores[j].Points
+		}
+		return scores[i].User < scores[j].User
+	})
+	if len(scores) > n {
+		scores = scores[:n]
+	}
+	return scores
+}
+
+// SetKarmaEnabled turns karma on or off in the channel, it is on by default.
+func (bot *Bot) SetKarmaEnabled(channel string, enabled bool) error {
+	if enabled {
+		_ = bot.ChannelState(channel).Delete(karmaDisabledKey)
+		return nil
+	}
+	return bot.ChannelState(channel).Put(karmaDisabledKey, true)
+}
+
+// KarmaEnabled returns false if karma has been turned off in the channel.
+func (bot *Bot) KarmaEnabled(channel string) bool {
+	var disabled bool
+	_ = bot.ChannelState(channel).Get(karmaDisabledKey, &disabled)
+	return !disabled
+}
+
+// loadKarma returns the points of each user in the Store, karmaMu must be held.
+func (bot *Bot) loadKarma() map[string]int {
+	karma := make(map[string]int)
+	_ = bot.store().Get(karmaStoreKey, &karma)
+	return karma
+}
+
+// formatKarmaLeaderboard formats the scores as a numbered list.
+func formatKarmaLeaderboard(scores []KarmaScore) string {
+	if len(scores) == 0 {
+		return karmaNoneMessage
+	}
+	var buf bytes.Buffer
+	for i, s := range scores {
+		buf.WriteString(fmt.Sprintf("%d. <@%s> - %d\n", i+1, s.User, s.Points))
+	}
+	return buf.String()
+}
@@ -0,0 +1,134 @@
+package slackbot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestKarmaListeners(t *testing.T) {
+	tests := []struct {
+		name        string
+		karma       map[string]int
+		disabled    bool
+		user        string
+		text        string
+		direct      bool
+		wantReplies []string
+		wantKarma   map[string]int
+	}{
+		{
+			name:        "should give karma",
+			karma:       map[string]int{"U2": 2},
+			user:        "U1",
+			text:        "thanks <@U2> ++",
+			wantReplies: []string{"<@U2> has 3 points.\n"},
+			wantKarma:   map[string]int{"U2": 3},
+		},
+		{
+			name:        "should give and take karma from several users",
+			user:        "U1",
+			text:        "<@U2>++ <@U3>--",
+			wantReplies: []string{"<@U2> has 1 points.\n<@U3> has -1 points.\n"},
+			wantKarma:   map[string]int{"U2": 1, "U3": -1},
+		},
+		{
+			name:        "should not let users give themselves karma",
+			user:        "U1",
+			text:        "<@U1> ++",
+			wantReplies: []string{karmaSelfMessage + "\n"},
+			wantKarma:   map[string]int{},
+		},
+		{
+			name:      "should not give karma in disabled channels",
+			disabled:  true,
+			user:      "U1",
+			text:      "<@U2> ++",
+			wantKarma: map[string]int{},
+		},
+		{
+			name:        "should report a user's karma",
+			karma:       map[string]int{"U2": 4},
+			user:        "U1",
+			text:        "karma <@U2>",
+			direct:      true,
+			wantReplies: []string{"<@U2> has 4 points."},
+			wantKarma:   map[string]int{"U2": 4},
+		},
+		{
+			name:        "should show the leaderboard",
+			karma:       map[string]int{"U2": 4, "U3": 7, "U4": 4},
+			user:        "U1",
+			text:        "karma leaderboard",
+			direct:      true,
+			wantReplies: []string{"1. <@U3> - 7\n2. <@U2> - 4\n3. <@U4> - 4\n"},
+			wantKarma:   map[string]int{"U2": 4, "U3": 7, "U4": 4},
+		},
+		{
+			name:        "should turn karma off in a channel",
+			user:        "U1",
+			text:        "karma off",
+			direct:      true,
+			wantReplies: []string{karmaDisableMessage},
+			wantKarma:   map[string]int{},
+		},
+		{
+			name:        "should turn karma on in a channel in any case",
+			disabled:    true,
+			user:        "U1",
+			text:        "Karma ON",
+			direct:      true,
+			wantReplies: []string{karmaEnabledMessage},
+			wantKarma:   map[string]int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var replies []string
+			indirect, direct := KarmaListeners()
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						replies = append(replies, msgValues(opts...).Get("text"))
+						return s, "ts", nil
+					},
+				},
+				IndirectListeners: []Listener{indirect},
+				DirectListeners:   direct,
+				userDetails:       &slack.UserDetails{ID: "bot"},
+			}
+			bot.init()
+			if tt.karma != nil {
+				_ = bot.store().Put(karmaStoreKey, tt.karma)
+			}
+			if tt.disabled {
+				_ = bot.SetKarmaEnabled("C1", false)
+			}
+			text := tt.text
+			if tt.direct {
+				text = "<@bot> " + text
+			}
+			bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "C1", User: tt.user, Text: text, Timestamp: "1.1"}})
+			if !reflect.DeepEqual(replies, tt.wantReplies) {
+				t.Errorf("replies = %q, want %q", replies, tt.wantReplies)
+			}
+			if got := bot.loadKarma(); !reflect.DeepEqual(got, tt.wantKarma) {
+				t.Errorf("karma = %v, want %v", got, tt.wantKarma)
+			}
+		})
+	}
+}
+
+func TestBot_SetKarmaEnabled(t *testing.T) {
+	bot := &Bot{}
+	if err := bot.SetKarmaEnabled("C1", false); err != nil || bot.KarmaEnabled("C1") {
+		t.Errorf("KarmaEnabled() = %v, %v, want false", bot.KarmaEnabled("C1"), err)
+	}
+	if err := bot.SetKarmaEnabled("C1", true); err != nil || !bot.KarmaEnabled("C1") {
+		t.Errorf("KarmaEnabled() = %v, %v, want true", bot.KarmaEnabled("C1"), err)
+	}
+	if err := bot.SetKarmaEnabled("C1", true); err != nil {
+		t.Errorf("SetKarmaEnabled() error = %v", err)
+	}
+}
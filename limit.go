@@ -0,0 +1,98 @@
+package slackbot
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	listenerBusyMessage   = "This is already running, started by %s."
+	listenerQueuedMessage = listenerBusyMessage + " Yours will start when it finishes."
+)
+
+// listenerLimit tracks the running invocations of a listener with MaxConcurrent set.
+type listenerLimit struct {
+	slots chan struct{}
+	mu    sync.Mutex
+	users []string
+}
+
+// acquireListener waits for, or rejects the event if QueueWhenBusy is not set, when the listener already
+// has MaxConcurrent handlers running. The user is told who started the running handlers. A queued event is
+// dropped if the bot stops while it waits. If the event can run, release must be called when the handler
+// returns.
+func (bot *Bot) acquireListener(l *Listener, ev *slack.MessageEvent) (release func(), ok bool) {
+	lim := bot.listenerLimit(l)
+	select {
+	case lim.slots <- struct{}{}:
+	default:
+		if !l.QueueWhenBusy {
+			_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), fmt.Sprintf(listenerBusyMessage, lim.startedBy()))
+			return nil, false
+		}
+		_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), fmt.Sprintf(listenerQueuedMessage, lim.startedBy()))
+		select {
+		case lim.slots <- struct{}{}:
+		case <-bot.stopChan():
+			return nil, false
+		case <-bot.context().Done():
+			return nil, false
+		}
+	}
+	lim.add(ev.User)
+	return func() {
+		lim.remove(ev.User)
+		<-lim.slots
+	}, true
+}
+
+// listenerLimit returns the limit for the listener. Listeners are copied when they run, so they are identified
+// by their command name and Aliases, which tells apart listeners that only match by their Aliases.
+func (bot *Bot) listenerLimit(l *Listener) *listenerLimit {
+	key := commandName(l.Usage, l.Regex)
+	for _, a := range l.Aliases {
+		key += "\x00" + a.String()
+	}
+	bot.limitsMu.Lock()
+	defer bot.limitsMu.Unlock()
+	if bot.limits == nil {
+		bot.limits = make(map[string]*listenerLimit)
+	}
+	lim, ok := bot.limits[key]
+	if !ok {
+		lim = &listenerLimit{slots: make(chan struct{}, l.MaxConcurrent)}
+		bot.limits[key] = lim
+	}
+	return lim
+}
+
+func (lim *listenerLimit) add(user string) {
+	lim.mu.Lock()
+	defer lim.mu.Unlock()
+	lim.users = append(lim.users, user)
+}
+
+func (lim *listenerLimit) remove(user string) {
+	lim.mu.Lock()
+	defer lim.mu.Unlock()
+	for i, u := range lim.users {
+		if u == user {
+			lim.users = append(lim.users[:i], lim.users[i+1:]...)
+			return
+		}
+	}
+}
+
+// startedBy returns mentions of the users running the listener.
+func (lim *listenerLimit) startedBy() string {
+	lim.mu.Lock()
+	defer lim.mu.Unlock()
+	mentions := make([]string, len(lim.users))
+	for i, u := range lim.users {
+		mentions[i] = fmt.Sprintf("<@%s>", u)
+	}
+	return strings.Join(mentions, ", ")
+}
@@ -0,0 +1,140 @@
+package slackbot
+
+import (
+	"reflect"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_acquireListener(t *testing.T) {
+	tests := []struct {
+		name        string
+		queue       bool
+		wantHandled int
+		wantReplies []string
+	}{
+		{
+			name:        "should reject messages while the handler is running",
+			wantHandled: 1,
+			wantReplies: []string{"This is already running, started by <@U1>."},
+		},
+		{
+			name:        "should queue messages while the handler is running",
+			queue:       true,
+			wantHandled: 2,
+			wantReplies: []string{"This is already running, started by <@U1>. Yours will start when it finishes."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var replies []string
+			handled := 0
+			started := make(chan struct{})
+			finish := make(chan struct{})
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						mu.Lock()
+						defer mu.Unlock()
+						replies = append(replies, msgValues(opts...).Get("text"))
+						return s, "ts", nil
+					},
+				},
+			}
+			l := &Listener{
+				Regex:         regexp.MustCompile("run tests"),
+				MaxConcurrent: 1,
+				QueueWhenBusy: tt.queue,
+				Handler: func(bot *Bot, ev *slack.MessageEvent) {
+					mu.Lock()
+					handled++
+					mu.Unlock()
+					if ev.User == "U1" {
+						close(started)
+						<-finish
+					}
+				},
+			}
+
+			first := make(chan struct{})
+			go func() {
+				defer close(first)
+				bot.runListener(l, &slack.MessageEvent{Msg: slack.Msg{User: "U1", Channel: "C1", Timestamp: "1.1"}}, false)
+			}()
+			<-started
+
+			second := make(chan struct{})
+			go func() {
+				defer close(second)
+				bot.runListener(l, &slack.MessageEvent{Msg: slack.Msg{User: "U2", Channel: "C1", Timestamp: "1.2"}}, false)
+			}()
+			waitFor(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return len(replies) > 0
+			})
+			close(finish)
+			<-first
+			<-second
+
+			if handled != tt.wantHandled {
+				t.Errorf("runListener() handled %d messages, want %d", handled, tt.wantHandled)
+			}
+			if !reflect.DeepEqual(replies, tt.wantReplies) {
+				t.Errorf("runListener() replied %q, want %q", replies, tt.wantReplies)
+			}
+		})
+	}
+}
+
+func TestBot_listenerLimit(t *testing.T) {
+	bot := &Bot{}
+	deploy := &Listener{Aliases: []*regexp.Regexp{regexp.MustCompile("deploy")}, MaxConcurrent: 1}
+	rollback := &Listener{Aliases: []*regexp.Regexp{regexp.MustCompile("rollback")}, MaxConcurrent: 1}
+	if bot.listenerLimit(deploy) == bot.listenerLimit(rollback) {
+		t.Errorf("listenerLimit() shared a limit between listeners that only have Aliases")
+	}
+	if bot.listenerLimit(deploy) != bot.listenerLimit(&Listener{Aliases: deploy.Aliases}) {
+		t.Errorf("listenerLimit() returned a new limit for a copy of the listener")
+	}
+}
+
+func TestBot_acquireListener_stop(t *testing.T) {
+	bot := &Bot{API: &mockAPI{postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+		return s, "ts", nil
+	}}}
+	l := &Listener{Regex: regexp.MustCompile("run tests"), MaxConcurrent: 1, QueueWhenBusy: true}
+	release, _ := bot.acquireListener(l, &slack.MessageEvent{Msg: slack.Msg{User: "U1", Channel: "C1"}})
+	defer release()
+
+	done := make(chan bool)
+	go func() {
+		_, ok := bot.acquireListener(l, &slack.MessageEvent{Msg: slack.Msg{User: "U2", Channel: "C1"}})
+		done <- ok
+	}()
+	bot.Stop()
+	select {
+	case ok := <-done:
+		if ok {
+			t.Errorf("acquireListener() = true after the bot stopped, want false")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("acquireListener() kept waiting after the bot stopped")
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,103 @@
+package slackbot
+
+import (
+	"log"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// LevelDebug is for verbose messages useful when debugging the bot.
+	LevelDebug LogLevel = iota
+	// LevelInfo is for messages about the normal operation of the bot.
+	LevelInfo
+	// LevelWarn is for problems the bot was able to recover from.
+	LevelWarn
+	// LevelError is for failures that someone should look at.
+	LevelError
+)
+
+// LogLevel is the severity of a log message. Messages below the bot's MinLevel are only logged to
+// the console and are not sent to slack.
+type LogLevel int
+
+// String returns the name of the level.
+func (l LogLevel) String() string {
+	switch l {
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "DEBUG"
+	}
+}
+
+// LogDebug will send the log message to the bots DebugChannel if set and log the message to the console.
+// If the DebugBatchInterval is set the message will be sent with the next batch.
+func (bot *Bot) LogDebug(msg string) {
+	bot.logAt(LevelDebug, msg)
+}
+
+// LogInfo will log the message to the console and send it to the DebugChannel if the MinLevel allows it.
+func (bot *Bot) LogInfo(msg string) {
+	bot.logAt(LevelInfo, msg)
+}
+
+// LogWarn will log the message to the console and send it to the DebugChannel if the MinLevel allows it.
+func (bot *Bot) LogWarn(msg string) {
+	bot.logAt(LevelWarn, msg)
+}
+
+// LogError will log the message to the console and send it to the ErrorChannel, or the DebugChannel if
+// there is no ErrorChannel, if the MinLevel allows it. Errors sent to the ErrorChannel are never batched.
+func (bot *Bot) LogError(msg string) {
+	bot.logAt(LevelError, msg)
+}
+
+func (bot *Bot) logAt(level LogLevel, msg string) {
+	msg = bot.redact(msg)
+	if level != LevelDebug {
+		msg = "[" + level.String() + "] " + msg
+	}
+	if level >= bot.MinLevel {
+		switch {
+		case level == LevelError && bot.ErrorChannel != "":
+			bot.sendLog(bot.ErrorChannel, msg)
+		case bot.DebugChannel != "" && bot.DebugBatchInterval > 0:
+			bot.batchDebug(msg)
+		case bot.DebugChannel != "":
+			bot.sendLog(bot.DebugChannel, msg)
+		}
+	}
+	log.Println(msg)
+}
+
+func (bot *Bot) sendLog(channel string, msg string) {
+	if err := bot.checkCircuitBreaker(channel); err != nil {
+		log.Printf("Error sending message to log channel %s - %s\n", channel, err)
+		return
+	}
+	if bot.channelUnavailable(channel) {
+		log.Printf("Error sending message to log channel %s - %s\n", channel, ErrChannelUnavailable)
+		return
+	}
+	if bot.SendQueue != nil {
+		priority := PriorityDebug
+		if channel == bot.ErrorChannel {
+			priority = PriorityAlert
+		}
+		bot.SendQueue.sendLog(bot, channel, priority, slack.MsgOptionText(msg, false), slack.MsgOptionAsUser(true))
+		return
+	}
+	err := bot.withRetry(func() error {
+		_, _, err := bot.api().PostMessageContext(bot.context(), channel, slack.MsgOptionText(msg, false), slack.MsgOptionAsUser(true))
+		return err
+	})
+	if err != nil {
+		bot.checkChannelUnavailable(channel, err)
+		log.Printf("Error sending message to log channel %s - %s\n", channel, err)
+	}
+}
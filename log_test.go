@@ -0,0 +1,90 @@
+package slackbot
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_logAt(t *testing.T) {
+	type fields struct {
+		DebugChannel string
+		ErrorChannel string
+		MinLevel     LogLevel
+	}
+	type args struct {
+		level LogLevel
+		msg   string
+	}
+	tests := []struct {
+		name        string
+		fields      fields
+		args        args
+		wantChannel string
+		wantText    string
+	}{
+		{
+			name:        "should send debug messages to the debug channel",
+			fields:      fields{DebugChannel: "debug", ErrorChannel: "errors"},
+			args:        args{level: LevelDebug, msg: "the message"},
+			wantChannel: "debug",
+			wantText:    "the message",
+		},
+		{
+			name:        "should prefix other levels",
+			fields:      fields{DebugChannel: "debug"},
+			args:        args{level: LevelWarn, msg: "the message"},
+			wantChannel: "debug",
+			wantText:    "[WARN] the message",
+		},
+		{
+			name:        "should send errors to the error channel",
+			fields:      fields{DebugChannel: "debug", ErrorChannel: "errors"},
+			args:        args{level: LevelError, msg: "the message"},
+			wantChannel: "errors",
+			wantText:    "[ERROR] the message",
+		},
+		{
+			name:        "should send errors to the debug channel without an error channel",
+			fields:      fields{DebugChannel: "debug"},
+			args:        args{level: LevelError, msg: "the message"},
+			wantChannel: "debug",
+			wantText:    "[ERROR] the message",
+		},
+		{
+			name:        "should redact secrets",
+			fields:      fields{DebugChannel: "debug"},
+			args:        args{level: LevelError, msg: "connecting with xoxp-123-456"},
+			wantChannel: "debug",
+			wantText:    "[ERROR] connecting with [REDACTED]",
+		},
+		{
+			name:   "should not send messages below the min level",
+			fields: fields{DebugChannel: "debug", MinLevel: LevelWarn},
+			args:   args{level: LevelInfo, msg: "the message"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotChannel, gotText string
+			bot := &Bot{
+				DebugChannel: tt.fields.DebugChannel,
+				ErrorChannel: tt.fields.ErrorChannel,
+				MinLevel:     tt.fields.MinLevel,
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						gotChannel, gotText = s, msgValues(opts...).Get("text")
+						return s, "ts", nil
+					},
+				},
+			}
+			bot.logAt(tt.args.level, tt.args.msg)
+			if gotChannel != tt.wantChannel {
+				t.Errorf("logAt() channel = %v, want %v", gotChannel, tt.wantChannel)
+			}
+			if gotText != tt.wantText {
+				t.Errorf("logAt() text = %v, want %v", gotText, tt.wantText)
+			}
+		})
+	}
+}
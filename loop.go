@@ -0,0 +1,202 @@
+package slackbot
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	defaultLoopWindow     = time.Minute
+	defaultLoopMaxRepeats = 2
+	botMessageSubType     = "bot_message"
+	loopWarningMessage    = "Ignoring messages from %s in %s, they look like a loop with the bot's replies"
+)
+
+type (
+	// LoopDetector stops the bot from getting into reply loops, where a reply from the bot, or a message
+	// from another bot, triggers a listener that replies again. A message is treated as part of a loop if
+	// it was sent by the bot itself, if it was sent by another bot and repeats one of the bot's recent
+	// replies in the channel, or if another bot sends the same message more than MaxRepeats times in the
+	// Window. Loops are broken by ignoring the message and sending a warning to the debug channel. Numbers
+	// and whitespace are ignored when messages are compared, so counters and timestamps don't hide a loop.
+	LoopDetector struct {
+		// Window is how long replies and messages are remembered, the default is one minute.
+		Window time.Duration
+		// MaxRepeats is how many times a bot can send the same message in the Window before it is
+		// treated as a loop, the default is 2.
+		MaxRepeats int
+
+		mu       sync.Mutex
+		replies  map[string]time.Time
+		messages map[string][]time.Time
+		warned   map[string]time.Time
+		clock    Clock
+	}
+)
+
+// noteReply remembers the text sent to the channel so it can be recognised if it comes back.
+func (ld *LoopDetector) noteReply(channel string, text string) {
+	if text == "" {
+		return
+	}
+	ld.mu.Lock()
+	defer ld.mu.Unlock()
+	if ld.replies == nil {
+		ld.replies = make(map[string]time.Time)
+	}
+	ld.replies[channel+" "+fingerprint(text)] = ld.now()
+}
+
+// isLoop returns true if the event is part of a loop. It must only be called for events that would
+// trigger a listener, so messages that are ignored anyway are not counted.
+func (ld *LoopDetector) isLoop(ev *slack.MessageEvent, self string) bool {
+	if ev.User == self && self != "" {
+		return true
+	}
+	if ev.BotID == "" && ev.SubType != botMessageSubType {
+		return false
+	}
+
+	ld.mu.Lock()
+	defer ld.mu.Unlock()
+	now := ld.now()
+	ld.prune(now)
+	key := ev.Channel + " " + fingerprint(ev.Text)
+	if _, ok := ld.replies[key]; ok {
+		return true
+	}
+	if ld.messages == nil {
+		ld.messages = make(map[string][]time.Time)
+	}
+	ld.messages[key] = append(ld.messages[key], now)
+	return len(ld.messages[key]) > ld.maxRepeats()
+}
+
+// shouldWarn returns true the first time a loop is found for the key in the Window.
+func (ld *LoopDetector) shouldWarn(key string) bool {
+	ld.mu.Lock()
+	defer ld.mu.Unlock()
+	if ld.warned == nil {
+		ld.warned = make(map[string]time.Time)
+	}
+	now := ld.now()
+	if t, ok := ld.warned[key]; ok && now.Sub(t) < ld.window() {
+		return false
+	}
+	ld.warned[key] = now
+	return true
+}
+
+// prune removes replies, messages and warnings older than the Window, it must be called while holding the lock.
+func (ld *LoopDetector) prune(now time.Time) {
+	cutoff := now.Add(-ld.window())
+	for key, t := range ld.replies {
+		if t.Before(cutoff) {
+			delete(ld.replies, key)
+		}
+	}
+	for key, t := range ld.warned {
+		if t.Before(cutoff) {
+			delete(ld.warned, key)
+		}
+	}
+	for key, times := range ld.messages {
+		i := 0
+		for i < len(times) && times[i].Before(cutoff) {
+			i++
+		}
+		if i == len(times) {
+			delete(ld.messages, key)
+		} else {
+			ld.messages[key] = times[i:]
+		}
+	}
+}
+
+func (ld *LoopDetector) window() time.Duration {
+	if ld.Window > 0 {
+		return ld.Window
+	}
+	return defaultLoopWindow
+}
+
+func (ld *LoopDetector) maxRepeats() int {
+	if ld.MaxRepeats > 0 {
+		return ld.MaxRepeats
+	}
+	return defaultLoopMaxRepeats
+}
+
+// detectLoop returns true if the event would trigger a listener and is part of a loop, and warns the
+// debug channel the first time the loop is found.
+func (bot *Bot) detectLoop(ev *slack.MessageEvent) bool {
+	ld := bot.LoopDetector
+	if ld == nil || !bot.matchesListener(ev) {
+		return false
+	}
+	var self string
+	if bot.userDetails != nil {
+		self = bot.userDetails.ID
+	}
+	if !ld.isLoop(ev, self) {
+		return false
+	}
+	source := ev.User
+	if source == "" {
+		source = ev.BotID
+	}
+	if ld.shouldWarn(ev.Channel + " " + source) {
+		bot.LogWarn(fmt.Sprintf(loopWarningMessage, source, ev.Channel))
+	}
+	return true
+}
+
+// matchesListener returns true if the event matches an indirect listener or has files for the file listeners.
+func (bot *Bot) matchesListener(ev *slack.MessageEvent) bool {
+	if len(ev.Files) > 0 && len(bot.FileListeners) > 0 {
+		return true
+	}
+	candidates := bot.indirectCandidates(ev.Text)
+	for i, l := range bot.IndirectListeners {
+		if candidates[i] && bot.listenerMatches(&l, ev) {
+			return true
+		}
+	}
+	return false
+}
+
+// noteReply records the text of a message sent by the bot for the LoopDetector.
+func (bot *Bot) noteReply(channel string, options ...slack.MsgOption) {
+	if bot.LoopDetector == nil {
+		return
+	}
+	if values, err := encodeMsgOptions(options...); err == nil {
+		bot.LoopDetector.noteReply(channel, values.Get("text"))
+	}
+}
+
+// fingerprint normalizes the text so messages that only differ by numbers, case or whitespace match.
+func fingerprint(text string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return '#'
+		}
+		return unicode.ToLower(r)
+	}, strings.Join(strings.Fields(text), " "))
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(normalized))
+	return fmt.Sprintf("%x", h.Sum64())
+}
+
+func (ld *LoopDetector) now() time.Time {
+	if ld.clock == nil {
+		return time.Now()
+	}
+	return ld.clock.Now()
+}
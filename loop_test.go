@@ -0,0 +1,140 @@
+package slackbot
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_detectLoop(t *testing.T) {
+	tests := []struct {
+		name        string
+		detector    *LoopDetector
+		replies     []string
+		events      []*slack.MessageEvent
+		wantHandled int
+		wantWarns   int
+	}{
+		{
+			name:        "should handle every message without a loop detector",
+			events:      repeatEvent(slack.Msg{Channel: "C1", User: "bot", Text: "ping"}, 3),
+			wantHandled: 3,
+		},
+		{
+			name:        "should ignore the bot's own messages and warn once",
+			detector:    &LoopDetector{},
+			events:      repeatEvent(slack.Msg{Channel: "C1", User: "bot", Text: "ping"}, 3),
+			wantHandled: 0,
+			wantWarns:   1,
+		},
+		{
+			name:        "should handle messages from users",
+			detector:    &LoopDetector{},
+			replies:     []string{"ping"},
+			events:      repeatEvent(slack.Msg{Channel: "C1", User: "U1", Text: "ping"}, 3),
+			wantHandled: 3,
+		},
+		{
+			name:        "should ignore another bot repeating a recent reply",
+			detector:    &LoopDetector{},
+			replies:     []string{"ping 1"},
+			events:      repeatEvent(slack.Msg{Channel: "C1", BotID: "B1", Text: "PING  2"}, 1),
+			wantHandled: 0,
+			wantWarns:   1,
+		},
+		{
+			name:        "should ignore another bot repeating a message too many times",
+			detector:    &LoopDetector{MaxRepeats: 2},
+			events:      repeatEvent(slack.Msg{Channel: "C1", BotID: "B1", Text: "ping"}, 4),
+			wantHandled: 2,
+			wantWarns:   1,
+		},
+		{
+			name:     "should handle another bot sending different messages",
+			detector: &LoopDetector{MaxRepeats: 1},
+			events: []*slack.MessageEvent{
+				{Msg: slack.Msg{Channel: "C1", BotID: "B1", Text: "ping a"}},
+				{Msg: slack.Msg{Channel: "C1", BotID: "B1", Text: "ping b"}},
+			},
+			wantHandled: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled, warns := 0, 0
+			bot := &Bot{
+				LoopDetector: tt.detector,
+				DebugChannel: "debug",
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						if strings.Contains(msgValues(opts...).Get("text"), "look like a loop") {
+							warns++
+						}
+						return s, "ts", nil
+					},
+				},
+				IndirectListeners: []Listener{
+					{
+						Regex: regexp.MustCompile("(?i)ping"),
+						Handler: func(bot *Bot, ev *slack.MessageEvent) {
+							handled++
+						},
+					},
+				},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			for _, r := range tt.replies {
+				_, _, _ = bot.Reply("C1", r)
+			}
+			for _, ev := range tt.events {
+				bot.processMessage(ev)
+			}
+			if handled != tt.wantHandled {
+				t.Errorf("processMessage() handled %d events, want %d", handled, tt.wantHandled)
+			}
+			if warns != tt.wantWarns {
+				t.Errorf("processMessage() sent %d warnings, want %d", warns, tt.wantWarns)
+			}
+		})
+	}
+}
+
+func TestLoopDetector_prune(t *testing.T) {
+	ld := &LoopDetector{Window: 10 * time.Millisecond, MaxRepeats: 1}
+	ev := &slack.MessageEvent{Msg: slack.Msg{Channel: "C1", BotID: "B1", Text: "ping"}}
+	ld.noteReply("C1", "pong")
+	if ld.isLoop(ev, "bot") {
+		t.Fatalf("isLoop() = true for the first message")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if ld.isLoop(ev, "bot") {
+		t.Errorf("isLoop() = true after the Window passed")
+	}
+	if len(ld.replies) != 0 {
+		t.Errorf("replies were not pruned after the Window passed")
+	}
+}
+
+func Test_fingerprint(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want bool
+	}{
+		{name: "should match the same text", a: "hello", b: "hello", want: true},
+		{name: "should ignore case and whitespace", a: "Hello  World ", b: "hello world", want: true},
+		{name: "should ignore numbers", a: "count 1", b: "count 2", want: true},
+		{name: "should not match different text", a: "hello", b: "goodbye", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fingerprint(tt.a) == fingerprint(tt.b); got != tt.want {
+				t.Errorf("fingerprint(%q) == fingerprint(%q) is %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
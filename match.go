@@ -0,0 +1,147 @@
+package slackbot
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const channelIDsTTL = 10 * time.Minute
+
+// MatchConditions restrict the messages an indirect listener matches beyond its Regex, so it can react to
+// messages posted by integrations, such as a P1 alert posted by the Datadog bot in #alerts. Every condition
+// that is set must match. A listener with Conditions doesn't need a Regex, it then matches every message that
+// meets the conditions.
+//
+// Example:
+//
+//	slackbot.Listener{
+//		Usage: "page the on call engineer for P1 alerts",
+//		Conditions: &slackbot.MatchConditions{
+//			BotIDs:   []string{"B0DATADOG"},
+//			Channels: []string{"alerts"},
+//			Fields:   map[string]*regexp.Regexp{"Priority": regexp.MustCompile(`^P1$`)},
+//		},
+//		ContextHandler: pageOnCall,
+//	}
+type MatchConditions struct {
+	// BotIDs only match messages posted by one of the bots, by the bot_id of the integration. Bots in BotIDs
+	// are handled by the listener even if the bot IgnoreBotMessages.
+	BotIDs []string
+
+	// Users only match messages sent by one of the users, by ID.
+	Users []string
+
+	// Channels only match messages sent in one of the channels, identified by name or ID.
+	Channels []string
+
+	// Content is matched against all of the message's text including its attachments and blocks, see
+	// MessageContent.AllText.
+	Content *regexp.Regexp
+
+	// Fields are matched against the values of the message's attachment fields with the titles, ignoring the
+	// case of the titles. Messages without one of the fields don't match.
+	Fields map[string]*regexp.Regexp
+}
+
+// listenerMatches returns true if the message matches the indirect listener's Regex or one of its Aliases,
+// and its Conditions.
+func (bot *Bot) listenerMatches(l *Listener, ev *slack.MessageEvent) bool {
+	c := l.Conditions
+	if c == nil {
+		return l.matches(bot, ev.Text)
+	}
+	if (l.Regex != nil || len(l.Aliases) > 0) && !l.matches(bot, ev.Text) {
+		return false
+	}
+	return c.matches(bot, ev)
+}
+
+// matches returns true if the message meets all of the conditions, empty conditions don't match any message.
+func (c *MatchConditions) matches(bot *Bot, ev *slack.MessageEvent) bool {
+	if len(c.BotIDs) == 0 && len(c.Users) == 0 && len(c.Channels) == 0 && c.Content == nil && len(c.Fields) == 0 {
+		return false
+	}
+	if len(c.BotIDs) > 0 && (ev.BotID == "" || !containsString(c.BotIDs, ev.BotID)) {
+		return false
+	}
+	if len(c.Users) > 0 && (ev.User == "" || !containsString(c.Users, ev.User)) {
+		return false
+	}
+	if len(c.Channels) > 0 && !bot.inChannels(c.Channels, ev.Channel) {
+		return false
+	}
+	if c.Content == nil && len(c.Fields) == 0 {
+		return true
+	}
+	content := ParseMessageContent(ev)
+	if c.Content != nil && !c.Content.MatchString(content.AllText()) {
+		return false
+	}
+	for title, re := range c.Fields {
+		value, ok := content.Field(title)
+		if !ok || (re != nil && !re.MatchString(value)) {
+			return false
+		}
+	}
+	return true
+}
+
+// inChannels returns true if the channel is one of the channels, which can be identified by name, ID or
+// mention. Names are resolved to IDs through a cache, so they aren't looked up for every message.
+func (bot *Bot) inChannels(channels []string, channel string) bool {
+	for _, c := range channels {
+		if c == channel || bot.cachedChannelID(c) == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// cachedChannelID returns the ID of the channel with the name, ID or mention, or "" if it can't be found. The
+// result is cached for ten minutes, including channels that weren't found.
+func (bot *Bot) cachedChannelID(identifier string) string {
+	c := bot.channelIDCache()
+	if id, ok := c.get(identifier); ok {
+		return id.(string)
+	}
+	id, err := bot.channelID(identifier)
+	if err != nil {
+		id = ""
+	}
+	c.set(identifier, id)
+	return id
+}
+
+func (bot *Bot) channelIDCache() *cache {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.channelIDs == nil {
+		bot.channelIDs = newCache(channelIDsTTL, bot.clock())
+	}
+	return bot.channelIDs
+}
+
+// allowedByConditions returns true if an indirect listener's Conditions name the bot that posted the message.
+func (bot *Bot) allowedByConditions(ev *slack.MessageEvent) bool {
+	if ev.BotID == "" {
+		return false
+	}
+	for _, l := range bot.IndirectListeners {
+		if l.Conditions != nil && containsString(l.Conditions.BotIDs, ev.BotID) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString returns true if s is one of the values.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
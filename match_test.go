@@ -0,0 +1,173 @@
+package slackbot
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func TestBot_listenerMatches(t *testing.T) {
+	alert := func(t *testing.T) *slack.MessageEvent {
+		ev := alertMessage(t)
+		ev.Channel = "C1"
+		return ev
+	}
+	tests := []struct {
+		name     string
+		listener Listener
+		ev       func(t *testing.T) *slack.MessageEvent
+		want     bool
+	}{
+		{
+			name:     "should match the text without conditions",
+			listener: Listener{Regex: regexp.MustCompile(`Triggered`)},
+			ev:       alert,
+			want:     true,
+		},
+		{
+			name:     "should not match empty conditions",
+			listener: Listener{Conditions: &MatchConditions{}},
+			ev:       alert,
+		},
+		{
+			name: "should match an alert from the bot in the channel by name",
+			listener: Listener{Conditions: &MatchConditions{
+				BotIDs:   []string{"B1"},
+				Channels: []string{"alerts"},
+				Fields:   map[string]*regexp.Regexp{"priority": regexp.MustCompile(`^P1$`)},
+			}},
+			ev:   alert,
+			want: true,
+		},
+		{
+			name:     "should match the content of attachments and blocks",
+			listener: Listener{Conditions: &MatchConditions{Content: regexp.MustCompile(`api latency > 2s`)}},
+			ev:       alert,
+			want:     true,
+		},
+		{
+			name: "should require the Regex to match as well as the conditions",
+			listener: Listener{
+				Regex:      regexp.MustCompile(`^Resolved`),
+				Conditions: &MatchConditions{BotIDs: []string{"B1"}},
+			},
+			ev: alert,
+		},
+		{
+			name:     "should not match messages from other bots",
+			listener: Listener{Conditions: &MatchConditions{BotIDs: []string{"B2"}}},
+			ev:       alert,
+		},
+		{
+			name:     "should not match messages from other users",
+			listener: Listener{Conditions: &MatchConditions{Users: []string{"U1"}}},
+			ev:       alert,
+		},
+		{
+			name:     "should not match messages in other channels",
+			listener: Listener{Conditions: &MatchConditions{Channels: []string{"C2", "general"}}},
+			ev:       alert,
+		},
+		{
+			name:     "should not match a field with another value",
+			listener: Listener{Conditions: &MatchConditions{Fields: map[string]*regexp.Regexp{"Priority": regexp.MustCompile(`^P2$`)}}},
+			ev:       alert,
+		},
+		{
+			name:     "should not match a missing field",
+			listener: Listener{Conditions: &MatchConditions{Fields: map[string]*regexp.Regexp{"Owner": nil}}},
+			ev:       alert,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{API: &mockAPI{
+				getChannel: func(identifier string) (slack.Channel, error) {
+					switch identifier {
+					case "alerts":
+						return slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}}}, nil
+					case "general":
+						return slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C3"}}}, nil
+					}
+					return slack.Channel{}, errors.New("channel_not_found")
+				},
+			}}
+			if got := bot.listenerMatches(&tt.listener, tt.ev(t)); got != tt.want {
+				t.Errorf("listenerMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_processMessage_conditions(t *testing.T) {
+	tests := []struct {
+		name        string
+		botID       string
+		wantHandled bool
+	}{
+		{
+			name:        "should handle messages from the bots in the conditions when bot messages are ignored",
+			botID:       "B1",
+			wantHandled: true,
+		},
+		{
+			name:  "should still ignore other bots",
+			botID: "B2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled := false
+			bot := &Bot{
+				IgnoreBotMessages: true,
+				API:               &mockAPI{},
+				IndirectListeners: []Listener{
+					{
+						Conditions: &MatchConditions{
+							Fields: map[string]*regexp.Regexp{"Priority": regexp.MustCompile(`^P1$`)},
+						},
+						Handler: func(bot *Bot, ev *slack.MessageEvent) {
+							handled = true
+						},
+					},
+					{
+						Conditions: &MatchConditions{BotIDs: []string{"B1"}},
+						Handler:    func(bot *Bot, ev *slack.MessageEvent) {},
+					},
+				},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			bot.init()
+			ev := alertMessage(t)
+			ev.BotID = tt.botID
+			bot.processMessage(ev)
+			if handled != tt.wantHandled {
+				t.Errorf("handled = %v, want %v", handled, tt.wantHandled)
+			}
+		})
+	}
+}
+
+func TestBot_inChannels_cached(t *testing.T) {
+	lookups := 0
+	bot := &Bot{API: &mockAPI{
+		getChannel: func(identifier string) (slack.Channel, error) {
+			lookups++
+			if identifier == "alerts" {
+				return slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}}}, nil
+			}
+			return slack.Channel{}, errors.New("channel_not_found")
+		},
+	}}
+	for _, channel := range []string{"C1", "C2", "C1", "C2"} {
+		want := channel == "C1"
+		if got := bot.inChannels([]string{"missing", "alerts"}, channel); got != want {
+			t.Errorf("inChannels(%s) = %v, want %v", channel, got, want)
+		}
+	}
+	if lookups != 2 {
+		t.Errorf("GetChannel() called %d times, want each name looked up once", lookups)
+	}
+}
@@ -0,0 +1,77 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"net/url"
+
+	"github.com/slack-go/slack"
+)
+
+// encodeMsgOptions applies the message options and returns the values that would be sent to slack.
+// Message options are functions so they can't be stored, the values can be and decoded back into
+// options with decodeMsgOptions.
+func encodeMsgOptions(options ...slack.MsgOption) (url.Values, error) {
+	_, values, err := slack.UnsafeApplyMsgOptions("", "", "", options...)
+	if err != nil {
+		return nil, err
+	}
+	values.Del("token")
+	values.Del("channel")
+	return values, nil
+}
+
+// decodeMsgOptions rebuilds message options from values returned by encodeMsgOptions.
+func decodeMsgOptions(values url.Values) ([]slack.MsgOption, error) {
+	var options []slack.MsgOption
+	if v, ok := values["text"]; ok {
+		options = append(options, slack.MsgOptionText(v[0], false))
+	}
+	if v := values.Get("attachments"); v != "" {
+		var attachments []slack.Attachment
+		if err := json.Unmarshal([]byte(v), &attachments); err != nil {
+			return nil, err
+		}
+		options = append(options, slack.MsgOptionAttachments(attachments...))
+	}
+	if v := values.Get("blocks"); v != "" {
+		var blocks slack.Blocks
+		if err := json.Unmarshal([]byte(v), &blocks); err != nil {
+			return nil, err
+		}
+		options = append(options, slack.MsgOptionBlocks(blocks.BlockSet...))
+	}
+	if v := values.Get("thread_ts"); v != "" {
+		options = append(options, slack.MsgOptionTS(v))
+	}
+	if values.Get("reply_broadcast") == "true" {
+		options = append(options, slack.MsgOptionBroadcast())
+	}
+	if v := values.Get("as_user"); v != "" {
+		options = append(options, slack.MsgOptionAsUser(v == "true"))
+	}
+	if v := values.Get("username"); v != "" {
+		options = append(options, slack.MsgOptionUsername(v))
+	}
+	if v := values.Get("icon_url"); v != "" {
+		options = append(options, slack.MsgOptionIconURL(v))
+	}
+	if v := values.Get("icon_emoji"); v != "" {
+		options = append(options, slack.MsgOptionIconEmoji(v))
+	}
+	switch values.Get("unfurl_links") {
+	case "true":
+		options = append(options, slack.MsgOptionEnableLinkUnfurl())
+	case "false":
+		options = append(options, slack.MsgOptionDisableLinkUnfurl())
+	}
+	if values.Get("unfurl_media") == "false" {
+		options = append(options, slack.MsgOptionDisableMediaUnfurl())
+	}
+	if values.Get("mrkdwn") == "false" {
+		options = append(options, slack.MsgOptionDisableMarkdown())
+	}
+	if v := values.Get("parse"); v != "" {
+		options = append(options, slack.MsgOptionParse(v == "full"))
+	}
+	return options, nil
+}
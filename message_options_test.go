@@ -0,0 +1,56 @@
+package slackbot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func Test_decodeMsgOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []slack.MsgOption
+	}{
+		{
+			name:    "should round trip text",
+			options: []slack.MsgOption{slack.MsgOptionText("hello & goodbye", false)},
+		},
+		{
+			name: "should round trip attachments and thread options",
+			options: []slack.MsgOption{
+				slack.MsgOptionText("text", false),
+				slack.MsgOptionAttachments(slack.Attachment{Pretext: "pre", Text: "text"}),
+				slack.MsgOptionTS("123.456"),
+				slack.MsgOptionBroadcast(),
+				slack.MsgOptionAsUser(true),
+			},
+		},
+		{
+			name: "should round trip blocks and persona options",
+			options: []slack.MsgOption{
+				slack.MsgOptionBlocks(slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "*hi*", false, false), nil, nil)),
+				slack.MsgOptionUsername("DeployBot"),
+				slack.MsgOptionIconEmoji(":rocket:"),
+				slack.MsgOptionDisableLinkUnfurl(),
+				slack.MsgOptionDisableMarkdown(),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := encodeMsgOptions(tt.options...)
+			if err != nil {
+				t.Fatalf("encodeMsgOptions() error = %v", err)
+			}
+			decoded, err := decodeMsgOptions(want)
+			if err != nil {
+				t.Fatalf("decodeMsgOptions() error = %v", err)
+			}
+			got, _ := encodeMsgOptions(decoded...)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("decodeMsgOptions() got = %v, want %v", got, want)
+			}
+		})
+	}
+}
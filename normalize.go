@@ -0,0 +1,95 @@
+package slackbot
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	slackTokenRegex   = regexp.MustCompile(`<([^<>]*)>`)
+	escapedTokenRegex = regexp.MustCompile(`&lt;[@#!]`)
+	spaceRegex        = regexp.MustCompile(`[ \t\x{00a0}]+`)
+
+	punctuationReplacer = strings.NewReplacer(
+		"‘", "'", "’", "'", "‚", "'", "′", "'",
+		"“", `"`, "”", `"`, "„", `"`, "″", `"`,
+		"–", "-", "—", "--", "…", "...",
+	)
+)
+
+// TextNormalizer rewrites the text of an incoming message before it is matched against the bot's listeners
+// and exchanges.
+type TextNormalizer func(text string) string
+
+// NormalizeSlackText removes the formatting slack adds to messages so they can be matched with simple
+// regular expressions. Links are replaced with their label, or the url if they have no label, channel links
+// become #channel, user group and special mentions become @name, and user mentions with a label are reduced to
+// <@USERID> so they match the mentions of users without labels. The &amp; &lt; and &gt; entities are
+// unescaped, smart quotes, dashes and ellipses from mobile keyboards are replaced with plain characters,
+// and runs of spaces are collapsed. Text the user typed that looks like a mention, such as &lt;@USERID&gt;,
+// stays escaped so it can't be mistaken for a real mention of the bot.
+func NormalizeSlackText(text string) string {
+	var buf strings.Builder
+	last := 0
+	for _, loc := range slackTokenRegex.FindAllStringIndex(text, -1) {
+		buf.WriteString(unescapeText(text[last:loc[0]]))
+		buf.WriteString(rewriteSlackToken(text[loc[0]+1 : loc[1]-1]))
+		last = loc[1]
+	}
+	buf.WriteString(unescapeText(text[last:]))
+	text = punctuationReplacer.Replace(buf.String())
+	text = spaceRegex.ReplaceAllString(text, " ")
+	return strings.TrimSpace(text)
+}
+
+// rewriteSlackToken returns the plain text of the inside of a <...> token.
+func rewriteSlackToken(inner string) string {
+	label := ""
+	if i := strings.Index(inner, "|"); i >= 0 {
+		inner, label = inner[:i], inner[i+1:]
+	}
+	switch {
+	case strings.HasPrefix(inner, "@"):
+		return "<" + inner + ">"
+	case strings.HasPrefix(inner, "#"):
+		if label != "" {
+			return "#" + unescapeText(label)
+		}
+		return inner
+	case strings.HasPrefix(inner, "!"):
+		if label != "" {
+			return "@" + strings.TrimPrefix(unescapeText(label), "@")
+		}
+		return "@" + strings.TrimPrefix(inner, "!")
+	case label != "":
+		return unescapeText(label)
+	default:
+		return unescapeText(inner)
+	}
+}
+
+// unescapeText unescapes the entities in text outside of slack's tokens, except for a &lt; that would start a
+// mention, channel link or special mention.
+func unescapeText(text string) string {
+	var buf strings.Builder
+	last := 0
+	for _, loc := range escapedTokenRegex.FindAllStringIndex(text, -1) {
+		buf.WriteString(html.UnescapeString(text[last:loc[0]]))
+		buf.WriteString(text[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	buf.WriteString(html.UnescapeString(text[last:]))
+	return buf.String()
+}
+
+// normalizeText applies NormalizeSlackText if NormalizeText is set, then each of the TextNormalizers.
+func (bot *Bot) normalizeText(text string) string {
+	if bot.NormalizeText {
+		text = NormalizeSlackText(text)
+	}
+	for _, n := range bot.TextNormalizers {
+		text = n(text)
+	}
+	return text
+}
@@ -0,0 +1,103 @@
+package slackbot
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestNormalizeSlackText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "should leave plain text alone", text: "deploy api", want: "deploy api"},
+		{name: "should replace links with the url", text: "check <https://example.com>", want: "check https://example.com"},
+		{name: "should replace labelled links with the label", text: "check <https://example.com|example.com>", want: "check example.com"},
+		{name: "should replace mailto links with the label", text: "email <mailto:a@b.com|a@b.com>", want: "email a@b.com"},
+		{name: "should reduce labelled user mentions", text: "<@U123|bob> hi", want: "<@U123> hi"},
+		{name: "should keep user mentions", text: "<@U123> hi", want: "<@U123> hi"},
+		{name: "should replace channel links", text: "post in <#C123|general>", want: "post in #general"},
+		{name: "should replace special mentions", text: "<!here> lunch", want: "@here lunch"},
+		{name: "should replace user group mentions", text: "<!subteam^S123|@oncall> help", want: "@oncall help"},
+		{name: "should unescape entities", text: "a &amp; b &lt;c&gt;", want: "a & b <c>"},
+		{name: "should unescape entities in links", text: "<https://example.com?a=1&amp;b=2>", want: "https://example.com?a=1&b=2"},
+		{name: "should not turn typed mentions into mentions", text: "&lt;@U123&gt; deploy", want: "&lt;@U123> deploy"},
+		{name: "should not turn typed mentions in labels into mentions", text: "<https://example.com|&lt;!here&gt;>", want: "&lt;!here>"},
+		{name: "should replace smart quotes", text: "say “hello” it’s me", want: `say "hello" it's me`},
+		{name: "should replace dashes and ellipses", text: "deploy —force…", want: "deploy --force..."},
+		{name: "should collapse spaces", text: "  deploy \t  api now ", want: "deploy api now"},
+		{name: "should keep new lines", text: "line one\nline two", want: "line one\nline two"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeSlackText(tt.text); got != tt.want {
+				t.Errorf("NormalizeSlackText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_normalizeText(t *testing.T) {
+	tests := []struct {
+		name        string
+		normalize   bool
+		normalizers []TextNormalizer
+		text        string
+		want        bool
+	}{
+		{
+			name: "should not normalize by default",
+			text: "<@myID> deploy   “api”",
+			want: false,
+		},
+		{
+			name:      "should match a direct message after normalizing",
+			normalize: true,
+			text:      "<@myID> deploy   “api”",
+			want:      true,
+		},
+		{
+			name:      "should not match a typed mention of the bot",
+			normalize: true,
+			text:      `&lt;@myID&gt; deploy "api"`,
+			want:      false,
+		},
+		{
+			name:        "should apply custom normalizers",
+			normalizers: []TextNormalizer{strings.NewReplacer("ship", "deploy").Replace},
+			text:        `<@myID> ship "api"`,
+			want:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled := false
+			bot := &Bot{
+				NormalizeText:   tt.normalize,
+				TextNormalizers: tt.normalizers,
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						return s, "ts", nil
+					},
+				},
+				DirectListeners: []Listener{
+					{
+						Regex: regexp.MustCompile(`^deploy "api"$`),
+						Handler: func(bot *Bot, ev *slack.MessageEvent) {
+							handled = true
+						},
+					},
+				},
+				userDetails: &slack.UserDetails{ID: "myID"},
+			}
+			bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{User: "U1", Text: tt.text}})
+			if handled != tt.want {
+				t.Errorf("processMessage() handled = %v, want %v", handled, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package slackbot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	"github.com/slack-go/slack"
+)
+
+const (
+	onboardingStoreKey      = "onboarding"
+	onboardingCheckInterval = time.Minute
+)
+
+type (
+	// Onboarding is a series of direct messages sent to a user after they join the team, or after they join
+	// one of the Channels if it has any. Each step is sent once its After duration has passed since the user
+	// joined, so a sequence can send a welcome straight away, tips the next day and so on. Progress is saved
+	// in the bot's Store, so steps that were already sent aren't sent again when the bot restarts. Name
+	// identifies the onboarding in the Store and must be unique.
+	Onboarding struct {
+		Name     string
+		Channels []string
+		Steps    []OnboardingStep
+	}
+
+	// OnboardingStep is a message in an Onboarding sequence. If the step has an Exchange, it is started in the
+	// thread of the Message so the user can reply to it, for example to ask which team they have joined.
+	OnboardingStep struct {
+		After    time.Duration
+		Message  string
+		Exchange *Exchange
+	}
+
+	// onboardingProgress is a user's progress through an onboarding, Sent is the number of steps sent.
+	onboardingProgress struct {
+		Onboarding string
+		User       string
+		Joined     time.Time
+		Sent       int
+	}
+)
+
+// StartOnboarding starts the onboarding with the name for the user, it is called automatically when users join
+// the team or the onboarding's Channels. Steps that are due straight away are sent before it returns. Starting
+// an onboarding for a user who has already started it does nothing.
+func (bot *Bot) StartOnboarding(name string, user string) error {
+	if bot.onboarding(name) == nil {
+		return errors.Errorf("there is no onboarding named %s", name)
+	}
+	bot.onboardingMu.Lock()
+	progress := bot.loadOnboardingProgress()
+	for _, p := range progress {
+		if p.Onboarding == name && p.User == user {
+			bot.onboardingMu.Unlock()
+			return nil
+		}
+	}
+	progress = append(progress, onboardingProgress{Onboarding: name, User: user, Joined: bot.clock().Now()})
+	err := bot.store().Put(onboardingStoreKey, progress)
+	bot.onboardingMu.Unlock()
+	if err != nil {
+		return errors.Wrapf(err, "unable to start onboarding %s for %s", name, user)
+	}
+	bot.sendOnboardingSteps()
+	return nil
+}
+
+// sendOnboardingSteps sends the steps that are due to every user with an onboarding in progress. If a step
+// can't be sent it is tried again the next time the steps are checked.
+func (bot *Bot) sendOnboardingSteps() {
+	defer bot.recoverPanic(ErrorInfo{Source: ErrorSourceScheduledTask}, nil)
+	bot.onboardingMu.Lock()
+	defer bot.onboardingMu.Unlock()
+	progress := bot.loadOnboardingProgress()
+	now := bot.clock().Now()
+	changed := false
+	for i := range progress {
+		p := &progress[i]
+		o := bot.onboarding(p.Onboarding)
+		if o == nil {
+			continue
+		}
+		for p.Sent < len(o.Steps) && !now.Before(p.Joined.Add(o.Steps[p.Sent].After)) {
+			if err := bot.sendOnboardingStep(o, p.Sent, p.User); err != nil {
+				bot.LogError(fmt.Sprintf("error sending step %d of onboarding %s to %s - %s", p.Sent+1, o.Name, p.User, err))
+				break
+			}
+			p.Sent++
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	if err := bot.store().Put(onboardingStoreKey, progress); err != nil {
+		bot.LogError(fmt.Sprintf("unable to save onboarding progress - %s", err))
+	}
+}
+
+// sendOnboardingStep sends the step to the user in a direct message and starts its exchange.
+func (bot *Bot) sendOnboardingStep(o *Onboarding, step int, user string) error {
+	s := o.Steps[step]
+	channel, timestamp, err := bot.Reply(user, s.Message)
+	if err != nil {
+		return err
+	}
+	if s.Exchange != nil {
+		bot.startExchange(&slack.MessageEvent{Msg: slack.Msg{Channel: channel, User: user, Timestamp: timestamp}}, s.Exchange)
+	}
+	return nil
+}
+
+// handleTeamJoin starts the onboardings without Channels for a user who joined the team.
+func (bot *Bot) handleTeamJoin(user string) {
+	for _, o := range bot.Onboarding {
+		if len(o.Channels) == 0 {
+			bot.startOnboarding(o.Name, user)
+		}
+	}
+}
+
+// startChannelOnboardings starts the onboardings for the channel a user joined.
+func (bot *Bot) startChannelOnboardings(user string, channel string) {
+	for _, o := range bot.Onboarding {
+		for _, c := range o.Channels {
+			if c == channel {
+				bot.startOnboarding(o.Name, user)
+				break
+			}
+		}
+	}
+}
+
+func (bot *Bot) startOnboarding(name string, user string) {
+	if err := bot.StartOnboarding(name, user); err != nil {
+		bot.LogError(err.Error())
+	}
+}
+
+// scheduleOnboarding checks for onboarding steps that are due every minute.
+func (bot *Bot) scheduleOnboarding(s *scheduler) {
+	if len(bot.Onboarding) == 0 {
+		return
+	}
+	s.Schedule(cron.Every(onboardingCheckInterval), cron.FuncJob(bot.sendOnboardingSteps))
+}
+
+// onboarding returns the bot's onboarding with the name.
+func (bot *Bot) onboarding(name string) *Onboarding {
+	for i := range bot.Onboarding {
+		if bot.Onboarding[i].Name == name {
+			return &bot.Onboarding[i]
+		}
+	}
+	return nil
+}
+
+// loadOnboardingProgress returns the onboarding progress in the Store, onboardingMu must be held.
+func (bot *Bot) loadOnboardingProgress() []onboardingProgress {
+	var progress []onboardingProgress
+	_ = bot.store().Get(onboardingStoreKey, &progress)
+	return progress
+}
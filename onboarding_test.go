@@ -0,0 +1,188 @@
+package slackbot
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func testOnboarding() []Onboarding {
+	return []Onboarding{
+		{
+			Name: "welcome",
+			Steps: []OnboardingStep{
+				{Message: "Welcome to the team!"},
+				{After: 24 * time.Hour, Message: "Here are some tips."},
+				{After: 48 * time.Hour, Message: "How are you settling in?"},
+			},
+		},
+		{
+			Name:     "deploys",
+			Channels: []string{"C1"},
+			Steps:    []OnboardingStep{{Message: "Welcome to #deploys."}},
+		},
+	}
+}
+
+func TestBot_StartOnboarding(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress []onboardingProgress
+		start    string
+		wantMsgs []string
+		wantErr  bool
+	}{
+		{
+			name:     "should send the steps that are due straight away",
+			start:    "welcome",
+			wantMsgs: []string{"Welcome to the team!"},
+		},
+		{
+			name:     "should not start an onboarding the user has already started",
+			progress: []onboardingProgress{{Onboarding: "welcome", User: "U1", Joined: time.Now(), Sent: 1}},
+			start:    "welcome",
+		},
+		{
+			name:    "should return an error for an unknown onboarding",
+			start:   "unknown",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msgs []string
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						msgs = append(msgs, msgValues(opts...).Get("text"))
+						return "D1", "ts", nil
+					},
+				},
+				Onboarding: testOnboarding(),
+			}
+			if tt.progress != nil {
+				_ = bot.store().Put(onboardingStoreKey, tt.progress)
+			}
+			if err := bot.StartOnboarding(tt.start, "U1"); (err != nil) != tt.wantErr {
+				t.Fatalf("StartOnboarding() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(msgs, tt.wantMsgs) {
+				t.Errorf("StartOnboarding() sent %q, want %q", msgs, tt.wantMsgs)
+			}
+		})
+	}
+}
+
+func TestBot_sendOnboardingSteps(t *testing.T) {
+	tests := []struct {
+		name     string
+		joined   time.Duration
+		sent     int
+		failPost bool
+		wantMsgs []string
+		wantSent int
+	}{
+		{
+			name:     "should send the steps that are due",
+			joined:   25 * time.Hour,
+			sent:     1,
+			wantMsgs: []string{"Here are some tips."},
+			wantSent: 2,
+		},
+		{
+			name:     "should send every step that is due after a restart",
+			joined:   72 * time.Hour,
+			sent:     1,
+			wantMsgs: []string{"Here are some tips.", "How are you settling in?"},
+			wantSent: 3,
+		},
+		{
+			name:     "should not send steps that aren't due",
+			joined:   time.Hour,
+			sent:     1,
+			wantSent: 1,
+		},
+		{
+			name:     "should try a step again when it can't be sent",
+			joined:   25 * time.Hour,
+			sent:     1,
+			failPost: true,
+			wantSent: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msgs []string
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						if tt.failPost {
+							return "", "", errors.New("channel_not_found")
+						}
+						msgs = append(msgs, msgValues(opts...).Get("text"))
+						return "D1", "ts", nil
+					},
+				},
+				Onboarding: testOnboarding(),
+			}
+			_ = bot.store().Put(onboardingStoreKey, []onboardingProgress{
+				{Onboarding: "welcome", User: "U1", Joined: time.Now().Add(-tt.joined), Sent: tt.sent},
+			})
+			bot.sendOnboardingSteps()
+			if !reflect.DeepEqual(msgs, tt.wantMsgs) {
+				t.Errorf("sendOnboardingSteps() sent %q, want %q", msgs, tt.wantMsgs)
+			}
+			if progress := bot.loadOnboardingProgress(); progress[0].Sent != tt.wantSent {
+				t.Errorf("sendOnboardingSteps() saved %d steps sent, want %d", progress[0].Sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestBot_handleMemberJoinedChannel(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     string
+		channel  string
+		wantMsgs []string
+	}{
+		{
+			name:     "should start the onboarding for the channel",
+			user:     "U1",
+			channel:  "C1",
+			wantMsgs: []string{"Welcome to #deploys."},
+		},
+		{
+			name:    "should ignore other channels",
+			user:    "U1",
+			channel: "C2",
+		},
+		{
+			name:    "should ignore the bot joining the channel",
+			user:    "bot",
+			channel: "C1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msgs []string
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						msgs = append(msgs, msgValues(opts...).Get("text"))
+						return "D1", "ts", nil
+					},
+				},
+				Onboarding:  testOnboarding(),
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			bot.handleMemberJoinedChannel(tt.user, tt.channel)
+			if !reflect.DeepEqual(msgs, tt.wantMsgs) {
+				t.Errorf("handleMemberJoinedChannel() sent %q, want %q", msgs, tt.wantMsgs)
+			}
+		})
+	}
+}
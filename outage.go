@@ -0,0 +1,134 @@
+package slackbot
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	defaultOutageThreshold  = time.Minute
+	defaultOutageBufferSize = 100
+	outageStartedMessage    = "Slack has been disconnected since %s, messages will be queued"
+	outageRecoveredMessage  = "Slack reconnected after %s, sending %d queued messages"
+	outageDroppedMessage    = "Dropped a queued message for %s, the outage buffer is full"
+)
+
+type (
+	// OutageDetector handles slack outages and reconnect storms when the bot is connected with RTM. If the
+	// connection isn't recovered within the Threshold, one minute by default, OnOutage is called and the
+	// messages the bot sends, such as the replies of scheduled tasks, are queued instead of failing one by one. When the bot reconnects
+	// OnRecovery is called and the queued messages are sent. No more than BufferSize messages are queued,
+	// 100 by default, the oldest are dropped when it is full.
+	OutageDetector struct {
+		Threshold  time.Duration
+		BufferSize int
+		OnOutage   func(bot *Bot, since time.Time)
+		OnRecovery func(bot *Bot, downtime time.Duration)
+
+		mu           sync.Mutex
+		disconnected time.Time
+		outage       bool
+		timer        Timer
+		queue        []queuedMessage
+	}
+
+	queuedMessage struct {
+		priority Priority
+		channel  string
+		options  []slack.MsgOption
+	}
+)
+
+// handleDisconnect starts timing the disconnect, the outage begins if the bot hasn't reconnected by the Threshold.
+func (od *OutageDetector) handleDisconnect(bot *Bot) {
+	od.mu.Lock()
+	defer od.mu.Unlock()
+	if !od.disconnected.IsZero() {
+		return
+	}
+	od.disconnected = bot.clock().Now()
+	od.timer = bot.clock().AfterFunc(od.threshold(), func() { od.startOutage(bot) })
+}
+
+func (od *OutageDetector) startOutage(bot *Bot) {
+	od.mu.Lock()
+	if od.disconnected.IsZero() || od.outage {
+		od.mu.Unlock()
+		return
+	}
+	od.outage = true
+	since := od.disconnected
+	od.mu.Unlock()
+	bot.LogWarn(fmt.Sprintf(outageStartedMessage, since.Format(time.RFC3339)))
+	if od.OnOutage != nil {
+		od.OnOutage(bot, since)
+	}
+}
+
+// handleConnect ends the outage, calling OnRecovery and sending the queued messages.
+func (od *OutageDetector) handleConnect(bot *Bot) {
+	od.mu.Lock()
+	if od.timer != nil {
+		od.timer.Stop()
+		od.timer = nil
+	}
+	outage, since, queue := od.outage, od.disconnected, od.queue
+	od.disconnected, od.outage, od.queue = time.Time{}, false, nil
+	od.mu.Unlock()
+	if !outage {
+		return
+	}
+	downtime := bot.clock().Now().Sub(since)
+	bot.LogInfo(fmt.Sprintf(outageRecoveredMessage, downtime.Round(time.Second), len(queue)))
+	if od.OnRecovery != nil {
+		od.OnRecovery(bot, downtime)
+	}
+	for _, m := range queue {
+		if _, _, err := bot.reply(bot.context(), m.priority, m.channel, m.options); err != nil {
+			bot.LogError(fmt.Sprintf("error sending a message queued during the outage to %s - %s", m.channel, err))
+		}
+	}
+}
+
+// enqueue queues the message if there is an outage, it returns false if the message should be sent now.
+func (od *OutageDetector) enqueue(bot *Bot, priority Priority, channel string, options []slack.MsgOption) bool {
+	od.mu.Lock()
+	if !od.outage {
+		od.mu.Unlock()
+		return false
+	}
+	var dropped []queuedMessage
+	if len(od.queue) >= od.bufferSize() {
+		dropped, od.queue = od.queue[:1], od.queue[1:]
+	}
+	od.queue = append(od.queue, queuedMessage{priority: priority, channel: channel, options: options})
+	od.mu.Unlock()
+	for _, m := range dropped {
+		bot.LogWarn(fmt.Sprintf(outageDroppedMessage, m.channel))
+	}
+	return true
+}
+
+// InOutage returns true if the connection to slack has been down for longer than the Threshold.
+func (od *OutageDetector) InOutage() bool {
+	od.mu.Lock()
+	defer od.mu.Unlock()
+	return od.outage
+}
+
+func (od *OutageDetector) threshold() time.Duration {
+	if od.Threshold > 0 {
+		return od.Threshold
+	}
+	return defaultOutageThreshold
+}
+
+func (od *OutageDetector) bufferSize() int {
+	if od.BufferSize > 0 {
+		return od.BufferSize
+	}
+	return defaultOutageBufferSize
+}
@@ -0,0 +1,118 @@
+package slackbot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestOutageDetector(t *testing.T) {
+	tests := []struct {
+		name         string
+		bufferSize   int
+		wait         time.Duration
+		messages     []string
+		wantOutage   bool
+		wantSent     []string
+		plainTask    bool
+		wantRecovery bool
+	}{
+		{
+			name:     "should send messages when the bot reconnects before the threshold",
+			wait:     0,
+			messages: []string{"one", "two"},
+			wantSent: []string{"one", "two"},
+		},
+		{
+			name:         "should queue messages during an outage and send them on recovery",
+			wait:         50 * time.Millisecond,
+			messages:     []string{"one", "two"},
+			wantOutage:   true,
+			wantSent:     []string{"one", "two"},
+			wantRecovery: true,
+		},
+		{
+			name:         "should queue the replies of tasks without a result",
+			wait:         50 * time.Millisecond,
+			messages:     []string{"one", "two"},
+			plainTask:    true,
+			wantOutage:   true,
+			wantSent:     []string{"one", "two"},
+			wantRecovery: true,
+		},
+		{
+			name:         "should drop the oldest messages when the buffer is full",
+			bufferSize:   2,
+			wait:         50 * time.Millisecond,
+			messages:     []string{"one", "two", "three"},
+			wantOutage:   true,
+			wantSent:     []string{"two", "three"},
+			wantRecovery: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []string
+			outages := make(chan time.Time, 1)
+			recovered := false
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						sent = append(sent, msgValues(opts...).Get("text"))
+						return s, "ts", nil
+					},
+				},
+			}
+			od := &OutageDetector{
+				Threshold:  10 * time.Millisecond,
+				BufferSize: tt.bufferSize,
+				OnOutage: func(bot *Bot, since time.Time) {
+					outages <- since
+				},
+				OnRecovery: func(bot *Bot, downtime time.Duration) {
+					recovered = true
+				},
+			}
+			bot.OutageDetector = od
+			od.handleDisconnect(bot)
+			time.Sleep(tt.wait)
+			if od.InOutage() != tt.wantOutage {
+				t.Fatalf("InOutage() = %v, want %v", od.InOutage(), tt.wantOutage)
+			}
+			for _, m := range tt.messages {
+				task := taskFuncWrapper{
+					name:       "report",
+					channel:    "C1",
+					bot:        bot,
+					resultFunc: func(*Bot) (string, error) { return m, nil },
+				}
+				if tt.plainTask {
+					task.resultFunc = nil
+					task.taskFunc = func(bot *Bot) { _, _, _ = bot.Reply("C1", m) }
+				}
+				task.Run()
+			}
+			if tt.wantOutage && len(sent) != 0 {
+				t.Errorf("sent %q during the outage, want them queued", sent)
+			}
+			od.handleConnect(bot)
+			if !reflect.DeepEqual(sent, tt.wantSent) {
+				t.Errorf("sent = %q, want %q", sent, tt.wantSent)
+			}
+			outage := false
+			select {
+			case <-outages:
+				outage = true
+			case <-time.After(10 * time.Millisecond):
+			}
+			if outage != tt.wantOutage || recovered != tt.wantRecovery {
+				t.Errorf("OnOutage called = %v, OnRecovery called = %v, want %v, %v", outage, recovered, tt.wantOutage, tt.wantRecovery)
+			}
+			if od.InOutage() {
+				t.Errorf("InOutage() = true after reconnecting")
+			}
+		})
+	}
+}
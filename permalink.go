@@ -0,0 +1,31 @@
+package slackbot
+
+import (
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+// Permalink returns the url of the message with the timestamp in the channel.
+func (bot *Bot) Permalink(channel string, timestamp string) (string, error) {
+	var link string
+	err := bot.withRetry(func() (err error) {
+		link, err = bot.api().GetPermalinkContext(bot.context(), &slack.PermalinkParameters{Channel: channel, Ts: timestamp})
+		return err
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to get the permalink of %s in %s", timestamp, channel)
+	}
+	return link, nil
+}
+
+// ReplyWithPermalink sends a message like ReplyWithOptions and also returns the permalink of the message, so
+// a handler can cross-post a link to it, for example "I replied here: <link>". If the message is sent but the
+// permalink can't be found, the channel and timestamp are returned with the error.
+func (bot *Bot) ReplyWithPermalink(channel string, options ...slack.MsgOption) (respChannel string, timestamp string, permalink string, err error) {
+	c, ts, err := bot.ReplyWithOptions(channel, options...)
+	if err != nil {
+		return "", "", "", err
+	}
+	link, err := bot.Permalink(c, ts)
+	return c, ts, link, err
+}
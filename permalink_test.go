@@ -0,0 +1,63 @@
+package slackbot
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func TestBot_ReplyWithPermalink(t *testing.T) {
+	tests := []struct {
+		name          string
+		postErr       error
+		permalinkErr  error
+		wantTimestamp string
+		wantPermalink string
+		wantErr       bool
+	}{
+		{
+			name:          "should return the permalink of the message",
+			wantTimestamp: "1.1",
+			wantPermalink: "https://example.slack.com/archives/C1/p11",
+		},
+		{
+			name:    "should return an error if the message can't be sent",
+			postErr: errors.New("channel_not_found"),
+			wantErr: true,
+		},
+		{
+			name:          "should return the message and an error if the permalink can't be found",
+			permalinkErr:  errors.New("message_not_found"),
+			wantTimestamp: "1.1",
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						if tt.postErr != nil {
+							return "", "", tt.postErr
+						}
+						return "C1", "1.1", nil
+					},
+					getPermalink: func(params *slack.PermalinkParameters) (string, error) {
+						if tt.permalinkErr != nil {
+							return "", tt.permalinkErr
+						}
+						return "https://example.slack.com/archives/" + params.Channel + "/p11", nil
+					},
+				},
+			}
+			_, ts, link, err := bot.ReplyWithPermalink("#general", slack.MsgOptionText("deployed", false))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReplyWithPermalink() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ts != tt.wantTimestamp || link != tt.wantPermalink {
+				t.Errorf("ReplyWithPermalink() = %v, %v, want %v, %v", ts, link, tt.wantTimestamp, tt.wantPermalink)
+			}
+		})
+	}
+}
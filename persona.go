@@ -0,0 +1,48 @@
+package slackbot
+
+import (
+	"github.com/slack-go/slack"
+)
+
+// Persona changes the name and icon messages are sent with, so one bot can post as "DeployBot" or "AlertBot"
+// depending on the listener. Only one of IconEmoji or IconURL should be set. Sending messages with a persona
+// requires the chat:write.customize scope.
+type Persona struct {
+	Username  string
+	IconEmoji string
+	IconURL   string
+}
+
+// MsgOption returns a message option that sends the message as the persona, it can be passed to
+// ReplyWithOptions to override the bot's Persona for one message.
+func (p Persona) MsgOption() slack.MsgOption {
+	var options []slack.MsgOption
+	if p.Username != "" {
+		options = append(options, slack.MsgOptionUsername(p.Username))
+	}
+	if p.IconEmoji != "" {
+		options = append(options, slack.MsgOptionIconEmoji(p.IconEmoji))
+	}
+	if p.IconURL != "" {
+		options = append(options, slack.MsgOptionIconURL(p.IconURL))
+	}
+	return slack.MsgOptionCompose(options...)
+}
+
+// ReplyAs sends a message to the channel as the persona.
+func (bot *Bot) ReplyAs(persona Persona, channel string, text string) (respChannel string, timestamp string, err error) {
+	return bot.ReplyWithOptions(channel, slack.MsgOptionText(text, false), persona.MsgOption())
+}
+
+// withPersona adds the bot's Persona to the options, before them so options for the message take priority.
+// Messages are sent as the bot user unless they have a persona, as as_user would override it.
+func (bot *Bot) withPersona(options []slack.MsgOption) []slack.MsgOption {
+	if bot.Persona != nil {
+		options = append([]slack.MsgOption{bot.Persona.MsgOption()}, options...)
+	}
+	if values, err := encodeMsgOptions(options...); err == nil &&
+		(values.Get("username") != "" || values.Get("icon_emoji") != "" || values.Get("icon_url") != "") {
+		return options
+	}
+	return append(options, slack.MsgOptionAsUser(true))
+}
@@ -0,0 +1,74 @@
+package slackbot
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_withPersona(t *testing.T) {
+	tests := []struct {
+		name    string
+		persona *Persona
+		options []slack.MsgOption
+		want    url.Values
+	}{
+		{
+			name: "should send as the bot user without a persona",
+			want: url.Values{"as_user": {"true"}},
+		},
+		{
+			name:    "should send with the bot's persona",
+			persona: &Persona{Username: "DeployBot", IconEmoji: ":rocket:"},
+			want:    url.Values{"username": {"DeployBot"}, "icon_emoji": {":rocket:"}},
+		},
+		{
+			name:    "should send with the message's persona",
+			options: []slack.MsgOption{Persona{Username: "AlertBot", IconURL: "https://example.com/fire.png"}.MsgOption()},
+			want:    url.Values{"username": {"AlertBot"}, "icon_url": {"https://example.com/fire.png"}},
+		},
+		{
+			name:    "should let the message's persona override the bot's persona",
+			persona: &Persona{Username: "DeployBot", IconEmoji: ":rocket:"},
+			options: []slack.MsgOption{Persona{Username: "AlertBot", IconEmoji: ":fire:"}.MsgOption()},
+			want:    url.Values{"username": {"AlertBot"}, "icon_emoji": {":fire:"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got url.Values
+			bot := &Bot{
+				Persona: tt.persona,
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						got = msgValues(opts...)
+						return s, "ts", nil
+					},
+				},
+			}
+			_, _, _ = bot.ReplyWithOptions("C1", tt.options...)
+			for _, key := range []string{"as_user", "username", "icon_emoji", "icon_url"} {
+				if got.Get(key) != tt.want.Get(key) {
+					t.Errorf("ReplyWithOptions() %s = %q, want %q", key, got.Get(key), tt.want.Get(key))
+				}
+			}
+		})
+	}
+}
+
+func TestBot_ReplyAs(t *testing.T) {
+	var got url.Values
+	bot := &Bot{
+		API: &mockAPI{
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				got = msgValues(opts...)
+				return s, "ts", nil
+			},
+		},
+	}
+	_, _, _ = bot.ReplyAs(Persona{Username: "AlertBot", IconEmoji: ":fire:"}, "C1", "disk is full")
+	if got.Get("text") != "disk is full" || got.Get("username") != "AlertBot" || got.Get("icon_emoji") != ":fire:" {
+		t.Errorf("ReplyAs() sent %v", got)
+	}
+}
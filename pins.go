@@ -0,0 +1,80 @@
+package slackbot
+
+import (
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+// PinReply sends a message to the channel and pins it. If the message is sent but can't be pinned, the
+// channel and timestamp of the message are returned with the error.
+func (bot *Bot) PinReply(channel string, text string) (respChannel string, timestamp string, err error) {
+	c, ts, err := bot.Reply(channel, text)
+	if err != nil {
+		return "", "", err
+	}
+	return c, ts, bot.Pin(c, ts)
+}
+
+// Pin pins the message with the timestamp to the channel.
+func (bot *Bot) Pin(channel string, timestamp string) error {
+	err := bot.withRetry(func() error {
+		return bot.api().AddPinContext(bot.context(), channel, slack.NewRefToMessage(channel, timestamp))
+	})
+	if err != nil && err.Error() != "already_pinned" {
+		return errors.Wrapf(err, "unable to pin message %s in %s", timestamp, channel)
+	}
+	return nil
+}
+
+// Unpin removes the message with the timestamp from the channel's pins.
+func (bot *Bot) Unpin(channel string, timestamp string) error {
+	err := bot.withRetry(func() error {
+		return bot.api().RemovePinContext(bot.context(), channel, slack.NewRefToMessage(channel, timestamp))
+	})
+	if err != nil && err.Error() != "no_pin" {
+		return errors.Wrapf(err, "unable to unpin message %s in %s", timestamp, channel)
+	}
+	return nil
+}
+
+// PinnedMessages returns the messages the bot has pinned in the channel.
+func (bot *Bot) PinnedMessages(channel string) ([]slack.Message, error) {
+	var items []slack.Item
+	err := bot.withRetry(func() (err error) {
+		items, _, err = bot.api().ListPinsContext(bot.context(), channel)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to list the pins in %s", channel)
+	}
+	var messages []slack.Message
+	for _, item := range items {
+		if item.Type == slack.TYPE_MESSAGE && item.Message != nil && bot.isOwnMessage(item.Message) {
+			messages = append(messages, *item.Message)
+		}
+	}
+	return messages, nil
+}
+
+// ReplacePins unpins the bot's pinned messages in the channel then sends and pins the text, so a handler can
+// keep a single current status message pinned.
+func (bot *Bot) ReplacePins(channel string, text string) (respChannel string, timestamp string, err error) {
+	pinned, err := bot.PinnedMessages(channel)
+	if err != nil {
+		return "", "", err
+	}
+	for _, m := range pinned {
+		if err := bot.Unpin(channel, m.Timestamp); err != nil {
+			return "", "", err
+		}
+	}
+	return bot.PinReply(channel, text)
+}
+
+// isOwnMessage returns true if the message was sent by the bot.
+func (bot *Bot) isOwnMessage(m *slack.Message) bool {
+	if bot.userDetails == nil || bot.userDetails.ID == "" {
+		return false
+	}
+	return m.User == bot.userDetails.ID
+}
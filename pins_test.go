@@ -0,0 +1,127 @@
+package slackbot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func TestBot_PinReply(t *testing.T) {
+	tests := []struct {
+		name    string
+		pinErr  error
+		wantTS  string
+		wantErr bool
+	}{
+		{
+			name:   "should send and pin the message",
+			wantTS: "1.1",
+		},
+		{
+			name:   "should ignore messages that are already pinned",
+			pinErr: errors.New("already_pinned"),
+			wantTS: "1.1",
+		},
+		{
+			name:    "should return the message and an error if it can't be pinned",
+			pinErr:  errors.New("too_many_pins"),
+			wantTS:  "1.1",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pinned slack.ItemRef
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						return s, "1.1", nil
+					},
+					addPin: func(channel string, item slack.ItemRef) error {
+						pinned = item
+						return tt.pinErr
+					},
+				},
+			}
+			_, ts, err := bot.PinReply("C1", "status: all good")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PinReply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ts != tt.wantTS {
+				t.Errorf("PinReply() timestamp = %v, want %v", ts, tt.wantTS)
+			}
+			if want := slack.NewRefToMessage("C1", "1.1"); pinned != want {
+				t.Errorf("PinReply() pinned %v, want %v", pinned, want)
+			}
+		})
+	}
+}
+
+func TestBot_ReplacePins(t *testing.T) {
+	tests := []struct {
+		name         string
+		pins         []slack.Item
+		listErr      error
+		wantUnpinned []string
+		wantPinned   []string
+		wantErr      bool
+	}{
+		{
+			name: "should unpin the bot's messages and pin the new one",
+			pins: []slack.Item{
+				slack.NewMessageItem("C1", &slack.Message{Msg: slack.Msg{User: "bot", Timestamp: "1.1"}}),
+				slack.NewMessageItem("C1", &slack.Message{Msg: slack.Msg{User: "U1", Timestamp: "1.2"}}),
+				slack.NewFileItem(&slack.File{ID: "F1"}),
+				slack.NewMessageItem("C1", &slack.Message{Msg: slack.Msg{User: "bot", Timestamp: "1.3"}}),
+			},
+			wantUnpinned: []string{"1.1", "1.3"},
+			wantPinned:   []string{"2.1"},
+		},
+		{
+			name:       "should pin the message if nothing is pinned",
+			wantPinned: []string{"2.1"},
+		},
+		{
+			name:    "should return an error if the pins can't be listed",
+			listErr: errors.New("channel_not_found"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var unpinned, pinned []string
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						return s, "2.1", nil
+					},
+					listPins: func(channel string) ([]slack.Item, *slack.Paging, error) {
+						return tt.pins, nil, tt.listErr
+					},
+					addPin: func(channel string, item slack.ItemRef) error {
+						pinned = append(pinned, item.Timestamp)
+						return nil
+					},
+					removePin: func(channel string, item slack.ItemRef) error {
+						unpinned = append(unpinned, item.Timestamp)
+						return nil
+					},
+				},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			_, _, err := bot.ReplacePins("C1", "status: degraded")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReplacePins() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(unpinned, tt.wantUnpinned) {
+				t.Errorf("ReplacePins() unpinned %v, want %v", unpinned, tt.wantUnpinned)
+			}
+			if !reflect.DeepEqual(pinned, tt.wantPinned) {
+				t.Errorf("ReplacePins() pinned %v, want %v", pinned, tt.wantPinned)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package slackbot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	preferenceKeyPrefix         = "preference:"
+	preferenceSetMessage        = "Your %s is now %s."
+	preferenceValueMessage      = "Your %s is %s."
+	preferenceDefaultMessage    = "Your %s is %s (the default)."
+	preferenceNotSetMessage     = "Your %s is not set."
+	preferenceResetMessage      = "Your %s has been reset."
+	preferenceUnknownMessage    = "There is no preference called %s. The preferences are %s."
+	preferenceInvalidMessage    = "%s, your %s was not changed."
+	preferenceListHeaderMessage = "Your preferences:"
+)
+
+var (
+	preferenceSetRegex   = regexp.MustCompile(`^(?i)set my (.+?) to (.+)$`)
+	preferenceListRegex  = regexp.MustCompile(`^(?i)(?:list|get|show) my preferences$`)
+	preferenceGetRegex   = regexp.MustCompile(`^(?i)(?:get|show) my (.+)$`)
+	preferenceResetRegex = regexp.MustCompile(`^(?i)reset my (.+)$`)
+)
+
+// Preference is a per-user setting, such as the environment a user deploys to by default. When a bot has
+// Preferences users can "set my <name> to <value>", "get my <name>", "reset my <name>" and "list my
+// preferences", and handlers read them with bot.Preference. Values are validated with the Type and
+// Options in the same way as a FormField, and saved in the user's UserState.
+type Preference struct {
+	// Name identifies the preference in commands and in bot.Preference, it is matched ignoring case.
+	Name        string
+	Description string
+	Type        FieldType
+	Options     []string
+
+	// Default is returned by bot.Preference for users that haven't set the preference.
+	Default string
+}
+
+// Preference returns the user's value for the preference with the name, or its Default if the user hasn't
+// set it. An empty string is returned if the bot has no preference with the name.
+func (bot *Bot) Preference(user string, name string) string {
+	p := bot.preference(name)
+	if p == nil {
+		return ""
+	}
+	var value string
+	if err := bot.UserState(user).Get(preferenceKeyPrefix+p.Name, &value); err != nil {
+		return p.Default
+	}
+	return value
+}
+
+// SetPreference validates the value and saves it as the user's value for the preference.
+func (bot *Bot) SetPreference(user string, name string, value string) error {
+	p := bot.preference(name)
+	if p == nil {
+		return errors.Errorf("there is no preference called %s", name)
+	}
+	field := p.field()
+	if err := field.validate(value); err != nil {
+		return err
+	}
+	if field.Type == FieldChoice {
+		value = field.option(value)
+	}
+	return bot.UserState(user).Put(preferenceKeyPrefix+p.Name, value)
+}
+
+// preference returns the bot's preference with the name, ignoring case.
+func (bot *Bot) preference(name string) *Preference {
+	for i := range bot.Preferences {
+		if strings.EqualFold(bot.Preferences[i].Name, strings.TrimSpace(name)) {
+			return &bot.Preferences[i]
+		}
+	}
+	return nil
+}
+
+// field returns a form field with the preference's type, so values are validated the same way.
+func (p *Preference) field() *FormField {
+	return &FormField{Name: p.Name, Label: p.Name, Type: p.Type, Options: p.Options}
+}
+
+// preferenceListeners returns the direct listeners for the preference commands, they are added to the bot's
+// DirectListeners when it has Preferences.
+func preferenceListeners() []Listener {
+	return []Listener{
+		{
+			Usage: "list my preferences",
+			Regex: preferenceListRegex,
+			Handler: func(bot *Bot, ev *slack.MessageEvent) {
+				lines := []string{preferenceListHeaderMessage}
+				for _, p := range bot.Preferences {
+					line := fmt.Sprintf("• *%s*: %s", p.Name, bot.Preference(ev.User, p.Name))
+					if p.Description != "" {
+						line += " - " + p.Description
+					}
+					lines = append(lines, line)
+				}
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), strings.Join(lines, "\n"))
+			},
+		},
+		{
+			Usage: "set my <preference> to <value>",
+			Regex: preferenceSetRegex,
+			Handler: func(bot *Bot, ev *slack.MessageEvent) {
+				match := preferenceSetRegex.FindStringSubmatch(ev.Text)
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), bot.setPreferenceReply(ev.User, match[1], strings.TrimSpace(match[2])))
+			},
+		},
+		{
+			Usage: "get my <preference>",
+			Regex: preferenceGetRegex,
+			Handler: func(bot *Bot, ev *slack.MessageEvent) {
+				name := preferenceGetRegex.FindStringSubmatch(ev.Text)[1]
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), bot.getPreferenceReply(ev.User, name))
+			},
+		},
+		{
+			Usage: "reset my <preference>",
+			Regex: preferenceResetRegex,
+			Handler: func(bot *Bot, ev *slack.MessageEvent) {
+				name := preferenceResetRegex.FindStringSubmatch(ev.Text)[1]
+				msg := bot.unknownPreference(name)
+				if p := bot.preference(name); p != nil {
+					_ = bot.UserState(ev.User).Delete(preferenceKeyPrefix + p.Name)
+					msg = fmt.Sprintf(preferenceResetMessage, p.Name)
+				}
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), msg)
+			},
+		},
+	}
+}
+
+func (bot *Bot) setPreferenceReply(user string, name string, value string) string {
+	p := bot.preference(name)
+	if p == nil {
+		return bot.unknownPreference(name)
+	}
+	if err := bot.SetPreference(user, p.Name, value); err != nil {
+		return fmt.Sprintf(preferenceInvalidMessage, err, p.Name)
+	}
+	return fmt.Sprintf(preferenceSetMessage, p.Name, bot.Preference(user, p.Name))
+}
+
+func (bot *Bot) getPreferenceReply(user string, name string) string {
+	p := bot.preference(name)
+	if p == nil {
+		return bot.unknownPreference(name)
+	}
+	var value string
+	if err := bot.UserState(user).Get(preferenceKeyPrefix+p.Name, &value); err == nil {
+		return fmt.Sprintf(preferenceValueMessage, p.Name, value)
+	}
+	if p.Default != "" {
+		return fmt.Sprintf(preferenceDefaultMessage, p.Name, p.Default)
+	}
+	return fmt.Sprintf(preferenceNotSetMessage, p.Name)
+}
+
+func (bot *Bot) unknownPreference(name string) string {
+	names := make([]string, len(bot.Preferences))
+	for i, p := range bot.Preferences {
+		names[i] = p.Name
+	}
+	return fmt.Sprintf(preferenceUnknownMessage, name, strings.Join(names, ", "))
+}
@@ -0,0 +1,179 @@
+package slackbot
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func testPreferences() []Preference {
+	return []Preference{
+		{Name: "environment", Description: "where deploys go", Type: FieldChoice, Options: []string{"dev", "staging", "prod"}, Default: "dev"},
+		{Name: "batch size", Type: FieldNumber},
+	}
+}
+
+func TestBot_Preference(t *testing.T) {
+	tests := []struct {
+		name string
+		set  map[string]string
+		pref string
+		want string
+	}{
+		{
+			name: "should return the default when the preference is not set",
+			pref: "environment",
+			want: "dev",
+		},
+		{
+			name: "should return the user's value",
+			set:  map[string]string{"environment": "staging"},
+			pref: "environment",
+			want: "staging",
+		},
+		{
+			name: "should match the name ignoring case",
+			set:  map[string]string{"Batch Size": "10"},
+			pref: "batch size",
+			want: "10",
+		},
+		{
+			name: "should return an empty string for an unknown preference",
+			pref: "color",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{Preferences: testPreferences()}
+			for k, v := range tt.set {
+				if err := bot.SetPreference("U1", k, v); err != nil {
+					t.Fatalf("SetPreference() error = %v", err)
+				}
+			}
+			if got := bot.Preference("U1", tt.pref); got != tt.want {
+				t.Errorf("Preference() = %v, want %v", got, tt.want)
+			}
+			if got := bot.Preference("U2", tt.pref); tt.set != nil && got == tt.want {
+				t.Errorf("Preference() for another user = %v", got)
+			}
+		})
+	}
+}
+
+func TestBot_SetPreference(t *testing.T) {
+	tests := []struct {
+		name    string
+		pref    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "should set a choice to the option's case",
+			pref:  "environment",
+			value: "STAGING",
+			want:  "staging",
+		},
+		{
+			name:    "should reject a value that isn't an option",
+			pref:    "environment",
+			value:   "qa",
+			want:    "dev",
+			wantErr: true,
+		},
+		{
+			name:    "should reject a value that isn't a number",
+			pref:    "batch size",
+			value:   "lots",
+			wantErr: true,
+		},
+		{
+			name:    "should reject an unknown preference",
+			pref:    "color",
+			value:   "blue",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{Preferences: testPreferences()}
+			if err := bot.SetPreference("U1", tt.pref, tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("SetPreference() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := bot.Preference("U1", tt.pref); got != tt.want {
+				t.Errorf("Preference() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_preferenceListeners(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		want     string
+	}{
+		{
+			name:     "should list the preferences",
+			messages: []string{"set my environment to prod", "list my preferences"},
+			want:     "Your preferences:\n• *environment*: prod - where deploys go\n• *batch size*: ",
+		},
+		{
+			name:     "should set a preference",
+			messages: []string{"set my batch size to 20"},
+			want:     "Your batch size is now 20.",
+		},
+		{
+			name:     "should tell the user when a value is invalid",
+			messages: []string{"set my environment to qa"},
+			want:     "qa is not one of dev, staging, prod, your environment was not changed.",
+		},
+		{
+			name:     "should get a preference",
+			messages: []string{"set my environment to staging", "get my environment"},
+			want:     "Your environment is staging.",
+		},
+		{
+			name:     "should get the default",
+			messages: []string{"get my environment"},
+			want:     "Your environment is dev (the default).",
+		},
+		{
+			name:     "should tell the user a preference without a default is not set",
+			messages: []string{"show my batch size"},
+			want:     "Your batch size is not set.",
+		},
+		{
+			name:     "should reset a preference",
+			messages: []string{"set my environment to staging", "reset my environment", "get my environment"},
+			want:     "Your environment is dev (the default).",
+		},
+		{
+			name:     "should list the preferences for an unknown preference",
+			messages: []string{"get my color"},
+			want:     "There is no preference called color. The preferences are environment, batch size.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						got = msgValues(opts...).Get("text")
+						return s, "ts", nil
+					},
+				},
+				Preferences: testPreferences(),
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			bot.init()
+			for _, text := range tt.messages {
+				bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: "U1", Text: text, Timestamp: "1.1"}})
+			}
+			if got != tt.want {
+				t.Errorf("reply = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
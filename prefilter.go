@@ -0,0 +1,176 @@
+package slackbot
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// literalFilter is a literal that every match of a regular expression contains. Most messages don't match
+// most listeners, and checking for the literal is much faster than running the regular expression, so busy
+// channels with many indirect listeners only run the regular expressions that could match.
+type literalFilter struct {
+	literal string
+
+	// fold is set for literals from case insensitive expressions, they are lower case ASCII and are found
+	// ignoring case.
+	fold bool
+}
+
+// prefilters caches the literalFilter of each of the bot's regular expressions, by pointer, because listeners
+// are compiled once and matched against every message.
+type prefilters struct {
+	mu      sync.RWMutex
+	filters map[*regexp.Regexp]literalFilter
+}
+
+// matchString reports whether the text matches the regular expression, skipping the regular expression when
+// the text doesn't contain a literal all of its matches contain.
+func (bot *Bot) matchString(re *regexp.Regexp, text string) bool {
+	if bot.prefilters == nil {
+		return re.MatchString(text)
+	}
+	return bot.literalFilterFor(re).mayMatch(text) && re.MatchString(text)
+}
+
+// literalFilterFor returns the literalFilter of the regular expression, parsing it the first time. Once there
+// are more filters than the bot has expressions its listeners have been replaced, so the filters are cleared
+// and only those of the current expressions are added again.
+func (bot *Bot) literalFilterFor(re *regexp.Regexp) literalFilter {
+	p := bot.prefilters
+	p.mu.RLock()
+	f, ok := p.filters[re]
+	p.mu.RUnlock()
+	if ok {
+		return f
+	}
+	f = newLiteralFilter(re.String())
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.filters) >= bot.expressionCount() {
+		p.filters = make(map[*regexp.Regexp]literalFilter)
+	}
+	p.filters[re] = f
+	return f
+}
+
+// expressionCount returns how many regular expressions the bot's listeners and exchanges are matched with.
+func (bot *Bot) expressionCount() int {
+	n := len(bot.Exchanges)
+	for _, listeners := range [][]Listener{bot.DirectListeners, bot.IndirectListeners} {
+		for _, l := range listeners {
+			n += 1 + len(l.Aliases)
+		}
+	}
+	return n
+}
+
+// newLiteralFilter returns the longest literal every match of the expression contains, or an empty filter
+// that lets every text through if there isn't one.
+func newLiteralFilter(expr string) literalFilter {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return literalFilter{}
+	}
+	re = re.Simplify()
+	if anchoredAtStart(re) {
+		return literalFilter{}
+	}
+	return requiredLiteral(re)
+}
+
+// anchoredAtStart returns true if the expression only matches at the start of the text or a line. The
+// regexp package rejects text that doesn't start with their literal prefix without scanning it, so filtering
+// them would only add work.
+func anchoredAtStart(re *syntax.Regexp) bool {
+	for re.Op == syntax.OpConcat || re.Op == syntax.OpCapture {
+		if len(re.Sub) == 0 {
+			return false
+		}
+		re = re.Sub[0]
+	}
+	return re.Op == syntax.OpBeginText || re.Op == syntax.OpBeginLine
+}
+
+// requiredLiteral returns the longest literal that every match of the expression contains.
+func requiredLiteral(re *syntax.Regexp) literalFilter {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase == 0 {
+			return literalFilter{literal: string(re.Rune)}
+		}
+		return foldLiteral(re.Rune)
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiteral(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min >= 1 {
+			return requiredLiteral(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		var longest literalFilter
+		for _, sub := range re.Sub {
+			if f := requiredLiteral(sub); len(f.literal) > len(longest.literal) {
+				longest = f
+			}
+		}
+		return longest
+	}
+	return literalFilter{}
+}
+
+// foldLiteral returns the longest run of the case insensitive literal that can be found by comparing ASCII
+// letters ignoring case. Non ASCII runes, and k and s which also match the Kelvin sign and long s, end a run.
+func foldLiteral(runes []rune) literalFilter {
+	var longest, run []rune
+	for _, r := range append(runes, utf8.RuneError) {
+		lower := r
+		if 'A' <= r && r <= 'Z' {
+			lower = r + 'a' - 'A'
+		}
+		if r < utf8.RuneSelf && lower != 'k' && lower != 's' {
+			run = append(run, lower)
+			continue
+		}
+		if len(run) > len(longest) {
+			longest = run
+		}
+		run = nil
+	}
+	return literalFilter{literal: string(longest), fold: true}
+}
+
+// mayMatch returns false if the text can't match because it doesn't contain the literal.
+func (f literalFilter) mayMatch(text string) bool {
+	switch {
+	case f.literal == "":
+		return true
+	case f.fold:
+		return containsFoldASCII(text, f.literal)
+	default:
+		return strings.Contains(text, f.literal)
+	}
+}
+
+// containsFoldASCII reports whether the text contains the lower case ASCII literal, ignoring the case of
+// ASCII letters in the text.
+func containsFoldASCII(text string, literal string) bool {
+	n := len(literal)
+	for i := 0; i+n <= len(text); i++ {
+		j := 0
+		for ; j < n; j++ {
+			c := text[i+j]
+			if 'A' <= c && c <= 'Z' {
+				c += 'a' - 'A'
+			}
+			if c != literal[j] {
+				break
+			}
+		}
+		if j == n {
+			return true
+		}
+	}
+	return false
+}
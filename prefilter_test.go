@@ -0,0 +1,200 @@
+package slackbot
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func Test_newLiteralFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want literalFilter
+	}{
+		{
+			name: "should use the literal of a simple expression",
+			expr: `\bdeploy$`,
+			want: literalFilter{literal: "deploy"},
+		},
+		{
+			name: "should use the longest literal",
+			expr: `deploy (\w+) to production$`,
+			want: literalFilter{literal: " to production"},
+		},
+		{
+			name: "should use a lower case literal for case insensitive expressions",
+			expr: `(?i)\bDeploy (\w+)$`,
+			want: literalFilter{literal: "deploy ", fold: true},
+		},
+		{
+			name: "should split case insensitive literals at letters with non ASCII case folds",
+			expr: `(?i)rollback`,
+			want: literalFilter{literal: "rollbac", fold: true},
+		},
+		{
+			name: "should use literals in groups that must match",
+			expr: `(?:please )?(restart the service)+$`,
+			want: literalFilter{literal: "restart the service"},
+		},
+		{
+			name: "should not use literals that are optional",
+			expr: `(deploy|release)?$`,
+		},
+		{
+			name: "should not use literals in alternations",
+			expr: `(deploy|release) now$`,
+			want: literalFilter{literal: " now"},
+		},
+		{
+			name: "should not filter expressions without literals",
+			expr: `^\w+$`,
+		},
+		{
+			name: "should not filter expressions anchored at the start",
+			expr: `^deploy (\w+)$`,
+		},
+		{
+			name: "should not filter expressions anchored at the start of a line",
+			expr: `(?m)^(deploy) (\w+)$`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newLiteralFilter(tt.expr); got != tt.want {
+				t.Errorf("newLiteralFilter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_matchString(t *testing.T) {
+	expressions := []string{
+		`^deploy (\w+)$`,
+		`(?i)^deploy (\w+)$`,
+		`(?i)status`,
+		`(?i)KELVIN`,
+		`incident (?:opened|closed)`,
+		`^(?i:hello) world$`,
+		`café`,
+		`(?i)café`,
+		`\bP1\b`,
+		`(?i)\bdeploy\b`,
+		`(?i)rollback`,
+	}
+	texts := []string{
+		"please deploy api",
+		"deploy api",
+		"DEPLOY api",
+		"Deploy",
+		"what is the STATUS",
+		"ſtatus",
+		"Kelvin",
+		"the incident closed",
+		"HeLLo world",
+		"hello WORLD",
+		"Café",
+		"CAFÉ",
+		"a P1 alert",
+		"",
+	}
+	bot := &Bot{prefilters: &prefilters{filters: make(map[*regexp.Regexp]literalFilter)}}
+	for _, expr := range expressions {
+		re := regexp.MustCompile(expr)
+		bot.DirectListeners = append(bot.DirectListeners, Listener{Regex: re})
+		for _, text := range texts {
+			if got, want := bot.matchString(re, text), re.MatchString(text); got != want {
+				t.Errorf("matchString(%q, %q) = %v, want %v", expr, text, got, want)
+			}
+		}
+	}
+}
+
+func TestBot_literalFilterFor(t *testing.T) {
+	bot := &Bot{prefilters: &prefilters{filters: make(map[*regexp.Regexp]literalFilter)}}
+	for i := 0; i < 3; i++ {
+		bot.IndirectListeners = benchmarkListeners(2, benchmarkFormats["unanchored"])
+		for _, l := range bot.IndirectListeners {
+			bot.matchString(l.Regex, "command1 api")
+		}
+		if got := len(bot.prefilters.filters); got != 2 {
+			t.Errorf("after replacing the listeners %d times there are %d filters, want 2", i, got)
+		}
+		for _, l := range bot.IndirectListeners {
+			if _, ok := bot.prefilters.filters[l.Regex]; !ok {
+				t.Errorf("there is no filter for %s", l.Regex)
+			}
+		}
+	}
+}
+
+// benchmarkListeners returns listeners that each match a different command with the format, which has a %d
+// for the command's number.
+func benchmarkListeners(n int, format string) []Listener {
+	listeners := make([]Listener, n)
+	for i := range listeners {
+		listeners[i] = Listener{
+			Regex:   regexp.MustCompile(fmt.Sprintf(format, i)),
+			Handler: func(*Bot, *slack.MessageEvent) {},
+		}
+	}
+	return listeners
+}
+
+var (
+	benchmarkTexts = []string{
+		"has anyone seen the latest deploy go out to production?",
+		"lunch anyone?",
+		"command42 api to staging",
+		"I think the dashboard is broken again, can someone take a look when they get a chance",
+	}
+	benchmarkFormats = map[string]string{
+		"anchored":   `(?i)^command%d (\w+)(?: to (\w+))?$`,
+		"unanchored": `(?i)\bcommand%d (\w+)(?: to (\w+))?`,
+	}
+)
+
+func BenchmarkListenerMatching(b *testing.B) {
+	for name, format := range benchmarkFormats {
+		listeners := benchmarkListeners(50, format)
+		b.Run(name+"/regexp", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, l := range listeners {
+					l.Regex.MatchString(benchmarkTexts[i%len(benchmarkTexts)])
+				}
+			}
+		})
+		bot := &Bot{DirectListeners: listeners, prefilters: &prefilters{filters: make(map[*regexp.Regexp]literalFilter)}}
+		b.Run(name+"/prefiltered", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, l := range listeners {
+					bot.matchString(l.Regex, benchmarkTexts[i%len(benchmarkTexts)])
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkBot_processMessage(b *testing.B) {
+	for name, format := range benchmarkFormats {
+		b.Run(name, func(b *testing.B) {
+			bot := &Bot{
+				API:               &mockAPI{},
+				IndirectListeners: benchmarkListeners(50, format),
+				userDetails:       &slack.UserDetails{ID: "bot"},
+			}
+			bot.init()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{
+					Channel:   "C1",
+					User:      "U1",
+					Text:      benchmarkTexts[i%len(benchmarkTexts)],
+					Timestamp: "1.1",
+				}})
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package slackbot
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	presenceCacheTTL     = 5 * time.Minute
+	presenceActive       = "active"
+	presenceKeyPrefix    = "presence "
+	dndStatusKeyPrefix   = "dnd "
+	maxPresenceSubscribe = 500
+)
+
+// IsUserActive returns true if the user's presence is active. Presence is cached, and when the bot is connected
+// with RTM the user is subscribed to presence_change events so the cached presence is kept up to date.
+// Notification logic can use it to fall back to another channel, such as email, when a user is away.
+func (bot *Bot) IsUserActive(user string) (bool, error) {
+	c := bot.presenceCache()
+	if presence, ok := c.get(presenceKeyPrefix + user); ok {
+		return presence.(string) == presenceActive, nil
+	}
+	var p *slack.UserPresence
+	err := bot.withRetry(func() (err error) {
+		p, err = bot.api().GetUserPresenceContext(bot.context(), user)
+		return err
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to get the presence of %s", user)
+	}
+	c.set(presenceKeyPrefix+user, p.Presence)
+	bot.subscribePresence(user)
+	return p.Presence == presenceActive, nil
+}
+
+// IsUserInDND returns true if the user has snoozed notifications or is in their do not disturb schedule.
+// The user's do not disturb settings are cached, and updated from dnd_updated_user events over RTM.
+func (bot *Bot) IsUserInDND(user string) (bool, error) {
+	c := bot.presenceCache()
+	if status, ok := c.get(dndStatusKeyPrefix + user); ok {
+		return inDND(status.(slack.DNDStatus), bot.clock().Now()), nil
+	}
+	var status *slack.DNDStatus
+	err := bot.withRetry(func() (err error) {
+		status, err = bot.api().GetDNDInfoContext(bot.context(), &user)
+		return err
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to get the do not disturb status of %s", user)
+	}
+	c.set(dndStatusKeyPrefix+user, *status)
+	return inDND(*status, bot.clock().Now()), nil
+}
+
+// handlePresenceChange updates the cached presence of the users in the event.
+func (bot *Bot) handlePresenceChange(ev *slack.PresenceChangeEvent) {
+	c := bot.presenceCache()
+	if ev.User != "" {
+		c.set(presenceKeyPrefix+ev.User, ev.Presence)
+	}
+	for _, u := range ev.Users {
+		c.set(presenceKeyPrefix+u, ev.Presence)
+	}
+}
+
+// handleDNDUpdated updates the cached do not disturb status of the user in the event.
+func (bot *Bot) handleDNDUpdated(ev *slack.DNDUpdatedEvent) {
+	bot.presenceCache().set(dndStatusKeyPrefix+ev.User, ev.Status)
+}
+
+// subscribePresence adds the user to the bot's presence subscriptions when it is connected with RTM. Slack
+// replaces the subscriptions with each request, so all of the users are sent every time.
+func (bot *Bot) subscribePresence(user string) {
+	bot.mu.Lock()
+	if !bot.rtmConnected || bot.presenceSubs[user] || len(bot.presenceSubs) >= maxPresenceSubscribe {
+		bot.mu.Unlock()
+		return
+	}
+	if bot.presenceSubs == nil {
+		bot.presenceSubs = make(map[string]bool)
+	}
+	bot.presenceSubs[user] = true
+	users := make([]string, 0, len(bot.presenceSubs))
+	for u := range bot.presenceSubs {
+		users = append(users, u)
+	}
+	bot.mu.Unlock()
+	bot.api().SendMessage(bot.api().NewSubscribeUserPresence(users))
+}
+
+func (bot *Bot) presenceCache() *cache {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.presence == nil {
+		bot.presence = newCache(presenceCacheTTL, bot.clock())
+	}
+	return bot.presence
+}
+
+// inDND returns true if notifications are snoozed or the time is in the do not disturb schedule.
+func inDND(status slack.DNDStatus, now time.Time) bool {
+	if status.SnoozeEnabled && (status.SnoozeEndTime == 0 || now.Unix() < int64(status.SnoozeEndTime)) {
+		return true
+	}
+	return status.Enabled && now.Unix() >= int64(status.NextStartTimestamp) && now.Unix() < int64(status.NextEndTimestamp)
+}
@@ -0,0 +1,160 @@
+package slackbot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func TestBot_IsUserActive(t *testing.T) {
+	tests := []struct {
+		name          string
+		presence      string
+		err           error
+		rtm           bool
+		event         *slack.PresenceChangeEvent
+		want          bool
+		wantCalls     int
+		wantSubscribe []string
+		wantErr       bool
+	}{
+		{
+			name:      "should return true if the user is active and cache it",
+			presence:  "active",
+			want:      true,
+			wantCalls: 1,
+		},
+		{
+			name:      "should return false if the user is away",
+			presence:  "away",
+			wantCalls: 1,
+		},
+		{
+			name:          "should subscribe to presence changes when connected with rtm",
+			presence:      "active",
+			rtm:           true,
+			want:          true,
+			wantCalls:     1,
+			wantSubscribe: []string{"U1"},
+		},
+		{
+			name:      "should use the presence from presence change events",
+			presence:  "active",
+			event:     &slack.PresenceChangeEvent{Presence: "away", Users: []string{"U2", "U1"}},
+			wantCalls: 0,
+		},
+		{
+			name:      "should return an error if the presence can't be found",
+			err:       errors.New("user_not_found"),
+			wantCalls: 2,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			var subscribed []string
+			bot := &Bot{
+				rtmConnected: tt.rtm,
+				API: &mockAPI{
+					getUserPresence: func(user string) (*slack.UserPresence, error) {
+						calls++
+						if tt.err != nil {
+							return nil, tt.err
+						}
+						return &slack.UserPresence{Presence: tt.presence}, nil
+					},
+					sendMessage: func(msg *slack.OutgoingMessage) {
+						subscribed = append(subscribed, msg.IDs...)
+					},
+				},
+			}
+			if tt.event != nil {
+				bot.handlePresenceChange(tt.event)
+			}
+			var got bool
+			var err error
+			for i := 0; i < 2; i++ {
+				got, err = bot.IsUserActive("U1")
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("IsUserActive() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("IsUserActive() got = %v, want %v", got, tt.want)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("IsUserActive() called GetUserPresence %d times, want %d", calls, tt.wantCalls)
+			}
+			if !reflect.DeepEqual(subscribed, tt.wantSubscribe) {
+				t.Errorf("IsUserActive() subscribed to %v, want %v", subscribed, tt.wantSubscribe)
+			}
+		})
+	}
+}
+
+func TestBot_IsUserInDND(t *testing.T) {
+	now := int(time.Now().Unix())
+	tests := []struct {
+		name    string
+		status  slack.DNDStatus
+		event   *slack.DNDUpdatedEvent
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:   "should return true if notifications are snoozed",
+			status: slack.DNDStatus{SnoozeInfo: slack.SnoozeInfo{SnoozeEnabled: true, SnoozeEndTime: now + 60}},
+			want:   true,
+		},
+		{
+			name:   "should return false if the snooze has ended",
+			status: slack.DNDStatus{SnoozeInfo: slack.SnoozeInfo{SnoozeEnabled: true, SnoozeEndTime: now - 60}},
+		},
+		{
+			name:   "should return true during the do not disturb schedule",
+			status: slack.DNDStatus{Enabled: true, NextStartTimestamp: now - 60, NextEndTimestamp: now + 60},
+			want:   true,
+		},
+		{
+			name:   "should return false outside the do not disturb schedule",
+			status: slack.DNDStatus{Enabled: true, NextStartTimestamp: now + 60, NextEndTimestamp: now + 120},
+		},
+		{
+			name:   "should use the status from dnd updated events",
+			status: slack.DNDStatus{},
+			event:  &slack.DNDUpdatedEvent{User: "U1", Status: slack.DNDStatus{SnoozeInfo: slack.SnoozeInfo{SnoozeEnabled: true}}},
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{
+				API: &mockAPI{
+					getDNDInfo: func(user *string) (*slack.DNDStatus, error) {
+						if *user != "U1" {
+							return nil, errors.New("user_not_found")
+						}
+						status := tt.status
+						return &status, nil
+					},
+				},
+			}
+			if tt.event != nil {
+				bot.handleDNDUpdated(tt.event)
+			}
+			got, err := bot.IsUserInDND("U1")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("IsUserInDND() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("IsUserInDND() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
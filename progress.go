@@ -0,0 +1,82 @@
+package slackbot
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+// ErrProgressDone is returned when a ProgressThread is changed after Done has been called.
+var ErrProgressDone = errors.New("the progress thread is done")
+
+// ProgressThread reports the progress of a long process, such as a deploy pipeline, in a single thread instead
+// of a series of messages in the channel. The root message shows the current status, which Update edits, each
+// step is added to the thread with Append, and Done replaces the root message with a final summary.
+//
+// Example:
+//
+//	progress, err := bot.StartProgressThread(channel, "Deploying api...")
+//	progress.Append("Tests passed")
+//	progress.Update("Deploying api, rolling out to 3 of 10 hosts...")
+//	progress.Done(":white_check_mark: Deployed api in 4m")
+type ProgressThread struct {
+	Channel   string
+	Timestamp string
+
+	bot  *Bot
+	mu   sync.Mutex
+	done bool
+}
+
+// StartProgressThread sends the root message of a ProgressThread to the channel.
+func (bot *Bot) StartProgressThread(channel string, text string) (*ProgressThread, error) {
+	c, ts, err := bot.Reply(channel, text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to start progress thread in %s", channel)
+	}
+	return &ProgressThread{Channel: c, Timestamp: ts, bot: bot}, nil
+}
+
+// Append adds a message to the thread.
+func (p *ProgressThread) Append(text string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.done {
+		return ErrProgressDone
+	}
+	if _, _, err := p.bot.ReplyInThread(p.Channel, p.Timestamp, text); err != nil {
+		return errors.Wrapf(err, "unable to add to progress thread %s", p.Timestamp)
+	}
+	return nil
+}
+
+// Update replaces the text of the root message with the current status.
+func (p *ProgressThread) Update(text string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.done {
+		return ErrProgressDone
+	}
+	return p.edit(text)
+}
+
+// Done replaces the root message with the summary, the thread can't be changed after it is done.
+func (p *ProgressThread) Done(summary string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.done {
+		return ErrProgressDone
+	}
+	p.done = true
+	return p.edit(summary)
+}
+
+// edit updates the root message, mu must be held.
+func (p *ProgressThread) edit(text string) error {
+	err := p.bot.withRetry(func() error {
+		_, _, _, err := p.bot.api().UpdateMessageContext(p.bot.context(), p.Channel, p.Timestamp, slack.MsgOptionText(text, false))
+		return err
+	})
+	return errors.Wrapf(err, "unable to update progress thread %s", p.Timestamp)
+}
@@ -0,0 +1,58 @@
+package slackbot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestProgressThread(t *testing.T) {
+	var got []string
+	bot := &Bot{
+		API: &mockAPI{
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				v := msgValues(opts...)
+				got = append(got, "post "+v.Get("thread_ts")+" "+v.Get("text"))
+				return s, "1.1", nil
+			},
+			updateMessage: func(c string, ts string, opts ...slack.MsgOption) (string, string, string, error) {
+				got = append(got, "update "+ts+" "+msgValues(opts...).Get("text"))
+				return c, ts, "", nil
+			},
+		},
+	}
+	p, err := bot.StartProgressThread("C1", "Deploying api...")
+	if err != nil {
+		t.Fatalf("StartProgressThread() error = %v", err)
+	}
+	if p.Channel != "C1" || p.Timestamp != "1.1" {
+		t.Errorf("StartProgressThread() = %s %s, want C1 1.1", p.Channel, p.Timestamp)
+	}
+	steps := []struct {
+		name    string
+		call    func() error
+		wantErr error
+	}{
+		{name: "Append", call: func() error { return p.Append("Tests passed") }},
+		{name: "Update", call: func() error { return p.Update("Deploying api, 3 of 10 hosts...") }},
+		{name: "Done", call: func() error { return p.Done("Deployed api") }},
+		{name: "Append after Done", call: func() error { return p.Append("too late") }, wantErr: ErrProgressDone},
+		{name: "Update after Done", call: func() error { return p.Update("too late") }, wantErr: ErrProgressDone},
+		{name: "Done after Done", call: func() error { return p.Done("too late") }, wantErr: ErrProgressDone},
+	}
+	for _, s := range steps {
+		if err := s.call(); err != s.wantErr {
+			t.Errorf("%s() error = %v, want %v", s.name, err, s.wantErr)
+		}
+	}
+	want := []string{
+		"post  Deploying api...",
+		"post 1.1 Tests passed",
+		"update 1.1 Deploying api, 3 of 10 hosts...",
+		"update 1.1 Deployed api",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("messages = %q, want %q", got, want)
+	}
+}
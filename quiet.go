@@ -0,0 +1,120 @@
+package slackbot
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxQuietWindows limits how many back to back quiet hours a message can be held through, so quiet hours that
+// never end can't hold a message forever.
+const maxQuietWindows = 14
+
+// ErrMessageHeld is returned when a message is held in the SendQueue because it was sent during QuietHours, it
+// will be sent when they end.
+var ErrMessageHeld = errors.New("the message is held until quiet hours end")
+
+// QuietHours is a window each day when messages below the MinPriority are held in the bot's SendQueue, and sent
+// when the window ends, so notifications respect the team's off hours. Start and End are times of day such as
+// "10pm", "7:30am" or "18:00". A window that ends before it starts runs over midnight, and one that ends when
+// it starts lasts the whole day. Days are the days the window starts on, every day if it is empty, and the
+// times are in the Location, the local timezone if it isn't set. Quiet hours with Channels, names or IDs, only
+// apply to those channels. The bot must have a SendQueue to hold messages.
+//
+// Example:
+//
+//	QuietHours: []slackbot.QuietHours{
+//		{Start: "7pm", End: "8am", MinPriority: slackbot.PriorityAlert},
+//		{Start: "midnight", End: "midnight", Days: []time.Weekday{time.Saturday, time.Sunday}, MinPriority: slackbot.PriorityAlert},
+//	}
+type QuietHours struct {
+	Start    string
+	End      string
+	Days     []time.Weekday
+	Location *time.Location
+	Channels []string
+
+	// MinPriority is the lowest priority that is sent during the quiet hours. The default of PriorityReply
+	// only holds debug messages, set it to PriorityAlert to hold everything except alerts.
+	MinPriority Priority
+}
+
+// validate returns an error if the Start or End can't be parsed.
+func (qh *QuietHours) validate() error {
+	if _, err := parseTimeOfDay(qh.Start); err != nil {
+		return errors.Wrap(err, "invalid quiet hours start")
+	}
+	if _, err := parseTimeOfDay(qh.End); err != nil {
+		return errors.Wrap(err, "invalid quiet hours end")
+	}
+	return nil
+}
+
+// end returns when the quiet hours that t is in end, and false if t isn't in the quiet hours.
+func (qh *QuietHours) end(t time.Time) (time.Time, bool) {
+	start, err := parseTimeOfDay(qh.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := parseTimeOfDay(qh.End)
+	if err != nil {
+		return time.Time{}, false
+	}
+	loc := qh.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	t = t.In(loc)
+	for _, day := range []time.Time{t.AddDate(0, 0, -1), t} {
+		if !qh.onDay(day.Weekday()) {
+			continue
+		}
+		s, e := atTimeOfDay(day, start), atTimeOfDay(day, end)
+		if !e.After(s) {
+			e = atTimeOfDay(day.AddDate(0, 0, 1), end)
+		}
+		if !t.Before(s) && t.Before(e) {
+			return e, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// onDay returns true if the quiet hours start on the day.
+func (qh *QuietHours) onDay(day time.Weekday) bool {
+	if len(qh.Days) == 0 {
+		return true
+	}
+	for _, d := range qh.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// quietUntil returns when a message to the channel with the priority can be sent, which is t unless t is in
+// the bot's QuietHours.
+func (bot *Bot) quietUntil(channel string, priority Priority, t time.Time) time.Time {
+	changed := true
+	for i := 0; changed && i < maxQuietWindows; i++ {
+		changed = false
+		for j := range bot.QuietHours {
+			qh := &bot.QuietHours[j]
+			if priority >= qh.MinPriority || (len(qh.Channels) > 0 && !bot.inChannels(qh.Channels, channel)) {
+				continue
+			}
+			if end, ok := qh.end(t); ok {
+				t, changed = end, true
+			}
+		}
+	}
+	return t
+}
+
+// IsQuiet returns true if it is the bot's QuietHours for messages to the channel with the priority, so they
+// are held in the SendQueue.
+func (bot *Bot) IsQuiet(channel string, priority Priority) bool {
+	now := bot.clock().Now()
+	return bot.quietUntil(channel, priority, now).After(now)
+}
@@ -0,0 +1,193 @@
+package slackbot
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// fixedClock is a Clock that is always at the same time.
+type fixedClock struct {
+	Clock
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+func TestQuietHours_end(t *testing.T) {
+	// 2021-03-05 is a Friday.
+	at := func(day int, hour int, minute int) time.Time {
+		return time.Date(2021, 3, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name   string
+		qh     QuietHours
+		t      time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{
+			name:   "should be quiet in the evening of a window over midnight",
+			qh:     QuietHours{Start: "7pm", End: "8am"},
+			t:      at(5, 22, 0),
+			want:   at(6, 8, 0),
+			wantOK: true,
+		},
+		{
+			name:   "should be quiet in the morning of a window over midnight",
+			qh:     QuietHours{Start: "7pm", End: "8am"},
+			t:      at(5, 7, 59),
+			want:   at(5, 8, 0),
+			wantOK: true,
+		},
+		{
+			name: "should not be quiet outside the window",
+			qh:   QuietHours{Start: "7pm", End: "8am"},
+			t:    at(5, 8, 0),
+		},
+		{
+			name:   "should be quiet in a window during the day",
+			qh:     QuietHours{Start: "12:00", End: "13:30"},
+			t:      at(5, 12, 0),
+			want:   at(5, 13, 30),
+			wantOK: true,
+		},
+		{
+			name:   "should be quiet all day",
+			qh:     QuietHours{Start: "midnight", End: "midnight", Days: []time.Weekday{time.Saturday, time.Sunday}},
+			t:      at(6, 15, 0),
+			want:   at(7, 0, 0),
+			wantOK: true,
+		},
+		{
+			name: "should not be quiet on other days",
+			qh:   QuietHours{Start: "midnight", End: "midnight", Days: []time.Weekday{time.Saturday, time.Sunday}},
+			t:    at(5, 15, 0),
+		},
+		{
+			name:   "should use the location",
+			qh:     QuietHours{Start: "7pm", End: "8am", Location: time.FixedZone("EST", -5*60*60)},
+			t:      at(5, 12, 30),
+			want:   at(5, 13, 0),
+			wantOK: true,
+		},
+		{
+			name: "should not be quiet with invalid times",
+			qh:   QuietHours{Start: "evening", End: "8am"},
+			t:    at(5, 22, 0),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.qh.end(tt.t)
+			if !got.Equal(tt.want) || ok != tt.wantOK {
+				t.Errorf("end() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestBot_quietUntil(t *testing.T) {
+	evening := time.Date(2021, 3, 5, 22, 0, 0, 0, time.UTC)
+	morning := time.Date(2021, 3, 6, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		quietHours []QuietHours
+		channel    string
+		priority   Priority
+		want       time.Time
+	}{
+		{
+			name:       "should hold messages below the min priority",
+			quietHours: []QuietHours{{Start: "7pm", End: "8am", Location: time.UTC, MinPriority: PriorityAlert}},
+			channel:    "C1",
+			priority:   PriorityReply,
+			want:       morning,
+		},
+		{
+			name:       "should send messages with the min priority",
+			quietHours: []QuietHours{{Start: "7pm", End: "8am", Location: time.UTC, MinPriority: PriorityAlert}},
+			channel:    "C1",
+			priority:   PriorityAlert,
+			want:       evening,
+		},
+		{
+			name:       "should only hold debug messages by default",
+			quietHours: []QuietHours{{Start: "7pm", End: "8am", Location: time.UTC}},
+			channel:    "C1",
+			priority:   PriorityReply,
+			want:       evening,
+		},
+		{
+			name:       "should only hold messages to the channels",
+			quietHours: []QuietHours{{Start: "7pm", End: "8am", Location: time.UTC, Channels: []string{"C2"}, MinPriority: PriorityAlert}},
+			channel:    "C1",
+			priority:   PriorityReply,
+			want:       evening,
+		},
+		{
+			name: "should hold messages through back to back windows",
+			quietHours: []QuietHours{
+				{Start: "7pm", End: "8am", Location: time.UTC, MinPriority: PriorityAlert},
+				{Start: "midnight", End: "midnight", Location: time.UTC, Days: []time.Weekday{time.Saturday}, MinPriority: PriorityAlert},
+			},
+			channel:  "C1",
+			priority: PriorityReply,
+			want:     time.Date(2021, 3, 7, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "should not hold messages forever",
+			quietHours: []QuietHours{{Start: "midnight", End: "midnight", Location: time.UTC, MinPriority: PriorityAlert}},
+			channel:    "C1",
+			priority:   PriorityReply,
+			want:       time.Date(2021, 3, 5+maxQuietWindows, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{QuietHours: tt.quietHours, API: &mockAPI{}}
+			if got := bot.quietUntil(tt.channel, tt.priority, evening); !got.Equal(tt.want) {
+				t.Errorf("quietUntil() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendQueue_quietHours(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	bot := &Bot{
+		API: &mockAPI{
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				sent = append(sent, msgValues(opts...).Get("text"))
+				return s, "1.1", nil
+			},
+		},
+		Clock:       fixedClock{Clock: RealClock, now: time.Date(2021, 3, 5, 22, 0, 0, 0, time.UTC)},
+		QuietHours:  []QuietHours{{Start: "7pm", End: "8am", Location: time.UTC, MinPriority: PriorityAlert}},
+		SendQueue:   &SendQueue{Interval: time.Millisecond},
+		userDetails: &slack.UserDetails{ID: "bot"},
+	}
+	defer bot.Stop()
+	if _, _, err := bot.Reply("C1", "standup in 5 minutes"); !errors.Is(err, ErrMessageHeld) {
+		t.Errorf("Reply() error = %v, want %v", err, ErrMessageHeld)
+	}
+	if _, ts, err := bot.Alert("C1", "the api is down"); err != nil || ts != "1.1" {
+		t.Errorf("Alert() = %q, %v, want 1.1, nil", ts, err)
+	}
+	if got := bot.QueuedMessages(); got != 1 {
+		t.Errorf("QueuedMessages() = %d, want 1", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) != 1 || sent[0] != "the api is down" {
+		t.Errorf("sent = %q, want only the alert", sent)
+	}
+}
@@ -0,0 +1,25 @@
+package slackbot
+
+import (
+	"fmt"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	readOnlyMessage            = "This is disabled in this environment."
+	readOnlyEnvironmentMessage = "This is disabled in the %s environment."
+)
+
+// readOnlyBlocked returns true and tells the user if the command is destructive and the bot is ReadOnly.
+func (bot *Bot) readOnlyBlocked(destructive bool, ev *slack.MessageEvent) bool {
+	if !destructive || !bot.ReadOnly {
+		return false
+	}
+	msg := readOnlyMessage
+	if bot.Environment != "" {
+		msg = fmt.Sprintf(readOnlyEnvironmentMessage, bot.Environment)
+	}
+	_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), msg)
+	return true
+}
@@ -0,0 +1,87 @@
+package slackbot
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_readOnly(t *testing.T) {
+	tests := []struct {
+		name        string
+		readOnly    bool
+		environment string
+		destructive bool
+		text        string
+		wantRan     bool
+		wantReplies []string
+	}{
+		{
+			name:        "should ask to confirm destructive listeners when the bot isn't read only",
+			destructive: true,
+			text:        "deploy",
+			wantReplies: []string{confirmPromptMessage},
+		},
+		{
+			name:     "should run listeners that aren't destructive when the bot is read only",
+			readOnly: true,
+			text:     "deploy",
+			wantRan:  true,
+		},
+		{
+			name:        "should disable destructive listeners when the bot is read only",
+			readOnly:    true,
+			environment: "staging",
+			destructive: true,
+			text:        "deploy",
+			wantReplies: []string{"This is disabled in the staging environment."},
+		},
+		{
+			name:        "should disable destructive exchanges when the bot is read only",
+			readOnly:    true,
+			destructive: true,
+			text:        "delete",
+			wantReplies: []string{"This is disabled in this environment."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var replies []string
+			ran := false
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						replies = append(replies, msgValues(opts...).Get("text"))
+						return s, "ts", nil
+					},
+				},
+				Environment: tt.environment,
+				ReadOnly:    tt.readOnly,
+				DirectListeners: []Listener{{
+					Regex:       regexp.MustCompile(`^deploy$`),
+					Destructive: tt.destructive,
+					Handler:     func(bot *Bot, ev *slack.MessageEvent) { ran = true },
+				}},
+				Exchanges: []Exchange{{
+					Regex:       regexp.MustCompile(`^delete$`),
+					Destructive: tt.destructive,
+					Steps: map[int]*Step{1: {Handler: func(ex *Exchange) error {
+						ran = true
+						return nil
+					}}},
+				}},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			bot.init()
+			bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: "U1", Text: tt.text, Timestamp: "1.1"}})
+			if ran != tt.wantRan {
+				t.Errorf("handler ran = %v, want %v", ran, tt.wantRan)
+			}
+			if !reflect.DeepEqual(replies, tt.wantReplies) {
+				t.Errorf("replies = %q, want %q", replies, tt.wantReplies)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package slackbot
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const recordedMessageType = "message"
+
+// recordedEvent is a single line written by the event recorder.
+type recordedEvent struct {
+	Time  time.Time       `json:"time"`
+	Type  string          `json:"type"`
+	Event json.RawMessage `json:"event"`
+}
+
+// Replay reads events written to the bot's RecordEvents writer and processes them in order as if they had just
+// been received from slack. Speed controls the delay between events, 1 replays them with their original timing,
+// 2 replays them twice as fast, and 0 replays them without any delay. Each event is processed before the next
+// one is read, and events of a type the bot doesn't process are skipped.
+func (bot *Bot) Replay(r io.Reader, speed float64) error {
+	var last time.Time
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var rec recordedEvent
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return errors.Wrapf(err, "unable to read recorded event on line %d", line)
+		}
+		if speed > 0 && !last.IsZero() {
+			time.Sleep(time.Duration(float64(rec.Time.Sub(last)) / speed))
+		}
+		last = rec.Time
+
+		switch rec.Type {
+		case recordedMessageType:
+			ev := &slack.MessageEvent{}
+			if err := json.Unmarshal(rec.Event, ev); err != nil {
+				return errors.Wrapf(err, "unable to read recorded message on line %d", line)
+			}
+			bot.HandleMessage(ev)
+		}
+	}
+	return scanner.Err()
+}
+
+// recordEvent writes the event to the bot's RecordEvents writer if it is set.
+func (bot *Bot) recordEvent(eventType string, ev interface{}) {
+	if bot.RecordEvents == nil {
+		return
+	}
+	data, err := json.Marshal(ev)
+	if err == nil {
+		data, err = json.Marshal(recordedEvent{Time: bot.clock().Now(), Type: eventType, Event: data})
+	}
+	if err == nil {
+		bot.recordMu.Lock()
+		_, err = fmt.Fprintf(bot.RecordEvents, "%s\n", data)
+		bot.recordMu.Unlock()
+	}
+	if err != nil {
+		bot.LogError(fmt.Sprintf("error recording %s event - %s", eventType, err))
+	}
+}
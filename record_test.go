@@ -0,0 +1,87 @@
+package slackbot
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_Replay(t *testing.T) {
+	tests := []struct {
+		name    string
+		events  []*slack.MessageEvent
+		speed   float64
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "should replay recorded messages in order",
+			events: []*slack.MessageEvent{
+				{Msg: slack.Msg{Channel: "D1", User: "U1", Text: "echo one", Timestamp: "1.1"}},
+				{Msg: slack.Msg{Channel: "D1", User: "U1", Text: "echo two", Timestamp: "1.2"}},
+			},
+			want: []string{"one", "two"},
+		},
+		{
+			name: "should replay with the original timing",
+			events: []*slack.MessageEvent{
+				{Msg: slack.Msg{Channel: "D1", User: "U1", Text: "echo one", Timestamp: "1.1"}},
+			},
+			speed: 1,
+			want:  []string{"one"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recording bytes.Buffer
+			recorder := &Bot{RecordEvents: &recording}
+			for _, ev := range tt.events {
+				recorder.recordEvent(recordedMessageType, ev)
+			}
+
+			var got []string
+			bot := &Bot{
+				API: &mockAPI{},
+				DirectListeners: []Listener{
+					{
+						Regex: regexp.MustCompile("^echo (.*)$"),
+						Handler: func(bot *Bot, ev *slack.MessageEvent) {
+							got = append(got, strings.TrimPrefix(ev.Text, "echo "))
+						},
+					},
+				},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			if err := bot.Replay(&recording, tt.speed); (err != nil) != tt.wantErr {
+				t.Errorf("Replay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Replay() handled = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_Replay_speed(t *testing.T) {
+	start := time.Now()
+	recording := strings.Join([]string{
+		`{"time":"2020-01-01T00:00:00Z","type":"message","event":{"text":"a"}}`,
+		`{"time":"2020-01-01T00:00:01Z","type":"message","event":{"text":"b"}}`,
+		`{"time":"2020-01-01T00:00:02Z","type":"unknown","event":{}}`,
+	}, "\n")
+	bot := &Bot{API: &mockAPI{}, userDetails: &slack.UserDetails{ID: "bot"}}
+	if err := bot.Replay(strings.NewReader(recording), 100); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Replay() at 100x took %s, want about 20ms", elapsed)
+	}
+	if err := bot.Replay(strings.NewReader("not json"), 0); err == nil {
+		t.Errorf("Replay() expected an error for an invalid recording")
+	}
+}
@@ -0,0 +1,302 @@
+package slackbot
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	"github.com/slack-go/slack"
+)
+
+const (
+	recurringStoreKey        = "recurring messages"
+	recurringCreatedMessage  = "Ok, I'll post %q here %s. To stop it, send me: delete recurring message %s"
+	recurringDeletedMessage  = "Deleted recurring message %s."
+	recurringNoneMessage     = "There are no recurring messages in this channel."
+	recurringErrorMessage    = "Sorry, I wasn't able to do that - %s"
+	recurringListItemMessage = "• `%s` %s: %s (added by <@%s>)"
+)
+
+var (
+	recurringAddRegex    = regexp.MustCompile(`^(?i)remind (?:this channel|here) (every .+?) to (.+)$`)
+	recurringListRegex   = regexp.MustCompile(`^(?i)list recurring messages$`)
+	recurringDeleteRegex = regexp.MustCompile(`^(?i)delete recurring message (\w+)$`)
+
+	recurrenceRegex      = regexp.MustCompile(`^every (.+?)(?: at (.+))?$`)
+	recurrenceEveryRegex = regexp.MustCompile(`^(\d+) (minute|hour)s?$`)
+)
+
+// RecurringMessage is a message the bot posts to a channel on a schedule. Unlike ScheduledTasks they are
+// created while the bot is running, for example by users in chat, and are saved in the bot's Store so they
+// are scheduled again when the bot restarts.
+type RecurringMessage struct {
+	ID        string
+	Channel   string
+	When      string
+	Schedule  string
+	Text      string
+	CreatedBy string
+	Created   time.Time
+}
+
+// recurringJob posts a recurring message when it is run by the scheduler.
+type recurringJob struct {
+	bot *Bot
+	msg RecurringMessage
+}
+
+func (j recurringJob) Run() {
+	defer j.bot.recoverPanic(ErrorInfo{Source: ErrorSourceScheduledTask, Channel: j.msg.Channel, User: j.msg.CreatedBy}, nil)
+	if _, _, err := j.bot.Reply(j.msg.Channel, j.msg.Text); err != nil {
+		j.bot.LogError(fmt.Sprintf("error posting recurring message %s - %s", j.msg.ID, err))
+	}
+}
+
+// AddRecurringMessage saves a message to post to the channel on a schedule and schedules it if the bot is
+// running. When is a phrase such as "every friday at 4pm", "every weekday at 9:30am", "every day" or "every
+// 2 hours", which is interpreted in the user's timezone, or a cron schedule.
+func (bot *Bot) AddRecurringMessage(channel string, when string, text string, user string) (*RecurringMessage, error) {
+	schedule, err := bot.recurrenceSchedule(user, when)
+	if err != nil {
+		return nil, err
+	}
+	msg := RecurringMessage{
+		ID:        newID(),
+		Channel:   channel,
+		When:      when,
+		Schedule:  schedule,
+		Text:      text,
+		CreatedBy: user,
+		Created:   bot.clock().Now(),
+	}
+
+	bot.recurringMu.Lock()
+	defer bot.recurringMu.Unlock()
+	messages := bot.loadRecurringMessages()
+	if err := bot.store().Put(recurringStoreKey, append(messages, msg)); err != nil {
+		return nil, errors.Wrap(err, "unable to save the recurring message")
+	}
+	bot.scheduleRecurring(msg)
+	return &msg, nil
+}
+
+// RecurringMessages returns the recurring messages for the channel, or for every channel if it is empty.
+func (bot *Bot) RecurringMessages(channel string) []RecurringMessage {
+	bot.recurringMu.Lock()
+	defer bot.recurringMu.Unlock()
+	messages := bot.loadRecurringMessages()
+	if channel == "" {
+		return messages
+	}
+	var inChannel []RecurringMessage
+	for _, m := range messages {
+		if m.Channel == channel {
+			inChannel = append(inChannel, m)
+		}
+	}
+	return inChannel
+}
+
+// DeleteRecurringMessage stops posting the recurring message with the ID and removes it from the Store.
+func (bot *Bot) DeleteRecurringMessage(ID string) error {
+	bot.recurringMu.Lock()
+	defer bot.recurringMu.Unlock()
+	messages := bot.loadRecurringMessages()
+	for i, m := range messages {
+		if m.ID != ID {
+			continue
+		}
+		if err := bot.store().Put(recurringStoreKey, append(messages[:i], messages[i+1:]...)); err != nil {
+			return errors.Wrap(err, "unable to delete the recurring message")
+		}
+		bot.mu.Lock()
+		s := bot.scheduler
+		bot.mu.Unlock()
+		if s != nil {
+			s.unscheduleRecurring(ID)
+		}
+		return nil
+	}
+	return errors.Errorf("there is no recurring message %s", ID)
+}
+
+// scheduleRecurringMessages schedules the recurring messages saved in the Store when the bot starts.
+func (bot *Bot) scheduleRecurringMessages() {
+	bot.recurringMu.Lock()
+	defer bot.recurringMu.Unlock()
+	for _, m := range bot.loadRecurringMessages() {
+		bot.scheduleRecurring(m)
+	}
+}
+
+// scheduleRecurring schedules the message if the bot's scheduler is running.
+func (bot *Bot) scheduleRecurring(msg RecurringMessage) {
+	bot.mu.Lock()
+	s := bot.scheduler
+	bot.mu.Unlock()
+	if s == nil {
+		return
+	}
+	schedule, err := scheduleParser.Parse(msg.Schedule)
+	if err != nil {
+		bot.LogError(fmt.Sprintf("invalid schedule %q for recurring message %s - %s", msg.Schedule, msg.ID, err))
+		return
+	}
+	s.scheduleRecurring(msg.ID, schedule, recurringJob{bot: bot, msg: msg})
+}
+
+// loadRecurringMessages returns the recurring messages in the Store, recurringMu must be held.
+func (bot *Bot) loadRecurringMessages() []RecurringMessage {
+	var messages []RecurringMessage
+	_ = bot.store().Get(recurringStoreKey, &messages)
+	return messages
+}
+
+// recurrenceSchedule returns the cron schedule for the phrase in the user's timezone, or the phrase itself if
+// it is already a cron schedule.
+func (bot *Bot) recurrenceSchedule(user string, when string) (string, error) {
+	loc := time.Local
+	if l, err := bot.UserLocation(user); err == nil {
+		loc = l
+	}
+	schedule, err := parseRecurrence(when, loc)
+	if err == nil {
+		return schedule, nil
+	}
+	if _, cronErr := scheduleParser.Parse(when); cronErr == nil {
+		return when, nil
+	}
+	return "", err
+}
+
+// parseRecurrence returns the cron schedule for phrases such as "every friday at 4pm", "every weekday",
+// "every day at 9:30am", "every hour" or "every 15 minutes". The time of day defaults to 9am.
+func parseRecurrence(text string, loc *time.Location) (string, error) {
+	t := strings.ToLower(strings.Join(strings.Fields(text), " "))
+	m := recurrenceRegex.FindStringSubmatch(t)
+	if m == nil {
+		return "", errors.Errorf("unable to parse a recurring time from %q, try something like every friday at 4pm", text)
+	}
+	period := m[1]
+
+	if e := recurrenceEveryRegex.FindStringSubmatch(period); e != nil {
+		n, _ := strconv.Atoi(e[1])
+		if n < 1 {
+			return "", errors.Errorf("invalid interval in %q", text)
+		}
+		return fmt.Sprintf("@every %d%s", n, e[2][:1]), nil
+	}
+	switch period {
+	case "minute":
+		return "* * * * *", nil
+	case "hour":
+		return "0 * * * *", nil
+	}
+
+	tod := defaultTimeOfDay
+	if m[2] != "" {
+		var err error
+		if tod, err = parseTimeOfDay(m[2]); err != nil {
+			return "", err
+		}
+	}
+	var days string
+	switch period {
+	case "day":
+		days = "*"
+	case "weekday", "weekdays":
+		days = "1-5"
+	default:
+		wd, ok := weekdays[strings.TrimSuffix(period, "s")]
+		if !ok {
+			return "", errors.Errorf("unable to parse a recurring time from %q, try something like every friday at 4pm", text)
+		}
+		days = strconv.Itoa(int(wd))
+	}
+	schedule := fmt.Sprintf("%d %d * * %s", int(tod%time.Hour/time.Minute), int(tod/time.Hour), days)
+	if loc != nil && loc != time.Local {
+		schedule = fmt.Sprintf("CRON_TZ=%s %s", loc, schedule)
+	}
+	return schedule, nil
+}
+
+// RecurringMessageListeners returns DirectListeners that let users "remind this channel every friday at 4pm to
+// submit timesheets", "list recurring messages" in a channel and "delete recurring message <id>". Only the
+// users and channels allowed by the admins ACL can delete recurring messages. They are not enabled by
+// default, add them to the bot's DirectListeners to enable them.
+func RecurringMessageListeners(admins *ACL) []Listener {
+	return []Listener{
+		{
+			Usage: "remind this channel every <day> at <time> to <message>",
+			Regex: recurringAddRegex,
+			Handler: func(bot *Bot, ev *slack.MessageEvent) {
+				m := recurringAddRegex.FindStringSubmatch(ev.Text)
+				msg, err := bot.AddRecurringMessage(ev.Channel, m[1], m[2], ev.User)
+				reply := fmt.Sprintf(recurringErrorMessage, err)
+				if err == nil {
+					reply = fmt.Sprintf(recurringCreatedMessage, msg.Text, msg.When, msg.ID)
+				}
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), reply)
+			},
+		},
+		{
+			Usage: "list recurring messages",
+			Regex: recurringListRegex,
+			Handler: func(bot *Bot, ev *slack.MessageEvent) {
+				reply := formatRecurringMessages(bot.RecurringMessages(ev.Channel))
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), reply)
+			},
+		},
+		{
+			Usage: "delete recurring message <id>",
+			Regex: recurringDeleteRegex,
+			ACL:   admins,
+			Handler: func(bot *Bot, ev *slack.MessageEvent) {
+				ID := recurringDeleteRegex.FindStringSubmatch(ev.Text)[1]
+				reply := fmt.Sprintf(recurringDeletedMessage, ID)
+				if err := bot.DeleteRecurringMessage(ID); err != nil {
+					reply = fmt.Sprintf(recurringErrorMessage, err)
+				}
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), reply)
+			},
+		},
+	}
+}
+
+func formatRecurringMessages(messages []RecurringMessage) string {
+	if len(messages) == 0 {
+		return recurringNoneMessage
+	}
+	lines := make([]string, len(messages))
+	for i, m := range messages {
+		lines[i] = fmt.Sprintf(recurringListItemMessage, m.ID, m.When, m.Text, m.CreatedBy)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// scheduleRecurring schedules the job for a recurring message, replacing any job already scheduled for it.
+func (sc *scheduler) scheduleRecurring(ID string, schedule cron.Schedule, job cron.Job) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.recurring == nil {
+		sc.recurring = make(map[string]cron.EntryID)
+	}
+	if entry, ok := sc.recurring[ID]; ok {
+		sc.Remove(entry)
+	}
+	sc.recurring[ID] = sc.Schedule(schedule, job)
+}
+
+// unscheduleRecurring removes the job for a recurring message.
+func (sc *scheduler) unscheduleRecurring(ID string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if entry, ok := sc.recurring[ID]; ok {
+		sc.Remove(entry)
+		delete(sc.recurring, ID)
+	}
+}
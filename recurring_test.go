@@ -0,0 +1,223 @@
+package slackbot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	denver, _ := time.LoadLocation("America/Denver")
+	tests := []struct {
+		name    string
+		text    string
+		loc     *time.Location
+		want    string
+		wantErr bool
+	}{
+		{name: "should parse a day and time", text: "every Friday at 4pm", want: "0 16 * * 5"},
+		{name: "should parse plural days", text: "every mondays at 9:30am", want: "30 9 * * 1"},
+		{name: "should default to 9am", text: "every day", want: "0 9 * * *"},
+		{name: "should parse weekdays", text: "every weekday at noon", want: "0 12 * * 1-5"},
+		{name: "should parse every hour", text: "every hour", want: "0 * * * *"},
+		{name: "should parse intervals", text: "every 15 minutes", want: "@every 15m"},
+		{name: "should include the timezone", text: "every friday at 4pm", loc: denver, want: "CRON_TZ=America/Denver 0 16 * * 5"},
+		{name: "should error without every", text: "friday at 4pm", wantErr: true},
+		{name: "should error on an unknown day", text: "every blue moon", wantErr: true},
+		{name: "should error on an invalid time", text: "every friday at 25pm", wantErr: true},
+		{name: "should error on a zero interval", text: "every 0 minutes", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := tt.loc
+			if loc == nil {
+				loc = time.Local
+			}
+			got, err := parseRecurrence(tt.text, loc)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseRecurrence() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseRecurrence() = %v, want %v", got, tt.want)
+			}
+			if got != "" {
+				if _, err := scheduleParser.Parse(got); err != nil {
+					t.Errorf("parseRecurrence() returned an invalid schedule - %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestBot_AddRecurringMessage(t *testing.T) {
+	tests := []struct {
+		name         string
+		when         string
+		wantSchedule string
+		wantErr      bool
+	}{
+		{
+			name:         "should save and schedule a recurring message",
+			when:         "every friday at 4pm",
+			wantSchedule: "0 16 * * 5",
+		},
+		{
+			name:         "should accept a cron schedule",
+			when:         "0 16 * * 5",
+			wantSchedule: "0 16 * * 5",
+		},
+		{
+			name:    "should reject an invalid time",
+			when:    "every so often",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &mockCron{}
+			bot := &Bot{
+				API: &mockAPI{
+					getUserInfo: func(s string) (*slack.User, error) {
+						return &slack.User{ID: s}, nil
+					},
+				},
+				scheduler: &scheduler{cronScheduler: c},
+			}
+			msg, err := bot.AddRecurringMessage("C1", tt.when, "submit timesheets", "U1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AddRecurringMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(bot.RecurringMessages("")) != 0 || len(c.schedules) != 0 {
+					t.Errorf("AddRecurringMessage() saved an invalid message")
+				}
+				return
+			}
+			if msg.Schedule != tt.wantSchedule {
+				t.Errorf("AddRecurringMessage() schedule = %v, want %v", msg.Schedule, tt.wantSchedule)
+			}
+			saved := bot.RecurringMessages("C1")
+			if len(saved) != 1 || saved[0].ID != msg.ID || saved[0].Text != "submit timesheets" {
+				t.Errorf("RecurringMessages() = %v", saved)
+			}
+			if len(bot.RecurringMessages("C2")) != 0 {
+				t.Errorf("RecurringMessages() returned a message from another channel")
+			}
+			if len(c.schedules) != 1 {
+				t.Fatalf("AddRecurringMessage() scheduled %d jobs, want 1", len(c.schedules))
+			}
+
+			if err := bot.DeleteRecurringMessage(msg.ID); err != nil {
+				t.Errorf("DeleteRecurringMessage() error = %v", err)
+			}
+			if len(bot.RecurringMessages("")) != 0 || c.schedules[0] != nil {
+				t.Errorf("DeleteRecurringMessage() did not remove the message")
+			}
+			if err := bot.DeleteRecurringMessage(msg.ID); err == nil {
+				t.Errorf("DeleteRecurringMessage() of a deleted message should error")
+			}
+		})
+	}
+}
+
+func TestBot_scheduleRecurringMessages(t *testing.T) {
+	store := NewMemoryStore(nil)
+	_ = store.Put(recurringStoreKey, []RecurringMessage{
+		{ID: "a", Channel: "C1", Schedule: "0 16 * * 5", Text: "timesheets"},
+		{ID: "b", Channel: "C2", Schedule: "@daily", Text: "standup"},
+	})
+	c := &mockCron{}
+	bot := &Bot{Store: store, scheduler: &scheduler{cronScheduler: c}}
+	bot.scheduleRecurringMessages()
+	if len(c.schedules) != 2 {
+		t.Errorf("scheduleRecurringMessages() scheduled %d messages, want 2", len(c.schedules))
+	}
+}
+
+func TestRecurringJob_Run(t *testing.T) {
+	var got string
+	bot := &Bot{
+		API: &mockAPI{
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				got = s + ": " + msgValues(opts...).Get("text")
+				return s, "ts", nil
+			},
+		},
+	}
+	recurringJob{bot: bot, msg: RecurringMessage{Channel: "C1", Text: "submit timesheets"}}.Run()
+	if got != "C1: submit timesheets" {
+		t.Errorf("Run() posted %q", got)
+	}
+}
+
+func TestRecurringMessageListeners(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     string
+		messages []string
+		want     string
+	}{
+		{
+			name:     "should add a recurring message",
+			messages: []string{"remind this channel every Friday at 4pm to submit timesheets"},
+			want:     `Ok, I'll post "submit timesheets" here every Friday at 4pm. To stop it, send me: delete recurring message `,
+		},
+		{
+			name:     "should reply with the error for an invalid time",
+			messages: []string{"remind here every so often to submit timesheets"},
+			want:     "Sorry, I wasn't able to do that - unable to parse a recurring time",
+		},
+		{
+			name:     "should list the channel's recurring messages",
+			messages: []string{"remind here every day to stand up", "list recurring messages"},
+			want:     "every day: stand up (added by <@U1>)",
+		},
+		{
+			name:     "should reply when there are no recurring messages",
+			messages: []string{"list recurring messages"},
+			want:     recurringNoneMessage,
+		},
+		{
+			name:     "should reply with the error for an unknown message",
+			messages: []string{"delete recurring message abc123"},
+			want:     "there is no recurring message abc123",
+		},
+		{
+			name:     "should not let other users delete recurring messages",
+			user:     "U2",
+			messages: []string{"delete recurring message abc123"},
+			want:     aclUserDeniedMessage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						got = msgValues(opts...).Get("text")
+						return s, "ts", nil
+					},
+					getUserInfo: func(s string) (*slack.User, error) {
+						return &slack.User{ID: s}, nil
+					},
+				},
+				DirectListeners: RecurringMessageListeners(&ACL{Users: []string{"U1"}}),
+				userDetails:     &slack.UserDetails{ID: "bot"},
+			}
+			bot.init()
+			user := tt.user
+			if user == "" {
+				user = "U1"
+			}
+			for _, text := range tt.messages {
+				bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: user, Text: text, Timestamp: "1.1"}})
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("reply = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package slackbot
+
+import (
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// DefaultRedactPatterns match slack tokens, bearer authorization headers and basic auth credentials in urls.
+// They are always applied to log messages, see Bot.RedactPatterns.
+var DefaultRedactPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`xox[abposre]-[A-Za-z0-9-]+`),
+	regexp.MustCompile(`xapp-[A-Za-z0-9-]+`),
+	regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`),
+	regexp.MustCompile(`://[^/\s:@]+:[^/\s@]+@`),
+}
+
+// redact replaces secrets in the message with [REDACTED]. The bot's Token, SearchToken and SigningSecret are
+// always removed, along with anything matching the DefaultRedactPatterns or the bot's RedactPatterns.
+func (bot *Bot) redact(msg string) string {
+	for _, secret := range []string{bot.token(), bot.SearchToken, bot.SigningSecret} {
+		if secret != "" {
+			msg = strings.Replace(msg, secret, redacted, -1)
+		}
+	}
+	for _, p := range DefaultRedactPatterns {
+		msg = redactPattern(p, msg)
+	}
+	for _, p := range bot.RedactPatterns {
+		msg = redactPattern(p, msg)
+	}
+	return msg
+}
+
+// redactPattern replaces matches of the pattern, keeping the url scheme separator and @ of url credentials
+// so the redacted url can still be read.
+func redactPattern(p *regexp.Regexp, msg string) string {
+	return p.ReplaceAllStringFunc(msg, func(match string) string {
+		if strings.HasPrefix(match, "://") && strings.HasSuffix(match, "@") {
+			return "://" + redacted + "@"
+		}
+		return redacted
+	})
+}
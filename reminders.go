@@ -0,0 +1,115 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+var reminderRegex = regexp.MustCompile(`^(?i)remind me to (.+?) ((?:in|at|on|every|tomorrow|next)\b.*)$`)
+
+// RemindUser will create a Slack reminder for the user. When can be a time.Time, a time.Duration
+// from now, or a string that Slack will parse as natural language such as "in 15 minutes" or "every Thursday".
+func (bot *Bot) RemindUser(user string, text string, when interface{}) (*slack.Reminder, error) {
+	return bot.RemindUserContext(bot.context(), user, text, when)
+}
+
+// RemindUserContext creates the reminder like RemindUser, returning ctx's error if it is done before slack
+// responds.
+func (bot *Bot) RemindUserContext(ctx context.Context, user string, text string, when interface{}) (*slack.Reminder, error) {
+	t, err := reminderTime(when, bot.clock().Now())
+	if err != nil {
+		return nil, err
+	}
+	ID, err := bot.resolveID(user)
+	if err != nil {
+		return nil, err
+	}
+	var r *slack.Reminder
+	err = bot.withRetry(func() error {
+		return withContext(ctx, func() (err error) {
+			r, err = bot.api().AddUserReminder(ID, text, t)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// RemindChannel will create a Slack reminder in the channel. See RemindUser for the accepted values of when.
+func (bot *Bot) RemindChannel(channel string, text string, when interface{}) (*slack.Reminder, error) {
+	return bot.RemindChannelContext(bot.context(), channel, text, when)
+}
+
+// RemindChannelContext creates the reminder like RemindChannel, returning ctx's error if it is done before slack
+// responds.
+func (bot *Bot) RemindChannelContext(ctx context.Context, channel string, text string, when interface{}) (*slack.Reminder, error) {
+	t, err := reminderTime(when, bot.clock().Now())
+	if err != nil {
+		return nil, err
+	}
+	ID, err := bot.resolveID(channel)
+	if err != nil {
+		return nil, err
+	}
+	var r *slack.Reminder
+	err = bot.withRetry(func() error {
+		return withContext(ctx, func() (err error) {
+			r, err = bot.api().AddChannelReminder(ID, text, t)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ReminderListener returns a DirectListener that lets users create reminders for themselves by
+// messaging the bot "remind me to <something> in 10 minutes". It is not enabled by default, add
+// it to the bot's DirectListeners to enable it.
+func ReminderListener() Listener {
+	return Listener{
+		Usage: "remind me to <something> in <amount of time>",
+		Regex: reminderRegex,
+		Handler: func(bot *Bot, ev *slack.MessageEvent) {
+			m := reminderRegex.FindStringSubmatch(ev.Text)
+
+			// Slack understands more phrases than ParseWhen, such as "every Thursday", so
+			// pass the phrase through to slack if it can't be parsed here.
+			var when interface{} = m[2]
+			if t, err := bot.ParseWhenFor(ev.User, m[2]); err == nil {
+				when = t
+			}
+			if _, err := bot.RemindUser(ev.User, m[1], when); err != nil {
+				_, _, _ = bot.ReplyInThread(ev.Channel, ev.Timestamp, fmt.Sprintf("Sorry, I wasn't able to create that reminder - %s", err))
+				return
+			}
+			_, _, _ = bot.ReplyInThread(ev.Channel, ev.Timestamp, fmt.Sprintf("Ok, I'll remind you to %s %s", m[1], m[2]))
+		},
+	}
+}
+
+// reminderTime returns when as the time slack expects, a time.Duration is added to now.
+func reminderTime(when interface{}, now time.Time) (string, error) {
+	switch w := when.(type) {
+	case time.Time:
+		return strconv.FormatInt(w.Unix(), 10), nil
+	case time.Duration:
+		return strconv.FormatInt(now.Add(w).Unix(), 10), nil
+	case string:
+		if w == "" {
+			return "", errors.New("reminder time can not be empty")
+		}
+		return w, nil
+	default:
+		return "", errors.Errorf("unsupported reminder time type %T", when)
+	}
+}
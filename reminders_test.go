@@ -0,0 +1,132 @@
+package slackbot
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func Test_reminderTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		when    interface{}
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "should format a time as a unix timestamp",
+			when:    time.Unix(1600000000, 0),
+			want:    "1600000000",
+			wantErr: false,
+		},
+		{
+			name: "should add a duration to now",
+			when: time.Minute,
+			want: "1600000060",
+		},
+		{
+			name:    "should pass a string through",
+			when:    "in 5 minutes",
+			want:    "in 5 minutes",
+			wantErr: false,
+		},
+		{
+			name:    "should error on an empty string",
+			when:    "",
+			wantErr: true,
+		},
+		{
+			name:    "should error on an unsupported type",
+			when:    5,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reminderTime(tt.when, time.Unix(1600000000, 0))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("reminderTime() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("reminderTime() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReminderListener(t *testing.T) {
+	var gotUser, gotText, gotTime string
+	bot := &Bot{
+		API: &mockAPI{
+			getUser: func(s string) (slack.User, error) {
+				return slack.User{ID: s}, nil
+			},
+			getUserInfo: func(s string) (*slack.User, error) {
+				return &slack.User{ID: s, TZ: "America/Denver"}, nil
+			},
+			addUserReminder: func(user string, text string, time string) (*slack.Reminder, error) {
+				gotUser, gotText, gotTime = user, text, time
+				return &slack.Reminder{}, nil
+			},
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				return "", "", nil
+			},
+		},
+	}
+	l := ReminderListener()
+	ev := &slack.MessageEvent{
+		Msg: slack.Msg{
+			User: "U123",
+			Text: "remind me to check the build every friday",
+		},
+	}
+	if !l.Regex.MatchString(ev.Text) {
+		t.Fatalf("ReminderListener() regex did not match %s", ev.Text)
+	}
+	l.Handler(bot, ev)
+	if gotUser != "U123" || gotText != "check the build" || gotTime != "every friday" {
+		t.Errorf("ReminderListener() got = %s %s %s", gotUser, gotText, gotTime)
+	}
+}
+
+func TestBot_RemindUser_duration(t *testing.T) {
+	var gotTime string
+	bot := &Bot{
+		API: &mockAPI{
+			getUser: func(s string) (slack.User, error) {
+				return slack.User{ID: s}, nil
+			},
+			addUserReminder: func(user string, text string, time string) (*slack.Reminder, error) {
+				gotTime = time
+				return &slack.Reminder{}, nil
+			},
+		},
+	}
+	before := time.Now().Add(time.Hour).Unix()
+	if _, err := bot.RemindUser("U123", "text", time.Hour); err != nil {
+		t.Fatalf("RemindUser() error = %v", err)
+	}
+	got, _ := strconv.ParseInt(gotTime, 10, 64)
+	if got < before || got > before+1 {
+		t.Errorf("RemindUser() time = %v, want about %v", got, before)
+	}
+}
+
+func TestBot_RemindUserContext_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	bot := &Bot{
+		API: &mockAPI{
+			getUser: func(s string) (slack.User, error) {
+				return slack.User{ID: s}, nil
+			},
+		},
+	}
+	if r, err := bot.RemindUserContext(ctx, "U123", "text", time.Hour); r != nil || err != context.Canceled {
+		t.Errorf("RemindUserContext() = %v, %v, want nil, %v", r, err, context.Canceled)
+	}
+}
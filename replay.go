@@ -0,0 +1,74 @@
+package slackbot
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultEventReplayWindow = 10 * time.Minute
+	seenEventsStoreKey       = "events:seen"
+	seenEventsInterval       = 5 * time.Second
+)
+
+// firstEvent returns true if the Events API event with the ID wasn't received within the bot's
+// EventReplayWindow, and remembers it. Events without an ID are always processed. IDs older than the window
+// are removed at most once every five seconds, and saved in a batch five seconds after the first new ID.
+func (bot *Bot) firstEvent(id string) bool {
+	if id == "" {
+		return true
+	}
+	bot.eventsMu.Lock()
+	defer bot.eventsMu.Unlock()
+	if bot.seenEvents == nil {
+		bot.seenEvents = bot.loadSeenEvents()
+	}
+	now := bot.clock().Now()
+	window := bot.EventReplayWindow
+	if window <= 0 {
+		window = defaultEventReplayWindow
+	}
+	if now.Sub(bot.seenPruned) >= seenEventsInterval {
+		for k, seen := range bot.seenEvents {
+			if now.Sub(seen) >= window {
+				delete(bot.seenEvents, k)
+			}
+		}
+		bot.seenPruned = now
+	}
+	if seen, ok := bot.seenEvents[id]; ok && now.Sub(seen) < window {
+		return false
+	}
+	bot.seenEvents[id] = now
+	if bot.PersistEventIDs && bot.Store != nil && bot.seenSave == nil {
+		bot.seenSave = bot.clock().AfterFunc(seenEventsInterval, bot.saveSeenEvents)
+	}
+	return true
+}
+
+// loadSeenEvents returns the event IDs saved in the Store if PersistEventIDs is set.
+func (bot *Bot) loadSeenEvents() map[string]time.Time {
+	seen := make(map[string]time.Time)
+	if !bot.PersistEventIDs || bot.Store == nil {
+		return seen
+	}
+	if err := bot.Store.Get(seenEventsStoreKey, &seen); err != nil || seen == nil {
+		return make(map[string]time.Time)
+	}
+	return seen
+}
+
+// saveSeenEvents saves the event IDs in the Store if there are new IDs waiting to be saved. It is called by
+// the timer started for the first new ID, and when the bot stops.
+func (bot *Bot) saveSeenEvents() {
+	bot.eventsMu.Lock()
+	defer bot.eventsMu.Unlock()
+	if bot.seenSave == nil {
+		return
+	}
+	bot.seenSave.Stop()
+	bot.seenSave = nil
+	if err := bot.Store.Put(seenEventsStoreKey, bot.seenEvents); err != nil {
+		bot.LogWarn(fmt.Sprintf("unable to save the ids of received events - %s", err))
+	}
+}
@@ -0,0 +1,136 @@
+package slackbot
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBot_firstEvent(t *testing.T) {
+	tests := []struct {
+		name   string
+		window time.Duration
+		seen   map[string]time.Time
+		id     string
+		want   bool
+	}{
+		{
+			name: "should process a new event",
+			id:   "Ev1",
+			want: true,
+		},
+		{
+			name: "should always process events without an id",
+			seen: map[string]time.Time{"": time.Now()},
+			want: true,
+		},
+		{
+			name: "should ignore an event received within the window",
+			seen: map[string]time.Time{"Ev1": time.Now().Add(-5 * time.Minute)},
+			id:   "Ev1",
+		},
+		{
+			name: "should process an event received before the default window",
+			seen: map[string]time.Time{"Ev1": time.Now().Add(-11 * time.Minute)},
+			id:   "Ev1",
+			want: true,
+		},
+		{
+			name:   "should ignore an event received within a longer window",
+			window: time.Hour,
+			seen:   map[string]time.Time{"Ev1": time.Now().Add(-30 * time.Minute)},
+			id:     "Ev1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{EventReplayWindow: tt.window, seenEvents: tt.seen}
+			if got := bot.firstEvent(tt.id); got != tt.want {
+				t.Errorf("firstEvent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_firstEvent_persisted(t *testing.T) {
+	tests := []struct {
+		name    string
+		persist bool
+		want    bool
+	}{
+		{
+			name:    "should ignore an event received before a restart",
+			persist: true,
+		},
+		{
+			name: "should forget events on restart without PersistEventIDs",
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore(nil)
+			before := &Bot{Store: store, PersistEventIDs: tt.persist}
+			before.firstEvent("Ev1")
+			before.Stop()
+			after := &Bot{Store: store, PersistEventIDs: tt.persist}
+			if got := after.firstEvent("Ev1"); got != tt.want {
+				t.Errorf("firstEvent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// countingStore is a Store that counts the values put in it.
+type countingStore struct {
+	*MemoryStore
+	mu   sync.Mutex
+	puts int
+}
+
+func (s *countingStore) Put(key string, value interface{}) error {
+	s.mu.Lock()
+	s.puts++
+	s.mu.Unlock()
+	return s.MemoryStore.Put(key, value)
+}
+
+func (s *countingStore) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.puts
+}
+
+func TestBot_firstEvent_savedInBatches(t *testing.T) {
+	store := &countingStore{MemoryStore: NewMemoryStore(nil)}
+	bot := &Bot{Store: store, PersistEventIDs: true}
+	for _, id := range []string{"Ev1", "Ev2", "Ev3", "Ev1"} {
+		bot.firstEvent(id)
+	}
+	if store.count() != 0 {
+		t.Errorf("saved the event IDs %d times before the interval, want 0", store.count())
+	}
+	bot.Stop()
+	var seen map[string]time.Time
+	if err := store.Get(seenEventsStoreKey, &seen); err != nil || len(seen) != 3 || store.count() != 1 {
+		t.Errorf("saved %d event IDs %d times, err = %v, want 3 saved once", len(seen), store.count(), err)
+	}
+}
+
+func TestBot_firstEvent_prunes(t *testing.T) {
+	now := time.Date(2021, 3, 5, 12, 0, 0, 0, time.UTC)
+	bot := &Bot{
+		Clock:      fixedClock{Clock: RealClock, now: now},
+		seenEvents: map[string]time.Time{"Ev1": now.Add(-time.Hour), "Ev2": now.Add(-time.Minute)},
+		seenPruned: now.Add(-time.Second),
+	}
+	bot.firstEvent("Ev3")
+	if len(bot.seenEvents) != 3 {
+		t.Errorf("seenEvents = %v, want old IDs kept until the next prune", bot.seenEvents)
+	}
+	bot.Clock = fixedClock{Clock: RealClock, now: now.Add(seenEventsInterval)}
+	bot.firstEvent("Ev4")
+	if _, ok := bot.seenEvents["Ev1"]; ok || len(bot.seenEvents) != 3 {
+		t.Errorf("seenEvents = %v, want the IDs older than the window removed", bot.seenEvents)
+	}
+}
@@ -0,0 +1,107 @@
+package slackbot
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// ErrorSourceListener is used for panics in direct and indirect listeners.
+	ErrorSourceListener = "listener"
+	// ErrorSourceFileListener is used for panics in file listeners.
+	ErrorSourceFileListener = "file listener"
+	// ErrorSourceExchange is used for errors returned from, and panics in, exchange steps.
+	ErrorSourceExchange = "exchange"
+	// ErrorSourceScheduledTask is used for panics in scheduled tasks.
+	ErrorSourceScheduledTask = "scheduled task"
+	// ErrorSourceJob is used for errors returned from, and panics in, background jobs.
+	ErrorSourceJob = "job"
+	// ErrorSourceConnection is used for slack connection failures.
+	ErrorSourceConnection = "connection"
+	// ErrorSourceShortcut is used for panics in shortcut handlers.
+	ErrorSourceShortcut = "shortcut"
+	// ErrorSourceUnfurl is used for panics in link unfurl handlers.
+	ErrorSourceUnfurl = "unfurl"
+	// ErrorSourceForm is used for panics in form submit functions.
+	ErrorSourceForm = "form"
+	// ErrorSourceCircuitBreaker is used when the CircuitBreaker trips.
+	ErrorSourceCircuitBreaker = "circuit breaker"
+	// ErrorSourceEvent is used for panics in event hooks such as OnEmojiAdded.
+	ErrorSourceEvent = "event"
+	// ErrorSourceWatchdog is used when one of the Watchdog's checks fails.
+	ErrorSourceWatchdog = "watchdog"
+)
+
+type (
+	// ErrorReporter is called with errors from handlers, recovered panics, and connection failures so
+	// they can be sent to an error tracking service such as Sentry or Bugsnag. When a bot has an
+	// ErrorReporter, panics in handlers are recovered instead of crashing the bot.
+	ErrorReporter interface {
+		ReportError(err error, info ErrorInfo)
+	}
+
+	// ErrorReporterFunc is a function that implements ErrorReporter.
+	ErrorReporterFunc func(err error, info ErrorInfo)
+
+	// ErrorInfo describes where a reported error came from. Event, Channel, User and Thread are set
+	// when the error happened while handling a message.
+	ErrorInfo struct {
+		Source  string
+		Event   *slack.MessageEvent
+		Channel string
+		User    string
+		Thread  string
+	}
+
+	// PanicError is reported when a panic is recovered. It holds the value passed to panic and the
+	// stack trace of the goroutine that panicked.
+	PanicError struct {
+		Value interface{}
+		Stack []byte
+	}
+)
+
+// ReportError calls f(err, info).
+func (f ErrorReporterFunc) ReportError(err error, info ErrorInfo) {
+	f(err, info)
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// reportError passes the error to the bot's ErrorReporter if it has one.
+func (bot *Bot) reportError(err error, info ErrorInfo) {
+	if bot.ErrorReporter != nil && err != nil {
+		bot.ErrorReporter.ReportError(err, info)
+	}
+}
+
+// recoverPanic must be deferred. A panic will be recovered and passed to onPanic as a *PanicError, or logged
+// and reported to the bot's ErrorReporter if onPanic is nil.
+func (bot *Bot) recoverPanic(info ErrorInfo, onPanic func(err error)) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	err := &PanicError{Value: r, Stack: debug.Stack()}
+	if onPanic != nil {
+		onPanic(err)
+		return
+	}
+	bot.LogError(fmt.Sprintf("recovered from panic in %s - %v", info.Source, r))
+	bot.reportError(err, info)
+}
+
+// messageErrorInfo returns the ErrorInfo for a message event.
+func messageErrorInfo(source string, ev *slack.MessageEvent) ErrorInfo {
+	return ErrorInfo{
+		Source:  source,
+		Event:   ev,
+		Channel: ev.Channel,
+		User:    ev.User,
+		Thread:  ev.ThreadTimestamp,
+	}
+}
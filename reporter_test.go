@@ -0,0 +1,97 @@
+package slackbot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func TestBot_recoverPanic(t *testing.T) {
+	tests := []struct {
+		name         string
+		timeout      time.Duration
+		wantReported bool
+		wantSource   string
+	}{
+		{
+			name:         "should recover and report a panic in a listener",
+			wantReported: true,
+			wantSource:   ErrorSourceListener,
+		},
+		{
+			name:         "should recover and report a panic in a listener with a timeout",
+			timeout:      time.Minute,
+			wantReported: true,
+			wantSource:   ErrorSourceListener,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reported error
+			var gotInfo ErrorInfo
+			bot := &Bot{
+				API: &mockAPI{},
+				ErrorReporter: ErrorReporterFunc(func(err error, info ErrorInfo) {
+					reported, gotInfo = err, info
+				}),
+			}
+			ev := &slack.MessageEvent{Msg: slack.Msg{Channel: "channel", User: "user"}}
+			bot.runListener(&Listener{
+				Timeout: tt.timeout,
+				Handler: func(bot *Bot, ev *slack.MessageEvent) {
+					panic("oops")
+				},
+			}, ev, false)
+
+			if (reported != nil) != tt.wantReported {
+				t.Fatalf("recoverPanic() reported = %v, want %v", reported, tt.wantReported)
+			}
+			if pe, ok := reported.(*PanicError); !ok || pe.Value != "oops" || len(pe.Stack) == 0 {
+				t.Errorf("recoverPanic() error = %#v, want a PanicError", reported)
+			}
+			if gotInfo.Source != tt.wantSource || gotInfo.Event != ev || gotInfo.Channel != "channel" || gotInfo.User != "user" {
+				t.Errorf("recoverPanic() info = %+v", gotInfo)
+			}
+		})
+	}
+}
+
+func TestBot_recoverPanic_noReporter(t *testing.T) {
+	api, logged := warningAPI()
+	bot := &Bot{API: api, ErrorChannel: "errors"}
+	bot.runListener(&Listener{
+		Handler: func(bot *Bot, ev *slack.MessageEvent) {
+			panic("oops")
+		},
+	}, &slack.MessageEvent{}, false)
+	if got := logged(); len(got) != 1 || !strings.Contains(got[0], "oops") {
+		t.Errorf("logged %q, want the recovered panic", got)
+	}
+}
+
+func TestExchange_handleError_report(t *testing.T) {
+	var reported error
+	var gotInfo ErrorInfo
+	bot := &Bot{
+		API: &mockAPI{},
+		ErrorReporter: ErrorReporterFunc(func(err error, info ErrorInfo) {
+			reported, gotInfo = err, info
+		}),
+		activeExchanges: map[string]*Exchange{},
+	}
+	ex := &Exchange{Bot: bot, Channel: "channel", Thread: "thread", User: "user"}
+	bot.activeExchanges["thread"] = ex
+
+	err := errors.New("step failed")
+	ex.handleError(nil, err)
+	if reported != err {
+		t.Errorf("handleError() reported = %v, want %v", reported, err)
+	}
+	want := ErrorInfo{Source: ErrorSourceExchange, Channel: "channel", User: "user", Thread: "thread"}
+	if gotInfo != want {
+		t.Errorf("handleError() info = %+v, want %+v", gotInfo, want)
+	}
+}
@@ -0,0 +1,82 @@
+package slackbot
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+// defaultResponsePolicy is used to retry messages sent to a response url when the bot has no RetryPolicy.
+var defaultResponsePolicy = &RetryPolicy{Attempts: 3, Backoff: 500 * time.Millisecond, MaxBackoff: 2 * time.Second}
+
+// Responder sends messages to the response url of a slash command or interaction. Unlike messages sent with
+// Reply, they can be shown only to the user who used the command, or replace or delete the message the
+// interaction came from. Slack accepts up to five messages to a response url within thirty minutes. Failed
+// messages are retried with the bot's RetryPolicy, or up to three times if it doesn't have one.
+//
+// Example:
+//
+//	responder := bot.Responder(callback.ResponseURL)
+//	if err := responder.ReplaceOriginal(ctx, slack.MsgOptionText("Approved by <@"+callback.User.ID+">", false)); err != nil {
+//		bot.LogError(err.Error())
+//	}
+type Responder struct {
+	URL string
+
+	// Channel is the channel of the command or interaction, messages are translated to its language if the
+	// bot has a Translator.
+	Channel string
+
+	bot *Bot
+}
+
+// Responder returns a Responder for the response url.
+func (bot *Bot) Responder(responseURL string) *Responder {
+	return &Responder{URL: responseURL, bot: bot}
+}
+
+// Ephemeral sends a message only the user who used the command or interaction can see.
+func (r *Responder) Ephemeral(ctx context.Context, options ...slack.MsgOption) error {
+	return r.send(ctx, slack.MsgOptionResponseURL(r.URL, slack.ResponseTypeEphemeral), options)
+}
+
+// InChannel sends a message everyone in the channel can see.
+func (r *Responder) InChannel(ctx context.Context, options ...slack.MsgOption) error {
+	return r.send(ctx, slack.MsgOptionResponseURL(r.URL, slack.ResponseTypeInChannel), options)
+}
+
+// ReplaceOriginal replaces the message the interaction came from, such as a message with buttons once one of
+// them is clicked.
+func (r *Responder) ReplaceOriginal(ctx context.Context, options ...slack.MsgOption) error {
+	return r.send(ctx, slack.MsgOptionReplaceOriginal(r.URL), options)
+}
+
+// DeleteOriginal deletes the message the interaction came from.
+func (r *Responder) DeleteOriginal(ctx context.Context) error {
+	return r.send(ctx, slack.MsgOptionDeleteOriginal(r.URL), nil)
+}
+
+// send posts the message to the response url with the mode, which must be added after the message is
+// translated and given the bot's persona because they rebuild its options.
+func (r *Responder) send(ctx context.Context, mode slack.MsgOption, options []slack.MsgOption) error {
+	if r.URL == "" {
+		return errors.New("there is no response url")
+	}
+	bot := r.bot
+	if err := bot.checkCircuitBreaker(r.Channel); err != nil {
+		return err
+	}
+	options = append(bot.withPersona(bot.translateReply(r.Channel, options)), mode)
+	p := bot.RetryPolicy
+	if p == nil {
+		p = defaultResponsePolicy
+	}
+	post := func() error {
+		_, _, err := bot.api().PostMessageContext(ctx, r.Channel, options...)
+		return err
+	}
+	err := bot.retry(p, post(), post)
+	return errors.Wrap(err, "unable to send a message to the response url")
+}
@@ -0,0 +1,99 @@
+package slackbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestResponder(t *testing.T) {
+	tests := []struct {
+		name     string
+		send     func(r *Responder) error
+		failures int
+		want     slack.Msg
+		wantErr  bool
+	}{
+		{
+			name: "should send an ephemeral message",
+			send: func(r *Responder) error {
+				return r.Ephemeral(context.Background(), slack.MsgOptionText("only you can see this", false))
+			},
+			want: slack.Msg{Text: "only you can see this", ResponseType: slack.ResponseTypeEphemeral},
+		},
+		{
+			name: "should send a message to the channel",
+			send: func(r *Responder) error {
+				return r.InChannel(context.Background(), slack.MsgOptionText("deployed", false))
+			},
+			want: slack.Msg{Text: "deployed", ResponseType: slack.ResponseTypeInChannel},
+		},
+		{
+			name: "should replace the original message",
+			send: func(r *Responder) error {
+				return r.ReplaceOriginal(context.Background(), slack.MsgOptionText("approved", false))
+			},
+			want: slack.Msg{Text: "approved", ReplaceOriginal: true},
+		},
+		{
+			name: "should delete the original message",
+			send: func(r *Responder) error {
+				return r.DeleteOriginal(context.Background())
+			},
+			want: slack.Msg{DeleteOriginal: true},
+		},
+		{
+			name: "should retry when slack is unavailable",
+			send: func(r *Responder) error {
+				return r.Ephemeral(context.Background(), slack.MsgOptionText("only you can see this", false))
+			},
+			failures: 2,
+			want:     slack.Msg{Text: "only you can see this", ResponseType: slack.ResponseTypeEphemeral},
+		},
+		{
+			name: "should give up after three attempts",
+			send: func(r *Responder) error {
+				return r.Ephemeral(context.Background(), slack.MsgOptionText("only you can see this", false))
+			},
+			failures: 3,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got slack.Msg
+			failures := tt.failures
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if failures > 0 {
+					failures--
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				_ = json.NewDecoder(r.Body).Decode(&got)
+				_, _ = w.Write([]byte("ok"))
+			}))
+			defer server.Close()
+
+			bot := &Bot{API: newSlackClient("token"), Clock: immediateClock{RealClock}}
+			err := tt.send(bot.Responder(server.URL))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("send error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Text != tt.want.Text || got.ResponseType != tt.want.ResponseType ||
+				got.ReplaceOriginal != tt.want.ReplaceOriginal || got.DeleteOriginal != tt.want.DeleteOriginal {
+				t.Errorf("sent %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResponder_noURL(t *testing.T) {
+	bot := &Bot{API: &mockAPI{}}
+	if err := bot.Responder("").Ephemeral(context.Background(), slack.MsgOptionText("hello", false)); err == nil {
+		t.Errorf("Ephemeral() error = nil, want an error")
+	}
+}
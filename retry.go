@@ -0,0 +1,101 @@
+package slackbot
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// RetryPolicy configures how outgoing slack api calls are retried when they fail with a transient
+// error such as a network error, a 5xx response, or being rate limited.
+type RetryPolicy struct {
+	// Attempts is the maximum number of times a call will be made, including the first attempt.
+	Attempts int
+
+	// Backoff is the delay before the first retry. It doubles after each attempt up to MaxBackoff.
+	// When slack responds with a rate limit error the delay it requests is used instead.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// Retryable decides if an error should be retried. If it is not set DefaultRetryable is used.
+	Retryable func(err error) bool
+}
+
+// DefaultRetryable returns true for network errors and errors slack marks as retryable, which
+// are rate limit errors and 5xx responses. Calls that failed because their context was cancelled or
+// its deadline passed are not retried.
+func DefaultRetryable(err error) bool {
+	if ue, ok := err.(*url.Error); ok && (ue.Err == context.Canceled || ue.Err == context.DeadlineExceeded) {
+		return false
+	}
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		return false
+	}
+	if r, ok := err.(interface{ Retryable() bool }); ok {
+		return r.Retryable()
+	}
+	if _, ok := err.(net.Error); ok {
+		return true
+	}
+	return false
+}
+
+// withRetry calls fn until it succeeds, returns an error that is not retryable, or the bot's
+// RetryPolicy runs out of attempts. Without a RetryPolicy fn is only called once, or twice if it failed
+// because the token was invalid and the bot's TokenProvider had a new one.
+func (bot *Bot) withRetry(fn func() error) error {
+	gen := bot.apiGeneration()
+	err := fn()
+	if bot.reloadAfterAuthError(gen, err) {
+		err = fn()
+	}
+	return bot.retry(bot.RetryPolicy, err, fn)
+}
+
+// withContext calls fn, which makes a slack api call that doesn't have a Context variant, and returns ctx's error
+// without waiting for fn if ctx is done first. fn keeps running in the background, so its results must only
+// be used when withContext returns nil.
+func withContext(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retry calls fn again after it failed with err until it succeeds, returns an error that is not retryable, or
+// the policy runs out of attempts.
+func (bot *Bot) retry(p *RetryPolicy, err error, fn func() error) error {
+	if p == nil {
+		return err
+	}
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = DefaultRetryable
+	}
+
+	backoff := p.Backoff
+	for attempt := 1; err != nil && attempt < p.Attempts && retryable(err); attempt++ {
+		wait := backoff
+		if rl, ok := err.(*slack.RateLimitedError); ok {
+			wait = rl.RetryAfter
+		}
+		bot.clock().Sleep(wait)
+		if backoff *= 2; p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+		err = fn()
+	}
+	return err
+}
@@ -0,0 +1,163 @@
+package slackbot
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func TestDefaultRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "should retry rate limit errors",
+			err:  &slack.RateLimitedError{RetryAfter: time.Second},
+			want: true,
+		},
+		{
+			name: "should retry network errors",
+			err:  &net.OpError{Op: "dial", Err: errors.New("connection refused")},
+			want: true,
+		},
+		{
+			name: "should not retry calls with a cancelled context",
+			err:  &url.Error{Op: "Post", URL: "https://slack.com/api/chat.postMessage", Err: context.Canceled},
+			want: false,
+		},
+		{
+			name: "should not retry calls past their deadline",
+			err:  context.DeadlineExceeded,
+			want: false,
+		},
+		{
+			name: "should not retry slack api errors",
+			err:  errors.New("channel_not_found"),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultRetryable(tt.err); got != tt.want {
+				t.Errorf("DefaultRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_ReplyWithOptions_retry(t *testing.T) {
+	transient := &slack.RateLimitedError{RetryAfter: time.Millisecond}
+	tests := []struct {
+		name      string
+		policy    *RetryPolicy
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "should only try once without a retry policy",
+			errs:      []error{transient, nil},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "should retry transient errors until the message is sent",
+			policy:    &RetryPolicy{Attempts: 3, Backoff: time.Millisecond},
+			errs:      []error{transient, transient, nil},
+			wantCalls: 3,
+			wantErr:   false,
+		},
+		{
+			name:      "should give up after the max attempts",
+			policy:    &RetryPolicy{Attempts: 2, Backoff: time.Millisecond},
+			errs:      []error{transient, transient, nil},
+			wantCalls: 2,
+			wantErr:   true,
+		},
+		{
+			name:      "should not retry errors that are not retryable",
+			policy:    &RetryPolicy{Attempts: 3, Backoff: time.Millisecond},
+			errs:      []error{errors.New("channel_not_found"), nil},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name: "should use a custom retryable func",
+			policy: &RetryPolicy{Attempts: 3, Backoff: time.Millisecond, Retryable: func(err error) bool {
+				return err.Error() == "channel_not_found"
+			}},
+			errs:      []error{errors.New("channel_not_found"), nil},
+			wantCalls: 2,
+			wantErr:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			bot := &Bot{
+				RetryPolicy: tt.policy,
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						err := tt.errs[calls]
+						calls++
+						return s, "ts", err
+					},
+				},
+			}
+			_, _, err := bot.ReplyWithOptions("channel", slack.MsgOptionText("hello", false))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReplyWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("ReplyWithOptions() calls = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func Test_withContext(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	block := make(chan struct{})
+	defer close(block)
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		fn      func() error
+		wantErr error
+	}{
+		{
+			name:    "should return fn's error",
+			ctx:     context.Background(),
+			fn:      func() error { return errors.New("failed") },
+			wantErr: errors.New("failed"),
+		},
+		{
+			name:    "should not call fn once ctx is done",
+			ctx:     cancelled,
+			fn:      func() error { panic("called fn") },
+			wantErr: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := withContext(tt.ctx, tt.fn); err == nil || err.Error() != tt.wantErr.Error() {
+				t.Errorf("withContext() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	t.Run("should not wait for fn after ctx is done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := withContext(ctx, func() error { <-block; return nil }); err != context.DeadlineExceeded {
+			t.Errorf("withContext() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+}
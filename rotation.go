@@ -0,0 +1,186 @@
+package slackbot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	"github.com/slack-go/slack"
+)
+
+const (
+	rotationStoreKeyPrefix = "rotation "
+	defaultRotationMessage = "{user} is on call this week."
+	defaultRotationTopic   = "On call: {user}"
+	rotationOnCallMessage  = "<@%s> is on call for %s."
+	rotationNoneMessage    = "There are no rotations."
+)
+
+var rotationOnCallRegex = regexp.MustCompile(`^(?i)who(?:'s| is) on call(?: for (.+?))?\??$`)
+
+type (
+	// Rotation announces who is on call. Each time the Schedule runs, the rotation moves on to the next of the
+	// Users, the Message is posted to the Channel and the channel's topic is set to the Topic, "{user}" is
+	// replaced with a mention of the user in both. The Schedule is a cron schedule like a ScheduledTask's, so
+	// "0 9 * * MON" hands over every Monday at 9am. The current position is saved in the bot's Store, so the
+	// rotation carries on where it left off when the bot restarts. Name identifies the rotation and must be
+	// unique.
+	Rotation struct {
+		Name     string
+		Users    []string
+		Schedule string
+		Channel  string
+
+		// Message defaults to "{user} is on call this week." and Topic defaults to "On call: {user}". Set
+		// KeepTopic to leave the channel's topic alone.
+		Message   string
+		Topic     string
+		KeepTopic bool
+	}
+
+	// rotationState is a rotation's position saved in the Store.
+	rotationState struct {
+		Index int
+	}
+
+	rotationJob struct {
+		bot  *Bot
+		name string
+	}
+)
+
+func (j rotationJob) Run() {
+	defer j.bot.recoverPanic(ErrorInfo{Source: ErrorSourceScheduledTask}, nil)
+	if _, err := j.bot.Rotate(j.name); err != nil {
+		j.bot.LogError(err.Error())
+	}
+}
+
+// OnCall returns the user who is currently on call for the rotation with the name.
+func (bot *Bot) OnCall(name string) (string, error) {
+	r := bot.rotation(name)
+	if r == nil {
+		return "", errors.Errorf("there is no rotation named %s", name)
+	}
+	if len(r.Users) == 0 {
+		return "", errors.Errorf("rotation %s has no users", r.Name)
+	}
+	bot.rotationMu.Lock()
+	defer bot.rotationMu.Unlock()
+	return r.Users[bot.loadRotationState(r.Name).Index%len(r.Users)], nil
+}
+
+// Rotate hands the rotation with the name over to the next user, announces them in the rotation's Channel and
+// returns them. It is called by the rotation's Schedule, and can be called to hand over early.
+func (bot *Bot) Rotate(name string) (string, error) {
+	r := bot.rotation(name)
+	if r == nil {
+		return "", errors.Errorf("there is no rotation named %s", name)
+	}
+	if len(r.Users) == 0 {
+		return "", errors.Errorf("rotation %s has no users", r.Name)
+	}
+	bot.rotationMu.Lock()
+	state := bot.loadRotationState(r.Name)
+	state.Index = (state.Index + 1) % len(r.Users)
+	err := bot.store().Put(rotationStoreKeyPrefix+r.Name, state)
+	bot.rotationMu.Unlock()
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to save rotation %s", r.Name)
+	}
+	user := r.Users[state.Index]
+	return user, bot.announceRotation(r, user)
+}
+
+// announceRotation posts the rotation's message and sets the channel's topic.
+func (bot *Bot) announceRotation(r *Rotation, user string) error {
+	if r.Channel == "" {
+		return nil
+	}
+	replacer := strings.NewReplacer("{user}", fmt.Sprintf("<@%s>", user))
+	message := r.Message
+	if message == "" {
+		message = defaultRotationMessage
+	}
+	if _, _, err := bot.Reply(r.Channel, replacer.Replace(message)); err != nil {
+		return errors.Wrapf(err, "unable to announce rotation %s", r.Name)
+	}
+	if r.KeepTopic {
+		return nil
+	}
+	topic := r.Topic
+	if topic == "" {
+		topic = defaultRotationTopic
+	}
+	return bot.SetTopic(r.Channel, replacer.Replace(topic))
+}
+
+// RotationListener returns a DirectListener that replies to "who is on call" with the user on call for each
+// of the bot's rotations, or "who is on call for <name>" for a single rotation.
+func RotationListener() Listener {
+	return Listener{
+		Usage: "who is on call [for <rotation>]",
+		Regex: rotationOnCallRegex,
+		Handler: func(bot *Bot, ev *slack.MessageEvent) {
+			names := []string{rotationOnCallRegex.FindStringSubmatch(ev.Text)[1]}
+			if names[0] == "" {
+				names = names[:0]
+				for _, r := range bot.Rotations {
+					names = append(names, r.Name)
+				}
+			}
+			if len(names) == 0 {
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), rotationNoneMessage)
+				return
+			}
+			lines := make([]string, len(names))
+			for i, name := range names {
+				user, err := bot.OnCall(name)
+				if err != nil {
+					lines[i] = err.Error()
+					continue
+				}
+				lines[i] = fmt.Sprintf(rotationOnCallMessage, user, name)
+			}
+			_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), strings.Join(lines, "\n"))
+		},
+	}
+}
+
+// scheduleRotations schedules the bot's rotations, it returns an error if one is invalid.
+func (bot *Bot) scheduleRotations(s *scheduler) error {
+	schedules := make([]cron.Schedule, len(bot.Rotations))
+	for i, r := range bot.Rotations {
+		schedule, err := scheduleParser.Parse(r.Schedule)
+		if err != nil {
+			return errors.Wrapf(err, "invalid schedule %q for rotation %s", r.Schedule, r.Name)
+		}
+		if len(r.Users) == 0 {
+			return errors.Errorf("rotation %s has no users", r.Name)
+		}
+		schedules[i] = schedule
+	}
+	for i, r := range bot.Rotations {
+		s.Schedule(schedules[i], rotationJob{bot: bot, name: r.Name})
+	}
+	return nil
+}
+
+// rotation returns the bot's rotation with the name, ignoring case.
+func (bot *Bot) rotation(name string) *Rotation {
+	for i := range bot.Rotations {
+		if strings.EqualFold(bot.Rotations[i].Name, name) {
+			return &bot.Rotations[i]
+		}
+	}
+	return nil
+}
+
+// loadRotationState returns the rotation's state in the Store, rotationMu must be held.
+func (bot *Bot) loadRotationState(name string) rotationState {
+	var state rotationState
+	_ = bot.store().Get(rotationStoreKeyPrefix+name, &state)
+	return state
+}
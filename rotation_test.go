@@ -0,0 +1,183 @@
+package slackbot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func testRotations() []Rotation {
+	return []Rotation{
+		{Name: "platform", Users: []string{"U1", "U2", "U3"}, Schedule: "0 9 * * MON", Channel: "C1"},
+		{Name: "support", Users: []string{"U4", "U5"}, Schedule: "@daily", Channel: "C2", Message: "{user} is on support today.", KeepTopic: true},
+	}
+}
+
+func TestBot_Rotate(t *testing.T) {
+	tests := []struct {
+		name         string
+		rotation     string
+		index        int
+		want         string
+		wantMessages []string
+		wantTopics   []string
+		wantErr      bool
+	}{
+		{
+			name:         "should hand over to the next user",
+			rotation:     "platform",
+			want:         "U2",
+			wantMessages: []string{"C1: <@U2> is on call this week."},
+			wantTopics:   []string{"C1: On call: <@U2>"},
+		},
+		{
+			name:         "should go back to the first user after the last",
+			rotation:     "platform",
+			index:        2,
+			want:         "U1",
+			wantMessages: []string{"C1: <@U1> is on call this week."},
+			wantTopics:   []string{"C1: On call: <@U1>"},
+		},
+		{
+			name:         "should use the rotation's message and keep the topic",
+			rotation:     "support",
+			want:         "U5",
+			wantMessages: []string{"C2: <@U5> is on support today."},
+		},
+		{
+			name:     "should return an error for an unknown rotation",
+			rotation: "database",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages, topics []string
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						messages = append(messages, s+": "+msgValues(opts...).Get("text"))
+						return s, "ts", nil
+					},
+					getChannel: func(s string) (slack.Channel, error) {
+						return testChannel(s, "on-call", false), nil
+					},
+					setTopic: func(c string, topic string) (*slack.Channel, error) {
+						topics = append(topics, c+": "+topic)
+						return nil, nil
+					},
+				},
+				Rotations: testRotations(),
+			}
+			_ = bot.store().Put(rotationStoreKeyPrefix+tt.rotation, rotationState{Index: tt.index})
+			got, err := bot.Rotate(tt.rotation)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Rotate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Rotate() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(messages, tt.wantMessages) {
+				t.Errorf("Rotate() sent %q, want %q", messages, tt.wantMessages)
+			}
+			if !reflect.DeepEqual(topics, tt.wantTopics) {
+				t.Errorf("Rotate() set topics %q, want %q", topics, tt.wantTopics)
+			}
+			if onCall, _ := bot.OnCall(tt.rotation); onCall != tt.want {
+				t.Errorf("OnCall() = %v, want %v", onCall, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotationListener(t *testing.T) {
+	tests := []struct {
+		name      string
+		rotations []Rotation
+		text      string
+		want      string
+	}{
+		{
+			name:      "should reply with who is on call for every rotation",
+			rotations: testRotations(),
+			text:      "who is on call?",
+			want:      "<@U1> is on call for platform.\n<@U4> is on call for support.",
+		},
+		{
+			name:      "should reply with who is on call for a rotation",
+			rotations: testRotations(),
+			text:      "who's on call for support",
+			want:      "<@U4> is on call for support.",
+		},
+		{
+			name:      "should reply when the rotation doesn't exist",
+			rotations: testRotations(),
+			text:      "who is on call for database?",
+			want:      "there is no rotation named database",
+		},
+		{
+			name: "should reply when there are no rotations",
+			text: "who is on call",
+			want: rotationNoneMessage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						got = msgValues(opts...).Get("text")
+						return s, "ts", nil
+					},
+				},
+				DirectListeners: []Listener{RotationListener()},
+				Rotations:       tt.rotations,
+				userDetails:     &slack.UserDetails{ID: "bot"},
+			}
+			bot.init()
+			bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: "U1", Text: tt.text, Timestamp: "1.1"}})
+			if got != tt.want {
+				t.Errorf("RotationListener() replied %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_scheduleRotations(t *testing.T) {
+	tests := []struct {
+		name      string
+		rotations []Rotation
+		wantCount int
+		wantErr   bool
+	}{
+		{
+			name:      "should schedule the rotations",
+			rotations: testRotations(),
+			wantCount: 2,
+		},
+		{
+			name:      "should return an error for an invalid schedule",
+			rotations: []Rotation{{Name: "platform", Users: []string{"U1"}, Schedule: "every monday"}},
+			wantErr:   true,
+		},
+		{
+			name:      "should return an error for a rotation without users",
+			rotations: []Rotation{{Name: "platform", Schedule: "@weekly"}},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &mockCron{}
+			bot := &Bot{Rotations: tt.rotations}
+			if err := bot.scheduleRotations(&scheduler{cronScheduler: c}); (err != nil) != tt.wantErr {
+				t.Fatalf("scheduleRotations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(c.schedules) != tt.wantCount {
+				t.Errorf("scheduleRotations() scheduled %d rotations, want %d", len(c.schedules), tt.wantCount)
+			}
+		})
+	}
+}
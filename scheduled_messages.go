@@ -0,0 +1,94 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+// ScheduleMessage will schedule a message to be posted to the channel at the time specified using
+// Slack's chat.scheduleMessage api. Unlike ScheduledTasks, Slack will post the message so the bot
+// does not need to be running at that time. The channel can be a channel or user name or ID.
+func (bot *Bot) ScheduleMessage(channel string, at time.Time, options ...slack.MsgOption) (respChannel string, postAt string, err error) {
+	return bot.ScheduleMessageContext(bot.context(), channel, at, options...)
+}
+
+// ScheduleMessageContext schedules the message like ScheduleMessage, returning ctx's error if it is done before
+// slack responds.
+func (bot *Bot) ScheduleMessageContext(ctx context.Context, channel string, at time.Time, options ...slack.MsgOption) (respChannel string, postAt string, err error) {
+	if !at.After(bot.clock().Now()) {
+		return "", "", errors.Errorf("unable to schedule message for %s, the time has already passed", at)
+	}
+	ID, err := bot.resolveID(channel)
+	if err != nil {
+		return "", "", err
+	}
+	if err := bot.checkCircuitBreaker(ID); err != nil {
+		return "", "", err
+	}
+	options = bot.withPersona(options)
+	var c, t string
+	e := bot.withRetry(func() error {
+		return withContext(ctx, func() (err error) {
+			c, t, err = bot.api().ScheduleMessage(ID, strconv.FormatInt(at.Unix(), 10), options...)
+			return err
+		})
+	})
+	if e != nil {
+		bot.LogError(fmt.Sprintf("failure scheduling message to %s with - %s", channel, e))
+		return "", "", e
+	}
+	return c, t, nil
+}
+
+// ListScheduledMessages returns all of the messages scheduled by the bot that have not been posted yet.
+// If channel is empty the scheduled messages for every channel will be returned.
+func (bot *Bot) ListScheduledMessages(channel string) ([]slack.ScheduledMessage, error) {
+	params := &slack.GetScheduledMessagesParameters{}
+	if channel != "" {
+		ID, err := bot.resolveID(channel)
+		if err != nil {
+			return nil, err
+		}
+		params.Channel = ID
+	}
+
+	var messages []slack.ScheduledMessage
+	for {
+		var msgs []slack.ScheduledMessage
+		var cursor string
+		err := bot.withRetry(func() (err error) {
+			msgs, cursor, err = bot.api().GetScheduledMessagesContext(bot.context(), params)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msgs...)
+		if cursor == "" {
+			return messages, nil
+		}
+		params.Cursor = cursor
+	}
+}
+
+// CancelScheduledMessage deletes a scheduled message before it is posted. The ID can be
+// found on the messages returned from ListScheduledMessages.
+func (bot *Bot) CancelScheduledMessage(channel string, ID string) error {
+	chID, err := bot.resolveID(channel)
+	if err != nil {
+		return err
+	}
+	return bot.withRetry(func() error {
+		_, err := bot.api().DeleteScheduledMessageContext(bot.context(), &slack.DeleteScheduledMessageParameters{
+			Channel:            chID,
+			ScheduledMessageID: ID,
+			AsUser:             true,
+		})
+		return err
+	})
+}
@@ -0,0 +1,161 @@
+package slackbot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func TestBot_ScheduleMessage(t *testing.T) {
+	type fields struct {
+		API MessagingClient
+	}
+	type args struct {
+		channel string
+		at      time.Time
+	}
+	tests := []struct {
+		name            string
+		fields          fields
+		args            args
+		wantRespChannel string
+		wantPostAt      string
+		wantErr         bool
+	}{
+		{
+			name: "should schedule the message",
+			fields: fields{
+				API: &mockAPI{
+					getChannel: func(s string) (slack.Channel, error) {
+						return slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C123"}}}, nil
+					},
+					scheduleMessage: func(ch string, postAt string, opts ...slack.MsgOption) (string, string, error) {
+						return ch, postAt, nil
+					},
+				},
+			},
+			args: args{
+				channel: "general",
+				at:      time.Unix(4102444800, 0),
+			},
+			wantRespChannel: "C123",
+			wantPostAt:      "4102444800",
+			wantErr:         false,
+		},
+		{
+			name: "should error if the time has passed",
+			fields: fields{
+				API: &mockAPI{},
+			},
+			args: args{
+				channel: "general",
+				at:      time.Now().Add(-time.Minute),
+			},
+			wantErr: true,
+		},
+		{
+			name: "should error if the channel can not be resolved",
+			fields: fields{
+				API: &mockAPI{},
+			},
+			args: args{
+				channel: "general",
+				at:      time.Now().Add(time.Hour),
+			},
+			wantErr: true,
+		},
+		{
+			name: "should return an error if scheduling fails",
+			fields: fields{
+				API: &mockAPI{
+					getChannel: func(s string) (slack.Channel, error) {
+						return slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C123"}}}, nil
+					},
+					scheduleMessage: func(ch string, postAt string, opts ...slack.MsgOption) (string, string, error) {
+						return "", "", errors.New("error")
+					},
+				},
+			},
+			args: args{
+				channel: "general",
+				at:      time.Now().Add(time.Hour),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{
+				API: tt.fields.API,
+			}
+			gotRespChannel, gotPostAt, err := bot.ScheduleMessage(tt.args.channel, tt.args.at)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ScheduleMessage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotRespChannel != tt.wantRespChannel {
+				t.Errorf("ScheduleMessage() gotRespChannel = %v, want %v", gotRespChannel, tt.wantRespChannel)
+			}
+			if gotPostAt != tt.wantPostAt {
+				t.Errorf("ScheduleMessage() gotPostAt = %v, want %v", gotPostAt, tt.wantPostAt)
+			}
+		})
+	}
+}
+
+func TestBot_ListScheduledMessages(t *testing.T) {
+	type fields struct {
+		API MessagingClient
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    []slack.ScheduledMessage
+		wantErr bool
+	}{
+		{
+			name: "should return messages from every page",
+			fields: fields{
+				API: &mockAPI{
+					getScheduledMessages: func(params *slack.GetScheduledMessagesParameters) ([]slack.ScheduledMessage, string, error) {
+						if params.Cursor == "" {
+							return []slack.ScheduledMessage{{ID: "1"}}, "next", nil
+						}
+						return []slack.ScheduledMessage{{ID: "2"}}, "", nil
+					},
+				},
+			},
+			want:    []slack.ScheduledMessage{{ID: "1"}, {ID: "2"}},
+			wantErr: false,
+		},
+		{
+			name: "should return an error if listing fails",
+			fields: fields{
+				API: &mockAPI{
+					getScheduledMessages: func(params *slack.GetScheduledMessagesParameters) ([]slack.ScheduledMessage, string, error) {
+						return nil, "", errors.New("error")
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{
+				API: tt.fields.API,
+			}
+			got, err := bot.ListScheduledMessages("")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ListScheduledMessages() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListScheduledMessages() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,50 +1,165 @@
 package slackbot
 
-import "github.com/robfig/cron"
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+)
+
+// scheduleParser parses standard 5 field cron schedules, schedules with an optional leading seconds field,
+// and descriptors such as "@daily" and "@every 5m".
+var scheduleParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// jitterRand is seeded with the time the process started, so replicas of a bot choose different delays.
+var (
+	jitterMu   sync.Mutex
+	jitterRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
 
 type cronScheduler interface {
-	Schedule(cron.Schedule, cron.Job)
+	Schedule(cron.Schedule, cron.Job) cron.EntryID
+	Remove(cron.EntryID)
 	Start()
+	Stop() context.Context
 }
 
 type (
-	// ScheduledTask is used to run the Task on a scheduled cron using the string Schedule
+	// ScheduledTask is used to run the Task on a scheduled cron using the string Schedule. The Schedule is a
+	// standard cron expression, optionally with a leading seconds field, or a descriptor such as "@hourly" or
+	// "@every 5m". Name identifies the task in error messages.
 	ScheduledTask struct {
+		Name     string
 		Schedule string
 		Task     taskFunc
+
+		// TaskWithResult can be set instead of Task. The message it returns is sent to the TargetChannel, or
+		// logged to the DebugChannel if there isn't one, and an error is logged to the ErrorChannel and
+		// reported to the ErrorReporter, so tasks don't need to send their own replies.
+		TaskWithResult resultTaskFunc
+		TargetChannel  string
+
+		// Jitter delays each run of the task by a random duration up to Jitter, so tasks on many replicas of
+		// a bot, or many tasks scheduled at the top of the hour, don't all run at the same instant.
+		Jitter time.Duration
+
+		// WeekdaysOnly skips runs on weekends and SkipHolidays skips runs on holidays, as decided by the bot's
+		// Calendar, so a daily standup prompt doesn't go out when nobody is working.
+		WeekdaysOnly bool
+		SkipHolidays bool
 	}
 
 	scheduler struct {
 		cronScheduler
+		mu        sync.Mutex
+		recurring map[string]cron.EntryID
 	}
 
 	// wrapping the taskFunc to allow passing the Bot to the Task
 	taskFuncWrapper struct {
-		taskFunc taskFunc
-		bot      *Bot
+		name         string
+		taskFunc     taskFunc
+		resultFunc   resultTaskFunc
+		channel      string
+		bot          *Bot
+		jitter       time.Duration
+		weekdaysOnly bool
+		skipHolidays bool
 	}
 
-	taskFunc func(*Bot)
+	taskFunc       func(*Bot)
+	resultTaskFunc func(*Bot) (message string, err error)
 )
 
 func (t taskFuncWrapper) Run() {
-	t.taskFunc(t.bot)
+	if t.jitter > 0 {
+		select {
+		case <-t.bot.clock().After(randomDuration(t.jitter)):
+		case <-t.bot.stopChan():
+			return
+		}
+	}
+	if t.bot.taskPaused(t.name) || t.bot.skipDay(t.name, t.bot.clock().Now(), t.weekdaysOnly, t.skipHolidays) {
+		return
+	}
+	defer t.bot.recoverPanic(ErrorInfo{Source: ErrorSourceScheduledTask}, nil)
+	if t.taskFunc != nil {
+		t.taskFunc(t.bot)
+		return
+	}
+	msg, err := t.resultFunc(t.bot)
+	if err != nil {
+		t.bot.LogError(fmt.Sprintf("error running scheduled task %s - %s", t.name, err))
+		t.bot.reportError(err, ErrorInfo{Source: ErrorSourceScheduledTask, Channel: t.channel})
+		return
+	}
+	if msg == "" {
+		return
+	}
+	if t.channel == "" {
+		t.bot.LogDebug(fmt.Sprintf("scheduled task %s - %s", t.name, msg))
+		return
+	}
+	if _, _, err := t.bot.Reply(t.channel, msg); err != nil {
+		t.bot.LogError(fmt.Sprintf("error sending the result of scheduled task %s - %s", t.name, err))
+	}
+}
+
+// name returns the task's Name, or its position in the bot's ScheduledTasks if it doesn't have one.
+func (t *ScheduledTask) name(i int) string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return fmt.Sprintf("%d", i+1)
+}
+
+// parseSchedule parses the task's Schedule, the error names the task.
+func (t *ScheduledTask) parseSchedule(i int) (cron.Schedule, error) {
+	s, err := scheduleParser.Parse(t.Schedule)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid schedule %q for scheduled task %s", t.Schedule, t.name(i))
+	}
+	return s, nil
 }
 
 func (sc *scheduler) scheduleTasks(bot *Bot, tasks []ScheduledTask) error {
-	for _, t := range tasks {
-		s, err := cron.ParseStandard(t.Schedule)
+	schedules := make([]cron.Schedule, len(tasks))
+	for i := range tasks {
+		s, err := tasks[i].parseSchedule(i)
 		if err != nil {
 			return err
 		}
+		if tasks[i].Task == nil && tasks[i].TaskWithResult == nil {
+			return errors.Errorf("scheduled task %s has no Task", tasks[i].name(i))
+		}
+		schedules[i] = s
+	}
 
+	for i, t := range tasks {
 		tw := taskFuncWrapper{
-			bot:      bot,
-			taskFunc: t.Task,
+			name:         t.name(i),
+			bot:          bot,
+			taskFunc:     t.Task,
+			resultFunc:   t.TaskWithResult,
+			channel:      t.TargetChannel,
+			jitter:       t.Jitter,
+			weekdaysOnly: t.WeekdaysOnly,
+			skipHolidays: t.SkipHolidays,
 		}
-		sc.Schedule(s, tw)
+		sc.Schedule(schedules[i], tw)
 	}
 	sc.Start()
 
 	return nil
 }
+
+// randomDuration returns a random duration from 0 up to max.
+func randomDuration(max time.Duration) time.Duration {
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+	return time.Duration(jitterRand.Int63n(int64(max)))
+}
@@ -0,0 +1,274 @@
+package slackbot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/slack-go/slack"
+)
+
+type mockCron struct {
+	schedules []cron.Schedule
+	started   bool
+}
+
+func (c *mockCron) Schedule(s cron.Schedule, j cron.Job) cron.EntryID {
+	c.schedules = append(c.schedules, s)
+	return cron.EntryID(len(c.schedules))
+}
+
+func (c *mockCron) Remove(id cron.EntryID) {
+	c.schedules[id-1] = nil
+}
+
+func (c *mockCron) Start() {
+	c.started = true
+}
+
+func (c *mockCron) Stop() context.Context {
+	return context.Background()
+}
+
+func TestScheduler_scheduleTasks(t *testing.T) {
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	task := func(*Bot) {}
+	tests := []struct {
+		name     string
+		tasks    []ScheduledTask
+		wantNext []time.Time
+		wantErr  string
+	}{
+		{
+			name:     "should schedule a standard cron expression",
+			tasks:    []ScheduledTask{{Schedule: "30 8 * * *", Task: task}},
+			wantNext: []time.Time{from.Add(8*time.Hour + 30*time.Minute)},
+		},
+		{
+			name:     "should schedule an expression with seconds",
+			tasks:    []ScheduledTask{{Schedule: "*/15 * * * * *", Task: task}},
+			wantNext: []time.Time{from.Add(15 * time.Second)},
+		},
+		{
+			name: "should schedule descriptors",
+			tasks: []ScheduledTask{
+				{Schedule: "@every 5m", Task: task},
+				{Schedule: "@daily", Task: task},
+			},
+			wantNext: []time.Time{from.Add(5 * time.Minute), from.Add(24 * time.Hour)},
+		},
+		{
+			name: "should name the task with an invalid schedule",
+			tasks: []ScheduledTask{
+				{Name: "daily report", Schedule: "@daily", Task: task},
+				{Name: "standup", Schedule: "every morning", Task: task},
+			},
+			wantErr: `invalid schedule "every morning" for scheduled task standup`,
+		},
+		{
+			name:    "should number a task without a name",
+			tasks:   []ScheduledTask{{Schedule: "@daily", Task: task}, {Schedule: "61 * * * *", Task: task}},
+			wantErr: `invalid schedule "61 * * * *" for scheduled task 2`,
+		},
+		{
+			name:     "should schedule a task with a result",
+			tasks:    []ScheduledTask{{Schedule: "@hourly", TaskWithResult: func(*Bot) (string, error) { return "", nil }}},
+			wantNext: []time.Time{from.Add(time.Hour)},
+		},
+		{
+			name:    "should reject a task without a Task",
+			tasks:   []ScheduledTask{{Name: "report", Schedule: "@daily"}},
+			wantErr: "scheduled task report has no Task",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &mockCron{}
+			sc := &scheduler{cronScheduler: c}
+			err := sc.scheduleTasks(&Bot{}, tt.tasks)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("scheduleTasks() error = %v, want %v", err, tt.wantErr)
+				}
+				if len(c.schedules) > 0 || c.started {
+					t.Errorf("scheduleTasks() scheduled tasks when one was invalid")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("scheduleTasks() error = %v", err)
+			}
+			if !c.started || len(c.schedules) != len(tt.wantNext) {
+				t.Fatalf("scheduleTasks() scheduled %d tasks, want %d", len(c.schedules), len(tt.wantNext))
+			}
+			for i, s := range c.schedules {
+				if next := s.Next(from); !next.Equal(tt.wantNext[i]) {
+					t.Errorf("task %d next run = %v, want %v", i, next, tt.wantNext[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTaskFuncWrapper_Run(t *testing.T) {
+	tests := []struct {
+		name    string
+		jitter  time.Duration
+		stop    bool
+		wantRun bool
+	}{
+		{
+			name:    "should run the task",
+			wantRun: true,
+		},
+		{
+			name:    "should run the task after the jitter",
+			jitter:  10 * time.Millisecond,
+			wantRun: true,
+		},
+		{
+			name:   "should not run the task if the bot is stopped during the jitter",
+			jitter: time.Hour,
+			stop:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{}
+			if tt.stop {
+				bot.Stop()
+			}
+			ran := false
+			start := time.Now()
+			taskFuncWrapper{bot: bot, jitter: tt.jitter, taskFunc: func(*Bot) { ran = true }}.Run()
+			if ran != tt.wantRun {
+				t.Errorf("Run() ran = %v, want %v", ran, tt.wantRun)
+			}
+			if elapsed := time.Since(start); tt.wantRun && elapsed > tt.jitter+100*time.Millisecond {
+				t.Errorf("Run() took %v, want at most the jitter %v", elapsed, tt.jitter)
+			}
+		})
+	}
+}
+
+func TestRandomDuration(t *testing.T) {
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 100; i++ {
+		d := randomDuration(time.Minute)
+		if d < 0 || d >= time.Minute {
+			t.Fatalf("randomDuration() = %v, want between 0 and 1m", d)
+		}
+		seen[d] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("randomDuration() returned the same duration every time")
+	}
+}
+
+func TestTaskFuncWrapper_Run_result(t *testing.T) {
+	tests := []struct {
+		name         string
+		channel      string
+		message      string
+		err          error
+		wantMessages []string
+		wantReported bool
+	}{
+		{
+			name:         "should send the message to the target channel",
+			channel:      "reports",
+			message:      "3 deploys today",
+			wantMessages: []string{"reports: 3 deploys today"},
+		},
+		{
+			name:         "should log the message to the debug channel without a target channel",
+			message:      "3 deploys today",
+			wantMessages: []string{"debug: scheduled task report - 3 deploys today"},
+		},
+		{
+			name:    "should not send an empty message",
+			channel: "reports",
+		},
+		{
+			name:         "should send errors to the error channel",
+			channel:      "reports",
+			message:      "ignored",
+			err:          errors.New("no data"),
+			wantMessages: []string{"errors: [ERROR] error running scheduled task report - no data"},
+			wantReported: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages []string
+			reported := false
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						messages = append(messages, s+": "+msgValues(opts...).Get("text"))
+						return s, "ts", nil
+					},
+				},
+				DebugChannel: "debug",
+				ErrorChannel: "errors",
+				ErrorReporter: ErrorReporterFunc(func(err error, info ErrorInfo) {
+					reported = info.Source == ErrorSourceScheduledTask
+				}),
+			}
+			taskFuncWrapper{name: "report", bot: bot, channel: tt.channel, resultFunc: func(*Bot) (string, error) {
+				return tt.message, tt.err
+			}}.Run()
+			if strings.Join(messages, "\n") != strings.Join(tt.wantMessages, "\n") {
+				t.Errorf("Run() sent %q, want %q", messages, tt.wantMessages)
+			}
+			if reported != tt.wantReported {
+				t.Errorf("Run() reported = %v, want %v", reported, tt.wantReported)
+			}
+		})
+	}
+}
+
+// soonSchedule runs a job a few milliseconds after each run.
+type soonSchedule struct{}
+
+func (soonSchedule) Next(t time.Time) time.Time {
+	return t.Add(5 * time.Millisecond)
+}
+
+func TestTaskFuncWrapper_Run_recover(t *testing.T) {
+	logged := make(chan string, 10)
+	bot := &Bot{
+		ErrorChannel: "errors",
+		API: &mockAPI{postMessage: func(channel string, opts ...slack.MsgOption) (string, string, error) {
+			logged <- msgValues(opts...).Get("text")
+			return channel, "1.1", nil
+		}},
+	}
+	s := &scheduler{cronScheduler: cron.New()}
+	runs := make(chan struct{}, 10)
+	s.Schedule(soonSchedule{}, taskFuncWrapper{name: "report", bot: bot, taskFunc: func(*Bot) {
+		runs <- struct{}{}
+		panic("boom")
+	}})
+	s.Start()
+	defer s.Stop()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatalf("task ran %d times, want it to keep running after a panic", i)
+		}
+	}
+	select {
+	case msg := <-logged:
+		if !strings.Contains(msg, "boom") {
+			t.Errorf("logged %q, want the panic", msg)
+		}
+	case <-time.After(time.Second):
+		t.Error("the panic was not logged to the ErrorChannel")
+	}
+}
@@ -0,0 +1,137 @@
+package slackbot
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	defaultSearchLimit = 20
+	searchSortByTime   = "timestamp"
+	searchSortByScore  = "score"
+)
+
+var slackUserIDRegex = regexp.MustCompile(`^[UW][A-Z0-9]{6,}$`)
+
+type (
+	// SearchOptions narrow down a search. Channel and From can be names, IDs or mentions. Results are sorted newest
+	// first unless ByRelevance is set, and Limit defaults to 20.
+	SearchOptions struct {
+		Channel     string
+		From        string
+		Limit       int
+		ByRelevance bool
+	}
+
+	// SearchResult is a message found by SearchMessages.
+	SearchResult struct {
+		Channel     string
+		ChannelName string
+		User        string
+		Username    string
+		Timestamp   string
+		Time        time.Time
+		Text        string
+		Permalink   string
+	}
+)
+
+// SearchMessages searches slack for messages matching the query, which can use slack's search modifiers.
+// Every result has a permalink so it can be linked to in a reply. Slack only allows searching with a user
+// token that has the search:read scope, so searches use the bot's SearchToken, and return an error if it
+// isn't set and the bot's token is a bot token.
+func (bot *Bot) SearchMessages(query string, opts SearchOptions) ([]SearchResult, error) {
+	q := strings.TrimSpace(query)
+	if opts.Channel != "" {
+		q += " in:" + searchModifier(opts.Channel, channelMentionPrefix, channelPrefix, slackIDRegex)
+	}
+	if opts.From != "" {
+		q += " from:" + searchModifier(opts.From, userMentionPrefix, userPrefix, slackUserIDRegex)
+	}
+	params := slack.NewSearchParameters()
+	params.Sort = searchSortByTime
+	if opts.ByRelevance {
+		params.Sort = searchSortByScore
+	}
+	params.Count = opts.Limit
+	if params.Count <= 0 {
+		params.Count = defaultSearchLimit
+	}
+
+	var found *slack.SearchMessages
+	err := bot.withRetry(func() (err error) {
+		found, err = bot.searchClient().SearchMessagesContext(bot.context(), q, params)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to search for %s", q)
+	}
+	results := make([]SearchResult, 0, len(found.Matches))
+	for _, m := range found.Matches {
+		r := SearchResult{
+			Channel:     m.Channel.ID,
+			ChannelName: m.Channel.Name,
+			User:        m.User,
+			Username:    m.Username,
+			Timestamp:   m.Timestamp,
+			Time:        timestampTime(m.Timestamp),
+			Text:        m.Text,
+			Permalink:   m.Permalink,
+		}
+		if r.Permalink == "" {
+			if r.Permalink, err = bot.Permalink(r.Channel, r.Timestamp); err != nil {
+				return nil, err
+			}
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// searchClient returns the client for the bot's SearchToken, or the bot's client if it isn't set.
+func (bot *Bot) searchClient() MessagingClient {
+	if bot.SearchToken == "" {
+		return bot.api()
+	}
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.searchAPI == nil {
+		bot.searchAPI = bot.clientFor(bot.SearchToken)
+	}
+	return bot.searchAPI
+}
+
+// searchModifier returns the channel or user for an in: or from: search modifier. Slack only understands
+// names with their prefix or mentions, so IDs are sent as mentions.
+func searchModifier(identifier, mentionPrefix, namePrefix string, idRegex *regexp.Regexp) string {
+	if id, ok := mentionID(identifier, mentionPrefix); ok {
+		return mentionPrefix + id + ">"
+	}
+	name := strings.TrimPrefix(strings.TrimSpace(identifier), namePrefix)
+	if idRegex.MatchString(name) {
+		return mentionPrefix + name + ">"
+	}
+	return namePrefix + name
+}
+
+// String formats the result as a quote of the message with a link to it.
+func (r SearchResult) String() string {
+	quote := "> " + strings.Replace(r.Text, "\n", "\n> ", -1)
+	return fmt.Sprintf("%s\n<%s|%s> from <@%s> in <#%s>", quote, r.Permalink, r.Time.UTC().Format(userTimeLayout), r.User, r.Channel)
+}
+
+// timestampTime returns the time of a slack message timestamp, or the zero time if it is not valid.
+func timestampTime(ts string) time.Time {
+	f, err := strconv.ParseFloat(ts, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*1e9)).Round(time.Microsecond)
+}
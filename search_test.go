@@ -0,0 +1,168 @@
+package slackbot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func TestBot_SearchMessages(t *testing.T) {
+	match := slack.SearchMessage{
+		Channel:   slack.CtxChannel{ID: "C1", Name: "deploys"},
+		User:      "U1",
+		Username:  "alice",
+		Timestamp: "1600000000.000100",
+		Text:      "deploying api v2",
+	}
+	linked := match
+	linked.Permalink = "https://example.slack.com/archives/C1/p1600000000000100"
+	tests := []struct {
+		name       string
+		query      string
+		opts       SearchOptions
+		matches    []slack.SearchMessage
+		searchErr  error
+		wantQuery  string
+		wantParams slack.SearchParameters
+		want       []SearchResult
+		wantErr    bool
+	}{
+		{
+			name:       "should search newest first and return the results",
+			query:      "deploying",
+			matches:    []slack.SearchMessage{linked},
+			wantQuery:  "deploying",
+			wantParams: slack.SearchParameters{Sort: "timestamp", SortDirection: "desc", Count: 20, Page: 1},
+			want: []SearchResult{{
+				Channel:     "C1",
+				ChannelName: "deploys",
+				User:        "U1",
+				Username:    "alice",
+				Timestamp:   "1600000000.000100",
+				Time:        time.Unix(1600000000, 100000),
+				Text:        "deploying api v2",
+				Permalink:   "https://example.slack.com/archives/C1/p1600000000000100",
+			}},
+		},
+		{
+			name:       "should add the channel and user to the query and get missing permalinks",
+			query:      "deploying",
+			opts:       SearchOptions{Channel: "deploys", From: "@alice", Limit: 1, ByRelevance: true},
+			matches:    []slack.SearchMessage{match},
+			wantQuery:  "deploying in:#deploys from:@alice",
+			wantParams: slack.SearchParameters{Sort: "score", SortDirection: "desc", Count: 1, Page: 1},
+			want: []SearchResult{{
+				Channel:     "C1",
+				ChannelName: "deploys",
+				User:        "U1",
+				Username:    "alice",
+				Timestamp:   "1600000000.000100",
+				Time:        time.Unix(1600000000, 100000),
+				Text:        "deploying api v2",
+				Permalink:   "https://permalink/C1/1600000000.000100",
+			}},
+		},
+		{
+			name:       "should search for channel and user IDs as mentions",
+			query:      "deploying",
+			opts:       SearchOptions{Channel: "C0123456", From: "U0123456"},
+			matches:    []slack.SearchMessage{linked},
+			wantQuery:  "deploying in:<#C0123456> from:<@U0123456>",
+			wantParams: slack.SearchParameters{Sort: "timestamp", SortDirection: "desc", Count: 20, Page: 1},
+			want: []SearchResult{{
+				Channel:     "C1",
+				ChannelName: "deploys",
+				User:        "U1",
+				Username:    "alice",
+				Timestamp:   "1600000000.000100",
+				Time:        time.Unix(1600000000, 100000),
+				Text:        "deploying api v2",
+				Permalink:   "https://example.slack.com/archives/C1/p1600000000000100",
+			}},
+		},
+		{
+			name:       "should keep mentions of channels and users",
+			query:      "deploying",
+			opts:       SearchOptions{Channel: "<#C0123456|deploys>", From: "<@U0123456>"},
+			wantQuery:  "deploying in:<#C0123456> from:<@U0123456>",
+			wantParams: slack.SearchParameters{Sort: "timestamp", SortDirection: "desc", Count: 20, Page: 1},
+			want:       []SearchResult{},
+		},
+		{
+			name:       "should return an error if the search fails",
+			query:      "deploying",
+			searchErr:  errors.New("not_allowed_token_type"),
+			wantQuery:  "deploying",
+			wantParams: slack.SearchParameters{Sort: "timestamp", SortDirection: "desc", Count: 20, Page: 1},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query string
+			var params slack.SearchParameters
+			bot := &Bot{
+				API: &mockAPI{
+					searchMessages: func(q string, p slack.SearchParameters) (*slack.SearchMessages, error) {
+						query, params = q, p
+						if tt.searchErr != nil {
+							return nil, tt.searchErr
+						}
+						return &slack.SearchMessages{Matches: tt.matches}, nil
+					},
+					getPermalink: func(p *slack.PermalinkParameters) (string, error) {
+						return "https://permalink/" + p.Channel + "/" + p.Ts, nil
+					},
+				},
+			}
+			got, err := bot.SearchMessages(tt.query, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SearchMessages() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if query != tt.wantQuery || params != tt.wantParams {
+				t.Errorf("SearchMessages() searched %q %+v, want %q %+v", query, params, tt.wantQuery, tt.wantParams)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SearchMessages() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_SearchMessages_searchToken(t *testing.T) {
+	var searchedWith string
+	bot := &Bot{
+		API:         &mockAPI{},
+		SearchToken: "xoxp-search",
+		newClient: func(token string) MessagingClient {
+			return &mockAPI{searchMessages: func(q string, p slack.SearchParameters) (*slack.SearchMessages, error) {
+				searchedWith = token
+				return &slack.SearchMessages{}, nil
+			}}
+		},
+	}
+	if _, err := bot.SearchMessages("deploying", SearchOptions{}); err != nil {
+		t.Fatalf("SearchMessages() error = %v", err)
+	}
+	if searchedWith != "xoxp-search" {
+		t.Errorf("searched with %q, want the SearchToken", searchedWith)
+	}
+}
+
+func TestSearchResult_String(t *testing.T) {
+	r := SearchResult{
+		Channel:   "C1",
+		User:      "U1",
+		Time:      time.Date(2020, 9, 13, 12, 26, 40, 0, time.UTC),
+		Text:      "deploying api v2\nall regions",
+		Permalink: "https://permalink",
+	}
+	want := "> deploying api v2\n> all regions\n<https://permalink|Sun Sep 13 12:26 PM UTC> from <@U1> in <#C1>"
+	if got := r.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
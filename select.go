@@ -0,0 +1,126 @@
+package slackbot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	selectActionID         = "slackbot_select"
+	selectOptionsKeyPrefix = "slackbot select options "
+	selectMaxOptions       = 100
+	selectPlaceholder      = "Choose an option"
+	selectReplyMessage     = "Reply with the number of your choice."
+	selectInvalidMessage   = "%s is not one of the options, please reply with a number from 1 to %d."
+)
+
+// SelectMenu is a step that asks the user to choose one of the Options, which are produced when the step is
+// reached so they can come from somewhere that changes, such as the list of deployable services. If the bot is
+// Interactive the options are shown in a select menu, otherwise, or if there are too many options for a menu,
+// they are listed as numbered options to reply to. The user can reply with an option or its number either way.
+// The choice is put in the exchange's Store with the StoreKey, which defaults to the step's Name.
+type SelectMenu struct {
+	Prompt   string
+	Options  func(ex *Exchange) ([]string, error)
+	StoreKey string
+}
+
+// runSelect shows the step's menu when the step is reached, and stores the user's choice when they reply. It
+// returns true once a valid option has been chosen.
+func (ex *Exchange) runSelect(step *Step, ev *slack.MessageEvent) bool {
+	if ev == nil {
+		var options []string
+		var err error
+		ex.runStep(step, func() {
+			options, err = step.Select.Options(ex)
+		})
+		if err == nil && len(options) == 0 {
+			err = errors.New("there are no options to choose from")
+		}
+		if err == nil {
+			err = ex.Store.Put(selectOptionsKeyPrefix+step.Name, options)
+		}
+		if err != nil {
+			ex.handleError(step, err)
+			return false
+		}
+		ex.ReplyWithOptions(step.Select.msgOptions(ex.Bot.Interactive, options)...)
+		return false
+	}
+	var options []string
+	if err := ex.Store.Get(selectOptionsKeyPrefix+step.Name, &options); err != nil {
+		ex.handleError(step, err)
+		return false
+	}
+	choice, ok := selectChoice(options, ev.Text)
+	if !ok {
+		ex.Reply(fmt.Sprintf(selectInvalidMessage, strings.TrimSpace(ev.Text), len(options)))
+		return false
+	}
+	key := step.Select.StoreKey
+	if key == "" {
+		key = step.Name
+	}
+	if err := ex.Store.Put(key, choice); err != nil {
+		ex.handleError(step, err)
+		return false
+	}
+	return true
+}
+
+// msgOptions returns the message showing the options, with a select menu if the bot is interactive. The
+// numbered options are always sent as the text, so they are shown in notifications.
+func (s *SelectMenu) msgOptions(interactive bool, options []string) []slack.MsgOption {
+	lines := make([]string, 0, len(options)+2)
+	lines = append(lines, s.Prompt)
+	for i, o := range options {
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, o))
+	}
+	lines = append(lines, selectReplyMessage)
+	text := strings.Join(lines, "\n")
+	if !interactive || len(options) > selectMaxOptions {
+		return []slack.MsgOption{slack.MsgOptionText(text, false)}
+	}
+	blockOptions := make([]*slack.OptionBlockObject, len(options))
+	for i, o := range options {
+		blockOptions[i] = slack.NewOptionBlockObject(o, slack.NewTextBlockObject(slack.PlainTextType, o, false, false), nil)
+	}
+	menu := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, slack.NewTextBlockObject(slack.PlainTextType, selectPlaceholder, false, false), selectActionID, blockOptions...)
+	section := slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, s.Prompt, false, false), nil, slack.NewAccessory(menu))
+	return []slack.MsgOption{slack.MsgOptionBlocks(section), slack.MsgOptionText(text, false)}
+}
+
+// selectChoice returns the option matching the reply, either its number or the option itself ignoring case.
+func selectChoice(options []string, reply string) (string, bool) {
+	reply = strings.TrimSpace(reply)
+	if n, err := strconv.Atoi(reply); err == nil && n >= 1 && n <= len(options) {
+		return options[n-1], true
+	}
+	for _, o := range options {
+		if strings.EqualFold(o, reply) {
+			return o, true
+		}
+	}
+	return "", false
+}
+
+// handleSelect continues the exchange in the thread of a select menu with the option that was chosen.
+func (bot *Bot) handleSelect(callback *slack.InteractionCallback, action *slack.BlockAction) {
+	thread := callback.Message.ThreadTimestamp
+	ex, ok := bot.activeExchange(thread)
+	if !ok {
+		bot.LogDebug(fmt.Sprintf("no exchange in thread %s for select menu", thread))
+		return
+	}
+	ex.resume(&slack.MessageEvent{Msg: slack.Msg{
+		Channel:         callback.Channel.ID,
+		User:            callback.User.ID,
+		Text:            action.SelectedOption.Value,
+		Timestamp:       callback.ActionTs,
+		ThreadTimestamp: thread,
+	}})
+}
@@ -0,0 +1,188 @@
+package slackbot
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func testSelectExchange(chosen chan string, options []string, err error) Exchange {
+	return Exchange{
+		Regex: regexp.MustCompile(`^deploy$`),
+		Steps: map[int]*Step{
+			1: {
+				Name: "service",
+				Select: &SelectMenu{
+					Prompt: "Which service?",
+					Options: func(ex *Exchange) ([]string, error) {
+						return options, err
+					},
+				},
+			},
+			2: {
+				Name: "deploy",
+				Handler: func(ex *Exchange) error {
+					var service string
+					_ = ex.Store.Get("service", &service)
+					chosen <- service
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func TestExchange_runSelect(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     []string
+		err         error
+		interactive bool
+		replies     []string
+		want        string
+		wantReplies []string
+		wantBlocks  bool
+	}{
+		{
+			name:        "should store the option chosen by number",
+			options:     []string{"api", "web"},
+			replies:     []string{"2"},
+			want:        "web",
+			wantReplies: []string{"Which service?\n1. api\n2. web\nReply with the number of your choice."},
+		},
+		{
+			name:    "should ask again for an invalid choice and accept the option's name",
+			options: []string{"api", "web"},
+			replies: []string{"db", "API"},
+			want:    "api",
+			wantReplies: []string{
+				"Which service?\n1. api\n2. web\nReply with the number of your choice.",
+				"db is not one of the options, please reply with a number from 1 to 2.",
+			},
+		},
+		{
+			name:        "should show a select menu when the bot is interactive",
+			options:     []string{"api"},
+			interactive: true,
+			replies:     []string{"1"},
+			want:        "api",
+			wantReplies: []string{"Which service?\n1. api\nReply with the number of your choice."},
+			wantBlocks:  true,
+		},
+		{
+			name:    "should end the exchange when the options can't be listed",
+			err:     errors.New("boom"),
+			replies: []string{"1"},
+		},
+		{
+			name:    "should end the exchange when there are no options",
+			replies: []string{"1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var replies []string
+			blocks := false
+			chosen := make(chan string, 1)
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						values := msgValues(opts...)
+						replies = append(replies, values.Get("text"))
+						blocks = blocks || values.Get("blocks") != ""
+						return s, "ts", nil
+					},
+				},
+				Interactive:     tt.interactive,
+				Exchanges:       []Exchange{testSelectExchange(chosen, tt.options, tt.err)},
+				activeExchanges: map[string]*Exchange{},
+				userDetails:     &slack.UserDetails{ID: "bot"},
+			}
+			bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: "U1", Text: "deploy", Timestamp: "1.1"}})
+			for _, r := range tt.replies {
+				bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: "U1", Text: r, ThreadTimestamp: "1.1"}})
+			}
+			var got string
+			select {
+			case got = <-chosen:
+			default:
+			}
+			if got != tt.want {
+				t.Errorf("runSelect() stored %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(replies, tt.wantReplies) {
+				t.Errorf("replies = %q, want %q", replies, tt.wantReplies)
+			}
+			if blocks != tt.wantBlocks {
+				t.Errorf("sent blocks = %v, want %v", blocks, tt.wantBlocks)
+			}
+		})
+	}
+}
+
+func TestSelectMenu_msgOptions(t *testing.T) {
+	many := make([]string, selectMaxOptions+1)
+	for i := range many {
+		many[i] = "service"
+	}
+	tests := []struct {
+		name        string
+		interactive bool
+		options     []string
+		wantBlocks  bool
+	}{
+		{name: "should only send text when the bot isn't interactive", options: []string{"api"}},
+		{name: "should send a select menu when the bot is interactive", interactive: true, options: []string{"api"}, wantBlocks: true},
+		{name: "should fall back to text when there are too many options", interactive: true, options: many},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &SelectMenu{Prompt: "Which service?"}
+			values := msgValues(s.msgOptions(tt.interactive, tt.options)...)
+			if got := strings.Contains(values.Get("blocks"), selectActionID); got != tt.wantBlocks {
+				t.Errorf("msgOptions() blocks = %s, want select menu %v", values.Get("blocks"), tt.wantBlocks)
+			}
+			if !strings.HasPrefix(values.Get("text"), "Which service?\n1. ") {
+				t.Errorf("msgOptions() text = %q, want the numbered options", values.Get("text"))
+			}
+		})
+	}
+}
+
+func TestBot_handleSelect(t *testing.T) {
+	chosen := make(chan string, 1)
+	bot := &Bot{
+		API: &mockAPI{
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				return s, "ts", nil
+			},
+		},
+		Interactive: true,
+		Exchanges:   []Exchange{testSelectExchange(chosen, []string{"api", "web"}, nil)},
+		userDetails: &slack.UserDetails{ID: "bot"},
+	}
+	bot.once.Do(bot.init)
+	bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: "U1", Text: "deploy", Timestamp: "1.1"}})
+	callback := &slack.InteractionCallback{
+		Type:    slack.InteractionTypeBlockActions,
+		Channel: slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "D1"}}},
+		User:    slack.User{ID: "U1"},
+		Message: slack.Message{Msg: slack.Msg{Timestamp: "1.2", ThreadTimestamp: "1.1"}},
+		ActionCallback: slack.ActionCallbacks{BlockActions: []*slack.BlockAction{
+			{ActionID: selectActionID, SelectedOption: slack.OptionBlockObject{Value: "web"}},
+		}},
+	}
+	bot.HandleInteraction(callback)
+	select {
+	case got := <-chosen:
+		if got != "web" {
+			t.Errorf("handleSelect() stored %q, want web", got)
+		}
+	default:
+		t.Errorf("handleSelect() did not continue the exchange")
+	}
+}
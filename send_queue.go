@@ -0,0 +1,399 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// PriorityDebug is used for messages sent to the DebugChannel, they are sent after everything else.
+	PriorityDebug Priority = -1
+	// PriorityReply is the priority of messages sent with the Reply methods.
+	PriorityReply Priority = 0
+	// PriorityAlert is used for messages sent with Alert and to the ErrorChannel, they are sent first.
+	PriorityAlert Priority = 1
+
+	sendQueueStoreKey      = "send_queue"
+	defaultSendInterval    = time.Second
+	defaultSendAttempts    = 5
+	defaultSendRetryDelay  = time.Minute
+	sendQueueFailedMessage = "failure sending queued message %s to %s, attempt %d of %d - %s"
+)
+
+// ErrMessageQueued is returned when the bot stops before a queued message is sent. The message is still in the
+// SendQueue and will be sent when the bot starts again.
+var ErrMessageQueued = errors.New("the message is queued and will be sent when the bot restarts")
+
+type (
+	// Priority orders the messages waiting in the SendQueue, messages with the same priority are sent in the
+	// order they were queued.
+	Priority int
+
+	// SendQueue makes the bot's outgoing messages durable, for bots that must not lose notifications such as
+	// alerts. Messages sent with the Reply methods are saved in the bot's Store and sent in order by a single
+	// sender, no faster than one every Interval, one second by default. A message that fails to send is
+	// retried after the RetryDelay, one minute by default, and becomes a dead letter after MaxAttempts, 5 by
+	// default. Messages that haven't been sent when the bot stops are sent when it starts again, so the Store
+	// should be persistent. The Reply methods wait for the first attempt to send the message, so they still
+	// return its timestamp. When messages are being sent as fast as the Interval allows, messages with a
+	// higher Priority are sent first, so alerts aren't held up behind replies or debug output.
+	SendQueue struct {
+		Interval    time.Duration
+		MaxAttempts int
+		RetryDelay  time.Duration
+
+		mu      sync.Mutex
+		started bool
+		key     string
+		wake    chan struct{}
+		waiters map[string]chan sendResult
+	}
+
+	// queuedSend is a message in the SendQueue.
+	queuedSend struct {
+		ID          string
+		Channel     string
+		Values      url.Values
+		Priority    Priority
+		Log         bool
+		Attempts    int
+		Queued      time.Time
+		NextAttempt time.Time
+		Error       string
+	}
+
+	sendResult struct {
+		channel   string
+		timestamp string
+		err       error
+	}
+)
+
+// ReplyWithPriority sends a message like ReplyWithOptions. If the bot has a SendQueue, messages with a higher
+// priority are sent before those with a lower one, otherwise the priority is ignored.
+func (bot *Bot) ReplyWithPriority(priority Priority, channel string, options ...slack.MsgOption) (respChannel string, timestamp string, err error) {
+	return bot.reply(bot.context(), priority, channel, options)
+}
+
+// Alert sends a critical notification to the channel, it is sent before other messages waiting in the
+// SendQueue and during QuietHours unless their MinPriority is above PriorityAlert.
+func (bot *Bot) Alert(channel string, text string) (respChannel string, timestamp string, err error) {
+	return bot.ReplyWithPriority(PriorityAlert, channel, slack.MsgOptionText(text, false))
+}
+
+// send queues the message and waits for the first attempt to send it, or for ctx to be done. The message
+// stays in the queue if ctx is done first. Messages sent during QuietHours are held without waiting.
+func (q *SendQueue) send(ctx context.Context, bot *Bot, channel string, options []slack.MsgOption, priority Priority) (string, string, error) {
+	msg := &queuedSend{Channel: channel, Priority: priority}
+	result, err := q.enqueue(bot, msg, options)
+	if err != nil {
+		return "", "", err
+	}
+	if msg.NextAttempt.After(msg.Queued) {
+		q.mu.Lock()
+		delete(q.waiters, msg.ID)
+		q.mu.Unlock()
+		return channel, "", ErrMessageHeld
+	}
+	select {
+	case r := <-result:
+		return r.channel, r.timestamp, r.err
+	case <-bot.stopChan():
+		return channel, "", ErrMessageQueued
+	case <-ctx.Done():
+		return channel, "", ErrMessageQueued
+	}
+}
+
+// sendLog queues a message to the DebugChannel or ErrorChannel without waiting for it to be sent, failures to
+// send it are only written to the standard logger so they don't cause more log messages.
+func (q *SendQueue) sendLog(bot *Bot, channel string, priority Priority, options ...slack.MsgOption) {
+	if _, err := q.enqueue(bot, &queuedSend{Channel: channel, Priority: priority, Log: true}, options); err != nil {
+		log.Printf("Error sending message to log channel %s - %s\n", channel, err)
+	}
+}
+
+// enqueue saves the message in the Store and wakes the sender, the result of the first attempt to send it is
+// sent on the returned channel. Messages sent during QuietHours are due when they end.
+func (q *SendQueue) enqueue(bot *Bot, msg *queuedSend, options []slack.MsgOption) (<-chan sendResult, error) {
+	values, err := encodeMsgOptions(options...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to queue message to %s", msg.Channel)
+	}
+	now := bot.clock().Now()
+	msg.ID, msg.Values = newID(), values
+	msg.Queued, msg.NextAttempt = now, bot.quietUntil(msg.Channel, msg.Priority, now)
+	result := make(chan sendResult, 1)
+
+	q.resolveKey(bot)
+	q.mu.Lock()
+	err = bot.store().Put(q.storeKey(bot), append(q.load(bot), *msg))
+	if err == nil {
+		if q.waiters == nil {
+			q.waiters = make(map[string]chan sendResult)
+		}
+		q.waiters[msg.ID] = result
+	}
+	q.mu.Unlock()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to queue message to %s", msg.Channel)
+	}
+
+	q.start(bot)
+	q.notify()
+	return result, nil
+}
+
+// start starts the sender if it isn't already running, it stops when the bot is stopped.
+func (q *SendQueue) start(bot *Bot) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.started {
+		return
+	}
+	q.started = true
+	if q.wake == nil {
+		q.wake = make(chan struct{}, 1)
+	}
+	go q.run(bot)
+}
+
+func (q *SendQueue) notify() {
+	q.mu.Lock()
+	wake := q.wake
+	q.mu.Unlock()
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+// run sends the queued messages as they become due, waiting for the Interval between messages.
+func (q *SendQueue) run(bot *Bot) {
+	stop := bot.stopChan()
+	for {
+		q.resolveKey(bot)
+		msg, wait, ok := q.next(bot)
+		if ok {
+			q.deliver(bot, msg)
+			wait = q.interval()
+		}
+		var due <-chan time.Time
+		if wait > 0 {
+			due = bot.clock().After(wait)
+		}
+		select {
+		case <-stop:
+			return
+		case <-q.wake:
+		case <-due:
+		}
+	}
+}
+
+// next returns the first message with the highest priority that is due to be sent. If none are due it returns
+// how long until one is, or 0 if the queue is empty.
+func (q *SendQueue) next(bot *Bot) (queuedSend, time.Duration, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := bot.clock().Now()
+	var wait time.Duration
+	var next *queuedSend
+	queue := q.load(bot)
+	for i, m := range queue {
+		if now.Before(m.NextAttempt) {
+			if d := m.NextAttempt.Sub(now); wait == 0 || d < wait {
+				wait = d
+			}
+			continue
+		}
+		if next == nil || m.Priority > next.Priority {
+			next = &queue[i]
+		}
+	}
+	if next == nil {
+		return queuedSend{}, wait, false
+	}
+	return *next, 0, true
+}
+
+// due returns how many messages are due to be sent, not counting those held for QuietHours or waiting to be
+// retried.
+func (q *SendQueue) due(bot *Bot) int {
+	q.resolveKey(bot)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := bot.clock().Now()
+	count := 0
+	for _, m := range q.load(bot) {
+		if !now.Before(m.NextAttempt) {
+			count++
+		}
+	}
+	return count
+}
+
+// deliver sends the message, removing it from the queue if it was sent or has run out of attempts.
+func (q *SendQueue) deliver(bot *Bot, msg queuedSend) {
+	var c, t string
+	options, err := decodeMsgOptions(msg.Values)
+	if err == nil {
+		err = bot.withRetry(func() (err error) {
+			c, t, err = bot.api().PostMessageContext(bot.context(), msg.Channel, options...)
+			return err
+		})
+	}
+	exhausted, gone := false, bot.checkChannelUnavailable(msg.Channel, err)
+	var retryAt time.Time
+	if err != nil {
+		retryAt = bot.quietUntil(msg.Channel, msg.Priority, bot.clock().Now().Add(q.retryDelay()))
+	}
+
+	q.mu.Lock()
+	queue := q.load(bot)
+	for i, m := range queue {
+		if m.ID == msg.ID {
+			queue = append(queue[:i], queue[i+1:]...)
+			break
+		}
+	}
+	if err != nil {
+		msg.Attempts++
+		msg.Error = err.Error()
+		msg.NextAttempt = retryAt
+		if exhausted = gone || msg.Attempts >= q.maxAttempts(); !exhausted {
+			queue = append(queue, msg)
+		}
+	}
+	if e := bot.store().Put(q.storeKey(bot), queue); e != nil {
+		bot.LogError(fmt.Sprintf("unable to save the send queue - %s", e))
+	}
+	waiter := q.waiters[msg.ID]
+	delete(q.waiters, msg.ID)
+	q.mu.Unlock()
+
+	if err != nil && msg.Log {
+		log.Printf("Error sending message to log channel %s - %s\n", msg.Channel, err)
+	} else if err != nil {
+		bot.LogError(fmt.Sprintf(sendQueueFailedMessage, msg.ID, msg.Channel, msg.Attempts, q.maxAttempts(), err))
+		// a sender still waiting for the first attempt dead letters an archived channel's message itself,
+		// unless it is sent as a DM instead
+		if exhausted && (!gone || waiter == nil) {
+			bot.deadLetter(msg.Channel, options, err)
+		}
+	} else {
+		bot.noteReply(c, options...)
+	}
+	if waiter != nil {
+		waiter <- sendResult{channel: c, timestamp: t, err: err}
+	}
+}
+
+// QueuedMessages returns the number of messages in the bot's SendQueue waiting to be sent.
+func (bot *Bot) QueuedMessages() int {
+	q := bot.SendQueue
+	if q == nil {
+		return 0
+	}
+	q.resolveKey(bot)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.load(bot))
+}
+
+// load returns the queued messages in the Store, mu must be held.
+func (q *SendQueue) load(bot *Bot) []queuedSend {
+	var queue []queuedSend
+	_ = bot.store().Get(q.storeKey(bot), &queue)
+	return queue
+}
+
+// storeKey returns the key the queue is saved under in the Store, see resolveKey, mu must be held.
+func (q *SendQueue) storeKey(bot *Bot) string {
+	if q.key != "" {
+		return q.key
+	}
+	return sendQueueStoreKey
+}
+
+// resolveKey namespaces the queue's key in the Store to the bot's user, so bots that share a Store don't send each
+// other's messages. The bot's user is looked up with auth.test if it hasn't connected yet. Until it is known the
+// queue is saved under the bare key, and those messages are moved to the bot's key once it is.
+func (q *SendQueue) resolveKey(bot *Bot) {
+	q.mu.Lock()
+	resolved := q.key != ""
+	q.mu.Unlock()
+	if resolved {
+		return
+	}
+	bot.mu.Lock()
+	var id string
+	if bot.userDetails != nil {
+		id = bot.userDetails.ID
+	}
+	bot.mu.Unlock()
+	if id == "" {
+		if resp, err := bot.api().AuthTestContext(bot.context()); err == nil {
+			id = resp.UserID
+		}
+	}
+	if id == "" {
+		return
+	}
+
+	q.mu.Lock()
+	err := q.moveQueue(bot, sendQueueStoreKey+":"+id)
+	q.mu.Unlock()
+	if err != nil {
+		bot.LogError(fmt.Sprintf("unable to save the send queue - %s", err))
+	}
+}
+
+// moveQueue sets the queue's key, moving the messages saved under the bare key to it. The key isn't set if they
+// can't be moved, so it is tried again. mu must be held.
+func (q *SendQueue) moveQueue(bot *Bot, key string) error {
+	if q.key != "" {
+		return nil
+	}
+	orphaned := q.load(bot)
+	if len(orphaned) > 0 {
+		var queue []queuedSend
+		_ = bot.store().Get(key, &queue)
+		if err := bot.store().Put(key, append(queue, orphaned...)); err != nil {
+			return err
+		}
+	}
+	q.key = key
+	if len(orphaned) > 0 {
+		return bot.store().Put(sendQueueStoreKey, []queuedSend{})
+	}
+	return nil
+}
+
+func (q *SendQueue) interval() time.Duration {
+	if q.Interval > 0 {
+		return q.Interval
+	}
+	return defaultSendInterval
+}
+
+func (q *SendQueue) maxAttempts() int {
+	if q.MaxAttempts > 0 {
+		return q.MaxAttempts
+	}
+	return defaultSendAttempts
+}
+
+func (q *SendQueue) retryDelay() time.Duration {
+	if q.RetryDelay > 0 {
+		return q.RetryDelay
+	}
+	return defaultSendRetryDelay
+}
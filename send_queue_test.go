@@ -0,0 +1,305 @@
+package slackbot
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestSendQueue_send(t *testing.T) {
+	tests := []struct {
+		name            string
+		failures        int
+		failure         string
+		maxAttempts     int
+		wantTimestamp   string
+		wantErr         bool
+		wantSent        []string
+		wantDeadLetters int
+	}{
+		{
+			name:          "should send the message and return its timestamp",
+			wantTimestamp: "1.1",
+			wantSent:      []string{"hello"},
+		},
+		{
+			name:        "should retry a message that failed",
+			failures:    1,
+			failure:     "internal_error",
+			maxAttempts: 3,
+			wantErr:     true,
+			wantSent:    []string{"hello"},
+		},
+		{
+			name:            "should dead letter a message after the max attempts",
+			failures:        2,
+			failure:         "internal_error",
+			maxAttempts:     2,
+			wantErr:         true,
+			wantDeadLetters: 1,
+		},
+		{
+			name:            "should not retry a message to an archived channel",
+			failures:        1,
+			failure:         "is_archived",
+			maxAttempts:     3,
+			wantErr:         true,
+			wantDeadLetters: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var sent []string
+			calls := 0
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						mu.Lock()
+						defer mu.Unlock()
+						if calls++; calls <= tt.failures {
+							return "", "", errors.New(tt.failure)
+						}
+						sent = append(sent, msgValues(opts...).Get("text"))
+						return s, "1.1", nil
+					},
+				},
+				RetryPolicy: &RetryPolicy{Attempts: 1},
+				SendQueue:   &SendQueue{Interval: time.Millisecond, RetryDelay: time.Millisecond, MaxAttempts: tt.maxAttempts},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			defer bot.Stop()
+			_, ts, err := bot.Reply("C1", "hello")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Reply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ts != tt.wantTimestamp {
+				t.Errorf("Reply() timestamp = %q, want %q", ts, tt.wantTimestamp)
+			}
+			waitFor(t, func() bool { return bot.QueuedMessages() == 0 })
+			mu.Lock()
+			if !reflect.DeepEqual(sent, tt.wantSent) {
+				t.Errorf("sent = %q, want %q", sent, tt.wantSent)
+			}
+			mu.Unlock()
+			letters, _ := bot.DeadLetters()
+			if len(letters) != tt.wantDeadLetters {
+				t.Errorf("DeadLetters() = %d, want %d", len(letters), tt.wantDeadLetters)
+			}
+		})
+	}
+}
+
+func TestSendQueue_restart(t *testing.T) {
+	store := NewMemoryStore(nil)
+	queued := []queuedSend{
+		{ID: "1", Channel: "C1", Values: url.Values{"text": {"first"}}},
+		{ID: "2", Channel: "C1", Values: url.Values{"text": {"second"}}},
+	}
+	if err := store.Put(sendQueueStoreKey+":bot", queued); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	var mu sync.Mutex
+	var sent []string
+	bot := &Bot{
+		API: &mockAPI{
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				sent = append(sent, msgValues(opts...).Get("text"))
+				return s, "1.1", nil
+			},
+		},
+		Store:       store,
+		SendQueue:   &SendQueue{Interval: time.Millisecond},
+		terminate:   func(int) {},
+		userDetails: &slack.UserDetails{ID: "bot"},
+	}
+	defer bot.Stop()
+	bot.once.Do(bot.init)
+	waitFor(t, func() bool { return bot.QueuedMessages() == 0 })
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"first", "second"}; !reflect.DeepEqual(sent, want) {
+		t.Errorf("sent = %q, want %q", sent, want)
+	}
+}
+
+func TestSendQueue_next(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		queue    []queuedSend
+		wantID   string
+		wantOK   bool
+		wantWait bool
+	}{
+		{
+			name: "should send the highest priority first",
+			queue: []queuedSend{
+				{ID: "debug", Priority: PriorityDebug},
+				{ID: "reply", Priority: PriorityReply},
+				{ID: "alert", Priority: PriorityAlert},
+				{ID: "second alert", Priority: PriorityAlert},
+			},
+			wantID: "alert",
+			wantOK: true,
+		},
+		{
+			name: "should send messages with the same priority in order",
+			queue: []queuedSend{
+				{ID: "first", Priority: PriorityReply},
+				{ID: "second", Priority: PriorityReply},
+			},
+			wantID: "first",
+			wantOK: true,
+		},
+		{
+			name: "should skip messages that aren't due yet",
+			queue: []queuedSend{
+				{ID: "retry", Priority: PriorityAlert, NextAttempt: now.Add(time.Minute)},
+				{ID: "debug", Priority: PriorityDebug},
+			},
+			wantID: "debug",
+			wantOK: true,
+		},
+		{
+			name: "should return how long to wait when nothing is due",
+			queue: []queuedSend{
+				{ID: "retry", NextAttempt: now.Add(time.Minute)},
+			},
+			wantWait: true,
+		},
+		{
+			name: "should not wait when the queue is empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{userDetails: &slack.UserDetails{ID: "bot"}}
+			if err := bot.store().Put(sendQueueStoreKey+":bot", tt.queue); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+			q := &SendQueue{}
+			q.resolveKey(bot)
+			got, wait, ok := q.next(bot)
+			if ok != tt.wantOK || got.ID != tt.wantID {
+				t.Errorf("next() = %q, %v, want %q, %v", got.ID, ok, tt.wantID, tt.wantOK)
+			}
+			if (wait > 0) != tt.wantWait {
+				t.Errorf("next() wait = %s, want a wait %v", wait, tt.wantWait)
+			}
+		})
+	}
+}
+
+func TestBot_sendLog_sendQueue(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	bot := &Bot{
+		API: &mockAPI{
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				sent = append(sent, s)
+				return s, "1.1", errors.New("not_in_channel")
+			},
+		},
+		RetryPolicy:  &RetryPolicy{Attempts: 1},
+		DebugChannel: "D1",
+		SendQueue:    &SendQueue{Interval: time.Millisecond, MaxAttempts: 1},
+		userDetails:  &slack.UserDetails{ID: "bot"},
+	}
+	defer bot.Stop()
+	bot.LogDebug("debug")
+	waitFor(t, func() bool { return bot.QueuedMessages() == 0 })
+	// a failed log message must not log another message about the failure
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"D1"}; !reflect.DeepEqual(sent, want) {
+		t.Errorf("sent to %q, want %q", sent, want)
+	}
+}
+
+func TestSendQueue_sharedStore(t *testing.T) {
+	store := NewMemoryStore(nil)
+	var mu sync.Mutex
+	sent := map[string][]string{}
+	newBot := func(id string) *Bot {
+		return &Bot{
+			API: &mockAPI{
+				authTest: func() (*slack.AuthTestResponse, error) {
+					return &slack.AuthTestResponse{UserID: id}, nil
+				},
+				postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					sent[id] = append(sent[id], msgValues(opts...).Get("text"))
+					return s, "1.1", nil
+				},
+			},
+			Store:     store,
+			SendQueue: &SendQueue{Interval: time.Millisecond},
+		}
+	}
+	first, second := newBot("U1"), newBot("U2")
+	defer first.Stop()
+	defer second.Stop()
+	for _, bot := range []*Bot{first, second} {
+		bot.SendQueue.resolveKey(bot)
+		if _, ts, err := bot.Reply("C1", bot.SendQueue.storeKey(bot)); err != nil || ts != "1.1" {
+			t.Fatalf("Reply() = %q, %v, want 1.1, nil", ts, err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	want := map[string][]string{"U1": {"send_queue:U1"}, "U2": {"send_queue:U2"}}
+	if !reflect.DeepEqual(sent, want) {
+		t.Errorf("sent = %q, want %q", sent, want)
+	}
+}
+
+func TestSendQueue_resolveKey(t *testing.T) {
+	store := NewMemoryStore(nil)
+	orphaned := []queuedSend{{ID: "1", Channel: "C1", Values: url.Values{"text": {"queued"}}}}
+	if err := store.Put(sendQueueStoreKey, orphaned); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	var mu sync.Mutex
+	var sent []string
+	bot := &Bot{
+		API: &mockAPI{
+			authTest: func() (*slack.AuthTestResponse, error) {
+				return &slack.AuthTestResponse{UserID: "U1"}, nil
+			},
+			postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				sent = append(sent, msgValues(opts...).Get("text"))
+				return s, "1.1", nil
+			},
+		},
+		Store:     store,
+		SendQueue: &SendQueue{Interval: time.Millisecond},
+	}
+	defer bot.Stop()
+	bot.SendQueue.start(bot)
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(sent) == 1
+	})
+	if sent[0] != "queued" {
+		t.Errorf("sent = %q, want the message queued under the bare key", sent)
+	}
+	var left []queuedSend
+	if _ = store.Get(sendQueueStoreKey, &left); len(left) != 0 {
+		t.Errorf("bare key = %v, want the messages moved", left)
+	}
+}
@@ -0,0 +1,168 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+type (
+	// Shortcut handles a slack shortcut. Message shortcuts appear in the menu of a message, for example
+	// "Create ticket from this message", and global shortcuts appear in the shortcuts menu of the composer.
+	// The CallbackID must match the callback ID configured for the shortcut in the slack app. Shortcuts are
+	// received through the InteractionsHandler.
+	Shortcut struct {
+		CallbackID string
+		Handler    func(ctx *ShortcutContext)
+	}
+
+	// ShortcutContext is passed to a Shortcut's Handler. Message is the message the shortcut was used on, and
+	// Channel is the channel it was in, both are empty for global shortcuts, as is the ResponseURL.
+	ShortcutContext struct {
+		Bot         *Bot
+		Callback    *slack.InteractionCallback
+		User        string
+		Channel     string
+		Message     *slack.Message
+		TriggerID   string
+		ResponseURL string
+	}
+)
+
+// OpenModal opens a modal for the user who used the shortcut. It must be called within 3 seconds of the
+// shortcut being used.
+func (ctx *ShortcutContext) OpenModal(view slack.ModalViewRequest) (*slack.ViewResponse, error) {
+	return ctx.Bot.OpenModal(ctx.TriggerID, view)
+}
+
+// OpenModal opens a modal using the trigger ID of an interaction.
+func (bot *Bot) OpenModal(triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error) {
+	if view.Type == "" {
+		view.Type = slack.VTModal
+	}
+	var resp *slack.ViewResponse
+	err := bot.withRetry(func() (err error) {
+		resp, err = bot.api().OpenViewContext(bot.context(), triggerID, view)
+		return err
+	})
+	return resp, errors.Wrap(err, "unable to open modal")
+}
+
+// InteractionsHandler returns an http.Handler for slack's interactivity request url, which receives
+// shortcuts and other interactions with the bot. Requests are verified with the bot's SigningSecret and
+// acknowledged straight away, the interaction is then processed in the background, see HandleInteraction.
+// Modal submissions are processed before they are acknowledged so validation errors can be shown in the modal,
+// unless they take too long to process, in which case they are acknowledged without a response so slack
+// doesn't report an error to the user.
+func (bot *Bot) InteractionsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := bot.readSlackRequest(r)
+		if err != nil {
+			bot.LogError(fmt.Sprintf("invalid interaction request - %s", err))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		callback := &slack.InteractionCallback{}
+		if err := json.Unmarshal([]byte(form.Get("payload")), callback); err != nil {
+			bot.LogError(fmt.Sprintf("unable to parse interaction - %s", err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if callback.Type == slack.InteractionTypeViewSubmission {
+			resp := bot.handleSubmission(callback)
+			if resp == nil {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(resp)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		go bot.HandleInteraction(callback)
+	})
+}
+
+// handleSubmission processes the modal submission, and returns nil without waiting for it to finish if it
+// takes longer than the ackTimeout.
+func (bot *Bot) handleSubmission(callback *slack.InteractionCallback) *slack.ViewSubmissionResponse {
+	done := make(chan *slack.ViewSubmissionResponse, 1)
+	go func() {
+		done <- bot.HandleInteraction(callback)
+	}()
+	select {
+	case resp := <-done:
+		return resp
+	case <-bot.clock().After(ackTimeout):
+		bot.LogWarn(fmt.Sprintf("the submission of %s took longer than %s, it was acknowledged before it was processed", callback.View.CallbackID, ackTimeout))
+		return nil
+	}
+}
+
+// HandleInteraction processes an interaction from slack synchronously. It is used by InteractionsHandler and
+// can be called with the interactions received from a Socket Mode connection. For modal submissions it returns
+// the response that should be sent when acknowledging the interaction, such as validation errors, otherwise
+// it returns nil.
+func (bot *Bot) HandleInteraction(callback *slack.InteractionCallback) *slack.ViewSubmissionResponse {
+	bot.once.Do(bot.init)
+	switch callback.Type {
+	case slack.InteractionTypeMessageAction:
+		bot.runShortcut(bot.MessageShortcuts, callback)
+	case slack.InteractionTypeShortcut:
+		bot.runShortcut(bot.GlobalShortcuts, callback)
+	case slack.InteractionTypeBlockActions:
+		for _, action := range callback.ActionCallback.BlockActions {
+			switch action.ActionID {
+			case formOpenActionID:
+				bot.openForm(callback, action)
+			case selectActionID:
+				bot.handleSelect(callback, action)
+			case confirmActionID, cancelActionID:
+				bot.handleConfirmAction(callback, action)
+			case escalationAckActionID:
+				bot.handleEscalationAck(callback, action)
+			}
+		}
+	case slack.InteractionTypeViewSubmission:
+		if strings.HasPrefix(callback.View.CallbackID, formCallbackPrefix) {
+			if form := bot.form(strings.TrimPrefix(callback.View.CallbackID, formCallbackPrefix)); form != nil {
+				return form.submitModal(bot, callback)
+			}
+		}
+	}
+	return nil
+}
+
+func (bot *Bot) runShortcut(shortcuts []Shortcut, callback *slack.InteractionCallback) {
+	for _, s := range shortcuts {
+		if s.CallbackID != callback.CallbackID || s.Handler == nil {
+			continue
+		}
+		ctx := &ShortcutContext{
+			Bot:         bot,
+			Callback:    callback,
+			User:        callback.User.ID,
+			Channel:     callback.Channel.ID,
+			TriggerID:   callback.TriggerID,
+			ResponseURL: callback.ResponseURL,
+		}
+		if callback.Type == slack.InteractionTypeMessageAction {
+			msg := callback.Message
+			ctx.Message = &msg
+		}
+		defer bot.recoverPanic(ErrorInfo{Source: ErrorSourceShortcut, Channel: ctx.Channel, User: ctx.User}, nil)
+		s.Handler(ctx)
+		return
+	}
+	bot.LogWarn(fmt.Sprintf("no shortcut registered for callback id %s", callback.CallbackID))
+}
@@ -0,0 +1,166 @@
+package slackbot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_HandleInteraction(t *testing.T) {
+	tests := []struct {
+		name        string
+		callback    *slack.InteractionCallback
+		wantHandled string
+		wantMessage string
+		wantModal   bool
+	}{
+		{
+			name: "should call the message shortcut with the source message",
+			callback: &slack.InteractionCallback{
+				Type:       slack.InteractionTypeMessageAction,
+				CallbackID: "create_ticket",
+				TriggerID:  "T123",
+				Channel:    slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}}},
+				User:       slack.User{ID: "U1"},
+				Message:    slack.Message{Msg: slack.Msg{Text: "the build is broken"}},
+			},
+			wantHandled: "create_ticket",
+			wantMessage: "the build is broken",
+			wantModal:   true,
+		},
+		{
+			name: "should call the global shortcut",
+			callback: &slack.InteractionCallback{
+				Type:       slack.InteractionTypeShortcut,
+				CallbackID: "new_incident",
+				TriggerID:  "T123",
+				User:       slack.User{ID: "U1"},
+			},
+			wantHandled: "new_incident",
+			wantModal:   true,
+		},
+		{
+			name: "should not call a global shortcut for a message shortcut",
+			callback: &slack.InteractionCallback{
+				Type:       slack.InteractionTypeMessageAction,
+				CallbackID: "new_incident",
+			},
+		},
+		{
+			name: "should ignore unknown callback ids",
+			callback: &slack.InteractionCallback{
+				Type:       slack.InteractionTypeShortcut,
+				CallbackID: "unknown",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handled, message string
+			modalOpened := false
+			handler := func(ctx *ShortcutContext) {
+				handled = ctx.Callback.CallbackID
+				if ctx.Message != nil {
+					message = ctx.Message.Text
+				}
+				if _, err := ctx.OpenModal(slack.ModalViewRequest{}); err != nil {
+					t.Errorf("OpenModal() error = %v", err)
+				}
+			}
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						return s, "ts", nil
+					},
+					openView: func(triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error) {
+						modalOpened = triggerID == "T123" && view.Type == slack.VTModal
+						return &slack.ViewResponse{}, nil
+					},
+				},
+				MessageShortcuts: []Shortcut{{CallbackID: "create_ticket", Handler: handler}},
+				GlobalShortcuts:  []Shortcut{{CallbackID: "new_incident", Handler: handler}},
+			}
+			bot.HandleInteraction(tt.callback)
+			if handled != tt.wantHandled {
+				t.Errorf("HandleInteraction() handled %q, want %q", handled, tt.wantHandled)
+			}
+			if message != tt.wantMessage {
+				t.Errorf("HandleInteraction() message = %q, want %q", message, tt.wantMessage)
+			}
+			if modalOpened != tt.wantModal {
+				t.Errorf("HandleInteraction() opened modal = %v, want %v", modalOpened, tt.wantModal)
+			}
+		})
+	}
+}
+
+func TestBot_InteractionsHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		payload    string
+		noSecret   bool
+		wantStatus int
+		wantCalled bool
+	}{
+		{
+			name:       "should handle a shortcut",
+			payload:    `{"type":"shortcut","callback_id":"new_incident","trigger_id":"T123","user":{"id":"U1"}}`,
+			wantStatus: http.StatusOK,
+			wantCalled: true,
+		},
+		{
+			name:       "should reject an invalid payload",
+			payload:    `not json`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "should reject a request when the bot has no signing secret",
+			payload:    `{"type":"shortcut","callback_id":"new_incident","trigger_id":"T123","user":{"id":"U1"}}`,
+			noSecret:   true,
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := make(chan string, 1)
+			secret := "secret"
+			if tt.noSecret {
+				secret = ""
+			}
+			bot := &Bot{
+				SigningSecret: secret,
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						return s, "ts", nil
+					},
+				},
+				GlobalShortcuts: []Shortcut{{CallbackID: "new_incident", Handler: func(ctx *ShortcutContext) {
+					called <- ctx.User
+				}}},
+			}
+			body := url.Values{"payload": {tt.payload}}.Encode()
+			req := httptest.NewRequest(http.MethodPost, "/slack/interactions", strings.NewReader(body))
+			signRequest(req, "secret", body)
+			rec := httptest.NewRecorder()
+			bot.InteractionsHandler().ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("InteractionsHandler() status = %v, want %v", rec.Code, tt.wantStatus)
+			}
+			if tt.wantCalled {
+				select {
+				case user := <-called:
+					if user != "U1" {
+						t.Errorf("shortcut called for %s, want U1", user)
+					}
+				case <-time.After(time.Second):
+					t.Errorf("shortcut was not called")
+				}
+			}
+		})
+	}
+}
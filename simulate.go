@@ -0,0 +1,140 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	simulatedChannel = "DSIMULATED"
+	simulatedUser    = "USIMULATED"
+	simulatedBot     = "USIMULATEDBOT"
+	simulatedThread  = "1500000000.000001"
+)
+
+type (
+	// TranscriptMessage is a message in the transcript of a simulated exchange, either one of the inputs
+	// sent by the user or a reply sent by the bot.
+	TranscriptMessage struct {
+		FromBot bool
+		Text    string
+	}
+
+	// simulationClient records the messages sent by a simulated exchange. Only the methods used to send
+	// messages are implemented, steps that use other parts of the slack API can't be simulated.
+	simulationClient struct {
+		MessagingClient
+
+		mu         sync.Mutex
+		transcript []TranscriptMessage
+		ts         int
+	}
+)
+
+// Simulate runs the exchange entirely in memory, with a bot that doesn't connect to slack, so the flow of its
+// steps can be unit tested. The exchange starts as if a user had sent the message that matches its Regex, and
+// each of the inputs is sent as the user's next message in the exchange's thread. It returns the transcript of
+// the inputs and the bot's replies, and the exchange's Store when it finished or ran out of inputs. An error
+// is returned if a step returns an error or panics, or if the exchange ends before all of the inputs are used.
+// Steps that expect a file can't be simulated.
+//
+// Example:
+//
+//	transcript, store, err := deployExchange.Simulate([]string{"api", "yes"})
+func (ex *Exchange) Simulate(inputs []string) ([]TranscriptMessage, Store, error) {
+	client := &simulationClient{}
+	var stepErr error
+	bot := &Bot{
+		API:         client,
+		userDetails: &slack.UserDetails{ID: simulatedBot},
+		ErrorReporter: ErrorReporterFunc(func(err error, info ErrorInfo) {
+			if stepErr == nil {
+				stepErr = err
+			}
+		}),
+	}
+	bot.once.Do(bot.init)
+
+	start := &slack.MessageEvent{Msg: slack.Msg{Channel: simulatedChannel, User: simulatedUser, Timestamp: simulatedThread}}
+	run, err := bot.newExchange(start, ex)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to start the simulated exchange")
+	}
+	bot.setActiveExchange(run)
+	run.resume(nil)
+
+	for i, input := range inputs {
+		if stepErr != nil {
+			break
+		}
+		if _, ok := bot.activeExchange(run.Thread); !ok {
+			return client.messages(), run.Store, errors.Errorf("the exchange ended with %d of the inputs unused", len(inputs)-i)
+		}
+		client.record(false, input)
+		run.resume(&slack.MessageEvent{Msg: slack.Msg{
+			Channel:         simulatedChannel,
+			User:            simulatedUser,
+			Text:            input,
+			Timestamp:       client.nextTimestamp(),
+			ThreadTimestamp: run.Thread,
+		}})
+	}
+	if stepErr != nil {
+		return client.messages(), run.Store, errors.Wrapf(stepErr, "step %d failed", run.currentStep)
+	}
+	return client.messages(), run.Store, nil
+}
+
+func (c *simulationClient) PostMessage(channel string, options ...slack.MsgOption) (string, string, error) {
+	values, err := encodeMsgOptions(options...)
+	if err != nil {
+		return "", "", err
+	}
+	c.record(true, values.Get("text"))
+	return channel, c.nextTimestamp(), nil
+}
+
+func (c *simulationClient) PostMessageContext(_ context.Context, channel string, options ...slack.MsgOption) (string, string, error) {
+	return c.PostMessage(channel, options...)
+}
+
+func (c *simulationClient) PostEphemeral(channel string, user string, options ...slack.MsgOption) (string, error) {
+	_, ts, err := c.PostMessage(channel, options...)
+	return ts, err
+}
+
+func (c *simulationClient) PostEphemeralContext(_ context.Context, channel string, user string, options ...slack.MsgOption) (string, error) {
+	return c.PostEphemeral(channel, user, options...)
+}
+
+func (c *simulationClient) UpdateMessage(channel string, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+	_, _, err := c.PostMessage(channel, options...)
+	return channel, timestamp, "", err
+}
+
+func (c *simulationClient) UpdateMessageContext(_ context.Context, channel string, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+	return c.UpdateMessage(channel, timestamp, options...)
+}
+
+func (c *simulationClient) record(fromBot bool, text string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.transcript = append(c.transcript, TranscriptMessage{FromBot: fromBot, Text: text})
+}
+
+func (c *simulationClient) messages() []TranscriptMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]TranscriptMessage(nil), c.transcript...)
+}
+
+func (c *simulationClient) nextTimestamp() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ts++
+	return fmt.Sprintf("1500000001.%06d", c.ts)
+}
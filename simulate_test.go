@@ -0,0 +1,100 @@
+package slackbot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func TestExchange_Simulate(t *testing.T) {
+	deploy := func() *Exchange {
+		return &Exchange{
+			Steps: map[int]*Step{
+				1: {Name: "ask", Message: "Which service?"},
+				2: {Name: "service", MsgHandler: func(ex *Exchange, ev *slack.MessageEvent) (bool, error) {
+					if ev.Text == "" || ev.Text == "?" {
+						ex.Reply("Please name a service.")
+						return true, nil
+					}
+					if ev.Text == "legacy" {
+						return false, errors.New("legacy can't be deployed")
+					}
+					return false, ex.Store.Put("service", ev.Text)
+				}},
+				3: {Name: "confirm", Handler: func(ex *Exchange) error {
+					var service string
+					_ = ex.Store.Get("service", &service)
+					ex.Reply("Deploying " + service)
+					return nil
+				}},
+			},
+		}
+	}
+	bot := func(text string) TranscriptMessage { return TranscriptMessage{FromBot: true, Text: text} }
+	user := func(text string) TranscriptMessage { return TranscriptMessage{Text: text} }
+	tests := []struct {
+		name        string
+		inputs      []string
+		want        []TranscriptMessage
+		wantService string
+		wantErr     bool
+	}{
+		{
+			name:        "should run the steps with the inputs",
+			inputs:      []string{"api"},
+			want:        []TranscriptMessage{bot("Which service?"), user("api"), bot("Deploying api")},
+			wantService: "api",
+		},
+		{
+			name:        "should retry steps",
+			inputs:      []string{"?", "api"},
+			want:        []TranscriptMessage{bot("Which service?"), user("?"), bot("Please name a service."), user("api"), bot("Deploying api")},
+			wantService: "api",
+		},
+		{
+			name:   "should wait when it runs out of inputs",
+			inputs: nil,
+			want:   []TranscriptMessage{bot("Which service?")},
+		},
+		{
+			name:    "should return step errors",
+			inputs:  []string{"legacy"},
+			want:    []TranscriptMessage{bot("Which service?"), user("legacy")},
+			wantErr: true,
+		},
+		{
+			name:        "should return an error if inputs are left over",
+			inputs:      []string{"api", "web"},
+			want:        []TranscriptMessage{bot("Which service?"), user("api"), bot("Deploying api")},
+			wantService: "api",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transcript, store, err := deploy().Simulate(tt.inputs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Simulate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(transcript, tt.want) {
+				t.Errorf("Simulate() transcript = %v, want %v", transcript, tt.want)
+			}
+			var service string
+			_ = store.Get("service", &service)
+			if service != tt.wantService {
+				t.Errorf("Simulate() stored service = %q, want %q", service, tt.wantService)
+			}
+		})
+	}
+}
+
+func TestExchange_SimulatePanic(t *testing.T) {
+	ex := &Exchange{Steps: map[int]*Step{1: {Handler: func(ex *Exchange) error {
+		panic("oops")
+	}}}}
+	if _, _, err := ex.Simulate(nil); err == nil {
+		t.Error("Simulate() error = nil, want the panic")
+	}
+}
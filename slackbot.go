@@ -32,7 +32,9 @@ package slackbot
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"regexp"
@@ -41,7 +43,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
-	"github.com/robfig/cron"
+	"github.com/robfig/cron/v3"
 	"github.com/slack-go/slack"
 	"github.com/ulule/deepcopier"
 )
@@ -65,6 +67,14 @@ type (
 		// Slack bot api token, see https://api.slack.com/bot-users
 		Token string
 
+		// TokenProvider supplies the Token if it isn't set, and a new token when slack reports the bot's token
+		// is invalid, the bot then reconnects with the new token instead of stopping.
+		TokenProvider TokenProvider
+
+		// SearchToken is a user token with the search:read scope, used by SearchMessages because slack doesn't
+		// allow searching with a bot token.
+		SearchToken string
+
 		// Slack api client, through which all slack api interactions will happen.
 		// Having the client available on the bot also allows all of the slack api
 		// functions to be access by the bot in DirectListeners, Exchanges, and ScheduledTasks.
@@ -79,31 +89,300 @@ type (
 		// be sent to the DebugChannel before being logged to std out.
 		DebugChannel string
 
-		// Store can be used persist data through restarts or pass data between different methods.
-		// It is an interface that can be implemented with a real db that can persist data or you could
-		// use the SimpleStore in this package to store data only for the life of the current slackbot process.
+		// If DebugBatchInterval is set, debug messages are collected and sent to the DebugChannel as a
+		// single message once per interval, with repeated messages collapsed into a count. Batched debug
+		// messages are not counted by the CircuitBreaker.
+		DebugBatchInterval time.Duration
+
+		// If the error channel is set, messages passed to bot.LogError(string) will be sent to the
+		// ErrorChannel instead of the DebugChannel so they don't get buried in debug output.
+		ErrorChannel string
+
+		// MinLevel is the lowest LogLevel that will be sent to slack, messages below it are only
+		// logged to std out. The default of LevelDebug sends everything.
+		MinLevel LogLevel
+
+		// RedactPatterns are removed from log messages before they are logged or sent to slack, in addition
+		// to the bot's Token, SearchToken, SigningSecret and the DefaultRedactPatterns.
+		RedactPatterns []*regexp.Regexp
+
+		// Store holds state shared across conversations, such as counters and per-user preferences, and is
+		// available to listeners, exchanges and scheduled tasks through the bot. It is an interface that can be
+		// implemented with a real db that can persist data, FileStore persists data to a file, and if it is not
+		// set a MemoryStore is used which stores data only for the life of the current slackbot process.
+		// Messages that fail to send are saved in it as dead letters, see DeadLetters.
 		Store Store
 
+		// SigningSecret is used to verify requests from slack to the EventsHandler, InteractionsHandler and
+		// SlashCommandsHandler. Requests are rejected if it isn't set, unless InsecureSkipVerify is set.
+		SigningSecret string
+
+		// InsecureSkipVerify accepts requests to the EventsHandler, InteractionsHandler and SlashCommandsHandler
+		// without verifying their signature when there is no SigningSecret. Anyone who can reach the handlers can
+		// then send the bot events, so it should only be used for local development.
+		InsecureSkipVerify bool
+
+		// EventReplayWindow is how long the IDs of Events API events are remembered, events delivered again
+		// within it, such as slack retrying an event the bot didn't acknowledge in time, are ignored. The
+		// default is ten minutes. When PersistEventIDs is set the IDs are saved in the Store every five
+		// seconds while events are received and when the bot stops, so events retried after the bot restarts
+		// don't replay commands.
+		EventReplayWindow time.Duration
+		PersistEventIDs   bool
+
+		// SlashCommand is the bot's own slash command, such as /ops, whose text is handled on its own by the
+		// direct listeners. Other slash commands are handled with their name, see HandleSlashCommand.
+		SlashCommand string
+
+		// AppHome builds the bot's Home tab when a user opens it.
+		AppHome *AppHome
+
+		// MessageShortcuts and GlobalShortcuts are called when a user uses one of the app's shortcuts, they are
+		// received through the InteractionsHandler.
+		MessageShortcuts []Shortcut
+		GlobalShortcuts  []Shortcut
+
+		// Set Interactive when the app's interactivity request url is served by the InteractionsHandler, Forms
+		// will then be shown as modals instead of being asked for in a thread.
+		Interactive bool
+
+		// Persona is the name and icon messages are sent with instead of the bot user's, it can be overridden
+		// for a message with Persona.MsgOption or ReplyAs.
+		Persona *Persona
+
+		// RetryPolicy controls how outgoing slack api calls are retried when they fail with a
+		// transient error. If it is not set calls are only attempted once.
+		RetryPolicy *RetryPolicy
+
+		// FallbackToDM sends a listener's reply to the user who triggered it as a direct message when the
+		// channel it was sent to has been archived or deleted. Only replies sent through the listener's
+		// MessageContext know who triggered it.
+		FallbackToDM bool
+
+		// OnFatal is called when the bot can't continue, such as when the CircuitBreaker trips, so an application
+		// embedding the bot can shut down gracefully. If it is not set the process exits.
+		OnFatal func(err error)
+
+		// ErrorReporter is called with errors from exchanges and jobs, recovered panics, and connection
+		// failures so they can be sent to an error tracking service.
+		ErrorReporter ErrorReporter
+
+		// If RecordUsage is set, the direct listeners and exchanges used are counted per user, channel
+		// and day in the Store. See UsageReport.
+		RecordUsage bool
+
+		// If RecordEvents is set, every message event the bot receives is written to it as a line of JSON.
+		// The recording can be played back through a bot with Replay to reproduce bugs or build tests.
+		RecordEvents io.Writer
+
+		// Environment names where the bot is running, such as "production" or "staging". When ReadOnly is
+		// set, Destructive listeners and exchanges are disabled, so a copy of a production bot can safely be
+		// run against a staging workspace.
+		Environment string
+		ReadOnly    bool
+
+		// FlagProvider enables listeners and exchanges with a FeatureFlag.
+		FlagProvider FlagProvider
+
+		// Clock is the source of time for the bot's timeouts, intervals and timers, the default is the RealClock.
+		// Tests can set a fake clock to control time instead of sleeping, see Clock.
+		Clock Clock
+
+		// Context is the parent of the contexts passed to handlers and used for the bot's slack api calls, the
+		// default is context.Background(). Cancelling it cancels running handlers and any calls in flight.
+		Context context.Context
+
+		// DisableDeprecatedAfter disables Deprecated listeners after the time, they reply that the command has
+		// been removed instead of running. Deprecated listeners run until then, or always if it isn't set.
+		DisableDeprecatedAfter time.Time
+
+		// ConfirmationTimeout is how long users have to confirm a Destructive listener, the default is
+		// one minute.
+		ConfirmationTimeout time.Duration
+
+		// ACL restricts who can use all of the bot's listeners and exchanges and where, see WhoAmIListener
+		// to let users ask what they are allowed to do.
+		ACL *ACL
+
+		// ExternalUsers decides how messages from users of other organizations in Slack Connect channels are
+		// handled, the default is ExternalAllow. See ExternalUserPolicy.
+		ExternalUsers ExternalUserPolicy
+
+		// If DryRun is set, messages the bot sends are logged instead of being posted, except for messages to
+		// the DebugChannel and ErrorChannel. This allows new listeners to be tried against live traffic safely.
+		// If MirrorDryRun is also set, the logged messages are sent to the DebugChannel as well.
+		DryRun       bool
+		MirrorDryRun bool
+
+		// FloodGuard temporarily ignores messages from a channel or user that triggers too many listeners,
+		// protecting against feedback loops before the CircuitBreaker has to stop the bot.
+		FloodGuard *FloodGuard
+
+		// If NormalizeText is set, slack formatting such as links, entities and smart quotes is removed from
+		// incoming messages before they are matched, see NormalizeSlackText. TextNormalizers are applied
+		// after it to customize the text further.
+		NormalizeText   bool
+		TextNormalizers []TextNormalizer
+
+		// If IgnoreBotMessages is set, messages sent by bots and integrations, including this bot, will not
+		// trigger any listeners or exchanges unless the bot or user ID of the sender is in AllowedBots.
+		IgnoreBotMessages bool
+		AllowedBots       []string
+
+		// LoopDetector ignores messages that look like a reply loop between the bot and itself or
+		// another bot, instead of relying on the CircuitBreaker to stop the bot.
+		LoopDetector *LoopDetector
+
+		// OutageDetector notices when the RTM connection stays down, and queues the messages the bot sends,
+		// such as the replies of scheduled tasks, until it recovers.
+		OutageDetector *OutageDetector
+
+		// Watchdog periodically checks the connection, the slack api, the scheduler and the queues, and
+		// reports checks that fail to the ErrorChannel and ErrorReporter.
+		Watchdog *Watchdog
+
+		// DebugToken is the bearer token requests to the DebugHandler must have, if it isn't set every request
+		// is refused.
+		DebugToken string
+
+		// MaxEventLag is how far behind the bot can fall before it warns, the default is five seconds. It is
+		// compared with how long after a message was sent the bot starts processing it, and how long a message
+		// waits for a free worker. See Stats for the bot's backpressure.
+		MaxEventLag time.Duration
+
+		// SendQueue saves outgoing messages in the Store and sends them from a single sender with rate
+		// limiting and retries, so messages aren't lost if the bot restarts or slack is unavailable.
+		SendQueue *SendQueue
+
+		// QuietHours are daily windows when messages below their MinPriority are held in the SendQueue, and
+		// sent when the window ends.
+		QuietHours []QuietHours
+
+		// If DedupeWindow is set, a reply identical to one the bot sent to the same channel and thread within
+		// the window isn't sent again. The earlier message is edited to count the repeats instead, so several
+		// events triggering the same notification don't spam the channel.
+		DedupeWindow time.Duration
+
+		// DigestInterval is how often the lines added with Digest are posted, the default is five minutes.
+		// A digest is posted sooner if it has DigestMaxLines lines, the default is 50.
+		DigestInterval time.Duration
+		DigestMaxLines int
+
 		CircuitBreaker    *CircuitBreaker
 		DirectListeners   []Listener
 		IndirectListeners []Listener
+		FileListeners     []FileListener
 		Exchanges         []Exchange
+		Forms             []Form
 		ScheduledTasks    []ScheduledTask
 
+		// Calendar decides which days are weekends and holidays for ScheduledTasks that are WeekdaysOnly or
+		// SkipHolidays. Without one Saturday and Sunday are the weekend and there are no holidays.
+		Calendar Calendar
+
+		// Preferences are per-user settings, see Preference for the commands users can change them with.
+		Preferences []Preference
+
+		// Translator translates the bot's replies to the language set for the channel with
+		// SetChannelLanguage, or in direct messages to the user's LanguagePreference. When TranslateIncoming
+		// is set, messages from users or channels with a language are translated to the bot's Language
+		// before they are matched. Language defaults to "en".
+		Translator        Translator
+		TranslateIncoming bool
+		Language          string
+
+		// Onboarding are sequences of direct messages sent to users after they join the team or a channel.
+		Onboarding []Onboarding
+
+		// Welcome greets users who join channels, see SetChannelWelcome to set a channel's welcome in chat.
+		Welcome *Welcome
+
+		// Rotations announce who is on call, see RotationListener to ask the bot who is on call.
+		Rotations []Rotation
+
+		// OnEmojiAdded is called when a custom emoji is added to the team, with its name and image url, so
+		// the bot can announce it. See ListCustomEmoji and RandomEmoji to use the team's emoji in messages.
+		OnEmojiAdded func(bot *Bot, name string, url string)
+
 		activeExchanges map[string]*Exchange
 		userDetails     *slack.UserDetails
 		terminate       func(int)
 		once            sync.Once
+		mu              sync.Mutex
+		locations       *cache
+		groups          *cache
+		presence        *cache
+		emoji           *cache
+		presenceSubs    map[string]bool
+		rtmConnected    bool
+		scheduler       *scheduler
+		stop            chan struct{}
+		stopOnce        sync.Once
+		eventsOnce      sync.Once
+		workers         chan struct{}
+		group           *BotGroup
+		jobs            map[string]*runningJob
+		deadLetterMu    sync.Mutex
+		debug           *debugBatch
+		usageMu         sync.Mutex
+		recordMu        sync.Mutex
+		limitsMu        sync.Mutex
+		recurringMu     sync.Mutex
+		onboardingMu    sync.Mutex
+		karmaMu         sync.Mutex
+		faqMu           sync.Mutex
+		welcomeMu       sync.Mutex
+		rotationMu      sync.Mutex
+		topicMu         sync.Mutex
+		confirmMu       sync.Mutex
+		confirmations   map[string]*pendingConfirmation
+		dedupeMu        sync.Mutex
+		sentReplies     map[string]*sentReply
+		digestMu        sync.Mutex
+		digests         map[string]*digestBatch
+		dmUsers         *cache
+		deliveries      *cache
+		unfurlers       map[string]UnfurlHandler
+		limits          map[string]*listenerLimit
+		ownsClient      bool
+		tokenMu         sync.Mutex
+		newClient       func(token string) MessagingClient
+		searchAPI       MessagingClient
+		tokenRefreshes  int
+		workspace       Workspace
+		enterprises     map[string]string
+		teamUsers       map[string]*cache
+		sharedChannels  *cache
+		channelIDs      *cache
+		externalUsers   *cache
+		externalWarned  *cache
+		eventsMu        sync.Mutex
+		seenEvents      map[string]time.Time
+		seenPruned      time.Time
+		seenSave        Timer
+		responseURLs    map[*slack.MessageEvent]string
+		escalationMu    sync.Mutex
+		escalateTimers  map[string]Timer
+		escalatedMsgs   map[string]string
+		triggers        *triggerIndex
+		prefilters      *prefilters
+		pressure        backpressure
+		apiMu           sync.RWMutex
+		apiGen          int
+		goneChannels    *cache
 	}
 
 	// CircuitBreaker can prevent a bot from sending messages out of control. When a circuit
 	// breaker is set on a bot, if more than MaxMessages are sent in the TimeInterval the bot
-	// will stop sending messages and self destruct.
+	// will stop sending messages and self destruct. It is safe to use from concurrent handlers.
 	CircuitBreaker struct {
 		MaxMessages   int
 		TimeInterval  time.Duration
+		mu            sync.Mutex
 		intervalStart time.Time
 		count         int
+		tripped       bool
+		clock         Clock
 	}
 
 	// Listener will listen for an incoming message that matches the Regex. When a match is
@@ -117,9 +396,65 @@ type (
 		Usage   string
 		Regex   *regexp.Regexp
 		Handler func(bot *Bot, ev *slack.MessageEvent)
+
+		// Category groups related commands, such as "deploys", in the bot's Describe output and generated docs.
+		// Examples are messages that use the command, such as "deploy api", shown in the generated docs.
+		Category string
+		Examples []string
+
+		// Aliases are other regular expressions that trigger the listener, so several commands can share a
+		// handler without combining them into one Regex. They are listed after the Usage in SendHelp.
+		Aliases []*regexp.Regexp
+
+		// Conditions restrict the messages an indirect listener matches to those from a bot, user or channel,
+		// or with attachment or block content, see MatchConditions. They are ignored for direct listeners.
+		Conditions *MatchConditions
+
+		// TriggerWords let an indirect listener skip messages that don't contain one of the words, ignoring
+		// case, before its Regex and Aliases are tried. In busy channels most messages don't contain any of
+		// them, so listeners with TriggerWords cost a lookup instead of running their regular expressions.
+		// Words are letters, digits, hyphens and underscores. They are ignored for direct listeners.
+		TriggerWords []string
+
+		// ContextHandler can be set instead of Handler to receive a MessageContext, which will be
+		// cancelled if the Timeout is exceeded. If both are set only the ContextHandler is called.
+		ContextHandler func(ctx *MessageContext)
+
+		// MaxConcurrent limits how many of the handler can run at once, 0 means there is no limit. When
+		// the limit is reached the user is told who started the running handlers, and the message is
+		// ignored unless QueueWhenBusy is set, in which case it runs when one of them finishes.
+		MaxConcurrent int
+		QueueWhenBusy bool
+
+		// Timeout is the maximum amount of time the handler should take. If it is exceeded the user
+		// will be told the command timed out and the MessageContext will be cancelled.
+		Timeout time.Duration
+
+		// Destructive listeners change something outside of the bot, such as deploying or deleting data.
+		// The user is asked to confirm before the handler runs, by replying yes in the thread or with a
+		// button if the bot is Interactive, and when the bot is ReadOnly they reply that they are disabled.
+		Destructive bool
+
+		// FeatureFlag is checked with the bot's FlagProvider for each message, when it is disabled the
+		// listener is skipped as if it didn't match.
+		FeatureFlag string
+
+		// ACL restricts who can use the listener and where, as well as the bot's ACL.
+		ACL *ACL
+
+		// AllowExternal lets users of other organizations use the listener when the bot's ExternalUsers
+		// policy is ExternalRestrict.
+		AllowExternal bool
+
+		// Deprecated listeners still run, but are followed by a notice that the command will be removed,
+		// suggesting the ReplacedBy command if it is set, and are flagged in the help. They stop running
+		// once the bot's DisableDeprecatedAfter has passed.
+		Deprecated bool
+		ReplacedBy string
 	}
 
-	// Store can be used to persist data between restarts or between interaction methods.
+	// Store can be used to persist data between restarts or between interaction methods. Get should return
+	// ErrNotFound, or an error wrapping it, when the key isn't set.
 	Store interface {
 		Put(key string, value interface{}) error
 		Get(key string, value interface{}) error
@@ -128,25 +463,76 @@ type (
 )
 
 func (bot *Bot) init() {
-	if bot.API == nil {
-		bot.API = newSlackClient(bot.Token)
+	if bot.api() == nil {
+		bot.initialToken()
+		bot.setClient(bot.Token, bot.clientFor(bot.Token))
+		bot.ownsClient = true
 	}
 	if bot.FallbackMessage == "" {
 		bot.FallbackMessage = defaultFallback
 	}
 	if bot.DebugChannel != "" {
-		var ID string
-		if c, err := bot.API.GetChannel(bot.DebugChannel); err != nil {
-			if u, err := bot.API.GetUser(bot.DebugChannel); err == nil {
-				ID = u.ID
-			}
-		} else {
-			ID = c.ID
+		bot.DebugChannel, _ = bot.resolveID(bot.DebugChannel)
+	}
+	if bot.ErrorChannel != "" {
+		bot.ErrorChannel, _ = bot.resolveID(bot.ErrorChannel)
+	}
+	if bot.DryRun {
+		bot.setClient(bot.Token, &dryRunClient{MessagingClient: bot.api(), bot: bot})
+	}
+	for i := range bot.QuietHours {
+		if err := bot.QuietHours[i].validate(); err != nil {
+			log.Printf("Error in the bot's QuietHours - %s\n", err)
 		}
-		bot.DebugChannel = ID
+	}
+	if len(bot.QuietHours) > 0 && bot.SendQueue == nil {
+		log.Println("The bot's QuietHours are ignored, messages can only be held by a SendQueue")
 	}
 	bot.activeExchanges = make(map[string]*Exchange)
-	bot.terminate = os.Exit
+	bot.prefilters = &prefilters{filters: make(map[*regexp.Regexp]literalFilter)}
+	if bot.Store == nil {
+		bot.Store = NewMemoryStore(nil)
+	}
+	if len(bot.Preferences) > 0 {
+		bot.DirectListeners = append(bot.DirectListeners, preferenceListeners()...)
+	}
+	if bot.terminate == nil {
+		bot.terminate = os.Exit
+	}
+	if bot.CircuitBreaker != nil {
+		bot.CircuitBreaker.clock = bot.clock()
+	}
+	if bot.FloodGuard != nil {
+		bot.FloodGuard.clock = bot.clock()
+	}
+	if bot.LoopDetector != nil {
+		bot.LoopDetector.clock = bot.clock()
+	}
+	if bot.SendQueue != nil {
+		bot.SendQueue.start(bot)
+	}
+	if bot.Watchdog != nil {
+		bot.Watchdog.start(bot)
+	}
+}
+
+// resolveID will find the ID of a channel or user by name or ID, channels are checked first. The ID of a
+// channel or user mention such as <#C12345|general> or <@U12345> is returned without looking it up.
+func (bot *Bot) resolveID(identifier string) (string, error) {
+	if id, ok := mentionID(identifier, channelMentionPrefix); ok {
+		return id, nil
+	}
+	if id, ok := mentionID(identifier, userMentionPrefix); ok {
+		return id, nil
+	}
+	if c, err := bot.api().GetChannel(identifier); err == nil {
+		return c.ID, nil
+	}
+	u, err := bot.api().GetUser(identifier)
+	if err != nil {
+		return "", errors.Errorf("unable to find channel or user with identifier %s", identifier)
+	}
+	return u.ID, nil
 }
 
 // Start will schedule any Scheduled Tasks on the bot, start managing connections and
@@ -160,36 +546,104 @@ func (bot *Bot) Start() error {
 		return err
 	}
 
-	go bot.API.ManageConnection()
+	if err := bot.connect(); err != nil {
+		return err
+	}
+
+	bot.LogInfo(bot.buildStartingMessage())
+	if err := bot.listen(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// connect starts managing the connection to slack and waits for the bot's details. The client is read once, so
+// the connection that is managed is the one the details are read from even if the token is reloaded.
+func (bot *Bot) connect() error {
+	api := bot.api()
+	go api.ManageConnection()
 
 	retry := slackConnectionRetry
 	for retry > 0 {
-		if info := bot.API.GetInfo(); info != nil {
+		if info := api.GetInfo(); info != nil {
+			bot.mu.Lock()
 			bot.userDetails = info.User
+			if info.Team != nil {
+				bot.workspace.TeamID = info.Team.ID
+			}
+			bot.rtmConnected = true
+			bot.mu.Unlock()
+			if bot.SendQueue != nil {
+				bot.SendQueue.resolveKey(bot)
+				bot.SendQueue.notify()
+			}
 			break
 		}
-		time.Sleep(slackConnectionRetrySleep)
+		bot.clock().Sleep(slackConnectionRetrySleep)
 		retry--
 	}
 	if retry == 0 {
-		return errors.New("unable to make slack rtm connection")
-	}
-
-	bot.LogDebug(bot.buildStartingMessage())
-	if err := bot.listen(); err != nil {
+		err := errors.New("unable to make slack rtm connection")
+		bot.reportError(err, ErrorInfo{Source: ErrorSourceConnection})
 		return err
 	}
 	return nil
 }
 
 func (bot *Bot) scheduleTasks() error {
-	s := scheduler{cron.New()}
+	s := &scheduler{cronScheduler: cron.New()}
+	if err := bot.scheduleRotations(s); err != nil {
+		return err
+	}
 	if err := s.scheduleTasks(bot, bot.ScheduledTasks); err != nil {
 		return err
 	}
+	if bot.Watchdog != nil {
+		bot.Watchdog.scheduleHeartbeat(bot, s)
+	}
+	bot.mu.Lock()
+	bot.scheduler = s
+	bot.mu.Unlock()
+	bot.scheduleRecurringMessages()
+	bot.resumeEscalations()
+	bot.scheduleOnboarding(s)
 	return nil
 }
 
+// Stop disconnects the bot and stops its scheduled tasks, Start will then return. Handlers that are
+// already running are not interrupted.
+func (bot *Bot) Stop() {
+	bot.stopOnce.Do(func() {
+		close(bot.stopChan())
+		bot.mu.Lock()
+		s := bot.scheduler
+		bot.mu.Unlock()
+		if s != nil {
+			s.Stop()
+		}
+		bot.flushDigests()
+		bot.flushDebug()
+		bot.saveSeenEvents()
+	})
+}
+
+// context returns the bot's Context, or context.Background() if it isn't set.
+func (bot *Bot) context() context.Context {
+	if bot.Context != nil {
+		return bot.Context
+	}
+	return context.Background()
+}
+
+func (bot *Bot) stopChan() chan struct{} {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.stop == nil {
+		bot.stop = make(chan struct{})
+	}
+	return bot.stop
+}
+
 func (bot *Bot) buildStartingMessage() string {
 	var msg strings.Builder
 	msg.WriteString("```Starting bot with:\n")
@@ -197,9 +651,24 @@ func (bot *Bot) buildStartingMessage() string {
 	msg.WriteString(fmt.Sprintf("- %d Indirect Listeners\n", len(bot.IndirectListeners)))
 	msg.WriteString(fmt.Sprintf("- %d Exchanges\n", len(bot.Exchanges)))
 	msg.WriteString(fmt.Sprintf("- %d Scheduled Tasks\n", len(bot.ScheduledTasks)))
+	if len(bot.FileListeners) > 0 {
+		msg.WriteString(fmt.Sprintf("- %d File Listeners\n", len(bot.FileListeners)))
+	}
+	if len(bot.Forms) > 0 {
+		msg.WriteString(fmt.Sprintf("- %d Forms\n", len(bot.Forms)))
+	}
 	if bot.DebugChannel != "" {
 		msg.WriteString(fmt.Sprintf("- Debug Channel: %s\n", bot.DebugChannel))
 	}
+	if bot.DryRun {
+		msg.WriteString("- Dry Run: messages will be logged instead of sent\n")
+	}
+	if bot.Environment != "" {
+		msg.WriteString(fmt.Sprintf("- Environment: %s\n", bot.Environment))
+	}
+	if bot.ReadOnly {
+		msg.WriteString("- Read Only: destructive listeners and exchanges are disabled\n")
+	}
 	if bot.FallbackMessage != "" {
 		msg.WriteString(fmt.Sprintf("- Fallback Message: \"%s\"\n", bot.FallbackMessage))
 	}
@@ -216,62 +685,206 @@ func (bot *Bot) listen() error {
 
 	// TODO - accept a context in Start, add switch case for <- ctx.Done()
 
+	stop := bot.stopChan()
 	for {
 		select {
-		case msg := <-bot.API.GetIncomingEvents():
+		case <-stop:
+			if err := bot.api().Disconnect(); err != nil {
+				log.Printf("Error disconnecting - %s\n", err)
+			}
+			return nil
+
+		case msg := <-bot.api().GetIncomingEvents():
+			if bot.Watchdog != nil {
+				bot.Watchdog.noteEvent(bot.clock().Now())
+			}
+			events := bot.api().GetIncomingEvents()
+			bot.noteIncoming(len(events), cap(events))
 			switch ev := msg.Data.(type) {
 
 			case *slack.ConnectedEvent:
 				log.Println("Connection counter:", ev.ConnectionCount)
+				bot.tokenRefreshes = 0
+				if bot.OutageDetector != nil {
+					go bot.OutageDetector.handleConnect(bot)
+				}
+
+			case *slack.DisconnectedEvent:
+				if bot.OutageDetector != nil && !ev.Intentional {
+					bot.OutageDetector.handleDisconnect(bot)
+				}
 
 			case *slack.MessageEvent:
-				go bot.processMessage(ev)
+				bot.recordEvent(recordedMessageType, ev)
+				bot.noteEventLag(ev.Channel, ev.Timestamp)
+				bot.dispatch(ev)
+
+			case *slack.ReactionAddedEvent:
+				go bot.acknowledgeMessage(ev.User, ev.Item.Channel, ev.Item.Timestamp)
+
+			case *slack.TeamJoinEvent:
+				go bot.handleTeamJoin(ev.User.ID)
+
+			case *slack.MemberJoinedChannelEvent:
+				go bot.handleMemberJoinedChannel(ev.User, ev.Channel)
+
+			case *slack.PresenceChangeEvent:
+				bot.handlePresenceChange(ev)
+
+			case *slack.DNDUpdatedEvent:
+				bot.handleDNDUpdated(ev)
+
+			case *slack.EmojiChangedEvent:
+				bot.handleEmojiChanged(ev)
+
+			case *slack.ChannelArchiveEvent:
+				bot.markChannelUnavailable(ev.Channel)
+
+			case *slack.GroupArchiveEvent:
+				bot.markChannelUnavailable(ev.Channel)
+
+			case *slack.ChannelDeletedEvent:
+				bot.markChannelUnavailable(ev.Channel)
+
+			case *slack.ChannelUnarchiveEvent:
+				bot.markChannelAvailable(ev.Channel)
+
+			case *slack.GroupUnarchiveEvent:
+				bot.markChannelAvailable(ev.Channel)
 
 			case *slack.RTMError:
 				log.Printf("Error: %s\n", ev.Error())
+				bot.reportError(ev, ErrorInfo{Source: ErrorSourceConnection})
+
+			case *slack.ConnectionErrorEvent:
+				log.Printf("Connection error: %s\n", ev.Error())
+				bot.reportError(ev, ErrorInfo{Source: ErrorSourceConnection})
+				if bot.OutageDetector != nil {
+					bot.OutageDetector.handleDisconnect(bot)
+				}
 
 			case *slack.InvalidAuthEvent:
 				log.Println("Invalid credentials")
-				return errors.New("invalid slack credentials")
+				err := errors.New("invalid slack credentials")
+				if bot.TokenProvider != nil {
+					refreshErr := bot.refreshToken()
+					if refreshErr == nil {
+						bot.LogInfo("Reconnected with a refreshed slack token")
+						continue
+					}
+					err = errors.Wrap(refreshErr, "invalid slack credentials")
+				}
+				bot.reportError(err, ErrorInfo{Source: ErrorSourceConnection})
+				return err
 			}
 		}
 	}
 }
 
+// HandleMessage processes the message event synchronously as if it had been received from slack. It
+// can be used to drive the bot in tests, see the slackbottest package, or from a different transport.
+func (bot *Bot) HandleMessage(ev *slack.MessageEvent) {
+	bot.once.Do(bot.init)
+	bot.mu.Lock()
+	if bot.userDetails == nil {
+		bot.userDetails = &slack.UserDetails{}
+		if info := bot.api().GetInfo(); info != nil && info.User != nil {
+			bot.userDetails = info.User
+		}
+	}
+	bot.mu.Unlock()
+	bot.processMessage(ev)
+}
+
+// dispatch processes the message in the background. If the bot shares a worker pool with a BotGroup it waits
+// for a free worker first, without holding up the bot's events. The message is dropped if the bot stops first.
+func (bot *Bot) dispatch(ev *slack.MessageEvent) {
+	if bot.workers == nil {
+		go bot.processMessage(ev)
+		return
+	}
+	queued := bot.clock().Now()
+	go func() {
+		select {
+		case bot.workers <- struct{}{}:
+		case <-bot.stopChan():
+			return
+		}
+		defer func() { <-bot.workers }()
+		bot.noteHandlerWait(bot.clock().Now().Sub(queued))
+		bot.processMessage(ev)
+	}()
+}
+
 func (bot *Bot) processMessage(ev *slack.MessageEvent) {
-	for _, l := range bot.IndirectListeners {
-		if l.Regex.MatchString(ev.Text) {
-			if l.Handler != nil {
-				l.Handler(bot, ev)
-			}
+	bot.process(ev, true)
+}
+
+// process runs the listeners, exchanges and forms that match the message. The indirect listeners are only run
+// if indirect is set, so a message received as both a message and an app_mention event only runs them once.
+func (bot *Bot) process(ev *slack.MessageEvent, indirect bool) {
+	ev.Text = bot.normalizeText(ev.Text)
+	if bot.ignoreBotMessage(ev) || bot.detectLoop(ev) {
+		return
+	}
+	bot.translateIncoming(ev)
+
+	if ev.ThreadTimestamp != "" {
+		bot.acknowledgeMessage(ev.User, ev.Channel, ev.ThreadTimestamp)
+	}
+
+	if len(ev.Files) > 0 {
+		bot.processFiles(ev)
+	}
+
+	// Events are only counted by the FloodGuard once, when they first match a listener or exchange.
+	checked, allowed := false, true
+	allow := func() bool {
+		if !checked {
+			checked, allowed = true, bot.allowEvent(ev)
+		}
+		return allowed
+	}
+
+	candidates := bot.indirectCandidates(ev.Text)
+	for i, l := range bot.IndirectListeners {
+		if indirect && candidates[i] && bot.listenerMatches(&l, ev) && bot.flagEnabled(l.FeatureFlag, ev) && bot.CanRun(l.ACL, ev.User, ev.Channel) &&
+			!bot.externalDenied(l.AllowExternal, commandName(l.Usage, l.Regex), ev, false) && allow() {
+			bot.runListener(&l, ev, false)
 		}
 	}
 
 	userPrefix := fmt.Sprintf("<@%s> ", bot.userDetails.ID)
-	exchange, activeThread := bot.activeExchanges[ev.ThreadTimestamp]
-	if ev.User != "" && ev.User != bot.userDetails.ID && ev.Text != "" &&
-		(strings.HasPrefix(ev.Msg.Channel, directMessagePrefix) || strings.HasPrefix(ev.Text, userPrefix) || activeThread) {
+	exchange, activeThread := bot.activeExchange(ev.ThreadTimestamp)
+	pendingKey, confirmation := bot.confirmationFor(ev)
+	hasContent := ev.Text != "" || (activeThread && len(ev.Files) > 0)
+	if ev.User != "" && ev.User != bot.userDetails.ID && hasContent &&
+		(strings.HasPrefix(ev.Msg.Channel, directMessagePrefix) || strings.HasPrefix(ev.Text, userPrefix) || activeThread || confirmation != nil) {
 
 		ev.Text = strings.TrimSpace(strings.TrimPrefix(ev.Text, userPrefix))
 
-		if activeThread {
-			exchange.continueExecution(ev)
+		if !allow() {
 			return
 		}
 
-		for _, e := range bot.Exchanges {
-			if e.Regex.MatchString(ev.Text) {
-				bot.startExchange(ev, &e)
-				return
-			}
+		if bot.ExternalUsers == ExternalIgnore && bot.IsExternalUser(ev.User) {
+			return
 		}
-		for _, l := range bot.DirectListeners {
-			if l.Regex.MatchString(ev.Text) {
-				if l.Handler != nil {
-					l.Handler(bot, ev)
-				}
-				return
+
+		if confirmation != nil {
+			bot.answerConfirmationReply(pendingKey, confirmation, ev)
+			return
+		}
+
+		if activeThread {
+			if !bot.externalDenied(exchange.AllowExternal, commandName(exchange.Usage, exchange.Regex), ev, false) {
+				exchange.resume(ev)
 			}
+			return
+		}
+
+		if bot.runCommand(ev) {
+			return
 		}
 
 		// If there are no exchanges or listeners that match the message, reply with the fallback message.
@@ -281,32 +894,121 @@ func (bot *Bot) processMessage(ev *slack.MessageEvent) {
 	}
 }
 
-func (bot *Bot) checkCircuitBreaker(channel string) {
-	if bot.CircuitBreaker != nil {
-		bot.CircuitBreaker.count++
-		if bot.CircuitBreaker.intervalStart.Before(time.Now().Add(-bot.CircuitBreaker.TimeInterval)) {
-			bot.CircuitBreaker.intervalStart = time.Now()
-			bot.CircuitBreaker.count = 1
-		} else if bot.CircuitBreaker.count > bot.CircuitBreaker.MaxMessages {
-			msg := fmt.Sprintf(circuitBreakerMessage, bot.CircuitBreaker.MaxMessages, bot.CircuitBreaker.TimeInterval/time.Second)
-			_, _, _ = bot.API.PostMessage(channel, slack.MsgOptionText(msg, false), slack.MsgOptionAsUser(true))
-			log.Println(msg)
-			bot.terminate(-1)
+// runCommand starts the exchange or form, or runs the direct listener, that matches a command sent to the bot.
+// It returns false if nothing matched the command. A trailing --dry-run is only removed from the command here,
+// so the answers to exchanges and confirmations, and the messages indirect listeners see, keep the flag.
+func (bot *Bot) runCommand(ev *slack.MessageEvent) bool {
+	text, dryRun := parseDryRunFlag(ev.Text)
+	for _, e := range bot.Exchanges {
+		if bot.matchString(e.Regex, text) && bot.flagEnabled(e.FeatureFlag, ev) {
+			bot.recordUsage(commandName(e.Usage, e.Regex), ev)
+			if dryRun {
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), dryRunUnsupportedMessage)
+			} else if !bot.aclDenied(e.ACL, ev) && !bot.externalDenied(e.AllowExternal, commandName(e.Usage, e.Regex), ev, true) &&
+				!bot.readOnlyBlocked(e.Destructive, ev) {
+				bot.startExchange(ev, &e)
+			}
+			return true
+		}
+	}
+	for i := range bot.Forms {
+		if f := &bot.Forms[i]; f.Regex != nil && f.Regex.MatchString(text) {
+			bot.recordUsage(commandName(f.Usage, f.Regex), ev)
+			if dryRun {
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), dryRunUnsupportedMessage)
+			} else if !bot.aclDenied(nil, ev) && !bot.externalDenied(f.AllowExternal, commandName(f.Usage, f.Regex), ev, true) {
+				f.start(bot, ev)
+			}
+			return true
+		}
+	}
+	for _, l := range bot.DirectListeners {
+		if l.matches(bot, text) && bot.flagEnabled(l.FeatureFlag, ev) {
+			ev.Text = text
+			bot.recordUsage(commandName(l.Usage, l.Regex), ev)
+			if !bot.aclDenied(l.ACL, ev) && !bot.externalDenied(l.AllowExternal, commandName(l.Usage, l.Regex), ev, true) {
+				bot.runListener(&l, ev, dryRun)
+			}
+			return true
 		}
 	}
+	return false
+}
+
+// ignoreBotMessage returns true if IgnoreBotMessages is set and the message was sent by a bot, including
+// this one, that is not in the AllowedBots or the BotIDs of an indirect listener's Conditions.
+func (bot *Bot) ignoreBotMessage(ev *slack.MessageEvent) bool {
+	if !bot.IgnoreBotMessages {
+		return false
+	}
+	isSelf := bot.userDetails != nil && ev.User != "" && ev.User == bot.userDetails.ID
+	if !isSelf && ev.BotID == "" && ev.SubType != botMessageSubType {
+		return false
+	}
+	if !isSelf && bot.allowedByConditions(ev) {
+		return false
+	}
+	for _, id := range bot.AllowedBots {
+		if id == ev.BotID || (id == ev.User && ev.User != "") {
+			return false
+		}
+	}
+	return true
+}
+
+// checkCircuitBreaker counts a message to the channel and returns ErrCircuitBreakerTripped if it must not be
+// sent. The message that trips the breaker is replaced with a notice and OnFatal is called, once per trip.
+func (bot *Bot) checkCircuitBreaker(channel string) error {
+	if bot.CircuitBreaker == nil {
+		return nil
+	}
+	trip, err := bot.CircuitBreaker.record()
+	if !trip {
+		return err
+	}
+	msg := fmt.Sprintf(circuitBreakerMessage, bot.CircuitBreaker.MaxMessages, bot.CircuitBreaker.TimeInterval/time.Second)
+	_, _, _ = bot.api().PostMessageContext(bot.context(), channel, slack.MsgOptionText(msg, false), slack.MsgOptionAsUser(true))
+	log.Println(msg)
+	bot.fatal(errors.New(msg), ErrorInfo{Source: ErrorSourceCircuitBreaker, Channel: channel})
+	return ErrCircuitBreakerTripped
+}
+
+// fatal reports an error the bot can't continue after, then calls OnFatal or exits the process.
+func (bot *Bot) fatal(err error, info ErrorInfo) {
+	bot.reportError(err, info)
+	if bot.OnFatal != nil {
+		bot.OnFatal(err)
+		return
+	}
+	bot.terminate(-1)
 }
 
 func (bot *Bot) startExchange(ev *slack.MessageEvent, template *Exchange) {
+	if ev.Timestamp == "" {
+		if err := bot.startSlashThread(ev); err != nil {
+			bot.LogError(fmt.Sprintf("error starting exchange - %s", err))
+			return
+		}
+	}
+	ex, err := bot.newExchange(ev, template)
+	if err != nil {
+		bot.LogError(fmt.Sprintf("error starting exchange - %s", err))
+		return
+	}
+	bot.setActiveExchange(ex)
+	ex.resume(nil)
+}
+
+// newExchange copies the template for a new exchange in the thread of the event.
+func (bot *Bot) newExchange(ev *slack.MessageEvent, template *Exchange) (*Exchange, error) {
 	ex := &Exchange{}
 	if err := deepcopier.Copy(template).To(ex); err != nil {
-		bot.LogDebug(fmt.Sprintf("error starting exchange - %s", err))
-		return
+		return nil, err
 	}
 	for i, step := range template.Steps {
 		s := &Step{}
 		if err := deepcopier.Copy(step).To(s); err != nil {
-			bot.LogDebug(fmt.Sprintf("error starting exchange - %s", err))
-			return
+			return nil, err
 		}
 		ex.Steps[i] = s
 	}
@@ -316,23 +1018,17 @@ func (bot *Bot) startExchange(ev *slack.MessageEvent, template *Exchange) {
 	ex.Channel = ev.Channel
 	ex.User = ev.User
 	ex.currentStep = firstStepIndex
+	ex.running = &sync.Mutex{}
 	ex.Store = SimpleStore{}
-	bot.activeExchanges[ev.Timestamp] = ex
-	ex.continueExecution(nil)
-}
-
-// LogDebug will send the log message to the bots DebugChannel if set and log the message to the console.
-func (bot *Bot) LogDebug(msg string) {
-	if bot.DebugChannel != "" {
-		bot.checkCircuitBreaker(bot.DebugChannel)
-		if _, _, err := bot.API.PostMessage(bot.DebugChannel, slack.MsgOptionText(msg, false), slack.MsgOptionAsUser(true)); err != nil {
-			log.Printf("Error sending message to debug channel %s - %s\n", bot.DebugChannel, err)
-		}
+	if template.StoreCodec != nil {
+		ex.StoreCodec = template.StoreCodec
+		ex.Store = SimpleStore{}.WithCodec(ex.StoreCodec)
 	}
-	log.Println(msg)
+	ex.begin()
+	return ex, nil
 }
 
-// SendHelp will send a message containing all of the Listener and Exchange Usage strings. If msg is passed
+// SendHelp will send a message containing all of the Listener, Exchange, Form and FileListener Usage strings. If msg is passed
 // in it will be prepended to the usage help strings
 func (bot *Bot) SendHelp(channel string, thread string, msg string) (respChannel string, timestamp string, err error) {
 	var buffer bytes.Buffer
@@ -341,7 +1037,7 @@ func (bot *Bot) SendHelp(channel string, thread string, msg string) (respChannel
 	}
 	for _, l := range bot.DirectListeners {
 		if l.Usage != "" {
-			buffer.WriteString(l.Usage + "\n")
+			buffer.WriteString(l.helpText() + "\n")
 		}
 	}
 	for _, e := range bot.Exchanges {
@@ -349,17 +1045,39 @@ func (bot *Bot) SendHelp(channel string, thread string, msg string) (respChannel
 			buffer.WriteString(e.Usage + "\n")
 		}
 	}
+	for _, f := range bot.Forms {
+		if f.Usage != "" {
+			buffer.WriteString(f.Usage + "\n")
+		}
+	}
+	for _, l := range bot.FileListeners {
+		if l.Usage != "" {
+			buffer.WriteString(l.Usage + "\n")
+		}
+	}
 	return bot.ReplyInThread(channel, thread, buffer.String())
 }
 
 // Reply will send a message to the channel specified.
 func (bot *Bot) Reply(channel string, text string) (respChannel string, timestamp string, err error) {
-	return bot.ReplyWithOptions(channel, slack.MsgOptionText(text, false))
+	return bot.ReplyContext(bot.context(), channel, text)
+}
+
+// ReplyContext sends a message to the channel like Reply, the slack api calls are cancelled with ctx. Handlers
+// can pass their MessageContext so the message isn't sent after the handler's Timeout.
+func (bot *Bot) ReplyContext(ctx context.Context, channel string, text string) (respChannel string, timestamp string, err error) {
+	return bot.ReplyWithOptionsContext(ctx, channel, slack.MsgOptionText(text, false))
 }
 
 // ReplyInThread will send a message to the channel and thread specified.
 func (bot *Bot) ReplyInThread(channel string, thread string, text string) (respChannel string, timestamp string, err error) {
-	return bot.ReplyWithOptions(channel, slack.MsgOptionText(text, false), slack.MsgOptionTS(thread))
+	return bot.ReplyInThreadContext(bot.context(), channel, thread, text)
+}
+
+// ReplyInThreadContext sends a message to the channel and thread like ReplyInThread, the slack api calls are
+// cancelled with ctx.
+func (bot *Bot) ReplyInThreadContext(ctx context.Context, channel string, thread string, text string) (respChannel string, timestamp string, err error) {
+	return bot.ReplyWithOptionsContext(ctx, channel, slack.MsgOptionText(text, false), slack.MsgOptionTS(thread))
 }
 
 // ReplyWithOptions will reply to the channel specified with the message options passed in.
@@ -381,11 +1099,67 @@ func (bot *Bot) ReplyInThread(channel string, thread string, text string) (respC
 //
 // 	bot.ReplyWithOptions("example_channel", slack.MsgOptionAttachments(attachment))
 func (bot *Bot) ReplyWithOptions(channel string, options ...slack.MsgOption) (respChannel string, timestamp string, err error) {
-	bot.checkCircuitBreaker(channel)
-	options = append(options, slack.MsgOptionAsUser(true))
-	c, t, e := bot.API.PostMessage(channel, options...)
+	return bot.ReplyWithOptionsContext(bot.context(), channel, options...)
+}
+
+// ReplyWithOptionsContext sends a message with the options like ReplyWithOptions, the slack api calls are
+// cancelled with ctx.
+func (bot *Bot) ReplyWithOptionsContext(ctx context.Context, channel string, options ...slack.MsgOption) (respChannel string, timestamp string, err error) {
+	return bot.reply(ctx, PriorityReply, channel, options)
+}
+
+// reply sends the message, through the SendQueue with the priority if the bot has one. The slack api calls
+// are made with ctx, so they are cancelled with it. During an outage the message is queued until the bot
+// reconnects, see OutageDetector.
+func (bot *Bot) reply(ctx context.Context, priority Priority, channel string, options []slack.MsgOption) (string, string, error) {
+	if od := bot.OutageDetector; od != nil && od.enqueue(bot, priority, channel, options) {
+		return channel, "", nil
+	}
+	if err := bot.checkCircuitBreaker(channel); err != nil {
+		return channel, "", err
+	}
+	options = bot.withPersona(bot.translateReply(channel, options))
+	if bot.DedupeWindow > 0 {
+		return bot.dedupeReply(ctx, priority, channel, options)
+	}
+	return bot.postReply(ctx, priority, channel, options)
+}
+
+// postReply sends the message, through the SendQueue with the priority if the bot has one. Messages to a
+// channel that was archived or deleted aren't sent, see FallbackToDM. A message that slack refused because
+// the channel is archived is saved as a dead letter, unless it was sent as a DM instead.
+func (bot *Bot) postReply(ctx context.Context, priority Priority, channel string, options []slack.MsgOption) (string, string, error) {
+	if bot.channelUnavailable(channel) {
+		return bot.replyInstead(ctx, priority, channel, options, ErrChannelUnavailable)
+	}
+	c, t, e := bot.sendReply(ctx, priority, channel, options)
+	if isChannelUnavailable(e) {
+		if c, t, err := bot.replyInstead(ctx, priority, channel, options, e); c != channel {
+			return c, t, err
+		}
+		bot.deadLetter(channel, options, e)
+	}
+	return c, t, e
+}
+
+// sendReply sends the message, through the SendQueue with the priority if the bot has one.
+func (bot *Bot) sendReply(ctx context.Context, priority Priority, channel string, options []slack.MsgOption) (string, string, error) {
+	if bot.SendQueue != nil {
+		return bot.SendQueue.send(ctx, bot, channel, options, priority)
+	}
+	var c, t string
+	e := bot.withRetry(func() (err error) {
+		c, t, err = bot.api().PostMessageContext(ctx, channel, options...)
+		return err
+	})
 	if e != nil {
-		bot.LogDebug(fmt.Sprintf("failure sending message to %s with - %s", channel, e))
+		bot.checkChannelUnavailable(channel, e)
+		bot.LogError(fmt.Sprintf("failure sending message to %s with - %s", channel, e))
+		if !isChannelUnavailable(e) {
+			bot.deadLetter(channel, options, e)
+		}
+	} else {
+		bot.noteReply(c, options...)
 	}
 	return c, t, e
 }
@@ -1,7 +1,12 @@
 package slackbot
 
 import (
+	"context"
+	"io"
+	"net/url"
+	"reflect"
 	"regexp"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -12,25 +17,120 @@ import (
 
 type mockAPI struct {
 	*slack.RTM
-	postMessage      func(string, ...slack.MsgOption) (string, string, error)
-	getInfo          func() *slack.Info
-	manageConnection func()
+	postMessage            func(string, ...slack.MsgOption) (string, string, error)
+	postMessageContext     func(context.Context, string, ...slack.MsgOption) (string, string, error)
+	postEphemeral          func(string, string, ...slack.MsgOption) (string, error)
+	getInfo                func() *slack.Info
+	manageConnection       func()
+	getChannel             func(string) (slack.Channel, error)
+	getUser                func(string) (slack.User, error)
+	getUserInfo            func(string) (*slack.User, error)
+	uploadFile             func(slack.FileUploadParameters) (*slack.File, error)
+	getFile                func(string, io.Writer) error
+	updateMessage          func(string, string, ...slack.MsgOption) (string, string, string, error)
+	scheduleMessage        func(string, string, ...slack.MsgOption) (string, string, error)
+	getScheduledMessages   func(*slack.GetScheduledMessagesParameters) ([]slack.ScheduledMessage, string, error)
+	deleteScheduledMessage func(*slack.DeleteScheduledMessageParameters) (bool, error)
+	addUserReminder        func(string, string, string) (*slack.Reminder, error)
+	publishView            func(string, slack.HomeTabViewRequest, string) (*slack.ViewResponse, error)
+	openView               func(string, slack.ModalViewRequest) (*slack.ViewResponse, error)
+	unfurlMessage          func(string, string, map[string]slack.Attachment, ...slack.MsgOption) (string, string, string, error)
+	getUserGroups          func(...slack.GetUserGroupsOption) ([]slack.UserGroup, error)
+	getConversations       func(*slack.GetConversationsParameters) ([]slack.Channel, string, error)
+	createConversation     func(string, bool) (*slack.Channel, error)
+	unArchiveConversation  func(string) error
+	inviteUsers            func(string, ...string) (*slack.Channel, error)
+	setTopic               func(string, string) (*slack.Channel, error)
+	setPurpose             func(string, string) (*slack.Channel, error)
+	archiveConversation    func(string) error
+	addPin                 func(string, slack.ItemRef) error
+	getUserPresence        func(string) (*slack.UserPresence, error)
+	getDNDInfo             func(*string) (*slack.DNDStatus, error)
+	sendMessage            func(*slack.OutgoingMessage)
+	removePin              func(string, slack.ItemRef) error
+	listPins               func(string) ([]slack.Item, *slack.Paging, error)
+	searchMessages         func(string, slack.SearchParameters) (*slack.SearchMessages, error)
+	getPermalink           func(*slack.PermalinkParameters) (string, error)
+	getReplies             func(*slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error)
+	disconnect             func() error
+	getEmoji               func() (map[string]string, error)
+	incomingEvents         chan slack.RTMEvent
+	getUsers               func() ([]slack.User, error)
+	getConversationInfo    func(string, bool) (*slack.Channel, error)
+	authTest               func() (*slack.AuthTestResponse, error)
+}
+
+func (m *mockAPI) GetEmoji() (map[string]string, error) {
+	return m.getEmoji()
+}
+
+func (m *mockAPI) GetEmojiContext(_ context.Context) (map[string]string, error) {
+	return m.GetEmoji()
 }
 
 func (m *mockAPI) PostMessage(ch string, opts ...slack.MsgOption) (string, string, error) {
 	return m.postMessage(ch, opts...)
 }
 
+func (m *mockAPI) PostMessageContext(ctx context.Context, ch string, opts ...slack.MsgOption) (string, string, error) {
+	if m.postMessageContext != nil {
+		return m.postMessageContext(ctx, ch, opts...)
+	}
+	return m.PostMessage(ch, opts...)
+}
+
+func (m *mockAPI) PostEphemeral(ch string, user string, opts ...slack.MsgOption) (string, error) {
+	return m.postEphemeral(ch, user, opts...)
+}
+
+func (m *mockAPI) PostEphemeralContext(_ context.Context, ch string, user string, opts ...slack.MsgOption) (string, error) {
+	return m.PostEphemeral(ch, user, opts...)
+}
+
 func (m *mockAPI) GetChannel(identifier string) (slack.Channel, error) {
+	if m.getChannel != nil {
+		return m.getChannel(identifier)
+	}
 	return slack.Channel{}, errors.New("unable to find channel with identifier")
 }
 
 func (m *mockAPI) GetUser(identifier string) (slack.User, error) {
+	if m.getUser != nil {
+		return m.getUser(identifier)
+	}
 	return slack.User{}, errors.New("unable to find user with identifier")
 }
 
+func (m *mockAPI) GetUserInfo(user string) (*slack.User, error) {
+	return m.getUserInfo(user)
+}
+
+func (m *mockAPI) GetUserInfoContext(_ context.Context, user string) (*slack.User, error) {
+	return m.GetUserInfo(user)
+}
+
+func (m *mockAPI) UploadFile(params slack.FileUploadParameters) (*slack.File, error) {
+	return m.uploadFile(params)
+}
+
+func (m *mockAPI) UploadFileContext(_ context.Context, params slack.FileUploadParameters) (*slack.File, error) {
+	return m.UploadFile(params)
+}
+
+func (m *mockAPI) GetFile(url string, w io.Writer) error {
+	return m.getFile(url, w)
+}
+
+func (m *mockAPI) UpdateMessage(ch string, ts string, opts ...slack.MsgOption) (string, string, string, error) {
+	return m.updateMessage(ch, ts, opts...)
+}
+
+func (m *mockAPI) UpdateMessageContext(_ context.Context, ch string, ts string, opts ...slack.MsgOption) (string, string, string, error) {
+	return m.UpdateMessage(ch, ts, opts...)
+}
+
 func (m *mockAPI) GetIncomingEvents() chan slack.RTMEvent {
-	return nil
+	return m.incomingEvents
 }
 
 func (m *mockAPI) GetInfo() *slack.Info {
@@ -41,6 +141,192 @@ func (m *mockAPI) ManageConnection() {
 	m.manageConnection()
 }
 
+func (m *mockAPI) ScheduleMessage(ch string, postAt string, opts ...slack.MsgOption) (string, string, error) {
+	return m.scheduleMessage(ch, postAt, opts...)
+}
+
+func (m *mockAPI) GetScheduledMessages(params *slack.GetScheduledMessagesParameters) ([]slack.ScheduledMessage, string, error) {
+	return m.getScheduledMessages(params)
+}
+
+func (m *mockAPI) GetScheduledMessagesContext(_ context.Context, params *slack.GetScheduledMessagesParameters) ([]slack.ScheduledMessage, string, error) {
+	return m.GetScheduledMessages(params)
+}
+
+func (m *mockAPI) AddUserReminder(user string, text string, time string) (*slack.Reminder, error) {
+	return m.addUserReminder(user, text, time)
+}
+
+func (m *mockAPI) DeleteScheduledMessage(params *slack.DeleteScheduledMessageParameters) (bool, error) {
+	return m.deleteScheduledMessage(params)
+}
+
+func (m *mockAPI) DeleteScheduledMessageContext(_ context.Context, params *slack.DeleteScheduledMessageParameters) (bool, error) {
+	return m.DeleteScheduledMessage(params)
+}
+
+func (m *mockAPI) PublishView(user string, view slack.HomeTabViewRequest, hash string) (*slack.ViewResponse, error) {
+	return m.publishView(user, view, hash)
+}
+
+func (m *mockAPI) PublishViewContext(_ context.Context, user string, view slack.HomeTabViewRequest, hash string) (*slack.ViewResponse, error) {
+	return m.PublishView(user, view, hash)
+}
+
+func (m *mockAPI) OpenView(triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error) {
+	return m.openView(triggerID, view)
+}
+
+func (m *mockAPI) OpenViewContext(_ context.Context, triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error) {
+	return m.OpenView(triggerID, view)
+}
+
+func (m *mockAPI) UnfurlMessage(ch string, ts string, unfurls map[string]slack.Attachment, opts ...slack.MsgOption) (string, string, string, error) {
+	return m.unfurlMessage(ch, ts, unfurls, opts...)
+}
+
+func (m *mockAPI) GetUserGroups(opts ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+	return m.getUserGroups(opts...)
+}
+
+func (m *mockAPI) GetUserGroupsContext(_ context.Context, opts ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+	return m.GetUserGroups(opts...)
+}
+
+func (m *mockAPI) GetConversations(params *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+	return m.getConversations(params)
+}
+
+func (m *mockAPI) GetConversationsContext(_ context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+	return m.GetConversations(params)
+}
+
+func (m *mockAPI) CreateConversation(name string, isPrivate bool) (*slack.Channel, error) {
+	return m.createConversation(name, isPrivate)
+}
+
+func (m *mockAPI) CreateConversationContext(_ context.Context, name string, isPrivate bool) (*slack.Channel, error) {
+	return m.CreateConversation(name, isPrivate)
+}
+
+func (m *mockAPI) UnArchiveConversation(channel string) error {
+	return m.unArchiveConversation(channel)
+}
+
+func (m *mockAPI) UnArchiveConversationContext(_ context.Context, channel string) error {
+	return m.UnArchiveConversation(channel)
+}
+
+func (m *mockAPI) InviteUsersToConversation(channel string, users ...string) (*slack.Channel, error) {
+	return m.inviteUsers(channel, users...)
+}
+
+func (m *mockAPI) InviteUsersToConversationContext(_ context.Context, channel string, users ...string) (*slack.Channel, error) {
+	return m.InviteUsersToConversation(channel, users...)
+}
+
+func (m *mockAPI) SetTopicOfConversation(channel string, topic string) (*slack.Channel, error) {
+	return m.setTopic(channel, topic)
+}
+
+func (m *mockAPI) SetTopicOfConversationContext(_ context.Context, channel string, topic string) (*slack.Channel, error) {
+	return m.SetTopicOfConversation(channel, topic)
+}
+
+func (m *mockAPI) SetPurposeOfConversation(channel string, purpose string) (*slack.Channel, error) {
+	return m.setPurpose(channel, purpose)
+}
+
+func (m *mockAPI) SetPurposeOfConversationContext(_ context.Context, channel string, purpose string) (*slack.Channel, error) {
+	return m.SetPurposeOfConversation(channel, purpose)
+}
+
+func (m *mockAPI) ArchiveConversation(channel string) error {
+	return m.archiveConversation(channel)
+}
+
+func (m *mockAPI) ArchiveConversationContext(_ context.Context, channel string) error {
+	return m.ArchiveConversation(channel)
+}
+
+func (m *mockAPI) AddPin(channel string, item slack.ItemRef) error {
+	return m.addPin(channel, item)
+}
+
+func (m *mockAPI) AddPinContext(_ context.Context, channel string, item slack.ItemRef) error {
+	return m.AddPin(channel, item)
+}
+
+func (m *mockAPI) GetUserPresence(user string) (*slack.UserPresence, error) {
+	return m.getUserPresence(user)
+}
+
+func (m *mockAPI) GetUserPresenceContext(_ context.Context, user string) (*slack.UserPresence, error) {
+	return m.GetUserPresence(user)
+}
+
+func (m *mockAPI) GetDNDInfo(user *string) (*slack.DNDStatus, error) {
+	return m.getDNDInfo(user)
+}
+
+func (m *mockAPI) GetDNDInfoContext(_ context.Context, user *string) (*slack.DNDStatus, error) {
+	return m.GetDNDInfo(user)
+}
+
+func (m *mockAPI) SendMessage(msg *slack.OutgoingMessage) {
+	m.sendMessage(msg)
+}
+
+func (m *mockAPI) RemovePin(channel string, item slack.ItemRef) error {
+	return m.removePin(channel, item)
+}
+
+func (m *mockAPI) RemovePinContext(_ context.Context, channel string, item slack.ItemRef) error {
+	return m.RemovePin(channel, item)
+}
+
+func (m *mockAPI) ListPins(channel string) ([]slack.Item, *slack.Paging, error) {
+	return m.listPins(channel)
+}
+
+func (m *mockAPI) ListPinsContext(_ context.Context, channel string) ([]slack.Item, *slack.Paging, error) {
+	return m.ListPins(channel)
+}
+
+func (m *mockAPI) SearchMessages(query string, params slack.SearchParameters) (*slack.SearchMessages, error) {
+	return m.searchMessages(query, params)
+}
+
+func (m *mockAPI) SearchMessagesContext(_ context.Context, query string, params slack.SearchParameters) (*slack.SearchMessages, error) {
+	return m.SearchMessages(query, params)
+}
+
+func (m *mockAPI) GetPermalink(params *slack.PermalinkParameters) (string, error) {
+	return m.getPermalink(params)
+}
+
+func (m *mockAPI) GetPermalinkContext(_ context.Context, params *slack.PermalinkParameters) (string, error) {
+	return m.GetPermalink(params)
+}
+
+func (m *mockAPI) GetConversationReplies(params *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error) {
+	return m.getReplies(params)
+}
+
+func (m *mockAPI) GetConversationRepliesContext(_ context.Context, params *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error) {
+	return m.GetConversationReplies(params)
+}
+
+func (m *mockAPI) Disconnect() error {
+	return m.disconnect()
+}
+
+// msgValues applies the message options and returns the values that would be sent to slack.
+func msgValues(opts ...slack.MsgOption) url.Values {
+	_, values, _ := slack.UnsafeApplyMsgOptions("", "", "", opts...)
+	return values
+}
+
 func TestBot_LogDebug(t *testing.T) {
 	messageSent := false
 	type fields struct {
@@ -429,6 +715,7 @@ func TestBot_checkCircuitBreaker(t *testing.T) {
 		ScheduledTasks    []ScheduledTask
 		activeExchanges   map[string]*Exchange
 		terminate         func(int)
+		OnFatal           func(error)
 	}
 	type args struct {
 		channel string
@@ -439,6 +726,32 @@ func TestBot_checkCircuitBreaker(t *testing.T) {
 		args       args
 		terminated bool
 	}{
+		{
+			name: "should call OnFatal instead of terminating",
+			fields: fields{
+				CircuitBreaker: &CircuitBreaker{
+					MaxMessages:   1,
+					TimeInterval:  10,
+					count:         10,
+					intervalStart: time.Now().Add(time.Second * 100),
+				},
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						return "foo", "bar", nil
+					},
+				},
+				terminate: func(i int) {
+					t.Errorf("terminate called with OnFatal set")
+				},
+				OnFatal: func(err error) {
+					terminateCalled = strings.Contains(err.Error(), "CIRCUIT BREAKER TRIPPED")
+				},
+			},
+			args: args{
+				channel: "ch",
+			},
+			terminated: true,
+		},
 		{
 			name: "should trip the breaker",
 			fields: fields{
@@ -495,6 +808,7 @@ func TestBot_checkCircuitBreaker(t *testing.T) {
 				ScheduledTasks:    tt.fields.ScheduledTasks,
 				activeExchanges:   tt.fields.activeExchanges,
 				terminate:         tt.fields.terminate,
+				OnFatal:           tt.fields.OnFatal,
 			}
 			terminateCalled = false
 			bot.checkCircuitBreaker(tt.args.channel)
@@ -679,6 +993,77 @@ func TestBot_processMessage(t *testing.T) {
 	}
 }
 
+func TestBot_ignoreBotMessage(t *testing.T) {
+	type fields struct {
+		IgnoreBotMessages bool
+		AllowedBots       []string
+	}
+	type args struct {
+		ev *slack.MessageEvent
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   bool
+	}{
+		{
+			name:   "should not ignore bot messages by default",
+			fields: fields{},
+			args:   args{ev: &slack.MessageEvent{Msg: slack.Msg{BotID: "B1"}}},
+			want:   false,
+		},
+		{
+			name:   "should not ignore user messages",
+			fields: fields{IgnoreBotMessages: true},
+			args:   args{ev: &slack.MessageEvent{Msg: slack.Msg{User: "U1"}}},
+			want:   false,
+		},
+		{
+			name:   "should ignore messages from other bots",
+			fields: fields{IgnoreBotMessages: true},
+			args:   args{ev: &slack.MessageEvent{Msg: slack.Msg{User: "U2", BotID: "B1"}}},
+			want:   true,
+		},
+		{
+			name:   "should ignore webhook integration messages",
+			fields: fields{IgnoreBotMessages: true},
+			args:   args{ev: &slack.MessageEvent{Msg: slack.Msg{SubType: "bot_message"}}},
+			want:   true,
+		},
+		{
+			name:   "should ignore the bot's own messages",
+			fields: fields{IgnoreBotMessages: true},
+			args:   args{ev: &slack.MessageEvent{Msg: slack.Msg{User: "myID"}}},
+			want:   true,
+		},
+		{
+			name:   "should not ignore allowed bots by bot ID",
+			fields: fields{IgnoreBotMessages: true, AllowedBots: []string{"B1"}},
+			args:   args{ev: &slack.MessageEvent{Msg: slack.Msg{User: "U2", BotID: "B1"}}},
+			want:   false,
+		},
+		{
+			name:   "should not ignore allowed bots by user ID",
+			fields: fields{IgnoreBotMessages: true, AllowedBots: []string{"U2"}},
+			args:   args{ev: &slack.MessageEvent{Msg: slack.Msg{User: "U2", BotID: "B1"}}},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{
+				IgnoreBotMessages: tt.fields.IgnoreBotMessages,
+				AllowedBots:       tt.fields.AllowedBots,
+				userDetails:       &slack.UserDetails{ID: "myID"},
+			}
+			if got := bot.ignoreBotMessage(tt.args.ev); got != tt.want {
+				t.Errorf("ignoreBotMessage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestBot_startExchange(t *testing.T) {
 	type fields struct {
 		Token             string
@@ -769,6 +1154,44 @@ func TestBot_startExchange(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "should use the exchange's store codec",
+			fields: fields{
+				activeExchanges: make(map[string]*Exchange),
+			},
+			args: args{
+				ev: &slack.MessageEvent{
+					Msg: slack.Msg{
+						Channel:   "test_chan",
+						User:      "test_user",
+						Text:      "test_text",
+						Timestamp: "here_is_the_timestamp",
+					},
+				},
+				template: &Exchange{
+					Regex:      regexp.MustCompile(`test_text`),
+					StoreCodec: JSONCodec,
+					Steps: map[int]*Step{
+						1: {
+							Name: "step 1",
+							MsgHandler: func(ex *Exchange, ev *slack.MessageEvent) (bool, error) {
+								return false, nil
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				key: "here_is_the_timestamp",
+				ex: &Exchange{
+					Store:       SimpleStore{}.WithCodec(JSONCodec),
+					Thread:      "here_is_the_timestamp",
+					Channel:     "test_chan",
+					User:        "test_user",
+					currentStep: 1,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -795,9 +1218,109 @@ func TestBot_startExchange(t *testing.T) {
 			if tt.want.ex.User != ex.User ||
 				tt.want.ex.Channel != ex.Channel ||
 				tt.want.ex.Thread != ex.Thread ||
-				tt.want.ex.currentStep != ex.currentStep {
+				tt.want.ex.currentStep != ex.currentStep ||
+				!reflect.DeepEqual(tt.want.ex.Store, ex.Store) {
 				t.Errorf("active exchange incorrect got = %v, want = %v", ex, tt.want.ex)
 			}
 		})
 	}
 }
+
+func TestBot_init(t *testing.T) {
+	custom := SimpleStore{}
+	tests := []struct {
+		name  string
+		store Store
+		want  func(Store) bool
+	}{
+		{
+			name: "should default the store to a memory store",
+			want: func(s Store) bool {
+				_, ok := s.(*MemoryStore)
+				return ok
+			},
+		},
+		{
+			name:  "should keep a store that is set",
+			store: custom,
+			want: func(s Store) bool {
+				_, ok := s.(SimpleStore)
+				return ok
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{API: &mockAPI{}, Store: tt.store}
+			bot.init()
+			if !tt.want(bot.Store) {
+				t.Errorf("init() Store = %T", bot.Store)
+			}
+		})
+	}
+}
+
+func (m *mockAPI) GetUsersContext(_ context.Context) ([]slack.User, error) {
+	return m.getUsers()
+}
+
+func (m *mockAPI) GetConversationInfoContext(_ context.Context, channel string, includeLocale bool) (*slack.Channel, error) {
+	return m.getConversationInfo(channel, includeLocale)
+}
+
+func (m *mockAPI) AuthTestContext(_ context.Context) (*slack.AuthTestResponse, error) {
+	return m.authTest()
+}
+
+func TestBot_resolveID(t *testing.T) {
+	api := &mockAPI{
+		getChannel: func(identifier string) (slack.Channel, error) {
+			return slack.Channel{}, errors.New("channel_not_found")
+		},
+		getUser: func(identifier string) (slack.User, error) {
+			if identifier == "bob" {
+				return slack.User{ID: "U1"}, nil
+			}
+			return slack.User{}, errors.New("user_not_found")
+		},
+	}
+	tests := []struct {
+		name       string
+		identifier string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "should return the ID of a channel mention",
+			identifier: "<#C12345|general>",
+			want:       "C12345",
+		},
+		{
+			name:       "should return the ID of a user mention",
+			identifier: "<@U12345>",
+			want:       "U12345",
+		},
+		{
+			name:       "should look up names",
+			identifier: "bob",
+			want:       "U1",
+		},
+		{
+			name:       "should return an error for unknown names",
+			identifier: "alice",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{API: api}
+			got, err := bot.resolveID(tt.identifier)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package slackbottest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/daftn/slackbot"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// BotID is the user ID of the bot when using the fake Client.
+	BotID = "UBOT"
+	// BotName is the name of the bot when using the fake Client.
+	BotName = "slackbot"
+
+	timestampBase = 1500000000
+)
+
+type (
+	// Client is a fake slackbot.MessagingClient that captures outgoing messages instead of sending
+	// them to slack. It implements the methods the bot uses to send messages and look up channels
+	// and users, calling any other method will panic.
+	Client struct {
+		slackbot.MessagingClient
+
+		// Channels and Users are used to look up channels and users by name or ID.
+		Channels []slack.Channel
+		Users    []slack.User
+
+		mu       sync.Mutex
+		messages []Message
+		ts       int
+		events   chan slack.RTMEvent
+	}
+
+	// Message is an outgoing message captured by the fake Client.
+	Message struct {
+		Channel         string
+		Timestamp       string
+		ThreadTimestamp string
+		Text            string
+		// Updated is true if the message was sent with UpdateMessage.
+		Updated bool
+		// Ephemeral is true if the message was sent with PostEphemeral, User is who it was sent to.
+		Ephemeral bool
+		User      string
+		// Values are all of the values the message options would have sent to slack.
+		Values url.Values
+	}
+)
+
+// NewClient returns a fake Client with no channels or users.
+func NewClient() *Client {
+	return &Client{events: make(chan slack.RTMEvent)}
+}
+
+// Messages returns all of the messages sent through the client.
+func (c *Client) Messages() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Message(nil), c.messages...)
+}
+
+// LastMessage returns the last message sent through the client, or an empty Message if there are none.
+func (c *Client) LastMessage() Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.messages) == 0 {
+		return Message{}
+	}
+	return c.messages[len(c.messages)-1]
+}
+
+// Reset clears the captured messages.
+func (c *Client) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = nil
+}
+
+// GetInfo returns the details of the fake bot user.
+func (c *Client) GetInfo() *slack.Info {
+	return &slack.Info{User: &slack.UserDetails{ID: BotID, Name: BotName}}
+}
+
+// GetIncomingEvents returns a channel that never receives events, messages are sent with SimulateMessage.
+func (c *Client) GetIncomingEvents() chan slack.RTMEvent {
+	return c.events
+}
+
+// ManageConnection does nothing, the fake client is never connected.
+func (c *Client) ManageConnection() {}
+
+// PostMessage captures the message.
+func (c *Client) PostMessage(channel string, options ...slack.MsgOption) (string, string, error) {
+	m, err := c.record(channel, options...)
+	return m.Channel, m.Timestamp, err
+}
+
+// PostMessageContext captures the message, the context is ignored.
+func (c *Client) PostMessageContext(_ context.Context, channel string, options ...slack.MsgOption) (string, string, error) {
+	return c.PostMessage(channel, options...)
+}
+
+// UpdateMessage captures the updated message.
+func (c *Client) UpdateMessage(channel string, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+	m, err := c.record(channel, options...)
+	if err != nil {
+		return "", "", "", err
+	}
+	c.mu.Lock()
+	last := &c.messages[len(c.messages)-1]
+	last.Timestamp, last.Updated = timestamp, true
+	c.mu.Unlock()
+	return m.Channel, timestamp, m.Text, nil
+}
+
+// UpdateMessageContext captures the updated message, the context is ignored.
+func (c *Client) UpdateMessageContext(_ context.Context, channel string, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+	return c.UpdateMessage(channel, timestamp, options...)
+}
+
+// PostEphemeral captures the ephemeral message.
+func (c *Client) PostEphemeral(channel string, user string, options ...slack.MsgOption) (string, error) {
+	m, err := c.record(channel, options...)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	last := &c.messages[len(c.messages)-1]
+	last.Ephemeral, last.User = true, user
+	c.mu.Unlock()
+	return m.Timestamp, nil
+}
+
+// PostEphemeralContext captures the ephemeral message, the context is ignored.
+func (c *Client) PostEphemeralContext(_ context.Context, channel string, user string, options ...slack.MsgOption) (string, error) {
+	return c.PostEphemeral(channel, user, options...)
+}
+
+// GetChannel finds one of the client's Channels by name or ID.
+func (c *Client) GetChannel(identifier string) (slack.Channel, error) {
+	i := strings.TrimPrefix(identifier, "#")
+	for _, ch := range c.Channels {
+		if ch.Name == i || ch.ID == i {
+			return ch, nil
+		}
+	}
+	return slack.Channel{}, errors.Errorf("unable to find channel with identifier %s", identifier)
+}
+
+// GetUser finds one of the client's Users by name, display name or ID.
+func (c *Client) GetUser(identifier string) (slack.User, error) {
+	i := strings.TrimPrefix(identifier, "@")
+	for _, u := range c.Users {
+		if u.Name == i || u.ID == i || u.RealName == i || (u.Profile.DisplayName != "" && u.Profile.DisplayName == i) {
+			return u, nil
+		}
+	}
+	return slack.User{}, errors.Errorf("unable to find user with identifier %s", identifier)
+}
+
+// GetUserInfo finds one of the client's Users by ID.
+func (c *Client) GetUserInfo(ID string) (*slack.User, error) {
+	for _, u := range c.Users {
+		if u.ID == ID {
+			return &u, nil
+		}
+	}
+	return nil, errors.New("user_not_found")
+}
+
+// GetUserInfoContext finds one of the client's Users by ID, the context is ignored.
+func (c *Client) GetUserInfoContext(_ context.Context, ID string) (*slack.User, error) {
+	return c.GetUserInfo(ID)
+}
+
+func (c *Client) record(channel string, options ...slack.MsgOption) (Message, error) {
+	_, values, err := slack.UnsafeApplyMsgOptions("", channel, "", options...)
+	if err != nil {
+		return Message{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := Message{
+		Channel:         channel,
+		Timestamp:       c.nextTimestamp(),
+		ThreadTimestamp: values.Get("thread_ts"),
+		Text:            values.Get("text"),
+		Values:          values,
+	}
+	c.messages = append(c.messages, m)
+	return m, nil
+}
+
+// nextTimestamp must be called while holding the lock.
+func (c *Client) nextTimestamp() string {
+	c.ts++
+	return fmt.Sprintf("%d.%06d", timestampBase, c.ts)
+}
@@ -0,0 +1,118 @@
+package slackbottest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/daftn/slackbot"
+)
+
+type (
+	// FakeClock is a slackbot.Clock that only moves when it is told to, so tests of timeouts, intervals and
+	// timers don't have to sleep. Advance moves the clock forward and fires the timers that are due, in the
+	// order they are due, calling AfterFunc functions before Advance returns. Sleep blocks until the clock is
+	// advanced by the duration, like a timer from After, so a test controls when retries and backoff resume.
+	FakeClock struct {
+		mu     sync.Mutex
+		now    time.Time
+		timers []*fakeTimer
+	}
+
+	fakeTimer struct {
+		clock *FakeClock
+		when  time.Time
+		fn    func()
+		ch    chan time.Time
+	}
+)
+
+var _ slackbot.Clock = &FakeClock{}
+
+// NewFakeClock returns a FakeClock set to the time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the clock's current time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep blocks until the clock is advanced by the duration.
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// After returns a channel that receives the time when the clock is advanced by the duration.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	t := &fakeTimer{clock: c, ch: make(chan time.Time, 1)}
+	c.add(t, d)
+	return t.ch
+}
+
+// AfterFunc calls f when the clock is advanced by the duration, unless the timer is stopped first.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) slackbot.Timer {
+	t := &fakeTimer{clock: c, fn: f}
+	c.add(t, d)
+	return t
+}
+
+// Advance moves the clock forward by the duration, firing the timers that are due.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	c.mu.Unlock()
+	for {
+		c.mu.Lock()
+		if len(c.timers) == 0 || c.timers[0].when.After(end) {
+			c.now = end
+			c.mu.Unlock()
+			return
+		}
+		t := c.timers[0]
+		c.timers = c.timers[1:]
+		if t.when.After(c.now) {
+			c.now = t.when
+		}
+		now := c.now
+		c.mu.Unlock()
+		if t.fn != nil {
+			t.fn()
+		} else {
+			t.ch <- now
+		}
+	}
+}
+
+// Timers returns the number of timers waiting to fire, so tests can wait for a handler to start a timer
+// before advancing the clock.
+func (c *FakeClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+func (c *FakeClock) add(t *fakeTimer, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t.when = c.now.Add(d)
+	c.timers = append(c.timers, t)
+	sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].when.Before(c.timers[j].when) })
+}
+
+// Stop removes the timer, it returns false if it has already fired or been stopped.
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, other := range c.timers {
+		if other == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
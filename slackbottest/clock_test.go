@@ -0,0 +1,124 @@
+package slackbottest
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/daftn/slackbot"
+	"github.com/slack-go/slack"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	var fired []string
+	clock.AfterFunc(2*time.Second, func() { fired = append(fired, "2s") })
+	clock.AfterFunc(time.Second, func() { fired = append(fired, "1s") })
+	stopped := clock.AfterFunc(time.Second, func() { fired = append(fired, "stopped") })
+	after := clock.After(3 * time.Second)
+
+	if !stopped.Stop() {
+		t.Error("Stop() = false for a pending timer")
+	}
+	if stopped.Stop() {
+		t.Error("Stop() = true for a stopped timer")
+	}
+	clock.Advance(2 * time.Second)
+	if want := []string{"1s", "2s"}; !reflect.DeepEqual(fired, want) {
+		t.Errorf("fired %v, want %v", fired, want)
+	}
+	select {
+	case <-after:
+		t.Error("After() fired early")
+	default:
+	}
+	slept := make(chan struct{})
+	go func() {
+		clock.Sleep(time.Second)
+		close(slept)
+	}()
+	for clock.Timers() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-slept:
+		t.Error("Sleep() returned before the clock was advanced")
+	default:
+	}
+	clock.Advance(time.Second)
+	<-slept
+	select {
+	case now := <-after:
+		if want := start.Add(3 * time.Second); !now.Equal(want) {
+			t.Errorf("After() sent %v, want %v", now, want)
+		}
+	default:
+		t.Error("After() didn't fire")
+	}
+	if want := start.Add(3 * time.Second); !clock.Now().Equal(want) {
+		t.Errorf("Now() = %v, want %v", clock.Now(), want)
+	}
+	if clock.Timers() != 0 {
+		t.Errorf("Timers() = %d, want 0", clock.Timers())
+	}
+}
+
+func TestFakeClock_listenerTimeout(t *testing.T) {
+	client := NewClient()
+	clock := NewFakeClock(time.Now())
+	bot := &slackbot.Bot{
+		API:   client,
+		Clock: clock,
+		DirectListeners: []slackbot.Listener{{
+			Regex:   regexp.MustCompile("^build$"),
+			Timeout: time.Minute,
+			ContextHandler: func(ctx *slackbot.MessageContext) {
+				<-ctx.Done()
+				if ctx.Err() != context.DeadlineExceeded {
+					t.Errorf("ctx.Err() = %v, want %v", ctx.Err(), context.DeadlineExceeded)
+				}
+			},
+		}},
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		SimulateMessage(bot, "D1", "U1", "build")
+	}()
+	deadline := time.Now().Add(time.Second)
+	for clock.Timers() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the listener didn't start its timeout")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(time.Minute)
+	<-done
+	client.AssertSent(t, "D1", "timed out after 1m0s")
+}
+
+func TestFakeClock_floodGuard(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	guard := &slackbot.FloodGuard{MaxEvents: 1, TimeInterval: time.Minute}
+	bot := &slackbot.Bot{
+		API:        NewClient(),
+		Clock:      clock,
+		FloodGuard: guard,
+		DirectListeners: []slackbot.Listener{{
+			Regex:   regexp.MustCompile("^ping$"),
+			Handler: func(bot *slackbot.Bot, ev *slack.MessageEvent) {},
+		}},
+	}
+	SimulateMessage(bot, "D1", "U1", "ping")
+	SimulateMessage(bot, "D1", "U1", "ping")
+	if !guard.Muted("U1") {
+		t.Fatal("Muted() = false after the flood")
+	}
+	clock.Advance(time.Minute)
+	if guard.Muted("U1") {
+		t.Error("Muted() = true after the MuteDuration")
+	}
+}
@@ -0,0 +1,76 @@
+package slackbottest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/daftn/slackbot"
+)
+
+const waitTimeout = 5 * time.Second
+
+func TestServer(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	bot := newTestBot(NewClient())
+	bot.API = server.Client()
+	done := make(chan struct{})
+	defer func() {
+		bot.Stop()
+		<-done
+	}()
+	go func() {
+		defer close(done)
+		if err := bot.Start(); err != nil {
+			t.Errorf("Start() error = %v", err)
+		}
+	}()
+	if !server.WaitForConnections(1, waitTimeout) {
+		t.Fatalf("bot did not connect to the server")
+	}
+
+	t.Run("should reply to a direct message", func(t *testing.T) {
+		server.SendMessage("D123", "U123", "hello")
+		if _, ok := server.WaitForMessage("Hi there!", waitTimeout); !ok {
+			t.Errorf("bot did not reply, sent %v", server.Messages())
+		}
+	})
+
+	t.Run("should run an exchange", func(t *testing.T) {
+		ts := server.SendMessage("D123", "U123", "introduce")
+		m, ok := server.WaitForMessage("What's your name?", waitTimeout)
+		if !ok || m.ThreadTimestamp != ts {
+			t.Fatalf("exchange did not start in the thread, sent %v", server.Messages())
+		}
+		server.SendThreadMessage("D123", ts, "U123", "Sam")
+		if _, ok := server.WaitForMessage("Nice to meet you Sam", waitTimeout); !ok {
+			t.Errorf("exchange did not continue, sent %v", server.Messages())
+		}
+	})
+
+	t.Run("should reconnect after being disconnected", func(t *testing.T) {
+		server.Disconnect()
+		if !server.WaitForConnections(2, waitTimeout) {
+			t.Fatalf("bot did not reconnect to the server")
+		}
+		server.SendMessage("D123", "U123", "hello again")
+		if _, ok := server.WaitForMessage("That is not a valid command...", waitTimeout); !ok {
+			t.Errorf("bot did not reply after reconnecting, sent %v", server.Messages())
+		}
+	})
+}
+
+func TestServer_Client(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	bot := &slackbot.Bot{API: server.Client()}
+	if _, _, err := bot.Reply("C123", "hello"); err != nil {
+		t.Fatalf("Reply() error = %v", err)
+	}
+	m, ok := server.WaitForMessage("hello", waitTimeout)
+	if !ok || m.Channel != "C123" || m.Values.Get("as_user") != "true" {
+		t.Errorf("Reply() sent = %+v", m)
+	}
+}
@@ -0,0 +1,107 @@
+// Package slackbottest provides a fake slack client and helpers for unit testing the listeners
+// and exchanges of a slackbot without connecting to slack.
+//
+// Example:
+//
+//	func TestHello(t *testing.T) {
+//		client := slackbottest.NewClient()
+//		bot := &slackbot.Bot{
+//			API:             client,
+//			DirectListeners: []slackbot.Listener{helloListener},
+//		}
+//
+//		slackbottest.SimulateMessage(bot, "D123", "U123", "hello")
+//		client.AssertSent(t, "D123", "Hi there!")
+//	}
+package slackbottest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/daftn/slackbot"
+	"github.com/slack-go/slack"
+)
+
+var (
+	eventMu sync.Mutex
+	eventTS int
+)
+
+// SimulateMessage sends a message from the user in the channel to the bot and waits for the bot to
+// process it. Channels starting with a D are direct messages, in other channels use Mention so the
+// message is directed at the bot. The event is returned so its Timestamp can be used to reply in the
+// thread with SimulateThreadMessage.
+func SimulateMessage(bot *slackbot.Bot, channel string, user string, text string) *slack.MessageEvent {
+	return SimulateThreadMessage(bot, channel, "", user, text)
+}
+
+// SimulateThreadMessage sends a message from the user in the channel's thread to the bot and waits for
+// the bot to process it. It is used to continue an exchange, which happens in the thread of the message
+// that started it.
+func SimulateThreadMessage(bot *slackbot.Bot, channel string, thread string, user string, text string) *slack.MessageEvent {
+	ev := &slack.MessageEvent{
+		Msg: slack.Msg{
+			Type:            "message",
+			Channel:         channel,
+			User:            user,
+			Text:            text,
+			Timestamp:       nextEventTimestamp(),
+			ThreadTimestamp: thread,
+		},
+	}
+	bot.HandleMessage(ev)
+	return ev
+}
+
+// Mention prefixes the text with a mention of the fake bot so the message is directed at it.
+func Mention(text string) string {
+	return fmt.Sprintf("<@%s> %s", BotID, text)
+}
+
+// AssertSent fails the test if no message containing the text was sent to the channel.
+func (c *Client) AssertSent(t testing.TB, channel string, text string) {
+	t.Helper()
+	for _, m := range c.Messages() {
+		if m.Channel == channel && strings.Contains(m.Text, text) {
+			return
+		}
+	}
+	t.Errorf("no message containing %q was sent to %s, sent: %s", text, channel, c.describe())
+}
+
+// AssertNotSent fails the test if a message containing the text was sent to any channel.
+func (c *Client) AssertNotSent(t testing.TB, text string) {
+	t.Helper()
+	for _, m := range c.Messages() {
+		if strings.Contains(m.Text, text) {
+			t.Errorf("a message containing %q was sent to %s", text, m.Channel)
+		}
+	}
+}
+
+// AssertMessageCount fails the test if the number of messages sent is not n.
+func (c *Client) AssertMessageCount(t testing.TB, n int) {
+	t.Helper()
+	if got := len(c.Messages()); got != n {
+		t.Errorf("%d messages were sent, want %d, sent: %s", got, n, c.describe())
+	}
+}
+
+func (c *Client) describe() string {
+	var sent []string
+	for _, m := range c.Messages() {
+		sent = append(sent, fmt.Sprintf("%s: %q", m.Channel, m.Text))
+	}
+	return "[" + strings.Join(sent, ", ") + "]"
+}
+
+func nextEventTimestamp() string {
+	eventMu.Lock()
+	defer eventMu.Unlock()
+	eventTS++
+	return fmt.Sprintf("%d.%06d", time.Now().Unix(), eventTS)
+}
@@ -0,0 +1,113 @@
+package slackbottest
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/daftn/slackbot"
+	"github.com/slack-go/slack"
+)
+
+func newTestBot(client *Client) *slackbot.Bot {
+	return &slackbot.Bot{
+		API: client,
+		DirectListeners: []slackbot.Listener{
+			{
+				Regex: regexp.MustCompile("^hello$"),
+				Handler: func(bot *slackbot.Bot, ev *slack.MessageEvent) {
+					_, _, _ = bot.Reply(ev.Channel, "Hi there!")
+				},
+			},
+		},
+		Exchanges: []slackbot.Exchange{
+			{
+				Regex: regexp.MustCompile("^introduce$"),
+				Steps: map[int]*slackbot.Step{
+					1: {Message: "What's your name?"},
+					2: {
+						MsgHandler: func(ex *slackbot.Exchange, ev *slack.MessageEvent) (bool, error) {
+							ex.Reply("Nice to meet you " + ev.Text)
+							return false, nil
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestSimulateMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		channel   string
+		text      string
+		wantText  string
+		wantCount int
+	}{
+		{
+			name:      "should run a listener for a direct message",
+			channel:   "D123",
+			text:      "hello",
+			wantText:  "Hi there!",
+			wantCount: 1,
+		},
+		{
+			name:      "should run a listener when the bot is mentioned",
+			channel:   "C123",
+			text:      Mention("hello"),
+			wantText:  "Hi there!",
+			wantCount: 1,
+		},
+		{
+			name:      "should ignore messages in a channel without a mention",
+			channel:   "C123",
+			text:      "hello",
+			wantCount: 0,
+		},
+		{
+			name:      "should send the fallback message when nothing matches",
+			channel:   "D123",
+			text:      "goodbye",
+			wantText:  "That is not a valid command...",
+			wantCount: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient()
+			bot := newTestBot(client)
+			SimulateMessage(bot, tt.channel, "U123", tt.text)
+			client.AssertMessageCount(t, tt.wantCount)
+			if tt.wantText != "" {
+				client.AssertSent(t, tt.channel, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestSimulateThreadMessage(t *testing.T) {
+	client := NewClient()
+	bot := newTestBot(client)
+
+	ev := SimulateMessage(bot, "D123", "U123", "introduce")
+	client.AssertSent(t, "D123", "What's your name?")
+	if got := client.LastMessage().ThreadTimestamp; got != ev.Timestamp {
+		t.Errorf("exchange message thread = %v, want %v", got, ev.Timestamp)
+	}
+
+	client.Reset()
+	SimulateThreadMessage(bot, "D123", ev.Timestamp, "U123", "Sam")
+	client.AssertSent(t, "D123", "Nice to meet you Sam")
+	client.AssertNotSent(t, "What's your name?")
+}
+
+func TestClient_UpdateMessage(t *testing.T) {
+	client := NewClient()
+	_, ts, _ := client.PostMessage("C123", slack.MsgOptionText("working", false))
+	_, _, _, _ = client.UpdateMessage("C123", ts, slack.MsgOptionText("done", false))
+
+	m := client.LastMessage()
+	if !m.Updated || m.Timestamp != ts || m.Text != "done" {
+		t.Errorf("UpdateMessage() captured = %+v", m)
+	}
+}
@@ -0,0 +1,174 @@
+package slackbot
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// responseURLTTL is how long slack accepts messages sent to a response url.
+	responseURLTTL = 30 * time.Minute
+
+	// ackTimeout is how long a request from slack can be processed before it is acknowledged, slack reports
+	// an error to the user if it isn't acknowledged within three seconds.
+	ackTimeout = 2500 * time.Millisecond
+
+	// slashThreadMessage starts the thread an exchange started by a slash command is held in.
+	slashThreadMessage = "<@%s> started *%s*"
+)
+
+// SlashCommandsHandler returns an http.Handler for the request url of the app's slash commands. Requests are
+// verified with the bot's SigningSecret and acknowledged straight away, so slow commands don't exceed slack's
+// three second limit, the command is then processed in the background, see HandleSlashCommand.
+func (bot *Bot) SlashCommandsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := bot.readSlackRequest(r)
+		if err != nil {
+			bot.LogError(fmt.Sprintf("invalid slash command request - %s", err))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		cmd, err := slack.SlashCommandParse(r)
+		if err != nil || cmd.Command == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		go bot.HandleSlashCommand(cmd)
+	})
+}
+
+// HandleSlashCommand processes a slash command synchronously. It is used by SlashCommandsHandler and can be
+// called with the slash commands received from a Socket Mode connection. The command is handled by the direct
+// listeners, exchanges and forms as a message of the command's name followed by its text, so /deploy api runs
+// the listener for "deploy api", and the text of the bot's SlashCommand, such as /ops deploy api, is handled on
+// its own. Exchanges continue in the thread of a message the bot posts to the channel. The command's response
+// url is available to ContextHandlers, see MessageContext.Respond.
+func (bot *Bot) HandleSlashCommand(cmd slack.SlashCommand) {
+	bot.once.Do(bot.init)
+	bot.ensureUserDetails()
+	bot.noteWorkspace(Workspace{EnterpriseID: cmd.EnterpriseID, TeamID: cmd.TeamID})
+	ev := &slack.MessageEvent{Msg: slack.Msg{
+		Channel: cmd.ChannelID,
+		User:    cmd.UserID,
+		Team:    cmd.TeamID,
+		Text:    bot.slashCommandText(cmd),
+	}}
+	bot.setResponseURL(ev, cmd.ResponseURL)
+
+	ev.Text = bot.normalizeText(ev.Text)
+	bot.translateIncoming(ev)
+	if !bot.allowEvent(ev) || (bot.ExternalUsers == ExternalIgnore && bot.IsExternalUser(ev.User)) {
+		return
+	}
+	if bot.runCommand(ev) {
+		return
+	}
+	if cmd.ResponseURL != "" {
+		responder := &Responder{URL: cmd.ResponseURL, Channel: ev.Channel, bot: bot}
+		if err := responder.Ephemeral(bot.context(), slack.MsgOptionText(bot.FallbackMessage, false)); err != nil {
+			bot.LogError(err.Error())
+		}
+	}
+}
+
+// startSlashThread posts the message the exchange started by a slash command continues in the thread of, slash
+// commands aren't messages so they don't have a thread of their own.
+func (bot *Bot) startSlashThread(ev *slack.MessageEvent) error {
+	_, timestamp, err := bot.Reply(ev.Channel, fmt.Sprintf(slashThreadMessage, ev.User, ev.Text))
+	if err != nil {
+		return err
+	}
+	ev.Timestamp = timestamp
+	return nil
+}
+
+// slashCommandText returns the text the slash command is matched with.
+func (bot *Bot) slashCommandText(cmd slack.SlashCommand) string {
+	name := strings.TrimPrefix(cmd.Command, "/")
+	if bot.SlashCommand != "" && name == strings.TrimPrefix(bot.SlashCommand, "/") {
+		return strings.TrimSpace(cmd.Text)
+	}
+	return strings.TrimSpace(name + " " + cmd.Text)
+}
+
+// setResponseURL records the response url of the slash command the event was created for, until it expires.
+func (bot *Bot) setResponseURL(ev *slack.MessageEvent, responseURL string) {
+	if responseURL == "" {
+		return
+	}
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.responseURLs == nil {
+		bot.responseURLs = make(map[*slack.MessageEvent]string)
+	}
+	bot.responseURLs[ev] = responseURL
+	bot.clock().AfterFunc(responseURLTTL, func() {
+		bot.mu.Lock()
+		defer bot.mu.Unlock()
+		delete(bot.responseURLs, ev)
+	})
+}
+
+// responseURL returns the response url of the slash command the event was created for, if there is one.
+func (bot *Bot) responseURL(ev *slack.MessageEvent) string {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	return bot.responseURLs[ev]
+}
+
+// Respond sends a message only the user who used the slash command can see, through the command's response
+// url. If the listener wasn't triggered by a slash command it replies in the thread of the message instead.
+func (ctx *MessageContext) Respond(text string) error {
+	return ctx.RespondWithOptions(slack.ResponseTypeEphemeral, slack.MsgOptionText(text, false))
+}
+
+// RespondInChannel sends a message everyone in the channel can see through the slash command's response url,
+// or replies in the thread of the message if the listener wasn't triggered by a slash command.
+func (ctx *MessageContext) RespondInChannel(text string) error {
+	return ctx.RespondWithOptions(slack.ResponseTypeInChannel, slack.MsgOptionText(text, false))
+}
+
+// RespondWithOptions sends a message with the options through the slash command's response url, the
+// responseType is slack.ResponseTypeEphemeral or slack.ResponseTypeInChannel. It starts with a DRY RUN banner
+// if the command was sent with --dry-run.
+func (ctx *MessageContext) RespondWithOptions(responseType string, options ...slack.MsgOption) error {
+	if ctx.ResponseURL == "" {
+		_, _, err := ctx.ReplyWithOptions(options...)
+		return err
+	}
+	if ctx.dryRun {
+		var err error
+		if options, err = withDryRunBanner(options); err != nil {
+			return err
+		}
+	}
+	if responseType == slack.ResponseTypeInChannel {
+		return ctx.Responder().InChannel(ctx, options...)
+	}
+	return ctx.Responder().Ephemeral(ctx, options...)
+}
+
+// Responder returns a Responder for the slash command's response url.
+func (ctx *MessageContext) Responder() *Responder {
+	return &Responder{URL: ctx.ResponseURL, Channel: ctx.Event.Channel, bot: ctx.Bot}
+}
+
+// Respond sends a message only the user who used the shortcut can see, through the interaction's response
+// url. Global shortcuts don't have a response url.
+func (ctx *ShortcutContext) Respond(text string) error {
+	return ctx.Responder().Ephemeral(ctx.Bot.context(), slack.MsgOptionText(text, false))
+}
+
+// Responder returns a Responder for the shortcut's response url, which can replace or delete the message the
+// shortcut was used on.
+func (ctx *ShortcutContext) Responder() *Responder {
+	return &Responder{URL: ctx.ResponseURL, Channel: ctx.Channel, bot: ctx.Bot}
+}
@@ -0,0 +1,282 @@
+package slackbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// immediateClock is a Clock whose timers fire straight away.
+type immediateClock struct {
+	Clock
+}
+
+func (immediateClock) Sleep(time.Duration) {}
+
+func (immediateClock) After(time.Duration) <-chan time.Time {
+	c := make(chan time.Time, 1)
+	c <- time.Now()
+	return c
+}
+
+func TestBot_HandleSlashCommand(t *testing.T) {
+	tests := []struct {
+		name            string
+		slashCommand    string
+		cmd             slack.SlashCommand
+		wantHandled     string
+		wantResponseURL string
+		wantResponse    string
+	}{
+		{
+			name:            "should handle the command's name and text",
+			cmd:             slack.SlashCommand{Command: "/deploy", Text: "api", ChannelID: "C1", UserID: "U1", ResponseURL: "https://hooks.slack.com/commands/1"},
+			wantHandled:     "deploy api",
+			wantResponseURL: "https://hooks.slack.com/commands/1",
+		},
+		{
+			name:         "should handle the text of the bot's slash command",
+			slashCommand: "/ops",
+			cmd:          slack.SlashCommand{Command: "/ops", Text: " deploy api ", ChannelID: "C1", UserID: "U1"},
+			wantHandled:  "deploy api",
+		},
+		{
+			name:         "should respond with the fallback message when no listener matches",
+			cmd:          slack.SlashCommand{Command: "/status", ChannelID: "C1", UserID: "U1", ResponseURL: "https://hooks.slack.com/commands/1"},
+			wantResponse: defaultFallback,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handled, responseURL, response string
+			bot := &Bot{
+				SlashCommand: tt.slashCommand,
+				API: &mockAPI{
+					postMessage: func(channel string, opts ...slack.MsgOption) (string, string, error) {
+						if endpoint, values, _ := slack.UnsafeApplyMsgOptions("", "", "", opts...); endpoint == tt.cmd.ResponseURL {
+							response = values.Get("text")
+						}
+						return channel, "", nil
+					},
+				},
+				DirectListeners: []Listener{
+					{
+						Regex: regexp.MustCompile(`^deploy`),
+						ContextHandler: func(ctx *MessageContext) {
+							handled, responseURL = ctx.Event.Text, ctx.ResponseURL
+						},
+					},
+				},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			bot.HandleSlashCommand(tt.cmd)
+			if handled != tt.wantHandled {
+				t.Errorf("listener handled %q, want %q", handled, tt.wantHandled)
+			}
+			if responseURL != tt.wantResponseURL {
+				t.Errorf("ResponseURL = %q, want %q", responseURL, tt.wantResponseURL)
+			}
+			if response != tt.wantResponse {
+				t.Errorf("responded %q, want %q", response, tt.wantResponse)
+			}
+		})
+	}
+}
+
+func TestBot_HandleSlashCommand_exchange(t *testing.T) {
+	var started, thread string
+	bot := &Bot{
+		API: &mockAPI{
+			postMessage: func(channel string, opts ...slack.MsgOption) (string, string, error) {
+				_, values, _ := slack.UnsafeApplyMsgOptions("", "", "", opts...)
+				if values.Get("thread_ts") == "" {
+					started = values.Get("text")
+				}
+				return channel, "2.2", nil
+			},
+		},
+		Exchanges: []Exchange{{
+			Regex: regexp.MustCompile(`^rollback$`),
+			Steps: map[int]*Step{1: {Handler: func(ex *Exchange) error {
+				thread = ex.Thread
+				return nil
+			}}},
+		}},
+		userDetails: &slack.UserDetails{ID: "bot"},
+	}
+	bot.HandleSlashCommand(slack.SlashCommand{Command: "/rollback", ChannelID: "C1", UserID: "U1"})
+	if want := "<@U1> started *rollback*"; started != want {
+		t.Errorf("started the thread with %q, want %q", started, want)
+	}
+	if thread != "2.2" {
+		t.Errorf("exchange Thread = %q, want %q", thread, "2.2")
+	}
+}
+
+func TestMessageContext_RespondWithOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseURL  string
+		dryRun       bool
+		wantEndpoint string
+		wantText     string
+		wantThread   string
+	}{
+		{
+			name:         "should send the message to the response url",
+			responseURL:  "https://hooks.slack.com/commands/1",
+			wantEndpoint: "https://hooks.slack.com/commands/1",
+			wantText:     "deployed",
+		},
+		{
+			name:         "should add the dry run banner",
+			responseURL:  "https://hooks.slack.com/commands/1",
+			dryRun:       true,
+			wantEndpoint: "https://hooks.slack.com/commands/1",
+			wantText:     dryRunBanner + " deployed",
+		},
+		{
+			name:         "should reply in the thread without a response url",
+			wantEndpoint: "chat.postMessage",
+			wantText:     "deployed",
+			wantThread:   "1.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var endpoint, text, thread string
+			bot := &Bot{API: &mockAPI{
+				postMessage: func(channel string, opts ...slack.MsgOption) (string, string, error) {
+					e, values, _ := slack.UnsafeApplyMsgOptions("", "", "", opts...)
+					endpoint, text, thread = e, values.Get("text"), values.Get("thread_ts")
+					return channel, "", nil
+				},
+			}}
+			ctx := &MessageContext{
+				Context:     context.Background(),
+				Bot:         bot,
+				Event:       &slack.MessageEvent{Msg: slack.Msg{Channel: "C1", Timestamp: "1.1"}},
+				ResponseURL: tt.responseURL,
+				dryRun:      tt.dryRun,
+			}
+			if err := ctx.RespondWithOptions(slack.ResponseTypeEphemeral, slack.MsgOptionText("deployed", false)); err != nil {
+				t.Fatalf("RespondWithOptions() error = %v", err)
+			}
+			if endpoint != tt.wantEndpoint || text != tt.wantText || thread != tt.wantThread {
+				t.Errorf("sent %q to %q in thread %q, want %q to %q in thread %q", text, endpoint, thread, tt.wantText, tt.wantEndpoint, tt.wantThread)
+			}
+		})
+	}
+}
+
+func TestBot_SlashCommandsHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		secret     string
+		noSecret   bool
+		form       url.Values
+		wantStatus int
+		wantCalled bool
+	}{
+		{
+			name:       "should acknowledge the command before handling it",
+			secret:     "secret",
+			form:       url.Values{"command": {"/deploy"}, "text": {"api"}, "channel_id": {"C1"}, "user_id": {"U1"}},
+			wantStatus: http.StatusOK,
+			wantCalled: true,
+		},
+		{
+			name:       "should reject requests with an invalid signature",
+			secret:     "wrong",
+			form:       url.Values{"command": {"/deploy"}, "text": {"api"}},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "should reject requests without a command",
+			secret:     "secret",
+			form:       url.Values{"text": {"api"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "should reject requests when the bot has no signing secret",
+			noSecret:   true,
+			form:       url.Values{"command": {"/deploy"}, "text": {"api"}, "channel_id": {"C1"}, "user_id": {"U1"}},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := make(chan string, 1)
+			secret := "secret"
+			if tt.noSecret {
+				secret = ""
+			}
+			bot := &Bot{
+				SigningSecret: secret,
+				API:           &mockAPI{},
+				DirectListeners: []Listener{
+					{
+						Regex: regexp.MustCompile(`^deploy`),
+						Handler: func(bot *Bot, ev *slack.MessageEvent) {
+							called <- ev.Text
+						},
+					},
+				},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			body := tt.form.Encode()
+			req := httptest.NewRequest(http.MethodPost, "/slack/commands", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			signRequest(req, tt.secret, body)
+			rec := httptest.NewRecorder()
+			bot.SlashCommandsHandler().ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("SlashCommandsHandler() status = %v, want %v", rec.Code, tt.wantStatus)
+			}
+			if tt.wantCalled {
+				select {
+				case text := <-called:
+					if text != "deploy api" {
+						t.Errorf("listener called with %q, want deploy api", text)
+					}
+				case <-time.After(time.Second):
+					t.Errorf("listener was not called")
+				}
+			}
+		})
+	}
+}
+
+func TestBot_handleSubmission(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	bot := &Bot{
+		Clock: immediateClock{RealClock},
+		API:   &mockAPI{},
+		Forms: []Form{
+			{
+				Name: "ticket",
+				Fields: []FormField{
+					{Name: "title", Validate: func(value string) error {
+						<-release
+						return nil
+					}},
+				},
+			},
+		},
+	}
+	callback := &slack.InteractionCallback{
+		Type: slack.InteractionTypeViewSubmission,
+		View: slack.View{CallbackID: formCallbackPrefix + "ticket"},
+	}
+	if got := bot.handleSubmission(callback); got != nil {
+		t.Errorf("handleSubmission() = %v, want nil", got)
+	}
+}
@@ -0,0 +1,75 @@
+package slackbot
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	userStatePrefix    = "user:"
+	channelStatePrefix = "channel:"
+	stateExpiresSuffix = ":expires"
+)
+
+// ScopedStore is a view of the bot's Store for a single user or channel. Its keys are namespaced, so
+// the same key can be used for every user or channel without them overwriting each other. Values can be
+// given a TTL with PutWithTTL, after which they are no longer returned.
+type ScopedStore struct {
+	store  Store
+	prefix string
+	clock  Clock
+}
+
+// UserState returns the bot's Store scoped to the user, for per-user state such as preferences.
+func (bot *Bot) UserState(userID string) *ScopedStore {
+	return &ScopedStore{store: bot.store(), prefix: userStatePrefix + userID + ":", clock: bot.clock()}
+}
+
+// ChannelState returns the bot's Store scoped to the channel, for per-channel settings.
+func (bot *Bot) ChannelState(channelID string) *ScopedStore {
+	return &ScopedStore{store: bot.store(), prefix: channelStatePrefix + channelID + ":", clock: bot.clock()}
+}
+
+// store returns the bot's Store, setting it to a MemoryStore if the bot hasn't been started.
+func (bot *Bot) store() Store {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.Store == nil {
+		bot.Store = NewMemoryStore(nil)
+	}
+	return bot.Store
+}
+
+// Put adds the value to the store, replacing any TTL the key had.
+func (s *ScopedStore) Put(key string, value interface{}) error {
+	if err := s.store.Put(s.prefix+key, value); err != nil {
+		return err
+	}
+	_ = s.store.Delete(s.prefix + key + stateExpiresSuffix)
+	return nil
+}
+
+// PutWithTTL adds the value to the store, it is removed the first time it is retrieved after the ttl.
+func (s *ScopedStore) PutWithTTL(key string, value interface{}, ttl time.Duration) error {
+	if err := s.store.Put(s.prefix+key, value); err != nil {
+		return err
+	}
+	return s.store.Put(s.prefix+key+stateExpiresSuffix, s.clock.Now().Add(ttl))
+}
+
+// Get retrieves a value by key from the store. An error is returned if the key is not found or has expired.
+func (s *ScopedStore) Get(key string, value interface{}) error {
+	var expires time.Time
+	if err := s.store.Get(s.prefix+key+stateExpiresSuffix, &expires); err == nil && s.clock.Now().After(expires) {
+		_ = s.Delete(key)
+		return errors.Wrapf(ErrNotFound, "key %s", key)
+	}
+	return s.store.Get(s.prefix+key, value)
+}
+
+// Delete removes a value by key from the store.
+func (s *ScopedStore) Delete(key string) error {
+	_ = s.store.Delete(s.prefix + key + stateExpiresSuffix)
+	return s.store.Delete(s.prefix + key)
+}
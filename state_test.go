@@ -0,0 +1,108 @@
+package slackbot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBot_UserState(t *testing.T) {
+	tests := []struct {
+		name    string
+		put     func(s *ScopedStore) error
+		get     func(bot *Bot) *ScopedStore
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "should get a value put for the user",
+			put:  func(s *ScopedStore) error { return s.Put("env", "staging") },
+			get:  func(bot *Bot) *ScopedStore { return bot.UserState("U1") },
+			want: "staging",
+		},
+		{
+			name:    "should not get a value put for another user",
+			put:     func(s *ScopedStore) error { return s.Put("env", "staging") },
+			get:     func(bot *Bot) *ScopedStore { return bot.UserState("U2") },
+			wantErr: true,
+		},
+		{
+			name:    "should not get a value put for a channel with the same ID",
+			put:     func(s *ScopedStore) error { return s.Put("env", "staging") },
+			get:     func(bot *Bot) *ScopedStore { return bot.ChannelState("U1") },
+			wantErr: true,
+		},
+		{
+			name: "should get a value before its ttl",
+			put:  func(s *ScopedStore) error { return s.PutWithTTL("env", "staging", time.Minute) },
+			get:  func(bot *Bot) *ScopedStore { return bot.UserState("U1") },
+			want: "staging",
+		},
+		{
+			name:    "should not get a value after its ttl",
+			put:     func(s *ScopedStore) error { return s.PutWithTTL("env", "staging", -time.Minute) },
+			get:     func(bot *Bot) *ScopedStore { return bot.UserState("U1") },
+			wantErr: true,
+		},
+		{
+			name: "should expire the value by the bot's clock",
+			put:  func(s *ScopedStore) error { return s.PutWithTTL("env", "staging", time.Minute) },
+			get: func(bot *Bot) *ScopedStore {
+				bot.Clock = fixedClock{Clock: RealClock, now: time.Now().Add(2 * time.Minute)}
+				return bot.UserState("U1")
+			},
+			wantErr: true,
+		},
+		{
+			name: "should remove the ttl when the value is put again",
+			put: func(s *ScopedStore) error {
+				if err := s.PutWithTTL("env", "dev", -time.Minute); err != nil {
+					return err
+				}
+				return s.Put("env", "staging")
+			},
+			get:  func(bot *Bot) *ScopedStore { return bot.UserState("U1") },
+			want: "staging",
+		},
+		{
+			name: "should delete the value",
+			put: func(s *ScopedStore) error {
+				if err := s.PutWithTTL("env", "staging", time.Minute); err != nil {
+					return err
+				}
+				return s.Delete("env")
+			},
+			get:     func(bot *Bot) *ScopedStore { return bot.UserState("U1") },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{}
+			if err := tt.put(bot.UserState("U1")); err != nil {
+				t.Fatalf("put error = %v", err)
+			}
+			var got string
+			err := tt.get(bot).Get("env", &got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_ChannelState(t *testing.T) {
+	bot := &Bot{Store: SimpleStore{}}
+	if err := bot.ChannelState("C1").Put("deploy target", "prod"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	var got string
+	if err := bot.ChannelState("C1").Get("deploy target", &got); err != nil || got != "prod" {
+		t.Errorf("Get() = %v, %v, want prod", got, err)
+	}
+	if _, ok := bot.Store.(SimpleStore)["channel:C1:deploy target"]; !ok {
+		t.Errorf("Put() did not namespace the key, store = %v", bot.Store)
+	}
+}
@@ -1,44 +1,191 @@
 package slackbot
 
 import (
-	"bytes"
-	"encoding/gob"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
 
 	"github.com/pkg/errors"
 )
 
-// SimpleStore is an optional store that can be used for the Store on an Exchange.
+// ErrNotFound is returned by the stores in this package when a key isn't set. Stores written for the bot
+// should return it, or an error wrapping it, so a missing key can be told apart from a failed read.
+var ErrNotFound = errors.New("not found")
+
+// SimpleStore is an optional store that can be used for the Store on an Exchange. Values are encoded with
+// the GobCodec, use WithCodec to encode them with another Codec.
 type SimpleStore map[string][]byte
 
+// codecStore is a SimpleStore that encodes values with a codec.
+type codecStore struct {
+	SimpleStore
+	codec Codec
+}
+
 // Put adds the value to the simple store.
 func (s SimpleStore) Put(key string, value interface{}) error {
+	return s.put(GobCodec, key, value)
+}
+
+// Get retrieves a value by key from the simple store.
+func (s SimpleStore) Get(key string, value interface{}) error {
+	return s.get(GobCodec, key, value)
+}
+
+// WithCodec returns a Store that saves values in the simple store encoded with the codec.
+func (s SimpleStore) WithCodec(codec Codec) Store {
+	return codecStore{SimpleStore: s, codec: codecOrDefault(codec)}
+}
+
+func (s SimpleStore) put(codec Codec, key string, value interface{}) error {
 	if value == nil {
 		return errors.Errorf("error try to put key %s", key)
 	}
-	var buf bytes.Buffer
-	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+	v, err := codec.Marshal(value)
+	if err != nil {
 		return err
 	}
-	s[key] = buf.Bytes()
+	s[key] = v
 	return nil
 }
 
-// Get retrieves a value by key from the simple store.
-func (s SimpleStore) Get(key string, value interface{}) error {
+func (s SimpleStore) get(codec Codec, key string, value interface{}) error {
 	v, ok := s[key]
 	if !ok {
-		return errors.Errorf("key %s not found", key)
+		return errors.Wrapf(ErrNotFound, "key %s", key)
 	}
-	d := gob.NewDecoder(bytes.NewReader(v))
-	return d.Decode(value)
+	return codec.Unmarshal(v, value)
+}
+
+// Put adds the value to the store.
+func (s codecStore) Put(key string, value interface{}) error {
+	return s.put(s.codec, key, value)
+}
+
+// Get retrieves a value by key from the store.
+func (s codecStore) Get(key string, value interface{}) error {
+	return s.get(s.codec, key, value)
 }
 
 // Delete removes a value by key from the simple store.
 func (s SimpleStore) Delete(key string) error {
 	_, ok := s[key]
 	if !ok {
-		return errors.Errorf("key %s not found", key)
+		return errors.Wrapf(ErrNotFound, "key %s", key)
 	}
 	delete(s, key)
 	return nil
 }
+
+// MemoryStore is a Store that keeps data in memory for the life of the process. Unlike SimpleStore it is safe
+// to use from multiple goroutines, so it is the default for the bot's Store.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	data  SimpleStore
+	codec Codec
+}
+
+// NewMemoryStore returns an empty MemoryStore that encodes values with the codec, or the GobCodec if it is nil.
+func NewMemoryStore(codec Codec) *MemoryStore {
+	return &MemoryStore{data: SimpleStore{}, codec: codecOrDefault(codec)}
+}
+
+// Put adds the value to the store.
+func (s *MemoryStore) Put(key string, value interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.put(s.codec, key, value)
+}
+
+// Get retrieves a value by key from the store.
+func (s *MemoryStore) Get(key string, value interface{}) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.get(s.codec, key, value)
+}
+
+// Delete removes a value by key from the store.
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.Delete(key)
+}
+
+// FileStore is a Store that saves data to a file, so it persists through restarts of a bot running on a
+// single host. The whole file is rewritten on every change, use a database backed Store for large amounts
+// of data.
+type FileStore struct {
+	mu    sync.RWMutex
+	path  string
+	data  SimpleStore
+	codec Codec
+}
+
+// NewFileStore returns a FileStore that saves to the file at path, loading any data already saved there. The
+// file and its values are encoded with the codec, or the GobCodec if it is nil. With the JSONCodec the file
+// is readable JSON.
+func NewFileStore(path string, codec Codec) (*FileStore, error) {
+	s := &FileStore{path: path, data: SimpleStore{}, codec: codecOrDefault(codec)}
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to open store file")
+	}
+	if s.data, err = decodeValues(s.codec, content); err != nil {
+		return nil, errors.Wrap(err, "unable to read store file")
+	}
+	return s, nil
+}
+
+// Put adds the value to the store and saves the file.
+func (s *FileStore) Put(key string, value interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.data.put(s.codec, key, value); err != nil {
+		return err
+	}
+	return s.save()
+}
+
+// Get retrieves a value by key from the store.
+func (s *FileStore) Get(key string, value interface{}) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.get(s.codec, key, value)
+}
+
+// Delete removes a value by key from the store and saves the file.
+func (s *FileStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.data.Delete(key); err != nil {
+		return err
+	}
+	return s.save()
+}
+
+// save writes the data to a temporary file and renames it over the store's file, so the file is never left
+// partially written.
+func (s *FileStore) save() error {
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return errors.Wrap(err, "unable to save store file")
+	}
+	defer os.Remove(tmp.Name())
+	content, err := encodeValues(s.codec, s.data)
+	if err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "unable to save store file")
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "unable to save store file")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "unable to save store file")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), s.path), "unable to save store file")
+}
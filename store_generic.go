@@ -0,0 +1,23 @@
+//go:build go1.18
+// +build go1.18
+
+package slackbot
+
+// GetAs retrieves a value by key from the store as a T, so the caller doesn't need to declare a variable to
+// decode it into. The zero value is returned with the error if the key is not found or can't be decoded.
+//
+//	count, err := slackbot.GetAs[int](bot.Store, "deploys")
+func GetAs[T any](store Store, key string) (T, error) {
+	var value T
+	if err := store.Get(key, &value); err != nil {
+		var zero T
+		return zero, err
+	}
+	return value, nil
+}
+
+// PutAs adds a T to the store. It is the counterpart of GetAs, the type parameter makes sure the value is
+// saved as the same type it will be retrieved as.
+func PutAs[T any](store Store, key string, value T) error {
+	return store.Put(key, value)
+}
@@ -0,0 +1,67 @@
+//go:build go1.18
+// +build go1.18
+
+package slackbot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetAs(t *testing.T) {
+	type prefs struct {
+		Env     string
+		Regions []string
+	}
+	tests := []struct {
+		name    string
+		store   Store
+		key     string
+		want    prefs
+		wantErr bool
+	}{
+		{
+			name:  "should get the value as the type",
+			store: SimpleStore{},
+			key:   "prefs",
+			want:  prefs{Env: "staging", Regions: []string{"us", "eu"}},
+		},
+		{
+			name:  "should get the value from a json store",
+			store: SimpleStore{}.WithCodec(JSONCodec),
+			key:   "prefs",
+			want:  prefs{Env: "staging", Regions: []string{"us", "eu"}},
+		},
+		{
+			name:    "should return the zero value if the key is not found",
+			store:   SimpleStore{},
+			key:     "missing",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := PutAs(tt.store, "prefs", prefs{Env: "staging", Regions: []string{"us", "eu"}}); err != nil {
+				t.Fatalf("PutAs() error = %v", err)
+			}
+			got, err := GetAs[prefs](tt.store, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetAs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetAs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetAs_decodeError(t *testing.T) {
+	store := SimpleStore{}
+	if err := PutAs(store, "count", "not a number"); err != nil {
+		t.Fatalf("PutAs() error = %v", err)
+	}
+	got, err := GetAs[int](store, "count")
+	if err == nil || got != 0 {
+		t.Errorf("GetAs() = %v, %v, want the zero value and an error", got, err)
+	}
+}
@@ -1,7 +1,16 @@
 package slackbot
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+
+	"github.com/pkg/errors"
 )
 
 func TestSimpleStore_Delete(t *testing.T) {
@@ -114,3 +123,202 @@ func TestSimpleStore_Get_and_Put(t *testing.T) {
 		})
 	}
 }
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore(nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("key%d", i)
+			if err := s.Put(key, i); err != nil {
+				t.Errorf("Put() error = %v", err)
+			}
+			var got int
+			if err := s.Get(key, &got); err != nil || got != i {
+				t.Errorf("Get() = %v, %v, want %v", got, err, i)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := s.Delete("key1"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if err := s.Delete("key1"); errors.Cause(err) != ErrNotFound {
+		t.Errorf("Delete() of a missing key error = %v, want %v", err, ErrNotFound)
+	}
+	var got int
+	if err := s.Get("key1", &got); errors.Cause(err) != ErrNotFound {
+		t.Errorf("Get() of a missing key error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		codec   Codec
+		setup   func(path string)
+		put     map[string]string
+		delete  []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "should persist values across stores",
+			put:  map[string]string{"a": "1", "b": "2"},
+			want: map[string]string{"a": "1", "b": "2"},
+		},
+		{
+			name:   "should persist deletes",
+			put:    map[string]string{"a": "1", "b": "2"},
+			delete: []string{"a"},
+			want:   map[string]string{"b": "2"},
+		},
+		{
+			name:  "should persist values encoded as json",
+			codec: JSONCodec,
+			put:   map[string]string{"a": "1"},
+			want:  map[string]string{"a": "1"},
+		},
+		{
+			name:  "should persist values with a custom codec",
+			codec: testCodec{},
+			put:   map[string]string{"a": "1"},
+			want:  map[string]string{"a": "1"},
+		},
+		{
+			name:  "should load an empty file",
+			setup: func(path string) { _ = ioutil.WriteFile(path, nil, 0600) },
+			want:  map[string]string{},
+		},
+		{
+			name:    "should error on an invalid file",
+			setup:   func(path string) { _ = ioutil.WriteFile(path, []byte("not a store"), 0600) },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "slackbot")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "store")
+			if tt.setup != nil {
+				tt.setup(path)
+			}
+			s, err := NewFileStore(path, tt.codec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewFileStore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for k, v := range tt.put {
+				if err := s.Put(k, v); err != nil {
+					t.Fatalf("Put() error = %v", err)
+				}
+			}
+			for _, k := range tt.delete {
+				if err := s.Delete(k); err != nil {
+					t.Fatalf("Delete() error = %v", err)
+				}
+			}
+
+			reopened, err := NewFileStore(path, tt.codec)
+			if err != nil {
+				t.Fatalf("NewFileStore() error = %v", err)
+			}
+			if len(reopened.data) != len(tt.want) {
+				t.Errorf("NewFileStore() loaded %d keys, want %d", len(reopened.data), len(tt.want))
+			}
+			for k, v := range tt.want {
+				var got string
+				if err := reopened.Get(k, &got); err != nil || got != v {
+					t.Errorf("Get(%s) = %v, %v, want %v", k, got, err, v)
+				}
+			}
+		})
+	}
+}
+
+func TestFileStore_json(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slackbot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "store.json")
+	s, err := NewFileStore(path, JSONCodec)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	if err := s.Put("prefs", map[string]string{"env": "staging"}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	content, _ := ioutil.ReadFile(path)
+	want := "{\n  \"prefs\": {\n    \"env\": \"staging\"\n  }\n}"
+	if string(content) != want {
+		t.Errorf("file content = %s, want %s", content, want)
+	}
+}
+
+func TestSimpleStore_WithCodec(t *testing.T) {
+	type value struct {
+		Name  string
+		Count int
+	}
+	tests := []struct {
+		name    string
+		codec   Codec
+		wantRaw string
+	}{
+		{
+			name:  "should default to gob",
+			codec: nil,
+		},
+		{
+			name:    "should encode values as json",
+			codec:   JSONCodec,
+			wantRaw: `{"Name":"deploys","Count":3}`,
+		},
+		{
+			name:    "should encode values with a custom codec",
+			codec:   testCodec{},
+			wantRaw: `codec:{"Name":"deploys","Count":3}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := SimpleStore{}
+			store := s.WithCodec(tt.codec)
+			if err := store.Put("key", value{Name: "deploys", Count: 3}); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+			var got value
+			if err := store.Get("key", &got); err != nil || got != (value{Name: "deploys", Count: 3}) {
+				t.Errorf("Get() = %v, %v", got, err)
+			}
+			if tt.wantRaw != "" && string(s["key"]) != tt.wantRaw {
+				t.Errorf("Put() saved %q, want %q", s["key"], tt.wantRaw)
+			}
+			if err := store.Delete("key"); err != nil || len(s) != 0 {
+				t.Errorf("Delete() error = %v, %d keys left", err, len(s))
+			}
+		})
+	}
+}
+
+// testCodec is a custom codec that prefixes JSON.
+type testCodec struct{}
+
+func (testCodec) Marshal(value interface{}) ([]byte, error) {
+	b, err := json.Marshal(value)
+	return append([]byte("codec:"), b...), err
+}
+
+func (testCodec) Unmarshal(data []byte, value interface{}) error {
+	return json.Unmarshal(bytes.TrimPrefix(data, []byte("codec:")), value)
+}
@@ -0,0 +1,142 @@
+package slackbot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	taskPausedKeyPrefix  = "task:paused:"
+	taskPausedMessage    = "Paused scheduled task %s."
+	taskResumedMessage   = "Resumed scheduled task %s."
+	noScheduledTasks     = "There are no scheduled tasks."
+	taskListPausedSuffix = " *(paused)*"
+)
+
+var (
+	taskPauseRegex  = regexp.MustCompile(`^(?i)pause task (.+)$`)
+	taskResumeRegex = regexp.MustCompile(`^(?i)resume task (.+)$`)
+	taskListRegex   = regexp.MustCompile(`^(?i)list tasks$`)
+)
+
+// ScheduledTaskStatus describes one of the bot's scheduled tasks.
+type ScheduledTaskStatus struct {
+	Name     string
+	Schedule string
+	Next     time.Time
+	Paused   bool
+}
+
+// ListScheduledTasks returns the bot's scheduled tasks, when they will next run and whether they are paused.
+// Tasks without a Name are named by their position in the ScheduledTasks.
+func (bot *Bot) ListScheduledTasks() []ScheduledTaskStatus {
+	now := bot.clock().Now()
+	tasks := make([]ScheduledTaskStatus, len(bot.ScheduledTasks))
+	for i := range bot.ScheduledTasks {
+		t := &bot.ScheduledTasks[i]
+		tasks[i] = ScheduledTaskStatus{Name: t.name(i), Schedule: t.Schedule, Paused: bot.taskPaused(t.name(i))}
+		if s, err := scheduleParser.Parse(t.Schedule); err == nil {
+			tasks[i].Next = s.Next(now)
+		}
+	}
+	return tasks
+}
+
+// PauseTask stops the scheduled task with the name from running until ResumeTask is called, for example to
+// silence a noisy report during an incident. Paused tasks are saved in the bot's Store, so they stay paused
+// after a restart when the Store is persistent.
+func (bot *Bot) PauseTask(name string) error {
+	task, err := bot.taskName(name)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(bot.store().Put(taskPausedKeyPrefix+task, true), "unable to pause scheduled task %s", task)
+}
+
+// ResumeTask runs a task paused with PauseTask on its schedule again.
+func (bot *Bot) ResumeTask(name string) error {
+	task, err := bot.taskName(name)
+	if err != nil {
+		return err
+	}
+	_ = bot.store().Delete(taskPausedKeyPrefix + task)
+	return nil
+}
+
+// taskPaused returns true if the task with the name has been paused.
+func (bot *Bot) taskPaused(name string) bool {
+	var paused bool
+	return bot.store().Get(taskPausedKeyPrefix+name, &paused) == nil && paused
+}
+
+// taskName returns the name of the bot's scheduled task matching the name ignoring case.
+func (bot *Bot) taskName(name string) (string, error) {
+	for i := range bot.ScheduledTasks {
+		if n := bot.ScheduledTasks[i].name(i); strings.EqualFold(n, strings.TrimSpace(name)) {
+			return n, nil
+		}
+	}
+	return "", errors.Errorf("there is no scheduled task called %s", name)
+}
+
+// ScheduledTaskListeners returns DirectListeners for admins to "list tasks", "pause task <name>" and "resume
+// task <name>", only the users and channels allowed by the admins ACL can use them. They are not enabled by
+// default, add them to the bot's DirectListeners to enable them.
+func ScheduledTaskListeners(admins *ACL) []Listener {
+	return []Listener{
+		{
+			Usage: "list tasks",
+			Regex: taskListRegex,
+			ACL:   admins,
+			Handler: func(bot *Bot, ev *slack.MessageEvent) {
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), formatTaskList(bot.ListScheduledTasks()))
+			},
+		},
+		{
+			Usage: "pause task <name>",
+			Regex: taskPauseRegex,
+			ACL:   admins,
+			Handler: func(bot *Bot, ev *slack.MessageEvent) {
+				name := taskPauseRegex.FindStringSubmatch(ev.Text)[1]
+				msg := fmt.Sprintf(taskPausedMessage, name)
+				if err := bot.PauseTask(name); err != nil {
+					msg = err.Error()
+				}
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), msg)
+			},
+		},
+		{
+			Usage: "resume task <name>",
+			Regex: taskResumeRegex,
+			ACL:   admins,
+			Handler: func(bot *Bot, ev *slack.MessageEvent) {
+				name := taskResumeRegex.FindStringSubmatch(ev.Text)[1]
+				msg := fmt.Sprintf(taskResumedMessage, name)
+				if err := bot.ResumeTask(name); err != nil {
+					msg = err.Error()
+				}
+				_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), msg)
+			},
+		},
+	}
+}
+
+// formatTaskList formats the tasks as a list with their schedules, paused tasks are marked.
+func formatTaskList(tasks []ScheduledTaskStatus) string {
+	if len(tasks) == 0 {
+		return noScheduledTasks
+	}
+	lines := make([]string, len(tasks))
+	for i, t := range tasks {
+		lines[i] = fmt.Sprintf("• *%s* `%s`", t.Name, t.Schedule)
+		if t.Paused {
+			lines[i] += taskListPausedSuffix
+		}
+	}
+	return strings.Join(lines, "\n")
+}
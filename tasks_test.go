@@ -0,0 +1,165 @@
+package slackbot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func testScheduledTasks() []ScheduledTask {
+	task := func(*Bot) {}
+	return []ScheduledTask{
+		{Name: "daily report", Schedule: "0 9 * * *", Task: task},
+		{Schedule: "@every 5m", Task: task},
+	}
+}
+
+func TestBot_PauseTask(t *testing.T) {
+	tests := []struct {
+		name       string
+		pause      []string
+		resume     []string
+		wantPaused []bool
+		wantErr    bool
+	}{
+		{
+			name:       "should pause a task by name ignoring case",
+			pause:      []string{"Daily Report"},
+			wantPaused: []bool{true, false},
+		},
+		{
+			name:       "should pause a task without a name by its number",
+			pause:      []string{"2"},
+			wantPaused: []bool{false, true},
+		},
+		{
+			name:       "should resume a paused task",
+			pause:      []string{"daily report"},
+			resume:     []string{"daily report"},
+			wantPaused: []bool{false, false},
+		},
+		{
+			name:       "should error for an unknown task",
+			pause:      []string{"weekly report"},
+			wantPaused: []bool{false, false},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{ScheduledTasks: testScheduledTasks()}
+			var err error
+			for _, name := range tt.pause {
+				err = bot.PauseTask(name)
+			}
+			for _, name := range tt.resume {
+				err = bot.ResumeTask(name)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PauseTask() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var paused []bool
+			for _, task := range bot.ListScheduledTasks() {
+				paused = append(paused, task.Paused)
+			}
+			if !reflect.DeepEqual(paused, tt.wantPaused) {
+				t.Errorf("ListScheduledTasks() paused = %v, want %v", paused, tt.wantPaused)
+			}
+		})
+	}
+}
+
+func TestBot_ListScheduledTasks(t *testing.T) {
+	bot := &Bot{ScheduledTasks: testScheduledTasks()}
+	tasks := bot.ListScheduledTasks()
+	if len(tasks) != 2 || tasks[0].Name != "daily report" || tasks[1].Name != "2" || tasks[1].Schedule != "@every 5m" {
+		t.Fatalf("ListScheduledTasks() = %v", tasks)
+	}
+	for _, task := range tasks {
+		if task.Next.IsZero() {
+			t.Errorf("ListScheduledTasks() did not set the next run of %s", task.Name)
+		}
+	}
+}
+
+func TestTaskFuncWrapper_Run_paused(t *testing.T) {
+	bot := &Bot{ScheduledTasks: testScheduledTasks()}
+	if err := bot.PauseTask("daily report"); err != nil {
+		t.Fatalf("PauseTask() error = %v", err)
+	}
+	ran := false
+	taskFuncWrapper{name: "daily report", bot: bot, taskFunc: func(*Bot) { ran = true }}.Run()
+	if ran {
+		t.Errorf("Run() ran a paused task")
+	}
+}
+
+func TestScheduledTaskListeners(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     string
+		messages []string
+		want     string
+	}{
+		{
+			name:     "should list the tasks",
+			messages: []string{"pause task daily report", "list tasks"},
+			want:     "• *daily report* `0 9 * * *` *(paused)*\n• *2* `@every 5m`",
+		},
+		{
+			name:     "should pause a task",
+			messages: []string{"pause task daily report"},
+			want:     "Paused scheduled task daily report.",
+		},
+		{
+			name:     "should resume a task",
+			messages: []string{"resume task daily report"},
+			want:     "Resumed scheduled task daily report.",
+		},
+		{
+			name:     "should reply with the error for an unknown task",
+			messages: []string{"pause task weekly report"},
+			want:     "there is no scheduled task called weekly report",
+		},
+		{
+			name:     "should not let other users pause tasks",
+			user:     "U2",
+			messages: []string{"pause task daily report"},
+			want:     aclUserDeniedMessage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						got = msgValues(opts...).Get("text")
+						return s, "ts", nil
+					},
+				},
+				DirectListeners: ScheduledTaskListeners(&ACL{Users: []string{"U1"}}),
+				ScheduledTasks:  testScheduledTasks(),
+				userDetails:     &slack.UserDetails{ID: "bot"},
+			}
+			bot.init()
+			user := tt.user
+			if user == "" {
+				user = "U1"
+			}
+			for _, text := range tt.messages {
+				bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "D1", User: user, Text: text, Timestamp: "1.1"}})
+			}
+			if got != tt.want {
+				t.Errorf("reply = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTaskList(t *testing.T) {
+	if got := formatTaskList(nil); got != noScheduledTasks {
+		t.Errorf("formatTaskList() = %q, want %q", got, noScheduledTasks)
+	}
+}
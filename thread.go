@@ -0,0 +1,53 @@
+package slackbot
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const threadPageSize = 200
+
+// ThreadMessages returns all of the messages in the thread, starting with the parent message.
+func (bot *Bot) ThreadMessages(channel string, threadTS string) ([]slack.Message, error) {
+	return bot.threadMessages(bot.context(), channel, threadTS)
+}
+
+func (bot *Bot) threadMessages(ctx context.Context, channel string, threadTS string) ([]slack.Message, error) {
+	params := &slack.GetConversationRepliesParameters{
+		ChannelID: channel,
+		Timestamp: threadTS,
+		Limit:     threadPageSize,
+	}
+	var messages []slack.Message
+	for {
+		var page []slack.Message
+		var hasMore bool
+		var cursor string
+		err := bot.withRetry(func() (err error) {
+			page, hasMore, cursor, err = bot.api().GetConversationRepliesContext(ctx, params)
+			return err
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to get the messages in thread %s in %s", threadTS, channel)
+		}
+		messages = append(messages, page...)
+		if !hasMore || cursor == "" {
+			return messages, nil
+		}
+		params.Cursor = cursor
+	}
+}
+
+// Thread returns the messages in the thread the event was sent in, starting with the parent message. They
+// are loaded the first time Thread is called. If the event is not in a thread nil is returned.
+func (ctx *MessageContext) Thread() ([]slack.Message, error) {
+	if ctx.Event.ThreadTimestamp == "" {
+		return nil, nil
+	}
+	ctx.threadOnce.Do(func() {
+		ctx.thread, ctx.threadErr = ctx.Bot.threadMessages(ctx, ctx.Event.Channel, ctx.Event.ThreadTimestamp)
+	})
+	return ctx.thread, ctx.threadErr
+}
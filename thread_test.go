@@ -0,0 +1,111 @@
+package slackbot
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func threadMessage(ts string, text string) slack.Message {
+	return slack.Message{Msg: slack.Msg{Timestamp: ts, ThreadTimestamp: "1.1", Text: text}}
+}
+
+func TestBot_ThreadMessages(t *testing.T) {
+	tests := []struct {
+		name      string
+		pages     map[string][]slack.Message
+		err       error
+		wantCalls int
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:      "should return the messages in the thread",
+			pages:     map[string][]slack.Message{"": {threadMessage("1.1", "deploy failed"), threadMessage("1.2", "retrying")}},
+			wantCalls: 1,
+			want:      []string{"deploy failed", "retrying"},
+		},
+		{
+			name: "should get every page of messages",
+			pages: map[string][]slack.Message{
+				"":      {threadMessage("1.1", "deploy failed")},
+				"page2": {threadMessage("1.2", "retrying")},
+				"page3": {threadMessage("1.3", "fixed")},
+			},
+			wantCalls: 3,
+			want:      []string{"deploy failed", "retrying", "fixed"},
+		},
+		{
+			name:      "should return an error if the messages can't be loaded",
+			err:       errors.New("thread_not_found"),
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			bot := &Bot{
+				API: &mockAPI{
+					getReplies: func(params *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error) {
+						calls++
+						if params.ChannelID != "C1" || params.Timestamp != "1.1" {
+							t.Errorf("GetConversationReplies() called with %+v", params)
+						}
+						next := map[string]string{"": "page2", "page2": "page3"}[params.Cursor]
+						if _, ok := tt.pages[next]; !ok {
+							next = ""
+						}
+						return tt.pages[params.Cursor], next != "", next, tt.err
+					},
+				},
+			}
+			got, err := bot.ThreadMessages("C1", "1.1")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ThreadMessages() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			var texts []string
+			for _, m := range got {
+				texts = append(texts, m.Text)
+			}
+			if !reflect.DeepEqual(texts, tt.want) {
+				t.Errorf("ThreadMessages() got = %v, want %v", texts, tt.want)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("ThreadMessages() made %d calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestMessageContext_Thread(t *testing.T) {
+	calls := 0
+	bot := &Bot{
+		API: &mockAPI{
+			getReplies: func(params *slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error) {
+				calls++
+				return []slack.Message{threadMessage("1.1", "deploy failed"), threadMessage("1.2", "why?")}, false, "", nil
+			},
+		},
+	}
+
+	ctx := &MessageContext{Context: context.Background(), Bot: bot, Event: &slack.MessageEvent{Msg: slack.Msg{Channel: "C1", Timestamp: "1.2", ThreadTimestamp: "1.1"}}}
+	for i := 0; i < 2; i++ {
+		thread, err := ctx.Thread()
+		if err != nil || len(thread) != 2 {
+			t.Errorf("Thread() = %v, %v, want 2 messages", thread, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Thread() loaded the thread %d times, want 1", calls)
+	}
+
+	ctx = &MessageContext{Context: context.Background(), Bot: bot, Event: &slack.MessageEvent{Msg: slack.Msg{Channel: "C1", Timestamp: "2.1"}}}
+	if thread, err := ctx.Thread(); thread != nil || err != nil {
+		t.Errorf("Thread() = %v, %v, want nil for a message outside a thread", thread, err)
+	}
+}
@@ -0,0 +1,51 @@
+package slackbot
+
+import (
+	"time"
+)
+
+const (
+	userCacheTTL   = time.Hour
+	userTimeLayout = "Mon Jan 2 3:04 PM MST"
+)
+
+// UserLocation returns the timezone set in the slack profile of the user with the ID passed in.
+// Locations are cached so repeated calls will not hit the slack api. If the user has no
+// timezone set the local timezone is returned.
+func (bot *Bot) UserLocation(userID string) (*time.Location, error) {
+	c := bot.locationCache()
+	if loc, ok := c.get(userID); ok {
+		return loc.(*time.Location), nil
+	}
+	u, err := bot.api().GetUserInfoContext(bot.context(), userID)
+	if err != nil {
+		return nil, err
+	}
+	loc := time.Local
+	if u.TZ != "" {
+		if loc, err = time.LoadLocation(u.TZ); err != nil {
+			return nil, err
+		}
+	}
+	c.set(userID, loc)
+	return loc, nil
+}
+
+// FormatTimeFor formats the time in the timezone of the user with the ID passed in. If the
+// user's timezone can not be found the time is formatted in the local timezone.
+func (bot *Bot) FormatTimeFor(userID string, t time.Time) string {
+	loc, err := bot.UserLocation(userID)
+	if err != nil {
+		loc = time.Local
+	}
+	return t.In(loc).Format(userTimeLayout)
+}
+
+func (bot *Bot) locationCache() *cache {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.locations == nil {
+		bot.locations = newCache(userCacheTTL, bot.clock())
+	}
+	return bot.locations
+}
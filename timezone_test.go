@@ -0,0 +1,104 @@
+package slackbot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func TestBot_UserLocation(t *testing.T) {
+	calls := 0
+	type fields struct {
+		API MessagingClient
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		userID    string
+		want      string
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name: "should return the user's location and cache it",
+			fields: fields{
+				API: &mockAPI{
+					getUserInfo: func(s string) (*slack.User, error) {
+						calls++
+						return &slack.User{ID: s, TZ: "America/Denver"}, nil
+					},
+				},
+			},
+			userID:    "U123",
+			want:      "America/Denver",
+			wantCalls: 1,
+		},
+		{
+			name: "should return local if the user has no timezone",
+			fields: fields{
+				API: &mockAPI{
+					getUserInfo: func(s string) (*slack.User, error) {
+						calls++
+						return &slack.User{ID: s}, nil
+					},
+				},
+			},
+			userID:    "U123",
+			want:      time.Local.String(),
+			wantCalls: 1,
+		},
+		{
+			name: "should return an error if the user can not be found",
+			fields: fields{
+				API: &mockAPI{
+					getUserInfo: func(s string) (*slack.User, error) {
+						calls++
+						return nil, errors.New("user_not_found")
+					},
+				},
+			},
+			userID:    "U123",
+			wantCalls: 2,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = 0
+			bot := &Bot{
+				API: tt.fields.API,
+			}
+			var got *time.Location
+			var err error
+			for i := 0; i < 2; i++ {
+				got, err = bot.UserLocation(tt.userID)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UserLocation() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("UserLocation() users.info calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if got != nil && got.String() != tt.want {
+				t.Errorf("UserLocation() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_FormatTimeFor(t *testing.T) {
+	bot := &Bot{
+		API: &mockAPI{
+			getUserInfo: func(s string) (*slack.User, error) {
+				return &slack.User{ID: s, TZ: "Asia/Tokyo"}, nil
+			},
+		},
+	}
+	got := bot.FormatTimeFor("U123", time.Date(2020, 6, 10, 14, 30, 0, 0, time.UTC))
+	if want := "Wed Jun 10 11:30 PM JST"; got != want {
+		t.Errorf("FormatTimeFor() got = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,234 @@
+package slackbot
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+// maxTokenRefreshes is how many times in a row the bot will refresh its token without connecting before it
+// gives up.
+const maxTokenRefreshes = 3
+
+type (
+	// TokenProvider supplies the bot's slack token. The bot asks for a token when it starts without one, and
+	// again when slack reports that its token is invalid, so a token rotated by a secrets manager is picked up
+	// by reconnecting instead of the bot stopping.
+	TokenProvider interface {
+		Token(ctx context.Context) (string, error)
+	}
+
+	// TokenProviderFunc adapts a function to a TokenProvider.
+	TokenProviderFunc func(ctx context.Context) (string, error)
+
+	// SecretStore reads a secret by name, it is implemented by small adapters around secrets managers such
+	// as AWS Secrets Manager or Vault so the bot doesn't depend on their clients. See SecretToken.
+	SecretStore interface {
+		GetSecret(ctx context.Context, name string) (string, error)
+	}
+
+	// SecretStoreFunc adapts a function to a SecretStore.
+	SecretStoreFunc func(ctx context.Context, name string) (string, error)
+)
+
+// Token calls f.
+func (f TokenProviderFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// GetSecret calls f.
+func (f SecretStoreFunc) GetSecret(ctx context.Context, name string) (string, error) {
+	return f(ctx, name)
+}
+
+// EnvToken returns a TokenProvider that reads the token from the environment variable each time it is asked
+// for one.
+func EnvToken(name string) TokenProvider {
+	return TokenProviderFunc(func(ctx context.Context) (string, error) {
+		token := strings.TrimSpace(os.Getenv(name))
+		if token == "" {
+			return "", errors.Errorf("the environment variable %s is not set", name)
+		}
+		return token, nil
+	})
+}
+
+// FileToken returns a TokenProvider that reads the token from the file each time it is asked for one, such
+// as a mounted kubernetes secret that is updated when the token is rotated. Surrounding whitespace is removed.
+func FileToken(path string) TokenProvider {
+	return TokenProviderFunc(func(ctx context.Context) (string, error) {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to read the token from %s", path)
+		}
+		token := strings.TrimSpace(string(b))
+		if token == "" {
+			return "", errors.Errorf("the token file %s is empty", path)
+		}
+		return token, nil
+	})
+}
+
+// SecretToken returns a TokenProvider that reads the token from the secret store each time it is asked for
+// one, so a token rotated in the store is picked up when slack reports the old one is invalid.
+//
+// Example:
+//
+//	bot := slackbot.Bot{
+//		TokenProvider: slackbot.SecretToken(slackbot.SecretStoreFunc(func(ctx context.Context, name string) (string, error) {
+//			out, err := secretsManager.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{SecretId: &name})
+//			if err != nil {
+//				return "", err
+//			}
+//			return *out.SecretString, nil
+//		}), "slackbot/token"),
+//	}
+func SecretToken(store SecretStore, name string) TokenProvider {
+	return TokenProviderFunc(func(ctx context.Context) (string, error) {
+		token, err := store.GetSecret(ctx, name)
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to read the token from the secret %s", name)
+		}
+		token = strings.TrimSpace(token)
+		if token == "" {
+			return "", errors.Errorf("the secret %s is empty", name)
+		}
+		return token, nil
+	})
+}
+
+// initialToken sets the bot's Token from its TokenProvider if it wasn't given one.
+func (bot *Bot) initialToken() {
+	if bot.Token != "" || bot.TokenProvider == nil {
+		return
+	}
+	token, err := bot.TokenProvider.Token(bot.context())
+	if err != nil {
+		log.Printf("Error getting the slack token - %s\n", err)
+		return
+	}
+	bot.Token = token
+}
+
+// api returns the bot's API client. Reloading the token replaces the client while handlers are using it, so
+// it is always read through the lock.
+func (bot *Bot) api() MessagingClient {
+	bot.apiMu.RLock()
+	defer bot.apiMu.RUnlock()
+	return bot.API
+}
+
+// token returns the bot's Token, which is replaced along with its client.
+func (bot *Bot) token() string {
+	bot.apiMu.RLock()
+	defer bot.apiMu.RUnlock()
+	return bot.Token
+}
+
+// apiGeneration returns how many times the bot's client has been set, so a call that failed because the token
+// was invalid can tell if another call already replaced the client.
+func (bot *Bot) apiGeneration() int {
+	bot.apiMu.RLock()
+	defer bot.apiMu.RUnlock()
+	return bot.apiGen
+}
+
+// setClient replaces the bot's token and the client that uses it.
+func (bot *Bot) setClient(token string, api MessagingClient) {
+	bot.apiMu.Lock()
+	defer bot.apiMu.Unlock()
+	bot.Token, bot.API = token, api
+	bot.apiGen++
+}
+
+// clientFor creates the slack client the bot uses for the token.
+func (bot *Bot) clientFor(token string) MessagingClient {
+	if bot.newClient != nil {
+		return bot.newClient(token)
+	}
+	return newSlackClient(token)
+}
+
+// refreshToken asks the TokenProvider for a new token after slack reported that the bot's token is invalid,
+// and reconnects with it. The client with the rejected token is disconnected first, so it stops managing its
+// connection and forwarding events.
+func (bot *Bot) refreshToken() error {
+	if bot.tokenRefreshes >= maxTokenRefreshes {
+		return errors.Errorf("the refreshed token was invalid %d times", bot.tokenRefreshes)
+	}
+	bot.tokenRefreshes++
+	gen := bot.apiGeneration()
+	if err := bot.api().Disconnect(); err != nil && err != slack.ErrAlreadyDisconnected {
+		log.Printf("Error disconnecting - %s\n", err)
+	}
+	if err := bot.reloadToken(gen); err != nil {
+		return err
+	}
+	return bot.connect()
+}
+
+// reloadAfterAuthError reloads the token when a slack api call made with the generation of the bot's client
+// fails because the token is invalid, and returns true if the call should be retried with the new token. Bots
+// connected over RTM reload the token when the connection reports it is invalid instead.
+func (bot *Bot) reloadAfterAuthError(gen int, err error) bool {
+	if err == nil || bot.TokenProvider == nil || !isAuthError(err) {
+		return false
+	}
+	bot.mu.Lock()
+	connected := bot.rtmConnected
+	bot.mu.Unlock()
+	if connected {
+		return false
+	}
+	if reloadErr := bot.reloadToken(gen); reloadErr != nil {
+		log.Printf("Error reloading the slack token - %s\n", reloadErr)
+		return false
+	}
+	return true
+}
+
+// reloadToken asks the TokenProvider for a new token and replaces the bot's client with one that uses it. gen
+// is the generation of the client whose token was rejected, when several calls fail at the same time only
+// the first reloads the token and the others use its client. Bots that were given their API client can't be
+// reloaded.
+func (bot *Bot) reloadToken(gen int) error {
+	if bot.TokenProvider == nil {
+		return errors.New("the bot has no TokenProvider")
+	}
+	if !bot.ownsClient {
+		return errors.New("the bot's API client was not created from its token")
+	}
+	bot.tokenMu.Lock()
+	defer bot.tokenMu.Unlock()
+	if bot.apiGeneration() != gen {
+		return nil
+	}
+
+	token, err := bot.TokenProvider.Token(bot.context())
+	if err != nil {
+		return errors.Wrap(err, "unable to refresh the slack token")
+	}
+	if token == "" || token == bot.token() {
+		return errors.New("the TokenProvider didn't return a new token")
+	}
+	api := bot.clientFor(token)
+	if bot.DryRun {
+		api = &dryRunClient{MessagingClient: api, bot: bot}
+	}
+	bot.setClient(token, api)
+	return nil
+}
+
+// isAuthError returns true if slack rejected the call because of the token.
+func isAuthError(err error) bool {
+	switch err.Error() {
+	case "invalid_auth", "not_authed", "token_revoked", "token_expired", "account_inactive":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,422 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func TestBot_initialToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		provider TokenProvider
+		want     string
+	}{
+		{
+			name:  "should keep the token without a provider",
+			token: "xoxb-1",
+			want:  "xoxb-1",
+		},
+		{
+			name:     "should not replace a token that was set",
+			token:    "xoxb-1",
+			provider: TokenProviderFunc(func(ctx context.Context) (string, error) { return "xoxb-2", nil }),
+			want:     "xoxb-1",
+		},
+		{
+			name:     "should get the token from the provider",
+			provider: TokenProviderFunc(func(ctx context.Context) (string, error) { return "xoxb-2", nil }),
+			want:     "xoxb-2",
+		},
+		{
+			name:     "should leave the token empty if the provider fails",
+			provider: TokenProviderFunc(func(ctx context.Context) (string, error) { return "", errors.New("denied") }),
+			want:     "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{Token: tt.token, TokenProvider: tt.provider}
+			bot.initialToken()
+			if bot.Token != tt.want {
+				t.Errorf("initialToken() token = %v, want %v", bot.Token, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_listen_invalidAuth(t *testing.T) {
+	tests := []struct {
+		name             string
+		provider         TokenProvider
+		ownsClient       bool
+		refreshes        int
+		wantToken        string
+		wantErr          bool
+		wantDisconnected bool
+	}{
+		{
+			name:       "should stop without a token provider",
+			ownsClient: true,
+			wantToken:  "xoxb-old",
+			wantErr:    true,
+		},
+		{
+			name:             "should reconnect with the refreshed token",
+			provider:         TokenProviderFunc(func(ctx context.Context) (string, error) { return "xoxb-new", nil }),
+			ownsClient:       true,
+			wantToken:        "xoxb-new",
+			wantDisconnected: true,
+		},
+		{
+			name:             "should stop if the provider returns the same token",
+			provider:         TokenProviderFunc(func(ctx context.Context) (string, error) { return "xoxb-old", nil }),
+			ownsClient:       true,
+			wantToken:        "xoxb-old",
+			wantErr:          true,
+			wantDisconnected: true,
+		},
+		{
+			name:             "should stop if the provider fails",
+			provider:         TokenProviderFunc(func(ctx context.Context) (string, error) { return "", errors.New("denied") }),
+			ownsClient:       true,
+			wantToken:        "xoxb-old",
+			wantErr:          true,
+			wantDisconnected: true,
+		},
+		{
+			name:             "should stop if the bot was given its client",
+			provider:         TokenProviderFunc(func(ctx context.Context) (string, error) { return "xoxb-new", nil }),
+			wantToken:        "xoxb-old",
+			wantErr:          true,
+			wantDisconnected: true,
+		},
+		{
+			name:       "should stop after refreshing too many times without connecting",
+			provider:   TokenProviderFunc(func(ctx context.Context) (string, error) { return "xoxb-new", nil }),
+			ownsClient: true,
+			refreshes:  maxTokenRefreshes,
+			wantToken:  "xoxb-old",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disconnected := false
+			old := &mockAPI{
+				incomingEvents: make(chan slack.RTMEvent, 1),
+				disconnect: func() error {
+					disconnected = true
+					return nil
+				},
+			}
+			old.incomingEvents <- slack.RTMEvent{Type: "invalid_auth", Data: &slack.InvalidAuthEvent{}}
+			bot := &Bot{
+				Token:          "xoxb-old",
+				TokenProvider:  tt.provider,
+				API:            old,
+				ownsClient:     tt.ownsClient,
+				tokenRefreshes: tt.refreshes,
+			}
+			var created string
+			bot.newClient = func(token string) MessagingClient {
+				created = token
+				return &mockAPI{
+					getInfo:          func() *slack.Info { return &slack.Info{User: &slack.UserDetails{ID: "bot"}} },
+					manageConnection: func() { bot.Stop() },
+					disconnect:       func() error { return nil },
+				}
+			}
+			err := bot.listen()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("listen() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if bot.Token != tt.wantToken {
+				t.Errorf("listen() token = %v, want %v", bot.Token, tt.wantToken)
+			}
+			if disconnected != tt.wantDisconnected {
+				t.Errorf("listen() disconnected the old client = %v, want %v", disconnected, tt.wantDisconnected)
+			}
+			if reconnected := bot.API != old; reconnected != (created != "") {
+				t.Errorf("listen() replaced the client = %v, created a client = %v", reconnected, created != "")
+			}
+		})
+	}
+}
+
+func TestEnvToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "should read the token from the environment",
+			value: "xoxb-env\n",
+			want:  "xoxb-env",
+		},
+		{
+			name:    "should error if the variable is not set",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv("SLACKBOT_TEST_TOKEN", tt.value)
+			defer os.Unsetenv("SLACKBOT_TEST_TOKEN")
+			got, err := EnvToken("SLACKBOT_TEST_TOKEN").Token(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Token() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Token() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slackbot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tests := []struct {
+		name     string
+		contents string
+		write    bool
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "should read the token from the file",
+			contents: "xoxb-file\n",
+			write:    true,
+			want:     "xoxb-file",
+		},
+		{
+			name:     "should error if the file is empty",
+			contents: " \n",
+			write:    true,
+			wantErr:  true,
+		},
+		{
+			name:    "should error if the file doesn't exist",
+			wantErr: true,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, fmt.Sprintf("token%d", i))
+			if tt.write {
+				if err := ioutil.WriteFile(path, []byte(tt.contents), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := FileToken(path).Token(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Token() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Token() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecretToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		store   SecretStore
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "should read the named secret",
+			store: SecretStoreFunc(func(ctx context.Context, name string) (string, error) {
+				if name != "slackbot/token" {
+					return "", errors.New("not found")
+				}
+				return "xoxb-secret", nil
+			}),
+			want: "xoxb-secret",
+		},
+		{
+			name: "should error if the store fails",
+			store: SecretStoreFunc(func(ctx context.Context, name string) (string, error) {
+				return "", errors.New("access denied")
+			}),
+			wantErr: true,
+		},
+		{
+			name: "should error if the secret is empty",
+			store: SecretStoreFunc(func(ctx context.Context, name string) (string, error) {
+				return "", nil
+			}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SecretToken(tt.store, "slackbot/token").Token(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Token() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Token() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_withRetry_authError(t *testing.T) {
+	tests := []struct {
+		name         string
+		rtmConnected bool
+		err          error
+		wantCalls    int
+		wantToken    string
+		wantErr      bool
+	}{
+		{
+			name:      "should reload the token and retry after an auth error",
+			err:       errors.New("token_revoked"),
+			wantCalls: 2,
+			wantToken: "xoxb-new",
+		},
+		{
+			name:      "should not reload the token after other errors",
+			err:       errors.New("channel_not_found"),
+			wantCalls: 1,
+			wantToken: "xoxb-old",
+			wantErr:   true,
+		},
+		{
+			name:         "should leave reloading to the connection when connected over RTM",
+			rtmConnected: true,
+			err:          errors.New("invalid_auth"),
+			wantCalls:    1,
+			wantToken:    "xoxb-old",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{
+				Token:         "xoxb-old",
+				TokenProvider: TokenProviderFunc(func(ctx context.Context) (string, error) { return "xoxb-new", nil }),
+				API:           &mockAPI{},
+				ownsClient:    true,
+				rtmConnected:  tt.rtmConnected,
+				newClient:     func(token string) MessagingClient { return &mockAPI{} },
+			}
+			calls := 0
+			err := bot.withRetry(func() error {
+				calls++
+				if bot.Token == "xoxb-old" {
+					return tt.err
+				}
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("withRetry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("withRetry() calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if bot.Token != tt.wantToken {
+				t.Errorf("withRetry() token = %v, want %v", bot.Token, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestBot_Reply_concurrentAuthErrors(t *testing.T) {
+	var mu sync.Mutex
+	refreshes := 0
+	client := func(token string) MessagingClient {
+		return &mockAPI{postMessage: func(channel string, opts ...slack.MsgOption) (string, string, error) {
+			if token == "xoxb-old" {
+				return "", "", errors.New("invalid_auth")
+			}
+			return channel, "1.1", nil
+		}}
+	}
+	bot := &Bot{
+		Token: "xoxb-old",
+		TokenProvider: TokenProviderFunc(func(ctx context.Context) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			refreshes++
+			return fmt.Sprintf("xoxb-new-%d", refreshes), nil
+		}),
+		API:        client("xoxb-old"),
+		ownsClient: true,
+		newClient:  client,
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := bot.Reply("C1", "hello"); err != nil {
+				t.Errorf("Reply() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if refreshes != 1 {
+		t.Errorf("the token was refreshed %d times, want 1", refreshes)
+	}
+	if token := bot.token(); token != "xoxb-new-1" {
+		t.Errorf("token = %q, want xoxb-new-1", token)
+	}
+}
+
+func TestBot_refreshToken_concurrentReplies(t *testing.T) {
+	client := func(token string) MessagingClient {
+		return &mockAPI{
+			getInfo:          func() *slack.Info { return &slack.Info{User: &slack.UserDetails{ID: "bot"}} },
+			manageConnection: func() {},
+			disconnect:       func() error { return nil },
+			postMessage: func(channel string, opts ...slack.MsgOption) (string, string, error) {
+				return channel, "1.1", nil
+			},
+		}
+	}
+	bot := &Bot{
+		Token:         "xoxb-old",
+		TokenProvider: TokenProviderFunc(func(ctx context.Context) (string, error) { return "xoxb-new", nil }),
+		API:           client("xoxb-old"),
+		ownsClient:    true,
+		newClient:     client,
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := bot.Reply("C1", "hello"); err != nil {
+				t.Errorf("Reply() error = %v", err)
+			}
+		}()
+	}
+	if err := bot.refreshToken(); err != nil {
+		t.Errorf("refreshToken() error = %v", err)
+	}
+	wg.Wait()
+	if token := bot.token(); token != "xoxb-new" {
+		t.Errorf("token = %q, want xoxb-new", token)
+	}
+}
@@ -0,0 +1,106 @@
+package slackbot
+
+import (
+	"bytes"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const topicStoreKeyPrefix = "topic "
+
+type (
+	// ChannelTopic keeps a channel's topic up to date on a schedule, such as the current sprint or a release
+	// freeze banner. Each time the Schedule runs the next of the Topics is rendered and set as the channel's
+	// topic, so a single topic is updated with the date, and several topics rotate. Topics are text/template
+	// templates executed with a TopicData, with an "oncall" function that returns a mention of the user on
+	// call for one of the bot's Rotations.
+	//
+	//	topic := slackbot.ChannelTopic{
+	//		Name:         "sprint",
+	//		Schedule:     "0 9 * * MON",
+	//		Channel:      "platform",
+	//		Topics:       []string{`Sprint {{.Sprint}} | week of {{.Time.Format "Jan 2"}} | on call: {{oncall "platform"}}`},
+	//		SprintStart:  time.Date(2021, 1, 4, 0, 0, 0, 0, time.Local),
+	//		SprintLength: 14 * 24 * time.Hour,
+	//	}
+	//	bot.ScheduledTasks = append(bot.ScheduledTasks, topic.ScheduledTask())
+	ChannelTopic struct {
+		Name     string
+		Schedule string
+		Channel  string
+		Topics   []string
+
+		// SprintStart is the start of the first sprint and SprintLength the length of each sprint, they are
+		// used to number the sprints in TopicData.
+		SprintStart  time.Time
+		SprintLength time.Duration
+	}
+
+	// TopicData is passed to ChannelTopic templates. Sprint is the number of the current sprint, starting at
+	// 1, or 0 if the topic doesn't have a SprintStart and SprintLength.
+	TopicData struct {
+		Time   time.Time
+		Sprint int
+	}
+)
+
+// ScheduledTask returns a ScheduledTask that sets the channel's topic. Errors rendering or setting the topic
+// are logged to the ErrorChannel.
+func (t ChannelTopic) ScheduledTask() ScheduledTask {
+	return ScheduledTask{
+		Name:     t.Name,
+		Schedule: t.Schedule,
+		TaskWithResult: func(bot *Bot) (string, error) {
+			return "", bot.UpdateTopic(t)
+		},
+	}
+}
+
+// UpdateTopic sets the channel's topic to the next of the topic's Topics. The position of rotating topics is
+// saved in the bot's Store.
+func (bot *Bot) UpdateTopic(t ChannelTopic) error {
+	if len(t.Topics) == 0 {
+		return errors.Errorf("channel topic %s has no topics", t.Name)
+	}
+	bot.topicMu.Lock()
+	var index int
+	_ = bot.store().Get(topicStoreKeyPrefix+t.Name, &index)
+	index %= len(t.Topics)
+	err := bot.store().Put(topicStoreKeyPrefix+t.Name, index+1)
+	bot.topicMu.Unlock()
+	if err != nil {
+		return errors.Wrapf(err, "unable to save channel topic %s", t.Name)
+	}
+	topic, err := bot.renderTopic(t, t.Topics[index], bot.clock().Now())
+	if err != nil {
+		return err
+	}
+	return bot.SetTopic(t.Channel, topic)
+}
+
+// renderTopic executes the topic template.
+func (bot *Bot) renderTopic(t ChannelTopic, text string, now time.Time) (string, error) {
+	tmpl, err := template.New(t.Name).Funcs(template.FuncMap{
+		"oncall": func(rotation string) (string, error) {
+			user, err := bot.OnCall(rotation)
+			if err != nil {
+				return "", err
+			}
+			return "<@" + user + ">", nil
+		},
+	}).Parse(text)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid template for channel topic %s", t.Name)
+	}
+	data := TopicData{Time: now}
+	if t.SprintLength > 0 && !t.SprintStart.IsZero() && !now.Before(t.SprintStart) {
+		data.Sprint = int(now.Sub(t.SprintStart)/t.SprintLength) + 1
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", errors.Wrapf(err, "unable to render channel topic %s", t.Name)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,122 @@
+package slackbot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_UpdateTopic(t *testing.T) {
+	tests := []struct {
+		name       string
+		topic      ChannelTopic
+		runs       int
+		wantTopics []string
+		wantErr    bool
+	}{
+		{
+			name:       "should set the topic with the on call user",
+			topic:      ChannelTopic{Name: "oncall", Channel: "C1", Topics: []string{`On call: {{oncall "platform"}}`}},
+			runs:       2,
+			wantTopics: []string{"C1: On call: <@U1>", "C1: On call: <@U1>"},
+		},
+		{
+			name:       "should rotate through the topics",
+			topic:      ChannelTopic{Name: "tips", Channel: "C1", Topics: []string{"Tip 1", "Tip 2"}},
+			runs:       3,
+			wantTopics: []string{"C1: Tip 1", "C1: Tip 2", "C1: Tip 1"},
+		},
+		{
+			name:    "should return an error for an invalid template",
+			topic:   ChannelTopic{Name: "broken", Channel: "C1", Topics: []string{"{{.Missing"}},
+			runs:    1,
+			wantErr: true,
+		},
+		{
+			name:    "should return an error for an unknown rotation",
+			topic:   ChannelTopic{Name: "oncall", Channel: "C1", Topics: []string{`{{oncall "database"}}`}},
+			runs:    1,
+			wantErr: true,
+		},
+		{
+			name:    "should return an error without topics",
+			topic:   ChannelTopic{Name: "empty", Channel: "C1"},
+			runs:    1,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var topics []string
+			bot := &Bot{
+				API: &mockAPI{
+					getChannel: func(s string) (slack.Channel, error) {
+						return testChannel(s, "platform", false), nil
+					},
+					setTopic: func(c string, topic string) (*slack.Channel, error) {
+						topics = append(topics, c+": "+topic)
+						return nil, nil
+					},
+				},
+				Rotations: testRotations(),
+			}
+			for i := 0; i < tt.runs; i++ {
+				if err := bot.UpdateTopic(tt.topic); (err != nil) != tt.wantErr {
+					t.Fatalf("UpdateTopic() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			}
+			if !reflect.DeepEqual(topics, tt.wantTopics) {
+				t.Errorf("UpdateTopic() set %q, want %q", topics, tt.wantTopics)
+			}
+		})
+	}
+}
+
+func TestBot_renderTopic(t *testing.T) {
+	start := time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		topic ChannelTopic
+		now   time.Time
+		want  string
+	}{
+		{
+			name:  "should number the sprints",
+			topic: ChannelTopic{SprintStart: start, SprintLength: 14 * 24 * time.Hour},
+			now:   start.Add(30 * 24 * time.Hour),
+			want:  "Sprint 3 | Feb 3",
+		},
+		{
+			name:  "should number the first sprint",
+			topic: ChannelTopic{SprintStart: start, SprintLength: 14 * 24 * time.Hour},
+			now:   start,
+			want:  "Sprint 1 | Jan 4",
+		},
+		{
+			name: "should leave the sprint empty without a sprint length",
+			now:  start,
+			want: "Sprint 0 | Jan 4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{}
+			got, err := bot.renderTopic(tt.topic, `Sprint {{.Sprint}} | {{.Time.Format "Jan 2"}}`, tt.now)
+			if err != nil {
+				t.Fatalf("renderTopic() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("renderTopic() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChannelTopic_ScheduledTask(t *testing.T) {
+	task := ChannelTopic{Name: "sprint", Schedule: "@weekly", Channel: "C1", Topics: []string{"Sprint"}}.ScheduledTask()
+	if task.Name != "sprint" || task.Schedule != "@weekly" || task.TaskWithResult == nil {
+		t.Errorf("ScheduledTask() = %+v, want a task named sprint scheduled @weekly", task)
+	}
+}
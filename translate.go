@@ -0,0 +1,152 @@
+package slackbot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// LanguagePreference is the name of the Preference holding a user's language. Add a Preference with
+	// this name to let users choose the language the bot's replies are translated to.
+	LanguagePreference = "language"
+
+	defaultLanguage    = "en"
+	channelLanguageKey = "language"
+	dmUserTTL          = 24 * time.Hour
+)
+
+type (
+	// Translator translates text to a language, such as "es" or "ja". It is used to translate the bot's
+	// replies, and incoming messages when TranslateIncoming is set, see Bot.Translator.
+	Translator interface {
+		Translate(text string, language string) (string, error)
+	}
+
+	// TranslatorFunc is a function that implements Translator.
+	TranslatorFunc func(text string, language string) (string, error)
+)
+
+// Translate calls the function.
+func (f TranslatorFunc) Translate(text string, language string) (string, error) {
+	return f(text, language)
+}
+
+// SetChannelLanguage sets the language replies in the channel are translated to. An empty language removes
+// the setting.
+func (bot *Bot) SetChannelLanguage(channel string, language string) error {
+	if language == "" {
+		_ = bot.ChannelState(channel).Delete(channelLanguageKey)
+		return nil
+	}
+	return bot.ChannelState(channel).Put(channelLanguageKey, language)
+}
+
+// ChannelLanguage returns the language set for the channel with SetChannelLanguage, or an empty string.
+func (bot *Bot) ChannelLanguage(channel string) string {
+	var language string
+	_ = bot.ChannelState(channel).Get(channelLanguageKey, &language)
+	return language
+}
+
+// UserLanguage returns the user's LanguagePreference, or an empty string.
+func (bot *Bot) UserLanguage(user string) string {
+	return bot.Preference(user, LanguagePreference)
+}
+
+// language returns the language the bot's listeners and messages are written in.
+func (bot *Bot) language() string {
+	if bot.Language != "" {
+		return bot.Language
+	}
+	return defaultLanguage
+}
+
+// replyLanguage returns the language to translate replies to the channel to. A direct message channel uses
+// the language of the user who last messaged the bot in it, unless the channel has its own language.
+func (bot *Bot) replyLanguage(channel string) string {
+	if language := bot.ChannelLanguage(channel); language != "" {
+		return language
+	}
+	if strings.HasPrefix(channel, directMessagePrefix) {
+		if user, ok := bot.dmUserCache().get(channel); ok {
+			return bot.UserLanguage(user.(string))
+		}
+	}
+	return ""
+}
+
+// translateReply replaces the message's text with its translation if the channel has a language
+// other than the bot's. The message is sent untranslated if the translation fails.
+func (bot *Bot) translateReply(channel string, options []slack.MsgOption) []slack.MsgOption {
+	if bot.Translator == nil {
+		return options
+	}
+	language := bot.replyLanguage(channel)
+	if language == "" || strings.EqualFold(language, bot.language()) {
+		return options
+	}
+	values, err := encodeMsgOptions(options...)
+	if err != nil || values.Get("text") == "" {
+		return options
+	}
+	translated, err := bot.Translator.Translate(values.Get("text"), language)
+	if err != nil {
+		bot.LogWarn(fmt.Sprintf("unable to translate message to %s - %s", language, err))
+		return options
+	}
+	values.Set("text", translated)
+	translatedOptions, err := decodeMsgOptions(values)
+	if err != nil {
+		return options
+	}
+	return translatedOptions
+}
+
+// translateIncoming translates the message's text to the bot's language when TranslateIncoming is set and the
+// user or channel has a different language, so it can be matched by the listeners. A mention of the bot at
+// the start of the message is kept.
+func (bot *Bot) translateIncoming(ev *slack.MessageEvent) {
+	if bot.Translator == nil || ev.User == "" || (bot.userDetails != nil && ev.User == bot.userDetails.ID) {
+		return
+	}
+	if strings.HasPrefix(ev.Channel, directMessagePrefix) {
+		bot.dmUserCache().set(ev.Channel, ev.User)
+	}
+	if !bot.TranslateIncoming || ev.Text == "" {
+		return
+	}
+	language := bot.UserLanguage(ev.User)
+	if language == "" {
+		language = bot.ChannelLanguage(ev.Channel)
+	}
+	if language == "" || strings.EqualFold(language, bot.language()) {
+		return
+	}
+	var prefix string
+	text := ev.Text
+	if bot.userDetails != nil {
+		mention := fmt.Sprintf("<@%s> ", bot.userDetails.ID)
+		if strings.HasPrefix(text, mention) {
+			prefix, text = mention, strings.TrimPrefix(text, mention)
+		}
+	}
+	translated, err := bot.Translator.Translate(text, bot.language())
+	if err != nil {
+		bot.LogWarn(fmt.Sprintf("unable to translate message from %s - %s", ev.User, err))
+		return
+	}
+	ev.Text = prefix + translated
+}
+
+// dmUserCache returns the cache of the user who last messaged the bot in each direct message channel.
+func (bot *Bot) dmUserCache() *cache {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.dmUsers == nil {
+		bot.dmUsers = newCache(dmUserTTL, bot.clock())
+	}
+	return bot.dmUsers
+}
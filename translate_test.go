@@ -0,0 +1,181 @@
+package slackbot
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+// testTranslator translates by prefixing the text with the language.
+func testTranslator() Translator {
+	return TranslatorFunc(func(text string, language string) (string, error) {
+		if text == "fail" {
+			return "", errors.New("translation failed")
+		}
+		return "[" + language + "] " + text, nil
+	})
+}
+
+func TestBot_translateReply(t *testing.T) {
+	tests := []struct {
+		name       string
+		translator Translator
+		channel    string
+		channelLng string
+		dmUser     string
+		userLng    string
+		text       string
+		want       string
+	}{
+		{
+			name:       "should translate to the channel's language",
+			translator: testTranslator(),
+			channel:    "C1",
+			channelLng: "es",
+			text:       "deploy finished",
+			want:       "[es] deploy finished",
+		},
+		{
+			name:       "should translate direct messages to the user's language",
+			translator: testTranslator(),
+			channel:    "D1",
+			dmUser:     "U1",
+			userLng:    "ja",
+			text:       "deploy finished",
+			want:       "[ja] deploy finished",
+		},
+		{
+			name:       "should not translate when the language is the bot's",
+			translator: testTranslator(),
+			channel:    "C1",
+			channelLng: "EN",
+			text:       "deploy finished",
+			want:       "deploy finished",
+		},
+		{
+			name:       "should not translate without a language",
+			translator: testTranslator(),
+			channel:    "C1",
+			text:       "deploy finished",
+			want:       "deploy finished",
+		},
+		{
+			name:       "should not translate without a translator",
+			channel:    "C1",
+			channelLng: "es",
+			text:       "deploy finished",
+			want:       "deploy finished",
+		},
+		{
+			name:       "should send the original text when translation fails",
+			translator: testTranslator(),
+			channel:    "C1",
+			channelLng: "es",
+			text:       "fail",
+			want:       "fail",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						got = msgValues(opts...).Get("text")
+						return s, "ts", nil
+					},
+				},
+				Translator:  tt.translator,
+				Preferences: []Preference{{Name: LanguagePreference}},
+			}
+			if tt.channelLng != "" {
+				_ = bot.SetChannelLanguage(tt.channel, tt.channelLng)
+			}
+			if tt.userLng != "" {
+				_ = bot.SetPreference(tt.dmUser, LanguagePreference, tt.userLng)
+			}
+			if tt.dmUser != "" {
+				bot.dmUserCache().set(tt.channel, tt.dmUser)
+			}
+			_, _, _ = bot.Reply(tt.channel, tt.text)
+			if got != tt.want {
+				t.Errorf("Reply() sent %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_translateIncoming(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming bool
+		userLng  string
+		text     string
+		want     string
+	}{
+		{
+			name:     "should translate messages from users with a language",
+			incoming: true,
+			userLng:  "es",
+			text:     "<@bot> desplegar api",
+			want:     "[en] desplegar api",
+		},
+		{
+			name:    "should not translate unless TranslateIncoming is set",
+			userLng: "es",
+			text:    "<@bot> desplegar api",
+		},
+		{
+			name:     "should not translate messages from users without a language",
+			incoming: true,
+			text:     "<@bot> deploy api",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						return s, "ts", nil
+					},
+				},
+				DirectListeners: []Listener{
+					{
+						Regex: regexp.MustCompile(`.*`),
+						Handler: func(bot *Bot, ev *slack.MessageEvent) {
+							got = ev.Text
+						},
+					},
+				},
+				Translator:        testTranslator(),
+				TranslateIncoming: tt.incoming,
+				Preferences:       []Preference{{Name: LanguagePreference}},
+				userDetails:       &slack.UserDetails{ID: "bot"},
+			}
+			if tt.userLng != "" {
+				_ = bot.SetPreference("U1", LanguagePreference, tt.userLng)
+			}
+			bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "C1", User: "U1", Text: tt.text, Timestamp: "1.1"}})
+			want := tt.want
+			if want == "" {
+				want = tt.text[len("<@bot> "):]
+			}
+			if got != want {
+				t.Errorf("listener got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestBot_SetChannelLanguage(t *testing.T) {
+	bot := &Bot{}
+	if err := bot.SetChannelLanguage("C1", "fr"); err != nil || bot.ChannelLanguage("C1") != "fr" {
+		t.Errorf("ChannelLanguage() = %v, %v, want fr", bot.ChannelLanguage("C1"), err)
+	}
+	if err := bot.SetChannelLanguage("C1", ""); err != nil || bot.ChannelLanguage("C1") != "" {
+		t.Errorf("ChannelLanguage() = %v, %v, want it removed", bot.ChannelLanguage("C1"), err)
+	}
+}
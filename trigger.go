@@ -0,0 +1,102 @@
+package slackbot
+
+import (
+	"strings"
+	"unicode"
+)
+
+// triggerIndex maps the TriggerWords of indirect listeners to the listeners' positions, so a message's words
+// decide which listeners are tried instead of running every listener's regular expressions.
+type triggerIndex struct {
+	size  int
+	words map[string][]int
+
+	// triggerWords are copies of each listener's TriggerWords, to tell when the listeners have changed.
+	triggerWords [][]string
+
+	// always are the listeners without TriggerWords, which are tried for every message.
+	always []int
+}
+
+// newTriggerIndex indexes the listeners by their lower case TriggerWords.
+func newTriggerIndex(listeners []Listener) *triggerIndex {
+	idx := &triggerIndex{size: len(listeners), words: make(map[string][]int), triggerWords: make([][]string, len(listeners))}
+	for i, l := range listeners {
+		idx.triggerWords[i] = append([]string(nil), l.TriggerWords...)
+		if len(l.TriggerWords) == 0 {
+			idx.always = append(idx.always, i)
+			continue
+		}
+		for _, w := range l.TriggerWords {
+			for _, token := range triggerTokens(w) {
+				idx.words[token] = append(idx.words[token], i)
+			}
+		}
+	}
+	return idx
+}
+
+// current returns true if the index was built from listeners with the same TriggerWords, in the same order.
+func (idx *triggerIndex) current(listeners []Listener) bool {
+	if idx.size != len(listeners) {
+		return false
+	}
+	for i, l := range listeners {
+		if len(l.TriggerWords) != len(idx.triggerWords[i]) {
+			return false
+		}
+		for j, w := range l.TriggerWords {
+			if w != idx.triggerWords[i][j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// candidates returns which of the listeners should be tried for the text, those without TriggerWords and
+// those with one of the text's words.
+func (idx *triggerIndex) candidates(text string) []bool {
+	c := make([]bool, idx.size)
+	for _, i := range idx.always {
+		c[i] = true
+	}
+	if len(idx.words) == 0 {
+		return c
+	}
+	for _, token := range triggerTokens(text) {
+		for _, i := range idx.words[token] {
+			c[i] = true
+		}
+	}
+	return c
+}
+
+// triggerTokens splits the text into lower case words of letters, digits, hyphens and underscores, so
+// "On-call: deploy k8s!" has the words on-call, deploy and k8s.
+func triggerTokens(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_'
+	})
+	tokens := words[:0]
+	for _, w := range words {
+		if w = strings.Trim(w, "-_"); w != "" {
+			tokens = append(tokens, w)
+		}
+	}
+	return tokens
+}
+
+// indirectCandidates returns which of the bot's IndirectListeners should be tried for the text. The index is
+// built the first time, and again whenever the listeners or their TriggerWords have changed, including when
+// listeners are replaced by others with the same count.
+func (bot *Bot) indirectCandidates(text string) []bool {
+	bot.mu.Lock()
+	idx := bot.triggers
+	if idx == nil || !idx.current(bot.IndirectListeners) {
+		idx = newTriggerIndex(bot.IndirectListeners)
+		bot.triggers = idx
+	}
+	bot.mu.Unlock()
+	return idx.candidates(text)
+}
@@ -0,0 +1,142 @@
+package slackbot
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func Test_triggerTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "should split the text into lower case words",
+			text: "Deploy the API",
+			want: []string{"deploy", "the", "api"},
+		},
+		{
+			name: "should split words at punctuation",
+			text: "deploy, now! (please)",
+			want: []string{"deploy", "now", "please"},
+		},
+		{
+			name: "should keep hyphens, underscores and digits in words",
+			text: "on-call for k8s_prod",
+			want: []string{"on-call", "for", "k8s_prod"},
+		},
+		{
+			name: "should trim hyphens and underscores from the ends of words",
+			text: "-- deploy --",
+			want: []string{"deploy"},
+		},
+		{
+			name: "should not have words in an empty text",
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := triggerTokens(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("triggerTokens() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_triggerIndex_candidates(t *testing.T) {
+	idx := newTriggerIndex([]Listener{
+		{TriggerWords: []string{"Deploy", "release"}},
+		{},
+		{TriggerWords: []string{"incident"}},
+	})
+	tests := []struct {
+		name string
+		text string
+		want []bool
+	}{
+		{
+			name: "should only try listeners without trigger words",
+			text: "lunch anyone?",
+			want: []bool{false, true, false},
+		},
+		{
+			name: "should try listeners with one of the words ignoring case",
+			text: "RELEASE the api",
+			want: []bool{true, true, false},
+		},
+		{
+			name: "should not try listeners for words that contain a trigger word",
+			text: "redeploy the api",
+			want: []bool{false, true, false},
+		},
+		{
+			name: "should try every listener with one of the words",
+			text: "deploy during the incident",
+			want: []bool{true, true, true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := idx.candidates(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("candidates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_processMessage_triggerWords(t *testing.T) {
+	var ran []string
+	listener := func(name string, re string, words ...string) Listener {
+		return Listener{
+			Regex:        regexp.MustCompile(re),
+			TriggerWords: words,
+			Handler:      func(*Bot, *slack.MessageEvent) { ran = append(ran, name) },
+		}
+	}
+	bot := &Bot{
+		API:               &mockAPI{},
+		IndirectListeners: []Listener{listener("deploy", `deploy (\w+)`, "Deploy")},
+		userDetails:       &slack.UserDetails{ID: "bot"},
+	}
+	bot.init()
+	process := func(text string) {
+		bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{Channel: "C1", User: "U1", Text: text, Timestamp: "1.1"}})
+	}
+
+	process("time to deploy api")
+	process("redeploy api")
+	bot.IndirectListeners = append(bot.IndirectListeners, listener("incident", `incident`, "incident"))
+	process("an incident")
+	bot.IndirectListeners = []Listener{listener("rollback", `rollback`, "rollback"), listener("incident", `incident`, "incident")}
+	process("rollback api")
+	bot.IndirectListeners[0].TriggerWords = []string{"revert"}
+	process("revert rollback")
+
+	if want := []string{"deploy", "incident", "rollback", "rollback"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("ran = %v, want %v", ran, want)
+	}
+}
+
+func BenchmarkBot_processMessage_triggerWords(b *testing.B) {
+	listeners := benchmarkListeners(50, benchmarkFormats["unanchored"])
+	for i := range listeners {
+		listeners[i].TriggerWords = []string{fmt.Sprintf("command%d", i)}
+	}
+	bot := &Bot{API: &mockAPI{}, IndirectListeners: listeners, userDetails: &slack.UserDetails{ID: "bot"}}
+	bot.init()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bot.processMessage(&slack.MessageEvent{Msg: slack.Msg{
+			Channel:   "C1",
+			User:      "U1",
+			Text:      benchmarkTexts[i%len(benchmarkTexts)],
+			Timestamp: "1.1",
+		}})
+	}
+}
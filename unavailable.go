@@ -0,0 +1,127 @@
+package slackbot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	unavailableChannelTTL = time.Hour
+	channelUnavailableDM  = "I couldn't reply in <#%s> because it was archived or deleted, so I'm replying here instead."
+	channelArchiveType    = "channel_archive"
+	channelUnarchiveType  = "channel_unarchive"
+	channelDeletedType    = "channel_deleted"
+	groupArchiveType      = "group_archive"
+	groupUnarchiveType    = "group_unarchive"
+)
+
+// ErrChannelUnavailable is returned instead of sending a message to a channel that slack said was archived or
+// deleted. Sends to the channel are skipped for an hour, or until the channel is unarchived.
+var ErrChannelUnavailable = errors.New("the channel is archived or deleted")
+
+// channelUnavailableErrors are the errors slack responds with when a channel has been archived, sending to it
+// will keep failing so they aren't retried. channel_not_found isn't one of them, slack also responds with it
+// for private channels the bot isn't a member of, so deleted channels are only found from their events.
+var channelUnavailableErrors = map[string]bool{
+	"is_archived": true,
+}
+
+// channelAvailability is the events API event sent when a channel is archived, unarchived or deleted.
+type channelAvailability struct {
+	Channel string `json:"channel"`
+}
+
+// isChannelUnavailable returns true if the error is slack saying the channel is archived.
+func isChannelUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	err = errors.Cause(err)
+	return err == ErrChannelUnavailable || channelUnavailableErrors[err.Error()]
+}
+
+// checkChannelUnavailable returns true if sending to the channel failed because it is archived,
+// and marks the channel as unavailable so messages to it aren't sent until it is available again.
+func (bot *Bot) checkChannelUnavailable(channel string, err error) bool {
+	if !isChannelUnavailable(err) {
+		return false
+	}
+	if errors.Cause(err) != ErrChannelUnavailable {
+		bot.markChannelUnavailable(channel)
+	}
+	return true
+}
+
+// markChannelUnavailable skips sending to the channel for an hour, and removes it from the bot's caches so
+// it is looked up again if it comes back.
+func (bot *Bot) markChannelUnavailable(channel string) {
+	bot.goneChannelCache().set(channel, true)
+	bot.sharedChannelCache().delete(channel)
+}
+
+// markChannelAvailable sends messages to the channel again, when it is unarchived.
+func (bot *Bot) markChannelAvailable(channel string) {
+	bot.goneChannelCache().delete(channel)
+	bot.sharedChannelCache().delete(channel)
+}
+
+// handleChannelAvailability marks the channel in an events API archive, unarchive or deleted event as
+// unavailable or available again. slackevents doesn't have types for these events, so they are read from the
+// inner event by their type.
+func (bot *Bot) handleChannelAvailability(eventType string, data json.RawMessage) {
+	ev := &channelAvailability{}
+	if err := json.Unmarshal(data, ev); err != nil {
+		bot.LogError(fmt.Sprintf("unable to read %s event - %s", eventType, err))
+		return
+	}
+	switch eventType {
+	case channelUnarchiveType, groupUnarchiveType:
+		bot.markChannelAvailable(ev.Channel)
+	default:
+		bot.markChannelUnavailable(ev.Channel)
+	}
+}
+
+// channelUnavailable returns true if the channel was recently found to be archived or deleted.
+func (bot *Bot) channelUnavailable(channel string) bool {
+	_, ok := bot.goneChannelCache().get(channel)
+	return ok
+}
+
+func (bot *Bot) goneChannelCache() *cache {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.goneChannels == nil {
+		bot.goneChannels = newCache(unavailableChannelTTL, bot.clock())
+	}
+	return bot.goneChannels
+}
+
+// replyInstead sends the message that couldn't be sent to the unavailable channel to the user who triggered
+// the listener as a direct message, if the bot has FallbackToDM set and the reply was sent through the
+// listener's MessageContext. Otherwise err is returned.
+func (bot *Bot) replyInstead(ctx context.Context, priority Priority, channel string, options []slack.MsgOption, err error) (string, string, error) {
+	mc, ok := ctx.(*MessageContext)
+	if !bot.FallbackToDM || !ok || mc.Event == nil || mc.Event.User == "" || mc.Event.User == channel {
+		return channel, "", err
+	}
+	values, e := encodeMsgOptions(options...)
+	if e != nil {
+		return channel, "", err
+	}
+	values.Del("thread_ts")
+	values.Del("reply_broadcast")
+	note := fmt.Sprintf(channelUnavailableDM, channel)
+	values.Set("text", strings.TrimSpace(note+"\n"+values.Get("text")))
+	options, e = decodeMsgOptions(values)
+	if e != nil {
+		return channel, "", err
+	}
+	return bot.postReply(ctx, priority, mc.Event.User, options)
+}
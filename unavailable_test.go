@@ -0,0 +1,202 @@
+package slackbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+func Test_isChannelUnavailable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "should be unavailable when the channel is archived",
+			err:  errors.New("is_archived"),
+			want: true,
+		},
+		{
+			name: "should be unavailable when the wrapped error is archived",
+			err:  errors.Wrap(errors.New("is_archived"), "unable to send"),
+			want: true,
+		},
+		{
+			name: "should be available when the channel isn't found, it may be private",
+			err:  errors.New("channel_not_found"),
+		},
+		{
+			name: "should be unavailable when sends to it are skipped",
+			err:  ErrChannelUnavailable,
+			want: true,
+		},
+		{
+			name: "should be available after other errors",
+			err:  errors.New("not_in_channel"),
+		},
+		{
+			name: "should be available without an error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isChannelUnavailable(tt.err); got != tt.want {
+				t.Errorf("isChannelUnavailable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_postReply_unavailableChannel(t *testing.T) {
+	tests := []struct {
+		name            string
+		fallbackToDM    bool
+		viaContext      bool
+		wantSent        []string
+		wantErr         error
+		wantDM          bool
+		wantDeadLetters int
+	}{
+		{
+			name:            "should not send to the channel again",
+			wantSent:        []string{"C1"},
+			wantErr:         ErrChannelUnavailable,
+			wantDeadLetters: 1,
+		},
+		{
+			name:         "should DM the user who triggered the listener instead of saving a dead letter",
+			fallbackToDM: true,
+			viaContext:   true,
+			wantSent:     []string{"C1", "U1", "U1"},
+			wantDM:       true,
+		},
+		{
+			name:            "should not DM the user without FallbackToDM",
+			viaContext:      true,
+			wantSent:        []string{"C1"},
+			wantErr:         ErrChannelUnavailable,
+			wantDeadLetters: 1,
+		},
+		{
+			name:            "should not DM replies that weren't sent through the MessageContext",
+			fallbackToDM:    true,
+			wantSent:        []string{"C1"},
+			wantErr:         ErrChannelUnavailable,
+			wantDeadLetters: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []string
+			var dm url.Values
+			bot := &Bot{
+				API: &mockAPI{postMessage: func(channel string, opts ...slack.MsgOption) (string, string, error) {
+					sent = append(sent, channel)
+					if channel == "C1" {
+						return "", "", errors.New("is_archived")
+					}
+					dm = msgValues(opts...)
+					return "D1", "1.2", nil
+				}},
+				FallbackToDM: tt.fallbackToDM,
+				Store:        NewMemoryStore(nil),
+			}
+			reply := func() (string, string, error) { return bot.Reply("C1", "deployed") }
+			if tt.viaContext {
+				ctx := &MessageContext{
+					Context: context.Background(),
+					Bot:     bot,
+					Event:   &slack.MessageEvent{Msg: slack.Msg{Channel: "C1", User: "U1", Timestamp: "1.1"}},
+				}
+				reply = func() (string, string, error) { return ctx.Reply("deployed") }
+			}
+
+			_, _, _ = reply()
+			_, _, err := reply()
+			if errors.Cause(err) != tt.wantErr {
+				t.Errorf("Reply() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(sent, tt.wantSent) {
+				t.Errorf("sent = %q, want %q", sent, tt.wantSent)
+			}
+			if (dm != nil) != tt.wantDM {
+				t.Fatalf("DM = %v, want %v", dm, tt.wantDM)
+			}
+			if dm != nil && (dm.Get("thread_ts") != "" || !strings.HasSuffix(dm.Get("text"), "replying here instead.\ndeployed")) {
+				t.Errorf("DM = %v, want the reply outside of a thread with a note", dm)
+			}
+			if letters, _ := bot.DeadLetters(); len(letters) != tt.wantDeadLetters {
+				t.Errorf("DeadLetters() = %v, want %d", letters, tt.wantDeadLetters)
+			}
+		})
+	}
+}
+
+func TestBot_markChannelAvailable(t *testing.T) {
+	calls := 0
+	bot := &Bot{API: &mockAPI{postMessage: func(channel string, opts ...slack.MsgOption) (string, string, error) {
+		calls++
+		return channel, "1.1", nil
+	}}}
+	bot.markChannelUnavailable("C1")
+	if _, _, err := bot.Reply("C1", "hello"); err != ErrChannelUnavailable {
+		t.Fatalf("Reply() error = %v, want %v", err, ErrChannelUnavailable)
+	}
+	bot.markChannelAvailable("C1")
+	if _, _, err := bot.Reply("C1", "hello"); err != nil || calls != 1 {
+		t.Errorf("Reply() error = %v with %d calls, want the message sent", err, calls)
+	}
+}
+
+func TestBot_HandleEventsAPIEvent_channelAvailability(t *testing.T) {
+	tests := []struct {
+		name            string
+		events          []string
+		wantUnavailable bool
+	}{
+		{
+			name:            "should mark an archived channel unavailable",
+			events:          []string{eventJSON("channel_archive", `"channel":"C1","user":"U1"`)},
+			wantUnavailable: true,
+		},
+		{
+			name:            "should mark a deleted channel unavailable",
+			events:          []string{eventJSON("channel_deleted", `"channel":"C1"`)},
+			wantUnavailable: true,
+		},
+		{
+			name: "should mark an unarchived private channel available",
+			events: []string{
+				eventJSON("group_archive", `"channel":"C1","user":"U1"`),
+				eventJSON("group_unarchive", `"channel":"C1","user":"U1"`),
+			},
+		},
+		{
+			name:   "should leave other channels available",
+			events: []string{eventJSON("channel_deleted", `"channel":"C2"`)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{API: &mockAPI{}, userDetails: &slack.UserDetails{ID: "bot"}}
+			for _, e := range tt.events {
+				ev, err := slackevents.ParseEvent(json.RawMessage(e), slackevents.OptionNoVerifyToken())
+				if err != nil {
+					t.Fatalf("ParseEvent() error = %v", err)
+				}
+				bot.HandleEventsAPIEvent(ev)
+			}
+			if got := bot.channelUnavailable("C1"); got != tt.wantUnavailable {
+				t.Errorf("channelUnavailable() = %v, want %v", got, tt.wantUnavailable)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package slackbot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+type (
+	// UnfurlHandler builds the preview shown for a link shared in slack. It can return nil to leave the link
+	// without a preview. Blocks can be set on the returned attachment for a Block Kit preview.
+	UnfurlHandler func(bot *Bot, link SharedLink) (*slack.Attachment, error)
+
+	// SharedLink is a link to one of the bot's registered domains that was shared in a message.
+	SharedLink struct {
+		URL              string
+		Domain           string
+		Channel          string
+		User             string
+		MessageTimestamp string
+	}
+)
+
+// OnLinkShared registers a handler that unfurls links to the domain, or any of its subdomains, when they are
+// shared in slack. The domain must also be added to the slack app's unfurl domains, and the link_shared event
+// is only received through the Events API. Registering a domain again replaces its handler.
+func (bot *Bot) OnLinkShared(domain string, handler UnfurlHandler) {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.unfurlers == nil {
+		bot.unfurlers = make(map[string]UnfurlHandler)
+	}
+	bot.unfurlers[strings.ToLower(domain)] = handler
+}
+
+// handleLinkShared calls the handlers for the shared links and sends the previews to slack with chat.unfurl.
+func (bot *Bot) handleLinkShared(ev *slackevents.LinkSharedEvent) {
+	unfurls := make(map[string]slack.Attachment)
+	for _, l := range ev.Links {
+		handler := bot.unfurler(l.Domain)
+		if handler == nil {
+			continue
+		}
+		link := SharedLink{
+			URL:              l.URL,
+			Domain:           l.Domain,
+			Channel:          ev.Channel,
+			User:             ev.User,
+			MessageTimestamp: ev.MessageTimeStamp.String(),
+		}
+		attachment, err := bot.runUnfurlHandler(handler, link)
+		if err != nil {
+			bot.LogError(fmt.Sprintf("error unfurling %s - %s", l.URL, err))
+			continue
+		}
+		if attachment != nil {
+			unfurls[l.URL] = *attachment
+		}
+	}
+	if len(unfurls) == 0 {
+		return
+	}
+	err := bot.withRetry(func() error {
+		_, _, _, err := bot.api().UnfurlMessage(ev.Channel, ev.MessageTimeStamp.String(), unfurls)
+		return err
+	})
+	if err != nil {
+		bot.LogError(fmt.Sprintf("error unfurling links in %s - %s", ev.Channel, err))
+	}
+}
+
+func (bot *Bot) runUnfurlHandler(handler UnfurlHandler, link SharedLink) (attachment *slack.Attachment, err error) {
+	defer bot.recoverPanic(ErrorInfo{Source: ErrorSourceUnfurl, Channel: link.Channel, User: link.User}, nil)
+	return handler(bot, link)
+}
+
+// unfurler returns the handler registered for the domain or the closest parent domain.
+func (bot *Bot) unfurler(domain string) UnfurlHandler {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	domain = strings.ToLower(domain)
+	for domain != "" {
+		if h, ok := bot.unfurlers[domain]; ok {
+			return h
+		}
+		i := strings.Index(domain, ".")
+		if i < 0 {
+			break
+		}
+		domain = domain[i+1:]
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+func TestBot_OnLinkShared(t *testing.T) {
+	tests := []struct {
+		name        string
+		links       string
+		wantUnfurls map[string]string
+	}{
+		{
+			name:        "should unfurl links to the domain",
+			links:       `{"domain":"jira.example.com","url":"https://jira.example.com/browse/OPS-1"}`,
+			wantUnfurls: map[string]string{"https://jira.example.com/browse/OPS-1": "OPS-1"},
+		},
+		{
+			name:        "should unfurl links to subdomains",
+			links:       `{"domain":"eu.jira.example.com","url":"https://eu.jira.example.com/browse/OPS-2"}`,
+			wantUnfurls: map[string]string{"https://eu.jira.example.com/browse/OPS-2": "OPS-2"},
+		},
+		{
+			name: "should only unfurl links with a preview",
+			links: `{"domain":"jira.example.com","url":"https://jira.example.com/browse/OPS-1"},` +
+				`{"domain":"jira.example.com","url":"https://jira.example.com/dashboard"},` +
+				`{"domain":"jira.example.com","url":"https://jira.example.com/error"},` +
+				`{"domain":"other.com","url":"https://other.com/page"}`,
+			wantUnfurls: map[string]string{"https://jira.example.com/browse/OPS-1": "OPS-1"},
+		},
+		{
+			name:  "should not call chat.unfurl without previews",
+			links: `{"domain":"other.com","url":"https://other.com/page"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUnfurls map[string]string
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						return s, "ts", nil
+					},
+					unfurlMessage: func(ch string, ts string, unfurls map[string]slack.Attachment, opts ...slack.MsgOption) (string, string, string, error) {
+						if ch != "C1" || ts != "1.1" {
+							t.Errorf("UnfurlMessage() called with %s, %s", ch, ts)
+						}
+						gotUnfurls = make(map[string]string)
+						for link, a := range unfurls {
+							gotUnfurls[link] = a.Title
+						}
+						return ch, ts, "", nil
+					},
+				},
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			bot.OnLinkShared("jira.example.com", func(bot *Bot, link SharedLink) (*slack.Attachment, error) {
+				switch link.URL[len(link.URL)-5:] {
+				case "error":
+					return nil, errors.New("not found")
+				case "board":
+					return nil, nil
+				}
+				return &slack.Attachment{Title: link.URL[len(link.URL)-5:]}, nil
+			})
+			e := eventJSON("link_shared", `"channel":"C1","user":"U1","message_ts":"1.1","links":[`+tt.links+`]`)
+			ev, err := slackevents.ParseEvent(json.RawMessage(e), slackevents.OptionNoVerifyToken())
+			if err != nil {
+				t.Fatalf("ParseEvent() error = %v", err)
+			}
+			bot.HandleEventsAPIEvent(ev)
+			if !reflect.DeepEqual(gotUnfurls, tt.wantUnfurls) {
+				t.Errorf("UnfurlMessage() unfurls = %v, want %v", gotUnfurls, tt.wantUnfurls)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package slackbot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	userGroupCacheTTL = 5 * time.Minute
+	userGroupsKey     = "usergroups"
+)
+
+// UserGroup finds a usergroup by ID, handle, with or without the @, or name. Usergroups and their members
+// are cached for a few minutes so repeated lookups will not hit the slack api.
+func (bot *Bot) UserGroup(identifier string) (slack.UserGroup, error) {
+	groups, err := bot.userGroups()
+	if err != nil {
+		return slack.UserGroup{}, err
+	}
+	i := strings.TrimPrefix(identifier, userPrefix)
+	if id, ok := userGroupMention(identifier); ok {
+		i = id
+	}
+	for _, g := range groups {
+		if g.ID == i || g.Handle == i || g.Name == i {
+			return g, nil
+		}
+	}
+	return slack.UserGroup{}, errors.Errorf("unable to find usergroup with identifier %s", identifier)
+}
+
+// UsersInGroup returns the IDs of the users in the usergroup, which can be identified by ID, handle or name.
+func (bot *Bot) UsersInGroup(identifier string) ([]string, error) {
+	g, err := bot.UserGroup(identifier)
+	if err != nil {
+		return nil, err
+	}
+	return g.Users, nil
+}
+
+// NotifyGroup sends a message mentioning the usergroup, so all of its members are notified, to each of the
+// group's default channels. If the group has no default channels each member is sent the message directly.
+func (bot *Bot) NotifyGroup(group string, text string) error {
+	g, err := bot.UserGroup(group)
+	if err != nil {
+		return err
+	}
+	channels := g.Prefs.Channels
+	if len(channels) == 0 {
+		channels = g.Users
+	}
+	if len(channels) == 0 {
+		return errors.Errorf("usergroup %s has no channels or members to notify", group)
+	}
+	msg := fmt.Sprintf("<!subteam^%s> %s", g.ID, text)
+	for _, c := range channels {
+		if _, _, err := bot.Reply(c, msg); err != nil {
+			return errors.Wrapf(err, "unable to notify usergroup %s", group)
+		}
+	}
+	return nil
+}
+
+// userGroups returns the team's usergroups with their members from the cache, or from slack if they
+// have expired.
+func (bot *Bot) userGroups() ([]slack.UserGroup, error) {
+	c := bot.userGroupCache()
+	if groups, ok := c.get(userGroupsKey); ok {
+		return groups.([]slack.UserGroup), nil
+	}
+	var groups []slack.UserGroup
+	err := bot.withRetry(func() (err error) {
+		groups, err = bot.api().GetUserGroupsContext(bot.context(), slack.GetUserGroupsOptionIncludeUsers(true))
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get usergroups")
+	}
+	c.set(userGroupsKey, groups)
+	return groups, nil
+}
+
+func (bot *Bot) userGroupCache() *cache {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.groups == nil {
+		bot.groups = newCache(userGroupCacheTTL, bot.clock())
+	}
+	return bot.groups
+}
+
+// userGroupMention returns the ID from a usergroup mention such as <!subteam^S123|@oncall>.
+func userGroupMention(text string) (string, bool) {
+	if !strings.HasPrefix(text, "<!subteam^") || !strings.HasSuffix(text, ">") {
+		return "", false
+	}
+	id := strings.TrimSuffix(strings.TrimPrefix(text, "<!subteam^"), ">")
+	return strings.SplitN(id, "|", 2)[0], true
+}
@@ -0,0 +1,146 @@
+package slackbot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func testUserGroups() []slack.UserGroup {
+	oncall := slack.UserGroup{ID: "S1", Handle: "oncall", Name: "On Call", Users: []string{"U1", "U2"}}
+	oncall.Prefs.Channels = []string{"C1"}
+	return []slack.UserGroup{
+		oncall,
+		{ID: "S2", Handle: "platform", Name: "Platform", Users: []string{"U3"}},
+		{ID: "S3", Handle: "empty", Name: "Empty"},
+	}
+}
+
+func TestBot_UsersInGroup(t *testing.T) {
+	tests := []struct {
+		name       string
+		identifier string
+		err        error
+		want       []string
+		wantErr    bool
+	}{
+		{
+			name:       "should find a group by ID",
+			identifier: "S1",
+			want:       []string{"U1", "U2"},
+		},
+		{
+			name:       "should find a group by handle",
+			identifier: "@platform",
+			want:       []string{"U3"},
+		},
+		{
+			name:       "should find a group by name",
+			identifier: "On Call",
+			want:       []string{"U1", "U2"},
+		},
+		{
+			name:       "should find a group from a mention",
+			identifier: "<!subteam^S2|@platform>",
+			want:       []string{"U3"},
+		},
+		{
+			name:       "should return an error if the group is not found",
+			identifier: "@nobody",
+			wantErr:    true,
+		},
+		{
+			name:       "should return an error if the groups can't be loaded",
+			identifier: "S1",
+			err:        errors.New("missing_scope"),
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			bot := &Bot{
+				API: &mockAPI{
+					getUserGroups: func(opts ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+						calls++
+						return testUserGroups(), tt.err
+					},
+				},
+			}
+			var got []string
+			var err error
+			for i := 0; i < 2; i++ {
+				got, err = bot.UsersInGroup(tt.identifier)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UsersInGroup() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UsersInGroup() got = %v, want %v", got, tt.want)
+			}
+			if tt.err == nil && calls != 1 {
+				t.Errorf("UsersInGroup() loaded the groups %d times, want 1", calls)
+			}
+		})
+	}
+}
+
+func TestBot_NotifyGroup(t *testing.T) {
+	tests := []struct {
+		name         string
+		group        string
+		wantChannels []string
+		wantText     string
+		wantErr      bool
+	}{
+		{
+			name:         "should mention the group in its default channels",
+			group:        "@oncall",
+			wantChannels: []string{"C1"},
+			wantText:     "<!subteam^S1> the build is broken",
+		},
+		{
+			name:         "should message each member if the group has no default channels",
+			group:        "platform",
+			wantChannels: []string{"U3"},
+			wantText:     "<!subteam^S2> the build is broken",
+		},
+		{
+			name:    "should return an error if there is no one to notify",
+			group:   "empty",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var channels []string
+			var text string
+			bot := &Bot{
+				API: &mockAPI{
+					getUserGroups: func(opts ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+						return testUserGroups(), nil
+					},
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						channels = append(channels, s)
+						text = msgValues(opts...).Get("text")
+						return s, "ts", nil
+					},
+				},
+			}
+			err := bot.NotifyGroup(tt.group, "the build is broken")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NotifyGroup() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(channels, tt.wantChannels) {
+				t.Errorf("NotifyGroup() sent to %v, want %v", channels, tt.wantChannels)
+			}
+			if text != tt.wantText {
+				t.Errorf("NotifyGroup() text = %q, want %q", text, tt.wantText)
+			}
+		})
+	}
+}
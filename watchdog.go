@@ -0,0 +1,200 @@
+package slackbot
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	defaultWatchdogInterval   = time.Minute
+	defaultWatchdogMaxSilence = 2 * time.Minute
+	defaultWatchdogQueueDepth = 100
+	watchdogAPITimeout        = 10 * time.Second
+	watchdogFailedMessage     = "watchdog: the %s check failed - %s"
+	watchdogRecoveredMessage  = "watchdog: the %s check recovered"
+
+	watchdogCheckWebsocket = "websocket"
+	watchdogCheckAPI       = "slack api"
+	watchdogCheckScheduler = "scheduler"
+	watchdogCheckSendQueue = "send queue"
+	watchdogCheckWorkers   = "workers"
+)
+
+type (
+	// Watchdog checks the bot's health every Interval, one minute by default. It checks that events are still
+	// arriving over the RTM websocket, that the slack api can be reached with auth.test, that the scheduler is
+	// still running scheduled tasks, and that the SendQueue and the worker pool of a BotGroup aren't backed up.
+	// When a check starts failing it is logged to the ErrorChannel and reported to the ErrorReporter, and
+	// logged again once it recovers.
+	Watchdog struct {
+		Interval time.Duration
+
+		// MaxSilence is how long the RTM websocket can go without an event before it is considered dead, two
+		// minutes by default. Slack sends a latency report every thirty seconds on a healthy connection.
+		MaxSilence time.Duration
+
+		// MaxQueueDepth is how many messages can be due in the SendQueue before it is considered backed up,
+		// 100 by default. Messages held for QuietHours or waiting to be retried aren't counted.
+		MaxQueueDepth int
+
+		mu        sync.Mutex
+		started   time.Time
+		lastEvent time.Time
+		heartbeat time.Time
+		failing   map[string]bool
+	}
+
+	watchdogResult struct {
+		check string
+		err   error
+	}
+)
+
+// start runs the checks every Interval until the bot is stopped.
+func (w *Watchdog) start(bot *Bot) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.started.IsZero() {
+		return
+	}
+	w.started = bot.clock().Now()
+	go w.run(bot)
+}
+
+func (w *Watchdog) run(bot *Bot) {
+	stop := bot.stopChan()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-bot.clock().After(w.interval()):
+			w.report(bot, w.check(bot))
+		}
+	}
+}
+
+// scheduleHeartbeat schedules a job that shows the scheduler is still running scheduled tasks.
+func (w *Watchdog) scheduleHeartbeat(bot *Bot, s *scheduler) {
+	w.beat(bot.clock().Now())
+	s.Schedule(cron.Every(w.interval()), cron.FuncJob(func() {
+		w.beat(bot.clock().Now())
+	}))
+}
+
+func (w *Watchdog) beat(t time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.heartbeat = t
+}
+
+// noteEvent records that an event was received over the RTM websocket.
+func (w *Watchdog) noteEvent(t time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastEvent = t
+}
+
+// check runs each of the checks that apply to the bot.
+func (w *Watchdog) check(bot *Bot) []watchdogResult {
+	now := bot.clock().Now()
+	bot.mu.Lock()
+	rtm, s, workers := bot.rtmConnected, bot.scheduler, bot.workers
+	bot.mu.Unlock()
+	w.mu.Lock()
+	lastEvent, heartbeat := w.lastEvent, w.heartbeat
+	if lastEvent.IsZero() {
+		lastEvent = w.started
+	}
+	w.mu.Unlock()
+
+	var results []watchdogResult
+	if rtm {
+		var err error
+		if silence := now.Sub(lastEvent); silence > w.maxSilence() {
+			err = errors.Errorf("no events have been received for %s", silence.Round(time.Second))
+		}
+		results = append(results, watchdogResult{check: watchdogCheckWebsocket, err: err})
+	}
+
+	ctx, cancel := withClockTimeout(bot.context(), bot.clock(), watchdogAPITimeout)
+	_, err := bot.api().AuthTestContext(ctx)
+	cancel()
+	results = append(results, watchdogResult{check: watchdogCheckAPI, err: errors.Wrap(err, "auth.test failed")})
+
+	if s != nil && !heartbeat.IsZero() {
+		var err error
+		if since := now.Sub(heartbeat); since > 2*w.interval() {
+			err = errors.Errorf("the scheduler hasn't run for %s", since.Round(time.Second))
+		}
+		results = append(results, watchdogResult{check: watchdogCheckScheduler, err: err})
+	}
+
+	if bot.SendQueue != nil {
+		var err error
+		if depth := bot.SendQueue.due(bot); depth > w.maxQueueDepth() {
+			err = errors.Errorf("%d messages are waiting to be sent", depth)
+		}
+		results = append(results, watchdogResult{check: watchdogCheckSendQueue, err: err})
+	}
+
+	if workers != nil {
+		var err error
+		if busy := len(workers); busy == cap(workers) {
+			err = errors.Errorf("all %d workers are busy", busy)
+		}
+		results = append(results, watchdogResult{check: watchdogCheckWorkers, err: err})
+	}
+	return results
+}
+
+// report logs and reports the checks that have started failing, and logs those that have recovered.
+func (w *Watchdog) report(bot *Bot, results []watchdogResult) {
+	w.mu.Lock()
+	if w.failing == nil {
+		w.failing = make(map[string]bool)
+	}
+	var failed, recovered []watchdogResult
+	for _, r := range results {
+		switch {
+		case r.err != nil && !w.failing[r.check]:
+			failed = append(failed, r)
+		case r.err == nil && w.failing[r.check]:
+			recovered = append(recovered, r)
+		}
+		w.failing[r.check] = r.err != nil
+	}
+	w.mu.Unlock()
+
+	for _, r := range failed {
+		bot.LogError(fmt.Sprintf(watchdogFailedMessage, r.check, r.err))
+		bot.reportError(errors.Wrapf(r.err, "watchdog %s check", r.check), ErrorInfo{Source: ErrorSourceWatchdog})
+	}
+	for _, r := range recovered {
+		bot.LogInfo(fmt.Sprintf(watchdogRecoveredMessage, r.check))
+	}
+}
+
+func (w *Watchdog) interval() time.Duration {
+	if w.Interval > 0 {
+		return w.Interval
+	}
+	return defaultWatchdogInterval
+}
+
+func (w *Watchdog) maxSilence() time.Duration {
+	if w.MaxSilence > 0 {
+		return w.MaxSilence
+	}
+	return defaultWatchdogMaxSilence
+}
+
+func (w *Watchdog) maxQueueDepth() int {
+	if w.MaxQueueDepth > 0 {
+		return w.MaxQueueDepth
+	}
+	return defaultWatchdogQueueDepth
+}
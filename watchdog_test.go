@@ -0,0 +1,135 @@
+package slackbot
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestWatchdog_check(t *testing.T) {
+	now := time.Date(2021, 3, 5, 12, 0, 0, 0, time.UTC)
+	authOK := func() (*slack.AuthTestResponse, error) { return &slack.AuthTestResponse{}, nil }
+	tests := []struct {
+		name     string
+		bot      func() *Bot
+		watchdog *Watchdog
+		want     map[string]bool
+	}{
+		{
+			name:     "should only check the api of a healthy Events API bot",
+			bot:      func() *Bot { return &Bot{API: &mockAPI{authTest: authOK}} },
+			watchdog: &Watchdog{},
+			want:     map[string]bool{watchdogCheckAPI: false},
+		},
+		{
+			name: "should fail the api check when auth.test fails",
+			bot: func() *Bot {
+				return &Bot{API: &mockAPI{authTest: func() (*slack.AuthTestResponse, error) {
+					return nil, errors.New("connection refused")
+				}}}
+			},
+			watchdog: &Watchdog{},
+			want:     map[string]bool{watchdogCheckAPI: true},
+		},
+		{
+			name:     "should pass the websocket check when an event was received recently",
+			bot:      func() *Bot { return &Bot{API: &mockAPI{authTest: authOK}, rtmConnected: true} },
+			watchdog: &Watchdog{lastEvent: now.Add(-30 * time.Second)},
+			want:     map[string]bool{watchdogCheckWebsocket: false, watchdogCheckAPI: false},
+		},
+		{
+			name:     "should fail the websocket check when no events have been received",
+			bot:      func() *Bot { return &Bot{API: &mockAPI{authTest: authOK}, rtmConnected: true} },
+			watchdog: &Watchdog{started: now.Add(-5 * time.Minute)},
+			want:     map[string]bool{watchdogCheckWebsocket: true, watchdogCheckAPI: false},
+		},
+		{
+			name:     "should fail the scheduler check when the heartbeat stops",
+			bot:      func() *Bot { return &Bot{API: &mockAPI{authTest: authOK}, scheduler: &scheduler{}} },
+			watchdog: &Watchdog{heartbeat: now.Add(-5 * time.Minute)},
+			want:     map[string]bool{watchdogCheckAPI: false, watchdogCheckScheduler: true},
+		},
+		{
+			name: "should fail the send queue check when too many messages are due",
+			bot: func() *Bot {
+				store := NewMemoryStore(nil)
+				_ = store.Put(sendQueueStoreKey, []queuedSend{{ID: "1"}, {ID: "2"}, {ID: "3", NextAttempt: now.Add(time.Hour)}})
+				return &Bot{API: &mockAPI{authTest: authOK}, Store: store, SendQueue: &SendQueue{}}
+			},
+			watchdog: &Watchdog{MaxQueueDepth: 1},
+			want:     map[string]bool{watchdogCheckAPI: false, watchdogCheckSendQueue: true},
+		},
+		{
+			name: "should not count messages that aren't due",
+			bot: func() *Bot {
+				store := NewMemoryStore(nil)
+				_ = store.Put(sendQueueStoreKey, []queuedSend{{ID: "1"}, {ID: "2", NextAttempt: now.Add(time.Hour)}})
+				return &Bot{API: &mockAPI{authTest: authOK}, Store: store, SendQueue: &SendQueue{}}
+			},
+			watchdog: &Watchdog{MaxQueueDepth: 1},
+			want:     map[string]bool{watchdogCheckAPI: false, watchdogCheckSendQueue: false},
+		},
+		{
+			name: "should fail the workers check when every worker is busy",
+			bot: func() *Bot {
+				workers := make(chan struct{}, 1)
+				workers <- struct{}{}
+				return &Bot{API: &mockAPI{authTest: authOK}, workers: workers}
+			},
+			watchdog: &Watchdog{},
+			want:     map[string]bool{watchdogCheckAPI: false, watchdogCheckWorkers: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := tt.bot()
+			bot.Clock = fixedClock{Clock: RealClock, now: now}
+			got := map[string]bool{}
+			for _, r := range tt.watchdog.check(bot) {
+				got[r.check] = r.err != nil
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("check() failed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchdog_report(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	var reported []error
+	bot := &Bot{
+		API: &mockAPI{postMessage: func(channel string, opts ...slack.MsgOption) (string, string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			sent = append(sent, channel)
+			return channel, "ts", nil
+		}},
+		DebugChannel: "debug",
+		ErrorChannel: "errors",
+		ErrorReporter: ErrorReporterFunc(func(err error, info ErrorInfo) {
+			if info.Source == ErrorSourceWatchdog {
+				reported = append(reported, err)
+			}
+		}),
+	}
+	w := &Watchdog{}
+	failed := []watchdogResult{{check: watchdogCheckAPI, err: errors.New("connection refused")}}
+	w.report(bot, failed)
+	w.report(bot, failed)
+	w.report(bot, []watchdogResult{{check: watchdogCheckAPI}})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"errors", "debug"}; !reflect.DeepEqual(sent, want) {
+		t.Errorf("report() sent to %v, want %v", sent, want)
+	}
+	if len(reported) != 1 {
+		t.Errorf("report() reported %d errors, want 1", len(reported))
+	}
+}
@@ -0,0 +1,180 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	welcomeStateKey        = "welcome"
+	welcomeThreadKey       = "welcome thread"
+	welcomeThreadTTL       = 24 * time.Hour
+	welcomeThreadMessage   = "Welcome to everyone who joined today :wave:"
+	defaultWelcomeMessages = 5
+	defaultWelcomeInterval = time.Minute
+	welcomeLimitedMessage  = "Not welcoming %s to %s, more than %d members joined in %s"
+)
+
+type (
+	// Welcome greets users when they join a channel with the channel's ChannelWelcome. Welcomes are set for
+	// channels in Channels, which can be loaded from a file with LoadWelcomeFile, or while the bot is running with
+	// SetChannelWelcome, which saves them in the bot's Store and takes precedence. To avoid spamming a channel
+	// during a mass invite, no more than MaxMessages welcomes are sent to a channel in each TimeInterval, 5 a
+	// minute by default, and users who join after that aren't welcomed.
+	Welcome struct {
+		Channels     map[string]ChannelWelcome
+		MaxMessages  int
+		TimeInterval time.Duration
+
+		mu      sync.Mutex
+		windows map[string]*welcomeWindow
+	}
+
+	// ChannelWelcome is the message sent to users who join a channel, "{user}" is replaced with a mention of
+	// the user and "{channel}" with a link to the channel. Ephemeral messages are only shown to the user who
+	// joined, otherwise the welcome is posted in a thread under a daily welcome message so the channel itself
+	// stays quiet.
+	ChannelWelcome struct {
+		Message   string `json:"message"`
+		Ephemeral bool   `json:"ephemeral"`
+	}
+
+	welcomeWindow struct {
+		start time.Time
+		count int
+	}
+)
+
+// LoadWelcomeFile reads channel welcomes from a JSON file, an object with channel IDs as keys and objects
+// with a "message" and optionally "ephemeral" as values.
+func LoadWelcomeFile(path string) (map[string]ChannelWelcome, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read welcome file %s", path)
+	}
+	var welcomes map[string]ChannelWelcome
+	if err := json.Unmarshal(data, &welcomes); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse welcome file %s", path)
+	}
+	return welcomes, nil
+}
+
+// SetChannelWelcome saves the welcome for the channel in the Store. A welcome with an empty Message removes
+// it, so the channel uses the welcome in the bot's Welcome Channels, if it has one.
+func (bot *Bot) SetChannelWelcome(channel string, welcome ChannelWelcome) error {
+	if welcome.Message == "" {
+		_ = bot.ChannelState(channel).Delete(welcomeStateKey)
+		return nil
+	}
+	return bot.ChannelState(channel).Put(welcomeStateKey, welcome)
+}
+
+// ChannelWelcome returns the welcome for the channel, and false if it doesn't have one.
+func (bot *Bot) ChannelWelcome(channel string) (ChannelWelcome, bool) {
+	var welcome ChannelWelcome
+	if err := bot.ChannelState(channel).Get(welcomeStateKey, &welcome); err == nil && welcome.Message != "" {
+		return welcome, true
+	}
+	welcome, ok := bot.welcome().Channels[channel]
+	return welcome, ok && welcome.Message != ""
+}
+
+// welcome returns the bot's Welcome, setting it to the default if it is nil so welcomes saved in the Store are
+// rate limited too.
+func (bot *Bot) welcome() *Welcome {
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.Welcome == nil {
+		bot.Welcome = &Welcome{}
+	}
+	return bot.Welcome
+}
+
+// welcomeMember sends the channel's welcome to a user who joined it.
+func (bot *Bot) welcomeMember(user string, channel string) {
+	welcome, ok := bot.ChannelWelcome(channel)
+	if !ok {
+		return
+	}
+	if w := bot.welcome(); !w.allow(channel, bot.clock().Now()) {
+		bot.LogDebug(fmt.Sprintf(welcomeLimitedMessage, user, channel, w.maxMessages(), w.timeInterval()))
+		return
+	}
+	text := strings.NewReplacer("{user}", fmt.Sprintf("<@%s>", user), "{channel}", fmt.Sprintf("<#%s>", channel)).Replace(welcome.Message)
+	if welcome.Ephemeral {
+		err := bot.withRetry(func() error {
+			_, err := bot.api().PostEphemeralContext(bot.context(), channel, user, slack.MsgOptionText(text, false))
+			return err
+		})
+		if err != nil {
+			bot.LogError(fmt.Sprintf("unable to welcome %s to %s - %s", user, channel, err))
+		}
+		return
+	}
+	thread, err := bot.welcomeThread(channel)
+	if err != nil {
+		bot.LogError(fmt.Sprintf("unable to welcome %s to %s - %s", user, channel, err))
+		return
+	}
+	if _, _, err := bot.ReplyInThread(channel, thread, text); err != nil {
+		bot.LogError(fmt.Sprintf("unable to welcome %s to %s - %s", user, channel, err))
+	}
+}
+
+// welcomeThread returns the timestamp of today's welcome message in the channel, posting it if there isn't
+// one yet.
+func (bot *Bot) welcomeThread(channel string) (string, error) {
+	bot.welcomeMu.Lock()
+	defer bot.welcomeMu.Unlock()
+	state := bot.ChannelState(channel)
+	var thread string
+	if err := state.Get(welcomeThreadKey, &thread); err == nil && thread != "" {
+		return thread, nil
+	}
+	_, thread, err := bot.Reply(channel, welcomeThreadMessage)
+	if err != nil {
+		return "", err
+	}
+	return thread, state.PutWithTTL(welcomeThreadKey, thread, welcomeThreadTTL)
+}
+
+// allow counts a welcome for the channel at now, it returns false if the channel has had MaxMessages welcomes
+// in the current TimeInterval.
+func (w *Welcome) allow(channel string, now time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.windows == nil {
+		w.windows = make(map[string]*welcomeWindow)
+	}
+	win, ok := w.windows[channel]
+	if !ok || now.Sub(win.start) >= w.timeInterval() {
+		win = &welcomeWindow{start: now}
+		w.windows[channel] = win
+	}
+	if win.count >= w.maxMessages() {
+		return false
+	}
+	win.count++
+	return true
+}
+
+func (w *Welcome) maxMessages() int {
+	if w.MaxMessages > 0 {
+		return w.MaxMessages
+	}
+	return defaultWelcomeMessages
+}
+
+func (w *Welcome) timeInterval() time.Duration {
+	if w.TimeInterval > 0 {
+		return w.TimeInterval
+	}
+	return defaultWelcomeInterval
+}
@@ -0,0 +1,110 @@
+package slackbot
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBot_welcomeMember(t *testing.T) {
+	tests := []struct {
+		name          string
+		welcome       *Welcome
+		stored        *ChannelWelcome
+		joins         []string
+		wantMessages  []string
+		wantEphemeral []string
+	}{
+		{
+			name:         "should welcome users in a thread",
+			welcome:      &Welcome{Channels: map[string]ChannelWelcome{"C1": {Message: "Welcome {user} to {channel}!"}}},
+			joins:        []string{"U1", "U2"},
+			wantMessages: []string{"C1: " + welcomeThreadMessage, "C1/ts: Welcome <@U1> to <#C1>!", "C1/ts: Welcome <@U2> to <#C1>!"},
+		},
+		{
+			name:          "should send ephemeral welcomes",
+			welcome:       &Welcome{Channels: map[string]ChannelWelcome{"C1": {Message: "Welcome {user}!", Ephemeral: true}}},
+			joins:         []string{"U1"},
+			wantEphemeral: []string{"C1/U1: Welcome <@U1>!"},
+		},
+		{
+			name:          "should prefer the welcome saved in the store",
+			welcome:       &Welcome{Channels: map[string]ChannelWelcome{"C1": {Message: "Welcome {user}!"}}},
+			stored:        &ChannelWelcome{Message: "Hi {user}, read the pins.", Ephemeral: true},
+			joins:         []string{"U1"},
+			wantEphemeral: []string{"C1/U1: Hi <@U1>, read the pins."},
+		},
+		{
+			name:          "should use the welcome saved in the store without a Welcome",
+			stored:        &ChannelWelcome{Message: "Hi {user}", Ephemeral: true},
+			joins:         []string{"U1"},
+			wantEphemeral: []string{"C1/U1: Hi <@U1>"},
+		},
+		{
+			name:          "should limit welcomes during mass invites",
+			welcome:       &Welcome{Channels: map[string]ChannelWelcome{"C1": {Message: "Welcome {user}!", Ephemeral: true}}, MaxMessages: 2},
+			joins:         []string{"U1", "U2", "U3"},
+			wantEphemeral: []string{"C1/U1: Welcome <@U1>!", "C1/U2: Welcome <@U2>!"},
+		},
+		{
+			name:    "should not welcome users to other channels",
+			welcome: &Welcome{Channels: map[string]ChannelWelcome{"C2": {Message: "Welcome {user}!"}}},
+			joins:   []string{"U1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages, ephemeral []string
+			bot := &Bot{
+				API: &mockAPI{
+					postMessage: func(s string, opts ...slack.MsgOption) (string, string, error) {
+						values := msgValues(opts...)
+						if ts := values.Get("thread_ts"); ts != "" {
+							s += "/" + ts
+						}
+						messages = append(messages, s+": "+values.Get("text"))
+						return s, "ts", nil
+					},
+					postEphemeral: func(s string, user string, opts ...slack.MsgOption) (string, error) {
+						ephemeral = append(ephemeral, s+"/"+user+": "+msgValues(opts...).Get("text"))
+						return "ts", nil
+					},
+				},
+				Welcome:     tt.welcome,
+				userDetails: &slack.UserDetails{ID: "bot"},
+			}
+			if tt.stored != nil {
+				_ = bot.SetChannelWelcome("C1", *tt.stored)
+			}
+			for _, user := range tt.joins {
+				bot.handleMemberJoinedChannel(user, "C1")
+			}
+			if !reflect.DeepEqual(messages, tt.wantMessages) {
+				t.Errorf("welcomeMember() sent %q, want %q", messages, tt.wantMessages)
+			}
+			if !reflect.DeepEqual(ephemeral, tt.wantEphemeral) {
+				t.Errorf("welcomeMember() sent ephemeral %q, want %q", ephemeral, tt.wantEphemeral)
+			}
+		})
+	}
+}
+
+func TestLoadWelcomeFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "welcome")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "welcome.json")
+	_ = ioutil.WriteFile(path, []byte(`{"C1":{"message":"Welcome {user}!","ephemeral":true}}`), 0600)
+	got, err := LoadWelcomeFile(path)
+	if err != nil {
+		t.Fatalf("LoadWelcomeFile() error = %v", err)
+	}
+	want := map[string]ChannelWelcome{"C1": {Message: "Welcome {user}!", Ephemeral: true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadWelcomeFile() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,151 @@
+package slackbot
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const defaultTimeOfDay = 9 * time.Hour
+
+var (
+	relativeRegex  = regexp.MustCompile(`^in (an?|\d+) (second|minute|hour|day|week)s?$`)
+	dayRegex       = regexp.MustCompile(`^(today|tonight|tomorrow|(?:next |on )?(?:sunday|monday|tuesday|wednesday|thursday|friday|saturday))(?: at)?(?: (.+))?$`)
+	timeOfDayRegex = regexp.MustCompile(`^(?:at )?(\d{1,2})(?::(\d{2}))? ?(am|pm)?$`)
+
+	absoluteLayouts = []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02 3:04pm", "2006-01-02"}
+
+	units = map[string]time.Duration{
+		"second": time.Second,
+		"minute": time.Minute,
+		"hour":   time.Hour,
+		"day":    24 * time.Hour,
+		"week":   7 * 24 * time.Hour,
+	}
+	weekdays = map[string]time.Weekday{
+		"sunday":    time.Sunday,
+		"monday":    time.Monday,
+		"tuesday":   time.Tuesday,
+		"wednesday": time.Wednesday,
+		"thursday":  time.Thursday,
+		"friday":    time.Friday,
+		"saturday":  time.Saturday,
+	}
+)
+
+// ParseWhen will parse a natural language description of a time in the local timezone.
+// Supported formats include "now", "in 2 hours", "in a minute", "at 5pm", "tomorrow 9am",
+// "tonight", "next monday", "friday at 10:30am", and absolute dates like "2021-03-04 15:00".
+// To parse a time in a slack user's timezone use Bot.ParseWhenFor.
+func ParseWhen(text string) (time.Time, error) {
+	return parseWhen(text, time.Now())
+}
+
+// ParseWhenFor will parse the text the same as ParseWhen, using the timezone from the slack
+// profile of the user with the ID passed in.
+func (bot *Bot) ParseWhenFor(userID string, text string) (time.Time, error) {
+	loc, err := bot.UserLocation(userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parseWhen(text, bot.clock().Now().In(loc))
+}
+
+// parseWhen parses the text relative to now, using now's location as the timezone.
+func parseWhen(text string, now time.Time) (time.Time, error) {
+	t := strings.ToLower(strings.Join(strings.Fields(text), " "))
+
+	for _, layout := range absoluteLayouts {
+		if parsed, err := time.ParseInLocation(layout, t, now.Location()); err == nil {
+			return parsed, nil
+		}
+	}
+
+	if t == "now" {
+		return now, nil
+	}
+
+	if m := relativeRegex.FindStringSubmatch(t); m != nil {
+		n := 1
+		if m[1] != "a" && m[1] != "an" {
+			n, _ = strconv.Atoi(m[1])
+		}
+		return now.Add(time.Duration(n) * units[m[2]]), nil
+	}
+
+	if m := dayRegex.FindStringSubmatch(t); m != nil {
+		day := now
+		tod := defaultTimeOfDay
+		switch m[1] {
+		case "today":
+		case "tonight":
+			tod = 20 * time.Hour
+		case "tomorrow":
+			day = day.AddDate(0, 0, 1)
+		default:
+			wd := weekdays[m[1][strings.LastIndex(m[1], " ")+1:]]
+			diff := (int(wd) - int(now.Weekday()) + 7) % 7
+			if diff == 0 {
+				diff = 7
+			}
+			day = day.AddDate(0, 0, diff)
+		}
+		if m[2] != "" {
+			var err error
+			if tod, err = parseTimeOfDay(m[2]); err != nil {
+				return time.Time{}, err
+			}
+		}
+		return atTimeOfDay(day, tod), nil
+	}
+
+	if tod, err := parseTimeOfDay(t); err == nil {
+		at := atTimeOfDay(now, tod)
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+		return at, nil
+	}
+
+	return time.Time{}, errors.Errorf("unable to parse time from %q", text)
+}
+
+// parseTimeOfDay returns the duration since midnight for times like "9am", "9:30 pm", "17:00", "noon" or "midnight".
+func parseTimeOfDay(text string) (time.Duration, error) {
+	switch strings.TrimPrefix(text, "at ") {
+	case "noon":
+		return 12 * time.Hour, nil
+	case "midnight":
+		return 0, nil
+	}
+	m := timeOfDayRegex.FindStringSubmatch(text)
+	if m == nil {
+		return 0, errors.Errorf("unable to parse time of day from %q", text)
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	if m[3] == "" && m[2] == "" {
+		return 0, errors.Errorf("ambiguous time of day %q, add am/pm or minutes", text)
+	}
+	if m[3] != "" {
+		if hour < 1 || hour > 12 {
+			return 0, errors.Errorf("invalid hour in %q", text)
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, errors.Errorf("invalid time of day %q", text)
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, nil
+}
+
+// atTimeOfDay returns the time on the same day as t at the time of day, using wall clock time so DST changes are handled.
+func atTimeOfDay(t time.Time, tod time.Duration) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), int(tod/time.Hour), int(tod%time.Hour/time.Minute), 0, 0, t.Location())
+}
@@ -0,0 +1,95 @@
+package slackbot
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_parseWhen(t *testing.T) {
+	// Wednesday
+	now := time.Date(2020, 6, 10, 14, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		text    string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name: "should parse now",
+			text: "now",
+			want: now,
+		},
+		{
+			name: "should parse a relative duration",
+			text: "in 2 hours",
+			want: now.Add(2 * time.Hour),
+		},
+		{
+			name: "should parse a relative duration with an article",
+			text: "In a minute",
+			want: now.Add(time.Minute),
+		},
+		{
+			name: "should parse tomorrow with a time",
+			text: "tomorrow 9am",
+			want: time.Date(2020, 6, 11, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "should parse tomorrow at a time",
+			text: "tomorrow at 5:15 pm",
+			want: time.Date(2020, 6, 11, 17, 15, 0, 0, time.UTC),
+		},
+		{
+			name: "should parse next weekday with the default time",
+			text: "next monday",
+			want: time.Date(2020, 6, 15, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "should parse the same weekday as next week",
+			text: "wednesday at noon",
+			want: time.Date(2020, 6, 17, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "should parse a time later today",
+			text: "at 17:00",
+			want: time.Date(2020, 6, 10, 17, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "should parse a time that has passed as tomorrow",
+			text: "8am",
+			want: time.Date(2020, 6, 11, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "should parse an absolute date",
+			text: "2020-07-04 15:04",
+			want: time.Date(2020, 7, 4, 15, 4, 0, 0, time.UTC),
+		},
+		{
+			name:    "should error on an ambiguous time",
+			text:    "tomorrow at 9",
+			wantErr: true,
+		},
+		{
+			name:    "should error on an invalid hour",
+			text:    "13pm",
+			wantErr: true,
+		},
+		{
+			name:    "should error on unknown text",
+			text:    "whenever",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseWhen(tt.text, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseWhen() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseWhen() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
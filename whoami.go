@@ -0,0 +1,128 @@
+package slackbot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	whoAmIUserMessage        = "You are <@%s>."
+	whoAmICommandsMessage    = "*Commands you can use:*"
+	whoAmINoCommandsMessage  = "There are no commands you can use."
+	whoAmIChannelsMessage    = "*Channels I respond in:* direct messages and %s"
+	whoAmIAllChannelsMessage = "*Channels I respond in:* direct messages and any channel I'm a member of"
+	whoAmIPreferencesMessage = "*Your preferences:*"
+)
+
+var whoAmIRegex = regexp.MustCompile(`^(?i)(?:whoami|who am i|what can i do)\??$`)
+
+// Permission is a command a user is allowed to use. Channels are the only channels it can be used in, as well
+// as direct messages, it is empty if the command can be used in any channel. DirectMessagesOnly is set when
+// the bot's ACL and the command's ACL have no channels in common.
+type Permission struct {
+	Usage              string
+	Channels           []string
+	DirectMessagesOnly bool
+}
+
+// Permissions returns the direct listeners, exchanges and forms with a Usage that the user is allowed to use
+// by the bot's ACL and their own.
+func (bot *Bot) Permissions(user string) []Permission {
+	var permissions []Permission
+	add := func(usage string, acl *ACL) {
+		if usage == "" || !bot.ACL.AllowsUser(bot, user) || !acl.AllowsUser(bot, user) {
+			return
+		}
+		p := Permission{Usage: usage}
+		if bot.ACL != nil {
+			p.Channels = bot.ACL.Channels
+		}
+		if acl != nil && len(acl.Channels) > 0 {
+			p.Channels = intersectChannels(p.Channels, acl.Channels)
+			p.DirectMessagesOnly = len(p.Channels) == 0
+		}
+		permissions = append(permissions, p)
+	}
+	for _, l := range bot.DirectListeners {
+		add(l.Usage, l.ACL)
+	}
+	for _, e := range bot.Exchanges {
+		add(e.Usage, e.ACL)
+	}
+	for _, f := range bot.Forms {
+		add(f.Usage, nil)
+	}
+	return permissions
+}
+
+// WhoAmIListener returns a DirectListener that replies to "whoami" or "what can I do" with the commands the
+// user is allowed to use, the channels the bot responds in and the user's preferences. It is not enabled by
+// default, add it to the bot's DirectListeners to enable it.
+func WhoAmIListener() Listener {
+	return Listener{
+		Usage: "whoami",
+		Regex: whoAmIRegex,
+		Handler: func(bot *Bot, ev *slack.MessageEvent) {
+			_, _, _ = bot.ReplyInThread(ev.Channel, threadTimestamp(ev), bot.whoAmI(ev.User))
+		},
+	}
+}
+
+func (bot *Bot) whoAmI(user string) string {
+	lines := []string{fmt.Sprintf(whoAmIUserMessage, user)}
+	permissions := bot.Permissions(user)
+	if len(permissions) == 0 {
+		lines = append(lines, whoAmINoCommandsMessage)
+	} else {
+		lines = append(lines, whoAmICommandsMessage)
+	}
+	for _, p := range permissions {
+		line := "• " + p.Usage
+		if p.DirectMessagesOnly {
+			line += " (only in direct messages)"
+		} else if len(p.Channels) > 0 {
+			line += fmt.Sprintf(" (only in %s)", formatChannels(p.Channels))
+		}
+		lines = append(lines, line)
+	}
+	if bot.ACL != nil && len(bot.ACL.Channels) > 0 {
+		lines = append(lines, fmt.Sprintf(whoAmIChannelsMessage, formatChannels(bot.ACL.Channels)))
+	} else {
+		lines = append(lines, whoAmIAllChannelsMessage)
+	}
+	if len(bot.Preferences) > 0 {
+		lines = append(lines, whoAmIPreferencesMessage)
+		for _, p := range bot.Preferences {
+			lines = append(lines, fmt.Sprintf("• *%s*: %s", p.Name, bot.Preference(user, p.Name)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// intersectChannels returns the channels in both lists, an empty list allows every channel.
+func intersectChannels(a []string, b []string) []string {
+	if len(a) == 0 {
+		return b
+	}
+	var channels []string
+	for _, c := range b {
+		for _, d := range a {
+			if c == d {
+				channels = append(channels, c)
+				break
+			}
+		}
+	}
+	return channels
+}
+
+func formatChannels(channels []string) string {
+	links := make([]string, len(channels))
+	for i, c := range channels {
+		links[i] = fmt.Sprintf("<#%s>", c)
+	}
+	return strings.Join(links, ", ")
+}
@@ -0,0 +1,77 @@
+package slackbot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBot_Permissions(t *testing.T) {
+	tests := []struct {
+		name   string
+		botACL *ACL
+		user   string
+		want   []Permission
+	}{
+		{
+			name: "should list the commands the user can use",
+			user: "U1",
+			want: []Permission{
+				{Usage: "deploy", Channels: []string{"C1"}},
+				{Usage: "status"},
+				{Usage: "rollback"},
+			},
+		},
+		{
+			name: "should leave out commands the user can't use",
+			user: "U2",
+			want: []Permission{{Usage: "status"}},
+		},
+		{
+			name:   "should combine the bot's channels with the command's",
+			botACL: &ACL{Channels: []string{"C2"}},
+			user:   "U1",
+			want: []Permission{
+				{Usage: "deploy", DirectMessagesOnly: true},
+				{Usage: "status", Channels: []string{"C2"}},
+				{Usage: "rollback", Channels: []string{"C2"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &Bot{
+				ACL: tt.botACL,
+				DirectListeners: []Listener{
+					{Usage: "deploy", ACL: &ACL{Users: []string{"U1"}, Channels: []string{"C1"}}},
+					{Usage: "status"},
+					{},
+				},
+				Exchanges: []Exchange{{Usage: "rollback", ACL: &ACL{Users: []string{"U1"}}}},
+			}
+			if got := bot.Permissions(tt.user); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Permissions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_whoAmI(t *testing.T) {
+	bot := &Bot{
+		ACL: &ACL{Channels: []string{"C1"}},
+		DirectListeners: []Listener{
+			{Usage: "deploy", ACL: &ACL{Users: []string{"U1"}}},
+			WhoAmIListener(),
+		},
+		Preferences: []Preference{{Name: "environment", Default: "staging"}},
+	}
+	want := "You are <@U1>.\n" +
+		"*Commands you can use:*\n" +
+		"• deploy (only in <#C1>)\n" +
+		"• whoami (only in <#C1>)\n" +
+		"*Channels I respond in:* direct messages and <#C1>\n" +
+		"*Your preferences:*\n" +
+		"• *environment*: staging"
+	if got := bot.whoAmI("U1"); got != want {
+		t.Errorf("whoAmI() = %q, want %q", got, want)
+	}
+}